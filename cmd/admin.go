@@ -29,6 +29,8 @@ func cmdAdmin() *cli.Command {
 			subcmdRegenerate(),
 			subcmdAuth(),
 			subcmdSendMail(),
+			subcmdPackages(),
+			subcmdSSHCertificateAuthority(),
 		},
 	}
 }
@@ -65,6 +67,8 @@ func subcmdAuth() *cli.Command {
 			microcmdAuthUpdateLdapSimpleAuth(),
 			microcmdAuthAddSMTP(),
 			microcmdAuthUpdateSMTP(),
+			microcmdAuthAddSAML(),
+			microcmdAuthUpdateSAML(),
 			microcmdAuthList(),
 			microcmdAuthDelete(),
 		},
@@ -0,0 +1,249 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	auth_model "forgejo.org/models/auth"
+	saml_module "forgejo.org/modules/saml"
+	"forgejo.org/services/auth/source/saml"
+
+	"github.com/urfave/cli/v3"
+)
+
+func samlCLIFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "name",
+			Value: "",
+			Usage: "Authentication name",
+		},
+		&cli.StringFlag{
+			Name:  "idp-metadata-url",
+			Value: "",
+			Usage: "URL to import the identity provider metadata from",
+		},
+		&cli.StringFlag{
+			Name:  "idp-metadata-file",
+			Value: "",
+			Usage: "File to read the identity provider metadata from",
+		},
+		&cli.StringFlag{
+			Name:  "name-id-format",
+			Value: "",
+			Usage: "NameID format to request from the identity provider",
+		},
+		&cli.StringFlag{
+			Name:  "username-attribute",
+			Value: "",
+			Usage: "Attribute providing the username, the NameID is used if empty",
+		},
+		&cli.StringFlag{
+			Name:  "full-name-attribute",
+			Value: "",
+			Usage: "Attribute providing the full name",
+		},
+		&cli.StringFlag{
+			Name:  "email-attribute",
+			Value: "",
+			Usage: "Attribute providing the email address, the NameID is used if empty",
+		},
+		&cli.StringFlag{
+			Name:  "group-attribute",
+			Value: "",
+			Usage: "Attribute providing group names for this source",
+		},
+		&cli.StringFlag{
+			Name:  "admin-group",
+			Value: "",
+			Usage: "Group for administrator users",
+		},
+		&cli.StringFlag{
+			Name:  "restricted-group",
+			Value: "",
+			Usage: "Group for restricted users",
+		},
+		&cli.StringFlag{
+			Name:  "group-team-map",
+			Value: "",
+			Usage: "JSON mapping between groups and org teams",
+		},
+		&cli.BoolFlag{
+			Name:  "group-team-map-removal",
+			Usage: "Activate automatic team membership removal depending on groups",
+		},
+		&cli.BoolFlag{
+			Name:  "allow-idp-initiated",
+			Usage: "Accept logins started at the identity provider",
+		},
+		&cli.BoolFlag{
+			Name:  "skip-local-2fa",
+			Usage: "Set to true to skip local 2fa for users authenticated by this source",
+		},
+		&cli.BoolFlag{
+			Name:  "active",
+			Usage: "This Authentication Source is Activated.",
+			Value: true,
+		},
+	}
+}
+
+func microcmdAuthAddSAML() *cli.Command {
+	return &cli.Command{
+		Name:   "add-saml",
+		Usage:  "Add new SAML authentication source",
+		Action: runAddSAML,
+		Flags:  samlCLIFlags(),
+	}
+}
+
+func microcmdAuthUpdateSAML() *cli.Command {
+	return &cli.Command{
+		Name:   "update-saml",
+		Usage:  "Update existing SAML authentication source",
+		Action: runUpdateSAML,
+		Flags:  append(samlCLIFlags()[:1], append([]cli.Flag{idFlag()}, samlCLIFlags()[1:]...)...),
+	}
+}
+
+// readSAMLMetadata returns the identity provider metadata given by --idp-metadata-url or --idp-metadata-file
+func readSAMLMetadata(ctx context.Context, c *cli.Command) (string, error) {
+	if c.IsSet("idp-metadata-url") && c.IsSet("idp-metadata-file") {
+		return "", errors.New("--idp-metadata-url and --idp-metadata-file are mutually exclusive")
+	}
+	if c.IsSet("idp-metadata-url") {
+		return saml.FetchIdentityProviderMetadata(ctx, c.String("idp-metadata-url"))
+	}
+	data, err := os.ReadFile(c.String("idp-metadata-file"))
+	if err != nil {
+		return "", err
+	}
+	if _, err := saml_module.ParseIdentityProviderMetadata(data); err != nil {
+		return "", fmt.Errorf("invalid identity provider metadata in %s: %w", c.String("idp-metadata-file"), err)
+	}
+	return string(data), nil
+}
+
+func runAddSAML(ctx context.Context, c *cli.Command) error {
+	if !c.IsSet("name") || len(c.String("name")) == 0 {
+		return errors.New("name must be set")
+	}
+	if !c.IsSet("idp-metadata-url") && !c.IsSet("idp-metadata-file") {
+		return errors.New("either --idp-metadata-url or --idp-metadata-file must be set")
+	}
+
+	ctx, cancel := installSignals(ctx)
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	metadata, err := readSAMLMetadata(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	active := true
+	if c.IsSet("active") {
+		active = c.Bool("active")
+	}
+
+	return auth_model.CreateSource(ctx, &auth_model.Source{
+		Type:     auth_model.SAML,
+		Name:     c.String("name"),
+		IsActive: active,
+		Cfg: &saml.Source{
+			IdentityProviderMetadata:    metadata,
+			IdentityProviderMetadataURL: c.String("idp-metadata-url"),
+			NameIDFormat:                c.String("name-id-format"),
+			AttributeUsername:           c.String("username-attribute"),
+			AttributeFullName:           c.String("full-name-attribute"),
+			AttributeEmail:              c.String("email-attribute"),
+			GroupAttribute:              c.String("group-attribute"),
+			AdminGroup:                  c.String("admin-group"),
+			RestrictedGroup:             c.String("restricted-group"),
+			GroupTeamMap:                c.String("group-team-map"),
+			GroupTeamMapRemoval:         c.Bool("group-team-map-removal"),
+			AllowIdPInitiated:           c.Bool("allow-idp-initiated"),
+			SkipLocalTwoFA:              c.Bool("skip-local-2fa"),
+		},
+	})
+}
+
+func runUpdateSAML(ctx context.Context, c *cli.Command) error {
+	if !c.IsSet("id") {
+		return errors.New("--id flag is missing")
+	}
+
+	ctx, cancel := installSignals(ctx)
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	source, err := auth_model.GetSourceByID(ctx, c.Int64("id"))
+	if err != nil {
+		return err
+	}
+	if source.Type != auth_model.SAML {
+		return fmt.Errorf("authentication source %d is not a SAML source", source.ID)
+	}
+	samlConfig := source.Cfg.(*saml.Source)
+
+	if c.IsSet("name") {
+		source.Name = c.String("name")
+	}
+	if c.IsSet("active") {
+		source.IsActive = c.Bool("active")
+	}
+
+	if c.IsSet("idp-metadata-url") || c.IsSet("idp-metadata-file") {
+		if samlConfig.IdentityProviderMetadata, err = readSAMLMetadata(ctx, c); err != nil {
+			return err
+		}
+		samlConfig.IdentityProviderMetadataURL = c.String("idp-metadata-url")
+	}
+	if c.IsSet("name-id-format") {
+		samlConfig.NameIDFormat = c.String("name-id-format")
+	}
+	if c.IsSet("username-attribute") {
+		samlConfig.AttributeUsername = c.String("username-attribute")
+	}
+	if c.IsSet("full-name-attribute") {
+		samlConfig.AttributeFullName = c.String("full-name-attribute")
+	}
+	if c.IsSet("email-attribute") {
+		samlConfig.AttributeEmail = c.String("email-attribute")
+	}
+	if c.IsSet("group-attribute") {
+		samlConfig.GroupAttribute = c.String("group-attribute")
+	}
+	if c.IsSet("admin-group") {
+		samlConfig.AdminGroup = c.String("admin-group")
+	}
+	if c.IsSet("restricted-group") {
+		samlConfig.RestrictedGroup = c.String("restricted-group")
+	}
+	if c.IsSet("group-team-map") {
+		samlConfig.GroupTeamMap = c.String("group-team-map")
+	}
+	if c.IsSet("group-team-map-removal") {
+		samlConfig.GroupTeamMapRemoval = c.Bool("group-team-map-removal")
+	}
+	if c.IsSet("allow-idp-initiated") {
+		samlConfig.AllowIdPInitiated = c.Bool("allow-idp-initiated")
+	}
+	if c.IsSet("skip-local-2fa") {
+		samlConfig.SkipLocalTwoFA = c.Bool("skip-local-2fa")
+	}
+
+	source.Cfg = samlConfig
+	return auth_model.UpdateSource(ctx, source)
+}
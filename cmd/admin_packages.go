@@ -0,0 +1,89 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	packages_model "forgejo.org/models/packages"
+	packages_scan "forgejo.org/services/packages/scan"
+
+	"github.com/urfave/cli/v3"
+)
+
+func subcmdPackages() *cli.Command {
+	return &cli.Command{
+		Name:  "packages",
+		Usage: "Manage the package registry",
+		Commands: []*cli.Command{
+			microcmdPackagesImportOSV(),
+		},
+	}
+}
+
+func microcmdPackagesImportOSV() *cli.Command {
+	return &cli.Command{
+		Name:   "import-osv",
+		Usage:  "Import vulnerability advisories in the OSV format and rescan all packages",
+		Action: runPackagesImportOSV,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "file",
+				Aliases:  []string{"f"},
+				Usage:    "Path to a zip archive of OSV records or a single OSV JSON record",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "skip-scan",
+				Usage: "Do not rescan the existing packages after the import",
+			},
+		},
+	}
+}
+
+func runPackagesImportOSV(ctx context.Context, c *cli.Command) error {
+	ctx, cancel := installSignals(ctx)
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	f, err := os.Open(c.String("file"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var result *packages_scan.ImportResult
+	if strings.HasSuffix(strings.ToLower(f.Name()), ".zip") {
+		fi, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		result, err = packages_scan.ImportArchive(ctx, f, fi.Size())
+		if err != nil {
+			return err
+		}
+	} else {
+		result, err = packages_scan.ImportRecord(ctx, f)
+		if err != nil {
+			return err
+		}
+	}
+
+	count, err := packages_model.CountAdvisories(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d records (%d withdrawn, %d skipped), %d advisory entries are stored\n", result.Records, result.Withdrawn, result.Skipped, count)
+
+	if c.Bool("skip-scan") {
+		return nil
+	}
+	return packages_scan.ScanAll(ctx)
+}
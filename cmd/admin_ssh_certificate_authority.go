@@ -0,0 +1,165 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	asymkey_model "forgejo.org/models/asymkey"
+	"forgejo.org/modules/setting"
+
+	"github.com/urfave/cli/v3"
+)
+
+func subcmdSSHCertificateAuthority() *cli.Command {
+	return &cli.Command{
+		Name:  "ssh-certificate-authority",
+		Usage: "Modify the certificate authorities trusted to sign SSH user certificates",
+		Commands: []*cli.Command{
+			microcmdSSHCertificateAuthorityAdd(),
+			microcmdSSHCertificateAuthorityList(),
+			microcmdSSHCertificateAuthorityDelete(),
+		},
+	}
+}
+
+func microcmdSSHCertificateAuthorityAdd() *cli.Command {
+	return &cli.Command{
+		Name:   "add",
+		Usage:  "Trust a certificate authority",
+		Action: runAddSSHCertificateAuthority,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "name",
+				Usage:    "Name of the certificate authority",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "key",
+				Usage: "Public key of the certificate authority in authorized keys format",
+			},
+			&cli.StringFlag{
+				Name:  "key-file",
+				Usage: "File to read the public key of the certificate authority from",
+			},
+			&cli.StringFlag{
+				Name:  "mapping",
+				Usage: "How certificates are mapped to users: none (principal keys of the users only), username or email (a principal is the username or an activated email address), key-id (the key ID is the username)",
+				Value: asymkey_model.SSHCertificateAuthorityMappingNone.String(),
+			},
+			&cli.DurationFlag{
+				Name:  "max-validity",
+				Usage: "Longest validity period accepted for certificates, 0 is unlimited",
+			},
+		},
+	}
+}
+
+func microcmdSSHCertificateAuthorityList() *cli.Command {
+	return &cli.Command{
+		Name:   "list",
+		Usage:  "List trusted certificate authorities",
+		Action: runListSSHCertificateAuthorities,
+	}
+}
+
+func microcmdSSHCertificateAuthorityDelete() *cli.Command {
+	return &cli.Command{
+		Name:   "delete",
+		Usage:  "Stop trusting a certificate authority",
+		Action: runDeleteSSHCertificateAuthority,
+		Flags: []cli.Flag{
+			&cli.Int64Flag{
+				Name:     "id",
+				Usage:    "ID of the certificate authority",
+				Required: true,
+			},
+		},
+	}
+}
+
+func runAddSSHCertificateAuthority(ctx context.Context, c *cli.Command) error {
+	if c.IsSet("key") == c.IsSet("key-file") {
+		return errors.New("either --key or --key-file must be set")
+	}
+	content := c.String("key")
+	if c.IsSet("key-file") {
+		data, err := os.ReadFile(c.String("key-file"))
+		if err != nil {
+			return err
+		}
+		content = string(data)
+	}
+	mapping, err := asymkey_model.ParseSSHCertificateAuthorityMapping(c.String("mapping"))
+	if err != nil {
+		return err
+	}
+	if c.Duration("max-validity") < 0 {
+		return errors.New("--max-validity must not be negative")
+	}
+	// OpenSSH only knows the certificate authorities of SSH_TRUSTED_USER_CA_KEYS and can not map certificates to users
+	if !setting.SSH.StartBuiltinServer {
+		return errors.New("certificate authorities can only be added if the builtin SSH server is used (START_SSH_SERVER), use SSH_TRUSTED_USER_CA_KEYS with OpenSSH")
+	}
+
+	ctx, cancel := installSignals(ctx)
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	ca := &asymkey_model.SSHCertificateAuthority{
+		Name:        c.String("name"),
+		Content:     content,
+		Mapping:     mapping,
+		MaxValidity: int64(c.Duration("max-validity").Seconds()),
+	}
+	if err := asymkey_model.AddSSHCertificateAuthority(ctx, ca); err != nil {
+		return err
+	}
+	fmt.Printf("Certificate authority %s added with ID %d, fingerprint %s\n", ca.Name, ca.ID, ca.Fingerprint)
+	return nil
+}
+
+func runListSSHCertificateAuthorities(ctx context.Context, c *cli.Command) error {
+	ctx, cancel := installSignals(ctx)
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	cas, err := asymkey_model.FindSSHCertificateAuthorities(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', 0)
+	fmt.Fprint(w, "ID\tName\tFingerprint\tMapping\tMax validity\n")
+	for _, ca := range cas {
+		maxValidity := "unlimited"
+		if ca.MaxValidity > 0 {
+			maxValidity = (time.Duration(ca.MaxValidity) * time.Second).String()
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", ca.ID, ca.Name, ca.Fingerprint, ca.Mapping, maxValidity)
+	}
+	return w.Flush()
+}
+
+func runDeleteSSHCertificateAuthority(ctx context.Context, c *cli.Command) error {
+	ctx, cancel := installSignals(ctx)
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	return asymkey_model.DeleteSSHCertificateAuthority(ctx, c.Int64("id"))
+}
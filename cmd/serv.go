@@ -158,25 +158,48 @@ func runServ(ctx context.Context, c *cli.Command) error {
 		}
 	}()
 
+	// the builtin server authenticates certificates mapped to a user by a trusted certificate authority as
+	// cert-<certificate authority id>-<user id>, any other key as key-<key id>
+	var keyID int64
+	var cert *private.ServCertificate
 	keys := strings.Split(c.Args().First(), "-")
-	if len(keys) != 2 || keys[0] != "key" {
+	switch {
+	case len(keys) == 2 && keys[0] == "key":
+		var err error
+		keyID, err = strconv.ParseInt(keys[1], 10, 64)
+		if err != nil {
+			return fail(ctx, "Key ID parsing error", "Invalid key argument: %s", c.Args().Get(1))
+		}
+	case len(keys) == 3 && keys[0] == "cert":
+		cert = &private.ServCertificate{}
+		var err error
+		if cert.AuthorityID, err = strconv.ParseInt(keys[1], 10, 64); err != nil {
+			return fail(ctx, "Certificate authority ID parsing error", "Invalid certificate argument: %s", c.Args().First())
+		}
+		if cert.UserID, err = strconv.ParseInt(keys[2], 10, 64); err != nil {
+			return fail(ctx, "User ID parsing error", "Invalid certificate argument: %s", c.Args().First())
+		}
+		cert.KeyID = os.Getenv("FORGEJO_SSH_CERTIFICATE_KEY_ID")
+		cert.Serial = os.Getenv("FORGEJO_SSH_CERTIFICATE_SERIAL")
+	default:
 		return fail(ctx, "Key ID format error", "Invalid key argument: %s", c.Args().First())
 	}
-	keyID, err := strconv.ParseInt(keys[1], 10, 64)
-	if err != nil {
-		return fail(ctx, "Key ID parsing error", "Invalid key argument: %s", c.Args().Get(1))
-	}
+
+	// SSH_CONNECTION is "client-address client-port server-address server-port", it is set by OpenSSH and the builtin server
+	remoteAddr, _, _ := strings.Cut(os.Getenv("SSH_CONNECTION"), " ")
 
 	cmd := os.Getenv("SSH_ORIGINAL_COMMAND")
 	if len(cmd) == 0 {
-		key, user, err := private.ServNoCommand(ctx, keyID)
+		key, user, err := private.ServNoCommand(ctx, keyID, cert, remoteAddr)
 		if err != nil {
 			return fail(ctx, "Key check failed", "Failed to check provided key: %v", err)
 		}
-		switch key.Type {
-		case asymkey_model.KeyTypeDeploy:
+		switch {
+		case cert != nil:
+			fmt.Println("Hi there, " + user.Name + "! You've successfully authenticated with a certificate of the certificate authority " + key.Name + ", but Forgejo does not provide shell access.")
+		case key.Type == asymkey_model.KeyTypeDeploy:
 			fmt.Println("Hi there! You've successfully authenticated with the deploy key named " + key.Name + ", but Forgejo does not provide shell access.")
-		case asymkey_model.KeyTypePrincipal:
+		case key.Type == asymkey_model.KeyTypePrincipal:
 			fmt.Println("Hi there! You've successfully authenticated with the principal " + key.Content + ", but Forgejo does not provide shell access.")
 		default:
 			fmt.Println("Hi there, " + user.Name + "! You've successfully authenticated with the key named " + key.Name + ", but Forgejo does not provide shell access.")
@@ -268,7 +291,7 @@ func runServ(ctx context.Context, c *cli.Command) error {
 		}
 	}
 
-	results, extra := private.ServCommand(ctx, keyID, username, reponame, requestedMode, verb, lfsVerb)
+	results, extra := private.ServCommand(ctx, keyID, cert, remoteAddr, username, reponame, requestedMode, verb, lfsVerb)
 	if extra.HasError() {
 		return fail(ctx, extra.UserMsg, "ServCommand failed: %s", extra.Error)
 	}
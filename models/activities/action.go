@@ -597,6 +597,28 @@ func DeleteOldActions(ctx context.Context, olderThan time.Duration) (err error)
 	return err
 }
 
+// GetLatestPublicAction returns the latest public action of a type the user performed in a repository or nil if there is none
+func GetLatestPublicAction(ctx context.Context, actUserID, repoID int64, opType ActionType) (*Action, error) {
+	action := &Action{}
+	has, err := db.GetEngine(ctx).
+		Where(builder.Eq{
+			"user_id":     actUserID,
+			"act_user_id": actUserID,
+			"repo_id":     repoID,
+			"op_type":     opType,
+			"is_private":  false,
+			"is_deleted":  false,
+		}).
+		Desc("id").
+		Get(action)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return action, nil
+}
+
 // NotifyWatchers creates batch of actions for every watcher.
 func NotifyWatchers(ctx context.Context, actions ...*Action) error {
 	var watchers []*repo_model.Watch
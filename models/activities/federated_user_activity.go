@@ -0,0 +1,80 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package activities
+
+import (
+	"context"
+
+	"forgejo.org/models/db"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/container"
+	"forgejo.org/modules/timeutil"
+)
+
+// FederatedUserActivity is a public activity of a user of another instance.
+// It is shown in the dashboard feed of the local followers of the user.
+type FederatedUserActivity struct {
+	ID          int64              `xorm:"pk autoincr"`
+	UserID      int64              `xorm:"NOT NULL INDEX"` // local representation of the remote actor
+	User        *user_model.User   `xorm:"-"`
+	NoteURL     string             `xorm:"VARCHAR(255) INDEX"`
+	NoteContent string             `xorm:"TEXT"`
+	Created     timeutil.TimeStamp `xorm:"created INDEX"`
+}
+
+func init() {
+	db.RegisterModel(new(FederatedUserActivity))
+}
+
+// CreateFederatedUserActivity stores an activity unless an activity with the same note was already received
+func CreateFederatedUserActivity(ctx context.Context, activity *FederatedUserActivity) error {
+	exists, err := db.GetEngine(ctx).Where("user_id = ? AND note_url = ?", activity.UserID, activity.NoteURL).Exist(new(FederatedUserActivity))
+	if err != nil || exists {
+		return err
+	}
+	return db.Insert(ctx, activity)
+}
+
+// GetFederatedUserActivitiesForFollower returns the activities of the federated users the follower follows
+func GetFederatedUserActivitiesForFollower(ctx context.Context, followerID int64, opts db.ListOptions) ([]*FederatedUserActivity, error) {
+	sess := db.GetEngine(ctx).
+		Join("INNER", "follow", "`follow`.follow_id = `federated_user_activity`.user_id").
+		Where("`follow`.user_id = ?", followerID).
+		OrderBy("`federated_user_activity`.created DESC")
+	if opts.PageSize > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+
+	activities := make([]*FederatedUserActivity, 0, opts.PageSize)
+	if err := sess.Find(&activities); err != nil {
+		return nil, err
+	}
+
+	userIDs := make(container.Set[int64])
+	for _, activity := range activities {
+		userIDs.Add(activity.UserID)
+	}
+	users, err := user_model.GetUsersByIDs(ctx, userIDs.Values())
+	if err != nil {
+		return nil, err
+	}
+	userMap := make(map[int64]*user_model.User, len(users))
+	for _, u := range users {
+		userMap[u.ID] = u
+	}
+	for _, activity := range activities {
+		activity.User = userMap[activity.UserID]
+		if activity.User == nil {
+			activity.User = user_model.NewGhostUser()
+		}
+	}
+
+	return activities, nil
+}
+
+// DeleteFederatedUserActivities removes the received activities of a federated user
+func DeleteFederatedUserActivities(ctx context.Context, userID int64) error {
+	_, err := db.DeleteByBean(ctx, &FederatedUserActivity{UserID: userID})
+	return err
+}
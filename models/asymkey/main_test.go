@@ -19,6 +19,7 @@ func TestMain(m *testing.M) {
 			"gpg_key_import.yml",
 			"user.yml",
 			"email_address.yml",
+			"ssh_certificate_authority.yml",
 		},
 	})
 }
@@ -0,0 +1,228 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package asymkey
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"forgejo.org/models/db"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/timeutil"
+	"forgejo.org/modules/util"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHCertificateAuthorityMapping is how the certificates of a certificate authority are mapped to users
+type SSHCertificateAuthorityMapping int
+
+const (
+	// SSHCertificateAuthorityMappingNone only accepts principals users registered as principal keys
+	SSHCertificateAuthorityMappingNone SSHCertificateAuthorityMapping = iota // 0
+	// SSHCertificateAuthorityMappingUsername maps a principal to the user of that name
+	SSHCertificateAuthorityMappingUsername // 1
+	// SSHCertificateAuthorityMappingEmail maps a principal to the user with that activated email address
+	SSHCertificateAuthorityMappingEmail // 2
+	// SSHCertificateAuthorityMappingKeyID maps the key ID of the certificate to the user of that name
+	SSHCertificateAuthorityMappingKeyID // 3
+)
+
+var sshCertificateAuthorityMappingNames = map[SSHCertificateAuthorityMapping]string{
+	SSHCertificateAuthorityMappingNone:     "none",
+	SSHCertificateAuthorityMappingUsername: "username",
+	SSHCertificateAuthorityMappingEmail:    "email",
+	SSHCertificateAuthorityMappingKeyID:    "key-id",
+}
+
+func (mapping SSHCertificateAuthorityMapping) String() string {
+	return sshCertificateAuthorityMappingNames[mapping]
+}
+
+// ParseSSHCertificateAuthorityMapping returns the mapping of the given name
+func ParseSSHCertificateAuthorityMapping(name string) (SSHCertificateAuthorityMapping, error) {
+	for mapping, mappingName := range sshCertificateAuthorityMappingNames {
+		if mappingName == name {
+			return mapping, nil
+		}
+	}
+	return 0, util.NewInvalidArgumentErrorf("unknown certificate mapping %q", name)
+}
+
+// SSHCertificateAuthority is a certificate authority trusted to sign user certificates
+type SSHCertificateAuthority struct {
+	ID          int64                          `xorm:"pk autoincr"`
+	Name        string                         `xorm:"UNIQUE NOT NULL"`
+	Fingerprint string                         `xorm:"UNIQUE NOT NULL"`
+	Content     string                         `xorm:"MEDIUMTEXT NOT NULL"`
+	Mapping     SSHCertificateAuthorityMapping `xorm:"NOT NULL DEFAULT 0"`
+	// MaxValidity is the longest validity period in seconds accepted for certificates, 0 is unlimited
+	MaxValidity int64 `xorm:"NOT NULL DEFAULT 0"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(SSHCertificateAuthority))
+}
+
+// ErrSSHCertificateAuthorityNotExist represents a "SSHCertificateAuthorityNotExist" kind of error.
+type ErrSSHCertificateAuthorityNotExist struct {
+	ID          int64
+	Fingerprint string
+}
+
+// IsErrSSHCertificateAuthorityNotExist checks if an error is a ErrSSHCertificateAuthorityNotExist.
+func IsErrSSHCertificateAuthorityNotExist(err error) bool {
+	_, ok := err.(ErrSSHCertificateAuthorityNotExist)
+	return ok
+}
+
+func (err ErrSSHCertificateAuthorityNotExist) Error() string {
+	return fmt.Sprintf("ssh certificate authority does not exist [id: %d, fingerprint: %s]", err.ID, err.Fingerprint)
+}
+
+func (err ErrSSHCertificateAuthorityNotExist) Unwrap() error {
+	return util.ErrNotExist
+}
+
+// ErrSSHCertificateAuthorityAlreadyExist represents a "SSHCertificateAuthorityAlreadyExist" kind of error.
+type ErrSSHCertificateAuthorityAlreadyExist struct {
+	Name        string
+	Fingerprint string
+}
+
+// IsErrSSHCertificateAuthorityAlreadyExist checks if an error is a ErrSSHCertificateAuthorityAlreadyExist.
+func IsErrSSHCertificateAuthorityAlreadyExist(err error) bool {
+	_, ok := err.(ErrSSHCertificateAuthorityAlreadyExist)
+	return ok
+}
+
+func (err ErrSSHCertificateAuthorityAlreadyExist) Error() string {
+	return fmt.Sprintf("ssh certificate authority already exists [name: %s, fingerprint: %s]", err.Name, err.Fingerprint)
+}
+
+func (err ErrSSHCertificateAuthorityAlreadyExist) Unwrap() error {
+	return util.ErrAlreadyExist
+}
+
+// AddSSHCertificateAuthority trusts a new certificate authority, its content is an authorized keys line
+func AddSSHCertificateAuthority(ctx context.Context, ca *SSHCertificateAuthority) error {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(ca.Content))
+	if err != nil {
+		return util.NewInvalidArgumentErrorf("invalid certificate authority key: %v", err)
+	}
+	if _, ok := key.(*ssh.Certificate); ok {
+		return util.NewInvalidArgumentErrorf("a certificate authority must be a public key, not a certificate")
+	}
+	ca.Content = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+	ca.Fingerprint = ssh.FingerprintSHA256(key)
+
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		has, err := db.GetEngine(ctx).Where("name = ? OR fingerprint = ?", ca.Name, ca.Fingerprint).Exist(new(SSHCertificateAuthority))
+		if err != nil {
+			return err
+		} else if has {
+			return ErrSSHCertificateAuthorityAlreadyExist{Name: ca.Name, Fingerprint: ca.Fingerprint}
+		}
+		return db.Insert(ctx, ca)
+	})
+}
+
+// GetSSHCertificateAuthorityByID returns the certificate authority with the given id
+func GetSSHCertificateAuthorityByID(ctx context.Context, id int64) (*SSHCertificateAuthority, error) {
+	ca := new(SSHCertificateAuthority)
+	has, err := db.GetEngine(ctx).ID(id).Get(ca)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrSSHCertificateAuthorityNotExist{ID: id}
+	}
+	return ca, nil
+}
+
+// GetSSHCertificateAuthorityByFingerprint returns the certificate authority of the given public key fingerprint
+func GetSSHCertificateAuthorityByFingerprint(ctx context.Context, fingerprint string) (*SSHCertificateAuthority, error) {
+	ca := new(SSHCertificateAuthority)
+	has, err := db.GetEngine(ctx).Where("fingerprint = ?", fingerprint).Get(ca)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrSSHCertificateAuthorityNotExist{Fingerprint: fingerprint}
+	}
+	return ca, nil
+}
+
+// FindSSHCertificateAuthorities returns all trusted certificate authorities
+func FindSSHCertificateAuthorities(ctx context.Context) ([]*SSHCertificateAuthority, error) {
+	cas := make([]*SSHCertificateAuthority, 0, 5)
+	return cas, db.GetEngine(ctx).OrderBy("id").Find(&cas)
+}
+
+// DeleteSSHCertificateAuthority stops trusting the certificate authority with the given id
+func DeleteSSHCertificateAuthority(ctx context.Context, id int64) error {
+	n, err := db.GetEngine(ctx).ID(id).Delete(new(SSHCertificateAuthority))
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return ErrSSHCertificateAuthorityNotExist{ID: id}
+	}
+	return nil
+}
+
+// CheckValidityPeriod returns an error if the certificate may be used longer than the authority allows.
+// The validity period itself is checked when the certificate is verified.
+func (ca *SSHCertificateAuthority) CheckValidityPeriod(cert *ssh.Certificate) error {
+	if ca.MaxValidity <= 0 {
+		return nil
+	}
+	if cert.ValidBefore == ssh.CertTimeInfinity || cert.ValidBefore < cert.ValidAfter ||
+		cert.ValidBefore-cert.ValidAfter > uint64(ca.MaxValidity) {
+		return fmt.Errorf("certificate validity period exceeds the maximum of %s allowed by %s", time.Duration(ca.MaxValidity)*time.Second, ca.Name)
+	}
+	return nil
+}
+
+// MapUser returns the user the certificate is mapped to and the principal which was mapped.
+// It returns ErrUserNotExist if the authority does not map certificates or no principal matches an active user.
+func (ca *SSHCertificateAuthority) MapUser(ctx context.Context, cert *ssh.Certificate) (*user_model.User, string, error) {
+	var candidates []string
+	switch ca.Mapping {
+	case SSHCertificateAuthorityMappingUsername, SSHCertificateAuthorityMappingEmail:
+		candidates = cert.ValidPrincipals
+	case SSHCertificateAuthorityMappingKeyID:
+		candidates = []string{cert.KeyId}
+	}
+
+	for _, candidate := range candidates {
+		var u *user_model.User
+		var err error
+		if ca.Mapping == SSHCertificateAuthorityMappingEmail {
+			var email *user_model.EmailAddress
+			email, err = user_model.GetEmailAddressByEmail(ctx, candidate)
+			if err == nil && !email.IsActivated {
+				continue
+			} else if err == nil {
+				u, err = user_model.GetUserByID(ctx, email.UID)
+			} else if user_model.IsErrEmailAddressNotExist(err) {
+				continue
+			}
+		} else {
+			u, err = user_model.GetUserByName(ctx, candidate)
+		}
+		if user_model.IsErrUserNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, "", err
+		}
+		if !u.IsIndividual() || !u.IsActive || u.ProhibitLogin {
+			continue
+		}
+		return u, candidate, nil
+	}
+	return nil, "", user_model.ErrUserNotExist{}
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package asymkey
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"forgejo.org/models/unittest"
+	user_model "forgejo.org/models/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestCertificateAuthorityKey(t *testing.T) string {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	return string(ssh.MarshalAuthorizedKey(sshPub))
+}
+
+func TestAddSSHCertificateAuthority(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	ca := &SSHCertificateAuthority{Name: "ca", Content: newTestCertificateAuthorityKey(t), Mapping: SSHCertificateAuthorityMappingUsername}
+	require.NoError(t, AddSSHCertificateAuthority(t.Context(), ca))
+	assert.NotZero(t, ca.ID)
+	assert.True(t, strings.HasPrefix(ca.Fingerprint, "SHA256:"))
+	assert.False(t, strings.HasSuffix(ca.Content, "\n"))
+
+	byFingerprint, err := GetSSHCertificateAuthorityByFingerprint(t.Context(), ca.Fingerprint)
+	require.NoError(t, err)
+	assert.Equal(t, ca.ID, byFingerprint.ID)
+	assert.Equal(t, SSHCertificateAuthorityMappingUsername, byFingerprint.Mapping)
+
+	t.Run("Duplicate", func(t *testing.T) {
+		err := AddSSHCertificateAuthority(t.Context(), &SSHCertificateAuthority{Name: "other", Content: ca.Content})
+		assert.True(t, IsErrSSHCertificateAuthorityAlreadyExist(err))
+		err = AddSSHCertificateAuthority(t.Context(), &SSHCertificateAuthority{Name: "ca", Content: newTestCertificateAuthorityKey(t)})
+		assert.True(t, IsErrSSHCertificateAuthorityAlreadyExist(err))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		require.Error(t, AddSSHCertificateAuthority(t.Context(), &SSHCertificateAuthority{Name: "invalid", Content: "ssh-ed25519 invalid"}))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require.NoError(t, DeleteSSHCertificateAuthority(t.Context(), ca.ID))
+		_, err := GetSSHCertificateAuthorityByID(t.Context(), ca.ID)
+		assert.True(t, IsErrSSHCertificateAuthorityNotExist(err))
+		assert.True(t, IsErrSSHCertificateAuthorityNotExist(DeleteSSHCertificateAuthority(t.Context(), ca.ID)))
+	})
+}
+
+func TestSSHCertificateAuthorityMapUser(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	test := func(t *testing.T, mapping SSHCertificateAuthorityMapping, cert *ssh.Certificate, expectedUser, expectedPrincipal string) {
+		t.Helper()
+		ca := &SSHCertificateAuthority{Name: "ca", Mapping: mapping}
+		u, principal, err := ca.MapUser(t.Context(), cert)
+		if expectedUser == "" {
+			assert.True(t, user_model.IsErrUserNotExist(err))
+			return
+		}
+		require.NoError(t, err)
+		assert.Equal(t, expectedUser, u.Name)
+		assert.Equal(t, expectedPrincipal, principal)
+	}
+
+	cert := &ssh.Certificate{KeyId: "user5", ValidPrincipals: []string{"unknown", "org3", "user2", "user2@example.com"}}
+	test(t, SSHCertificateAuthorityMappingNone, cert, "", "")
+	test(t, SSHCertificateAuthorityMappingUsername, cert, "user2", "user2")
+	test(t, SSHCertificateAuthorityMappingEmail, cert, "user2", "user2@example.com")
+	test(t, SSHCertificateAuthorityMappingKeyID, cert, "user5", "user5")
+
+	// email addresses must be activated
+	test(t, SSHCertificateAuthorityMappingEmail, &ssh.Certificate{ValidPrincipals: []string{"user11@example.com"}}, "", "")
+	// organizations can not be mapped
+	test(t, SSHCertificateAuthorityMappingKeyID, &ssh.Certificate{KeyId: "org3"}, "", "")
+}
+
+func TestSSHCertificateAuthorityCheckValidityPeriod(t *testing.T) {
+	now := uint64(time.Now().Unix())
+	ca := &SSHCertificateAuthority{Name: "ca"}
+	require.NoError(t, ca.CheckValidityPeriod(&ssh.Certificate{ValidAfter: 0, ValidBefore: ssh.CertTimeInfinity}))
+
+	ca.MaxValidity = 3600
+	require.NoError(t, ca.CheckValidityPeriod(&ssh.Certificate{ValidAfter: now, ValidBefore: now + 3600}))
+	require.Error(t, ca.CheckValidityPeriod(&ssh.Certificate{ValidAfter: now, ValidBefore: now + 3601}))
+	require.Error(t, ca.CheckValidityPeriod(&ssh.Certificate{ValidAfter: now, ValidBefore: ssh.CertTimeInfinity}))
+}
+
+func TestParseSSHCertificateAuthorityMapping(t *testing.T) {
+	for _, mapping := range []SSHCertificateAuthorityMapping{
+		SSHCertificateAuthorityMappingNone,
+		SSHCertificateAuthorityMappingUsername,
+		SSHCertificateAuthorityMappingEmail,
+		SSHCertificateAuthorityMappingKeyID,
+	} {
+		parsed, err := ParseSSHCertificateAuthorityMapping(mapping.String())
+		require.NoError(t, err)
+		assert.Equal(t, mapping, parsed)
+	}
+	_, err := ParseSSHCertificateAuthorityMapping("principal")
+	require.Error(t, err)
+}
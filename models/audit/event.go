@@ -0,0 +1,188 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package audit
+
+import (
+	"context"
+	"time"
+
+	"forgejo.org/models/db"
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// Action is the kind of change an audit event records
+type Action string
+
+const (
+	ActionUserSignIn       Action = "user_sign_in"
+	ActionUserSignInFailed Action = "user_sign_in_failed"
+
+	ActionUserTwoFactorEnable  Action = "user_2fa_enable"
+	ActionUserTwoFactorDisable Action = "user_2fa_disable"
+	ActionUserWebAuthnAdd      Action = "user_webauthn_add"
+	ActionUserWebAuthnRemove   Action = "user_webauthn_remove"
+
+	ActionUserAccessTokenCreate Action = "user_access_token_create"
+	ActionUserAccessTokenDelete Action = "user_access_token_delete"
+
+	ActionUserSessionRevoke Action = "user_session_revoke"
+
+	ActionRepoCollaboratorAdd        Action = "repo_collaborator_add"
+	ActionRepoCollaboratorAccessMode Action = "repo_collaborator_access_mode"
+	ActionRepoCollaboratorRemove     Action = "repo_collaborator_remove"
+
+	ActionRepoBranchProtectionUpdate Action = "repo_branch_protection_update"
+	ActionRepoBranchProtectionDelete Action = "repo_branch_protection_delete"
+
+	ActionRepoVisibility Action = "repo_visibility"
+	ActionRepoTransfer   Action = "repo_transfer"
+	ActionRepoDelete     Action = "repo_delete"
+
+	ActionOrgIPAllowlistUpdate    Action = "org_ip_allowlist_update"
+	ActionOrgTwoFactorRequirement Action = "org_2fa_requirement"
+	ActionOrgMemberRemove         Action = "org_member_remove"
+
+	ActionOrgTeamCreate       Action = "org_team_create"
+	ActionOrgTeamUpdate       Action = "org_team_update"
+	ActionOrgTeamDelete       Action = "org_team_delete"
+	ActionOrgTeamMemberAdd    Action = "org_team_member_add"
+	ActionOrgTeamMemberRemove Action = "org_team_member_remove"
+
+	ActionAdminUserCreate  Action = "admin_user_create"
+	ActionAdminUserUpdate  Action = "admin_user_update"
+	ActionAdminUserDelete  Action = "admin_user_delete"
+	ActionAdminUserSignOut Action = "admin_user_sign_out"
+)
+
+// Actions lists all actions, in the order the viewers offer them as filters
+var Actions = []Action{
+	ActionUserSignIn,
+	ActionUserSignInFailed,
+	ActionUserTwoFactorEnable,
+	ActionUserTwoFactorDisable,
+	ActionUserWebAuthnAdd,
+	ActionUserWebAuthnRemove,
+	ActionUserAccessTokenCreate,
+	ActionUserAccessTokenDelete,
+	ActionUserSessionRevoke,
+	ActionRepoCollaboratorAdd,
+	ActionRepoCollaboratorAccessMode,
+	ActionRepoCollaboratorRemove,
+	ActionRepoBranchProtectionUpdate,
+	ActionRepoBranchProtectionDelete,
+	ActionRepoVisibility,
+	ActionRepoTransfer,
+	ActionRepoDelete,
+	ActionOrgIPAllowlistUpdate,
+	ActionOrgTwoFactorRequirement,
+	ActionOrgMemberRemove,
+	ActionOrgTeamCreate,
+	ActionOrgTeamUpdate,
+	ActionOrgTeamDelete,
+	ActionOrgTeamMemberAdd,
+	ActionOrgTeamMemberRemove,
+	ActionAdminUserCreate,
+	ActionAdminUserUpdate,
+	ActionAdminUserDelete,
+	ActionAdminUserSignOut,
+}
+
+// TargetType is the kind of object an audit event changed
+type TargetType string
+
+const (
+	TargetTypeUser               TargetType = "user"
+	TargetTypeRepository         TargetType = "repository"
+	TargetTypeAccessToken        TargetType = "access_token"
+	TargetTypeProtectedBranch    TargetType = "protected_branch"
+	TargetTypeWebAuthnCredential TargetType = "webauthn_credential"
+	TargetTypeTeam               TargetType = "team"
+)
+
+// Event is a structured record of a security relevant change, who made it from where and what it changed.
+// Unlike system notices, events are not free-text, they can be filtered and exported.
+type Event struct {
+	ID     int64  `xorm:"pk autoincr"`
+	Action Action `xorm:"INDEX NOT NULL"`
+	// ActorID is 0 if nobody is signed in, e.g. for failed sign ins ActorName is the name which was tried
+	ActorID   int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	ActorName string
+	IPAddress string
+	// OwnerID is the user or organization the target belongs to, owners of organizations can view their events
+	OwnerID    int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	RepoID     int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	TargetType TargetType
+	TargetID   int64
+	TargetName string
+	// Before and After are the JSON encoded values the action changed
+	Before      string             `xorm:"TEXT"`
+	After       string             `xorm:"TEXT"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+func init() {
+	db.RegisterModel(new(Event))
+}
+
+// TableName sets the table name to `audit_event`
+func (e *Event) TableName() string {
+	return "audit_event"
+}
+
+// InsertEvent stores an audit event
+func InsertEvent(ctx context.Context, e *Event) error {
+	return db.Insert(ctx, e)
+}
+
+// FindEventsOptions represents the options to find audit events
+type FindEventsOptions struct {
+	db.ListOptions
+	Action    Action
+	ActorID   int64
+	ActorName string
+	OwnerID   int64
+	RepoID    int64
+	Since     timeutil.TimeStamp
+	Before    timeutil.TimeStamp
+}
+
+func (opts FindEventsOptions) ToConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.Action != "" {
+		cond = cond.And(builder.Eq{"action": opts.Action})
+	}
+	if opts.ActorID > 0 {
+		cond = cond.And(builder.Eq{"actor_id": opts.ActorID})
+	}
+	if opts.ActorName != "" {
+		cond = cond.And(builder.Eq{"actor_name": opts.ActorName})
+	}
+	if opts.OwnerID > 0 {
+		cond = cond.And(builder.Eq{"owner_id": opts.OwnerID})
+	}
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if opts.Since > 0 {
+		cond = cond.And(builder.Gte{"created_unix": opts.Since})
+	}
+	if opts.Before > 0 {
+		cond = cond.And(builder.Lt{"created_unix": opts.Before})
+	}
+	return cond
+}
+
+func (opts FindEventsOptions) ToOrders() string {
+	return "id DESC"
+}
+
+// DeleteEventsOlderThan deletes the audit events which were recorded before the retention period
+func DeleteEventsOlderThan(ctx context.Context, olderThan time.Duration) error {
+	_, err := db.GetEngine(ctx).
+		Where(builder.Lt{"created_unix": timeutil.TimeStampNow().AddDuration(-olderThan)}).
+		Delete(new(Event))
+	return err
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package audit_test
+
+import (
+	"testing"
+	"time"
+
+	audit_model "forgejo.org/models/audit"
+	"forgejo.org/models/db"
+	"forgejo.org/models/unittest"
+	"forgejo.org/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindEvents(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	signIn := &audit_model.Event{Action: audit_model.ActionUserSignIn, ActorID: 2, ActorName: "user2", OwnerID: 2}
+	require.NoError(t, audit_model.InsertEvent(db.DefaultContext, signIn))
+	collaborator := &audit_model.Event{Action: audit_model.ActionRepoCollaboratorAdd, ActorID: 2, ActorName: "user2", OwnerID: 3, RepoID: 3}
+	require.NoError(t, audit_model.InsertEvent(db.DefaultContext, collaborator))
+
+	events, err := db.Find[audit_model.Event](db.DefaultContext, audit_model.FindEventsOptions{})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	// the most recent events come first
+	assert.Equal(t, collaborator.ID, events[0].ID)
+
+	events, err = db.Find[audit_model.Event](db.DefaultContext, audit_model.FindEventsOptions{OwnerID: 3})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, collaborator.ID, events[0].ID)
+
+	events, err = db.Find[audit_model.Event](db.DefaultContext, audit_model.FindEventsOptions{Action: audit_model.ActionUserSignIn, ActorName: "user2"})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, signIn.ID, events[0].ID)
+
+	count, err := db.Count[audit_model.Event](db.DefaultContext, audit_model.FindEventsOptions{Since: timeutil.TimeStampNow().Add(60)})
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestDeleteEventsOlderThan(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	old := &audit_model.Event{Action: audit_model.ActionUserSignIn, ActorID: 2, ActorName: "user2", OwnerID: 2}
+	require.NoError(t, audit_model.InsertEvent(db.DefaultContext, old))
+	_, err := db.GetEngine(db.DefaultContext).ID(old.ID).NoAutoTime().Cols("created_unix").
+		Update(&audit_model.Event{CreatedUnix: timeutil.TimeStampNow().AddDuration(-48 * time.Hour)})
+	require.NoError(t, err)
+	recent := &audit_model.Event{Action: audit_model.ActionUserSignIn, ActorID: 2, ActorName: "user2", OwnerID: 2}
+	require.NoError(t, audit_model.InsertEvent(db.DefaultContext, recent))
+
+	require.NoError(t, audit_model.DeleteEventsOlderThan(db.DefaultContext, 24*time.Hour))
+	unittest.AssertNotExistsBean(t, &audit_model.Event{ID: old.ID})
+	unittest.AssertExistsAndLoadBean(t, &audit_model.Event{ID: recent.ID})
+}
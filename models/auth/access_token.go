@@ -9,6 +9,9 @@ import (
 	"crypto/subtle"
 	"encoding/hex"
 	"fmt"
+	"hash/crc32"
+	"slices"
+	"strings"
 	"time"
 
 	"forgejo.org/models/db"
@@ -69,6 +72,16 @@ type AccessToken struct {
 	TokenLastEight string `xorm:"INDEX token_last_eight"`
 	Scope          AccessTokenScope
 
+	// FineGrained tokens only access the repositories of ResourceOwnerID, or the repositories
+	// RepoIDs if it is 0, with RepoPermissions until they expire.
+	FineGrained     bool                       `xorm:"NOT NULL DEFAULT false"`
+	ResourceOwnerID int64                      `xorm:"NOT NULL DEFAULT 0"`
+	RepoIDs         []int64                    `xorm:"JSON TEXT"`
+	RepoPermissions AccessTokenRepoPermissions `xorm:"TEXT"`
+	ExpiresUnix     timeutil.TimeStamp         `xorm:"INDEX NOT NULL DEFAULT 0"`
+	// InstallationID is set for the tokens minted by applications for one of their installations
+	InstallationID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+
 	CreatedUnix       timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix       timeutil.TimeStamp `xorm:"INDEX updated"`
 	HasRecentActivity bool               `xorm:"-"`
@@ -81,6 +94,22 @@ func (t *AccessToken) AfterLoad() {
 	t.HasRecentActivity = t.UpdatedUnix.AddDuration(7*24*time.Hour) > timeutil.TimeStampNow()
 }
 
+// IsExpired returns whether the token can no longer be used
+func (t *AccessToken) IsExpired() bool {
+	return t.ExpiresUnix > 0 && t.ExpiresUnix <= timeutil.TimeStampNow()
+}
+
+// CanAccessRepo returns whether the token may access the repository, only fine-grained tokens are limited
+func (t *AccessToken) CanAccessRepo(repoID, ownerID int64) bool {
+	if !t.FineGrained {
+		return true
+	}
+	if t.ResourceOwnerID > 0 {
+		return t.ResourceOwnerID == ownerID
+	}
+	return slices.Contains(t.RepoIDs, repoID)
+}
+
 func init() {
 	db.RegisterModel(new(AccessToken), func() error {
 		if setting.SuccessfulTokensCacheSize > 0 {
@@ -98,6 +127,18 @@ func init() {
 
 // NewAccessToken creates new access token.
 func NewAccessToken(ctx context.Context, t *AccessToken) error {
+	if t.FineGrained {
+		if t.ExpiresUnix <= timeutil.TimeStampNow() {
+			return util.NewInvalidArgumentErrorf("fine-grained access tokens must expire in the future")
+		}
+		if t.ResourceOwnerID <= 0 && len(t.RepoIDs) == 0 {
+			return util.NewInvalidArgumentErrorf("fine-grained access tokens must be limited to an owner or repositories")
+		}
+		t.Scope = t.RepoPermissions.Scope()
+		if t.Scope == "" {
+			return util.NewInvalidArgumentErrorf("fine-grained access tokens must have at least one permission")
+		}
+	}
 	err := generateAccessToken(t)
 	if err != nil {
 		return err
@@ -106,13 +147,41 @@ func NewAccessToken(ctx context.Context, t *AccessToken) error {
 	return err
 }
 
+// AccessTokenPrefix starts the access tokens so that they can be recognized when they leak,
+// it is followed by 40 random hexadecimal characters and the 8 hexadecimal characters of their checksum.
+const AccessTokenPrefix = "gtp_"
+
+func accessTokenChecksum(random string) string {
+	return fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(AccessTokenPrefix+random)))
+}
+
+// IsAccessTokenFormat returns whether the value has the format of an access token and a valid checksum,
+// it tells values which only look like access tokens apart without querying the database.
+func IsAccessTokenFormat(token string) bool {
+	random, ok := strings.CutPrefix(token, AccessTokenPrefix)
+	if !ok || len(random) != 48 || !isLowerHex(random) {
+		return false
+	}
+	return accessTokenChecksum(random[:40]) == random[40:]
+}
+
+func isLowerHex(s string) bool {
+	for _, x := range []byte(s) {
+		if x < '0' || (x > '9' && x < 'a') || x > 'f' {
+			return false
+		}
+	}
+	return true
+}
+
 func generateAccessToken(t *AccessToken) error {
 	salt, err := util.CryptoRandomString(10)
 	if err != nil {
 		return err
 	}
 	t.TokenSalt = salt
-	t.Token = hex.EncodeToString(util.CryptoRandomBytes(20))
+	random := hex.EncodeToString(util.CryptoRandomBytes(20))
+	t.Token = AccessTokenPrefix + random + accessTokenChecksum(random)
 	t.TokenHash = HashToken(t.Token, t.TokenSalt)
 	t.TokenLastEight = t.Token[len(t.Token)-8:]
 	return nil
@@ -147,14 +216,13 @@ func GetAccessTokenBySHA(ctx context.Context, token string) (*AccessToken, error
 	if token == "" {
 		return nil, ErrAccessTokenEmpty{}
 	}
-	// A token is defined as being SHA1 sum these are 40 hexadecimal bytes long
-	if len(token) != 40 {
-		return nil, ErrAccessTokenNotExist{token}
-	}
-	for _, x := range []byte(token) {
-		if x < '0' || (x > '9' && x < 'a') || x > 'f' {
+	// Tokens are prefixed and end with a checksum, the tokens generated before were 40 hexadecimal bytes long
+	if strings.HasPrefix(token, AccessTokenPrefix) {
+		if !IsAccessTokenFormat(token) {
 			return nil, ErrAccessTokenNotExist{token}
 		}
+	} else if len(token) != 40 || !isLowerHex(token) {
+		return nil, ErrAccessTokenNotExist{token}
 	}
 
 	lastEight := token[len(token)-8:]
@@ -169,6 +237,9 @@ func GetAccessTokenBySHA(ctx context.Context, token string) (*AccessToken, error
 			return nil, err
 		}
 		if has {
+			if accessToken.IsExpired() {
+				return nil, ErrAccessTokenNotExist{token}
+			}
 			return accessToken, nil
 		}
 		successfulAccessTokenCache.Remove(token)
@@ -185,6 +256,9 @@ func GetAccessTokenBySHA(ctx context.Context, token string) (*AccessToken, error
 	for _, t := range tokens {
 		tempHash := HashToken(token, t.TokenSalt)
 		if subtle.ConstantTimeCompare([]byte(t.TokenHash), []byte(tempHash)) == 1 {
+			if t.IsExpired() {
+				return nil, ErrAccessTokenNotExist{token}
+			}
 			if successfulAccessTokenCache != nil {
 				successfulAccessTokenCache.Add(token, t.ID)
 			}
@@ -0,0 +1,173 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"forgejo.org/models/perm"
+	"forgejo.org/models/unit"
+)
+
+// AccessTokenRepoPermission is a permission fine-grained access tokens have in the repositories they are limited to
+type AccessTokenRepoPermission string
+
+const (
+	AccessTokenRepoPermissionContents     AccessTokenRepoPermission = "contents"
+	AccessTokenRepoPermissionIssues       AccessTokenRepoPermission = "issues"
+	AccessTokenRepoPermissionPullRequests AccessTokenRepoPermission = "pull_requests"
+	AccessTokenRepoPermissionStatuses     AccessTokenRepoPermission = "statuses"
+	AccessTokenRepoPermissionReleases     AccessTokenRepoPermission = "releases"
+	AccessTokenRepoPermissionWiki         AccessTokenRepoPermission = "wiki"
+)
+
+// AllAccessTokenRepoPermissions contains all permissions of fine-grained access tokens
+var AllAccessTokenRepoPermissions = []AccessTokenRepoPermission{
+	AccessTokenRepoPermissionContents,
+	AccessTokenRepoPermissionIssues,
+	AccessTokenRepoPermissionPullRequests,
+	AccessTokenRepoPermissionStatuses,
+	AccessTokenRepoPermissionReleases,
+	AccessTokenRepoPermissionWiki,
+}
+
+// units returns the repository units a permission grants access to
+func (p AccessTokenRepoPermission) units() []unit.Type {
+	switch p {
+	case AccessTokenRepoPermissionContents:
+		return []unit.Type{unit.TypeCode}
+	case AccessTokenRepoPermissionIssues:
+		return []unit.Type{unit.TypeIssues}
+	case AccessTokenRepoPermissionPullRequests:
+		return []unit.Type{unit.TypePullRequests}
+	case AccessTokenRepoPermissionStatuses:
+		// commit statuses are read and written next to the commits they belong to
+		return []unit.Type{unit.TypeCode}
+	case AccessTokenRepoPermissionReleases:
+		return []unit.Type{unit.TypeReleases}
+	case AccessTokenRepoPermissionWiki:
+		return []unit.Type{unit.TypeWiki}
+	}
+	return nil
+}
+
+// AccessTokenRepoPermissions is a comma separated list of the permissions of a fine-grained access token
+// prefixed with their level, e.g. "read:contents,write:statuses"
+type AccessTokenRepoPermissions string
+
+var accessTokenScopeLevelNames = map[AccessTokenScopeLevel]string{
+	Read:  "read",
+	Write: "write",
+}
+
+// NewAccessTokenRepoPermissions returns the permissions of the given levels, permissions without access are omitted
+func NewAccessTokenRepoPermissions(levels map[AccessTokenRepoPermission]AccessTokenScopeLevel) AccessTokenRepoPermissions {
+	permissions := make([]string, 0, len(levels))
+	for _, permission := range AllAccessTokenRepoPermissions {
+		if name, ok := accessTokenScopeLevelNames[levels[permission]]; ok {
+			permissions = append(permissions, name+":"+string(permission))
+		}
+	}
+	return AccessTokenRepoPermissions(strings.Join(permissions, ","))
+}
+
+// ParseAccessTokenRepoPermissions parses and validates permissions
+func ParseAccessTokenRepoPermissions(s string) (AccessTokenRepoPermissions, error) {
+	levels := make(map[AccessTokenRepoPermission]AccessTokenScopeLevel)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		levelName, name, ok := strings.Cut(item, ":")
+		permission := AccessTokenRepoPermission(name)
+		if !ok || !slices.Contains(AllAccessTokenRepoPermissions, permission) {
+			return "", fmt.Errorf("invalid access token repository permission: %s", item)
+		}
+		switch levelName {
+		case "read":
+			levels[permission] = max(levels[permission], Read)
+		case "write":
+			levels[permission] = Write
+		default:
+			return "", fmt.Errorf("invalid access token repository permission level: %s", item)
+		}
+	}
+	return NewAccessTokenRepoPermissions(levels), nil
+}
+
+// Level returns the level of the permission
+func (p AccessTokenRepoPermissions) Level(permission AccessTokenRepoPermission) AccessTokenScopeLevel {
+	level := NoAccess
+	for _, item := range strings.Split(string(p), ",") {
+		levelName, name, _ := strings.Cut(item, ":")
+		if AccessTokenRepoPermission(name) != permission {
+			continue
+		}
+		for l, n := range accessTokenScopeLevelNames {
+			if n == levelName {
+				level = max(level, l)
+			}
+		}
+	}
+	return level
+}
+
+// UnitAccessMode returns the highest access to the repository unit the permissions allow
+func (p AccessTokenRepoPermissions) UnitAccessMode(unitType unit.Type) perm.AccessMode {
+	mode := perm.AccessModeNone
+	for _, permission := range AllAccessTokenRepoPermissions {
+		if !slices.Contains(permission.units(), unitType) {
+			continue
+		}
+		level := p.Level(permission)
+		if permission == AccessTokenRepoPermissionStatuses {
+			// writing commit statuses is checked separately and must not allow pushing
+			level = min(level, Read)
+		}
+		switch level {
+		case Read:
+			mode = max(mode, perm.AccessModeRead)
+		case Write:
+			mode = max(mode, perm.AccessModeWrite)
+		}
+	}
+	return mode
+}
+
+// Scope returns the scope of a token with the permissions, the permissions are then enforced per repository unit
+func (p AccessTokenRepoPermissions) Scope() AccessTokenScope {
+	repository, issue := NoAccess, NoAccess
+	for _, permission := range AllAccessTokenRepoPermissions {
+		level := p.Level(permission)
+		switch permission {
+		case AccessTokenRepoPermissionIssues:
+			issue = max(issue, level)
+		case AccessTokenRepoPermissionPullRequests:
+			// pull requests are commented on like issues
+			issue = max(issue, level)
+			repository = max(repository, level)
+		default:
+			repository = max(repository, level)
+		}
+	}
+	scopes := make([]string, 0, 2)
+	if repository != NoAccess {
+		scopes = append(scopes, string(accessTokenScopes[repository][AccessTokenScopeCategoryRepository]))
+	}
+	if issue != NoAccess {
+		scopes = append(scopes, string(accessTokenScopes[issue][AccessTokenScopeCategoryIssue]))
+	}
+	return AccessTokenScope(strings.Join(scopes, ","))
+}
+
+// StringSlice returns the permissions as a slice of strings
+func (p AccessTokenRepoPermissions) StringSlice() []string {
+	if p == "" {
+		return nil
+	}
+	return strings.Split(string(p), ",")
+}
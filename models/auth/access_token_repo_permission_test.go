@@ -0,0 +1,50 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth
+
+import (
+	"testing"
+
+	"forgejo.org/models/perm"
+	"forgejo.org/models/unit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAccessTokenRepoPermissions(t *testing.T) {
+	permissions, err := ParseAccessTokenRepoPermissions("write:statuses, read:contents,,read:statuses")
+	require.NoError(t, err)
+	assert.Equal(t, AccessTokenRepoPermissions("read:contents,write:statuses"), permissions)
+
+	permissions, err = ParseAccessTokenRepoPermissions("")
+	require.NoError(t, err)
+	assert.Empty(t, permissions)
+
+	for _, invalid := range []string{"contents", "read:admin", "admin:contents"} {
+		_, err := ParseAccessTokenRepoPermissions(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestAccessTokenRepoPermissions(t *testing.T) {
+	permissions := NewAccessTokenRepoPermissions(map[AccessTokenRepoPermission]AccessTokenScopeLevel{
+		AccessTokenRepoPermissionIssues:   Write,
+		AccessTokenRepoPermissionStatuses: Write,
+		AccessTokenRepoPermissionWiki:     NoAccess,
+	})
+	assert.Equal(t, AccessTokenRepoPermissions("write:issues,write:statuses"), permissions)
+	assert.Equal(t, Write, permissions.Level(AccessTokenRepoPermissionIssues))
+	assert.Equal(t, NoAccess, permissions.Level(AccessTokenRepoPermissionContents))
+
+	// writing commit statuses only allows to read the code
+	assert.Equal(t, perm.AccessModeRead, permissions.UnitAccessMode(unit.TypeCode))
+	assert.Equal(t, perm.AccessModeWrite, permissions.UnitAccessMode(unit.TypeIssues))
+	assert.Equal(t, perm.AccessModeNone, permissions.UnitAccessMode(unit.TypePullRequests))
+	assert.Equal(t, perm.AccessModeNone, permissions.UnitAccessMode(unit.TypeProjects))
+
+	assert.Equal(t, AccessTokenScope("write:repository,write:issue"), permissions.Scope())
+	assert.Equal(t, AccessTokenScope("read:repository,read:issue"), AccessTokenRepoPermissions("read:pull_requests").Scope())
+	assert.Empty(t, AccessTokenRepoPermissions("").Scope())
+}
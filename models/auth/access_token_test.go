@@ -5,10 +5,12 @@ package auth_test
 
 import (
 	"testing"
+	"time"
 
 	auth_model "forgejo.org/models/auth"
 	"forgejo.org/models/db"
 	"forgejo.org/models/unittest"
+	"forgejo.org/modules/timeutil"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,6 +24,11 @@ func TestNewAccessToken(t *testing.T) {
 	}
 	require.NoError(t, auth_model.NewAccessToken(db.DefaultContext, token))
 	unittest.AssertExistsAndLoadBean(t, token)
+	assert.True(t, auth_model.IsAccessTokenFormat(token.Token))
+
+	found, err := auth_model.GetAccessTokenBySHA(db.DefaultContext, token.Token)
+	require.NoError(t, err)
+	assert.Equal(t, token.ID, found.ID)
 
 	invalidToken := &auth_model.AccessToken{
 		ID:   token.ID, // duplicate
@@ -84,6 +91,15 @@ func TestGetAccessTokenBySHA(t *testing.T) {
 	assert.True(t, auth_model.IsErrAccessTokenEmpty(err))
 }
 
+func TestIsAccessTokenFormat(t *testing.T) {
+	assert.True(t, auth_model.IsAccessTokenFormat("gtp_d2c6c1ba3890b309189a8e618c72a162e4efbf36f4349638"))
+	// the checksum does not match
+	assert.False(t, auth_model.IsAccessTokenFormat("gtp_d2c6c1ba3890b309189a8e618c72a162e4efbf3600000000"))
+	assert.False(t, auth_model.IsAccessTokenFormat("d2c6c1ba3890b309189a8e618c72a162e4efbf36"))
+	assert.False(t, auth_model.IsAccessTokenFormat("gtp_d2c6c1ba3890b309189a8e618c72a162e4efbf36"))
+	assert.False(t, auth_model.IsAccessTokenFormat("gtp_D2C6C1BA3890B309189A8E618C72A162E4EFBF36F4349638"))
+}
+
 func TestListAccessTokens(t *testing.T) {
 	require.NoError(t, unittest.PrepareTestDatabase())
 	tokens, err := db.Find[auth_model.AccessToken](db.DefaultContext, auth_model.ListAccessTokensOptions{UserID: 1})
@@ -156,3 +172,43 @@ func TestRegenerateAccessTokenByID(t *testing.T) {
 	assert.Equal(t, token.Name, newToken.Name)
 	assert.Equal(t, token.Scope, newToken.Scope)
 }
+
+func TestNewFineGrainedAccessToken(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	token := &auth_model.AccessToken{
+		UID:             2,
+		Name:            "Fine-grained",
+		FineGrained:     true,
+		RepoIDs:         []int64{1},
+		RepoPermissions: "read:contents,write:issues",
+		ExpiresUnix:     timeutil.TimeStampNow().AddDuration(time.Hour),
+	}
+	require.NoError(t, auth_model.NewAccessToken(db.DefaultContext, token))
+	assert.Equal(t, auth_model.AccessTokenScope("read:repository,write:issue"), token.Scope)
+
+	loaded, err := auth_model.GetAccessTokenBySHA(db.DefaultContext, token.Token)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, loaded.RepoIDs)
+	assert.True(t, loaded.CanAccessRepo(1, 2))
+	assert.False(t, loaded.CanAccessRepo(2, 2))
+
+	t.Run("Expired", func(t *testing.T) {
+		loaded.ExpiresUnix = timeutil.TimeStampNow().AddDuration(-time.Hour)
+		require.NoError(t, auth_model.UpdateAccessToken(db.DefaultContext, loaded))
+		_, err := auth_model.GetAccessTokenBySHA(db.DefaultContext, token.Token)
+		assert.True(t, auth_model.IsErrAccessTokenNotExist(err))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, token := range []*auth_model.AccessToken{
+			// no expiry
+			{UID: 2, Name: "no expiry", FineGrained: true, RepoIDs: []int64{1}, RepoPermissions: "read:contents"},
+			// no repositories
+			{UID: 2, Name: "no repositories", FineGrained: true, RepoPermissions: "read:contents", ExpiresUnix: timeutil.TimeStampNow().AddDuration(time.Hour)},
+			// no permissions
+			{UID: 2, Name: "no permissions", FineGrained: true, ResourceOwnerID: 3, ExpiresUnix: timeutil.TimeStampNow().AddDuration(time.Hour)},
+		} {
+			require.Error(t, auth_model.NewAccessToken(db.DefaultContext, token), token.Name)
+		}
+	})
+}
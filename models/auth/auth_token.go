@@ -12,6 +12,7 @@ import (
 
 	"forgejo.org/models/db"
 	"forgejo.org/modules/timeutil"
+	"forgejo.org/modules/useragent"
 	"forgejo.org/modules/util"
 )
 
@@ -41,6 +42,12 @@ type AuthorizationToken struct {
 	HashedValidator string
 	Purpose         AuthorizationPurpose `xorm:"NOT NULL DEFAULT 'long_term_authorization'"`
 	Expiry          timeutil.TimeStamp
+
+	// The client which last used a long term authorization token
+	IP           string             `xorm:"VARCHAR(64)"`
+	UserAgent    string             `xorm:"TEXT"`
+	CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+	LastUsedUnix timeutil.TimeStamp
 }
 
 // TableName provides the real table name.
@@ -105,6 +112,36 @@ func DeleteAuthTokenByUser(ctx context.Context, userID int64) error {
 	return err
 }
 
+// Device returns a description of the device which last used the token
+func (authToken *AuthorizationToken) Device() string {
+	return useragent.Device(authToken.UserAgent)
+}
+
+// UpdateAuthTokenClient remembers the client which last used the long term authorization token
+func UpdateAuthTokenClient(ctx context.Context, lookupKey, ip, userAgent string) error {
+	_, err := db.GetEngine(ctx).Where("lookup_key = ?", lookupKey).Cols("ip", "user_agent", "last_used_unix").Update(&AuthorizationToken{
+		IP:           ip,
+		UserAgent:    userAgent,
+		LastUsedUnix: timeutil.TimeStampNow(),
+	})
+	return err
+}
+
+// FindLongTermAuthTokens returns the long term authorization tokens of a user which did not expire
+func FindLongTermAuthTokens(ctx context.Context, userID int64) ([]*AuthorizationToken, error) {
+	tokens := make([]*AuthorizationToken, 0, 5)
+	return tokens, db.GetEngine(ctx).
+		Where("uid = ? AND purpose = ? AND expiry > ?", userID, LongTermAuthorization, timeutil.TimeStampNow()).
+		OrderBy("last_used_unix DESC").
+		Find(&tokens)
+}
+
+// DeleteLongTermAuthToken revokes a long term authorization token of a user
+func DeleteLongTermAuthToken(ctx context.Context, userID, id int64) error {
+	_, err := db.GetEngine(ctx).ID(id).Delete(&AuthorizationToken{UID: userID, Purpose: LongTermAuthorization})
+	return err
+}
+
 // HashValidator will return a hexified hashed version of the validator.
 func HashValidator(validator []byte) string {
 	h := sha256.New()
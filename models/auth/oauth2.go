@@ -37,16 +37,23 @@ type OAuth2Application struct {
 	// https://datatracker.ietf.org/doc/html/rfc6749#section-2.1
 	// "Authorization servers MUST record the client type in the client registration details"
 	// https://datatracker.ietf.org/doc/html/rfc8252#section-8.4
-	ConfidentialClient bool               `xorm:"NOT NULL DEFAULT TRUE"`
-	RedirectURIs       []string           `xorm:"redirect_uris JSON TEXT"`
-	CreatedUnix        timeutil.TimeStamp `xorm:"INDEX created"`
-	UpdatedUnix        timeutil.TimeStamp `xorm:"INDEX updated"`
+	ConfidentialClient bool     `xorm:"NOT NULL DEFAULT TRUE"`
+	RedirectURIs       []string `xorm:"redirect_uris JSON TEXT"`
+	// BotUserID is the bot acting for the application in its installations, 0 if it can not be installed
+	BotUserID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	// AppPermissions are requested from the owners installing the application
+	AppPermissions AccessTokenRepoPermissions `xorm:"TEXT"`
+	// AppPublicKey verifies the JWTs the application signs to mint installation tokens
+	AppPublicKey string             `xorm:"TEXT"`
+	CreatedUnix  timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix  timeutil.TimeStamp `xorm:"INDEX updated"`
 }
 
 func init() {
 	db.RegisterModel(new(OAuth2Application))
 	db.RegisterModel(new(OAuth2AuthorizationCode))
 	db.RegisterModel(new(OAuth2Grant))
+	db.RegisterModel(new(OAuth2ApplicationInstallation))
 }
 
 type BuiltinOAuth2Application struct {
@@ -350,7 +357,10 @@ func deleteOAuth2Application(ctx context.Context, id, userid int64) error {
 	if _, err := sess.Where("application_id = ?", id).Delete(new(OAuth2Grant)); err != nil {
 		return err
 	}
-	return nil
+	if _, err := sess.Where("application_id = ?", id).Delete(new(OAuth2DeviceAuthorization)); err != nil {
+		return err
+	}
+	return deleteOAuth2ApplicationInstallations(ctx, builder.Eq{"app_id": id})
 }
 
 // DeleteOAuth2Application deletes the application with the given id and the grants and auth codes related to it. It checks if the userid was the creator of the app.
@@ -633,6 +643,18 @@ func DeleteOAuth2RelictsByUserID(ctx context.Context, userID int64) error {
 		return err
 	}
 
+	if _, err := db.GetEngine(ctx).In("grant_id", deleteCond).
+		Delete(&OAuth2DeviceAuthorization{}); err != nil {
+		return err
+	}
+
+	if err := deleteOAuth2ApplicationInstallations(ctx, builder.Or(
+		builder.Eq{"owner_id": userID},
+		builder.In("app_id", builder.Select("id").From("oauth2_application").Where(builder.Eq{"uid": userID})),
+	)); err != nil {
+		return err
+	}
+
 	if err := db.DeleteBeans(ctx,
 		&OAuth2Application{UID: userID},
 		&OAuth2Grant{UserID: userID},
@@ -0,0 +1,239 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"forgejo.org/models/db"
+	"forgejo.org/modules/timeutil"
+	"forgejo.org/modules/util"
+
+	"xorm.io/builder"
+)
+
+// InstallationTokenLifetime is how long the tokens minted for installations can be used
+const InstallationTokenLifetime = time.Hour
+
+// OAuth2ApplicationInstallation grants an application access to the repositories of an owner
+type OAuth2ApplicationInstallation struct {
+	ID      int64 `xorm:"pk autoincr"`
+	AppID   int64 `xorm:"UNIQUE(s) NOT NULL"`
+	OwnerID int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	// RepoIDs limits the installation to some repositories of the owner, it is empty for all of them
+	RepoIDs []int64 `xorm:"JSON TEXT"`
+	// Permissions are the permissions the application requested when it was installed
+	Permissions AccessTokenRepoPermissions `xorm:"TEXT"`
+	InstallerID int64                      `xorm:"NOT NULL DEFAULT 0"`
+
+	App *OAuth2Application `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// TableName sets the table name to `oauth2_application_installation`
+func (inst *OAuth2ApplicationInstallation) TableName() string {
+	return "oauth2_application_installation"
+}
+
+// ErrOAuth2ApplicationInstallationNotExist represents a "OAuth2ApplicationInstallationNotExist" kind of error.
+type ErrOAuth2ApplicationInstallationNotExist struct {
+	ID int64
+}
+
+// IsErrOAuth2ApplicationInstallationNotExist checks if an error is a ErrOAuth2ApplicationInstallationNotExist.
+func IsErrOAuth2ApplicationInstallationNotExist(err error) bool {
+	_, ok := err.(ErrOAuth2ApplicationInstallationNotExist)
+	return ok
+}
+
+func (err ErrOAuth2ApplicationInstallationNotExist) Error() string {
+	return fmt.Sprintf("oauth2 application installation does not exist [id: %d]", err.ID)
+}
+
+func (err ErrOAuth2ApplicationInstallationNotExist) Unwrap() error {
+	return util.ErrNotExist
+}
+
+// IsInstallable returns whether owners can install the application
+func (app *OAuth2Application) IsInstallable() bool {
+	return app.BotUserID > 0 && app.AppPermissions != ""
+}
+
+// CoversRepo returns whether the application may access the repository through the installation
+func (inst *OAuth2ApplicationInstallation) CoversRepo(repoID, ownerID int64) bool {
+	if inst.OwnerID != ownerID {
+		return false
+	}
+	return len(inst.RepoIDs) == 0 || slices.Contains(inst.RepoIDs, repoID)
+}
+
+// LoadApp loads the installed application
+func (inst *OAuth2ApplicationInstallation) LoadApp(ctx context.Context) (err error) {
+	if inst.App == nil {
+		inst.App, err = GetOAuth2ApplicationByID(ctx, inst.AppID)
+	}
+	return err
+}
+
+// CreateOAuth2ApplicationInstallation installs an application, or updates the repositories and
+// permissions of its existing installation on the owner
+func CreateOAuth2ApplicationInstallation(ctx context.Context, inst *OAuth2ApplicationInstallation) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		existing := new(OAuth2ApplicationInstallation)
+		has, err := db.GetEngine(ctx).Where("app_id = ? AND owner_id = ?", inst.AppID, inst.OwnerID).Get(existing)
+		if err != nil {
+			return err
+		} else if !has {
+			return db.Insert(ctx, inst)
+		}
+		inst.ID = existing.ID
+		if _, err := db.GetEngine(ctx).ID(inst.ID).Cols("repo_ids", "permissions", "installer_id").Update(inst); err != nil {
+			return err
+		}
+		// the tokens were minted for the previous repositories and permissions
+		_, err = db.GetEngine(ctx).Where("installation_id = ?", inst.ID).Delete(new(AccessToken))
+		return err
+	})
+}
+
+// GetOAuth2ApplicationInstallationByID returns the installation with the given id
+func GetOAuth2ApplicationInstallationByID(ctx context.Context, id int64) (*OAuth2ApplicationInstallation, error) {
+	inst := new(OAuth2ApplicationInstallation)
+	has, err := db.GetEngine(ctx).ID(id).Get(inst)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrOAuth2ApplicationInstallationNotExist{ID: id}
+	}
+	return inst, nil
+}
+
+// FindOAuth2ApplicationInstallationsOptions represents the options to find installations
+type FindOAuth2ApplicationInstallationsOptions struct {
+	db.ListOptions
+	AppID   int64
+	OwnerID int64
+}
+
+func (opts FindOAuth2ApplicationInstallationsOptions) ToConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.AppID > 0 {
+		cond = cond.And(builder.Eq{"app_id": opts.AppID})
+	}
+	if opts.OwnerID > 0 {
+		cond = cond.And(builder.Eq{"owner_id": opts.OwnerID})
+	}
+	return cond
+}
+
+func (opts FindOAuth2ApplicationInstallationsOptions) ToOrders() string {
+	return "id"
+}
+
+// GetBotRepoInstallation returns the installation of the application acting as the bot which covers the repository,
+// it returns nil if the application is not installed for the repository. isAppBot is false if the bot does not act
+// for any application.
+func GetBotRepoInstallation(ctx context.Context, botUserID, repoID, ownerID int64) (inst *OAuth2ApplicationInstallation, isAppBot bool, err error) {
+	app := new(OAuth2Application)
+	has, err := db.GetEngine(ctx).Where("bot_user_id = ?", botUserID).Get(app)
+	if err != nil || !has {
+		return nil, false, err
+	}
+	inst = new(OAuth2ApplicationInstallation)
+	has, err = db.GetEngine(ctx).Where("app_id = ? AND owner_id = ?", app.ID, ownerID).Get(inst)
+	if err != nil {
+		return nil, true, err
+	} else if !has || !inst.CoversRepo(repoID, ownerID) {
+		return nil, true, nil
+	}
+	inst.App = app
+	return inst, true, nil
+}
+
+// FindRepoInstallationBotUserIDs returns the bots of the applications installed for the repository,
+// only applications installed for all repositories of the owner are returned if repoID is 0
+func FindRepoInstallationBotUserIDs(ctx context.Context, repoID, ownerID int64) ([]int64, error) {
+	insts := make([]*OAuth2ApplicationInstallation, 0, 5)
+	if err := db.GetEngine(ctx).Where("owner_id = ?", ownerID).Find(&insts); err != nil {
+		return nil, err
+	}
+	appIDs := make([]int64, 0, len(insts))
+	for _, inst := range insts {
+		if repoID == 0 && len(inst.RepoIDs) > 0 || repoID != 0 && !inst.CoversRepo(repoID, ownerID) {
+			continue
+		}
+		appIDs = append(appIDs, inst.AppID)
+	}
+	if len(appIDs) == 0 {
+		return nil, nil
+	}
+	botUserIDs := make([]int64, 0, len(appIDs))
+	return botUserIDs, db.GetEngine(ctx).Table("oauth2_application").
+		In("id", appIDs).And("bot_user_id > 0").Cols("bot_user_id").Find(&botUserIDs)
+}
+
+// DeleteOAuth2ApplicationInstallation uninstalls an application and revokes the tokens of the installation
+func DeleteOAuth2ApplicationInstallation(ctx context.Context, id, ownerID int64) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		n, err := db.GetEngine(ctx).Where("id = ? AND owner_id = ?", id, ownerID).Delete(new(OAuth2ApplicationInstallation))
+		if err != nil {
+			return err
+		} else if n == 0 {
+			return ErrOAuth2ApplicationInstallationNotExist{ID: id}
+		}
+		_, err = db.GetEngine(ctx).Where("installation_id = ?", id).Delete(new(AccessToken))
+		return err
+	})
+}
+
+// deleteOAuth2ApplicationInstallations uninstalls the applications matching the condition and revokes their tokens
+func deleteOAuth2ApplicationInstallations(ctx context.Context, cond builder.Cond) error {
+	if _, err := db.GetEngine(ctx).
+		In("installation_id", builder.Select("id").From("oauth2_application_installation").Where(cond)).
+		Delete(new(AccessToken)); err != nil {
+		return err
+	}
+	_, err := db.GetEngine(ctx).Where(cond).Delete(new(OAuth2ApplicationInstallation))
+	return err
+}
+
+// CreateInstallationToken mints a short-lived token for the bot of the application,
+// limited to the repositories and permissions of the installation
+func CreateInstallationToken(ctx context.Context, inst *OAuth2ApplicationInstallation) (*AccessToken, error) {
+	if err := inst.LoadApp(ctx); err != nil {
+		return nil, err
+	}
+	if !inst.App.IsInstallable() {
+		return nil, util.NewInvalidArgumentErrorf("the application can not be installed")
+	}
+
+	// the tokens of the installation are only kept until they expire
+	if _, err := db.GetEngine(ctx).Where("installation_id = ? AND expires_unix <= ?", inst.ID, timeutil.TimeStampNow()).
+		Delete(new(AccessToken)); err != nil {
+		return nil, err
+	}
+
+	suffix, err := util.CryptoRandomString(8)
+	if err != nil {
+		return nil, err
+	}
+	token := &AccessToken{
+		UID:             inst.App.BotUserID,
+		Name:            fmt.Sprintf("installation-%d-%s", inst.ID, suffix),
+		FineGrained:     true,
+		RepoIDs:         inst.RepoIDs,
+		RepoPermissions: inst.Permissions,
+		ExpiresUnix:     timeutil.TimeStampNow().AddDuration(InstallationTokenLifetime),
+		InstallationID:  inst.ID,
+	}
+	if len(inst.RepoIDs) == 0 {
+		token.ResourceOwnerID = inst.OwnerID
+	}
+	return token, NewAccessToken(ctx, token)
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth_test
+
+import (
+	"testing"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	"forgejo.org/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuth2ApplicationInstallation(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	app := unittest.AssertExistsAndLoadBean(t, &auth_model.OAuth2Application{ID: 1})
+	app.BotUserID = 1000
+	app.AppPermissions = "read:contents,write:issues"
+	_, err := db.GetEngine(db.DefaultContext).ID(app.ID).Cols("bot_user_id", "app_permissions").Update(app)
+	require.NoError(t, err)
+	assert.True(t, app.IsInstallable())
+
+	inst := &auth_model.OAuth2ApplicationInstallation{
+		AppID:       app.ID,
+		OwnerID:     2,
+		RepoIDs:     []int64{1},
+		Permissions: app.AppPermissions,
+		InstallerID: 2,
+	}
+	require.NoError(t, auth_model.CreateOAuth2ApplicationInstallation(db.DefaultContext, inst))
+	assert.True(t, inst.CoversRepo(1, 2))
+	assert.False(t, inst.CoversRepo(2, 2))
+	assert.False(t, inst.CoversRepo(1, 3))
+
+	t.Run("BotRepoInstallation", func(t *testing.T) {
+		found, isAppBot, err := auth_model.GetBotRepoInstallation(db.DefaultContext, 1000, 1, 2)
+		require.NoError(t, err)
+		assert.True(t, isAppBot)
+		require.NotNil(t, found)
+		assert.Equal(t, inst.ID, found.ID)
+
+		found, isAppBot, err = auth_model.GetBotRepoInstallation(db.DefaultContext, 1000, 2, 2)
+		require.NoError(t, err)
+		assert.True(t, isAppBot)
+		assert.Nil(t, found)
+
+		// the user does not act for an application
+		found, isAppBot, err = auth_model.GetBotRepoInstallation(db.DefaultContext, 2, 1, 2)
+		require.NoError(t, err)
+		assert.False(t, isAppBot)
+		assert.Nil(t, found)
+
+		botUserIDs, err := auth_model.FindRepoInstallationBotUserIDs(db.DefaultContext, 1, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1000}, botUserIDs)
+
+		// the installation is limited to repositories, it does not receive the events of the owner
+		botUserIDs, err = auth_model.FindRepoInstallationBotUserIDs(db.DefaultContext, 0, 2)
+		require.NoError(t, err)
+		assert.Empty(t, botUserIDs)
+	})
+
+	t.Run("InstallationToken", func(t *testing.T) {
+		token, err := auth_model.CreateInstallationToken(db.DefaultContext, inst)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1000), token.UID)
+		assert.True(t, token.FineGrained)
+		assert.Equal(t, []int64{1}, token.RepoIDs)
+		assert.Equal(t, inst.Permissions, token.RepoPermissions)
+		assert.False(t, token.IsExpired())
+
+		// installing again updates the installation and revokes its tokens
+		reinstalled := &auth_model.OAuth2ApplicationInstallation{
+			AppID:       app.ID,
+			OwnerID:     2,
+			Permissions: app.AppPermissions,
+			InstallerID: 2,
+		}
+		require.NoError(t, auth_model.CreateOAuth2ApplicationInstallation(db.DefaultContext, reinstalled))
+		assert.Equal(t, inst.ID, reinstalled.ID)
+		unittest.AssertNotExistsBean(t, &auth_model.AccessToken{ID: token.ID})
+		assert.True(t, reinstalled.CoversRepo(2, 2))
+	})
+
+	t.Run("Uninstall", func(t *testing.T) {
+		token, err := auth_model.CreateInstallationToken(db.DefaultContext, inst)
+		require.NoError(t, err)
+
+		err = auth_model.DeleteOAuth2ApplicationInstallation(db.DefaultContext, inst.ID, 3)
+		assert.True(t, auth_model.IsErrOAuth2ApplicationInstallationNotExist(err))
+
+		require.NoError(t, auth_model.DeleteOAuth2ApplicationInstallation(db.DefaultContext, inst.ID, 2))
+		unittest.AssertNotExistsBean(t, &auth_model.OAuth2ApplicationInstallation{ID: inst.ID})
+		unittest.AssertNotExistsBean(t, &auth_model.AccessToken{ID: token.ID})
+	})
+}
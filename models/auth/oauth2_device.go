@@ -0,0 +1,198 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"forgejo.org/models/db"
+	"forgejo.org/modules/timeutil"
+	"forgejo.org/modules/util"
+
+	"xorm.io/builder"
+)
+
+// userCodeCharset contains the characters of user codes, it has no vowels to avoid
+// forming words and is case insensitive as recommended by RFC 8628 Section 6.1
+const userCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+
+// userCodeLength is the number of characters of user codes, they are displayed in two groups
+const userCodeLength = 8
+
+// OAuth2DeviceAuthorization is a pending authorization of a device which can not open a browser, RFC 8628.
+// The user approves it by entering the user code on another device, the device polls with the device code
+// until the grant of the user is set.
+type OAuth2DeviceAuthorization struct {
+	ID            int64  `xorm:"pk autoincr"`
+	ApplicationID int64  `xorm:"INDEX NOT NULL"`
+	DeviceCode    string `xorm:"UNIQUE NOT NULL"`
+	UserCode      string `xorm:"UNIQUE NOT NULL"`
+	Scope         string `xorm:"TEXT"`
+	// GrantID is set once the user approved the device
+	GrantID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	Denied  bool  `xorm:"NOT NULL DEFAULT false"`
+	// PollInterval is the number of seconds the device must wait between polls, it increases when the device polls too fast
+	PollInterval   int64              `xorm:"NOT NULL DEFAULT 5"`
+	LastPolledUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	ExpiresUnix    timeutil.TimeStamp `xorm:"INDEX"`
+	CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(OAuth2DeviceAuthorization))
+}
+
+// TableName sets the table name to `oauth2_device_authorization`
+func (d *OAuth2DeviceAuthorization) TableName() string {
+	return "oauth2_device_authorization"
+}
+
+// ErrOAuth2DeviceAuthorizationNotExist represents a "OAuth2DeviceAuthorizationNotExist" kind of error.
+type ErrOAuth2DeviceAuthorizationNotExist struct {
+	Code string
+}
+
+// IsErrOAuth2DeviceAuthorizationNotExist checks if an error is a ErrOAuth2DeviceAuthorizationNotExist.
+func IsErrOAuth2DeviceAuthorizationNotExist(err error) bool {
+	_, ok := err.(ErrOAuth2DeviceAuthorizationNotExist)
+	return ok
+}
+
+func (err ErrOAuth2DeviceAuthorizationNotExist) Error() string {
+	return fmt.Sprintf("OAuth2 device authorization does not exist [code: %s]", err.Code)
+}
+
+func (err ErrOAuth2DeviceAuthorizationNotExist) Unwrap() error {
+	return util.ErrNotExist
+}
+
+// IsExpired returns whether the user can no longer approve the device
+func (d *OAuth2DeviceAuthorization) IsExpired() bool {
+	return d.ExpiresUnix <= timeutil.TimeStampNow()
+}
+
+// IsPending returns whether the user has neither approved nor denied the device yet
+func (d *OAuth2DeviceAuthorization) IsPending() bool {
+	return d.GrantID == 0 && !d.Denied
+}
+
+// FormattedUserCode returns the user code the way it is displayed to users
+func (d *OAuth2DeviceAuthorization) FormattedUserCode() string {
+	return d.UserCode[:userCodeLength/2] + "-" + d.UserCode[userCodeLength/2:]
+}
+
+// normalizeUserCode removes the separators users may type and uppercases the code
+func normalizeUserCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(code))
+}
+
+func generateUserCode() (string, error) {
+	code := make([]byte, userCodeLength)
+	charsetLen := big.NewInt(int64(len(userCodeCharset)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, charsetLen)
+		if err != nil {
+			return "", err
+		}
+		code[i] = userCodeCharset[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// CreateOAuth2DeviceAuthorization creates a pending authorization of a device for the application
+func CreateOAuth2DeviceAuthorization(ctx context.Context, app *OAuth2Application, scope string, expiresIn time.Duration, interval int64) (*OAuth2DeviceAuthorization, error) {
+	userCode, err := generateUserCode()
+	if err != nil {
+		return nil, err
+	}
+	d := &OAuth2DeviceAuthorization{
+		ApplicationID: app.ID,
+		// Add a prefix to the base32, this is in order to make it easier
+		// for code scanners to grab sensitive tokens.
+		DeviceCode:   "gtd_" + base32Lower.EncodeToString(util.CryptoRandomBytes(32)),
+		UserCode:     userCode,
+		Scope:        scope,
+		PollInterval: interval,
+		ExpiresUnix:  timeutil.TimeStampNow().AddDuration(expiresIn),
+	}
+	if err := db.Insert(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// GetOAuth2DeviceAuthorizationByUserCode returns the device authorization the user code was issued for
+func GetOAuth2DeviceAuthorizationByUserCode(ctx context.Context, userCode string) (*OAuth2DeviceAuthorization, error) {
+	d := new(OAuth2DeviceAuthorization)
+	has, err := db.GetEngine(ctx).Where("user_code = ?", normalizeUserCode(userCode)).Get(d)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrOAuth2DeviceAuthorizationNotExist{Code: userCode}
+	}
+	return d, nil
+}
+
+// GetOAuth2DeviceAuthorizationByDeviceCode returns the device authorization the device code was issued for
+func GetOAuth2DeviceAuthorizationByDeviceCode(ctx context.Context, deviceCode string) (*OAuth2DeviceAuthorization, error) {
+	d := new(OAuth2DeviceAuthorization)
+	has, err := db.GetEngine(ctx).Where("device_code = ?", deviceCode).Get(d)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrOAuth2DeviceAuthorizationNotExist{}
+	}
+	return d, nil
+}
+
+// Approve sets the grant the device obtains its tokens with
+func (d *OAuth2DeviceAuthorization) Approve(ctx context.Context, grant *OAuth2Grant) error {
+	d.GrantID = grant.ID
+	_, err := db.GetEngine(ctx).ID(d.ID).Cols("grant_id").Update(d)
+	return err
+}
+
+// Deny marks the device as denied, its next poll fails
+func (d *OAuth2DeviceAuthorization) Deny(ctx context.Context) error {
+	d.Denied = true
+	_, err := db.GetEngine(ctx).ID(d.ID).Cols("denied").Update(d)
+	return err
+}
+
+// Poll records a poll of the device and returns whether it polled faster than its interval,
+// its interval is then increased by 5 seconds as required by RFC 8628 Section 3.5
+func (d *OAuth2DeviceAuthorization) Poll(ctx context.Context) (slowDown bool, err error) {
+	now := timeutil.TimeStampNow()
+	cols := []string{"last_polled_unix"}
+	if d.LastPolledUnix > 0 && int64(now-d.LastPolledUnix) < d.PollInterval {
+		slowDown = true
+		d.PollInterval += 5
+		cols = append(cols, "poll_interval")
+	}
+	d.LastPolledUnix = now
+	_, err = db.GetEngine(ctx).ID(d.ID).Cols(cols...).Update(d)
+	return slowDown, err
+}
+
+// Invalidate deletes the device authorization, its device code can only be exchanged once.
+// It returns the number of deleted device authorizations, which is 0 if a concurrent request deleted it first.
+func (d *OAuth2DeviceAuthorization) Invalidate(ctx context.Context) (int64, error) {
+	return db.GetEngine(ctx).ID(d.ID).NoAutoCondition().Delete(d)
+}
+
+// DeleteExpiredOAuth2DeviceAuthorizations deletes the device authorizations which can no longer be approved nor polled
+func DeleteExpiredOAuth2DeviceAuthorizations(ctx context.Context) error {
+	_, err := db.GetEngine(ctx).Where(builder.Lte{"expires_unix": timeutil.TimeStampNow()}).Delete(new(OAuth2DeviceAuthorization))
+	return err
+}
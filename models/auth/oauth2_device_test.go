@@ -0,0 +1,88 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	"forgejo.org/models/unittest"
+	"forgejo.org/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuth2DeviceAuthorization(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	app := unittest.AssertExistsAndLoadBean(t, &auth_model.OAuth2Application{ID: 1})
+	device, err := auth_model.CreateOAuth2DeviceAuthorization(db.DefaultContext, app, "openid", time.Minute, 5)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(device.DeviceCode, "gtd_"))
+	assert.Len(t, device.UserCode, 8)
+	assert.Len(t, device.FormattedUserCode(), 9)
+	assert.True(t, device.IsPending())
+	assert.False(t, device.IsExpired())
+
+	t.Run("Lookup", func(t *testing.T) {
+		found, err := auth_model.GetOAuth2DeviceAuthorizationByUserCode(db.DefaultContext, strings.ToLower(device.FormattedUserCode()))
+		require.NoError(t, err)
+		assert.Equal(t, device.ID, found.ID)
+
+		found, err = auth_model.GetOAuth2DeviceAuthorizationByDeviceCode(db.DefaultContext, device.DeviceCode)
+		require.NoError(t, err)
+		assert.Equal(t, device.ID, found.ID)
+
+		_, err = auth_model.GetOAuth2DeviceAuthorizationByUserCode(db.DefaultContext, "BBBB-BBBB")
+		assert.True(t, auth_model.IsErrOAuth2DeviceAuthorizationNotExist(err))
+	})
+
+	t.Run("Poll", func(t *testing.T) {
+		slowDown, err := device.Poll(db.DefaultContext)
+		require.NoError(t, err)
+		assert.False(t, slowDown)
+
+		slowDown, err = device.Poll(db.DefaultContext)
+		require.NoError(t, err)
+		assert.True(t, slowDown)
+		unittest.AssertExistsAndLoadBean(t, &auth_model.OAuth2DeviceAuthorization{ID: device.ID, PollInterval: 10})
+	})
+
+	t.Run("Approve", func(t *testing.T) {
+		grant := unittest.AssertExistsAndLoadBean(t, &auth_model.OAuth2Grant{ID: 1})
+		require.NoError(t, device.Approve(db.DefaultContext, grant))
+		assert.False(t, device.IsPending())
+		unittest.AssertExistsAndLoadBean(t, &auth_model.OAuth2DeviceAuthorization{ID: device.ID, GrantID: grant.ID})
+
+		deleted, err := device.Invalidate(db.DefaultContext)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, deleted)
+		unittest.AssertNotExistsBean(t, &auth_model.OAuth2DeviceAuthorization{ID: device.ID})
+
+		// the device code can only be exchanged once
+		deleted, err = device.Invalidate(db.DefaultContext)
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, deleted)
+	})
+
+	t.Run("DeleteExpired", func(t *testing.T) {
+		expired, err := auth_model.CreateOAuth2DeviceAuthorization(db.DefaultContext, app, "openid", time.Minute, 5)
+		require.NoError(t, err)
+		expired.ExpiresUnix = timeutil.TimeStampNow() - 1
+		_, err = db.GetEngine(db.DefaultContext).ID(expired.ID).Cols("expires_unix").Update(expired)
+		require.NoError(t, err)
+		assert.True(t, expired.IsExpired())
+
+		valid, err := auth_model.CreateOAuth2DeviceAuthorization(db.DefaultContext, app, "openid", time.Minute, 5)
+		require.NoError(t, err)
+
+		require.NoError(t, auth_model.DeleteExpiredOAuth2DeviceAuthorizations(db.DefaultContext))
+		unittest.AssertNotExistsBean(t, &auth_model.OAuth2DeviceAuthorization{ID: expired.ID})
+		unittest.AssertExistsAndLoadBean(t, &auth_model.OAuth2DeviceAuthorization{ID: valid.ID})
+	})
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth
+
+import (
+	"context"
+
+	"forgejo.org/models/db"
+	"forgejo.org/modules/timeutil"
+)
+
+// SCIMResourceType is the type of a resource provisioned with SCIM
+type SCIMResourceType string
+
+const (
+	SCIMResourceTypeUser  SCIMResourceType = "User"
+	SCIMResourceTypeGroup SCIMResourceType = "Group"
+)
+
+// SCIMResource stores the id an identity provider assigned to a user or a team provisioned with SCIM
+type SCIMResource struct {
+	ID           int64              `xorm:"pk autoincr"`
+	ResourceType SCIMResourceType   `xorm:"UNIQUE(s) VARCHAR(10) NOT NULL"`
+	ResourceID   int64              `xorm:"UNIQUE(s) NOT NULL"`
+	ExternalID   string             `xorm:"INDEX VARCHAR(255)"`
+	CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix  timeutil.TimeStamp `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(SCIMResource))
+}
+
+// GetSCIMResource returns the SCIM data of a user or team, or nil if it was not provisioned with SCIM
+func GetSCIMResource(ctx context.Context, resourceType SCIMResourceType, resourceID int64) (*SCIMResource, error) {
+	resource := &SCIMResource{}
+	has, err := db.GetEngine(ctx).Where("resource_type=? AND resource_id=?", resourceType, resourceID).Get(resource)
+	if err != nil || !has {
+		return nil, err
+	}
+	return resource, nil
+}
+
+// FindSCIMResourcesByIDs returns the SCIM data of users or teams mapped by their ids
+func FindSCIMResourcesByIDs(ctx context.Context, resourceType SCIMResourceType, resourceIDs []int64) (map[int64]*SCIMResource, error) {
+	resources := make([]*SCIMResource, 0, len(resourceIDs))
+	if err := db.GetEngine(ctx).Where("resource_type=?", resourceType).In("resource_id", resourceIDs).Find(&resources); err != nil {
+		return nil, err
+	}
+	result := make(map[int64]*SCIMResource, len(resources))
+	for _, resource := range resources {
+		result[resource.ResourceID] = resource
+	}
+	return result, nil
+}
+
+// FindSCIMResourceIDsByExternalID returns the ids of the users or teams with the external id
+func FindSCIMResourceIDsByExternalID(ctx context.Context, resourceType SCIMResourceType, externalID string) ([]int64, error) {
+	ids := make([]int64, 0, 1)
+	return ids, db.GetEngine(ctx).Table("scim_resource").Where("resource_type=? AND external_id=?", resourceType, externalID).Cols("resource_id").Find(&ids)
+}
+
+// SetSCIMResource records that a user or team is managed by SCIM and stores its external id
+func SetSCIMResource(ctx context.Context, resourceType SCIMResourceType, resourceID int64, externalID string) error {
+	resource, err := GetSCIMResource(ctx, resourceType, resourceID)
+	if err != nil {
+		return err
+	}
+	if resource == nil {
+		_, err = db.GetEngine(ctx).Insert(&SCIMResource{ResourceType: resourceType, ResourceID: resourceID, ExternalID: externalID})
+		return err
+	}
+	resource.ExternalID = externalID
+	_, err = db.GetEngine(ctx).ID(resource.ID).Cols("external_id").Update(resource)
+	return err
+}
+
+// DeleteSCIMResource removes the SCIM data of a deleted user or team
+func DeleteSCIMResource(ctx context.Context, resourceType SCIMResourceType, resourceID int64) error {
+	_, err := db.GetEngine(ctx).Delete(&SCIMResource{ResourceType: resourceType, ResourceID: resourceID})
+	return err
+}
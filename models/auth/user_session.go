@@ -0,0 +1,111 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"forgejo.org/models/db"
+	"forgejo.org/modules/timeutil"
+	"forgejo.org/modules/useragent"
+	"forgejo.org/modules/util"
+
+	"xorm.io/builder"
+)
+
+// UserSession records a web session of a signed in user, so that the user can see where they are signed in
+// and revoke the session. The session itself is kept by the session provider, the record only remembers
+// the client it was used by.
+type UserSession struct {
+	ID             int64              `xorm:"pk autoincr"`
+	UID            int64              `xorm:"INDEX NOT NULL"`
+	IP             string             `xorm:"VARCHAR(64)"`
+	UserAgent      string             `xorm:"TEXT"`
+	CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+	LastAccessUnix timeutil.TimeStamp `xorm:"INDEX"`
+}
+
+func init() {
+	db.RegisterModel(new(UserSession))
+}
+
+// Device returns a description of the device of the session
+func (s *UserSession) Device() string {
+	return useragent.Device(s.UserAgent)
+}
+
+// ErrUserSessionNotExist represents a "UserSessionNotExist" kind of error.
+type ErrUserSessionNotExist struct {
+	ID int64
+}
+
+// IsErrUserSessionNotExist checks if an error is a ErrUserSessionNotExist.
+func IsErrUserSessionNotExist(err error) bool {
+	_, ok := err.(ErrUserSessionNotExist)
+	return ok
+}
+
+func (err ErrUserSessionNotExist) Error() string {
+	return fmt.Sprintf("user session does not exist [id: %d]", err.ID)
+}
+
+func (err ErrUserSessionNotExist) Unwrap() error {
+	return util.ErrNotExist
+}
+
+// CreateUserSession records a new session of a user and forgets the sessions of the user
+// which expired because they were not used for maxLifetime seconds
+func CreateUserSession(ctx context.Context, s *UserSession, maxLifetime int64) error {
+	if _, err := db.GetEngine(ctx).
+		Where("uid = ? AND last_access_unix <= ?", s.UID, timeutil.TimeStampNow().Add(-maxLifetime)).
+		Delete(&UserSession{}); err != nil {
+		return err
+	}
+	s.LastAccessUnix = timeutil.TimeStampNow()
+	return db.Insert(ctx, s)
+}
+
+// GetUserSessionByID returns the record of a session
+func GetUserSessionByID(ctx context.Context, id int64) (*UserSession, error) {
+	s, exist, err := db.GetByID[UserSession](ctx, id)
+	if err != nil {
+		return nil, err
+	} else if !exist {
+		return nil, ErrUserSessionNotExist{ID: id}
+	}
+	return s, nil
+}
+
+// UpdateUserSessionAccess remembers the client which last used the session
+func UpdateUserSessionAccess(ctx context.Context, s *UserSession) error {
+	s.LastAccessUnix = timeutil.TimeStampNow()
+	_, err := db.GetEngine(ctx).ID(s.ID).Cols("ip", "user_agent", "last_access_unix").Update(s)
+	return err
+}
+
+// FindUserSessions returns the sessions of a user which were used in the last maxLifetime seconds,
+// the most recently used first
+func FindUserSessions(ctx context.Context, uid, maxLifetime int64) ([]*UserSession, error) {
+	sessions := make([]*UserSession, 0, 10)
+	return sessions, db.GetEngine(ctx).
+		Where(builder.Eq{"uid": uid}.And(builder.Gt{"last_access_unix": timeutil.TimeStampNow().Add(-maxLifetime)})).
+		OrderBy("last_access_unix DESC").
+		Find(&sessions)
+}
+
+// DeleteUserSession revokes a session of a user
+func DeleteUserSession(ctx context.Context, uid, id int64) error {
+	_, err := db.GetEngine(ctx).ID(id).Delete(&UserSession{UID: uid})
+	return err
+}
+
+// DeleteUserSessionsByUser revokes all sessions of a user, except the excluded one
+func DeleteUserSessionsByUser(ctx context.Context, uid, excludeID int64) error {
+	if uid == 0 {
+		return nil
+	}
+	_, err := db.GetEngine(ctx).Where("uid = ? AND id <> ?", uid, excludeID).Delete(&UserSession{})
+	return err
+}
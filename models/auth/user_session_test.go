@@ -0,0 +1,69 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth_test
+
+import (
+	"testing"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	"forgejo.org/models/unittest"
+	"forgejo.org/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserSessions(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	const maxLifetime = 3600
+
+	first := &auth_model.UserSession{UID: 2, IP: "192.0.2.1", UserAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0"}
+	require.NoError(t, auth_model.CreateUserSession(t.Context(), first, maxLifetime))
+	second := &auth_model.UserSession{UID: 2, IP: "192.0.2.2"}
+	require.NoError(t, auth_model.CreateUserSession(t.Context(), second, maxLifetime))
+	other := &auth_model.UserSession{UID: 4, IP: "192.0.2.3"}
+	require.NoError(t, auth_model.CreateUserSession(t.Context(), other, maxLifetime))
+	assert.Equal(t, "Firefox on Linux", first.Device())
+
+	t.Run("Find", func(t *testing.T) {
+		sessions, err := auth_model.FindUserSessions(t.Context(), 2, maxLifetime)
+		require.NoError(t, err)
+		assert.Len(t, sessions, 2)
+	})
+
+	t.Run("Expired sessions are forgotten", func(t *testing.T) {
+		_, err := db.GetEngine(t.Context()).ID(second.ID).Cols("last_access_unix").
+			Update(&auth_model.UserSession{LastAccessUnix: timeutil.TimeStampNow().Add(-2 * maxLifetime)})
+		require.NoError(t, err)
+
+		sessions, err := auth_model.FindUserSessions(t.Context(), 2, maxLifetime)
+		require.NoError(t, err)
+		if assert.Len(t, sessions, 1) {
+			assert.Equal(t, first.ID, sessions[0].ID)
+		}
+
+		third := &auth_model.UserSession{UID: 2, IP: "192.0.2.4"}
+		require.NoError(t, auth_model.CreateUserSession(t.Context(), third, maxLifetime))
+		unittest.AssertNotExistsBean(t, &auth_model.UserSession{ID: second.ID})
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		// the session of another user is not deleted
+		require.NoError(t, auth_model.DeleteUserSession(t.Context(), 2, other.ID))
+		unittest.AssertExistsAndLoadBean(t, &auth_model.UserSession{ID: other.ID})
+
+		require.NoError(t, auth_model.DeleteUserSessionsByUser(t.Context(), 2, first.ID))
+		sessions, err := auth_model.FindUserSessions(t.Context(), 2, maxLifetime)
+		require.NoError(t, err)
+		if assert.Len(t, sessions, 1) {
+			assert.Equal(t, first.ID, sessions[0].ID)
+		}
+
+		require.NoError(t, auth_model.DeleteUserSession(t.Context(), 2, first.ID))
+		_, err = auth_model.GetUserSessionByID(t.Context(), first.ID)
+		assert.True(t, auth_model.IsErrUserSessionNotExist(err))
+		unittest.AssertExistsAndLoadBean(t, &auth_model.UserSession{ID: other.ID})
+	})
+}
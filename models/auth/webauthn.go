@@ -55,9 +55,12 @@ type WebAuthnCredential struct {
 	BackupEligible  bool `xorm:"NOT NULL DEFAULT false"`
 	BackupState     bool `xorm:"NOT NULL DEFAULT false"`
 	// If legacy is set to true, backup_eligible and backup_state isn't set.
-	Legacy      bool               `xorm:"NOT NULL DEFAULT true"`
-	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
-	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	Legacy bool `xorm:"NOT NULL DEFAULT true"`
+	// Discoverable credentials are passkeys, they are resident on the authenticator
+	// which verified the user and can sign in without a username or a password.
+	Discoverable bool               `xorm:"NOT NULL DEFAULT false"`
+	CreatedUnix  timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix  timeutil.TimeStamp `xorm:"INDEX updated"`
 }
 
 func init() {
@@ -159,6 +162,11 @@ func HasWebAuthnRegistrationsByUID(ctx context.Context, uid int64) (bool, error)
 	return db.GetEngine(ctx).Where("user_id = ?", uid).Exist(&WebAuthnCredential{})
 }
 
+// HasPasskeysByUID returns whether a given user has discoverable WebAuthn credentials to sign in with
+func HasPasskeysByUID(ctx context.Context, uid int64) (bool, error) {
+	return db.GetEngine(ctx).Where("user_id = ? AND discoverable = ?", uid, true).Exist(&WebAuthnCredential{})
+}
+
 // GetWebAuthnCredentialByCredID returns WebAuthn credential by credential ID
 func GetWebAuthnCredentialByCredID(ctx context.Context, userID int64, credID []byte) (*WebAuthnCredential, error) {
 	cred := new(WebAuthnCredential)
@@ -172,6 +180,15 @@ func GetWebAuthnCredentialByCredID(ctx context.Context, userID int64, credID []b
 
 // CreateCredential will create a new WebAuthnCredential from the given Credential
 func CreateCredential(ctx context.Context, userID int64, name string, cred *webauthn.Credential) (*WebAuthnCredential, error) {
+	return createCredential(ctx, userID, name, cred, false)
+}
+
+// CreatePasskey will create a new discoverable WebAuthnCredential from the given Credential
+func CreatePasskey(ctx context.Context, userID int64, name string, cred *webauthn.Credential) (*WebAuthnCredential, error) {
+	return createCredential(ctx, userID, name, cred, true)
+}
+
+func createCredential(ctx context.Context, userID int64, name string, cred *webauthn.Credential, discoverable bool) (*WebAuthnCredential, error) {
 	c := &WebAuthnCredential{
 		UserID:          userID,
 		Name:            name,
@@ -184,6 +201,7 @@ func CreateCredential(ctx context.Context, userID int64, name string, cred *weba
 		BackupEligible:  cred.Flags.BackupEligible,
 		BackupState:     cred.Flags.BackupState,
 		Legacy:          false,
+		Discoverable:    discoverable,
 	}
 
 	if err := db.Insert(ctx, c); err != nil {
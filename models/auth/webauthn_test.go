@@ -76,3 +76,19 @@ func TestCreateCredential(t *testing.T) {
 
 	unittest.AssertExistsIf(t, true, &auth_model.WebAuthnCredential{Name: "WebAuthn Created Credential", UserID: 1, BackupEligible: true, BackupState: true}, "legacy = false")
 }
+
+func TestCreatePasskey(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	has, err := auth_model.HasPasskeysByUID(db.DefaultContext, 32)
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	res, err := auth_model.CreatePasskey(db.DefaultContext, 32, "Passkey", &webauthn.Credential{ID: []byte("Passkey")})
+	require.NoError(t, err)
+	assert.True(t, res.Discoverable)
+
+	has, err = auth_model.HasPasskeysByUID(db.DefaultContext, 32)
+	require.NoError(t, err)
+	assert.True(t, has)
+}
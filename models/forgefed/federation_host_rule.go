@@ -0,0 +1,107 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forgefed
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"forgejo.org/models/db"
+	"forgejo.org/modules/hostmatcher"
+	"forgejo.org/modules/timeutil"
+	"forgejo.org/modules/util"
+)
+
+// FederationHostRuleType is the effect of a rule on the hosts it matches
+type FederationHostRuleType int
+
+const (
+	FederationHostRuleDeny  FederationHostRuleType = iota + 1 // federation with matching hosts is blocked
+	FederationHostRuleAllow                                   // matching hosts may federate when the allowlist mode is used
+)
+
+// String returns the name of the rule type
+func (t FederationHostRuleType) String() string {
+	if t == FederationHostRuleAllow {
+		return "allow"
+	}
+	return "deny"
+}
+
+// FederationHostRule is an admin managed entry of the federation allow or deny list.
+// The pattern is a host name, a wildcard pattern like *.example.com or a CIDR.
+type FederationHostRule struct {
+	ID          int64                  `xorm:"pk autoincr"`
+	Pattern     string                 `xorm:"UNIQUE(s) VARCHAR(255) NOT NULL"`
+	Type        FederationHostRuleType `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Comment     string                 `xorm:"TEXT"`
+	CreatedUnix timeutil.TimeStamp     `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(FederationHostRule))
+}
+
+// ErrFederationHostRuleAlreadyExist represents a "FederationHostRuleAlreadyExist" kind of error.
+type ErrFederationHostRuleAlreadyExist struct {
+	Pattern string
+}
+
+func (err ErrFederationHostRuleAlreadyExist) Error() string {
+	return fmt.Sprintf("federation host rule already exists [pattern: %s]", err.Pattern)
+}
+
+func (err ErrFederationHostRuleAlreadyExist) Unwrap() error {
+	return util.ErrAlreadyExist
+}
+
+// NormalizeFederationHostPattern returns the pattern in the form it is stored
+func NormalizeFederationHostPattern(pattern string) string {
+	return strings.ToLower(strings.TrimSpace(pattern))
+}
+
+// CreateFederationHostRule adds a rule to the allow or deny list
+func CreateFederationHostRule(ctx context.Context, rule *FederationHostRule) error {
+	rule.Pattern = NormalizeFederationHostPattern(rule.Pattern)
+	if rule.Pattern == "" {
+		return util.NewInvalidArgumentErrorf("pattern is empty")
+	}
+	has, err := db.GetEngine(ctx).Exist(&FederationHostRule{Pattern: rule.Pattern, Type: rule.Type})
+	if err != nil {
+		return err
+	} else if has {
+		return ErrFederationHostRuleAlreadyExist{Pattern: rule.Pattern}
+	}
+	return db.Insert(ctx, rule)
+}
+
+// DeleteFederationHostRule removes a rule from the allow or deny list
+func DeleteFederationHostRule(ctx context.Context, id int64) error {
+	_, err := db.GetEngine(ctx).ID(id).Delete(&FederationHostRule{})
+	return err
+}
+
+// FindFederationHostRules returns the rules of a type, all rules if ruleType is 0
+func FindFederationHostRules(ctx context.Context, ruleType FederationHostRuleType) ([]*FederationHostRule, error) {
+	sess := db.GetEngine(ctx).OrderBy("pattern")
+	if ruleType != 0 {
+		sess = sess.Where("type = ?", ruleType)
+	}
+	rules := make([]*FederationHostRule, 0, 10)
+	return rules, sess.Find(&rules)
+}
+
+// GetFederationHostMatchList returns a matcher for the patterns of all rules of a type
+func GetFederationHostMatchList(ctx context.Context, ruleType FederationHostRuleType) (*hostmatcher.HostMatchList, error) {
+	rules, err := FindFederationHostRules(ctx, ruleType)
+	if err != nil {
+		return nil, err
+	}
+	patterns := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		patterns = append(patterns, rule.Pattern)
+	}
+	return hostmatcher.ParseHostMatchList("federation."+ruleType.String(), strings.Join(patterns, ",")), nil
+}
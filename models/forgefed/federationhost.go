@@ -24,6 +24,7 @@ type FederationHost struct {
 	LatestActivity time.Time              `xorm:"NOT NULL"`
 	KeyID          sql.NullString         `xorm:"key_id UNIQUE"`
 	PublicKey      sql.Null[sql.RawBytes] `xorm:"BLOB"`
+	Quarantined    bool                   `xorm:"NOT NULL DEFAULT false"` // activities are held for review by an admin
 	Created        timeutil.TimeStamp     `xorm:"created"`
 	Updated        timeutil.TimeStamp     `xorm:"updated"`
 }
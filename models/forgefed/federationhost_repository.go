@@ -6,6 +6,7 @@ package forgefed
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"forgejo.org/models/db"
 	"forgejo.org/modules/validation"
@@ -57,6 +58,16 @@ func FindFederationHostByFqdnAndPort(ctx context.Context, fqdn string, port uint
 	return host, nil
 }
 
+func FindFederationHostByFqdn(ctx context.Context, fqdn string) (*FederationHost, error) {
+	return findFederationHostFromDB(ctx, "host_fqdn=?", strings.ToLower(fqdn))
+}
+
+// FindQuarantinedFederationHosts returns the hosts whose activities are held for review
+func FindQuarantinedFederationHosts(ctx context.Context) ([]*FederationHost, error) {
+	hosts := make([]*FederationHost, 0, 10)
+	return hosts, db.GetEngine(ctx).Where("quarantined = ?", true).OrderBy("host_fqdn").Find(&hosts)
+}
+
 func FindFederationHostByKeyID(ctx context.Context, keyID string) (*FederationHost, error) {
 	return findFederationHostFromDB(ctx, "key_id=?", keyID)
 }
@@ -0,0 +1,91 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forgefed
+
+import (
+	"context"
+	"fmt"
+
+	"forgejo.org/models/db"
+	"forgejo.org/modules/timeutil"
+	"forgejo.org/modules/util"
+)
+
+// QuarantinedActivityTargetType is the kind of inbox a held activity was sent to
+type QuarantinedActivityTargetType int
+
+const (
+	QuarantinedActivityTargetUser       QuarantinedActivityTargetType = iota + 1 // inbox of a user
+	QuarantinedActivityTargetRepository                                          // inbox of a repository
+)
+
+// QuarantinedActivity is an activity of a quarantined host which is held until an admin reviewed it
+type QuarantinedActivity struct {
+	ID          int64                         `xorm:"pk autoincr"`
+	Host        string                        `xorm:"INDEX VARCHAR(255) NOT NULL"`
+	TargetType  QuarantinedActivityTargetType `xorm:"NOT NULL"`
+	TargetID    int64                         `xorm:"NOT NULL"`
+	Payload     string                        `xorm:"LONGTEXT NOT NULL"`
+	CreatedUnix timeutil.TimeStamp            `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(QuarantinedActivity))
+}
+
+// ErrQuarantinedActivityNotExist represents a "QuarantinedActivityNotExist" kind of error.
+type ErrQuarantinedActivityNotExist struct {
+	ID int64
+}
+
+func (err ErrQuarantinedActivityNotExist) Error() string {
+	return fmt.Sprintf("quarantined activity does not exist [id: %d]", err.ID)
+}
+
+func (err ErrQuarantinedActivityNotExist) Unwrap() error {
+	return util.ErrNotExist
+}
+
+// CreateQuarantinedActivity holds an activity for review
+func CreateQuarantinedActivity(ctx context.Context, activity *QuarantinedActivity) error {
+	return db.Insert(ctx, activity)
+}
+
+// GetQuarantinedActivity returns a held activity by id
+func GetQuarantinedActivity(ctx context.Context, id int64) (*QuarantinedActivity, error) {
+	activity := &QuarantinedActivity{}
+	has, err := db.GetEngine(ctx).ID(id).Get(activity)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrQuarantinedActivityNotExist{ID: id}
+	}
+	return activity, nil
+}
+
+// FindQuarantinedActivities returns the held activities, of all hosts if host is empty, oldest first
+func FindQuarantinedActivities(ctx context.Context, host string, opts db.ListOptions) ([]*QuarantinedActivity, int64, error) {
+	sess := db.GetEngine(ctx).Asc("id")
+	if host != "" {
+		sess = sess.Where("host = ?", host)
+	}
+	if opts.PageSize > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+	activities := make([]*QuarantinedActivity, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&activities)
+	return activities, count, err
+}
+
+// DeleteQuarantinedActivity removes a held activity
+func DeleteQuarantinedActivity(ctx context.Context, id int64) error {
+	_, err := db.GetEngine(ctx).ID(id).Delete(&QuarantinedActivity{})
+	return err
+}
+
+// DeleteQuarantinedActivitiesByHost removes all held activities of a host
+func DeleteQuarantinedActivitiesByHost(ctx context.Context, host string) error {
+	_, err := db.GetEngine(ctx).Where("host = ?", host).Delete(&QuarantinedActivity{})
+	return err
+}
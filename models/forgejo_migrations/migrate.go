@@ -103,6 +103,44 @@ var migrations = []*Migration{
 	NewMigration("Normalize repository.topics to empty slice instead of null", SetTopicsAsEmptySlice),
 	// v31 -> v32
 	NewMigration("Migrate maven package name concatenation", ChangeMavenArtifactConcatenation),
+	// v32 -> v33
+	NewMigration("Create the `package_virtual_registry` table", CreatePackageVirtualRegistryTable),
+	// v33 -> v34
+	NewMigration("Create the `package_advisory` and `package_vulnerability` tables", CreatePackageAdvisoryTables),
+	// v34 -> v35
+	NewMigration("Add package channels, promotions and immutable package versions", AddPackageChannelsAndImmutableVersions),
+	// v35 -> v36
+	NewMigration("Add build provenance to package versions", AddPackageVersionProvenance),
+	// v36 -> v37
+	NewMigration("Add federated user following", AddFederatedUserFollowing),
+	// v37 -> v38
+	NewMigration("Add federated comments", AddFederatedComment),
+	// v38 -> v39
+	NewMigration("Add federated pull requests", AddFederatedPullRequests),
+	// v39 -> v40
+	NewMigration("Add federation moderation", AddFederationModeration),
+	// v40 -> v41
+	NewMigration("Add table to store the external ids of SCIM resources", AddSCIMResourceTable),
+	// v41 -> v42
+	NewMigration("Add table of trusted SSH certificate authorities", AddSSHCertificateAuthorityTable),
+	// v42 -> v43
+	NewMigration("Add fine-grained access tokens limited to repositories", AddFineGrainedAccessTokens),
+	// v43 -> v44
+	NewMigration("Add installations of OAuth2 applications", AddOAuth2ApplicationInstallations),
+	// v44 -> v45
+	NewMigration("Add discoverable to WebAuthn credentials", AddDiscoverableToWebAuthnCredential),
+	// v45 -> v46
+	NewMigration("Add OAuth2 device authorizations", AddOAuth2DeviceAuthorization),
+	// v46 -> v47
+	NewMigration("Add audit events", AddAuditEvent),
+	// v47 -> v48
+	NewMigration("Add secret scanning alerts", AddSecretScanningAlert),
+	// v48 -> v49
+	NewMigration("Add IP allowlists of organizations", AddOrgIPAllowlist),
+	// v49 -> v50
+	NewMigration("Add user sessions and the clients of long term authorization tokens", AddUserSessionAndAuthTokenClient),
+	// v50 -> v51
+	NewMigration("Add pending revocations of access tokens found in pushes", AddSecretScanningPendingRevocation),
 }
 
 // GetCurrentDBVersion returns the current Forgejo database version.
@@ -0,0 +1,26 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import (
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func CreatePackageVirtualRegistryTable(x *xorm.Engine) error {
+	type PackageVirtualRegistry struct {
+		ID          int64              `xorm:"pk autoincr"`
+		OwnerID     int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Type        string             `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Name        string             `xorm:"NOT NULL"`
+		LowerName   string             `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		MemberIDs   []int64            `xorm:"JSON TEXT"`
+		UpstreamURL string             `xorm:"upstream_url NOT NULL DEFAULT ''"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL DEFAULT 0"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync(new(PackageVirtualRegistry))
+}
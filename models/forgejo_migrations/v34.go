@@ -0,0 +1,39 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import (
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func CreatePackageAdvisoryTables(x *xorm.Engine) error {
+	type PackageAdvisory struct {
+		ID           int64              `xorm:"pk autoincr"`
+		AdvisoryID   string             `xorm:"UNIQUE(s) NOT NULL"`
+		Ecosystem    string             `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		PackageName  string             `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Aliases      []string           `xorm:"JSON TEXT"`
+		Summary      string             `xorm:"TEXT"`
+		Severity     string             `xorm:"NOT NULL DEFAULT ''"`
+		URL          string             `xorm:"url TEXT"`
+		AffectedJSON string             `xorm:"affected_json LONGTEXT"`
+		ModifiedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	type PackageVulnerability struct {
+		ID                    int64              `xorm:"pk autoincr"`
+		VersionID             int64              `xorm:"INDEX NOT NULL"`
+		AdvisoryID            string             `xorm:"NOT NULL"`
+		DependencyName        string             `xorm:"NOT NULL"`
+		DependencyRequirement string             `xorm:"NOT NULL DEFAULT ''"`
+		Severity              string             `xorm:"NOT NULL DEFAULT ''"`
+		Summary               string             `xorm:"TEXT"`
+		URL                   string             `xorm:"url TEXT"`
+		CreatedUnix           timeutil.TimeStamp `xorm:"created NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync(new(PackageAdvisory), new(PackageVulnerability))
+}
@@ -0,0 +1,38 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import (
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddPackageChannelsAndImmutableVersions(x *xorm.Engine) error {
+	type PackageVersion struct {
+		IsImmutable bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	type PackageChannel struct {
+		ID           int64              `xorm:"pk autoincr"`
+		PackageID    int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Name         string             `xorm:"UNIQUE(s) NOT NULL"`
+		VersionID    int64              `xorm:"INDEX NOT NULL"`
+		PromotedByID int64              `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix  timeutil.TimeStamp `xorm:"created NOT NULL"`
+		UpdatedUnix  timeutil.TimeStamp `xorm:"updated NOT NULL"`
+	}
+
+	type PackagePromotion struct {
+		ID              int64              `xorm:"pk autoincr"`
+		VersionID       int64              `xorm:"INDEX NOT NULL"`
+		SourceVersionID int64              `xorm:"NOT NULL DEFAULT 0"`
+		SourceOwnerID   int64              `xorm:"NOT NULL DEFAULT 0"`
+		Channel         string             `xorm:"NOT NULL DEFAULT ''"`
+		DoerID          int64              `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix     timeutil.TimeStamp `xorm:"created INDEX NOT NULL"`
+	}
+
+	return x.Sync(new(PackageVersion), new(PackageChannel), new(PackagePromotion))
+}
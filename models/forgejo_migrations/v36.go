@@ -0,0 +1,19 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import "xorm.io/xorm"
+
+func AddPackageVersionProvenance(x *xorm.Engine) error {
+	type PackageVersion struct {
+		ProvenanceRepoID     int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+		ProvenanceRunID      int64  `xorm:"NOT NULL DEFAULT 0"`
+		ProvenanceTaskID     int64  `xorm:"NOT NULL DEFAULT 0"`
+		ProvenanceCommitSHA  string `xorm:"VARCHAR(64)"`
+		ProvenanceRef        string
+		ProvenanceWorkflowID string
+	}
+
+	return x.Sync(new(PackageVersion))
+}
@@ -0,0 +1,34 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import (
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddFederatedUserFollowing(x *xorm.Engine) error {
+	type FederatedUser struct {
+		InboxURL string `xorm:"VARCHAR(255)"`
+	}
+
+	type FollowRequest struct {
+		ID          int64              `xorm:"pk autoincr"`
+		UserID      int64              `xorm:"UNIQUE(follow_request)"`
+		FollowID    int64              `xorm:"UNIQUE(follow_request)"`
+		ActivityID  string             `xorm:"VARCHAR(255) INDEX"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	type FederatedUserActivity struct {
+		ID          int64              `xorm:"pk autoincr"`
+		UserID      int64              `xorm:"NOT NULL INDEX"`
+		NoteURL     string             `xorm:"VARCHAR(255) INDEX"`
+		NoteContent string             `xorm:"TEXT"`
+		Created     timeutil.TimeStamp `xorm:"created INDEX"`
+	}
+
+	return x.Sync(new(FederatedUser), new(FollowRequest), new(FederatedUserActivity))
+}
@@ -0,0 +1,22 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import (
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddFederatedComment(x *xorm.Engine) error {
+	type FederatedComment struct {
+		ID          int64              `xorm:"pk autoincr"`
+		CommentID   int64              `xorm:"UNIQUE NOT NULL"`
+		IssueID     int64              `xorm:"INDEX NOT NULL"`
+		NoteID      string             `xorm:"VARCHAR(255) UNIQUE NOT NULL"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	return x.Sync(new(FederatedComment))
+}
@@ -0,0 +1,39 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import (
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddFederatedPullRequests(x *xorm.Engine) error {
+	type FederatedPullRequest struct {
+		ID          int64              `xorm:"pk autoincr"`
+		PullID      int64              `xorm:"UNIQUE NOT NULL"`
+		IssueID     int64              `xorm:"INDEX NOT NULL"`
+		OfferID     string             `xorm:"VARCHAR(255) UNIQUE NOT NULL"`
+		OriginRepo  string             `xorm:"VARCHAR(255) NOT NULL"`
+		OriginRef   string             `xorm:"VARCHAR(255) NOT NULL"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	type PullRequestOffer struct {
+		ID           int64              `xorm:"pk autoincr"`
+		RepoID       int64              `xorm:"INDEX NOT NULL"`
+		DoerID       int64              `xorm:"NOT NULL"`
+		HeadBranch   string             `xorm:"VARCHAR(255) NOT NULL"`
+		TargetRepo   string             `xorm:"VARCHAR(255) NOT NULL"`
+		TargetBranch string             `xorm:"VARCHAR(255) NOT NULL"`
+		Title        string             `xorm:"VARCHAR(255)"`
+		OfferID      string             `xorm:"VARCHAR(255) UNIQUE NOT NULL"`
+		TicketID     string             `xorm:"VARCHAR(255) INDEX"`
+		Status       int                `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix  timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	return x.Sync(new(FederatedPullRequest), new(PullRequestOffer))
+}
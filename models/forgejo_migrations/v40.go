@@ -0,0 +1,35 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import (
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddFederationModeration(x *xorm.Engine) error {
+	type FederationHost struct {
+		Quarantined bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	type FederationHostRule struct {
+		ID          int64              `xorm:"pk autoincr"`
+		Pattern     string             `xorm:"UNIQUE(s) VARCHAR(255) NOT NULL"`
+		Type        int                `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Comment     string             `xorm:"TEXT"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	type QuarantinedActivity struct {
+		ID          int64              `xorm:"pk autoincr"`
+		Host        string             `xorm:"INDEX VARCHAR(255) NOT NULL"`
+		TargetType  int                `xorm:"NOT NULL"`
+		TargetID    int64              `xorm:"NOT NULL"`
+		Payload     string             `xorm:"LONGTEXT NOT NULL"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	return x.Sync(new(FederationHost), new(FederationHostRule), new(QuarantinedActivity))
+}
@@ -0,0 +1,23 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import (
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddSCIMResourceTable(x *xorm.Engine) error {
+	type SCIMResource struct {
+		ID           int64              `xorm:"pk autoincr"`
+		ResourceType string             `xorm:"UNIQUE(s) VARCHAR(10) NOT NULL"`
+		ResourceID   int64              `xorm:"UNIQUE(s) NOT NULL"`
+		ExternalID   string             `xorm:"INDEX VARCHAR(255)"`
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix  timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	return x.Sync(new(SCIMResource))
+}
@@ -0,0 +1,25 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import (
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddSSHCertificateAuthorityTable(x *xorm.Engine) error {
+	type SSHCertificateAuthority struct {
+		ID          int64              `xorm:"pk autoincr"`
+		Name        string             `xorm:"UNIQUE NOT NULL"`
+		Fingerprint string             `xorm:"UNIQUE NOT NULL"`
+		Content     string             `xorm:"MEDIUMTEXT NOT NULL"`
+		Mapping     int                `xorm:"NOT NULL DEFAULT 0"`
+		MaxValidity int64              `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	return x.Sync(new(SSHCertificateAuthority))
+}
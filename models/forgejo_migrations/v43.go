@@ -0,0 +1,22 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import (
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddFineGrainedAccessTokens(x *xorm.Engine) error {
+	type AccessToken struct {
+		FineGrained     bool               `xorm:"NOT NULL DEFAULT false"`
+		ResourceOwnerID int64              `xorm:"NOT NULL DEFAULT 0"`
+		RepoIDs         []int64            `xorm:"JSON TEXT"`
+		RepoPermissions string             `xorm:"TEXT"`
+		ExpiresUnix     timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync(new(AccessToken))
+}
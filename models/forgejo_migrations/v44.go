@@ -0,0 +1,35 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import (
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddOAuth2ApplicationInstallations(x *xorm.Engine) error {
+	type oauth2Application struct {
+		BotUserID      int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+		AppPermissions string `xorm:"TEXT"`
+		AppPublicKey   string `xorm:"TEXT"`
+	}
+
+	type oauth2ApplicationInstallation struct {
+		ID          int64              `xorm:"pk autoincr"`
+		AppID       int64              `xorm:"UNIQUE(s) NOT NULL"`
+		OwnerID     int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		RepoIDs     []int64            `xorm:"JSON TEXT"`
+		Permissions string             `xorm:"TEXT"`
+		InstallerID int64              `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type AccessToken struct {
+		InstallationID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync(new(oauth2Application), new(oauth2ApplicationInstallation), new(AccessToken))
+}
@@ -0,0 +1,14 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import "xorm.io/xorm"
+
+func AddDiscoverableToWebAuthnCredential(x *xorm.Engine) error {
+	type webauthnCredential struct {
+		Discoverable bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	return x.Sync(new(webauthnCredential))
+}
@@ -0,0 +1,28 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import (
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddOAuth2DeviceAuthorization(x *xorm.Engine) error {
+	type oauth2DeviceAuthorization struct {
+		ID             int64              `xorm:"pk autoincr"`
+		ApplicationID  int64              `xorm:"INDEX NOT NULL"`
+		DeviceCode     string             `xorm:"UNIQUE NOT NULL"`
+		UserCode       string             `xorm:"UNIQUE NOT NULL"`
+		Scope          string             `xorm:"TEXT"`
+		GrantID        int64              `xorm:"INDEX NOT NULL DEFAULT 0"`
+		Denied         bool               `xorm:"NOT NULL DEFAULT false"`
+		PollInterval   int64              `xorm:"NOT NULL DEFAULT 5"`
+		LastPolledUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		ExpiresUnix    timeutil.TimeStamp `xorm:"INDEX"`
+		CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+	}
+
+	return x.Sync(new(oauth2DeviceAuthorization))
+}
@@ -0,0 +1,30 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import (
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddAuditEvent(x *xorm.Engine) error {
+	type auditEvent struct {
+		ID          int64  `xorm:"pk autoincr"`
+		Action      string `xorm:"INDEX NOT NULL"`
+		ActorID     int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+		ActorName   string
+		IPAddress   string
+		OwnerID     int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+		RepoID      int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+		TargetType  string
+		TargetID    int64
+		TargetName  string
+		Before      string             `xorm:"TEXT"`
+		After       string             `xorm:"TEXT"`
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	return x.Sync(new(auditEvent))
+}
@@ -0,0 +1,30 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import (
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddSecretScanningAlert(x *xorm.Engine) error {
+	type secretScanningAlert struct {
+		ID           int64  `xorm:"pk autoincr"`
+		RepoID       int64  `xorm:"UNIQUE(s) NOT NULL"`
+		Fingerprint  string `xorm:"UNIQUE(s) VARCHAR(64) NOT NULL"`
+		DetectorID   string `xorm:"NOT NULL"`
+		Redacted     string
+		CommitID     string `xorm:"VARCHAR(64)"`
+		Path         string `xorm:"TEXT"`
+		Line         int
+		State        string             `xorm:"INDEX NOT NULL DEFAULT 'open'"`
+		BypassedByID int64              `xorm:"NOT NULL DEFAULT 0"`
+		ClosedByID   int64              `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix  timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix  timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	return x.Sync(new(secretScanningAlert))
+}
@@ -0,0 +1,22 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import (
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddOrgIPAllowlist(x *xorm.Engine) error {
+	type OrgIPAllowlist struct {
+		ID          int64              `xorm:"pk autoincr"`
+		OrgID       int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		CIDR        string             `xorm:"UNIQUE(s) VARCHAR(64) NOT NULL"`
+		Comment     string             `xorm:"TEXT"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	return x.Sync(new(OrgIPAllowlist))
+}
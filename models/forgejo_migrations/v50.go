@@ -0,0 +1,31 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import (
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddUserSessionAndAuthTokenClient(x *xorm.Engine) error {
+	type UserSession struct {
+		ID             int64              `xorm:"pk autoincr"`
+		UID            int64              `xorm:"INDEX NOT NULL"`
+		IP             string             `xorm:"VARCHAR(64)"`
+		UserAgent      string             `xorm:"TEXT"`
+		CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+		LastAccessUnix timeutil.TimeStamp `xorm:"INDEX"`
+	}
+
+	// the client which last used a long term authorization token
+	type ForgejoAuthToken struct {
+		IP           string             `xorm:"VARCHAR(64)"`
+		UserAgent    string             `xorm:"TEXT"`
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+		LastUsedUnix timeutil.TimeStamp
+	}
+
+	return x.Sync(new(UserSession), new(ForgejoAuthToken))
+}
@@ -0,0 +1,23 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package forgejo_migrations //nolint:revive
+
+import (
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddSecretScanningPendingRevocation(x *xorm.Engine) error {
+	type secretScanningPendingRevocation struct {
+		ID          int64              `xorm:"pk autoincr"`
+		RepoID      int64              `xorm:"INDEX(s) NOT NULL"`
+		CommitID    string             `xorm:"INDEX(s) VARCHAR(64) NOT NULL"`
+		TokenID     int64              `xorm:"NOT NULL"`
+		Link        string             `xorm:"TEXT"`
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	return x.Sync(new(secretScanningPendingRevocation))
+}
@@ -1211,6 +1211,12 @@ func DeleteComment(ctx context.Context, comment *Comment) error {
 		return err
 	}
 
+	if _, err := db.DeleteByBean(ctx, &FederatedComment{
+		CommentID: comment.ID,
+	}); err != nil {
+		return err
+	}
+
 	if comment.Type.CountedAsConversation() {
 		if err := UpdateIssueNumComments(ctx, comment.IssueID); err != nil {
 			return err
@@ -0,0 +1,66 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package issues
+
+import (
+	"context"
+
+	"forgejo.org/models/db"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/timeutil"
+)
+
+// FederatedComment links a comment to the ForgeFed Note it was created from
+type FederatedComment struct {
+	ID          int64              `xorm:"pk autoincr"`
+	CommentID   int64              `xorm:"UNIQUE NOT NULL"`
+	IssueID     int64              `xorm:"INDEX NOT NULL"`
+	NoteID      string             `xorm:"VARCHAR(255) UNIQUE NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(FederatedComment))
+}
+
+// CreateFederatedComment records the Note a comment was created from
+func CreateFederatedComment(ctx context.Context, comment *Comment, noteID string) error {
+	return db.Insert(ctx, &FederatedComment{
+		CommentID: comment.ID,
+		IssueID:   comment.IssueID,
+		NoteID:    noteID,
+	})
+}
+
+// GetFederatedCommentByNoteID returns the federated comment of a Note or nil if the Note was not received yet
+func GetFederatedCommentByNoteID(ctx context.Context, noteID string) (*FederatedComment, error) {
+	fc := &FederatedComment{}
+	has, err := db.GetEngine(ctx).Where("note_id = ?", noteID).Get(fc)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return fc, nil
+}
+
+// GetFederatedCommentByCommentID returns the federated comment of a comment or nil if the comment was created locally
+func GetFederatedCommentByCommentID(ctx context.Context, commentID int64) (*FederatedComment, error) {
+	fc := &FederatedComment{}
+	has, err := db.GetEngine(ctx).Where("comment_id = ?", commentID).Get(fc)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return fc, nil
+}
+
+// GetFederatedCommenters returns the users of other instances who commented on an issue
+func GetFederatedCommenters(ctx context.Context, issueID int64) ([]*user_model.FederatedUser, error) {
+	federatedUsers := make([]*user_model.FederatedUser, 0, 5)
+	return federatedUsers, db.GetEngine(ctx).
+		Where("`federated_user`.user_id IN (SELECT poster_id FROM `comment` WHERE issue_id = ? AND type = ?)", issueID, CommentTypeComment).
+		Find(&federatedUsers)
+}
@@ -0,0 +1,52 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package issues_test
+
+import (
+	"testing"
+
+	"forgejo.org/models/db"
+	issues_model "forgejo.org/models/issues"
+	"forgejo.org/models/unittest"
+	user_model "forgejo.org/models/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFederatedComment(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	comment := unittest.AssertExistsAndLoadBean(t, &issues_model.Comment{ID: 3})
+	noteID := "https://example.org/api/v1/activitypub/user-id/5/notes/1"
+
+	commenters, err := issues_model.GetFederatedCommenters(db.DefaultContext, comment.IssueID)
+	require.NoError(t, err)
+	assert.Empty(t, commenters)
+
+	require.NoError(t, issues_model.CreateFederatedComment(db.DefaultContext, comment, noteID))
+	require.NoError(t, db.Insert(db.DefaultContext, &user_model.FederatedUser{
+		UserID:                comment.PosterID,
+		ExternalID:            "5",
+		FederationHostID:      1,
+		NormalizedOriginalURL: "https://example.org/api/v1/activitypub/user-id/5",
+	}))
+
+	fc, err := issues_model.GetFederatedCommentByNoteID(db.DefaultContext, noteID)
+	require.NoError(t, err)
+	require.NotNil(t, fc)
+	assert.Equal(t, comment.ID, fc.CommentID)
+
+	fc, err = issues_model.GetFederatedCommentByNoteID(db.DefaultContext, noteID+"/unknown")
+	require.NoError(t, err)
+	assert.Nil(t, fc)
+
+	commenters, err = issues_model.GetFederatedCommenters(db.DefaultContext, comment.IssueID)
+	require.NoError(t, err)
+	require.Len(t, commenters, 1)
+	assert.Equal(t, comment.PosterID, commenters[0].UserID)
+
+	require.NoError(t, issues_model.DeleteComment(db.DefaultContext, comment))
+	unittest.AssertNotExistsBean(t, &issues_model.FederatedComment{CommentID: comment.ID})
+}
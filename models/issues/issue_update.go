@@ -704,6 +704,16 @@ func DeleteIssuesByRepoID(ctx context.Context, repoID int64) (attachmentPaths []
 			return nil, err
 		}
 
+		_, err = sess.In("issue_id", issueIDs).Delete(&FederatedComment{})
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = sess.In("issue_id", issueIDs).Delete(&FederatedPullRequest{})
+		if err != nil {
+			return nil, err
+		}
+
 		// Dependencies for issues in this repository
 		_, err = sess.In("issue_id", issueIDs).Delete(&IssueDependency{})
 		if err != nil {
@@ -0,0 +1,55 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package issues
+
+import (
+	"context"
+
+	"forgejo.org/models/db"
+	"forgejo.org/modules/timeutil"
+)
+
+// FederatedPullRequest links a pull request to the Offer of a user of another instance it was created from
+type FederatedPullRequest struct {
+	ID          int64              `xorm:"pk autoincr"`
+	PullID      int64              `xorm:"UNIQUE NOT NULL"`
+	IssueID     int64              `xorm:"INDEX NOT NULL"`
+	OfferID     string             `xorm:"VARCHAR(255) UNIQUE NOT NULL"`
+	OriginRepo  string             `xorm:"VARCHAR(255) NOT NULL"` // id of the repository actor the changes come from
+	OriginRef   string             `xorm:"VARCHAR(255) NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(FederatedPullRequest))
+}
+
+// CreateFederatedPullRequest records the Offer a pull request was created from
+func CreateFederatedPullRequest(ctx context.Context, fpr *FederatedPullRequest) error {
+	return db.Insert(ctx, fpr)
+}
+
+// GetFederatedPullRequestByOfferID returns the federated pull request of an Offer or nil if the Offer was not received yet
+func GetFederatedPullRequestByOfferID(ctx context.Context, offerID string) (*FederatedPullRequest, error) {
+	fpr := &FederatedPullRequest{}
+	has, err := db.GetEngine(ctx).Where("offer_id = ?", offerID).Get(fpr)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return fpr, nil
+}
+
+// GetFederatedPullRequestByPullID returns the federated pull request of a pull request or nil if it was created locally
+func GetFederatedPullRequestByPullID(ctx context.Context, pullID int64) (*FederatedPullRequest, error) {
+	fpr := &FederatedPullRequest{}
+	has, err := db.GetEngine(ctx).Where("pull_id = ?", pullID).Get(fpr)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return fpr, nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package issues_test
+
+import (
+	"testing"
+
+	"forgejo.org/models/db"
+	issues_model "forgejo.org/models/issues"
+	"forgejo.org/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFederatedPullRequest(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	pr := unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: 1})
+	offerID := "https://example.org/api/v1/activitypub/user-id/5/offers/1"
+
+	fpr, err := issues_model.GetFederatedPullRequestByPullID(db.DefaultContext, pr.ID)
+	require.NoError(t, err)
+	assert.Nil(t, fpr)
+
+	require.NoError(t, issues_model.CreateFederatedPullRequest(db.DefaultContext, &issues_model.FederatedPullRequest{
+		PullID:     pr.ID,
+		IssueID:    pr.IssueID,
+		OfferID:    offerID,
+		OriginRepo: "https://example.org/api/v1/activitypub/repository-id/3",
+		OriginRef:  "refs/heads/feature",
+	}))
+
+	fpr, err = issues_model.GetFederatedPullRequestByOfferID(db.DefaultContext, offerID)
+	require.NoError(t, err)
+	require.NotNil(t, fpr)
+	assert.Equal(t, pr.ID, fpr.PullID)
+
+	fpr, err = issues_model.GetFederatedPullRequestByPullID(db.DefaultContext, pr.ID)
+	require.NoError(t, err)
+	require.NotNil(t, fpr)
+	assert.Equal(t, offerID, fpr.OfferID)
+}
+
+func TestPullRequestOffer(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	offer := &issues_model.PullRequestOffer{
+		RepoID:       1,
+		DoerID:       2,
+		HeadBranch:   "branch2",
+		TargetRepo:   "https://example.org/api/v1/activitypub/repository-id/3",
+		TargetBranch: "main",
+		Title:        "federated change",
+		OfferID:      "https://forgejo.example/api/v1/activitypub/user-id/2/offers/1",
+	}
+	require.NoError(t, issues_model.CreatePullRequestOffer(db.DefaultContext, offer))
+
+	offers, count, err := issues_model.FindPullRequestOffers(db.DefaultContext, 1, db.ListOptions{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	require.Len(t, offers, 1)
+	assert.Equal(t, issues_model.PullRequestOfferStatusPending, offers[0].Status)
+
+	offer.TicketID = "https://example.org/api/v1/activitypub/repository-id/3/issues/7"
+	offer.Status = issues_model.PullRequestOfferStatusAccepted
+	require.NoError(t, issues_model.UpdatePullRequestOffer(db.DefaultContext, offer))
+
+	loaded, err := issues_model.GetPullRequestOfferByTicketID(db.DefaultContext, offer.TicketID)
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, offer.ID, loaded.ID)
+	assert.Equal(t, "accepted", loaded.Status.String())
+
+	loaded, err = issues_model.GetPullRequestOfferByOfferID(db.DefaultContext, offer.OfferID+"/unknown")
+	require.NoError(t, err)
+	assert.Nil(t, loaded)
+}
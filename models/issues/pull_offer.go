@@ -0,0 +1,100 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package issues
+
+import (
+	"context"
+
+	"forgejo.org/models/db"
+	"forgejo.org/modules/timeutil"
+)
+
+// PullRequestOfferStatus is the state of a pull request offered to a repository of another instance
+type PullRequestOfferStatus int
+
+const (
+	PullRequestOfferStatusPending  PullRequestOfferStatus = iota // waiting for the remote repository
+	PullRequestOfferStatusAccepted                               // a pull request was opened
+	PullRequestOfferStatusMerged                                 // the pull request was merged
+	PullRequestOfferStatusClosed                                 // the pull request was closed without merging
+)
+
+// String returns the name of the status
+func (s PullRequestOfferStatus) String() string {
+	switch s {
+	case PullRequestOfferStatusAccepted:
+		return "accepted"
+	case PullRequestOfferStatusMerged:
+		return "merged"
+	case PullRequestOfferStatusClosed:
+		return "closed"
+	}
+	return "pending"
+}
+
+// PullRequestOffer is a branch offered as pull request to a repository of another instance
+type PullRequestOffer struct {
+	ID           int64                  `xorm:"pk autoincr"`
+	RepoID       int64                  `xorm:"INDEX NOT NULL"`
+	DoerID       int64                  `xorm:"NOT NULL"`
+	HeadBranch   string                 `xorm:"VARCHAR(255) NOT NULL"`
+	TargetRepo   string                 `xorm:"VARCHAR(255) NOT NULL"` // id of the remote repository actor
+	TargetBranch string                 `xorm:"VARCHAR(255) NOT NULL"`
+	Title        string                 `xorm:"VARCHAR(255)"`
+	OfferID      string                 `xorm:"VARCHAR(255) UNIQUE NOT NULL"`
+	TicketID     string                 `xorm:"VARCHAR(255) INDEX"` // id of the Ticket of the remote pull request, set once accepted
+	Status       PullRequestOfferStatus `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix  timeutil.TimeStamp     `xorm:"created"`
+	UpdatedUnix  timeutil.TimeStamp     `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(PullRequestOffer))
+}
+
+// CreatePullRequestOffer stores a new offer
+func CreatePullRequestOffer(ctx context.Context, offer *PullRequestOffer) error {
+	return db.Insert(ctx, offer)
+}
+
+// GetPullRequestOfferByOfferID returns the offer with the id of the Offer activity or nil if there is none
+func GetPullRequestOfferByOfferID(ctx context.Context, offerID string) (*PullRequestOffer, error) {
+	offer := &PullRequestOffer{}
+	has, err := db.GetEngine(ctx).Where("offer_id = ?", offerID).Get(offer)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return offer, nil
+}
+
+// GetPullRequestOfferByTicketID returns the offer which resulted in the Ticket or nil if there is none
+func GetPullRequestOfferByTicketID(ctx context.Context, ticketID string) (*PullRequestOffer, error) {
+	offer := &PullRequestOffer{}
+	has, err := db.GetEngine(ctx).Where("ticket_id = ?", ticketID).Get(offer)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return offer, nil
+}
+
+// FindPullRequestOffers returns the offers made from a repository, newest first
+func FindPullRequestOffers(ctx context.Context, repoID int64, opts db.ListOptions) ([]*PullRequestOffer, int64, error) {
+	sess := db.GetEngine(ctx).Where("repo_id = ?", repoID).Desc("id")
+	if opts.PageSize > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+	offers := make([]*PullRequestOffer, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&offers)
+	return offers, count, err
+}
+
+// UpdatePullRequestOffer stores the ticket and status of an offer
+func UpdatePullRequestOffer(ctx context.Context, offer *PullRequestOffer) error {
+	_, err := db.GetEngine(ctx).ID(offer.ID).Cols("ticket_id", "status").Update(offer)
+	return err
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"strings"
 
+	auth_model "forgejo.org/models/auth"
 	"forgejo.org/models/db"
 	git_model "forgejo.org/models/git"
 	issues_model "forgejo.org/models/issues"
@@ -336,6 +337,7 @@ func DeleteTeam(ctx context.Context, t *organization.Team) error {
 		&organization.TeamUnit{TeamID: t.ID},
 		&organization.TeamInvite{TeamID: t.ID},
 		&issues_model.Review{Type: issues_model.ReviewTypeRequest, ReviewerTeamID: t.ID}, // batch delete the binding relationship between team and PR (request review from team)
+		&auth_model.SCIMResource{ResourceType: auth_model.SCIMResourceTypeGroup, ResourceID: t.ID},
 	); err != nil {
 		return err
 	}
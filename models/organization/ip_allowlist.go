@@ -0,0 +1,152 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package organization
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"forgejo.org/models/db"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/timeutil"
+	"forgejo.org/modules/util"
+)
+
+// IPAllowlistMode is how the IP allowlist of an organization is applied
+type IPAllowlistMode string
+
+const (
+	IPAllowlistDisabled IPAllowlistMode = ""        // the allowlist is not applied
+	IPAllowlistEnforced IPAllowlistMode = "enforce" // clients outside of the allowlist are blocked
+	IPAllowlistDryRun   IPAllowlistMode = "dry_run" // clients outside of the allowlist are only logged
+)
+
+// IsValid returns true if the mode is known
+func (m IPAllowlistMode) IsValid() bool {
+	return m == IPAllowlistDisabled || m == IPAllowlistEnforced || m == IPAllowlistDryRun
+}
+
+// IPAllowlistEntry is a range of addresses allowed to access the organization and its repositories
+type IPAllowlistEntry struct {
+	ID          int64              `xorm:"pk autoincr"`
+	OrgID       int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	CIDR        string             `xorm:"UNIQUE(s) VARCHAR(64) NOT NULL"`
+	Comment     string             `xorm:"TEXT"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// TableName sets the table name of the IP allowlist entries
+func (*IPAllowlistEntry) TableName() string {
+	return "org_ip_allowlist"
+}
+
+func init() {
+	db.RegisterModel(new(IPAllowlistEntry))
+}
+
+// ErrIPAllowlistEntryAlreadyExist represents a "IPAllowlistEntryAlreadyExist" kind of error.
+type ErrIPAllowlistEntryAlreadyExist struct {
+	CIDR string
+}
+
+func (err ErrIPAllowlistEntryAlreadyExist) Error() string {
+	return fmt.Sprintf("IP allowlist entry already exists [cidr: %s]", err.CIDR)
+}
+
+func (err ErrIPAllowlistEntryAlreadyExist) Unwrap() error {
+	return util.ErrAlreadyExist
+}
+
+// NormalizeIPAllowlistCIDR returns the range in the form it is stored, a single address is a range of one address
+func NormalizeIPAllowlistCIDR(cidr string) (string, error) {
+	cidr = strings.TrimSpace(cidr)
+	if !strings.Contains(cidr, "/") {
+		addr, err := netip.ParseAddr(cidr)
+		if err != nil {
+			return "", util.NewInvalidArgumentErrorf("invalid IP address or range %q", cidr)
+		}
+		return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()).String(), nil
+	}
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return "", util.NewInvalidArgumentErrorf("invalid IP address or range %q", cidr)
+	}
+	return prefix.Masked().String(), nil
+}
+
+// CreateIPAllowlistEntry adds a range to the allowlist of an organization
+func CreateIPAllowlistEntry(ctx context.Context, entry *IPAllowlistEntry) error {
+	cidr, err := NormalizeIPAllowlistCIDR(entry.CIDR)
+	if err != nil {
+		return err
+	}
+	entry.CIDR = cidr
+	has, err := db.GetEngine(ctx).Exist(&IPAllowlistEntry{OrgID: entry.OrgID, CIDR: entry.CIDR})
+	if err != nil {
+		return err
+	} else if has {
+		return ErrIPAllowlistEntryAlreadyExist{CIDR: entry.CIDR}
+	}
+	return db.Insert(ctx, entry)
+}
+
+// DeleteIPAllowlistEntry removes a range from the allowlist of an organization
+func DeleteIPAllowlistEntry(ctx context.Context, orgID, id int64) error {
+	_, err := db.GetEngine(ctx).ID(id).Delete(&IPAllowlistEntry{OrgID: orgID})
+	return err
+}
+
+// FindIPAllowlistEntries returns the ranges of the allowlist of an organization
+func FindIPAllowlistEntries(ctx context.Context, orgID int64) ([]*IPAllowlistEntry, error) {
+	entries := make([]*IPAllowlistEntry, 0, 10)
+	return entries, db.GetEngine(ctx).Where("org_id = ?", orgID).OrderBy("cidr").Find(&entries)
+}
+
+// GetIPAllowlistMode returns how the IP allowlist of an organization is applied
+func GetIPAllowlistMode(ctx context.Context, orgID int64) (IPAllowlistMode, error) {
+	mode, err := user_model.GetSetting(ctx, orgID, user_model.SettingsKeyIPAllowlistMode)
+	if err != nil {
+		if user_model.IsErrUserSettingIsNotExist(err) {
+			return IPAllowlistDisabled, nil
+		}
+		return IPAllowlistDisabled, err
+	}
+	return IPAllowlistMode(mode), nil
+}
+
+// SetIPAllowlistMode changes how the IP allowlist of an organization is applied
+func SetIPAllowlistMode(ctx context.Context, orgID int64, mode IPAllowlistMode) error {
+	if !mode.IsValid() {
+		return util.NewInvalidArgumentErrorf("invalid IP allowlist mode %q", mode)
+	}
+	return user_model.SetUserSetting(ctx, orgID, user_model.SettingsKeyIPAllowlistMode, string(mode))
+}
+
+// IsIPAllowlisted returns true if the address is in one of the ranges of the allowlist of an organization.
+// An empty allowlist allows all addresses.
+func IsIPAllowlisted(ctx context.Context, orgID int64, addr netip.Addr) (bool, error) {
+	entries, err := FindIPAllowlistEntries(ctx, orgID)
+	if err != nil {
+		return false, err
+	}
+	return IPAllowlistContains(entries, addr), nil
+}
+
+// IPAllowlistContains returns true if the address is in one of the ranges of the entries.
+// An empty allowlist allows all addresses.
+func IPAllowlistContains(entries []*IPAllowlistEntry, addr netip.Addr) bool {
+	if len(entries) == 0 {
+		return true
+	}
+	addr = addr.Unmap()
+	for _, entry := range entries {
+		prefix, err := netip.ParsePrefix(entry.CIDR)
+		if err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package organization_test
+
+import (
+	"net/netip"
+	"testing"
+
+	"forgejo.org/models/db"
+	"forgejo.org/models/organization"
+	"forgejo.org/models/unittest"
+	"forgejo.org/modules/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeIPAllowlistCIDR(t *testing.T) {
+	for input, expected := range map[string]string{
+		"192.0.2.10":        "192.0.2.10/32",
+		" 192.0.2.10/24 ":   "192.0.2.0/24",
+		"2001:db8::1":       "2001:db8::1/128",
+		"2001:db8::1/32":    "2001:db8::/32",
+		"::ffff:192.0.2.10": "192.0.2.10/32",
+	} {
+		cidr, err := organization.NormalizeIPAllowlistCIDR(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, cidr, input)
+	}
+
+	for _, input := range []string{"", "example.com", "192.0.2.0/33", "192.0.2"} {
+		_, err := organization.NormalizeIPAllowlistCIDR(input)
+		require.ErrorIs(t, err, util.ErrInvalidArgument, input)
+	}
+}
+
+func TestIPAllowlist(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	inside := netip.MustParseAddr("192.0.2.10")
+	outside := netip.MustParseAddr("198.51.100.1")
+
+	// an empty allowlist allows all addresses
+	allowed, err := organization.IsIPAllowlisted(db.DefaultContext, 3, outside)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	require.NoError(t, organization.CreateIPAllowlistEntry(db.DefaultContext, &organization.IPAllowlistEntry{OrgID: 3, CIDR: "192.0.2.1/24", Comment: "office"}))
+	require.NoError(t, organization.CreateIPAllowlistEntry(db.DefaultContext, &organization.IPAllowlistEntry{OrgID: 3, CIDR: "2001:db8::/32"}))
+	err = organization.CreateIPAllowlistEntry(db.DefaultContext, &organization.IPAllowlistEntry{OrgID: 3, CIDR: "192.0.2.0/24"})
+	require.ErrorIs(t, err, util.ErrAlreadyExist)
+
+	entries, err := organization.FindIPAllowlistEntries(db.DefaultContext, 3)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "192.0.2.0/24", entries[0].CIDR)
+	assert.Equal(t, "office", entries[0].Comment)
+
+	for addr, expected := range map[netip.Addr]bool{
+		inside:                                   true,
+		netip.MustParseAddr("::ffff:192.0.2.10"): true,
+		netip.MustParseAddr("2001:db8::1"):       true,
+		outside:                                  false,
+		{}:                                       false,
+	} {
+		allowed, err := organization.IsIPAllowlisted(db.DefaultContext, 3, addr)
+		require.NoError(t, err)
+		assert.Equal(t, expected, allowed, addr.String())
+	}
+
+	// the allowlist of another organization is not affected
+	allowed, err = organization.IsIPAllowlisted(db.DefaultContext, 6, outside)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	// entries are only deleted from their organization
+	require.NoError(t, organization.DeleteIPAllowlistEntry(db.DefaultContext, 6, entries[0].ID))
+	unittest.AssertExistsIf(t, true, &organization.IPAllowlistEntry{ID: entries[0].ID})
+	require.NoError(t, organization.DeleteIPAllowlistEntry(db.DefaultContext, 3, entries[0].ID))
+	unittest.AssertExistsIf(t, false, &organization.IPAllowlistEntry{ID: entries[0].ID})
+}
+
+func TestIPAllowlistMode(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	mode, err := organization.GetIPAllowlistMode(db.DefaultContext, 3)
+	require.NoError(t, err)
+	assert.Equal(t, organization.IPAllowlistDisabled, mode)
+
+	require.NoError(t, organization.SetIPAllowlistMode(db.DefaultContext, 3, organization.IPAllowlistDryRun))
+	mode, err = organization.GetIPAllowlistMode(db.DefaultContext, 3)
+	require.NoError(t, err)
+	assert.Equal(t, organization.IPAllowlistDryRun, mode)
+
+	require.NoError(t, organization.SetIPAllowlistMode(db.DefaultContext, 3, organization.IPAllowlistDisabled))
+	mode, err = organization.GetIPAllowlistMode(db.DefaultContext, 3)
+	require.NoError(t, err)
+	assert.Equal(t, organization.IPAllowlistDisabled, mode)
+
+	err = organization.SetIPAllowlistMode(db.DefaultContext, 3, "block")
+	require.ErrorIs(t, err, util.ErrInvalidArgument)
+}
@@ -397,6 +397,7 @@ func DeleteOrganization(ctx context.Context, org *Organization) error {
 		&TeamUser{OrgID: org.ID},
 		&TeamUnit{OrgID: org.ID},
 		&TeamInvite{OrgID: org.ID},
+		&IPAllowlistEntry{OrgID: org.ID},
 		&secret_model.Secret{OwnerID: org.ID},
 		&actions_model.ActionRunner{OwnerID: org.ID},
 		&actions_model.ActionRunnerToken{OwnerID: org.ID},
@@ -522,6 +523,15 @@ func AddOrgUser(ctx context.Context, orgID, uid int64) error {
 		return err
 	}
 
+	// a new member of an organization which requires two-factor authentication has a grace period to enable it
+	if required, err := isTwoFactorRequiredByOrg(ctx, orgID); err != nil {
+		return err
+	} else if required {
+		if err := startTwoFactorGracePeriod(ctx, uid); err != nil {
+			return err
+		}
+	}
+
 	ou := &OrgUser{
 		UID:      uid,
 		OrgID:    orgID,
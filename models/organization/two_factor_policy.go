@@ -0,0 +1,88 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package organization
+
+import (
+	"context"
+	"strconv"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/setting"
+)
+
+// IsTwoFactorRequired returns whether an organization requires its members to enable two-factor authentication,
+// all organizations do if the instance requires it of everyone
+func IsTwoFactorRequired(ctx context.Context, orgID int64) (bool, error) {
+	if setting.RequireTwoFactor {
+		return true, nil
+	}
+	return isTwoFactorRequiredByOrg(ctx, orgID)
+}
+
+func isTwoFactorRequiredByOrg(ctx context.Context, orgID int64) (bool, error) {
+	value, err := user_model.GetSetting(ctx, orgID, user_model.SettingsKeyTwoFactorRequired)
+	if err != nil {
+		if user_model.IsErrUserSettingIsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	required, _ := strconv.ParseBool(value)
+	return required, nil
+}
+
+// SetTwoFactorRequired changes whether an organization requires its members to enable two-factor authentication.
+// When it starts to, the grace period of the members who did not enable it starts.
+func SetTwoFactorRequired(ctx context.Context, orgID int64, required bool) error {
+	if !required {
+		return user_model.DeleteUserSetting(ctx, orgID, user_model.SettingsKeyTwoFactorRequired)
+	}
+
+	wasRequired, err := isTwoFactorRequiredByOrg(ctx, orgID)
+	if err != nil || wasRequired {
+		return err
+	}
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		uids := make([]int64, 0, 10)
+		if err := db.GetEngine(ctx).Table("org_user").Where("org_id = ?", orgID).Cols("uid").Find(&uids); err != nil {
+			return err
+		}
+		for _, uid := range uids {
+			if err := startTwoFactorGracePeriod(ctx, uid); err != nil {
+				return err
+			}
+		}
+		return user_model.SetUserSetting(ctx, orgID, user_model.SettingsKeyTwoFactorRequired, strconv.FormatBool(true))
+	})
+}
+
+// startTwoFactorGracePeriod starts the grace period of a user who becomes required to enable two-factor
+// authentication by an organization, unless they already are required to or did enable it
+func startTwoFactorGracePeriod(ctx context.Context, uid int64) error {
+	if setting.RequireTwoFactor {
+		return nil
+	}
+	required, err := HasOrgRequiringTwoFactor(ctx, uid)
+	if err != nil || required {
+		return err
+	}
+	hasTwoFactor, err := auth_model.HasTwoFactorByUID(ctx, uid)
+	if err != nil || hasTwoFactor {
+		return err
+	}
+	_, err = user_model.StartTwoFactorGracePeriod(ctx, uid)
+	return err
+}
+
+// HasOrgRequiringTwoFactor returns whether the user is a member of an organization which requires its members
+// to enable two-factor authentication
+func HasOrgRequiringTwoFactor(ctx context.Context, uid int64) (bool, error) {
+	return db.GetEngine(ctx).Table("org_user").
+		Join("INNER", "user_setting", "user_setting.user_id = org_user.org_id").
+		Where("org_user.uid = ? AND user_setting.setting_key = ? AND user_setting.setting_value = ?",
+			uid, user_model.SettingsKeyTwoFactorRequired, strconv.FormatBool(true)).
+		Exist()
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package organization_test
+
+import (
+	"testing"
+
+	"forgejo.org/models/db"
+	"forgejo.org/models/organization"
+	"forgejo.org/models/unittest"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwoFactorRequired(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	assertRequired := func(t *testing.T, orgID int64, expected bool) {
+		t.Helper()
+		required, err := organization.IsTwoFactorRequired(db.DefaultContext, orgID)
+		require.NoError(t, err)
+		assert.Equal(t, expected, required)
+	}
+	assertRequiredByOrg := func(t *testing.T, uid int64, expected bool) {
+		t.Helper()
+		required, err := organization.HasOrgRequiringTwoFactor(db.DefaultContext, uid)
+		require.NoError(t, err)
+		assert.Equal(t, expected, required)
+	}
+	gracePeriodStarted := func(uid int64) bool {
+		return unittest.BeanExists(t, &user_model.Setting{UserID: uid, SettingKey: user_model.SettingsKeyTwoFactorGraceDeadline})
+	}
+
+	assertRequired(t, 3, false)
+	assertRequiredByOrg(t, 2, false)
+
+	require.NoError(t, organization.SetTwoFactorRequired(db.DefaultContext, 3, true))
+	assertRequired(t, 3, true)
+	assertRequiredByOrg(t, 2, true)
+	assertRequiredByOrg(t, 5, false)
+	assert.True(t, gracePeriodStarted(2))
+
+	t.Run("New members", func(t *testing.T) {
+		require.NoError(t, organization.AddOrgUser(db.DefaultContext, 3, 5))
+		assertRequiredByOrg(t, 5, true)
+		assert.True(t, gracePeriodStarted(5))
+
+		// user 24 enabled two-factor authentication
+		require.NoError(t, organization.AddOrgUser(db.DefaultContext, 3, 24))
+		assertRequiredByOrg(t, 24, true)
+		assert.False(t, gracePeriodStarted(24))
+	})
+
+	t.Run("Required by the instance", func(t *testing.T) {
+		defer test.MockVariableValue(&setting.RequireTwoFactor, true)()
+		assertRequired(t, 6, true)
+	})
+
+	require.NoError(t, organization.SetTwoFactorRequired(db.DefaultContext, 3, false))
+	assertRequired(t, 3, false)
+	assertRequiredByOrg(t, 2, false)
+}
@@ -22,7 +22,9 @@ import (
 	"forgejo.org/modules/packages/container"
 	"forgejo.org/modules/packages/cran"
 	"forgejo.org/modules/packages/debian"
+	"forgejo.org/modules/packages/galaxy"
 	"forgejo.org/modules/packages/helm"
+	"forgejo.org/modules/packages/hex"
 	"forgejo.org/modules/packages/maven"
 	"forgejo.org/modules/packages/npm"
 	"forgejo.org/modules/packages/nuget"
@@ -117,7 +119,7 @@ func GetPackageDescriptor(ctx context.Context, pv *PackageVersion) (*PackageDesc
 			return nil, err
 		}
 	}
-	creator, err := user_model.GetUserByID(ctx, pv.CreatorID)
+	creator, err := user_model.GetPossibleUserByID(ctx, pv.CreatorID)
 	if err != nil {
 		if errors.Is(err, util.ErrNotExist) {
 			creator = user_model.NewGhostUser()
@@ -172,12 +174,16 @@ func GetPackageDescriptor(ctx context.Context, pv *PackageVersion) (*PackageDesc
 		metadata = &cran.Metadata{}
 	case TypeDebian:
 		metadata = &debian.Metadata{}
+	case TypeGalaxy:
+		metadata = &galaxy.Metadata{}
 	case TypeGeneric:
 		// generic packages have no metadata
 	case TypeGo:
 		// go packages have no metadata
 	case TypeHelm:
 		metadata = &helm.Metadata{}
+	case TypeHex:
+		metadata = &hex.Metadata{}
 	case TypeNuGet:
 		metadata = &nuget.Metadata{}
 	case TypeNpm:
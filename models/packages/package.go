@@ -42,9 +42,11 @@ const (
 	TypeContainer Type = "container"
 	TypeCran      Type = "cran"
 	TypeDebian    Type = "debian"
+	TypeGalaxy    Type = "galaxy"
 	TypeGeneric   Type = "generic"
 	TypeGo        Type = "go"
 	TypeHelm      Type = "helm"
+	TypeHex       Type = "hex"
 	TypeMaven     Type = "maven"
 	TypeNpm       Type = "npm"
 	TypeNuGet     Type = "nuget"
@@ -68,9 +70,11 @@ var TypeList = []Type{
 	TypeContainer,
 	TypeCran,
 	TypeDebian,
+	TypeGalaxy,
 	TypeGeneric,
 	TypeGo,
 	TypeHelm,
+	TypeHex,
 	TypeMaven,
 	TypeNpm,
 	TypeNuGet,
@@ -106,12 +110,16 @@ func (pt Type) Name() string {
 		return "CRAN"
 	case TypeDebian:
 		return "Debian"
+	case TypeGalaxy:
+		return "Ansible Galaxy"
 	case TypeGeneric:
 		return "Generic"
 	case TypeGo:
 		return "Go"
 	case TypeHelm:
 		return "Helm"
+	case TypeHex:
+		return "Hex"
 	case TypeMaven:
 		return "Maven"
 	case TypeNpm:
@@ -159,12 +167,16 @@ func (pt Type) SVGName() string {
 		return "gitea-cran"
 	case TypeDebian:
 		return "gitea-debian"
+	case TypeGalaxy:
+		return "gitea-galaxy"
 	case TypeGeneric:
 		return "octicon-package"
 	case TypeGo:
 		return "gitea-go"
 	case TypeHelm:
 		return "gitea-helm"
+	case TypeHex:
+		return "gitea-hex"
 	case TypeMaven:
 		return "gitea-maven"
 	case TypeNpm:
@@ -0,0 +1,119 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package packages
+
+import (
+	"context"
+
+	"forgejo.org/models/db"
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+func init() {
+	db.RegisterModel(new(PackageAdvisory))
+	db.RegisterModel(new(PackageVulnerability))
+}
+
+// PackageAdvisory is an imported vulnerability advisory for a single package of an ecosystem
+type PackageAdvisory struct {
+	ID           int64              `xorm:"pk autoincr"`
+	AdvisoryID   string             `xorm:"UNIQUE(s) NOT NULL"`
+	Ecosystem    string             `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	PackageName  string             `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Aliases      []string           `xorm:"JSON TEXT"`
+	Summary      string             `xorm:"TEXT"`
+	Severity     string             `xorm:"NOT NULL DEFAULT ''"`
+	URL          string             `xorm:"url TEXT"`
+	AffectedJSON string             `xorm:"affected_json LONGTEXT"`
+	ModifiedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+}
+
+// UpsertAdvisory inserts the advisory or updates the stored advisory if the new one is more recent
+func UpsertAdvisory(ctx context.Context, pa *PackageAdvisory) error {
+	e := db.GetEngine(ctx)
+
+	existing := &PackageAdvisory{}
+	has, err := e.Where(builder.Eq{
+		"advisory_id":  pa.AdvisoryID,
+		"ecosystem":    pa.Ecosystem,
+		"package_name": pa.PackageName,
+	}).Get(existing)
+	if err != nil {
+		return err
+	}
+	if !has {
+		_, err = e.Insert(pa)
+		return err
+	}
+	if existing.ModifiedUnix >= pa.ModifiedUnix {
+		return nil
+	}
+	pa.ID = existing.ID
+	_, err = e.ID(pa.ID).AllCols().Update(pa)
+	return err
+}
+
+// DeleteAdvisoriesByAdvisoryID deletes all entries of an advisory
+func DeleteAdvisoriesByAdvisoryID(ctx context.Context, advisoryID string) error {
+	_, err := db.GetEngine(ctx).Where("advisory_id = ?", advisoryID).Delete(&PackageAdvisory{})
+	return err
+}
+
+// GetAdvisoriesByPackageName gets all advisories for the package of the ecosystem
+func GetAdvisoriesByPackageName(ctx context.Context, ecosystem, name string) ([]*PackageAdvisory, error) {
+	pas := make([]*PackageAdvisory, 0, 5)
+	return pas, db.GetEngine(ctx).Where(builder.Eq{
+		"ecosystem":    ecosystem,
+		"package_name": name,
+	}).Find(&pas)
+}
+
+// CountAdvisories returns the number of imported advisory entries
+func CountAdvisories(ctx context.Context) (int64, error) {
+	return db.GetEngine(ctx).Count(&PackageAdvisory{})
+}
+
+// PackageVulnerability is a known vulnerable dependency of a package version
+type PackageVulnerability struct {
+	ID                    int64              `xorm:"pk autoincr"`
+	VersionID             int64              `xorm:"INDEX NOT NULL"`
+	AdvisoryID            string             `xorm:"NOT NULL"`
+	DependencyName        string             `xorm:"NOT NULL"`
+	DependencyRequirement string             `xorm:"NOT NULL DEFAULT ''"`
+	Severity              string             `xorm:"NOT NULL DEFAULT ''"`
+	Summary               string             `xorm:"TEXT"`
+	URL                   string             `xorm:"url TEXT"`
+	CreatedUnix           timeutil.TimeStamp `xorm:"created NOT NULL DEFAULT 0"`
+}
+
+// ReplaceVulnerabilities replaces the vulnerabilities recorded for a package version
+func ReplaceVulnerabilities(ctx context.Context, versionID int64, pvs []*PackageVulnerability) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if err := DeleteVulnerabilitiesByVersionID(ctx, versionID); err != nil {
+			return err
+		}
+		for _, pv := range pvs {
+			pv.ID = 0
+			pv.VersionID = versionID
+		}
+		if len(pvs) == 0 {
+			return nil
+		}
+		return db.Insert(ctx, pvs)
+	})
+}
+
+// GetVulnerabilitiesByVersionID gets all vulnerabilities of a package version
+func GetVulnerabilitiesByVersionID(ctx context.Context, versionID int64) ([]*PackageVulnerability, error) {
+	pvs := make([]*PackageVulnerability, 0, 5)
+	return pvs, db.GetEngine(ctx).Where("version_id = ?", versionID).OrderBy("advisory_id, dependency_name").Find(&pvs)
+}
+
+// DeleteVulnerabilitiesByVersionID deletes all vulnerabilities of a package version
+func DeleteVulnerabilitiesByVersionID(ctx context.Context, versionID int64) error {
+	_, err := db.GetEngine(ctx).Where("version_id = ?", versionID).Delete(&PackageVulnerability{})
+	return err
+}
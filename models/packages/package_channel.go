@@ -0,0 +1,135 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package packages
+
+import (
+	"context"
+	"strings"
+
+	"forgejo.org/models/db"
+	"forgejo.org/modules/timeutil"
+	"forgejo.org/modules/util"
+
+	"xorm.io/builder"
+)
+
+// ErrPackageChannelNotExist indicates a package channel not exist error
+var ErrPackageChannelNotExist = util.NewNotExistErrorf("package channel does not exist")
+
+func init() {
+	db.RegisterModel(new(PackageChannel))
+	db.RegisterModel(new(PackagePromotion))
+}
+
+// PackageChannel is a named pointer like "staging" or "prod" to a version of a package
+type PackageChannel struct {
+	ID           int64              `xorm:"pk autoincr"`
+	PackageID    int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Name         string             `xorm:"UNIQUE(s) NOT NULL"`
+	VersionID    int64              `xorm:"INDEX NOT NULL"`
+	PromotedByID int64              `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix  timeutil.TimeStamp `xorm:"created NOT NULL"`
+	UpdatedUnix  timeutil.TimeStamp `xorm:"updated NOT NULL"`
+}
+
+// SetChannel points the channel of the package to the version
+func SetChannel(ctx context.Context, pc *PackageChannel) error {
+	pc.Name = strings.ToLower(pc.Name)
+
+	e := db.GetEngine(ctx)
+
+	existing := &PackageChannel{}
+	has, err := e.Where(builder.Eq{
+		"package_id": pc.PackageID,
+		"name":       pc.Name,
+	}).Get(existing)
+	if err != nil {
+		return err
+	}
+	if !has {
+		_, err = e.Insert(pc)
+		return err
+	}
+	pc.ID = existing.ID
+	pc.CreatedUnix = existing.CreatedUnix
+	_, err = e.ID(pc.ID).Cols("version_id", "promoted_by_id").Update(pc)
+	return err
+}
+
+// GetChannel gets a channel of the package
+func GetChannel(ctx context.Context, packageID int64, name string) (*PackageChannel, error) {
+	pc := &PackageChannel{}
+	has, err := db.GetEngine(ctx).Where(builder.Eq{
+		"package_id": packageID,
+		"name":       strings.ToLower(name),
+	}).Get(pc)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrPackageChannelNotExist
+	}
+	return pc, nil
+}
+
+// GetChannelsByPackageID gets all channels of the package
+func GetChannelsByPackageID(ctx context.Context, packageID int64) ([]*PackageChannel, error) {
+	pcs := make([]*PackageChannel, 0, 5)
+	return pcs, db.GetEngine(ctx).Where("package_id = ?", packageID).OrderBy("name").Find(&pcs)
+}
+
+// GetChannelsByVersionID gets all channels which point to the version
+func GetChannelsByVersionID(ctx context.Context, versionID int64) ([]*PackageChannel, error) {
+	pcs := make([]*PackageChannel, 0, 2)
+	return pcs, db.GetEngine(ctx).Where("version_id = ?", versionID).OrderBy("name").Find(&pcs)
+}
+
+// DeleteChannel deletes a channel of the package
+func DeleteChannel(ctx context.Context, packageID int64, name string) error {
+	n, err := db.GetEngine(ctx).Where(builder.Eq{
+		"package_id": packageID,
+		"name":       strings.ToLower(name),
+	}).Delete(&PackageChannel{})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrPackageChannelNotExist
+	}
+	return nil
+}
+
+// DeleteChannelsByVersionID deletes all channels which point to the version
+func DeleteChannelsByVersionID(ctx context.Context, versionID int64) error {
+	_, err := db.GetEngine(ctx).Where("version_id = ?", versionID).Delete(&PackageChannel{})
+	return err
+}
+
+// PackagePromotion records the promotion of a version to a channel or to another owner
+type PackagePromotion struct {
+	ID              int64              `xorm:"pk autoincr"`
+	VersionID       int64              `xorm:"INDEX NOT NULL"`
+	SourceVersionID int64              `xorm:"NOT NULL DEFAULT 0"`
+	SourceOwnerID   int64              `xorm:"NOT NULL DEFAULT 0"`
+	Channel         string             `xorm:"NOT NULL DEFAULT ''"`
+	DoerID          int64              `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix     timeutil.TimeStamp `xorm:"created INDEX NOT NULL"`
+}
+
+// InsertPromotion records a promotion
+func InsertPromotion(ctx context.Context, pp *PackagePromotion) error {
+	return db.Insert(ctx, pp)
+}
+
+// GetPromotionsByVersionID gets the promotion history of a version, newest first
+func GetPromotionsByVersionID(ctx context.Context, versionID int64) ([]*PackagePromotion, error) {
+	pps := make([]*PackagePromotion, 0, 5)
+	return pps, db.GetEngine(ctx).Where("version_id = ?", versionID).OrderBy("created_unix DESC, id DESC").Find(&pps)
+}
+
+// DeletePromotionsByVersionID deletes the promotion history of a version
+func DeletePromotionsByVersionID(ctx context.Context, versionID int64) error {
+	_, err := db.GetEngine(ctx).Where("version_id = ?", versionID).Delete(&PackagePromotion{})
+	return err
+}
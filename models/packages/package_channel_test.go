@@ -0,0 +1,77 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package packages_test
+
+import (
+	"testing"
+
+	"forgejo.org/models/db"
+	packages_model "forgejo.org/models/packages"
+	"forgejo.org/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageChannel(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	require.NoError(t, packages_model.SetChannel(db.DefaultContext, &packages_model.PackageChannel{
+		PackageID:    1,
+		Name:         "Staging",
+		VersionID:    10,
+		PromotedByID: 2,
+	}))
+
+	pc, err := packages_model.GetChannel(db.DefaultContext, 1, "staging")
+	require.NoError(t, err)
+	assert.Equal(t, "staging", pc.Name)
+	assert.EqualValues(t, 10, pc.VersionID)
+
+	// moving the channel keeps a single entry
+	require.NoError(t, packages_model.SetChannel(db.DefaultContext, &packages_model.PackageChannel{
+		PackageID:    1,
+		Name:         "staging",
+		VersionID:    11,
+		PromotedByID: 3,
+	}))
+	require.NoError(t, packages_model.SetChannel(db.DefaultContext, &packages_model.PackageChannel{
+		PackageID: 1,
+		Name:      "prod",
+		VersionID: 10,
+	}))
+
+	pcs, err := packages_model.GetChannelsByPackageID(db.DefaultContext, 1)
+	require.NoError(t, err)
+	require.Len(t, pcs, 2)
+	assert.Equal(t, "prod", pcs[0].Name)
+	assert.Equal(t, "staging", pcs[1].Name)
+	assert.EqualValues(t, 11, pcs[1].VersionID)
+	assert.EqualValues(t, 3, pcs[1].PromotedByID)
+
+	require.NoError(t, packages_model.DeleteChannelsByVersionID(db.DefaultContext, 10))
+	_, err = packages_model.GetChannel(db.DefaultContext, 1, "prod")
+	require.ErrorIs(t, err, packages_model.ErrPackageChannelNotExist)
+
+	require.NoError(t, packages_model.DeleteChannel(db.DefaultContext, 1, "STAGING"))
+	require.ErrorIs(t, packages_model.DeleteChannel(db.DefaultContext, 1, "staging"), packages_model.ErrPackageChannelNotExist)
+}
+
+func TestPackagePromotion(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	require.NoError(t, packages_model.InsertPromotion(db.DefaultContext, &packages_model.PackagePromotion{VersionID: 5, SourceVersionID: 4, SourceOwnerID: 2, Channel: "staging", DoerID: 2}))
+	require.NoError(t, packages_model.InsertPromotion(db.DefaultContext, &packages_model.PackagePromotion{VersionID: 5, SourceVersionID: 5, SourceOwnerID: 3, Channel: "prod", DoerID: 3}))
+
+	pps, err := packages_model.GetPromotionsByVersionID(db.DefaultContext, 5)
+	require.NoError(t, err)
+	require.Len(t, pps, 2)
+	assert.Equal(t, "prod", pps[0].Channel)
+	assert.Equal(t, "staging", pps[1].Channel)
+
+	require.NoError(t, packages_model.DeletePromotionsByVersionID(db.DefaultContext, 5))
+	pps, err = packages_model.GetPromotionsByVersionID(db.DefaultContext, 5)
+	require.NoError(t, err)
+	assert.Empty(t, pps)
+}
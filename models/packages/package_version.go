@@ -16,8 +16,12 @@ import (
 	"xorm.io/builder"
 )
 
-// ErrDuplicatePackageVersion indicates a duplicated package version error
-var ErrDuplicatePackageVersion = util.NewAlreadyExistErrorf("package version already exists")
+var (
+	// ErrDuplicatePackageVersion indicates a duplicated package version error
+	ErrDuplicatePackageVersion = util.NewAlreadyExistErrorf("package version already exists")
+	// ErrPackageVersionImmutable indicates that a locked package version can't be changed
+	ErrPackageVersionImmutable = util.NewPermissionDeniedErrorf("package version is immutable")
+)
 
 func init() {
 	db.RegisterModel(new(PackageVersion))
@@ -34,6 +38,19 @@ type PackageVersion struct {
 	IsInternal    bool               `xorm:"INDEX NOT NULL DEFAULT false"`
 	MetadataJSON  string             `xorm:"metadata_json LONGTEXT"`
 	DownloadCount int64              `xorm:"NOT NULL DEFAULT 0"`
+	IsImmutable   bool               `xorm:"NOT NULL DEFAULT false"`
+	// build provenance of versions published by an Actions run
+	ProvenanceRepoID     int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+	ProvenanceRunID      int64  `xorm:"NOT NULL DEFAULT 0"`
+	ProvenanceTaskID     int64  `xorm:"NOT NULL DEFAULT 0"`
+	ProvenanceCommitSHA  string `xorm:"VARCHAR(64)"`
+	ProvenanceRef        string
+	ProvenanceWorkflowID string
+}
+
+// HasProvenance returns if the version was published by an Actions run
+func (pv *PackageVersion) HasProvenance() bool {
+	return pv.ProvenanceRunID != 0
 }
 
 // GetOrInsertVersion inserts a version. If the same version exist already ErrDuplicatePackageVersion is returned
@@ -64,6 +81,12 @@ func UpdateVersion(ctx context.Context, pv *PackageVersion) error {
 	return err
 }
 
+// SetVersionImmutable locks a version so that it can't be overwritten or deleted
+func SetVersionImmutable(ctx context.Context, versionID int64) error {
+	_, err := db.GetEngine(ctx).ID(versionID).Cols("is_immutable").Update(&PackageVersion{IsImmutable: true})
+	return err
+}
+
 // IncrementDownloadCounter increments the download counter of a version
 func IncrementDownloadCounter(ctx context.Context, versionID int64) error {
 	_, err := db.GetEngine(ctx).Exec("UPDATE `package_version` SET `download_count` = `download_count` + 1 WHERE `id` = ?", versionID)
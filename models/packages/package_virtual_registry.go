@@ -0,0 +1,114 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package packages
+
+import (
+	"context"
+	"strings"
+
+	"forgejo.org/models/db"
+	"forgejo.org/modules/timeutil"
+	"forgejo.org/modules/util"
+
+	"xorm.io/builder"
+)
+
+var (
+	// ErrVirtualRegistryNotExist indicates a virtual registry not exist error
+	ErrVirtualRegistryNotExist = util.NewNotExistErrorf("virtual registry does not exist")
+	// ErrDuplicateVirtualRegistry indicates a duplicated virtual registry error
+	ErrDuplicateVirtualRegistry = util.NewAlreadyExistErrorf("virtual registry already exists")
+)
+
+func init() {
+	db.RegisterModel(new(PackageVirtualRegistry))
+}
+
+// VirtualRegistryTypes are the package types which can be served by a virtual registry
+var VirtualRegistryTypes = []Type{
+	TypeMaven,
+	TypeNpm,
+	TypePyPI,
+}
+
+// IsVirtualRegistryType checks if the package type can be served by a virtual registry
+func IsVirtualRegistryType(t Type) bool {
+	for _, vt := range VirtualRegistryTypes {
+		if vt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// PackageVirtualRegistry aggregates the packages of an ordered list of owners
+// (and optionally an upstream registry) behind a single registry URL.
+type PackageVirtualRegistry struct {
+	ID          int64              `xorm:"pk autoincr"`
+	OwnerID     int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Type        Type               `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Name        string             `xorm:"NOT NULL"`
+	LowerName   string             `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	MemberIDs   []int64            `xorm:"JSON TEXT"`
+	UpstreamURL string             `xorm:"upstream_url NOT NULL DEFAULT ''"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL DEFAULT 0"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated NOT NULL DEFAULT 0"`
+}
+
+// InsertVirtualRegistry inserts a virtual registry. If a registry with the same name exists already ErrDuplicateVirtualRegistry is returned
+func InsertVirtualRegistry(ctx context.Context, vr *PackageVirtualRegistry) (*PackageVirtualRegistry, error) {
+	vr.LowerName = strings.ToLower(vr.Name)
+
+	has, err := db.GetEngine(ctx).Where(builder.Eq{
+		"owner_id":   vr.OwnerID,
+		"type":       vr.Type,
+		"lower_name": vr.LowerName,
+	}).Exist(&PackageVirtualRegistry{})
+	if err != nil {
+		return nil, err
+	}
+	if has {
+		return nil, ErrDuplicateVirtualRegistry
+	}
+	return vr, db.Insert(ctx, vr)
+}
+
+// UpdateVirtualRegistry updates the members and upstream of a virtual registry
+func UpdateVirtualRegistry(ctx context.Context, vr *PackageVirtualRegistry) error {
+	_, err := db.GetEngine(ctx).ID(vr.ID).Cols("member_ids", "upstream_url").Update(vr)
+	return err
+}
+
+// GetVirtualRegistryByName gets a virtual registry of an owner by type and name
+func GetVirtualRegistryByName(ctx context.Context, ownerID int64, packageType Type, name string) (*PackageVirtualRegistry, error) {
+	vr := &PackageVirtualRegistry{}
+
+	has, err := db.GetEngine(ctx).Where(builder.Eq{
+		"owner_id":   ownerID,
+		"type":       packageType,
+		"lower_name": strings.ToLower(name),
+	}).Get(vr)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrVirtualRegistryNotExist
+	}
+	return vr, nil
+}
+
+// GetVirtualRegistriesByOwner gets all virtual registries of an owner
+func GetVirtualRegistriesByOwner(ctx context.Context, ownerID int64) ([]*PackageVirtualRegistry, error) {
+	vrs := make([]*PackageVirtualRegistry, 0, 5)
+	return vrs, db.GetEngine(ctx).Where("owner_id = ?", ownerID).OrderBy("type, lower_name").Find(&vrs)
+}
+
+// DeleteVirtualRegistryByID deletes a virtual registry
+func DeleteVirtualRegistryByID(ctx context.Context, id int64) error {
+	n, err := db.GetEngine(ctx).ID(id).Delete(&PackageVirtualRegistry{})
+	if n == 0 && err == nil {
+		return ErrVirtualRegistryNotExist
+	}
+	return err
+}
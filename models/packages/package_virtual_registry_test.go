@@ -0,0 +1,61 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package packages_test
+
+import (
+	"testing"
+
+	"forgejo.org/models/db"
+	packages_model "forgejo.org/models/packages"
+	"forgejo.org/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVirtualRegistry(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	vr, err := packages_model.InsertVirtualRegistry(db.DefaultContext, &packages_model.PackageVirtualRegistry{
+		OwnerID:   2,
+		Type:      packages_model.TypeNpm,
+		Name:      "Combined",
+		MemberIDs: []int64{3, 2},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "combined", vr.LowerName)
+
+	_, err = packages_model.InsertVirtualRegistry(db.DefaultContext, &packages_model.PackageVirtualRegistry{
+		OwnerID: 2,
+		Type:    packages_model.TypeNpm,
+		Name:    "COMBINED",
+	})
+	require.ErrorIs(t, err, packages_model.ErrDuplicateVirtualRegistry)
+
+	// the same name can be used for another package type
+	_, err = packages_model.InsertVirtualRegistry(db.DefaultContext, &packages_model.PackageVirtualRegistry{
+		OwnerID: 2,
+		Type:    packages_model.TypePyPI,
+		Name:    "combined",
+	})
+	require.NoError(t, err)
+
+	vr, err = packages_model.GetVirtualRegistryByName(db.DefaultContext, 2, packages_model.TypeNpm, "combined")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 2}, vr.MemberIDs)
+
+	vr.MemberIDs = []int64{2}
+	vr.UpstreamURL = "https://registry.npmjs.org"
+	require.NoError(t, packages_model.UpdateVirtualRegistry(db.DefaultContext, vr))
+
+	vrs, err := packages_model.GetVirtualRegistriesByOwner(db.DefaultContext, 2)
+	require.NoError(t, err)
+	require.Len(t, vrs, 2)
+	assert.Equal(t, []int64{2}, vrs[0].MemberIDs)
+	assert.Equal(t, "https://registry.npmjs.org", vrs[0].UpstreamURL)
+
+	require.NoError(t, packages_model.DeleteVirtualRegistryByID(db.DefaultContext, vr.ID))
+	_, err = packages_model.GetVirtualRegistryByName(db.DefaultContext, 2, packages_model.TypeNpm, "combined")
+	require.ErrorIs(t, err, packages_model.ErrVirtualRegistryNotExist)
+}
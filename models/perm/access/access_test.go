@@ -10,6 +10,7 @@ import (
 	perm_model "forgejo.org/models/perm"
 	access_model "forgejo.org/models/perm/access"
 	repo_model "forgejo.org/models/repo"
+	"forgejo.org/models/unit"
 	"forgejo.org/models/unittest"
 	user_model "forgejo.org/models/user"
 
@@ -125,3 +126,24 @@ func TestRepository_RecalculateAccesses2(t *testing.T) {
 	require.NoError(t, err)
 	assert.False(t, has)
 }
+
+func TestGetUserRepoPermissionOfBot(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	// bots which do not act for an application keep their owner and collaborator access
+	_, err := db.GetEngine(db.DefaultContext).In("id", []int64{4, 5}).Cols("type").Update(&user_model.User{Type: user_model.UserTypeBot})
+	require.NoError(t, err)
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5})
+	collaborator := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4})
+	require.True(t, collaborator.IsBot())
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 4})
+
+	perm, err := access_model.GetUserRepoPermission(db.DefaultContext, repo, owner)
+	require.NoError(t, err)
+	assert.True(t, perm.IsOwner())
+
+	perm, err = access_model.GetUserRepoPermission(db.DefaultContext, repo, collaborator)
+	require.NoError(t, err)
+	assert.Equal(t, perm_model.AccessModeWrite, perm.AccessMode)
+	assert.True(t, perm.CanWrite(unit.TypeCode))
+}
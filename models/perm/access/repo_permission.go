@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 
+	auth_model "forgejo.org/models/auth"
 	"forgejo.org/models/db"
 	"forgejo.org/models/organization"
 	perm_model "forgejo.org/models/perm"
@@ -64,6 +65,19 @@ func (p *Permission) UnitAccessMode(unitType unit.Type) perm_model.AccessMode {
 	return p.UnitsMode[unitType]
 }
 
+// LimitUnitsMode lowers the access to every unit of the repository to the limit of the unit,
+// the limited permission never allows administrating the repository
+func (p *Permission) LimitUnitsMode(limit func(unit.Type) perm_model.AccessMode) {
+	unitsMode := make(map[unit.Type]perm_model.AccessMode)
+	for _, u := range p.Units {
+		if mode := min(p.UnitAccessMode(u.Type), limit(u.Type)); mode > perm_model.AccessModeNone {
+			unitsMode[u.Type] = mode
+		}
+	}
+	p.UnitsMode = unitsMode
+	p.AccessMode = min(p.AccessMode, perm_model.AccessModeRead)
+}
+
 // CanAccess returns true if user has mode access to the unit of the repository
 func (p *Permission) CanAccess(mode perm_model.AccessMode, unitType unit.Type) bool {
 	return p.UnitAccessMode(unitType) >= mode
@@ -174,6 +188,14 @@ func GetUserRepoPermission(ctx context.Context, repo *repo_model.Repository, use
 		return perm, err
 	}
 
+	// bots of applications access the repositories they are installed for, other bots are regular users
+	if user != nil && user.IsBot() {
+		botPerm, isAppBot, err := getBotRepoPermission(ctx, repo, user)
+		if err != nil || isAppBot {
+			return botPerm, err
+		}
+	}
+
 	// Prevent strangers from checking out public repo of private organization/users
 	// Allow user if they are collaborator of a repo within a private user or a private organization but not a member of the organization itself
 	if !organization.HasOrgOrUserVisible(ctx, repo.Owner, user) && !isCollaborator {
@@ -284,6 +306,31 @@ func GetUserRepoPermission(ctx context.Context, repo *repo_model.Repository, use
 	return perm, err
 }
 
+// getBotRepoPermission returns the permissions the installation of the application acting as the bot grants,
+// isAppBot is false if the bot does not act for an application
+func getBotRepoPermission(ctx context.Context, repo *repo_model.Repository, bot *user_model.User) (perm Permission, isAppBot bool, err error) {
+	inst, isAppBot, err := auth_model.GetBotRepoInstallation(ctx, bot.ID, repo.ID, repo.OwnerID)
+	if err != nil || inst == nil {
+		return perm, isAppBot, err
+	}
+	if err := repo.LoadUnits(ctx); err != nil {
+		return perm, true, err
+	}
+
+	perm.UnitsMode = make(map[unit.Type]perm_model.AccessMode)
+	perm.Units = make([]*repo_model.RepoUnit, 0, len(repo.Units))
+	for _, u := range repo.Units {
+		if mode := inst.Permissions.UnitAccessMode(u.Type); mode > perm_model.AccessModeNone {
+			perm.UnitsMode[u.Type] = mode
+			perm.Units = append(perm.Units, u)
+		}
+	}
+	if len(perm.UnitsMode) > 0 {
+		perm.AccessMode = perm_model.AccessModeRead
+	}
+	return perm, true, nil
+}
+
 // IsUserRealRepoAdmin check if this user is real repo admin
 func IsUserRealRepoAdmin(ctx context.Context, repo *repo_model.Repository, user *user_model.User) (bool, error) {
 	if repo.OwnerID == user.ID {
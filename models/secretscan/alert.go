@@ -0,0 +1,197 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package secretscan
+
+import (
+	"context"
+	"fmt"
+
+	"forgejo.org/models/db"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/optional"
+	"forgejo.org/modules/timeutil"
+	"forgejo.org/modules/util"
+
+	"xorm.io/builder"
+)
+
+// AlertState is the state of a secret scanning alert
+type AlertState string
+
+const (
+	// AlertStateOpen is the state of the secrets which have not been handled yet
+	AlertStateOpen AlertState = "open"
+	// AlertStateRevoked is the state of the secrets which have been revoked so they can no longer be used
+	AlertStateRevoked AlertState = "revoked"
+	// AlertStateFalsePositive is the state of the alerts about values which are not secrets
+	AlertStateFalsePositive AlertState = "false_positive"
+)
+
+// IsValid returns whether the state is known
+func (s AlertState) IsValid() bool {
+	switch s {
+	case AlertStateOpen, AlertStateRevoked, AlertStateFalsePositive:
+		return true
+	}
+	return false
+}
+
+// Alert is a secret found in the content of a repository, either when it was pushed or when its history was scanned
+type Alert struct {
+	ID     int64 `xorm:"pk autoincr"`
+	RepoID int64 `xorm:"UNIQUE(s) NOT NULL"`
+	// Fingerprint identifies the secret in the file, the same secret is only reported once per file
+	Fingerprint string `xorm:"UNIQUE(s) VARCHAR(64) NOT NULL"`
+	DetectorID  string `xorm:"NOT NULL"`
+	// Redacted is the secret with most of its characters hidden, the secret itself is not stored
+	Redacted string
+	CommitID string `xorm:"VARCHAR(64)"`
+	Path     string `xorm:"TEXT"`
+	Line     int
+	State    AlertState `xorm:"INDEX NOT NULL DEFAULT 'open'"`
+	// BypassedByID is the user who pushed the secret while bypassing the push protection
+	BypassedByID int64 `xorm:"NOT NULL DEFAULT 0"`
+	ClosedByID   int64 `xorm:"NOT NULL DEFAULT 0"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+
+	BypassedBy *user_model.User `xorm:"-"`
+	ClosedBy   *user_model.User `xorm:"-"`
+}
+
+func init() {
+	db.RegisterModel(new(Alert))
+}
+
+// TableName sets the table name to `secret_scanning_alert`
+func (a *Alert) TableName() string {
+	return "secret_scanning_alert"
+}
+
+// IsOpen returns whether the alert still needs to be handled
+func (a *Alert) IsOpen() bool {
+	return a.State == AlertStateOpen
+}
+
+// AlertList is a list of secret scanning alerts
+type AlertList []*Alert
+
+// LoadUsers loads the users who bypassed the push protection and who closed the alerts
+func (alerts AlertList) LoadUsers(ctx context.Context) error {
+	ids := make([]int64, 0, len(alerts)*2)
+	for _, a := range alerts {
+		ids = append(ids, a.BypassedByID, a.ClosedByID)
+	}
+	users, err := user_model.GetPossibleUserByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	userMap := make(map[int64]*user_model.User, len(users))
+	for _, u := range users {
+		userMap[u.ID] = u
+	}
+	for _, a := range alerts {
+		if a.BypassedByID > 0 {
+			a.BypassedBy = userMap[a.BypassedByID]
+			if a.BypassedBy == nil {
+				a.BypassedBy = user_model.NewGhostUser()
+			}
+		}
+		if a.ClosedByID > 0 {
+			a.ClosedBy = userMap[a.ClosedByID]
+			if a.ClosedBy == nil {
+				a.ClosedBy = user_model.NewGhostUser()
+			}
+		}
+	}
+	return nil
+}
+
+// ErrAlertNotExist represents a "AlertNotExist" kind of error.
+type ErrAlertNotExist struct {
+	ID int64
+}
+
+// IsErrAlertNotExist checks if an error is a ErrAlertNotExist.
+func IsErrAlertNotExist(err error) bool {
+	_, ok := err.(ErrAlertNotExist)
+	return ok
+}
+
+func (err ErrAlertNotExist) Error() string {
+	return fmt.Sprintf("secret scanning alert does not exist [id: %d]", err.ID)
+}
+
+func (err ErrAlertNotExist) Unwrap() error {
+	return util.ErrNotExist
+}
+
+// InsertAlerts stores the alerts about secrets which have not been reported in the repository yet
+func InsertAlerts(ctx context.Context, alerts []*Alert) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		for _, a := range alerts {
+			has, err := db.GetEngine(ctx).Exist(&Alert{RepoID: a.RepoID, Fingerprint: a.Fingerprint})
+			if err != nil {
+				return err
+			} else if has {
+				continue
+			}
+			if err := db.Insert(ctx, a); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetAlertByID returns the alert of the repository
+func GetAlertByID(ctx context.Context, repoID, id int64) (*Alert, error) {
+	a := new(Alert)
+	has, err := db.GetEngine(ctx).Where("id = ? AND repo_id = ?", id, repoID).Get(a)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrAlertNotExist{ID: id}
+	}
+	return a, nil
+}
+
+// SetState opens or closes the alert, closedByID is the user who closed it
+func (a *Alert) SetState(ctx context.Context, state AlertState, closedByID int64) error {
+	a.State = state
+	a.ClosedByID = 0
+	if state != AlertStateOpen {
+		a.ClosedByID = closedByID
+	}
+	_, err := db.GetEngine(ctx).ID(a.ID).Cols("state", "closed_by_id").Update(a)
+	return err
+}
+
+// FindAlertsOptions represents the options to find secret scanning alerts
+type FindAlertsOptions struct {
+	db.ListOptions
+	RepoID int64
+	// IsOpen filters the alerts which still need to be handled or the closed ones
+	IsOpen optional.Option[bool]
+}
+
+func (opts FindAlertsOptions) ToConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if opts.IsOpen.Has() {
+		if opts.IsOpen.Value() {
+			cond = cond.And(builder.Eq{"state": AlertStateOpen})
+		} else {
+			cond = cond.And(builder.Neq{"state": AlertStateOpen})
+		}
+	}
+	return cond
+}
+
+func (opts FindAlertsOptions) ToOrders() string {
+	return "id DESC"
+}
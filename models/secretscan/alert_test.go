@@ -0,0 +1,68 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package secretscan_test
+
+import (
+	"testing"
+
+	"forgejo.org/models/db"
+	secretscan_model "forgejo.org/models/secretscan"
+	"forgejo.org/models/unittest"
+	"forgejo.org/modules/optional"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertAlerts(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	alerts := []*secretscan_model.Alert{
+		{RepoID: 1, Fingerprint: "a", DetectorID: "private_key", State: secretscan_model.AlertStateOpen},
+		{RepoID: 1, Fingerprint: "b", DetectorID: "aws_access_key_id", State: secretscan_model.AlertStateOpen, BypassedByID: 2},
+	}
+	require.NoError(t, secretscan_model.InsertAlerts(db.DefaultContext, alerts))
+	unittest.AssertCount(t, &secretscan_model.Alert{RepoID: 1}, 2)
+
+	// the secrets already reported are skipped
+	require.NoError(t, secretscan_model.InsertAlerts(db.DefaultContext, []*secretscan_model.Alert{
+		{RepoID: 1, Fingerprint: "a", DetectorID: "private_key", State: secretscan_model.AlertStateOpen},
+		{RepoID: 2, Fingerprint: "a", DetectorID: "private_key", State: secretscan_model.AlertStateOpen},
+	}))
+	unittest.AssertCount(t, &secretscan_model.Alert{RepoID: 1}, 2)
+	unittest.AssertCount(t, &secretscan_model.Alert{RepoID: 2}, 1)
+
+	list, err := db.Find[secretscan_model.Alert](db.DefaultContext, secretscan_model.FindAlertsOptions{RepoID: 1})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.NoError(t, secretscan_model.AlertList(list).LoadUsers(db.DefaultContext))
+	assert.Equal(t, "user2", list[0].BypassedBy.Name)
+	assert.Nil(t, list[1].BypassedBy)
+}
+
+func TestAlertSetState(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	alert := &secretscan_model.Alert{RepoID: 1, Fingerprint: "a", DetectorID: "private_key", State: secretscan_model.AlertStateOpen}
+	require.NoError(t, secretscan_model.InsertAlerts(db.DefaultContext, []*secretscan_model.Alert{alert}))
+
+	_, err := secretscan_model.GetAlertByID(db.DefaultContext, 2, alert.ID)
+	assert.True(t, secretscan_model.IsErrAlertNotExist(err))
+
+	require.NoError(t, alert.SetState(db.DefaultContext, secretscan_model.AlertStateRevoked, 2))
+	alert, err = secretscan_model.GetAlertByID(db.DefaultContext, 1, alert.ID)
+	require.NoError(t, err)
+	assert.False(t, alert.IsOpen())
+	assert.EqualValues(t, 2, alert.ClosedByID)
+
+	closed, err := db.Count[secretscan_model.Alert](db.DefaultContext, secretscan_model.FindAlertsOptions{RepoID: 1, IsOpen: optional.Some(false)})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, closed)
+
+	require.NoError(t, alert.SetState(db.DefaultContext, secretscan_model.AlertStateOpen, 2))
+	alert, err = secretscan_model.GetAlertByID(db.DefaultContext, 1, alert.ID)
+	require.NoError(t, err)
+	assert.True(t, alert.IsOpen())
+	assert.Zero(t, alert.ClosedByID)
+}
@@ -0,0 +1,20 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package secretscan_test
+
+import (
+	"testing"
+
+	"forgejo.org/models/unittest"
+
+	_ "forgejo.org/models"
+	_ "forgejo.org/models/actions"
+	_ "forgejo.org/models/activities"
+	_ "forgejo.org/models/forgefed"
+	_ "forgejo.org/models/secretscan"
+)
+
+func TestMain(m *testing.M) {
+	unittest.MainTest(m)
+}
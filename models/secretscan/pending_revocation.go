@@ -0,0 +1,66 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package secretscan
+
+import (
+	"context"
+
+	"forgejo.org/models/db"
+	"forgejo.org/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// PendingRevocation is an access token found in a push to a public repository, it is revoked once the push is accepted.
+// The post-receive hook is only told about the updated refs, so it is stored for each new commit of the push.
+type PendingRevocation struct {
+	ID       int64  `xorm:"pk autoincr"`
+	RepoID   int64  `xorm:"INDEX(s) NOT NULL"`
+	CommitID string `xorm:"INDEX(s) VARCHAR(64) NOT NULL"`
+	TokenID  int64  `xorm:"NOT NULL"`
+	// Link is the link to the line of the pushed file which contains the token
+	Link        string             `xorm:"TEXT"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+func init() {
+	db.RegisterModel(new(PendingRevocation))
+}
+
+// TableName sets the table name to `secret_scanning_pending_revocation`
+func (r *PendingRevocation) TableName() string {
+	return "secret_scanning_pending_revocation"
+}
+
+// InsertPendingRevocations stores the access tokens found in a push until it is accepted
+func InsertPendingRevocations(ctx context.Context, revocations []*PendingRevocation) error {
+	if len(revocations) == 0 {
+		return nil
+	}
+	return db.Insert(ctx, revocations)
+}
+
+// TakePendingRevocations deletes and returns the access tokens found for the new commits of an accepted push
+func TakePendingRevocations(ctx context.Context, repoID int64, commitIDs []string) ([]*PendingRevocation, error) {
+	if len(commitIDs) == 0 {
+		return nil, nil
+	}
+	var revocations []*PendingRevocation
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		cond := builder.Eq{"repo_id": repoID}.And(builder.In("commit_id", commitIDs))
+		if err := db.GetEngine(ctx).Where(cond).Find(&revocations); err != nil {
+			return err
+		}
+		_, err := db.GetEngine(ctx).Where(cond).Delete(new(PendingRevocation))
+		return err
+	})
+	return revocations, err
+}
+
+// DeletePendingRevocationsBefore deletes the access tokens found in pushes which have not been accepted since the time,
+// e.g. because another hook rejected them
+func DeletePendingRevocationsBefore(ctx context.Context, before timeutil.TimeStamp) error {
+	_, err := db.GetEngine(ctx).Where(builder.Lt{"created_unix": before}).Delete(new(PendingRevocation))
+	return err
+}
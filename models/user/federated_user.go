@@ -17,6 +17,7 @@ type FederatedUser struct {
 	KeyID                 sql.NullString         `xorm:"key_id UNIQUE"`
 	PublicKey             sql.Null[sql.RawBytes] `xorm:"BLOB"`
 	NormalizedOriginalURL string                 // This field is just to keep original information. Pls. do not use for search or as ID!
+	InboxURL              string                 `xorm:"VARCHAR(255)"`
 }
 
 func NewFederatedUser(userID int64, externalID string, federationHostID int64, normalizedOriginalURL string) (FederatedUser, error) {
@@ -32,6 +33,15 @@ func NewFederatedUser(userID int64, externalID string, federationHostID int64, n
 	return result, nil
 }
 
+// GetInboxURL returns the inbox activities for the user are delivered to.
+// Users which were created before the inbox was stored use the inbox location of Forgejo.
+func (user FederatedUser) GetInboxURL() string {
+	if user.InboxURL != "" {
+		return user.InboxURL
+	}
+	return user.NormalizedOriginalURL + "/inbox"
+}
+
 func (user FederatedUser) Validate() []string {
 	var result []string
 	result = append(result, validation.ValidateNotEmpty(user.UserID, "UserID")...)
@@ -18,8 +18,18 @@ type Follow struct {
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 }
 
+// FollowRequest represents a follow of a user of another instance which was not accepted yet.
+type FollowRequest struct {
+	ID          int64              `xorm:"pk autoincr"`
+	UserID      int64              `xorm:"UNIQUE(follow_request)"`
+	FollowID    int64              `xorm:"UNIQUE(follow_request)"`
+	ActivityID  string             `xorm:"VARCHAR(255) INDEX"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
 func init() {
 	db.RegisterModel(new(Follow))
+	db.RegisterModel(new(FollowRequest))
 }
 
 // IsFollowing returns true if user is following followID.
@@ -83,3 +93,60 @@ func UnfollowUser(ctx context.Context, userID, followID int64) (err error) {
 	}
 	return committer.Commit()
 }
+
+// CreateFollowRequest records the Follow activity sent to a federated user.
+// An older request of the same user is replaced.
+func CreateFollowRequest(ctx context.Context, userID, followID int64, activityID string) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := db.DeleteByBean(ctx, &FollowRequest{UserID: userID, FollowID: followID}); err != nil {
+			return err
+		}
+		return db.Insert(ctx, &FollowRequest{UserID: userID, FollowID: followID, ActivityID: activityID})
+	})
+}
+
+// GetFollowRequest returns the pending follow request of user for followID or nil if there is none.
+func GetFollowRequest(ctx context.Context, userID, followID int64) (*FollowRequest, error) {
+	fr := &FollowRequest{}
+	has, err := db.GetEngine(ctx).Where("user_id = ? AND follow_id = ?", userID, followID).Get(fr)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, nil
+	}
+	return fr, nil
+}
+
+// DeleteFollowRequest removes a pending follow request.
+func DeleteFollowRequest(ctx context.Context, userID, followID int64) error {
+	_, err := db.DeleteByBean(ctx, &FollowRequest{UserID: userID, FollowID: followID})
+	return err
+}
+
+// AcceptFollowRequest turns a pending follow request into a follow.
+func AcceptFollowRequest(ctx context.Context, fr *FollowRequest) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := db.DeleteByID[FollowRequest](ctx, fr.ID); err != nil {
+			return err
+		}
+		return FollowUser(ctx, fr.UserID, fr.FollowID)
+	})
+}
+
+// GetFederatedFollowers returns the followers of a user which are users of other instances.
+func GetFederatedFollowers(ctx context.Context, userID int64) ([]*FederatedUser, error) {
+	federatedUsers := make([]*FederatedUser, 0, 10)
+	return federatedUsers, db.GetEngine(ctx).
+		Join("INNER", "follow", "`follow`.user_id = `federated_user`.user_id").
+		Where("`follow`.follow_id = ?", userID).
+		Find(&federatedUsers)
+}
+
+// HasFederatedFollowers returns true if at least one user of another instance follows the user.
+func HasFederatedFollowers(ctx context.Context, userID int64) (bool, error) {
+	return db.GetEngine(ctx).
+		Join("INNER", "follow", "`follow`.user_id = `federated_user`.user_id").
+		Where("`follow`.follow_id = ?", userID).
+		Exist(new(FederatedUser))
+}
@@ -22,3 +22,25 @@ func TestIsFollowing(t *testing.T) {
 	assert.False(t, user_model.IsFollowing(db.DefaultContext, unittest.NonexistentID, 5))
 	assert.False(t, user_model.IsFollowing(db.DefaultContext, unittest.NonexistentID, unittest.NonexistentID))
 }
+
+func TestFollowRequest(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	require.NoError(t, user_model.CreateFollowRequest(db.DefaultContext, 2, 5, "https://example.org/follows/1"))
+	require.NoError(t, user_model.CreateFollowRequest(db.DefaultContext, 2, 5, "https://example.org/follows/2"))
+	unittest.AssertCount(t, &user_model.FollowRequest{UserID: 2, FollowID: 5}, 1)
+
+	fr, err := user_model.GetFollowRequest(db.DefaultContext, 2, 5)
+	require.NoError(t, err)
+	require.NotNil(t, fr)
+	assert.Equal(t, "https://example.org/follows/2", fr.ActivityID)
+	assert.False(t, user_model.IsFollowing(db.DefaultContext, 2, 5))
+
+	require.NoError(t, user_model.AcceptFollowRequest(db.DefaultContext, fr))
+	assert.True(t, user_model.IsFollowing(db.DefaultContext, 2, 5))
+	unittest.AssertNotExistsBean(t, &user_model.FollowRequest{UserID: 2, FollowID: 5})
+
+	fr, err = user_model.GetFollowRequest(db.DefaultContext, 2, 5)
+	require.NoError(t, err)
+	assert.Nil(t, fr)
+}
@@ -10,6 +10,12 @@ const (
 	SettingsKeyDiffWhitespaceBehavior = "diff.whitespace_behaviour"
 	// SettingsKeyShowOutdatedComments is the setting key whether or not to show outdated comments in PRs
 	SettingsKeyShowOutdatedComments = "comment_code.show_outdated"
+	// SettingsKeyIPAllowlistMode is the setting key for how the IP allowlist of an organization is applied
+	SettingsKeyIPAllowlistMode = "ip_allowlist.mode"
+	// SettingsKeyTwoFactorRequired is the setting key whether an organization requires its members to enable two-factor authentication
+	SettingsKeyTwoFactorRequired = "two_factor.required"
+	// SettingsKeyTwoFactorGraceDeadline is the setting key for when the grace period of a user to enable two-factor authentication ends
+	SettingsKeyTwoFactorGraceDeadline = "two_factor.grace_deadline"
 	// UserActivityPubPrivPem is user's private key
 	UserActivityPubPrivPem = "activitypub.priv_pem"
 	// UserActivityPubPubPem is user's public key
@@ -0,0 +1,48 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package user
+
+import (
+	"context"
+	"strconv"
+
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/timeutil"
+)
+
+// StartTwoFactorGracePeriod starts the period in which the user must enable two-factor authentication,
+// it replaces the grace period the user had before
+func StartTwoFactorGracePeriod(ctx context.Context, uid int64) (timeutil.TimeStamp, error) {
+	deadline := timeutil.TimeStampNow().AddDuration(setting.TwoFactorGracePeriod)
+	return deadline, SetUserSetting(ctx, uid, SettingsKeyTwoFactorGraceDeadline, strconv.FormatInt(int64(deadline), 10))
+}
+
+// GetTwoFactorGraceDeadline returns when the period in which the user must enable two-factor authentication
+// ends. The grace period of a user who has none yet starts now, so that it also ends for the users who never
+// sign in with a password, e.g. because they only use access tokens or SSH.
+func GetTwoFactorGraceDeadline(ctx context.Context, uid int64) (timeutil.TimeStamp, error) {
+	started, deadline, err := getTwoFactorGraceDeadline(ctx, uid)
+	if err != nil || started {
+		return deadline, err
+	}
+	return StartTwoFactorGracePeriod(ctx, uid)
+}
+
+// HasTwoFactorGracePeriod returns whether the grace period of the user has started
+func HasTwoFactorGracePeriod(ctx context.Context, uid int64) (bool, error) {
+	started, _, err := getTwoFactorGraceDeadline(ctx, uid)
+	return started, err
+}
+
+func getTwoFactorGraceDeadline(ctx context.Context, uid int64) (bool, timeutil.TimeStamp, error) {
+	value, err := GetSetting(ctx, uid, SettingsKeyTwoFactorGraceDeadline)
+	if err != nil && !IsErrUserSettingIsNotExist(err) {
+		return false, 0, err
+	}
+	deadline, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false, 0, nil
+	}
+	return true, timeutil.TimeStamp(deadline), nil
+}
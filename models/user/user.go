@@ -383,10 +383,13 @@ func (u *User) NewGitSig() *git.Signature {
 // SetPassword hashes a password using the algorithm defined in the config value of PASSWORD_HASH_ALGO
 // change passwd, salt and passwd_hash_algo fields
 func (u *User) SetPassword(passwd string) (err error) {
-	// Invalidate all authentication tokens for this user.
+	// Invalidate all authentication tokens and sessions for this user.
 	if err := auth.DeleteAuthTokenByUser(db.DefaultContext, u.ID); err != nil {
 		return err
 	}
+	if err := auth.DeleteUserSessionsByUser(db.DefaultContext, u.ID, 0); err != nil {
+		return err
+	}
 
 	u.Salt = GetUserSalt()
 	if u.Passwd, err = hash.Parse(setting.PasswordHashAlgo).Hash(passwd, u.Salt); err != nil {
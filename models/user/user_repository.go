@@ -101,6 +101,18 @@ func FindFederatedUserByKeyID(ctx context.Context, keyID string) (*User, *Federa
 	return user, federatedUser, nil
 }
 
+// GetFederatedUserByUserID returns the federated user of a local user or nil if the user is not a federated one
+func GetFederatedUserByUserID(ctx context.Context, userID int64) (*FederatedUser, error) {
+	federatedUser := new(FederatedUser)
+	has, err := db.GetEngine(ctx).Where("user_id=?", userID).Get(federatedUser)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return federatedUser, nil
+}
+
 func DeleteFederatedUser(ctx context.Context, userID int64) error {
 	_, err := db.GetEngine(ctx).Delete(&FederatedUser{UserID: userID})
 	return err
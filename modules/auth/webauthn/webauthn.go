@@ -6,6 +6,7 @@ package webauthn
 import (
 	"encoding/binary"
 	"encoding/gob"
+	"errors"
 
 	"forgejo.org/models/auth"
 	"forgejo.org/models/db"
@@ -48,6 +49,16 @@ func (u *User) WebAuthnID() []byte {
 	return id
 }
 
+// UserIDFromWebAuthnID returns the user id encoded in the user handle of a discoverable credential,
+// it is the reverse of WebAuthnID
+func UserIDFromWebAuthnID(id []byte) (int64, error) {
+	uid, n := binary.Varint(id)
+	if n <= 0 || uid <= 0 {
+		return 0, errors.New("invalid user handle")
+	}
+	return uid, nil
+}
+
 // WebAuthnName implements the webauthn.User interface
 func (u *User) WebAuthnName() string {
 	if u.LoginName == "" {
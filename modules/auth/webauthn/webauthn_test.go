@@ -10,6 +10,7 @@ import (
 	"forgejo.org/modules/test"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestInit(t *testing.T) {
@@ -23,3 +24,14 @@ func TestInit(t *testing.T) {
 	assert.Equal(t, setting.AppName, WebAuthn.Config.RPDisplayName)
 	assert.Equal(t, []string{"https://domain"}, WebAuthn.Config.RPOrigins)
 }
+
+func TestUserIDFromWebAuthnID(t *testing.T) {
+	uid, err := UserIDFromWebAuthnID((&User{ID: 1234}).WebAuthnID())
+	require.NoError(t, err)
+	assert.EqualValues(t, 1234, uid)
+
+	_, err = UserIDFromWebAuthnID(nil)
+	require.Error(t, err)
+	_, err = UserIDFromWebAuthnID((&User{ID: -1}).WebAuthnID())
+	require.Error(t, err)
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forgefed
+
+import (
+	"forgejo.org/modules/validation"
+
+	ap "github.com/go-ap/activitypub"
+	"github.com/google/uuid"
+)
+
+// ForgeAccept activity data type
+// swagger:model
+type ForgeAccept struct {
+	// swagger:ignore
+	ap.Activity
+}
+
+func NewForgeAccept(actor string, follow ap.Activity) (ForgeAccept, error) {
+	result := ForgeAccept{}
+	result.Type = ap.AcceptType
+	result.ID = ap.IRI(actor + "/accepts/" + uuid.New().String())
+	result.Actor = ap.IRI(actor)
+	result.Object = &follow
+	if valid, err := validation.IsValid(result); !valid {
+		return ForgeAccept{}, err
+	}
+	return result, nil
+}
+
+// NewForgeAcceptOffer creates the Accept of an Offer{Ticket}, the result is the Ticket the offer became
+func NewForgeAcceptOffer(actor, offerID, ticketID string) (ForgeAccept, error) {
+	result := ForgeAccept{}
+	result.Type = ap.AcceptType
+	result.ID = ap.IRI(actor + "/accepts/" + uuid.New().String())
+	result.Actor = ap.IRI(actor)
+	result.Object = ap.IRI(offerID)
+	result.Result = ap.IRI(ticketID)
+	if valid, err := validation.IsValid(result); !valid {
+		return ForgeAccept{}, err
+	}
+	return result, nil
+}
+
+func (accept ForgeAccept) MarshalJSON() ([]byte, error) {
+	return accept.Activity.MarshalJSON()
+}
+
+func (accept *ForgeAccept) UnmarshalJSON(data []byte) error {
+	return accept.Activity.UnmarshalJSON(data)
+}
+
+func (accept ForgeAccept) Validate() []string {
+	var result []string
+	result = append(result, validation.ValidateNotEmpty(string(accept.Type), "type")...)
+	result = append(result, validation.ValidateOneOf(string(accept.Type), []any{"Accept"}, "type")...)
+	result = append(result, validation.ValidateIDExists(accept.Actor, "actor")...)
+	result = append(result, validation.ValidateIDExists(accept.Object, "object")...)
+
+	return result
+}
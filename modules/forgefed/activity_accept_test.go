@@ -0,0 +1,48 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forgefed
+
+import (
+	"testing"
+
+	"forgejo.org/modules/validation"
+
+	ap "github.com/go-ap/activitypub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewForgeAccept(t *testing.T) {
+	follow, err := NewForgeFollow("https://example.org/api/v1/activitypub/user-id/1", "https://forgejo.example/api/v1/activitypub/user-id/2")
+	require.NoError(t, err)
+
+	accept, err := NewForgeAccept("https://forgejo.example/api/v1/activitypub/user-id/2", follow.Activity)
+	require.NoError(t, err)
+	assert.Equal(t, ap.AcceptType, accept.Type)
+	assert.Equal(t, follow.ID, accept.Object.GetID())
+
+	json, err := accept.MarshalJSON()
+	require.NoError(t, err)
+
+	unmarshalled := ForgeAccept{}
+	require.NoError(t, unmarshalled.UnmarshalJSON(json))
+	assert.Equal(t, accept.ID, unmarshalled.ID)
+	assert.Equal(t, follow.ID, unmarshalled.Object.GetID())
+}
+
+func Test_ForgeAcceptValidation(t *testing.T) {
+	sut := ForgeAccept{}
+	sut.Type = "Accept"
+	sut.Actor = ap.IRI("example.org/bob")
+	sut.Object = ap.IRI("example.org/alice/follows/1")
+
+	if err, _ := validation.IsValid(sut); !err {
+		t.Errorf("sut is invalid: %v\n", err)
+	}
+
+	sut.Type = "Follow"
+	if err, _ := validation.IsValid(sut); err {
+		t.Errorf("sut is valid: %v\n", err)
+	}
+}
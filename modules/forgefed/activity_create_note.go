@@ -0,0 +1,87 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forgefed
+
+import (
+	"time"
+
+	"forgejo.org/modules/validation"
+
+	ap "github.com/go-ap/activitypub"
+)
+
+// ForgeCreateNote activity data type, used for comments on tickets
+// swagger:model
+type ForgeCreateNote struct {
+	// swagger:ignore
+	ap.Activity
+}
+
+// NewForgeNote creates a Note which replies to inReplyTo
+func NewForgeNote(id, actor, inReplyTo, url, content string, published time.Time) ap.Object {
+	note := ap.Object{}
+	note.Type = ap.NoteType
+	note.ID = ap.IRI(id)
+	note.AttributedTo = ap.IRI(actor)
+	note.InReplyTo = ap.IRI(inReplyTo)
+	note.URL = ap.IRI(url)
+	note.MediaType = "text/html"
+	note.Content = ap.NaturalLanguageValues{
+		{
+			Ref:   ap.NilLangRef,
+			Value: ap.Content(content),
+		},
+	}
+	note.Published = published
+	return note
+}
+
+func NewForgeCreateNote(actor string, note ap.Object) (ForgeCreateNote, error) {
+	result := ForgeCreateNote{}
+	result.Type = ap.CreateType
+	result.ID = note.ID + "/create"
+	result.Actor = ap.IRI(actor)
+	result.Published = note.Published
+	result.Object = &note
+	if valid, err := validation.IsValid(result); !valid {
+		return ForgeCreateNote{}, err
+	}
+	return result, nil
+}
+
+func (create ForgeCreateNote) MarshalJSON() ([]byte, error) {
+	return create.Activity.MarshalJSON()
+}
+
+func (create *ForgeCreateNote) UnmarshalJSON(data []byte) error {
+	return create.Activity.UnmarshalJSON(data)
+}
+
+// Note returns the Note which is created
+func (create ForgeCreateNote) Note() *ap.Object {
+	note, _ := create.Object.(*ap.Object)
+	return note
+}
+
+func (create ForgeCreateNote) Validate() []string {
+	var result []string
+	result = append(result, validation.ValidateNotEmpty(string(create.Type), "type")...)
+	result = append(result, validation.ValidateOneOf(string(create.Type), []any{"Create"}, "type")...)
+	result = append(result, validation.ValidateIDExists(create.Actor, "actor")...)
+
+	note := create.Note()
+	if note == nil {
+		result = append(result, "object is not of type Note")
+		return result
+	}
+	result = append(result, validation.ValidateOneOf(string(note.Type), []any{"Note"}, "type")...)
+	result = append(result, validation.ValidateNotEmpty(note.ID.String(), "id")...)
+	result = append(result, validation.ValidateIDExists(note.InReplyTo, "inReplyTo")...)
+	result = append(result, validation.ValidateNotEmpty(note.Content.String(), "content")...)
+	if note.AttributedTo != nil && note.AttributedTo.GetID() != create.Actor.GetID() {
+		result = append(result, "note is not attributed to the actor")
+	}
+
+	return result
+}
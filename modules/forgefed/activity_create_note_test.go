@@ -0,0 +1,54 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forgefed
+
+import (
+	"testing"
+	"time"
+
+	"forgejo.org/modules/validation"
+
+	ap "github.com/go-ap/activitypub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewForgeCreateNote(t *testing.T) {
+	actor := "https://example.org/api/v1/activitypub/user-id/1"
+	ticket := "https://forgejo.example/api/v1/activitypub/repository-id/1/issues/1"
+	note := NewForgeNote(actor+"/notes/1", actor, ticket, "https://example.org/notes/1", "<p>hello</p>", time.Now())
+
+	create, err := NewForgeCreateNote(actor, note)
+	require.NoError(t, err)
+
+	json, err := create.MarshalJSON()
+	require.NoError(t, err)
+
+	unmarshalled := ForgeCreateNote{}
+	require.NoError(t, unmarshalled.UnmarshalJSON(json))
+	if valid, err := validation.IsValid(unmarshalled); !valid {
+		t.Errorf("unmarshalled create is invalid: %v", err)
+	}
+	assert.Equal(t, ap.IRI(ticket), unmarshalled.Note().InReplyTo.GetID())
+	assert.Equal(t, "<p>hello</p>", unmarshalled.Note().Content.String())
+}
+
+func Test_ForgeCreateNoteValidation(t *testing.T) {
+	actor := "https://example.org/api/v1/activitypub/user-id/1"
+	note := NewForgeNote(actor+"/notes/1", "https://example.org/api/v1/activitypub/user-id/2", "https://forgejo.example/issues/1", "https://example.org/notes/1", "hello", time.Now())
+
+	sut := ForgeCreateNote{}
+	sut.Type = ap.CreateType
+	sut.Actor = ap.IRI(actor)
+	sut.Object = &note
+	if valid, _ := validation.IsValid(sut); valid {
+		t.Errorf("note attributed to another actor is valid")
+	}
+
+	note.AttributedTo = ap.IRI(actor)
+	note.InReplyTo = nil
+	if valid, _ := validation.IsValid(sut); valid {
+		t.Errorf("note without inReplyTo is valid")
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forgefed
+
+import (
+	"strings"
+
+	"forgejo.org/modules/validation"
+
+	ap "github.com/go-ap/activitypub"
+	"github.com/google/uuid"
+	"github.com/valyala/fastjson"
+)
+
+// ForgeOfferTicket activity data type, used to offer a merge request to a repository
+// swagger:model
+type ForgeOfferTicket struct {
+	// swagger:ignore
+	ap.Activity
+	// swagger:ignore
+	Ticket *Ticket
+}
+
+func NewForgeOfferTicket(actor string, ticket *Ticket) (ForgeOfferTicket, error) {
+	result := ForgeOfferTicket{}
+	result.Type = ap.OfferType
+	result.ID = ap.IRI(actor + "/offers/" + uuid.New().String())
+	result.Actor = ap.IRI(actor)
+	result.Target = ticket.Target.Context
+	result.Object = ticket
+	result.Ticket = ticket
+	if valid, err := validation.IsValid(result); !valid {
+		return ForgeOfferTicket{}, err
+	}
+	return result, nil
+}
+
+func (offer ForgeOfferTicket) MarshalJSON() ([]byte, error) {
+	return offer.Activity.MarshalJSON()
+}
+
+func (offer *ForgeOfferTicket) UnmarshalJSON(data []byte) error {
+	if err := offer.Activity.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	p := fastjson.Parser{}
+	val, err := p.ParseBytes(data)
+	if err != nil {
+		return err
+	}
+	if object := val.Get("object"); object != nil && object.Type() == fastjson.TypeObject {
+		offer.Ticket = &Ticket{}
+		if err := JSONLoadTicket(object, offer.Ticket); err != nil {
+			return err
+		}
+		offer.Object = offer.Ticket
+	}
+	return nil
+}
+
+func validateBranch(branch *Branch, name string) []string {
+	if branch == nil {
+		return []string{name + " should not be nil."}
+	}
+	var result []string
+	result = append(result, validation.ValidateNotEmpty(branch.Context.String(), name+".context")...)
+	if !strings.HasPrefix(branch.Ref, "refs/heads/") {
+		result = append(result, name+".ref is not a branch")
+	}
+	return result
+}
+
+func (offer ForgeOfferTicket) Validate() []string {
+	var result []string
+	result = append(result, validation.ValidateNotEmpty(string(offer.Type), "type")...)
+	result = append(result, validation.ValidateOneOf(string(offer.Type), []any{"Offer"}, "type")...)
+	result = append(result, validation.ValidateIDExists(offer.Actor, "actor")...)
+	result = append(result, validation.ValidateNotEmpty(offer.ID.String(), "id")...)
+
+	if offer.Ticket == nil {
+		result = append(result, "object is not of type Ticket")
+		return result
+	}
+	result = append(result, validation.ValidateOneOf(string(offer.Ticket.Type), []any{"Ticket"}, "object.type")...)
+	result = append(result, validation.ValidateNotEmpty(offer.Ticket.Name.String(), "object.name")...)
+	result = append(result, validateBranch(offer.Ticket.Origin, "object.origin")...)
+	result = append(result, validateBranch(offer.Ticket.Target, "object.target")...)
+	if offer.Ticket.AttributedTo != nil && offer.Ticket.AttributedTo.GetID() != offer.Actor.GetID() {
+		result = append(result, "ticket is not attributed to the actor")
+	}
+
+	return result
+}
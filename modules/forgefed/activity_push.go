@@ -0,0 +1,111 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forgefed
+
+import (
+	"strings"
+
+	"forgejo.org/modules/validation"
+
+	ap "github.com/go-ap/activitypub"
+	"github.com/google/uuid"
+	"github.com/valyala/fastjson"
+)
+
+// PushType announces that commits were pushed to a branch of a repository
+const PushType ap.ActivityVocabularyType = "Push"
+
+// ForgePush activity data type
+// swagger:model
+type ForgePush struct {
+	// swagger:ignore
+	ap.Activity
+	// Branch is the ref the commits were pushed to, its context is the repository
+	// swagger:ignore
+	Branch *Branch
+	// HashBefore is the commit the ref pointed to before the push
+	HashBefore string
+	// HashAfter is the commit the ref points to after the push
+	HashAfter string
+}
+
+// NewForgePush creates a Push of the commits to the ref of a repository.
+// The object lists the ids of the pushed commits, oldest first.
+func NewForgePush(actor, repository, ref, hashBefore, hashAfter string, commits []string) (ForgePush, error) {
+	items := make(ap.ItemCollection, 0, len(commits))
+	for _, commit := range commits {
+		items = append(items, ap.IRI(commit))
+	}
+	collection := ap.OrderedCollectionNew("")
+	collection.OrderedItems = items
+	collection.TotalItems = uint(len(items))
+
+	result := ForgePush{}
+	result.Type = PushType
+	result.ID = ap.IRI(repository + "/pushes/" + uuid.New().String())
+	result.Actor = ap.IRI(actor)
+	result.Context = ap.IRI(repository)
+	result.Object = collection
+	result.Branch = &Branch{Context: ap.IRI(repository), Ref: ref}
+	result.HashBefore = hashBefore
+	result.HashAfter = hashAfter
+	if valid, err := validation.IsValid(result); !valid {
+		return ForgePush{}, err
+	}
+	return result, nil
+}
+
+func (push ForgePush) MarshalJSON() ([]byte, error) {
+	b, err := push.Activity.MarshalJSON()
+	if len(b) == 0 || err != nil {
+		return nil, err
+	}
+
+	b = b[:len(b)-1]
+	if push.Branch != nil {
+		v, _ := push.Branch.MarshalJSON()
+		ap.JSONWriteProp(&b, "target", v)
+	}
+	ap.JSONWriteStringProp(&b, "hashBefore", push.HashBefore)
+	ap.JSONWriteStringProp(&b, "hashAfter", push.HashAfter)
+	ap.JSONWrite(&b, '}')
+	return b, nil
+}
+
+func (push *ForgePush) UnmarshalJSON(data []byte) error {
+	if err := push.Activity.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	p := fastjson.Parser{}
+	val, err := p.ParseBytes(data)
+	if err != nil {
+		return err
+	}
+	push.Branch = jsonLoadBranch(val, "target")
+	push.HashBefore = ap.JSONGetString(val, "hashBefore")
+	push.HashAfter = ap.JSONGetString(val, "hashAfter")
+	return nil
+}
+
+func (push ForgePush) Validate() []string {
+	var result []string
+	result = append(result, validation.ValidateNotEmpty(string(push.Type), "type")...)
+	result = append(result, validation.ValidateOneOf(string(push.Type), []any{"Push"}, "type")...)
+	result = append(result, validation.ValidateIDExists(push.Actor, "actor")...)
+	result = append(result, validation.ValidateIDExists(push.Context, "context")...)
+
+	if push.Branch == nil {
+		result = append(result, "target is not of type Branch")
+		return result
+	}
+	if !strings.HasPrefix(push.Branch.Ref, "refs/") {
+		result = append(result, "target.ref is not a ref")
+	}
+	if push.Context != nil && push.Branch.Context != push.Context.GetID() {
+		result = append(result, "target is not a ref of the context")
+	}
+
+	return result
+}
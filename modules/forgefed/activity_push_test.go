@@ -0,0 +1,54 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forgefed
+
+import (
+	"testing"
+
+	"forgejo.org/modules/validation"
+
+	ap "github.com/go-ap/activitypub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewForgePush(t *testing.T) {
+	repository := "https://forgejo.example/api/v1/activitypub/repository-id/1"
+	push, err := NewForgePush("https://forgejo.example/api/v1/activitypub/user-id/2", repository, "refs/heads/main",
+		"65f1bf27bc3bf70f64657658635e66094edbcb4d", "2c54faec6c45d31c1abfaecdab471eac6633738a",
+		[]string{"https://forgejo.example/user2/repo1/commit/2c54faec6c45d31c1abfaecdab471eac6633738a"})
+	require.NoError(t, err)
+	assert.Equal(t, PushType, push.Type)
+
+	json, err := push.MarshalJSON()
+	require.NoError(t, err)
+
+	unmarshalled := ForgePush{}
+	require.NoError(t, unmarshalled.UnmarshalJSON(json))
+	assert.Equal(t, push.ID, unmarshalled.ID)
+	assert.Equal(t, PushType, unmarshalled.Type)
+	assert.Equal(t, ap.IRI(repository), unmarshalled.Context.GetID())
+	assert.Equal(t, &Branch{Context: ap.IRI(repository), Ref: "refs/heads/main"}, unmarshalled.Branch)
+	assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", unmarshalled.HashBefore)
+	assert.Equal(t, "2c54faec6c45d31c1abfaecdab471eac6633738a", unmarshalled.HashAfter)
+	valid, err := validation.IsValid(unmarshalled)
+	assert.True(t, valid, err)
+}
+
+func Test_ForgePushValidation(t *testing.T) {
+	push := ForgePush{}
+	push.Type = PushType
+	push.Actor = ap.IRI("https://forgejo.example/api/v1/activitypub/user-id/2")
+	push.Context = ap.IRI("https://forgejo.example/api/v1/activitypub/repository-id/1")
+	valid, _ := validation.IsValid(push)
+	assert.False(t, valid, "target is missing")
+
+	push.Branch = &Branch{Context: ap.IRI("https://forgejo.example/api/v1/activitypub/repository-id/2"), Ref: "refs/heads/main"}
+	valid, _ = validation.IsValid(push)
+	assert.False(t, valid, "target is a ref of another repository")
+
+	push.Branch.Context = push.Context.GetID()
+	valid, _ = validation.IsValid(push)
+	assert.True(t, valid)
+}
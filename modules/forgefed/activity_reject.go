@@ -0,0 +1,48 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forgefed
+
+import (
+	"forgejo.org/modules/validation"
+
+	ap "github.com/go-ap/activitypub"
+	"github.com/google/uuid"
+)
+
+// ForgeReject activity data type
+// swagger:model
+type ForgeReject struct {
+	// swagger:ignore
+	ap.Activity
+}
+
+func NewForgeReject(actor, object string) (ForgeReject, error) {
+	result := ForgeReject{}
+	result.Type = ap.RejectType
+	result.ID = ap.IRI(actor + "/rejects/" + uuid.New().String())
+	result.Actor = ap.IRI(actor)
+	result.Object = ap.IRI(object)
+	if valid, err := validation.IsValid(result); !valid {
+		return ForgeReject{}, err
+	}
+	return result, nil
+}
+
+func (reject ForgeReject) MarshalJSON() ([]byte, error) {
+	return reject.Activity.MarshalJSON()
+}
+
+func (reject *ForgeReject) UnmarshalJSON(data []byte) error {
+	return reject.Activity.UnmarshalJSON(data)
+}
+
+func (reject ForgeReject) Validate() []string {
+	var result []string
+	result = append(result, validation.ValidateNotEmpty(string(reject.Type), "type")...)
+	result = append(result, validation.ValidateOneOf(string(reject.Type), []any{"Reject"}, "type")...)
+	result = append(result, validation.ValidateIDExists(reject.Actor, "actor")...)
+	result = append(result, validation.ValidateIDExists(reject.Object, "object")...)
+
+	return result
+}
@@ -0,0 +1,28 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forgefed
+
+import (
+	"testing"
+
+	"forgejo.org/modules/validation"
+
+	ap "github.com/go-ap/activitypub"
+)
+
+func Test_ForgeRejectValidation(t *testing.T) {
+	sut := ForgeReject{}
+	sut.Type = "Reject"
+	sut.Actor = ap.IRI("example.org/repository-id/1")
+	sut.Object = ap.IRI("example.org/alice/offers/1")
+
+	if err, _ := validation.IsValid(sut); !err {
+		t.Errorf("sut is invalid: %v\n", err)
+	}
+
+	sut.Type = "Resolve"
+	if err, _ := validation.IsValid(sut); err {
+		t.Errorf("sut is valid: %v\n", err)
+	}
+}
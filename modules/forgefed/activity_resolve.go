@@ -0,0 +1,51 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forgefed
+
+import (
+	"forgejo.org/modules/validation"
+
+	ap "github.com/go-ap/activitypub"
+	"github.com/google/uuid"
+)
+
+// ResolveType marks a Ticket as resolved, e.g. a merged pull request
+const ResolveType ap.ActivityVocabularyType = "Resolve"
+
+// ForgeResolve activity data type
+// swagger:model
+type ForgeResolve struct {
+	// swagger:ignore
+	ap.Activity
+}
+
+func NewForgeResolve(actor, ticket string) (ForgeResolve, error) {
+	result := ForgeResolve{}
+	result.Type = ResolveType
+	result.ID = ap.IRI(actor + "/resolves/" + uuid.New().String())
+	result.Actor = ap.IRI(actor)
+	result.Object = ap.IRI(ticket)
+	if valid, err := validation.IsValid(result); !valid {
+		return ForgeResolve{}, err
+	}
+	return result, nil
+}
+
+func (resolve ForgeResolve) MarshalJSON() ([]byte, error) {
+	return resolve.Activity.MarshalJSON()
+}
+
+func (resolve *ForgeResolve) UnmarshalJSON(data []byte) error {
+	return resolve.Activity.UnmarshalJSON(data)
+}
+
+func (resolve ForgeResolve) Validate() []string {
+	var result []string
+	result = append(result, validation.ValidateNotEmpty(string(resolve.Type), "type")...)
+	result = append(result, validation.ValidateOneOf(string(resolve.Type), []any{"Resolve"}, "type")...)
+	result = append(result, validation.ValidateIDExists(resolve.Actor, "actor")...)
+	result = append(result, validation.ValidateIDExists(resolve.Object, "object")...)
+
+	return result
+}
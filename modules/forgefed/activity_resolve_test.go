@@ -0,0 +1,27 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forgefed
+
+import (
+	"testing"
+
+	ap "github.com/go-ap/activitypub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewForgeResolve(t *testing.T) {
+	resolve, err := NewForgeResolve("https://forgejo.example/api/v1/activitypub/repository-id/1", "https://forgejo.example/api/v1/activitypub/repository-id/1/issues/2")
+	require.NoError(t, err)
+	assert.Equal(t, ResolveType, resolve.Type)
+
+	json, err := resolve.MarshalJSON()
+	require.NoError(t, err)
+
+	unmarshalled := ForgeResolve{}
+	require.NoError(t, unmarshalled.UnmarshalJSON(json))
+	assert.Equal(t, resolve.ID, unmarshalled.ID)
+	assert.Equal(t, ResolveType, unmarshalled.Type)
+	assert.Equal(t, ap.IRI("https://forgejo.example/api/v1/activitypub/repository-id/1/issues/2"), unmarshalled.Object.GetID())
+}
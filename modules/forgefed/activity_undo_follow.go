@@ -0,0 +1,63 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forgefed
+
+import (
+	"forgejo.org/modules/validation"
+
+	ap "github.com/go-ap/activitypub"
+	"github.com/google/uuid"
+)
+
+// ForgeUndoFollow activity data type
+// swagger:model
+type ForgeUndoFollow struct {
+	// swagger:ignore
+	ap.Activity
+}
+
+func NewForgeUndoFollow(actor, object string) (ForgeUndoFollow, error) {
+	result := ForgeUndoFollow{}
+	result.Type = ap.UndoType
+	result.ID = ap.IRI(actor + "/undos/" + uuid.New().String())
+	result.Actor = ap.IRI(actor)
+
+	follow := ap.Activity{}
+	follow.Type = ap.FollowType
+	follow.Actor = ap.IRI(actor)
+	follow.Object = ap.IRI(object)
+	result.Object = &follow
+
+	if valid, err := validation.IsValid(result); !valid {
+		return ForgeUndoFollow{}, err
+	}
+	return result, nil
+}
+
+func (undo ForgeUndoFollow) MarshalJSON() ([]byte, error) {
+	return undo.Activity.MarshalJSON()
+}
+
+func (undo *ForgeUndoFollow) UnmarshalJSON(data []byte) error {
+	return undo.Activity.UnmarshalJSON(data)
+}
+
+func (undo ForgeUndoFollow) Validate() []string {
+	var result []string
+	result = append(result, validation.ValidateNotEmpty(string(undo.Type), "type")...)
+	result = append(result, validation.ValidateOneOf(string(undo.Type), []any{"Undo"}, "type")...)
+	result = append(result, validation.ValidateIDExists(undo.Actor, "actor")...)
+
+	if undo.Object == nil {
+		result = append(result, "object should not be empty.")
+	} else if activity, ok := undo.Object.(*ap.Activity); !ok {
+		result = append(result, "object is not of type Activity")
+	} else {
+		result = append(result, validation.ValidateOneOf(string(activity.Type), []any{"Follow"}, "type")...)
+		result = append(result, validation.ValidateIDExists(activity.Actor, "actor")...)
+		result = append(result, validation.ValidateIDExists(activity.Object, "object")...)
+	}
+
+	return result
+}
@@ -0,0 +1,47 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forgefed
+
+import (
+	"testing"
+
+	"forgejo.org/modules/validation"
+
+	ap "github.com/go-ap/activitypub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewForgeUndoFollow(t *testing.T) {
+	actor := "https://example.org/api/v1/activitypub/user-id/1"
+	object := "https://forgejo.example/api/v1/activitypub/user-id/2"
+
+	undo, err := NewForgeUndoFollow(actor, object)
+	require.NoError(t, err)
+
+	json, err := undo.MarshalJSON()
+	require.NoError(t, err)
+
+	unmarshalled := ForgeUndoFollow{}
+	require.NoError(t, unmarshalled.UnmarshalJSON(json))
+	if valid, err := validation.IsValid(unmarshalled); !valid {
+		t.Errorf("unmarshalled undo is invalid: %v", err)
+	}
+
+	follow, ok := unmarshalled.Object.(*ap.Activity)
+	require.True(t, ok)
+	assert.Equal(t, ap.FollowType, follow.Type)
+	assert.Equal(t, ap.IRI(object), follow.Object.GetID())
+}
+
+func Test_ForgeUndoFollowValidation(t *testing.T) {
+	sut := ForgeUndoFollow{}
+	sut.Type = "Undo"
+	sut.Actor = ap.IRI("example.org/alice")
+	sut.Object = &ap.Activity{Type: ap.LikeType, Actor: ap.IRI("example.org/alice"), Object: ap.IRI("example.org/bob")}
+
+	if err, _ := validation.IsValid(sut); err {
+		t.Errorf("sut is valid: %v\n", err)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forgefed
+
+import (
+	ap "github.com/go-ap/activitypub"
+	"github.com/valyala/fastjson"
+)
+
+const (
+	TicketType ap.ActivityVocabularyType = "Ticket"
+	BranchType ap.ActivityVocabularyType = "Branch"
+)
+
+// Branch references a branch of a repository
+type Branch struct {
+	// Context is the id of the repository
+	Context ap.IRI
+	// Ref is the full name of the branch, e.g. refs/heads/main
+	Ref string
+}
+
+func (b Branch) MarshalJSON() ([]byte, error) {
+	result := make([]byte, 0)
+	ap.JSONWrite(&result, '{')
+	ap.JSONWriteStringProp(&result, "type", string(BranchType))
+	ap.JSONWriteStringProp(&result, "context", b.Context.String())
+	ap.JSONWriteStringProp(&result, "ref", b.Ref)
+	ap.JSONWrite(&result, '}')
+	return result, nil
+}
+
+func jsonLoadBranch(val *fastjson.Value, prop string) *Branch {
+	if val = val.Get(prop); val == nil || val.Type() != fastjson.TypeObject {
+		return nil
+	}
+	if ap.JSONGetString(val, "type") != string(BranchType) {
+		return nil
+	}
+	return &Branch{
+		Context: ap.JSONGetIRI(val, "context"),
+		Ref:     ap.JSONGetString(val, "ref"),
+	}
+}
+
+// Ticket is an issue of a repository.
+// Tickets offered as merge requests reference the branch to merge and the branch to merge into.
+type Ticket struct {
+	ap.Object
+	// IsResolved tells whether the ticket was closed
+	IsResolved bool `jsonld:"isResolved"`
+	// Origin is the branch the changes of a merge request come from
+	Origin *Branch `jsonld:"origin,omitempty"`
+	// Target is the branch the changes of a merge request are merged into
+	Target *Branch `jsonld:"target,omitempty"`
+}
+
+// TicketNew initializes a Ticket type object
+func TicketNew(id ap.ID) *Ticket {
+	o := ap.ObjectNew(TicketType)
+	o.ID = id
+	return &Ticket{Object: *o}
+}
+
+func (t Ticket) MarshalJSON() ([]byte, error) {
+	b, err := t.Object.MarshalJSON()
+	if len(b) == 0 || err != nil {
+		return nil, err
+	}
+
+	b = b[:len(b)-1]
+	ap.JSONWriteBoolProp(&b, "isResolved", t.IsResolved)
+	if t.Origin != nil {
+		v, _ := t.Origin.MarshalJSON()
+		ap.JSONWriteProp(&b, "origin", v)
+	}
+	if t.Target != nil {
+		v, _ := t.Target.MarshalJSON()
+		ap.JSONWriteProp(&b, "target", v)
+	}
+	ap.JSONWrite(&b, '}')
+	return b, nil
+}
+
+// JSONLoadTicket loads a Ticket from a fastjson.Value
+func JSONLoadTicket(val *fastjson.Value, t *Ticket) error {
+	if err := ap.JSONLoadObject(val, &t.Object); err != nil {
+		return err
+	}
+	t.IsResolved = ap.JSONGetBoolean(val, "isResolved")
+	t.Origin = jsonLoadBranch(val, "origin")
+	t.Target = jsonLoadBranch(val, "target")
+	return nil
+}
+
+func (t *Ticket) UnmarshalJSON(data []byte) error {
+	p := fastjson.Parser{}
+	val, err := p.ParseBytes(data)
+	if err != nil {
+		return err
+	}
+	return JSONLoadTicket(val, t)
+}
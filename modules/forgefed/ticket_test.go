@@ -0,0 +1,61 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forgefed
+
+import (
+	"testing"
+
+	"forgejo.org/modules/json"
+	"forgejo.org/modules/validation"
+
+	ap "github.com/go-ap/activitypub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TicketMarshalJSON(t *testing.T) {
+	ticket := TicketNew("https://example.org/api/v1/activitypub/repository-id/1/issues/1")
+	ticket.Name = ap.DefaultNaturalLanguageValue("issue title")
+	ticket.IsResolved = true
+
+	b, err := ticket.MarshalJSON()
+	require.NoError(t, err)
+
+	var result map[string]any
+	require.NoError(t, json.Unmarshal(b, &result))
+	assert.Equal(t, "Ticket", result["type"])
+	assert.Equal(t, "issue title", result["name"])
+	assert.Equal(t, true, result["isResolved"])
+}
+
+func Test_ForgeOfferTicket(t *testing.T) {
+	actor := "https://example.org/api/v1/activitypub/user-id/1"
+	ticket := TicketNew("")
+	ticket.AttributedTo = ap.IRI(actor)
+	ticket.Name = ap.DefaultNaturalLanguageValue("add feature")
+	ticket.Content = ap.DefaultNaturalLanguageValue("please merge")
+	ticket.Origin = &Branch{Context: "https://example.org/api/v1/activitypub/repository-id/3", Ref: "refs/heads/feature"}
+	ticket.Target = &Branch{Context: "https://forgejo.example/api/v1/activitypub/repository-id/1", Ref: "refs/heads/main"}
+
+	offer, err := NewForgeOfferTicket(actor, ticket)
+	require.NoError(t, err)
+
+	b, err := offer.MarshalJSON()
+	require.NoError(t, err)
+
+	unmarshalled := ForgeOfferTicket{}
+	require.NoError(t, unmarshalled.UnmarshalJSON(b))
+	if valid, err := validation.IsValid(unmarshalled); !valid {
+		t.Errorf("unmarshalled offer is invalid: %v", err)
+	}
+	require.NotNil(t, unmarshalled.Ticket)
+	assert.Equal(t, "add feature", unmarshalled.Ticket.Name.String())
+	assert.Equal(t, *ticket.Origin, *unmarshalled.Ticket.Origin)
+	assert.Equal(t, *ticket.Target, *unmarshalled.Ticket.Target)
+
+	unmarshalled.Ticket.Origin.Ref = "refs/tags/v1"
+	if valid, _ := validation.IsValid(unmarshalled); valid {
+		t.Errorf("offer of a tag is valid")
+	}
+}
@@ -24,15 +24,23 @@ var _ EventWriter = (*eventWriterConn)(nil)
 
 func NewEventWriterConn(writerName string, writerMode WriterMode) EventWriter {
 	w := &eventWriterConn{EventWriterBaseImpl: NewEventWriterBase(writerName, "conn", writerMode)}
-	opt := writerMode.WriterOption.(WriterConnOption)
-	w.connWriter = connWriter{
+	w.connWriter = *newConnWriter(writerMode.WriterOption.(WriterConnOption))
+	w.OutputWriteCloser = &w.connWriter
+	return w
+}
+
+// NewConnWriter returns a writer which sends every write to the network address of the option, it connects on the first write.
+func NewConnWriter(opt WriterConnOption) io.WriteCloser {
+	return newConnWriter(opt)
+}
+
+func newConnWriter(opt WriterConnOption) *connWriter {
+	return &connWriter{
 		ReconnectOnMsg: opt.ReconnectOnMsg,
 		Reconnect:      opt.Reconnect,
 		Net:            opt.Protocol,
 		Addr:           opt.Addr,
 	}
-	w.OutputWriteCloser = &w.connWriter
-	return w
 }
 
 func init() {
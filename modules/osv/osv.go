@@ -0,0 +1,236 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package osv implements the subset of the Open Source Vulnerability format
+// (https://ossf.github.io/osv-schema/) which is needed to match package
+// dependencies against advisories.
+package osv
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"forgejo.org/modules/json"
+
+	"github.com/hashicorp/go-version"
+)
+
+// Ecosystems as named by the OSV schema
+const (
+	EcosystemCargo     = "crates.io"
+	EcosystemMaven     = "Maven"
+	EcosystemNpm       = "npm"
+	EcosystemPackagist = "Packagist"
+	EcosystemPyPI      = "PyPI"
+)
+
+// Severities of an advisory
+const (
+	SeverityUnknown  = "UNKNOWN"
+	SeverityLow      = "LOW"
+	SeverityModerate = "MODERATE"
+	SeverityHigh     = "HIGH"
+	SeverityCritical = "CRITICAL"
+)
+
+// Range types
+const (
+	RangeTypeSemver    = "SEMVER"
+	RangeTypeEcosystem = "ECOSYSTEM"
+	RangeTypeGit       = "GIT"
+)
+
+var ErrInvalidRecord = errors.New("invalid OSV record")
+
+// Record is a single vulnerability entry
+type Record struct {
+	ID               string           `json:"id"`
+	Modified         time.Time        `json:"modified"`
+	Published        time.Time        `json:"published"`
+	Withdrawn        *time.Time       `json:"withdrawn,omitempty"`
+	Aliases          []string         `json:"aliases,omitempty"`
+	Summary          string           `json:"summary,omitempty"`
+	Details          string           `json:"details,omitempty"`
+	Affected         []*Affected      `json:"affected,omitempty"`
+	References       []*Reference     `json:"references,omitempty"`
+	DatabaseSpecific DatabaseSpecific `json:"database_specific"`
+}
+
+// DatabaseSpecific contains the fields used by the GitHub advisory database
+type DatabaseSpecific struct {
+	Severity string `json:"severity,omitempty"`
+}
+
+// Affected describes the affected versions of a package
+type Affected struct {
+	Package          Package          `json:"package"`
+	Ranges           []*Range         `json:"ranges,omitempty"`
+	Versions         []string         `json:"versions,omitempty"`
+	DatabaseSpecific DatabaseSpecific `json:"database_specific"`
+}
+
+// Package identifies a package in an ecosystem
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+// Range is a list of events which mark affected version intervals
+type Range struct {
+	Type   string   `json:"type"`
+	Events []*Event `json:"events"`
+}
+
+// Event is a version boundary of a range
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// Reference is a link with further information
+type Reference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// ParseRecord parses a single OSV record
+func ParseRecord(r io.Reader) (*Record, error) {
+	var rec Record
+	if err := json.NewDecoder(r).Decode(&rec); err != nil {
+		return nil, err
+	}
+	if rec.ID == "" {
+		return nil, ErrInvalidRecord
+	}
+	return &rec, nil
+}
+
+// Severity returns the normalized severity of the record for the affected package
+func (r *Record) Severity(a *Affected) string {
+	s := a.DatabaseSpecific.Severity
+	if s == "" {
+		s = r.DatabaseSpecific.Severity
+	}
+	switch s = strings.ToUpper(s); s {
+	case SeverityLow, SeverityModerate, SeverityHigh, SeverityCritical:
+		return s
+	case "MEDIUM":
+		return SeverityModerate
+	default:
+		return SeverityUnknown
+	}
+}
+
+// AdvisoryURL returns the most relevant reference of the record
+func (r *Record) AdvisoryURL() string {
+	for _, ref := range r.References {
+		if ref.Type == "ADVISORY" {
+			return ref.URL
+		}
+	}
+	if len(r.References) > 0 {
+		return r.References[0].URL
+	}
+	return ""
+}
+
+var pypiNormalizer = regexp.MustCompile(`[-_.]+`)
+
+// NormalizePackageName returns the canonical form of a package name which is used for comparisons
+func NormalizePackageName(ecosystem, name string) string {
+	name = strings.TrimSpace(name)
+	switch ecosystem {
+	case EcosystemPyPI:
+		return pypiNormalizer.ReplaceAllString(strings.ToLower(name), "-")
+	case EcosystemMaven:
+		// Maven coordinates are case sensitive
+		return name
+	default:
+		return strings.ToLower(name)
+	}
+}
+
+// IsVersionAffected checks if the version is affected.
+// Only SEMVER and ECOSYSTEM ranges are evaluated, GIT ranges are ignored.
+func (a *Affected) IsVersionAffected(v string) bool {
+	for _, av := range a.Versions {
+		if av == v {
+			return true
+		}
+	}
+
+	sv, err := version.NewVersion(v)
+	if err != nil {
+		return false
+	}
+
+	for _, r := range a.Ranges {
+		if r.Type != RangeTypeSemver && r.Type != RangeTypeEcosystem {
+			continue
+		}
+		if r.contains(sv) {
+			return true
+		}
+	}
+	return false
+}
+
+type boundary struct {
+	version *version.Version // nil means "0"
+	event   *Event
+}
+
+func (r *Range) contains(v *version.Version) bool {
+	boundaries := make([]*boundary, 0, len(r.Events))
+	for _, e := range r.Events {
+		value := e.Introduced + e.Fixed + e.LastAffected + e.Limit
+		if e.Introduced == "0" {
+			boundaries = append(boundaries, &boundary{event: e})
+			continue
+		}
+		bv, err := version.NewVersion(value)
+		if err != nil {
+			return false
+		}
+		boundaries = append(boundaries, &boundary{version: bv, event: e})
+	}
+
+	sort.SliceStable(boundaries, func(i, j int) bool {
+		if boundaries[i].version == nil {
+			return boundaries[j].version != nil
+		}
+		if boundaries[j].version == nil {
+			return false
+		}
+		return boundaries[i].version.LessThan(boundaries[j].version)
+	})
+
+	affected := false
+	for _, b := range boundaries {
+		switch {
+		case b.event.Introduced != "":
+			if b.version == nil || !v.LessThan(b.version) {
+				affected = true
+			}
+		case b.event.Fixed != "":
+			if !v.LessThan(b.version) {
+				affected = false
+			}
+		case b.event.LastAffected != "":
+			if v.GreaterThan(b.version) {
+				affected = false
+			}
+		case b.event.Limit != "":
+			if !v.LessThan(b.version) {
+				return false
+			}
+		}
+	}
+	return affected
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package osv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lodashRecord = `{
+  "id": "GHSA-35jh-r3h4-6jhm",
+  "modified": "2024-02-16T08:13:38Z",
+  "published": "2021-05-06T16:05:51Z",
+  "aliases": ["CVE-2021-23337"],
+  "summary": "Command Injection in lodash",
+  "affected": [
+    {
+      "package": {"ecosystem": "npm", "name": "lodash"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]
+    }
+  ],
+  "references": [
+    {"type": "WEB", "url": "https://example.com/web"},
+    {"type": "ADVISORY", "url": "https://nvd.nist.gov/vuln/detail/CVE-2021-23337"}
+  ],
+  "database_specific": {"severity": "HIGH"}
+}`
+
+func TestParseRecord(t *testing.T) {
+	rec, err := ParseRecord(strings.NewReader(lodashRecord))
+	require.NoError(t, err)
+	assert.Equal(t, "GHSA-35jh-r3h4-6jhm", rec.ID)
+	assert.Equal(t, []string{"CVE-2021-23337"}, rec.Aliases)
+	require.Len(t, rec.Affected, 1)
+	assert.Equal(t, EcosystemNpm, rec.Affected[0].Package.Ecosystem)
+	assert.Equal(t, SeverityHigh, rec.Severity(rec.Affected[0]))
+	assert.Equal(t, "https://nvd.nist.gov/vuln/detail/CVE-2021-23337", rec.AdvisoryURL())
+
+	_, err = ParseRecord(strings.NewReader(`{}`))
+	require.ErrorIs(t, err, ErrInvalidRecord)
+}
+
+func TestIsVersionAffected(t *testing.T) {
+	a := &Affected{
+		Ranges: []*Range{
+			{
+				Type: RangeTypeEcosystem,
+				Events: []*Event{
+					{Fixed: "1.5.0"},
+					{Introduced: "1.2.0"},
+					{Introduced: "2.0.0"},
+					{LastAffected: "2.1.3"},
+				},
+			},
+			{
+				Type:   RangeTypeGit,
+				Events: []*Event{{Introduced: "0"}},
+			},
+		},
+		Versions: []string{"0.9-special"},
+	}
+
+	cases := map[string]bool{
+		"0.9-special": true,
+		"1.0.0":       false,
+		"1.2.0":       true,
+		"1.4.9":       true,
+		"1.5.0":       false,
+		"1.9.0":       false,
+		"2.0.0":       true,
+		"2.1.3":       true,
+		"2.1.4":       false,
+		"invalid":     false,
+	}
+	for v, expected := range cases {
+		assert.Equal(t, expected, a.IsVersionAffected(v), "version %s", v)
+	}
+
+	all := &Affected{Ranges: []*Range{{Type: RangeTypeSemver, Events: []*Event{{Introduced: "0"}}}}}
+	assert.True(t, all.IsVersionAffected("0.0.1"))
+	assert.True(t, all.IsVersionAffected("99.0.0"))
+}
+
+func TestNormalizePackageName(t *testing.T) {
+	assert.Equal(t, "zope-interface", NormalizePackageName(EcosystemPyPI, "Zope_Interface"))
+	assert.Equal(t, "org.Example:Artifact", NormalizePackageName(EcosystemMaven, "org.Example:Artifact"))
+	assert.Equal(t, "@scope/name", NormalizePackageName(EcosystemNpm, "@Scope/Name"))
+}
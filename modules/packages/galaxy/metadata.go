@@ -0,0 +1,192 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package galaxy
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	"forgejo.org/modules/json"
+	"forgejo.org/modules/util"
+	"forgejo.org/modules/validation"
+
+	"github.com/hashicorp/go-version"
+)
+
+var (
+	ErrInvalidArchive       = util.NewInvalidArgumentErrorf("collection is not a valid tarball")
+	ErrMissingManifestFile  = util.NewInvalidArgumentErrorf("MANIFEST.json file is missing")
+	ErrManifestFileTooLarge = util.NewInvalidArgumentErrorf("MANIFEST.json file is too large")
+	ErrInvalidNamespace     = util.NewInvalidArgumentErrorf("collection namespace is invalid")
+	ErrInvalidName          = util.NewInvalidArgumentErrorf("collection name is invalid")
+	ErrInvalidVersion       = util.NewInvalidArgumentErrorf("collection version is invalid")
+)
+
+// https://docs.ansible.com/ansible/latest/dev_guide/collections_galaxy_meta.html
+var namePattern = regexp.MustCompile(`\A[a-z][a-z0-9_]{0,63}\z`)
+
+const (
+	maxManifestFileSize = 1024 * 1024
+	maxReadmeFileSize   = 1024 * 1024
+)
+
+// Package represents an Ansible collection
+type Package struct {
+	Namespace string
+	Name      string
+	Version   string
+	Metadata  *Metadata
+}
+
+// FullName gets the name of the collection in the namespace.name form which is used as package name
+func (p *Package) FullName() string {
+	return p.Namespace + "." + p.Name
+}
+
+// Metadata represents the metadata of an Ansible collection
+type Metadata struct {
+	Namespace        string            `json:"namespace"`
+	Name             string            `json:"name"`
+	Description      string            `json:"description,omitempty"`
+	Authors          []string          `json:"authors,omitempty"`
+	License          []string          `json:"license,omitempty"`
+	Tags             []string          `json:"tags,omitempty"`
+	Dependencies     map[string]string `json:"dependencies,omitempty"`
+	RepositoryURL    string            `json:"repository_url,omitempty"`
+	DocumentationURL string            `json:"documentation_url,omitempty"`
+	HomepageURL      string            `json:"homepage_url,omitempty"`
+	IssuesURL        string            `json:"issues_url,omitempty"`
+	Readme           string            `json:"readme,omitempty"`
+}
+
+type manifest struct {
+	CollectionInfo struct {
+		Namespace     string            `json:"namespace"`
+		Name          string            `json:"name"`
+		Version       string            `json:"version"`
+		Authors       []string          `json:"authors"`
+		Readme        string            `json:"readme"`
+		Tags          []string          `json:"tags"`
+		Description   string            `json:"description"`
+		License       []string          `json:"license"`
+		Dependencies  map[string]string `json:"dependencies"`
+		Repository    string            `json:"repository"`
+		Documentation string            `json:"documentation"`
+		Homepage      string            `json:"homepage"`
+		Issues        string            `json:"issues"`
+	} `json:"collection_info"`
+}
+
+// ParsePackage parses the collection tarball created by "ansible-galaxy collection build"
+func ParsePackage(r io.Reader) (*Package, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, ErrInvalidArchive
+	}
+	defer gzr.Close()
+
+	var p *Package
+	var readmeName string
+	// the readme file name is only known after reading the manifest, so all candidates are kept
+	readmes := make(map[string]string)
+
+	tr := tar.NewReader(gzr)
+	for {
+		hd, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidArchive
+		}
+
+		if hd.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(strings.TrimPrefix(hd.Name, "./"))
+		if name == "MANIFEST.json" {
+			if hd.Size > maxManifestFileSize {
+				return nil, ErrManifestFileTooLarge
+			}
+			p, readmeName, err = ParseManifest(io.LimitReader(tr, maxManifestFileSize))
+			if err != nil {
+				return nil, err
+			}
+		} else if strings.HasPrefix(strings.ToLower(path.Base(name)), "readme") && hd.Size <= maxReadmeFileSize {
+			data, err := io.ReadAll(io.LimitReader(tr, maxReadmeFileSize))
+			if err != nil {
+				return nil, err
+			}
+			readmes[name] = string(data)
+		}
+	}
+
+	if p == nil {
+		return nil, ErrMissingManifestFile
+	}
+
+	p.Metadata.Readme = readmes[path.Clean(readmeName)]
+
+	return p, nil
+}
+
+// ParseManifest parses the MANIFEST.json file of a collection.
+// It returns the package and the name of the readme file.
+func ParseManifest(r io.Reader) (*Package, string, error) {
+	var m manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, "", util.NewInvalidArgumentErrorf("MANIFEST.json file is invalid: %v", err)
+	}
+
+	ci := m.CollectionInfo
+
+	if !namePattern.MatchString(ci.Namespace) {
+		return nil, "", ErrInvalidNamespace
+	}
+	if !namePattern.MatchString(ci.Name) {
+		return nil, "", ErrInvalidName
+	}
+
+	v, err := version.NewSemver(ci.Version)
+	if err != nil {
+		return nil, "", ErrInvalidVersion
+	}
+
+	if !validation.IsValidURL(ci.Repository) {
+		ci.Repository = ""
+	}
+	if !validation.IsValidURL(ci.Documentation) {
+		ci.Documentation = ""
+	}
+	if !validation.IsValidURL(ci.Homepage) {
+		ci.Homepage = ""
+	}
+	if !validation.IsValidURL(ci.Issues) {
+		ci.Issues = ""
+	}
+
+	return &Package{
+		Namespace: ci.Namespace,
+		Name:      ci.Name,
+		Version:   v.String(),
+		Metadata: &Metadata{
+			Namespace:        ci.Namespace,
+			Name:             ci.Name,
+			Description:      ci.Description,
+			Authors:          ci.Authors,
+			License:          ci.License,
+			Tags:             ci.Tags,
+			Dependencies:     ci.Dependencies,
+			RepositoryURL:    ci.Repository,
+			DocumentationURL: ci.Documentation,
+			HomepageURL:      ci.Homepage,
+			IssuesURL:        ci.Issues,
+		},
+	}, ci.Readme, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package galaxy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	namespace         = "forgejo"
+	collectionName    = "tools"
+	collectionVersion = "1.0.1"
+	description       = "Collection Description"
+	repositoryURL     = "https://codeberg.org/forgejo/tools"
+	readme            = "# Tools"
+)
+
+const manifestContent = `{
+  "collection_info": {
+    "namespace": "` + namespace + `",
+    "name": "` + collectionName + `",
+    "version": "` + collectionVersion + `",
+    "authors": ["Forgejo"],
+    "readme": "README.md",
+    "tags": ["tools"],
+    "description": "` + description + `",
+    "license": ["MIT"],
+    "license_file": null,
+    "dependencies": {"community.general": ">=1.0.0"},
+    "repository": "` + repositoryURL + `",
+    "documentation": "not-a-url",
+    "homepage": null,
+    "issues": null
+  },
+  "file_manifest_file": {"name": "FILES.json", "ftype": "file", "format": 1},
+  "format": 1
+}`
+
+func TestParsePackage(t *testing.T) {
+	createArchive := func(files map[string]string) io.Reader {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(zw)
+		for filename, content := range files {
+			hdr := &tar.Header{
+				Name: filename,
+				Mode: 0o600,
+				Size: int64(len(content)),
+			}
+			tw.WriteHeader(hdr)
+			tw.Write([]byte(content))
+		}
+		tw.Close()
+		zw.Close()
+		return &buf
+	}
+
+	t.Run("InvalidArchive", func(t *testing.T) {
+		p, err := ParsePackage(strings.NewReader("invalid"))
+		assert.Nil(t, p)
+		require.ErrorIs(t, err, ErrInvalidArchive)
+	})
+
+	t.Run("MissingManifestFile", func(t *testing.T) {
+		p, err := ParsePackage(createArchive(map[string]string{"README.md": readme}))
+		assert.Nil(t, p)
+		require.ErrorIs(t, err, ErrMissingManifestFile)
+	})
+
+	t.Run("InvalidNamespace", func(t *testing.T) {
+		p, err := ParsePackage(createArchive(map[string]string{"MANIFEST.json": strings.Replace(manifestContent, `"forgejo"`, `"For-gejo"`, 1)}))
+		assert.Nil(t, p)
+		require.ErrorIs(t, err, ErrInvalidNamespace)
+	})
+
+	t.Run("InvalidName", func(t *testing.T) {
+		p, err := ParsePackage(createArchive(map[string]string{"MANIFEST.json": strings.Replace(manifestContent, `"tools"`, `"1tools"`, 1)}))
+		assert.Nil(t, p)
+		require.ErrorIs(t, err, ErrInvalidName)
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		p, err := ParsePackage(createArchive(map[string]string{"MANIFEST.json": strings.Replace(manifestContent, collectionVersion, "1.x", 1)}))
+		assert.Nil(t, p)
+		require.ErrorIs(t, err, ErrInvalidVersion)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		p, err := ParsePackage(createArchive(map[string]string{
+			"MANIFEST.json":    manifestContent,
+			"./README.md":      readme,
+			"docs/README.md":   "other",
+			"plugins/dummy.py": "",
+		}))
+		require.NoError(t, err)
+		assert.NotNil(t, p)
+
+		assert.Equal(t, namespace, p.Namespace)
+		assert.Equal(t, collectionName, p.Name)
+		assert.Equal(t, namespace+"."+collectionName, p.FullName())
+		assert.Equal(t, collectionVersion, p.Version)
+		assert.Equal(t, description, p.Metadata.Description)
+		assert.Equal(t, []string{"Forgejo"}, p.Metadata.Authors)
+		assert.Equal(t, []string{"MIT"}, p.Metadata.License)
+		assert.Equal(t, []string{"tools"}, p.Metadata.Tags)
+		assert.Equal(t, map[string]string{"community.general": ">=1.0.0"}, p.Metadata.Dependencies)
+		assert.Equal(t, repositoryURL, p.Metadata.RepositoryURL)
+		assert.Empty(t, p.Metadata.DocumentationURL)
+		assert.Empty(t, p.Metadata.HomepageURL)
+		assert.Equal(t, readme, p.Metadata.Readme)
+	})
+}
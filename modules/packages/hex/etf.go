@@ -0,0 +1,296 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package hex
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	"forgejo.org/modules/util"
+)
+
+// ContentTypeErlang is the media type of request and response bodies in the Erlang External Term Format.
+// The Hex client uses it by default and expects responses in the same format.
+const ContentTypeErlang = "application/vnd.hex+erlang"
+
+var ErrInvalidExternalTerm = util.NewInvalidArgumentErrorf("invalid erlang external term")
+
+// https://www.erlang.org/doc/apps/erts/erl_ext_dist.html
+const (
+	etfVersion       = 131
+	etfSmallInteger  = 97
+	etfInteger       = 98
+	etfAtom          = 100
+	etfSmallTuple    = 104
+	etfLargeTuple    = 105
+	etfNil           = 106
+	etfString        = 107
+	etfList          = 108
+	etfBinary        = 109
+	etfSmallAtom     = 115
+	etfMap           = 116
+	etfAtomUTF8      = 118
+	etfSmallAtomUTF8 = 119
+)
+
+// EncodeExternalTerm encodes the value in the Erlang External Term Format.
+// Supported are maps with string keys, slices, strings (as binaries), integers, booleans, nil and atoms.
+func EncodeExternalTerm(v any) ([]byte, error) {
+	return appendExternalTerm([]byte{etfVersion}, v)
+}
+
+func appendExternalTerm(b []byte, v any) ([]byte, error) {
+	var err error
+
+	switch t := v.(type) {
+	case nil:
+		return appendAtom(b, "nil"), nil
+	case bool:
+		if t {
+			return appendAtom(b, "true"), nil
+		}
+		return appendAtom(b, "false"), nil
+	case Atom:
+		return appendAtom(b, string(t)), nil
+	case string:
+		b = append(b, etfBinary)
+		b = binary.BigEndian.AppendUint32(b, uint32(len(t)))
+		return append(b, t...), nil
+	case int:
+		return appendInteger(b, int64(t))
+	case int64:
+		return appendInteger(b, t)
+	case []string:
+		l := make([]any, 0, len(t))
+		for _, s := range t {
+			l = append(l, s)
+		}
+		return appendExternalTerm(b, l)
+	case []any:
+		if len(t) > 0 {
+			b = append(b, etfList)
+			b = binary.BigEndian.AppendUint32(b, uint32(len(t)))
+			for _, e := range t {
+				if b, err = appendExternalTerm(b, e); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return append(b, etfNil), nil
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		b = append(b, etfMap)
+		b = binary.BigEndian.AppendUint32(b, uint32(len(t)))
+		for _, k := range keys {
+			if b, err = appendExternalTerm(b, k); err != nil {
+				return nil, err
+			}
+			if b, err = appendExternalTerm(b, t[k]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("unsupported type %T", v)
+}
+
+func appendAtom(b []byte, s string) []byte {
+	b = append(b, etfSmallAtomUTF8, byte(len(s)))
+	return append(b, s...)
+}
+
+func appendInteger(b []byte, i int64) ([]byte, error) {
+	if 0 <= i && i <= math.MaxUint8 {
+		return append(b, etfSmallInteger, byte(i)), nil
+	}
+	if math.MinInt32 <= i && i <= math.MaxInt32 {
+		b = append(b, etfInteger)
+		return binary.BigEndian.AppendUint32(b, uint32(int32(i))), nil
+	}
+	return nil, fmt.Errorf("integer %d out of range", i)
+}
+
+// DecodeExternalTerm decodes a value in the Erlang External Term Format.
+// Maps are returned as map[string]any, binaries as strings and the atoms true, false and nil as Go values.
+func DecodeExternalTerm(data []byte) (any, error) {
+	if len(data) == 0 || data[0] != etfVersion {
+		return nil, ErrInvalidExternalTerm
+	}
+	d := &termDecoder{data: data, pos: 1}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, ErrInvalidExternalTerm
+	}
+	return v, nil
+}
+
+const maxExternalTermDepth = 32
+
+type termDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *termDecoder) read(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, ErrInvalidExternalTerm
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *termDecoder) readLength(size int) (int, error) {
+	b, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	if size == 1 {
+		n = int(b[0])
+	} else if size == 2 {
+		n = int(binary.BigEndian.Uint16(b))
+	} else {
+		n = int(binary.BigEndian.Uint32(b))
+	}
+	// every element needs at least one byte, so larger values can't be valid
+	if n > len(d.data)-d.pos {
+		return 0, ErrInvalidExternalTerm
+	}
+	return n, nil
+}
+
+func (d *termDecoder) decode(depth int) (any, error) {
+	if depth > maxExternalTermDepth {
+		return nil, ErrInvalidExternalTerm
+	}
+
+	tag, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag[0] {
+	case etfSmallInteger:
+		b, err := d.read(1)
+		if err != nil {
+			return nil, err
+		}
+		return int64(b[0]), nil
+	case etfInteger:
+		b, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return int64(int32(binary.BigEndian.Uint32(b))), nil
+	case etfAtom, etfAtomUTF8, etfSmallAtom, etfSmallAtomUTF8:
+		size := 2
+		if tag[0] == etfSmallAtom || tag[0] == etfSmallAtomUTF8 {
+			size = 1
+		}
+		n, err := d.readLength(size)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		switch a := string(b); a {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "nil", "undefined":
+			return nil, nil
+		default:
+			return Atom(a), nil
+		}
+	case etfBinary, etfString:
+		size := 4
+		if tag[0] == etfString {
+			size = 2
+		}
+		n, err := d.readLength(size)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case etfNil:
+		return List{}, nil
+	case etfList:
+		n, err := d.readLength(4)
+		if err != nil {
+			return nil, err
+		}
+		l := make(List, 0, n)
+		for range n {
+			e, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, e)
+		}
+		// only proper lists are supported
+		if tail, err := d.read(1); err != nil || tail[0] != etfNil {
+			return nil, ErrInvalidExternalTerm
+		}
+		return l, nil
+	case etfSmallTuple, etfLargeTuple:
+		size := 1
+		if tag[0] == etfLargeTuple {
+			size = 4
+		}
+		n, err := d.readLength(size)
+		if err != nil {
+			return nil, err
+		}
+		t := make(Tuple, 0, n)
+		for range n {
+			e, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			t = append(t, e)
+		}
+		return t, nil
+	case etfMap:
+		n, err := d.readLength(4)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]any, n)
+		for range n {
+			k, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			key := toString(k)
+			if key == "" {
+				return nil, ErrInvalidExternalTerm
+			}
+			m[key] = v
+		}
+		return m, nil
+	}
+	return nil, ErrInvalidExternalTerm
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package hex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalTerm(t *testing.T) {
+	t.Run("Encode", func(t *testing.T) {
+		// term_to_binary(#{<<"a">> => [1, 1000], <<"b">> => true})
+		b, err := EncodeExternalTerm(map[string]any{"a": []any{1, 1000}, "b": true})
+		require.NoError(t, err)
+		assert.Equal(t, []byte{
+			131, 116, 0, 0, 0, 2,
+			109, 0, 0, 0, 1, 'a', 108, 0, 0, 0, 2, 97, 1, 98, 0, 0, 3, 232, 106,
+			109, 0, 0, 0, 1, 'b', 119, 4, 't', 'r', 'u', 'e',
+		}, b)
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		b, err := EncodeExternalTerm(map[string]any{
+			"reason":  "security",
+			"message": "",
+			"list":    []string{"x"},
+			"empty":   []any{},
+			"number":  -5,
+			"flag":    false,
+			"nothing": nil,
+			"atom":    Atom("ok"),
+		})
+		require.NoError(t, err)
+
+		v, err := DecodeExternalTerm(b)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"reason":  "security",
+			"message": "",
+			"list":    List{"x"},
+			"empty":   List{},
+			"number":  int64(-5),
+			"flag":    false,
+			"nothing": nil,
+			"atom":    Atom("ok"),
+		}, v)
+	})
+
+	t.Run("DecodeTuple", func(t *testing.T) {
+		// term_to_binary({ok, "ab"})
+		v, err := DecodeExternalTerm([]byte{131, 104, 2, 100, 0, 2, 'o', 'k', 107, 0, 2, 'a', 'b'})
+		require.NoError(t, err)
+		assert.Equal(t, Tuple{Atom("ok"), "ab"}, v)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, data := range [][]byte{
+			{},
+			{130, 106},
+			{131, 109, 0, 0, 0, 5, 'a'},
+			{131, 108, 0, 0, 0, 1, 97, 1, 97, 2},
+			{131, 106, 106},
+			{131, 116, 0, 0, 0, 1, 97, 1, 97, 1},
+		} {
+			_, err := DecodeExternalTerm(data)
+			require.ErrorIs(t, err, ErrInvalidExternalTerm, data)
+		}
+	})
+}
@@ -0,0 +1,314 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package hex
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"regexp"
+	"strings"
+
+	"forgejo.org/modules/util"
+	"forgejo.org/modules/validation"
+
+	"github.com/hashicorp/go-version"
+)
+
+var (
+	ErrInvalidTarball       = util.NewInvalidArgumentErrorf("package is not a valid tarball")
+	ErrMissingMetadataFile  = util.NewInvalidArgumentErrorf("metadata.config file is missing")
+	ErrMissingContentsFile  = util.NewInvalidArgumentErrorf("contents.tar.gz file is missing")
+	ErrMetadataFileTooLarge = util.NewInvalidArgumentErrorf("metadata.config file is too large")
+	ErrUnsupportedVersion   = util.NewInvalidArgumentErrorf("tarball version is not supported")
+	ErrInvalidChecksum      = util.NewInvalidArgumentErrorf("tarball checksum is invalid")
+	ErrInvalidFileOrder     = util.NewInvalidArgumentErrorf("contents.tar.gz must be the last file of the tarball")
+	ErrInvalidName          = util.NewInvalidArgumentErrorf("package name is invalid")
+	ErrInvalidVersion       = util.NewInvalidArgumentErrorf("package version is invalid")
+)
+
+const (
+	PropertyInnerChecksum = "hex.checksum.inner"
+	PropertyRetired       = "hex.retired"
+
+	SettingKeyPrivate = "hex.key.private"
+	SettingKeyPublic  = "hex.key.public"
+
+	// DefaultRepository is the name of the public Hex repository
+	DefaultRepository = "hexpm"
+)
+
+var namePattern = regexp.MustCompile(`\A[a-z][a-z0-9_]*\z`)
+
+// https://github.com/hexpm/hex_core/blob/main/src/hex_tarball.erl
+const maxMetadataFileSize = 128 * 1024
+
+// Package represents a Hex package
+type Package struct {
+	Name     string
+	Version  string
+	Metadata *Metadata
+	// InnerChecksum is the checksum over the VERSION, metadata.config and contents.tar.gz files
+	InnerChecksum []byte
+}
+
+// Metadata represents the metadata of a Hex package
+type Metadata struct {
+	App               string            `json:"app,omitempty"`
+	Description       string            `json:"description,omitempty"`
+	Licenses          []string          `json:"licenses,omitempty"`
+	Links             map[string]string `json:"links,omitempty"`
+	BuildTools        []string          `json:"build_tools,omitempty"`
+	ElixirRequirement string            `json:"elixir,omitempty"`
+	Requirements      []*Requirement    `json:"requirements,omitempty"`
+}
+
+// Requirement represents a dependency of a Hex package
+type Requirement struct {
+	Name        string `json:"name"`
+	Requirement string `json:"requirement"`
+	Optional    bool   `json:"optional,omitempty"`
+	App         string `json:"app,omitempty"`
+	Repository  string `json:"repository,omitempty"`
+}
+
+// ParsePackage parses the Hex package tarball
+// https://github.com/hexpm/specifications/blob/main/package_tarball.md
+func ParsePackage(r io.Reader) (*Package, error) {
+	var versionFile, metadataFile, checksumFile []byte
+	var innerChecksum []byte
+
+	tr := tar.NewReader(r)
+	for {
+		hd, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidTarball
+		}
+
+		if hd.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		switch hd.Name {
+		case "VERSION":
+			versionFile, err = io.ReadAll(io.LimitReader(tr, 16))
+		case "CHECKSUM":
+			checksumFile, err = io.ReadAll(io.LimitReader(tr, 128))
+		case "metadata.config":
+			if hd.Size > maxMetadataFileSize {
+				return nil, ErrMetadataFileTooLarge
+			}
+			metadataFile, err = io.ReadAll(io.LimitReader(tr, maxMetadataFileSize))
+		case "contents.tar.gz":
+			// The inner checksum is sha256(VERSION ++ metadata.config ++ contents.tar.gz).
+			// Clients write the contents last so the file can be hashed without buffering it.
+			if versionFile == nil || metadataFile == nil {
+				return nil, ErrInvalidFileOrder
+			}
+			h := sha256.New()
+			h.Write(versionFile)
+			h.Write(metadataFile)
+			if _, err = io.Copy(h, tr); err == nil {
+				innerChecksum = h.Sum(nil)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if strings.TrimSpace(string(versionFile)) != "3" {
+		return nil, ErrUnsupportedVersion
+	}
+	if metadataFile == nil {
+		return nil, ErrMissingMetadataFile
+	}
+	if innerChecksum == nil {
+		return nil, ErrMissingContentsFile
+	}
+
+	if checksumFile != nil {
+		checksum, err := hex.DecodeString(strings.TrimSpace(string(checksumFile)))
+		if err != nil || !bytes.Equal(checksum, innerChecksum) {
+			return nil, ErrInvalidChecksum
+		}
+	}
+
+	p, err := ParseMetadata(metadataFile)
+	if err != nil {
+		return nil, err
+	}
+
+	p.InnerChecksum = innerChecksum
+
+	return p, nil
+}
+
+// ParseMetadata parses the metadata.config file of a Hex package
+func ParseMetadata(data []byte) (*Package, error) {
+	terms, err := ParseTerms(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Package{
+		Metadata: &Metadata{},
+	}
+
+	for _, t := range terms {
+		kv, ok := t.(Tuple)
+		if !ok || len(kv) != 2 {
+			continue
+		}
+		key, ok := kv[0].(string)
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "name":
+			p.Name = toString(kv[1])
+		case "version":
+			p.Version = toString(kv[1])
+		case "app":
+			p.Metadata.App = toString(kv[1])
+		case "description":
+			p.Metadata.Description = toString(kv[1])
+		case "elixir":
+			p.Metadata.ElixirRequirement = toString(kv[1])
+		case "licenses":
+			p.Metadata.Licenses = toStringList(kv[1])
+		case "build_tools":
+			p.Metadata.BuildTools = toStringList(kv[1])
+		case "links":
+			links := make(map[string]string)
+			for name, value := range toPropList(kv[1]) {
+				if s := toString(value); validation.IsValidURL(s) {
+					links[name] = s
+				}
+			}
+			if len(links) > 0 {
+				p.Metadata.Links = links
+			}
+		case "requirements":
+			p.Metadata.Requirements = parseRequirements(kv[1])
+		}
+	}
+
+	if !namePattern.MatchString(p.Name) {
+		return nil, ErrInvalidName
+	}
+
+	v, err := version.NewSemver(p.Version)
+	if err != nil {
+		return nil, ErrInvalidVersion
+	}
+	p.Version = v.String()
+
+	if p.Metadata.App == "" {
+		p.Metadata.App = p.Name
+	}
+
+	return p, nil
+}
+
+// parseRequirements supports the list of {Name, Properties} tuples written by current clients
+// and the list of property lists with a "name" key written by older clients.
+func parseRequirements(v any) []*Requirement {
+	l, ok := v.(List)
+	if !ok {
+		return nil
+	}
+
+	reqs := make([]*Requirement, 0, len(l))
+	for _, e := range l {
+		var name string
+		var props map[string]any
+
+		if t, ok := e.(Tuple); ok && len(t) == 2 {
+			if _, isString := t[0].(string); isString {
+				name = toString(t[0])
+				props = toPropList(t[1])
+			}
+		}
+		if props == nil {
+			props = toPropList(e)
+			name = toString(props["name"])
+		}
+		if name == "" {
+			continue
+		}
+
+		app := toString(props["app"])
+		if app == "" {
+			app = name
+		}
+		repository := toString(props["repository"])
+		if repository == "" {
+			repository = DefaultRepository
+		}
+
+		reqs = append(reqs, &Requirement{
+			Name:        name,
+			Requirement: toString(props["requirement"]),
+			Optional:    toBool(props["optional"]),
+			App:         app,
+			Repository:  repository,
+		})
+	}
+	return reqs
+}
+
+func toString(v any) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case Atom:
+		if s == "nil" || s == "undefined" {
+			return ""
+		}
+		return string(s)
+	}
+	return ""
+}
+
+func toBool(v any) bool {
+	a, ok := v.(Atom)
+	return ok && a == "true"
+}
+
+func toStringList(v any) []string {
+	l, ok := v.(List)
+	if !ok {
+		return nil
+	}
+	r := make([]string, 0, len(l))
+	for _, e := range l {
+		if s := toString(e); s != "" {
+			r = append(r, s)
+		}
+	}
+	return r
+}
+
+func toPropList(v any) map[string]any {
+	l, ok := v.(List)
+	if !ok {
+		return nil
+	}
+	m := make(map[string]any, len(l))
+	for _, e := range l {
+		if t, ok := e.(Tuple); ok && len(t) == 2 {
+			if k := toString(t[0]); k != "" {
+				m[k] = t[1]
+			}
+		}
+	}
+	return m
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package hex
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	packageName    = "forgejo"
+	packageVersion = "1.0.1"
+	description    = "Package \"Description\""
+	projectURL     = "https://forgejo.org"
+)
+
+const metadataContent = `{<<"app">>,<<"forgejo_app">>}.
+{<<"build_tools">>,[<<"mix">>]}.
+{<<"description">>,<<"Package \"Description\""/utf8>>}.
+{<<"elixir">>,<<"~> 1.15">>}.
+{<<"files">>,[<<"lib">>,<<"lib/forgejo.ex">>,<<"mix.exs">>]}.
+{<<"licenses">>,[<<"MIT">>]}.
+{<<"links">>,[{<<"Homepage">>,<<"https://forgejo.org">>},{<<"Invalid">>,<<"no-url">>}]}.
+{<<"name">>,<<"forgejo">>}.
+{<<"requirements">>,
+ [{<<"decimal">>,
+   [{<<"app">>,<<"decimal">>},
+    {<<"optional">>,false},
+    {<<"repository">>,<<"hexpm">>},
+    {<<"requirement">>,<<"~> 2.0">>}]},
+  {<<"jason">>,
+   [{<<"app">>,<<"jason">>},
+    {<<"optional">>,true},
+    {<<"repository">>,<<"forgejo">>},
+    {<<"requirement">>,<<">= 1.0.0">>}]}]}.
+{<<"version">>,<<"1.0.1">>}.
+`
+
+func TestParseTerms(t *testing.T) {
+	terms, err := ParseTerms(`% comment
+{atom, 'quoted atom', "string", <<"binary">>, <<>>, -12, [1, 2]}.
+{<<"escaped \x41\x{e9}\n">>, []}.`)
+	require.NoError(t, err)
+	require.Len(t, terms, 2)
+	assert.Equal(t, Tuple{Atom("atom"), Atom("quoted atom"), "string", "binary", "", int64(-12), List{int64(1), int64(2)}}, terms[0])
+	assert.Equal(t, Tuple{"escaped Aé\n", List{}}, terms[1])
+
+	for _, s := range []string{`{a, b}`, `{a, b.`, `[a b].`, `<<"unterminated>>.`, `{A}.`} {
+		_, err := ParseTerms(s)
+		require.ErrorIs(t, err, ErrInvalidTerm, s)
+	}
+}
+
+func TestParsePackage(t *testing.T) {
+	createArchive := func(files []string, contents map[string][]byte) io.Reader {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, filename := range files {
+			content := contents[filename]
+			hdr := &tar.Header{
+				Name: filename,
+				Mode: 0o600,
+				Size: int64(len(content)),
+			}
+			tw.WriteHeader(hdr)
+			tw.Write(content)
+		}
+		tw.Close()
+		return &buf
+	}
+
+	innerChecksum := func(contents map[string][]byte) []byte {
+		h := sha256.New()
+		h.Write(contents["VERSION"])
+		h.Write(contents["metadata.config"])
+		h.Write(contents["contents.tar.gz"])
+		return h.Sum(nil)
+	}
+
+	validContents := func() map[string][]byte {
+		contents := map[string][]byte{
+			"VERSION":         []byte("3"),
+			"metadata.config": []byte(metadataContent),
+			"contents.tar.gz": []byte("dummy"),
+		}
+		contents["CHECKSUM"] = []byte(strings.ToUpper(hex.EncodeToString(innerChecksum(contents))))
+		return contents
+	}
+
+	files := []string{"VERSION", "CHECKSUM", "metadata.config", "contents.tar.gz"}
+
+	t.Run("InvalidTarball", func(t *testing.T) {
+		p, err := ParsePackage(strings.NewReader("invalid"))
+		assert.Nil(t, p)
+		require.ErrorIs(t, err, ErrInvalidTarball)
+	})
+
+	t.Run("UnsupportedVersion", func(t *testing.T) {
+		contents := validContents()
+		contents["VERSION"] = []byte("2")
+
+		p, err := ParsePackage(createArchive(files, contents))
+		assert.Nil(t, p)
+		require.ErrorIs(t, err, ErrUnsupportedVersion)
+	})
+
+	t.Run("MissingMetadataFile", func(t *testing.T) {
+		p, err := ParsePackage(createArchive([]string{"VERSION"}, validContents()))
+		assert.Nil(t, p)
+		require.ErrorIs(t, err, ErrMissingMetadataFile)
+	})
+
+	t.Run("MissingContentsFile", func(t *testing.T) {
+		p, err := ParsePackage(createArchive([]string{"VERSION", "metadata.config"}, validContents()))
+		assert.Nil(t, p)
+		require.ErrorIs(t, err, ErrMissingContentsFile)
+	})
+
+	t.Run("InvalidFileOrder", func(t *testing.T) {
+		p, err := ParsePackage(createArchive([]string{"VERSION", "contents.tar.gz", "metadata.config"}, validContents()))
+		assert.Nil(t, p)
+		require.ErrorIs(t, err, ErrInvalidFileOrder)
+	})
+
+	t.Run("InvalidChecksum", func(t *testing.T) {
+		contents := validContents()
+		contents["CHECKSUM"] = []byte(strings.Repeat("A", 64))
+
+		p, err := ParsePackage(createArchive(files, contents))
+		assert.Nil(t, p)
+		require.ErrorIs(t, err, ErrInvalidChecksum)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		contents := validContents()
+
+		p, err := ParsePackage(createArchive(files, contents))
+		require.NoError(t, err)
+		assert.NotNil(t, p)
+		assert.Equal(t, innerChecksum(contents), p.InnerChecksum)
+		assert.Equal(t, packageName, p.Name)
+	})
+}
+
+func TestParseMetadata(t *testing.T) {
+	t.Run("InvalidName", func(t *testing.T) {
+		for _, name := range []string{"", "Forgejo", "1forgejo", "forgejo-package"} {
+			p, err := ParseMetadata([]byte(`{<<"name">>,<<"` + name + `">>}.{<<"version">>,<<"1.0.0">>}.`))
+			assert.Nil(t, p)
+			require.ErrorIs(t, err, ErrInvalidName)
+		}
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		p, err := ParseMetadata([]byte(`{<<"name">>,<<"forgejo">>}.{<<"version">>,<<"1.x">>}.`))
+		assert.Nil(t, p)
+		require.ErrorIs(t, err, ErrInvalidVersion)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		p, err := ParseMetadata([]byte(metadataContent))
+		require.NoError(t, err)
+		assert.NotNil(t, p)
+
+		assert.Equal(t, packageName, p.Name)
+		assert.Equal(t, packageVersion, p.Version)
+		assert.Equal(t, "forgejo_app", p.Metadata.App)
+		assert.Equal(t, description, p.Metadata.Description)
+		assert.Equal(t, "~> 1.15", p.Metadata.ElixirRequirement)
+		assert.Equal(t, []string{"MIT"}, p.Metadata.Licenses)
+		assert.Equal(t, []string{"mix"}, p.Metadata.BuildTools)
+		assert.Equal(t, map[string]string{"Homepage": projectURL}, p.Metadata.Links)
+		assert.Equal(t, []*Requirement{
+			{Name: "decimal", Requirement: "~> 2.0", App: "decimal", Repository: "hexpm"},
+			{Name: "jason", Requirement: ">= 1.0.0", Optional: true, App: "jason", Repository: "forgejo"},
+		}, p.Metadata.Requirements)
+	})
+
+	t.Run("LegacyRequirements", func(t *testing.T) {
+		p, err := ParseMetadata([]byte(`{<<"name">>,<<"forgejo">>}.{<<"version">>,<<"1.0.0">>}.
+{<<"requirements">>,[[{<<"name">>,<<"decimal">>},{<<"optional">>,false},{<<"requirement">>,<<"~> 2.0">>}]]}.`))
+		require.NoError(t, err)
+		assert.Equal(t, "forgejo", p.Metadata.App)
+		assert.Equal(t, []*Requirement{
+			{Name: "decimal", Requirement: "~> 2.0", App: "decimal", Repository: "hexpm"},
+		}, p.Metadata.Requirements)
+	})
+}
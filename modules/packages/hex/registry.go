@@ -0,0 +1,187 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package hex
+
+import (
+	"bytes"
+	"compress/gzip"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The registry resources are protobuf messages which are signed and gzipped.
+// The messages are small and stable, so they are encoded by hand instead of generating code.
+// https://github.com/hexpm/specifications/blob/main/registry-v2.md
+
+// RetirementReason is the reason why a release was retired
+type RetirementReason int32
+
+const (
+	RetiredOther      RetirementReason = 0
+	RetiredInvalid    RetirementReason = 1
+	RetiredSecurity   RetirementReason = 2
+	RetiredDeprecated RetirementReason = 3
+	RetiredRenamed    RetirementReason = 4
+)
+
+// RetirementReasons maps the names used by the API to the reasons
+var RetirementReasons = map[string]RetirementReason{
+	"other":      RetiredOther,
+	"invalid":    RetiredInvalid,
+	"security":   RetiredSecurity,
+	"deprecated": RetiredDeprecated,
+	"renamed":    RetiredRenamed,
+}
+
+// RetirementStatus describes a retired release
+type RetirementStatus struct {
+	Reason  RetirementReason `json:"reason"`
+	Message string           `json:"message,omitempty"`
+}
+
+// NamesPackage is an entry of the names resource
+type NamesPackage struct {
+	Name           string
+	UpdatedSeconds int64
+}
+
+// VersionsPackage is an entry of the versions resource
+type VersionsPackage struct {
+	Name     string
+	Versions []string
+	// Retired contains the indexes of the retired versions
+	Retired []int32
+}
+
+// Release is a version entry of the package resource
+type Release struct {
+	Version       string
+	InnerChecksum []byte
+	OuterChecksum []byte
+	Dependencies  []*Requirement
+	Retired       *RetirementStatus
+}
+
+// EncodeNames encodes the Names message
+func EncodeNames(repository string, packages []*NamesPackage) []byte {
+	var b []byte
+	for _, p := range packages {
+		var pb []byte
+		pb = protowire.AppendTag(pb, 1, protowire.BytesType)
+		pb = protowire.AppendString(pb, p.Name)
+
+		var ts []byte
+		ts = protowire.AppendTag(ts, 1, protowire.VarintType)
+		ts = protowire.AppendVarint(ts, uint64(p.UpdatedSeconds))
+		pb = protowire.AppendTag(pb, 2, protowire.BytesType)
+		pb = protowire.AppendBytes(pb, ts)
+
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, pb)
+	}
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	return protowire.AppendString(b, repository)
+}
+
+// EncodeVersions encodes the Versions message
+func EncodeVersions(repository string, packages []*VersionsPackage) []byte {
+	var b []byte
+	for _, p := range packages {
+		var pb []byte
+		pb = protowire.AppendTag(pb, 1, protowire.BytesType)
+		pb = protowire.AppendString(pb, p.Name)
+		for _, v := range p.Versions {
+			pb = protowire.AppendTag(pb, 2, protowire.BytesType)
+			pb = protowire.AppendString(pb, v)
+		}
+		if len(p.Retired) > 0 {
+			var packed []byte
+			for _, i := range p.Retired {
+				packed = protowire.AppendVarint(packed, uint64(i))
+			}
+			pb = protowire.AppendTag(pb, 3, protowire.BytesType)
+			pb = protowire.AppendBytes(pb, packed)
+		}
+
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, pb)
+	}
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	return protowire.AppendString(b, repository)
+}
+
+// EncodePackage encodes the Package message
+func EncodePackage(repository, name string, releases []*Release) []byte {
+	var b []byte
+	for _, r := range releases {
+		var rb []byte
+		rb = protowire.AppendTag(rb, 1, protowire.BytesType)
+		rb = protowire.AppendString(rb, r.Version)
+		rb = protowire.AppendTag(rb, 2, protowire.BytesType)
+		rb = protowire.AppendBytes(rb, r.InnerChecksum)
+		for _, d := range r.Dependencies {
+			var db []byte
+			db = protowire.AppendTag(db, 1, protowire.BytesType)
+			db = protowire.AppendString(db, d.Name)
+			db = protowire.AppendTag(db, 2, protowire.BytesType)
+			db = protowire.AppendString(db, d.Requirement)
+			if d.Optional {
+				db = protowire.AppendTag(db, 3, protowire.VarintType)
+				db = protowire.AppendVarint(db, 1)
+			}
+			if d.App != "" && d.App != d.Name {
+				db = protowire.AppendTag(db, 4, protowire.BytesType)
+				db = protowire.AppendString(db, d.App)
+			}
+			if d.Repository != "" && d.Repository != repository {
+				db = protowire.AppendTag(db, 5, protowire.BytesType)
+				db = protowire.AppendString(db, d.Repository)
+			}
+
+			rb = protowire.AppendTag(rb, 3, protowire.BytesType)
+			rb = protowire.AppendBytes(rb, db)
+		}
+		if r.Retired != nil {
+			var sb []byte
+			sb = protowire.AppendTag(sb, 1, protowire.VarintType)
+			sb = protowire.AppendVarint(sb, uint64(r.Retired.Reason))
+			if r.Retired.Message != "" {
+				sb = protowire.AppendTag(sb, 2, protowire.BytesType)
+				sb = protowire.AppendString(sb, r.Retired.Message)
+			}
+			rb = protowire.AppendTag(rb, 4, protowire.BytesType)
+			rb = protowire.AppendBytes(rb, sb)
+		}
+		if len(r.OuterChecksum) > 0 {
+			rb = protowire.AppendTag(rb, 5, protowire.BytesType)
+			rb = protowire.AppendBytes(rb, r.OuterChecksum)
+		}
+
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, rb)
+	}
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendString(b, name)
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	return protowire.AppendString(b, repository)
+}
+
+// EncodeSigned wraps the payload in a gzipped Signed message
+func EncodeSigned(payload, signature []byte) ([]byte, error) {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, payload)
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendBytes(b, signature)
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,274 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package hex
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"forgejo.org/modules/util"
+)
+
+var ErrInvalidTerm = util.NewInvalidArgumentErrorf("invalid erlang term")
+
+// Atom is an Erlang atom
+type Atom string
+
+// Tuple is an Erlang tuple
+type Tuple []any
+
+// List is an Erlang list
+type List []any
+
+// ParseTerms parses a file in the format read by file:consult/1.
+// Only the subset of the term syntax used by the Hex metadata file is supported:
+// tuples, lists, binaries, strings, atoms and integers.
+// Binaries and strings are returned as Go strings.
+func ParseTerms(s string) ([]any, error) {
+	p := &termParser{s: s}
+
+	terms := make([]any, 0, 10)
+	for {
+		p.skipWhitespace()
+		if p.eof() {
+			return terms, nil
+		}
+
+		t, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipWhitespace()
+		if !p.consume('.') {
+			return nil, ErrInvalidTerm
+		}
+
+		terms = append(terms, t)
+	}
+}
+
+type termParser struct {
+	s   string
+	pos int
+}
+
+func (p *termParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *termParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *termParser) consume(c byte) bool {
+	if p.peek() == c && !p.eof() {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *termParser) skipWhitespace() {
+	for !p.eof() {
+		switch c := p.s[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			p.pos++
+		case c == '%':
+			for !p.eof() && p.s[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *termParser) parseTerm() (any, error) {
+	p.skipWhitespace()
+
+	switch c := p.peek(); {
+	case c == '{':
+		p.pos++
+		elems, err := p.parseSequence('}')
+		if err != nil {
+			return nil, err
+		}
+		return Tuple(elems), nil
+	case c == '[':
+		p.pos++
+		elems, err := p.parseSequence(']')
+		if err != nil {
+			return nil, err
+		}
+		return List(elems), nil
+	case c == '<':
+		return p.parseBinary()
+	case c == '"':
+		return p.parseString('"')
+	case c == '\'':
+		s, err := p.parseString('\'')
+		if err != nil {
+			return nil, err
+		}
+		return Atom(s), nil
+	case c == '-' || ('0' <= c && c <= '9'):
+		return p.parseInteger()
+	case 'a' <= c && c <= 'z':
+		start := p.pos
+		for !p.eof() {
+			c := p.s[p.pos]
+			if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '@') {
+				break
+			}
+			p.pos++
+		}
+		return Atom(p.s[start:p.pos]), nil
+	}
+	return nil, ErrInvalidTerm
+}
+
+func (p *termParser) parseSequence(end byte) ([]any, error) {
+	elems := make([]any, 0, 5)
+
+	p.skipWhitespace()
+	if p.consume(end) {
+		return elems, nil
+	}
+
+	for {
+		t, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, t)
+
+		p.skipWhitespace()
+		if p.consume(end) {
+			return elems, nil
+		}
+		if !p.consume(',') {
+			return nil, ErrInvalidTerm
+		}
+	}
+}
+
+// parseBinary parses <<"...">> and <<"..."/utf8>> binaries
+func (p *termParser) parseBinary() (any, error) {
+	if !strings.HasPrefix(p.s[p.pos:], "<<") {
+		return nil, ErrInvalidTerm
+	}
+	p.pos += 2
+
+	p.skipWhitespace()
+	if strings.HasPrefix(p.s[p.pos:], ">>") {
+		p.pos += 2
+		return "", nil
+	}
+
+	s, err := p.parseString('"')
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(p.s[p.pos:], "/utf8") {
+		p.pos += len("/utf8")
+	}
+
+	p.skipWhitespace()
+	if !strings.HasPrefix(p.s[p.pos:], ">>") {
+		return nil, ErrInvalidTerm
+	}
+	p.pos += 2
+
+	return s, nil
+}
+
+func (p *termParser) parseString(quote byte) (string, error) {
+	if !p.consume(quote) {
+		return "", ErrInvalidTerm
+	}
+
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", ErrInvalidTerm
+		}
+
+		c := p.s[p.pos]
+		p.pos++
+
+		if c == quote {
+			return sb.String(), nil
+		}
+		if c != '\\' {
+			sb.WriteByte(c)
+			continue
+		}
+
+		if p.eof() {
+			return "", ErrInvalidTerm
+		}
+		c = p.s[p.pos]
+		p.pos++
+
+		switch c {
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		case 's':
+			sb.WriteByte(' ')
+		case 'e':
+			sb.WriteByte(0x1b)
+		case 'x':
+			// \xHH or \x{H...}
+			var hex string
+			if p.consume('{') {
+				end := strings.IndexByte(p.s[p.pos:], '}')
+				if end == -1 {
+					return "", ErrInvalidTerm
+				}
+				hex = p.s[p.pos : p.pos+end]
+				p.pos += end + 1
+			} else {
+				if p.pos+2 > len(p.s) {
+					return "", ErrInvalidTerm
+				}
+				hex = p.s[p.pos : p.pos+2]
+				p.pos += 2
+			}
+			r, err := strconv.ParseInt(hex, 16, 32)
+			if err != nil {
+				return "", ErrInvalidTerm
+			}
+			if r < utf8.RuneSelf {
+				sb.WriteByte(byte(r))
+			} else {
+				sb.WriteRune(rune(r))
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+}
+
+func (p *termParser) parseInteger() (any, error) {
+	start := p.pos
+	p.consume('-')
+	for !p.eof() && '0' <= p.s[p.pos] && p.s[p.pos] <= '9' {
+		p.pos++
+	}
+
+	i, err := strconv.ParseInt(p.s[start:p.pos], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidTerm
+	}
+	return i, nil
+}
@@ -20,9 +20,25 @@ type KeyAndOwner struct {
 	Owner *user_model.User         `json:"user"`
 }
 
-// ServNoCommand returns information about the provided key
-func ServNoCommand(ctx context.Context, keyID int64) (*asymkey_model.PublicKey, *user_model.User, error) {
-	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/serv/none/%d", keyID)
+// ServCertificate identifies a certificate which a trusted certificate authority mapped to a user
+type ServCertificate struct {
+	AuthorityID int64
+	UserID      int64
+	KeyID       string // the key ID and serial identify the certificate in the audit log
+	Serial      string
+}
+
+func (cert *ServCertificate) query() string {
+	if cert == nil {
+		return ""
+	}
+	return fmt.Sprintf("certificate_authority=%d&user=%d&certificate_key_id=%s&certificate_serial=%s",
+		cert.AuthorityID, cert.UserID, url.QueryEscape(cert.KeyID), url.QueryEscape(cert.Serial))
+}
+
+// ServNoCommand returns information about the provided key, or the certificate if the key id is 0
+func ServNoCommand(ctx context.Context, keyID int64, cert *ServCertificate, remoteAddr string) (*asymkey_model.PublicKey, *user_model.User, error) {
+	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/serv/none/%d?remote_addr=%s&%s", keyID, url.QueryEscape(remoteAddr), cert.query())
 	req := newInternalRequest(ctx, reqURL, "GET")
 	keyAndOwner, extra := requestJSONResp(req, &KeyAndOwner{})
 	if extra.HasError() {
@@ -45,14 +61,19 @@ type ServCommandResults struct {
 	RepoID      int64
 }
 
-// ServCommand preps for a serv call
-func ServCommand(ctx context.Context, keyID int64, ownerName, repoName string, mode perm.AccessMode, verbs ...string) (*ServCommandResults, ResponseExtra) {
-	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/serv/command/%d/%s/%s?mode=%d",
+// ServCommand preps for a serv call, the session is identified by the key or the certificate if the key id is 0.
+// The remote address of the SSH client is checked against the IP allowlist of the owner.
+func ServCommand(ctx context.Context, keyID int64, cert *ServCertificate, remoteAddr, ownerName, repoName string, mode perm.AccessMode, verbs ...string) (*ServCommandResults, ResponseExtra) {
+	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/serv/command/%d/%s/%s?mode=%d&remote_addr=%s",
 		keyID,
 		url.PathEscape(ownerName),
 		url.PathEscape(repoName),
 		mode,
+		url.QueryEscape(remoteAddr),
 	)
+	if cert != nil {
+		reqURL += "&" + cert.query()
+	}
 	for _, verb := range verbs {
 		if verb != "" {
 			reqURL += fmt.Sprintf("&verb=%s", url.QueryEscape(verb))
@@ -0,0 +1,427 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package saml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+const nsXML = "http://www.w3.org/XML/1998/namespace"
+
+type namespaceDecl struct {
+	prefix string
+	uri    string
+}
+
+// element is a node of a parsed XML document. Unlike encoding/xml it keeps the namespace prefixes
+// and declarations as written, which is needed to canonicalize the signed parts of a document.
+type element struct {
+	parent     *element
+	prefix     string
+	local      string
+	namespaces []namespaceDecl
+	attrs      []xml.Attr // Name.Space is the prefix
+	children   []any      // *element, xml.CharData, xml.Comment or xml.ProcInst
+}
+
+// normalizeAttributeValues replaces the literal white space of attribute values by spaces,
+// see https://www.w3.org/TR/xml/#AVNormalize. encoding/xml only normalizes the line endings.
+// Character references are left as they are, so that they are kept by the decoder.
+func normalizeAttributeValues(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	var quote byte
+	inTag := false
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		switch {
+		case quote != 0:
+			switch b {
+			case quote:
+				quote = 0
+			case '\r', '\n', '\t':
+				if b == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+					i++
+				}
+				b = ' '
+			}
+		case inTag:
+			switch b {
+			case '"', '\'':
+				quote = b
+			case '>':
+				inTag = false
+			}
+		case b == '<':
+			// comments, CDATA sections, processing instructions and declarations have no attributes
+			end := ""
+			for _, markup := range [][2]string{{"<!--", "-->"}, {"<![CDATA[", "]]>"}, {"<?", "?>"}, {"<!", ">"}} {
+				if bytes.HasPrefix(data[i:], []byte(markup[0])) {
+					end = markup[1]
+					break
+				}
+			}
+			if end == "" {
+				inTag = true
+				break
+			}
+			n := bytes.Index(data[i:], []byte(end))
+			if n < 0 {
+				return append(out, data[i:]...)
+			}
+			out = append(out, data[i:i+n+len(end)]...)
+			i += n + len(end) - 1
+			continue
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// parseDocument parses data into a tree of elements, document type declarations are rejected
+func parseDocument(data []byte) (*element, error) {
+	d := xml.NewDecoder(bytes.NewReader(normalizeAttributeValues(data)))
+	d.Strict = true
+
+	var root, current *element
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			el := &element{parent: current, prefix: t.Name.Space, local: t.Name.Local}
+			for _, attr := range t.Attr {
+				switch {
+				case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+					el.namespaces = append(el.namespaces, namespaceDecl{uri: attr.Value})
+				case attr.Name.Space == "xmlns":
+					el.namespaces = append(el.namespaces, namespaceDecl{prefix: attr.Name.Local, uri: attr.Value})
+				default:
+					el.attrs = append(el.attrs, attr)
+				}
+			}
+			if current != nil {
+				current.children = append(current.children, el)
+			} else if root != nil {
+				return nil, errors.New("document has more than one root element")
+			} else {
+				root = el
+			}
+			current = el
+		case xml.EndElement:
+			if current == nil || current.prefix != t.Name.Space || current.local != t.Name.Local {
+				return nil, errors.New("unexpected end element")
+			}
+			current = current.parent
+		case xml.CharData:
+			if current != nil {
+				current.children = append(current.children, t.Copy())
+			}
+		case xml.Comment:
+			if current != nil {
+				current.children = append(current.children, t.Copy())
+			}
+		case xml.ProcInst:
+			if current != nil {
+				current.children = append(current.children, t.Copy())
+			}
+		case xml.Directive:
+			return nil, errors.New("document type declarations are not allowed")
+		}
+	}
+	if root == nil || current != nil {
+		return nil, errors.New("document is incomplete")
+	}
+	if err := root.checkNamespaces(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// checkNamespaces makes sure all prefixes of the subtree are declared
+func (e *element) checkNamespaces() error {
+	if _, ok := e.lookupNamespace(e.prefix); !ok {
+		return errors.New("undeclared namespace prefix " + e.prefix)
+	}
+	for _, attr := range e.attrs {
+		if _, ok := e.lookupNamespace(attr.Name.Space); !ok {
+			return errors.New("undeclared namespace prefix " + attr.Name.Space)
+		}
+	}
+	for _, child := range e.children {
+		if el, ok := child.(*element); ok {
+			if err := el.checkNamespaces(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// lookupNamespace returns the namespace bound to prefix in the scope of the element
+func (e *element) lookupNamespace(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return nsXML, true
+	}
+	for el := e; el != nil; el = el.parent {
+		for _, ns := range el.namespaces {
+			if ns.prefix == prefix {
+				return ns.uri, true
+			}
+		}
+	}
+	return "", prefix == ""
+}
+
+func (e *element) namespace() string {
+	uri, _ := e.lookupNamespace(e.prefix)
+	return uri
+}
+
+func (e *element) is(namespace, local string) bool {
+	return e.local == local && e.namespace() == namespace
+}
+
+// attr returns the value of the attribute without namespace named local
+func (e *element) attr(local string) string {
+	if e == nil {
+		return ""
+	}
+	for _, attr := range e.attrs {
+		if attr.Name.Space == "" && attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// elements returns the child elements with the name
+func (e *element) elements(namespace, local string) []*element {
+	if e == nil {
+		return nil
+	}
+	var result []*element
+	for _, child := range e.children {
+		if el, ok := child.(*element); ok && el.is(namespace, local) {
+			result = append(result, el)
+		}
+	}
+	return result
+}
+
+// element returns the first child element with the name, nil if there is none
+func (e *element) element(namespace, local string) *element {
+	if e == nil {
+		return nil
+	}
+	for _, child := range e.children {
+		if el, ok := child.(*element); ok && el.is(namespace, local) {
+			return el
+		}
+	}
+	return nil
+}
+
+// text returns the character data of the element
+func (e *element) text() string {
+	if e == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, child := range e.children {
+		if data, ok := child.(xml.CharData); ok {
+			sb.Write(data)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// canonicalizer produces the exclusive canonical form of a subtree,
+// see https://www.w3.org/TR/xml-exc-c14n/
+type canonicalizer struct {
+	inclusivePrefixes map[string]bool
+	withComments      bool
+	exclude           *element
+}
+
+func newCanonicalizer(prefixList string, withComments bool, exclude *element) *canonicalizer {
+	c := &canonicalizer{inclusivePrefixes: map[string]bool{}, withComments: withComments, exclude: exclude}
+	for _, prefix := range strings.Fields(prefixList) {
+		if prefix == "#default" {
+			prefix = ""
+		}
+		c.inclusivePrefixes[prefix] = true
+	}
+	return c
+}
+
+func (c *canonicalizer) canonicalize(e *element) []byte {
+	var buf bytes.Buffer
+	c.writeElement(&buf, e, map[string]string{})
+	return buf.Bytes()
+}
+
+func qualifiedName(prefix, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+// writeElement writes an element, rendered holds the namespace declarations in the output scope
+func (c *canonicalizer) writeElement(buf *bytes.Buffer, e *element, rendered map[string]string) {
+	used := map[string]bool{e.prefix: true}
+	for _, attr := range e.attrs {
+		if attr.Name.Space != "" {
+			used[attr.Name.Space] = true
+		}
+	}
+	for prefix := range c.inclusivePrefixes {
+		if _, ok := e.lookupNamespace(prefix); ok {
+			used[prefix] = true
+		}
+	}
+
+	var decls []namespaceDecl
+	for prefix := range used {
+		if prefix == "xml" {
+			continue
+		}
+		uri, _ := e.lookupNamespace(prefix)
+		if previous, ok := rendered[prefix]; ok && previous == uri {
+			continue
+		} else if !ok && prefix == "" && uri == "" {
+			continue
+		}
+		decls = append(decls, namespaceDecl{prefix: prefix, uri: uri})
+	}
+	sort.Slice(decls, func(i, j int) bool { return decls[i].prefix < decls[j].prefix })
+
+	type attribute struct {
+		namespace string
+		local     string
+		name      string
+		value     string
+	}
+	attrs := make([]attribute, 0, len(e.attrs))
+	for _, attr := range e.attrs {
+		namespace := ""
+		if attr.Name.Space != "" {
+			namespace, _ = e.lookupNamespace(attr.Name.Space)
+		}
+		attrs = append(attrs, attribute{namespace, attr.Name.Local, qualifiedName(attr.Name.Space, attr.Name.Local), attr.Value})
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].namespace != attrs[j].namespace {
+			return attrs[i].namespace < attrs[j].namespace
+		}
+		return attrs[i].local < attrs[j].local
+	})
+
+	name := qualifiedName(e.prefix, e.local)
+	buf.WriteByte('<')
+	buf.WriteString(name)
+	if len(decls) > 0 {
+		inner := make(map[string]string, len(rendered)+len(decls))
+		for prefix, uri := range rendered {
+			inner[prefix] = uri
+		}
+		for _, decl := range decls {
+			buf.WriteString(" xmlns")
+			if decl.prefix != "" {
+				buf.WriteByte(':')
+				buf.WriteString(decl.prefix)
+			}
+			buf.WriteString(`="`)
+			escapeAttr(buf, decl.uri)
+			buf.WriteByte('"')
+			inner[decl.prefix] = decl.uri
+		}
+		rendered = inner
+	}
+	for _, attr := range attrs {
+		buf.WriteByte(' ')
+		buf.WriteString(attr.name)
+		buf.WriteString(`="`)
+		escapeAttr(buf, attr.value)
+		buf.WriteByte('"')
+	}
+	buf.WriteByte('>')
+
+	for _, child := range e.children {
+		switch t := child.(type) {
+		case *element:
+			if t != c.exclude {
+				c.writeElement(buf, t, rendered)
+			}
+		case xml.CharData:
+			escapeText(buf, string(t))
+		case xml.Comment:
+			if c.withComments {
+				buf.WriteString("<!--")
+				buf.Write(t)
+				buf.WriteString("-->")
+			}
+		case xml.ProcInst:
+			buf.WriteString("<?")
+			buf.WriteString(t.Target)
+			if len(t.Inst) > 0 {
+				buf.WriteByte(' ')
+				buf.Write(t.Inst)
+			}
+			buf.WriteString("?>")
+		}
+	}
+
+	buf.WriteString("</")
+	buf.WriteString(name)
+	buf.WriteByte('>')
+}
+
+func escapeText(buf *bytes.Buffer, s string) {
+	for _, r := range s {
+		switch r {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '>':
+			buf.WriteString("&gt;")
+		case '\r':
+			buf.WriteString("&#xD;")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+}
+
+func escapeAttr(buf *bytes.Buffer, s string) {
+	for _, r := range s {
+		switch r {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '"':
+			buf.WriteString("&quot;")
+		case '\t':
+			buf.WriteString("&#x9;")
+		case '\n':
+			buf.WriteString("&#xA;")
+		case '\r':
+			buf.WriteString("&#xD;")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package saml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// find returns the first element of the subtree with the local name
+func (e *element) find(local string) *element {
+	if e.local == local {
+		return e
+	}
+	for _, child := range e.children {
+		if el, ok := child.(*element); ok {
+			if found := el.find(local); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// TestCanonicalizeConformance uses the examples of the W3C recommendations
+// https://www.w3.org/TR/xml-exc-c14n/ and https://www.w3.org/TR/xml-c14n/.
+// The examples which rely on a document type declaration are left out as such documents are rejected.
+func TestCanonicalizeConformance(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		input        string
+		apex         string
+		prefixList   string
+		withComments bool
+		expected     string
+	}{
+		{
+			// Exclusive XML Canonicalization 2.2, the namespaces of the ancestors are not rendered unless used
+			name: "ExcC14N 2.2 first document",
+			input: `<n0:local xmlns:n0="foo:bar" xmlns:n3="ftp://example.org">
+  <n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+    <n3:stuff xmlns:n3="ftp://example.org"/>
+  </n1:elem2>
+</n0:local>`,
+			apex: "elem2",
+			expected: `<n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+    <n3:stuff xmlns:n3="ftp://example.org"></n3:stuff>
+  </n1:elem2>`,
+		},
+		{
+			// Exclusive XML Canonicalization 2.2, the xml attributes of the ancestors are not inherited
+			name: "ExcC14N 2.2 second document",
+			input: `<n2:pdu xmlns:n1="http://example.com" xmlns:n2="http://foo.example" xml:lang="fr" xml:space="retain">
+  <n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+    <n3:stuff xmlns:n3="ftp://example.org"/>
+  </n1:elem2>
+</n2:pdu>`,
+			apex: "elem2",
+			expected: `<n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+    <n3:stuff xmlns:n3="ftp://example.org"></n3:stuff>
+  </n1:elem2>`,
+		},
+		{
+			name: "ExcC14N 2.2 InclusiveNamespaces PrefixList",
+			input: `<n2:pdu xmlns:n1="http://example.com" xmlns:n2="http://foo.example">
+  <n1:elem2 xmlns:n1="http://example.net">
+    <n3:stuff xmlns:n3="ftp://example.org"/>
+  </n1:elem2>
+</n2:pdu>`,
+			apex:       "elem2",
+			prefixList: "n2",
+			expected: `<n1:elem2 xmlns:n1="http://example.net" xmlns:n2="http://foo.example">
+    <n3:stuff xmlns:n3="ftp://example.org"></n3:stuff>
+  </n1:elem2>`,
+		},
+		{
+			name:     "C14N 3.1 PIs and comments",
+			input:    `<doc><?pi-without-data?><!-- Comment 2 --><?pi target with data?></doc>`,
+			apex:     "doc",
+			expected: `<doc><?pi-without-data?><?pi target with data?></doc>`,
+		},
+		{
+			name:         "C14N 3.1 PIs and comments WithComments",
+			input:        `<doc><?pi-without-data?><!-- Comment 2 --></doc>`,
+			apex:         "doc",
+			withComments: true,
+			expected:     `<doc><?pi-without-data?><!-- Comment 2 --></doc>`,
+		},
+		{
+			name: "C14N 3.2 whitespace in document content",
+			input: `<doc>
+   <clean>   </clean>
+   <dirty>   A   B   </dirty>
+   <mixed>
+      A
+      <clean>   </clean>
+      B
+      <dirty>   A   B   </dirty>
+      C
+   </mixed>
+</doc>`,
+			apex: "doc",
+			expected: `<doc>
+   <clean>   </clean>
+   <dirty>   A   B   </dirty>
+   <mixed>
+      A
+      <clean>   </clean>
+      B
+      <dirty>   A   B   </dirty>
+      C
+   </mixed>
+</doc>`,
+		},
+		{
+			// the namespace declarations which are not used are left out by the exclusive canonicalization
+			name: "C14N 3.3 start and end tags",
+			input: `<doc>
+   <e1   />
+   <e2   ></e2>
+   <e3   name = "elem3"   id="elem3"   />
+   <e4   name="elem4"   id="elem4"   ></e4>
+   <e5 a:attr="out" b:attr="sorted" attr2="all" attr="I'm"
+      xmlns:b="http://www.ietf.org"
+      xmlns:a="http://www.w3.org"
+      xmlns="http://example.org"/>
+   <e6 xmlns="" xmlns:a="http://www.w3.org">
+      <e7 xmlns="http://www.ietf.org">
+         <e8 xmlns="" xmlns:a="http://www.w3.org">
+            <e9 xmlns="" xmlns:a="http://www.ietf.org"/>
+         </e8>
+      </e7>
+   </e6>
+</doc>`,
+			apex: "doc",
+			expected: `<doc>
+   <e1></e1>
+   <e2></e2>
+   <e3 id="elem3" name="elem3"></e3>
+   <e4 id="elem4" name="elem4"></e4>
+   <e5 xmlns="http://example.org" xmlns:a="http://www.w3.org" xmlns:b="http://www.ietf.org" attr="I'm" attr2="all" b:attr="sorted" a:attr="out"></e5>
+   <e6>
+      <e7 xmlns="http://www.ietf.org">
+         <e8 xmlns="">
+            <e9></e9>
+         </e8>
+      </e7>
+   </e6>
+</doc>`,
+		},
+		{
+			name: "C14N 3.4 character modifications and character references",
+			input: `<doc>
+   <text>First line&#x0d;&#10;Second line</text>
+   <value>&#x32;</value>
+   <compute><![CDATA[value>"0" && value<"10" ?"valid":"error"]]></compute>
+   <compute expr='value>"0" &amp;&amp; value&lt;"10" ?"valid":"error"'>valid</compute>
+   <norm attr=' &apos;   &#x20;&#13;&#xa;&#9;   &apos; '/>
+</doc>`,
+			apex: "doc",
+			expected: `<doc>
+   <text>First line&#xD;
+Second line</text>
+   <value>2</value>
+   <compute>value&gt;"0" &amp;&amp; value&lt;"10" ?"valid":"error"</compute>
+   <compute expr="value>&quot;0&quot; &amp;&amp; value&lt;&quot;10&quot; ?&quot;valid&quot;:&quot;error&quot;">valid</compute>
+   <norm attr=" '    &#xD;&#xA;&#x9;   ' "></norm>
+</doc>`,
+		},
+		{
+			name:     "C14N 3.6 UTF-8 encoding",
+			input:    `<doc>&#169;</doc>`,
+			apex:     "doc",
+			expected: `<doc>©</doc>`,
+		},
+		{
+			// literal line breaks and tabs of attribute values are normalized to spaces by the parser
+			name:     "attribute value normalization",
+			input:    "<doc attr=\"a\r\nb\tc\nd\"></doc>",
+			apex:     "doc",
+			expected: `<doc attr="a b c d"></doc>`,
+		},
+		{
+			name:     "line ending normalization",
+			input:    "<doc>a\r\nb\rc</doc>",
+			apex:     "doc",
+			expected: "<doc>a\nb\nc</doc>",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := parseDocument([]byte(tc.input))
+			require.NoError(t, err)
+			apex := doc.find(tc.apex)
+			require.NotNil(t, apex)
+			assert.Equal(t, tc.expected, string(newCanonicalizer(tc.prefixList, tc.withComments, nil).canonicalize(apex)))
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package saml
+
+import (
+	"crypto/x509"
+	"encoding/xml"
+	"errors"
+	"fmt"
+)
+
+type metadataEndpoint struct {
+	Binding  string `xml:"Binding,attr"`
+	Location string `xml:"Location,attr"`
+}
+
+type metadataKeyDescriptor struct {
+	Use          string   `xml:"use,attr"`
+	Certificates []string `xml:"KeyInfo>X509Data>X509Certificate"`
+}
+
+type metadataIDPSSODescriptor struct {
+	KeyDescriptors       []metadataKeyDescriptor `xml:"urn:oasis:names:tc:SAML:2.0:metadata KeyDescriptor"`
+	SingleSignOnServices []metadataEndpoint      `xml:"urn:oasis:names:tc:SAML:2.0:metadata SingleSignOnService"`
+}
+
+type metadataEntityDescriptor struct {
+	XMLName           xml.Name                   `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID          string                     `xml:"entityID,attr"`
+	IDPSSODescriptors []metadataIDPSSODescriptor `xml:"urn:oasis:names:tc:SAML:2.0:metadata IDPSSODescriptor"`
+}
+
+// IdentityProvider is the part of the metadata of an identity provider needed to log in users
+type IdentityProvider struct {
+	EntityID string
+	// SingleSignOnURL receives authentication requests with the HTTP-Redirect binding
+	SingleSignOnURL string
+	// Certificates are used to verify the signatures of responses
+	Certificates []*x509.Certificate
+}
+
+// ParseIdentityProviderMetadata reads the metadata of a single identity provider
+func ParseIdentityProviderMetadata(data []byte) (*IdentityProvider, error) {
+	// the document is parsed once to reject document type declarations
+	if _, err := parseDocument(data); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	var descriptor metadataEntityDescriptor
+	if err := xml.Unmarshal(data, &descriptor); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	if descriptor.EntityID == "" {
+		return nil, errors.New("the metadata has no entity id")
+	}
+	if len(descriptor.IDPSSODescriptors) == 0 {
+		return nil, errors.New("the metadata does not describe an identity provider")
+	}
+
+	idp := &IdentityProvider{EntityID: descriptor.EntityID}
+	for _, sso := range descriptor.IDPSSODescriptors {
+		for _, service := range sso.SingleSignOnServices {
+			if service.Binding == BindingHTTPRedirect && idp.SingleSignOnURL == "" {
+				idp.SingleSignOnURL = service.Location
+			}
+		}
+		for _, key := range sso.KeyDescriptors {
+			if key.Use != "" && key.Use != "signing" {
+				continue
+			}
+			for _, encoded := range key.Certificates {
+				der, err := decodeBase64(encoded)
+				if err != nil {
+					return nil, fmt.Errorf("invalid certificate: %w", err)
+				}
+				certificate, err := x509.ParseCertificate(der)
+				if err != nil {
+					return nil, fmt.Errorf("invalid certificate: %w", err)
+				}
+				idp.Certificates = append(idp.Certificates, certificate)
+			}
+		}
+	}
+	if idp.SingleSignOnURL == "" {
+		return nil, errors.New("the identity provider does not support the HTTP-Redirect binding")
+	}
+	if len(idp.Certificates) == 0 {
+		return nil, errors.New("the metadata has no signing certificate")
+	}
+	return idp, nil
+}
+
+type spAssertionConsumerService struct {
+	Binding   string `xml:"Binding,attr"`
+	Location  string `xml:"Location,attr"`
+	Index     int    `xml:"index,attr"`
+	IsDefault bool   `xml:"isDefault,attr"`
+}
+
+type spSSODescriptor struct {
+	AuthnRequestsSigned        bool                       `xml:"AuthnRequestsSigned,attr"`
+	WantAssertionsSigned       bool                       `xml:"WantAssertionsSigned,attr"`
+	ProtocolSupportEnumeration string                     `xml:"protocolSupportEnumeration,attr"`
+	NameIDFormat               string                     `xml:"md:NameIDFormat,omitempty"`
+	AssertionConsumerService   spAssertionConsumerService `xml:"md:AssertionConsumerService"`
+}
+
+type spEntityDescriptor struct {
+	XMLName         xml.Name        `xml:"md:EntityDescriptor"`
+	XMLNSMetadata   string          `xml:"xmlns:md,attr"`
+	EntityID        string          `xml:"entityID,attr"`
+	SPSSODescriptor spSSODescriptor `xml:"md:SPSSODescriptor"`
+}
+
+// Metadata returns the metadata of the service provider to register it at the identity provider
+func (sp *ServiceProvider) Metadata() ([]byte, error) {
+	data, err := xml.MarshalIndent(&spEntityDescriptor{
+		XMLNSMetadata: nsMetadata,
+		EntityID:      sp.EntityID,
+		SPSSODescriptor: spSSODescriptor{
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: nsProtocol,
+			NameIDFormat:               sp.NameIDFormat,
+			AssertionConsumerService: spAssertionConsumerService{
+				Binding:   BindingHTTPPost,
+				Location:  sp.AssertionConsumerServiceURL,
+				IsDefault: true,
+			},
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,219 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package saml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidResponse is wrapped by all errors about responses which must not be accepted
+var ErrInvalidResponse = errors.New("invalid SAML response")
+
+func invalidResponse(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidResponse, fmt.Sprintf(format, args...))
+}
+
+// NameID identifies the subject of an assertion
+type NameID struct {
+	Format string `xml:"Format,attr"`
+	Value  string `xml:",chardata"`
+}
+
+type subjectConfirmationData struct {
+	NotOnOrAfter time.Time `xml:"NotOnOrAfter,attr"`
+	Recipient    string    `xml:"Recipient,attr"`
+	InResponseTo string    `xml:"InResponseTo,attr"`
+}
+
+type subjectConfirmation struct {
+	Method string                  `xml:"Method,attr"`
+	Data   subjectConfirmationData `xml:"SubjectConfirmationData"`
+}
+
+type subject struct {
+	NameID        NameID                `xml:"NameID"`
+	Confirmations []subjectConfirmation `xml:"SubjectConfirmation"`
+}
+
+type audienceRestriction struct {
+	Audiences []string `xml:"Audience"`
+}
+
+type conditions struct {
+	NotBefore            time.Time             `xml:"NotBefore,attr"`
+	NotOnOrAfter         time.Time             `xml:"NotOnOrAfter,attr"`
+	AudienceRestrictions []audienceRestriction `xml:"AudienceRestriction"`
+}
+
+// Attribute is an attribute of the subject of an assertion
+type Attribute struct {
+	Name         string   `xml:"Name,attr"`
+	FriendlyName string   `xml:"FriendlyName,attr"`
+	Values       []string `xml:"AttributeValue"`
+}
+
+type attributeStatement struct {
+	Attributes []Attribute `xml:"Attribute"`
+}
+
+// Assertion is a verified statement of the identity provider about a user
+type Assertion struct {
+	XMLName             xml.Name             `xml:"urn:oasis:names:tc:SAML:2.0:assertion Assertion"`
+	ID                  string               `xml:"ID,attr"`
+	Issuer              string               `xml:"Issuer"`
+	Subject             subject              `xml:"Subject"`
+	Conditions          *conditions          `xml:"Conditions"`
+	AttributeStatements []attributeStatement `xml:"AttributeStatement"`
+
+	// InResponseTo is the ID of the authentication request, empty for logins started at the identity provider
+	InResponseTo string `xml:"-"`
+	// NotOnOrAfter is the time until which the assertion could be replayed
+	NotOnOrAfter time.Time `xml:"-"`
+}
+
+// NameID returns the identifier of the user
+func (a *Assertion) NameID() NameID {
+	return a.Subject.NameID
+}
+
+// Attributes returns the values of the attributes by name and friendly name
+func (a *Assertion) Attributes() map[string][]string {
+	attributes := make(map[string][]string)
+	for _, statement := range a.AttributeStatements {
+		for _, attr := range statement.Attributes {
+			attributes[attr.Name] = append(attributes[attr.Name], attr.Values...)
+		}
+	}
+	for _, statement := range a.AttributeStatements {
+		for _, attr := range statement.Attributes {
+			if _, ok := attributes[attr.FriendlyName]; attr.FriendlyName != "" && !ok {
+				attributes[attr.FriendlyName] = attr.Values
+			}
+		}
+	}
+	return attributes
+}
+
+// ParseResponse verifies a base64 encoded response received with the HTTP-POST binding and returns its assertion.
+// The caller must check that InResponseTo is the ID of a pending request and that the assertion is not replayed.
+func (sp *ServiceProvider) ParseResponse(encoded string, now time.Time) (*Assertion, error) {
+	data, err := decodeBase64(encoded)
+	if err != nil {
+		return nil, invalidResponse("%v", err)
+	}
+	response, err := parseDocument(data)
+	if err != nil {
+		return nil, invalidResponse("%v", err)
+	}
+	if !response.is(nsProtocol, "Response") {
+		return nil, invalidResponse("unexpected element %s", response.local)
+	}
+	if destination := response.attr("Destination"); destination != "" && destination != sp.AssertionConsumerServiceURL {
+		return nil, invalidResponse("the response is meant for %s", destination)
+	}
+	if issuer := response.element(nsAssertion, "Issuer").text(); issuer != "" && issuer != sp.IdentityProvider.EntityID {
+		return nil, invalidResponse("unexpected issuer %s", issuer)
+	}
+	if status := response.element(nsProtocol, "Status").element(nsProtocol, "StatusCode").attr("Value"); status != statusSuccess {
+		message := response.element(nsProtocol, "Status").element(nsProtocol, "StatusMessage").text()
+		return nil, invalidResponse("the identity provider returned %s %s", status, message)
+	}
+	if len(response.elements(nsAssertion, "EncryptedAssertion")) > 0 {
+		return nil, invalidResponse("encrypted assertions are not supported")
+	}
+	assertions := response.elements(nsAssertion, "Assertion")
+	if len(assertions) != 1 {
+		return nil, invalidResponse("the response must contain exactly one assertion")
+	}
+
+	// either the response or the assertion must be signed, the assertion is covered by a signature of the response
+	responseSigned := false
+	if err := verifySignature(response, sp.IdentityProvider.Certificates); err == nil {
+		responseSigned = true
+	} else if !errors.Is(err, ErrMissingSignature) {
+		return nil, invalidResponse("%v", err)
+	}
+	if err := verifySignature(assertions[0], sp.IdentityProvider.Certificates); err != nil {
+		if !responseSigned || !errors.Is(err, ErrMissingSignature) {
+			return nil, invalidResponse("%v", err)
+		}
+	}
+
+	// only the verified element is read, this rules out data injected next to it
+	assertion := &Assertion{}
+	if err := xml.Unmarshal(newCanonicalizer("", false, nil).canonicalize(assertions[0]), assertion); err != nil {
+		return nil, invalidResponse("%v", err)
+	}
+	assertion.InResponseTo = response.attr("InResponseTo")
+	if err := sp.validateAssertion(assertion, now); err != nil {
+		return nil, err
+	}
+	return assertion, nil
+}
+
+func (sp *ServiceProvider) validateAssertion(assertion *Assertion, now time.Time) error {
+	skew := sp.clockSkew()
+
+	if assertion.ID == "" {
+		return invalidResponse("the assertion has no ID")
+	}
+	if strings.TrimSpace(assertion.Issuer) != sp.IdentityProvider.EntityID {
+		return invalidResponse("unexpected issuer %s", assertion.Issuer)
+	}
+	assertion.Subject.NameID.Value = strings.TrimSpace(assertion.Subject.NameID.Value)
+	if assertion.Subject.NameID.Value == "" {
+		return invalidResponse("the assertion has no subject")
+	}
+
+	if c := assertion.Conditions; c != nil {
+		if !c.NotBefore.IsZero() && now.Add(skew).Before(c.NotBefore) {
+			return invalidResponse("the assertion is not yet valid")
+		}
+		if !c.NotOnOrAfter.IsZero() {
+			if !now.Add(-skew).Before(c.NotOnOrAfter) {
+				return invalidResponse("the assertion has expired")
+			}
+			assertion.NotOnOrAfter = c.NotOnOrAfter
+		}
+		for _, restriction := range c.AudienceRestrictions {
+			found := false
+			for _, audience := range restriction.Audiences {
+				if strings.TrimSpace(audience) == sp.EntityID {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return invalidResponse("the assertion is meant for another audience")
+			}
+		}
+	}
+
+	confirmed := false
+	for _, confirmation := range assertion.Subject.Confirmations {
+		if confirmation.Method != confirmationMethodBearer {
+			continue
+		}
+		data := confirmation.Data
+		if data.Recipient != sp.AssertionConsumerServiceURL || data.InResponseTo != assertion.InResponseTo {
+			continue
+		}
+		if data.NotOnOrAfter.IsZero() || !now.Add(-skew).Before(data.NotOnOrAfter) {
+			continue
+		}
+		if assertion.NotOnOrAfter.IsZero() || data.NotOnOrAfter.Before(assertion.NotOnOrAfter) {
+			assertion.NotOnOrAfter = data.NotOnOrAfter
+		}
+		confirmed = true
+		break
+	}
+	if !confirmed {
+		return invalidResponse("the subject confirmation is missing or has expired")
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+// Package saml implements the service provider side of the SAML 2.0 Web Browser SSO profile:
+// authentication requests with the HTTP-Redirect binding and signed responses with the HTTP-POST binding.
+// Encrypted assertions are not supported.
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"net/url"
+	"time"
+)
+
+const (
+	nsMetadata  = "urn:oasis:names:tc:SAML:2.0:metadata"
+	nsProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	nsAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+
+	BindingHTTPRedirect = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	BindingHTTPPost     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+
+	NameIDFormatPersistent   = "urn:oasis:names:tc:SAML:2.0:nameid-format:persistent"
+	NameIDFormatEmailAddress = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+	NameIDFormatUnspecified  = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+
+	statusSuccess = "urn:oasis:names:tc:SAML:2.0:status:Success"
+
+	confirmationMethodBearer = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+)
+
+// DefaultClockSkew is the tolerated difference between the clocks of the identity and the service provider
+const DefaultClockSkew = 3 * time.Minute
+
+// ServiceProvider is the configuration of this instance as SAML service provider of an identity provider
+type ServiceProvider struct {
+	EntityID                    string
+	AssertionConsumerServiceURL string
+	// NameIDFormat is requested from the identity provider, empty to leave it to the identity provider
+	NameIDFormat     string
+	IdentityProvider *IdentityProvider
+	ClockSkew        time.Duration
+}
+
+// NewRequestID returns a random ID for an authentication request
+func NewRequestID() string {
+	b := make([]byte, 20)
+	_, _ = rand.Read(b)
+	// IDs must not start with a digit
+	return "id-" + hex.EncodeToString(b)
+}
+
+type authnRequestNameIDPolicy struct {
+	Format      string `xml:"Format,attr,omitempty"`
+	AllowCreate bool   `xml:"AllowCreate,attr"`
+}
+
+type authnRequest struct {
+	XMLName                     xml.Name                 `xml:"samlp:AuthnRequest"`
+	XMLNSProtocol               string                   `xml:"xmlns:samlp,attr"`
+	XMLNSAssertion              string                   `xml:"xmlns:saml,attr"`
+	ID                          string                   `xml:"ID,attr"`
+	Version                     string                   `xml:"Version,attr"`
+	IssueInstant                string                   `xml:"IssueInstant,attr"`
+	Destination                 string                   `xml:"Destination,attr"`
+	AssertionConsumerServiceURL string                   `xml:"AssertionConsumerServiceURL,attr"`
+	ProtocolBinding             string                   `xml:"ProtocolBinding,attr"`
+	Issuer                      string                   `xml:"saml:Issuer"`
+	NameIDPolicy                authnRequestNameIDPolicy `xml:"samlp:NameIDPolicy"`
+}
+
+// AuthnRequestURL returns the URL of the identity provider to redirect the user to for logging in
+func (sp *ServiceProvider) AuthnRequestURL(requestID, relayState string, now time.Time) (string, error) {
+	request, err := xml.Marshal(&authnRequest{
+		XMLNSProtocol:               nsProtocol,
+		XMLNSAssertion:              nsAssertion,
+		ID:                          requestID,
+		Version:                     "2.0",
+		IssueInstant:                now.UTC().Format(time.RFC3339),
+		Destination:                 sp.IdentityProvider.SingleSignOnURL,
+		AssertionConsumerServiceURL: sp.AssertionConsumerServiceURL,
+		ProtocolBinding:             BindingHTTPPost,
+		Issuer:                      sp.EntityID,
+		NameIDPolicy: authnRequestNameIDPolicy{
+			Format:      sp.NameIDFormat,
+			AllowCreate: true,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(request); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(sp.IdentityProvider.SingleSignOnURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if relayState != "" {
+		query.Set("RelayState", relayState)
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func (sp *ServiceProvider) clockSkew() time.Duration {
+	if sp.ClockSkew > 0 {
+		return sp.ClockSkew
+	}
+	return DefaultClockSkew
+}
@@ -0,0 +1,274 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalize(t *testing.T) {
+	doc, err := parseDocument([]byte(`<?xml version="1.0"?>
+<root xmlns="urn:a" xmlns:b="urn:b" xmlns:unused="urn:u"><b:child z="1" b:a="2" a="3">text &amp; &lt; &gt;</b:child><!-- comment --><empty/><other xmlns="">x</other></root>`))
+	require.NoError(t, err)
+
+	c := newCanonicalizer("", false, nil)
+	assert.Equal(t,
+		`<root xmlns="urn:a"><b:child xmlns:b="urn:b" a="3" z="1" b:a="2">text &amp; &lt; &gt;</b:child><empty></empty><other xmlns="">x</other></root>`,
+		string(c.canonicalize(doc)))
+
+	// namespaces of the ancestors are rendered on the apex if they are used
+	child := doc.elements("urn:b", "child")[0]
+	assert.Equal(t, `<b:child xmlns:b="urn:b" a="3" z="1" b:a="2">text &amp; &lt; &gt;</b:child>`, string(c.canonicalize(child)))
+
+	// the inclusive prefix list renders namespaces which are not visibly used
+	c = newCanonicalizer("unused #default", false, nil)
+	assert.Equal(t, `<b:child xmlns="urn:a" xmlns:b="urn:b" xmlns:unused="urn:u" a="3" z="1" b:a="2">text &amp; &lt; &gt;</b:child>`, string(c.canonicalize(child)))
+
+	_, err = parseDocument([]byte(`<!DOCTYPE root [<!ENTITY e "x">]><root>&e;</root>`))
+	require.Error(t, err)
+	_, err = parseDocument([]byte(`<root><x:child/></root>`))
+	require.Error(t, err)
+}
+
+type testIdentityProvider struct {
+	key         *rsa.PrivateKey
+	certificate *x509.Certificate
+}
+
+func newTestIdentityProvider(t *testing.T) *testIdentityProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testIdentityProvider{key: key, certificate: certificate}
+}
+
+func (idp *testIdentityProvider) metadata() string {
+	return fmt.Sprintf(`<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="https://idp.example.com/metadata">
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:X509Data><ds:X509Certificate>
+        %s
+      </ds:X509Certificate></ds:X509Data></ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="https://idp.example.com/sso/post"/>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.com/sso?tenant=1"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`, base64.StdEncoding.EncodeToString(idp.certificate.Raw))
+}
+
+// sign replaces the <!--signature:ID--> placeholder of the element with the ID by its signature
+func (idp *testIdentityProvider) sign(t *testing.T, document, id string) string {
+	doc, err := parseDocument([]byte(document))
+	require.NoError(t, err)
+	var find func(e *element) *element
+	find = func(e *element) *element {
+		if e.attr("ID") == id {
+			return e
+		}
+		for _, child := range e.children {
+			if el, ok := child.(*element); ok {
+				if found := find(el); found != nil {
+					return found
+				}
+			}
+		}
+		return nil
+	}
+	signed := find(doc)
+	require.NotNil(t, signed)
+
+	digest := sha256.Sum256(newCanonicalizer("", false, nil).canonicalize(signed))
+	signedInfo := fmt.Sprintf(`<ds:SignedInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/><ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"/><ds:Reference URI="#%s"><ds:Transforms><ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"/><ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/></ds:Transforms><ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"/><ds:DigestValue>%s</ds:DigestValue></ds:Reference></ds:SignedInfo>`,
+		id, base64.StdEncoding.EncodeToString(digest[:]))
+	signedInfoElement, err := parseDocument([]byte(signedInfo))
+	require.NoError(t, err)
+	hashed := sha256.Sum256(newCanonicalizer("", false, nil).canonicalize(signedInfoElement))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, hashed[:])
+	require.NoError(t, err)
+
+	return strings.Replace(document, "<!--signature:"+id+"-->", fmt.Sprintf(`<ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#">%s<ds:SignatureValue>%s</ds:SignatureValue></ds:Signature>`,
+		signedInfo, base64.StdEncoding.EncodeToString(signature)), 1)
+}
+
+type testResponse struct {
+	InResponseTo string
+	Audience     string
+	Recipient    string
+	NotOnOrAfter time.Time
+	AssertionID  string
+}
+
+func (r testResponse) String() string {
+	return fmt.Sprintf(`<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="response-1" Version="2.0" IssueInstant="2025-01-01T00:00:00Z" Destination="https://forgejo.example.com/user/saml/idp/acs" InResponseTo="%[1]s">
+  <saml:Issuer>https://idp.example.com/metadata</saml:Issuer><!--signature:response-1-->
+  <samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>
+  <saml:Assertion ID="%[5]s" Version="2.0" IssueInstant="2025-01-01T00:00:00Z">
+    <saml:Issuer>https://idp.example.com/metadata</saml:Issuer><!--signature:%[5]s-->
+    <saml:Subject>
+      <saml:NameID Format="urn:oasis:names:tc:SAML:2.0:nameid-format:persistent">jdoe-4711</saml:NameID>
+      <saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">
+        <saml:SubjectConfirmationData InResponseTo="%[1]s" Recipient="%[3]s" NotOnOrAfter="%[4]s"/>
+      </saml:SubjectConfirmation>
+    </saml:Subject>
+    <saml:Conditions NotBefore="2025-01-01T00:00:00Z" NotOnOrAfter="%[4]s">
+      <saml:AudienceRestriction><saml:Audience>%[2]s</saml:Audience></saml:AudienceRestriction>
+    </saml:Conditions>
+    <saml:AttributeStatement>
+      <saml:Attribute Name="urn:oid:0.9.2342.19200300.100.1.3" FriendlyName="mail"><saml:AttributeValue>jdoe@example.com</saml:AttributeValue></saml:Attribute>
+      <saml:Attribute Name="groups"><saml:AttributeValue>staff</saml:AttributeValue><saml:AttributeValue>admins</saml:AttributeValue></saml:Attribute>
+    </saml:AttributeStatement>
+  </saml:Assertion>
+</samlp:Response>`, r.InResponseTo, r.Audience, r.Recipient, r.NotOnOrAfter.UTC().Format(time.RFC3339), r.AssertionID)
+}
+
+func TestParseResponse(t *testing.T) {
+	idp := newTestIdentityProvider(t)
+	identityProvider, err := ParseIdentityProviderMetadata([]byte(idp.metadata()))
+	require.NoError(t, err)
+	assert.Equal(t, "https://idp.example.com/metadata", identityProvider.EntityID)
+	assert.Equal(t, "https://idp.example.com/sso?tenant=1", identityProvider.SingleSignOnURL)
+	require.Len(t, identityProvider.Certificates, 1)
+
+	sp := &ServiceProvider{
+		EntityID:                    "https://forgejo.example.com/user/saml/idp/metadata",
+		AssertionConsumerServiceURL: "https://forgejo.example.com/user/saml/idp/acs",
+		IdentityProvider:            identityProvider,
+	}
+	now := time.Date(2025, 1, 1, 0, 1, 0, 0, time.UTC)
+	valid := testResponse{
+		InResponseTo: "id-1234",
+		Audience:     sp.EntityID,
+		Recipient:    sp.AssertionConsumerServiceURL,
+		NotOnOrAfter: now.Add(5 * time.Minute),
+		AssertionID:  "assertion-1",
+	}
+	encode := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+
+	t.Run("SignedAssertion", func(t *testing.T) {
+		assertion, err := sp.ParseResponse(encode(idp.sign(t, valid.String(), "assertion-1")), now)
+		require.NoError(t, err)
+		assert.Equal(t, "assertion-1", assertion.ID)
+		assert.Equal(t, "id-1234", assertion.InResponseTo)
+		assert.Equal(t, "jdoe-4711", assertion.NameID().Value)
+		assert.Equal(t, NameIDFormatPersistent, assertion.NameID().Format)
+		assert.Equal(t, valid.NotOnOrAfter, assertion.NotOnOrAfter)
+		attributes := assertion.Attributes()
+		assert.Equal(t, []string{"jdoe@example.com"}, attributes["mail"])
+		assert.Equal(t, []string{"jdoe@example.com"}, attributes["urn:oid:0.9.2342.19200300.100.1.3"])
+		assert.Equal(t, []string{"staff", "admins"}, attributes["groups"])
+	})
+
+	t.Run("SignedResponse", func(t *testing.T) {
+		assertion, err := sp.ParseResponse(encode(idp.sign(t, valid.String(), "response-1")), now)
+		require.NoError(t, err)
+		assert.Equal(t, "jdoe-4711", assertion.NameID().Value)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		other := newTestIdentityProvider(t)
+		expired := valid
+		expired.NotOnOrAfter = now.Add(-5 * time.Minute)
+		audience := valid
+		audience.Audience = "https://other.example.com"
+		recipient := valid
+		recipient.Recipient = "https://other.example.com/acs"
+
+		// the signed assertion is hidden in the extensions and a forged copy takes its place
+		signed := idp.sign(t, valid.String(), "assertion-1")
+		start, end := strings.Index(signed, "<saml:Assertion"), strings.Index(signed, "</saml:Assertion>")+len("</saml:Assertion>")
+		original := signed[start:end]
+		forged := strings.Replace(original, "jdoe-4711", "admin", 1)
+		wrapped := signed[:start] + "<samlp:Extensions>" + original + "</samlp:Extensions>" + forged + signed[end:]
+
+		for name, response := range map[string]string{
+			"Unsigned":    valid.String(),
+			"OtherKey":    other.sign(t, valid.String(), "assertion-1"),
+			"Tampered":    strings.Replace(idp.sign(t, valid.String(), "assertion-1"), "jdoe-4711", "admin", 1),
+			"Expired":     idp.sign(t, expired.String(), "assertion-1"),
+			"Audience":    idp.sign(t, audience.String(), "assertion-1"),
+			"Recipient":   idp.sign(t, recipient.String(), "assertion-1"),
+			"NotResponse": strings.ReplaceAll(idp.sign(t, valid.String(), "assertion-1"), "samlp:Response", "samlp:Other"),
+			"Wrapped":     wrapped,
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := sp.ParseResponse(encode(response), now)
+				require.ErrorIs(t, err, ErrInvalidResponse)
+			})
+		}
+	})
+}
+
+func TestServiceProvider(t *testing.T) {
+	idp := newTestIdentityProvider(t)
+	identityProvider, err := ParseIdentityProviderMetadata([]byte(idp.metadata()))
+	require.NoError(t, err)
+	sp := &ServiceProvider{
+		EntityID:                    "https://forgejo.example.com/user/saml/idp/metadata",
+		AssertionConsumerServiceURL: "https://forgejo.example.com/user/saml/idp/acs",
+		NameIDFormat:                NameIDFormatPersistent,
+		IdentityProvider:            identityProvider,
+	}
+
+	metadata, err := sp.Metadata()
+	require.NoError(t, err)
+	doc, err := parseDocument(metadata)
+	require.NoError(t, err)
+	assert.True(t, doc.is(nsMetadata, "EntityDescriptor"))
+	assert.Equal(t, sp.EntityID, doc.attr("entityID"))
+	acs := doc.element(nsMetadata, "SPSSODescriptor").element(nsMetadata, "AssertionConsumerService")
+	assert.Equal(t, BindingHTTPPost, acs.attr("Binding"))
+	assert.Equal(t, sp.AssertionConsumerServiceURL, acs.attr("Location"))
+
+	redirect, err := sp.AuthnRequestURL("id-1234", "/explore", time.Now())
+	require.NoError(t, err)
+	u, err := url.Parse(redirect)
+	require.NoError(t, err)
+	assert.Equal(t, "idp.example.com", u.Host)
+	assert.Equal(t, "1", u.Query().Get("tenant"))
+	assert.Equal(t, "/explore", u.Query().Get("RelayState"))
+
+	compressed, err := base64.StdEncoding.DecodeString(u.Query().Get("SAMLRequest"))
+	require.NoError(t, err)
+	request, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	require.NoError(t, err)
+	doc, err = parseDocument(request)
+	require.NoError(t, err)
+	assert.True(t, doc.is(nsProtocol, "AuthnRequest"))
+	assert.Equal(t, "id-1234", doc.attr("ID"))
+	assert.Equal(t, sp.AssertionConsumerServiceURL, doc.attr("AssertionConsumerServiceURL"))
+	assert.Equal(t, sp.EntityID, doc.element(nsAssertion, "Issuer").text())
+	assert.Equal(t, NameIDFormatPersistent, doc.element(nsProtocol, "NameIDPolicy").attr("Format"))
+
+	_, err = ParseIdentityProviderMetadata([]byte(strings.ReplaceAll(idp.metadata(), BindingHTTPRedirect, BindingHTTPPost)))
+	require.Error(t, err)
+}
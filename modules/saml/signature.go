@@ -0,0 +1,160 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package saml
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	_ "crypto/sha1" // register the digest algorithms
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+const (
+	nsDSig   = "http://www.w3.org/2000/09/xmldsig#"
+	nsExcC14 = "http://www.w3.org/2001/10/xml-exc-c14n#"
+
+	algorithmExcC14N             = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	algorithmExcC14NWithComments = "http://www.w3.org/2001/10/xml-exc-c14n#WithComments"
+	algorithmEnvelopedSignature  = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+)
+
+var (
+	// ErrMissingSignature is returned if an element is not signed
+	ErrMissingSignature = errors.New("the element is not signed")
+	// ErrInvalidSignature is returned if a signature does not match any of the certificates of the identity provider
+	ErrInvalidSignature = errors.New("the signature is invalid")
+)
+
+var digestAlgorithms = map[string]crypto.Hash{
+	"http://www.w3.org/2000/09/xmldsig#sha1":  crypto.SHA1,
+	"http://www.w3.org/2001/04/xmlenc#sha256": crypto.SHA256,
+	"http://www.w3.org/2001/04/xmlenc#sha512": crypto.SHA512,
+}
+
+var signatureAlgorithms = map[string]crypto.Hash{
+	"http://www.w3.org/2000/09/xmldsig#rsa-sha1":          crypto.SHA1,
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha256":   crypto.SHA256,
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha512":   crypto.SHA512,
+	"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256": crypto.SHA256,
+	"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha512": crypto.SHA512,
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+}
+
+// verifySignature checks the enveloped signature of an element. Only a signature of the element itself,
+// referenced by its ID attribute, is accepted to rule out signature wrapping.
+func verifySignature(signed *element, certificates []*x509.Certificate) error {
+	signatures := signed.elements(nsDSig, "Signature")
+	if len(signatures) == 0 {
+		return ErrMissingSignature
+	}
+	if len(signatures) > 1 {
+		return errors.New("the element has more than one signature")
+	}
+	signature := signatures[0]
+	signedInfo := signature.element(nsDSig, "SignedInfo")
+	if signedInfo == nil {
+		return errors.New("the signature has no SignedInfo")
+	}
+
+	references := signedInfo.elements(nsDSig, "Reference")
+	if len(references) != 1 {
+		return errors.New("the signature must have exactly one reference")
+	}
+	reference := references[0]
+	id := signed.attr("ID")
+	if id == "" || reference.attr("URI") != "#"+id {
+		return errors.New("the signature does not reference the signed element")
+	}
+
+	var digestCanonicalizer *canonicalizer
+	enveloped := false
+	for _, transform := range reference.element(nsDSig, "Transforms").elements(nsDSig, "Transform") {
+		switch algorithm := transform.attr("Algorithm"); algorithm {
+		case algorithmEnvelopedSignature:
+			enveloped = true
+		case algorithmExcC14N, algorithmExcC14NWithComments:
+			// comments are always removed from same-document references
+			digestCanonicalizer = newCanonicalizer(transform.element(nsExcC14, "InclusiveNamespaces").attr("PrefixList"), false, nil)
+		default:
+			return fmt.Errorf("unsupported transform %s", algorithm)
+		}
+	}
+	if !enveloped || digestCanonicalizer == nil {
+		return errors.New("the signature must be an enveloped signature using exclusive canonicalization")
+	}
+	digestCanonicalizer.exclude = signature
+
+	digestHash, ok := digestAlgorithms[reference.element(nsDSig, "DigestMethod").attr("Algorithm")]
+	if !ok {
+		return errors.New("unsupported digest method")
+	}
+	digestValue, err := decodeBase64(reference.element(nsDSig, "DigestValue").text())
+	if err != nil {
+		return fmt.Errorf("invalid digest value: %w", err)
+	}
+	h := digestHash.New()
+	h.Write(digestCanonicalizer.canonicalize(signed))
+	if !bytes.Equal(h.Sum(nil), digestValue) {
+		return ErrInvalidSignature
+	}
+
+	canonicalizationMethod := signedInfo.element(nsDSig, "CanonicalizationMethod")
+	var signedInfoCanonicalizer *canonicalizer
+	switch algorithm := canonicalizationMethod.attr("Algorithm"); algorithm {
+	case algorithmExcC14N, algorithmExcC14NWithComments:
+		prefixList := canonicalizationMethod.element(nsExcC14, "InclusiveNamespaces").attr("PrefixList")
+		signedInfoCanonicalizer = newCanonicalizer(prefixList, algorithm == algorithmExcC14NWithComments, nil)
+	default:
+		return fmt.Errorf("unsupported canonicalization method %s", algorithm)
+	}
+
+	signatureMethod := signedInfo.element(nsDSig, "SignatureMethod").attr("Algorithm")
+	signatureHash, ok := signatureAlgorithms[signatureMethod]
+	if !ok {
+		return fmt.Errorf("unsupported signature method %s", signatureMethod)
+	}
+	signatureValue, err := decodeBase64(signature.element(nsDSig, "SignatureValue").text())
+	if err != nil {
+		return fmt.Errorf("invalid signature value: %w", err)
+	}
+	h = signatureHash.New()
+	h.Write(signedInfoCanonicalizer.canonicalize(signedInfo))
+	hashed := h.Sum(nil)
+
+	for _, certificate := range certificates {
+		if verifyHash(certificate.PublicKey, signatureHash, hashed, signatureValue) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func verifyHash(publicKey crypto.PublicKey, hash crypto.Hash, hashed, signature []byte) bool {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, hash, hashed, signature) == nil
+	case *ecdsa.PublicKey:
+		// XML signatures use the concatenation of r and s instead of ASN.1
+		if len(signature) == 0 || len(signature)%2 != 0 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:len(signature)/2])
+		s := new(big.Int).SetBytes(signature[len(signature)/2:])
+		return ecdsa.Verify(key, hashed, r, s)
+	}
+	return false
+}
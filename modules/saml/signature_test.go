@@ -0,0 +1,144 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package saml
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cut returns the first part of s from the start up to and including end, and s without it
+func cut(t *testing.T, s, start, end string) (string, string) {
+	t.Helper()
+	i := strings.Index(s, start)
+	require.GreaterOrEqual(t, i, 0)
+	j := strings.Index(s[i:], end)
+	require.GreaterOrEqual(t, j, 0)
+	j += i + len(end)
+	return s[i:j], s[:i] + s[j:]
+}
+
+// TestSignatureWrapping covers the XML signature wrapping attacks XSW1 to XSW8 of
+// "On Breaking SAML: Be Whoever You Want to Be" (Somorovsky et al., USENIX Security 2012)
+// and other known attacks on XML signatures of SAML responses.
+func TestSignatureWrapping(t *testing.T) {
+	idp := newTestIdentityProvider(t)
+	identityProvider, err := ParseIdentityProviderMetadata([]byte(idp.metadata()))
+	require.NoError(t, err)
+	sp := &ServiceProvider{
+		EntityID:                    "https://forgejo.example.com/user/saml/idp/metadata",
+		AssertionConsumerServiceURL: "https://forgejo.example.com/user/saml/idp/acs",
+		IdentityProvider:            identityProvider,
+	}
+	now := time.Date(2025, 1, 1, 0, 1, 0, 0, time.UTC)
+	valid := testResponse{
+		InResponseTo: "id-1234",
+		Audience:     sp.EntityID,
+		Recipient:    sp.AssertionConsumerServiceURL,
+		NotOnOrAfter: now.Add(5 * time.Minute),
+		AssertionID:  "assertion-1",
+	}
+	parse := func(response string) (*Assertion, error) {
+		return sp.ParseResponse(base64.StdEncoding.EncodeToString([]byte(response)), now)
+	}
+
+	signedResponse := idp.sign(t, valid.String(), "response-1")
+	signedAssertion := idp.sign(t, valid.String(), "assertion-1")
+
+	// the parts of the response with the signed response
+	responseSignature, unsignedResponse := cut(t, signedResponse, "<ds:Signature", "</ds:Signature>")
+	forgedResponse := strings.Replace(unsignedResponse, "jdoe-4711", "admin", 1)
+
+	// the parts of the response with the signed assertion
+	assertion, _ := cut(t, signedAssertion, "<saml:Assertion", "</saml:Assertion>")
+	assertionSignature, unsignedAssertion := cut(t, assertion, "<ds:Signature", "</ds:Signature>")
+	forgedAssertion := strings.Replace(assertion, "jdoe-4711", "admin", 1)
+	forgedUnsignedAssertion := strings.Replace(unsignedAssertion, "jdoe-4711", "admin", 1)
+	withAssertion := func(replacement string) string {
+		return strings.Replace(signedAssertion, assertion, replacement, 1)
+	}
+	insertIntoSignature := func(signature, content string) string {
+		return strings.Replace(signature, "</ds:Signature>", content+"</ds:Signature>", 1)
+	}
+	issuerEnd := "</saml:Issuer>"
+
+	for name, response := range map[string]string{
+		// the signed response is moved into the signature of a forged response
+		"XSW1": strings.Replace(
+			strings.Replace(forgedResponse, `ID="response-1"`, `ID="response-evil"`, 1),
+			issuerEnd, issuerEnd+insertIntoSignature(responseSignature, signedResponse), 1),
+		"XSW1 same ID": strings.Replace(forgedResponse, issuerEnd, issuerEnd+insertIntoSignature(responseSignature, signedResponse), 1),
+		// the signed response is placed next to the signature of a forged response
+		"XSW2": strings.Replace(
+			strings.Replace(forgedResponse, `ID="response-1"`, `ID="response-evil"`, 1),
+			issuerEnd, issuerEnd+signedResponse+responseSignature, 1),
+		// a forged assertion is placed before the signed one
+		"XSW3": withAssertion(strings.Replace(forgedUnsignedAssertion, `ID="assertion-1"`, `ID="assertion-evil"`, 1) + assertion),
+		// the signed assertion is wrapped in a forged one
+		"XSW4": withAssertion(strings.Replace(
+			strings.Replace(forgedUnsignedAssertion, `ID="assertion-1"`, `ID="assertion-evil"`, 1),
+			"</saml:Assertion>", assertion+"</saml:Assertion>", 1)),
+		// the signature is copied into a forged assertion and the signed one is placed after it
+		"XSW5": withAssertion(forgedAssertion + strings.Replace(unsignedAssertion, `ID="assertion-1"`, `ID="assertion-original"`, 1)),
+		// the signed assertion is moved into the signature of a forged assertion
+		"XSW6": withAssertion(strings.Replace(forgedAssertion, assertionSignature,
+			insertIntoSignature(assertionSignature, `<ds:Object xmlns:ds="http://www.w3.org/2000/09/xmldsig#">`+assertion+`</ds:Object>`), 1)),
+		// the signed assertion is hidden in the extensions of the response
+		"XSW7": withAssertion(`<samlp:Extensions>` + assertion + `</samlp:Extensions>` + forgedUnsignedAssertion),
+		// the signed assertion without its signature is moved into the signature of a forged assertion
+		"XSW8": withAssertion(strings.Replace(forgedAssertion, assertionSignature,
+			insertIntoSignature(assertionSignature, `<ds:Object xmlns:ds="http://www.w3.org/2000/09/xmldsig#">`+unsignedAssertion+`</ds:Object>`), 1)),
+
+		// the signature references the whole document
+		"EmptyReference": strings.Replace(signedAssertion, `URI="#assertion-1"`, `URI=""`, 1),
+		// a transform which could select other content than the referenced element
+		"XSLTTransform": strings.Replace(signedAssertion, `<ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/>`,
+			`<ds:Transform Algorithm="http://www.w3.org/TR/1999/REC-xslt-19991116"/>`, 1),
+		// a signature method with a shared secret instead of the key of the identity provider
+		"HMACSignatureMethod": strings.Replace(signedAssertion, "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256",
+			"http://www.w3.org/2000/09/xmldsig#hmac-sha1", 1),
+		// the certificate of the signature must not be trusted, only those of the metadata
+		"EmbeddedCertificate": func() string {
+			other := newTestIdentityProvider(t)
+			return strings.Replace(other.sign(t, valid.String(), "assertion-1"), "</ds:SignatureValue>",
+				"</ds:SignatureValue><ds:KeyInfo><ds:X509Data><ds:X509Certificate>"+
+					base64.StdEncoding.EncodeToString(other.certificate.Raw)+
+					"</ds:X509Certificate></ds:X509Data></ds:KeyInfo>", 1)
+		}(),
+		// the signature element is not in the namespace of XML signatures
+		"OtherNamespace": strings.Replace(signedAssertion, `<ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#">`,
+			`<ds:Signature xmlns:ds="urn:evil">`, 1),
+		"DuplicateSignature": withAssertion(strings.Replace(assertion, assertionSignature, assertionSignature+assertionSignature, 1)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parse(response)
+			require.ErrorIs(t, err, ErrInvalidResponse)
+		})
+	}
+
+	t.Run("CommentInNameID", func(t *testing.T) {
+		// comments are not signed, the subject must not be truncated at them
+		document := strings.Replace(valid.String(), "jdoe-4711", "jdoe-4711.evil.example.com", 1)
+		signed := strings.Replace(idp.sign(t, document, "assertion-1"), "jdoe-4711.evil", "jdoe-4711<!---->.evil", 1)
+		assertion, err := parse(signed)
+		require.NoError(t, err)
+		assert.Equal(t, "jdoe-4711.evil.example.com", assertion.NameID().Value)
+	})
+
+	t.Run("ProcessingInstruction", func(t *testing.T) {
+		// processing instructions are signed
+		document := strings.Replace(valid.String(), "<saml:Subject>", "<saml:Subject><?pi data?>", 1)
+		signed := idp.sign(t, document, "assertion-1")
+		_, err := parse(signed)
+		require.NoError(t, err)
+
+		_, err = parse(strings.Replace(signed, "<?pi data?>", "<?pi other?>", 1))
+		require.ErrorIs(t, err, ErrInvalidResponse)
+	})
+}
@@ -0,0 +1,508 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"forgejo.org/modules/json"
+)
+
+// Filter is a parsed filter expression, see RFC 7644 section 3.4.2.2.
+// Filters are matched against the JSON representation of a resource.
+type Filter interface {
+	Match(resource map[string]any) bool
+}
+
+// Comparison filter operators
+const (
+	OperatorEqual          = "eq"
+	OperatorNotEqual       = "ne"
+	OperatorContains       = "co"
+	OperatorStartsWith     = "sw"
+	OperatorEndsWith       = "ew"
+	OperatorPresent        = "pr"
+	OperatorGreater        = "gt"
+	OperatorGreaterOrEqual = "ge"
+	OperatorLess           = "lt"
+	OperatorLessOrEqual    = "le"
+)
+
+// Comparison compares the values of an attribute
+type Comparison struct {
+	Attribute    string
+	SubAttribute string
+	Operator     string
+	Value        any
+}
+
+// Logical combines two filters with "and" or "or"
+type Logical struct {
+	Operator string
+	Left     Filter
+	Right    Filter
+}
+
+// Not negates a filter
+type Not struct {
+	Filter Filter
+}
+
+// ValuePath matches the elements of a multi-valued attribute, e.g. emails[type eq "work"]
+type ValuePath struct {
+	Attribute string
+	Filter    Filter
+}
+
+// Path references an attribute in a PATCH operation, e.g. members[value eq "2"].display
+type Path struct {
+	Attribute    string
+	Filter       Filter
+	SubAttribute string
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOpen
+	tokenClose
+	tokenOpenBracket
+	tokenCloseBracket
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+func tokenize(s string) ([]token, error) {
+	tokens := make([]token, 0, 8)
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenOpen})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenClose})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{kind: tokenOpenBracket})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{kind: tokenCloseBracket})
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			var value string
+			if err := json.Unmarshal([]byte(s[i:j+1]), &value); err != nil {
+				return nil, fmt.Errorf("invalid string at %d: %w", i, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value})
+			i = j + 1
+		default:
+			j := i
+			for ; j < len(s) && !strings.ContainsRune(" \t()[]\"", rune(s[j])); j++ {
+			}
+			tokens = append(tokens, token{kind: tokenWord, value: s[i:j]})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	if p.pos >= len(p.tokens) {
+		return token{kind: tokenEOF}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *parser) peekKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenWord && strings.EqualFold(t.value, keyword)
+}
+
+func (p *parser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Operator: "or", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Operator: "and", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseGroup() (Filter, error) {
+	if t := p.next(); t.kind != tokenOpen {
+		return nil, fmt.Errorf("expected (")
+	}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.next(); t.kind != tokenClose {
+		return nil, fmt.Errorf("expected )")
+	}
+	return filter, nil
+}
+
+func (p *parser) parseUnary() (Filter, error) {
+	if p.peekKeyword("not") {
+		p.next()
+		filter, err := p.parseGroup()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Filter: filter}, nil
+	}
+	if p.peek().kind == tokenOpen {
+		return p.parseGroup()
+	}
+
+	t := p.next()
+	if t.kind != tokenWord {
+		return nil, fmt.Errorf("expected attribute")
+	}
+	attribute := attributeName(t.value)
+
+	if p.peek().kind == tokenOpenBracket {
+		p.next()
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenCloseBracket {
+			return nil, fmt.Errorf("expected ]")
+		}
+		return &ValuePath{Attribute: attribute, Filter: filter}, nil
+	}
+
+	op := p.next()
+	if op.kind != tokenWord {
+		return nil, fmt.Errorf("expected operator after %s", attribute)
+	}
+	comparison := &Comparison{Operator: strings.ToLower(op.value)}
+	comparison.Attribute, comparison.SubAttribute, _ = strings.Cut(attribute, ".")
+	switch comparison.Operator {
+	case OperatorPresent:
+		return comparison, nil
+	case OperatorEqual, OperatorNotEqual, OperatorContains, OperatorStartsWith, OperatorEndsWith,
+		OperatorGreater, OperatorGreaterOrEqual, OperatorLess, OperatorLessOrEqual:
+	default:
+		return nil, fmt.Errorf("unknown operator %s", op.value)
+	}
+
+	value := p.next()
+	switch value.kind {
+	case tokenString:
+		comparison.Value = value.value
+	case tokenWord:
+		switch strings.ToLower(value.value) {
+		case "true":
+			comparison.Value = true
+		case "false":
+			comparison.Value = false
+		case "null":
+			comparison.Value = nil
+		default:
+			number, err := strconv.ParseFloat(value.value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %s", value.value)
+			}
+			comparison.Value = number
+		}
+	default:
+		return nil, fmt.Errorf("expected value after %s", op.value)
+	}
+	return comparison, nil
+}
+
+// attributeName strips the schema URN of fully qualified attribute names
+func attributeName(name string) string {
+	if strings.HasPrefix(strings.ToLower(name), "urn:") {
+		if i := strings.LastIndex(name, ":"); i >= 0 {
+			return name[i+1:]
+		}
+	}
+	return name
+}
+
+// ParseFilter parses a filter expression
+func ParseFilter(s string) (Filter, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidFilter, "%v", err)
+	}
+	p := &parser{tokens: tokens}
+	filter, err := p.parseOr()
+	if err == nil && p.peek().kind != tokenEOF {
+		err = fmt.Errorf("unexpected token at %d", p.pos)
+	}
+	if err != nil {
+		return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidFilter, "%v", err)
+	}
+	return filter, nil
+}
+
+// ParsePath parses the path of a PATCH operation
+func ParsePath(s string) (*Path, error) {
+	tokens, err := tokenize(s)
+	if err != nil || len(tokens) == 0 || tokens[0].kind != tokenWord {
+		return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidPath, "invalid path %q", s)
+	}
+	path := &Path{}
+	p := &parser{tokens: tokens[1:]}
+	if p.peek().kind != tokenOpenBracket {
+		path.Attribute, path.SubAttribute, _ = strings.Cut(attributeName(tokens[0].value), ".")
+	} else {
+		path.Attribute = attributeName(tokens[0].value)
+		p.next()
+		if path.Filter, err = p.parseOr(); err != nil || p.next().kind != tokenCloseBracket {
+			return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidPath, "invalid path %q", s)
+		}
+		if t := p.peek(); t.kind == tokenWord && strings.HasPrefix(t.value, ".") {
+			path.SubAttribute = t.value[1:]
+			p.next()
+		}
+	}
+	if p.peek().kind != tokenEOF || path.Attribute == "" {
+		return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidPath, "invalid path %q", s)
+	}
+	return path, nil
+}
+
+// lookup returns the value of an attribute, the names of attributes are case insensitive
+func lookup(resource map[string]any, attribute string) (string, any, bool) {
+	if value, ok := resource[attribute]; ok {
+		return attribute, value, true
+	}
+	for key, value := range resource {
+		if strings.EqualFold(key, attribute) {
+			return key, value, true
+		}
+	}
+	return attribute, nil, false
+}
+
+// values returns the values of an attribute and sub attribute, multi-valued attributes are flattened
+func values(resource map[string]any, attribute, subAttribute string) []any {
+	_, value, ok := lookup(resource, attribute)
+	if !ok || value == nil {
+		return nil
+	}
+	elements, isMulti := value.([]any)
+	if !isMulti {
+		elements = []any{value}
+	}
+	result := make([]any, 0, len(elements))
+	for _, element := range elements {
+		object, isObject := element.(map[string]any)
+		switch {
+		case subAttribute != "" && isObject:
+			if _, v, ok := lookup(object, subAttribute); ok && v != nil {
+				result = append(result, v)
+			}
+		case subAttribute == "" && isObject && isMulti:
+			// the value of multi-valued attributes is compared by default
+			if _, v, ok := lookup(object, "value"); ok && v != nil {
+				result = append(result, v)
+			}
+		case subAttribute == "":
+			result = append(result, element)
+		}
+	}
+	return result
+}
+
+func compare(operator string, actual, expected any) bool {
+	switch expectedValue := expected.(type) {
+	case string:
+		actualValue, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		a, e := strings.ToLower(actualValue), strings.ToLower(expectedValue)
+		switch operator {
+		case OperatorEqual:
+			return a == e
+		case OperatorNotEqual:
+			return a != e
+		case OperatorContains:
+			return strings.Contains(a, e)
+		case OperatorStartsWith:
+			return strings.HasPrefix(a, e)
+		case OperatorEndsWith:
+			return strings.HasSuffix(a, e)
+		case OperatorGreater:
+			return a > e
+		case OperatorGreaterOrEqual:
+			return a >= e
+		case OperatorLess:
+			return a < e
+		case OperatorLessOrEqual:
+			return a <= e
+		}
+	case float64:
+		actualValue, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		switch operator {
+		case OperatorEqual:
+			return actualValue == expectedValue
+		case OperatorNotEqual:
+			return actualValue != expectedValue
+		case OperatorGreater:
+			return actualValue > expectedValue
+		case OperatorGreaterOrEqual:
+			return actualValue >= expectedValue
+		case OperatorLess:
+			return actualValue < expectedValue
+		case OperatorLessOrEqual:
+			return actualValue <= expectedValue
+		}
+	case bool:
+		actualValue, ok := actual.(bool)
+		if !ok {
+			return false
+		}
+		switch operator {
+		case OperatorEqual:
+			return actualValue == expectedValue
+		case OperatorNotEqual:
+			return actualValue != expectedValue
+		}
+	case nil:
+		switch operator {
+		case OperatorEqual:
+			return actual == nil
+		case OperatorNotEqual:
+			return actual != nil
+		}
+	}
+	return false
+}
+
+func (c *Comparison) Match(resource map[string]any) bool {
+	actual := values(resource, c.Attribute, c.SubAttribute)
+	if c.Operator == OperatorPresent {
+		for _, value := range actual {
+			if s, ok := value.(string); !ok || s != "" {
+				return true
+			}
+		}
+		return false
+	}
+	if len(actual) == 0 {
+		return compare(c.Operator, nil, c.Value)
+	}
+	if c.Operator == OperatorNotEqual {
+		for _, value := range actual {
+			if !compare(c.Operator, value, c.Value) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, value := range actual {
+		if compare(c.Operator, value, c.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Logical) Match(resource map[string]any) bool {
+	if l.Operator == "or" {
+		return l.Left.Match(resource) || l.Right.Match(resource)
+	}
+	return l.Left.Match(resource) && l.Right.Match(resource)
+}
+
+func (n *Not) Match(resource map[string]any) bool {
+	return !n.Filter.Match(resource)
+}
+
+func (v *ValuePath) Match(resource map[string]any) bool {
+	_, value, _ := lookup(resource, v.Attribute)
+	elements, _ := value.([]any)
+	for _, element := range elements {
+		if object, ok := element.(map[string]any); ok && v.Filter.Match(object) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToMap returns the JSON representation of a resource, which filters and patches operate on
+func ToMap(resource any) (map[string]any, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]any{}
+	return result, json.Unmarshal(data, &result)
+}
+
+// FromMap decodes the JSON representation of a resource
+func FromMap(m map[string]any, resource any) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, resource); err != nil {
+		return NewError(http.StatusBadRequest, ErrorTypeInvalidValue, "%v", err)
+	}
+	return nil
+}
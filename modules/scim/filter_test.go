@@ -0,0 +1,92 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	filter, err := ParseFilter(`userName eq "bjensen"`)
+	require.NoError(t, err)
+	assert.Equal(t, &Comparison{Attribute: "userName", Operator: OperatorEqual, Value: "bjensen"}, filter)
+
+	filter, err = ParseFilter(`urn:ietf:params:scim:schemas:core:2.0:User:name.familyName co "O'Malley"`)
+	require.NoError(t, err)
+	assert.Equal(t, &Comparison{Attribute: "name", SubAttribute: "familyName", Operator: OperatorContains, Value: "O'Malley"}, filter)
+
+	filter, err = ParseFilter(`active eq true and (emails.value ew "@example.com" or not (title pr))`)
+	require.NoError(t, err)
+	assert.Equal(t, &Logical{
+		Operator: "and",
+		Left:     &Comparison{Attribute: "active", Operator: OperatorEqual, Value: true},
+		Right: &Logical{
+			Operator: "or",
+			Left:     &Comparison{Attribute: "emails", SubAttribute: "value", Operator: OperatorEndsWith, Value: "@example.com"},
+			Right:    &Not{Filter: &Comparison{Attribute: "title", Operator: OperatorPresent}},
+		},
+	}, filter)
+
+	for _, invalid := range []string{``, `userName`, `userName eq`, `userName foo "x"`, `(userName eq "x"`, `userName eq "x`, `emails[type eq "work"`} {
+		_, err := ParseFilter(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	resource := map[string]any{
+		"userName": "BJensen",
+		"active":   true,
+		"name":     map[string]any{"givenName": "Barbara", "familyName": "Jensen"},
+		"emails": []any{
+			map[string]any{"value": "bjensen@example.com", "type": "work", "primary": true},
+			map[string]any{"value": "babs@jensen.org", "type": "home"},
+		},
+	}
+
+	cases := map[string]bool{
+		`userName eq "bjensen"`:                                  true,
+		`username eq "bjensen"`:                                  true,
+		`userName ne "bjensen"`:                                  false,
+		`userName sw "bj"`:                                       true,
+		`name.givenName eq "Barbara" and active eq true`:         true,
+		`name.givenName eq "Barbara" and active eq false`:        false,
+		`emails co "jensen.org"`:                                 true,
+		`emails.value eq "babs@jensen.org"`:                      true,
+		`emails[type eq "work" and value co "@example.com"]`:     true,
+		`emails[type eq "home" and value co "@example.com"]`:     false,
+		`externalId pr`:                                          false,
+		`not (externalId pr)`:                                    true,
+		`externalId eq "x" or userName ew "sen"`:                 true,
+		`urn:ietf:params:scim:schemas:core:2.0:User:userName pr`: true,
+	}
+	for expr, expected := range cases {
+		filter, err := ParseFilter(expr)
+		require.NoError(t, err, expr)
+		assert.Equal(t, expected, filter.Match(resource), expr)
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	path, err := ParsePath("name.givenName")
+	require.NoError(t, err)
+	assert.Equal(t, &Path{Attribute: "name", SubAttribute: "givenName"}, path)
+
+	path, err = ParsePath(`members[value eq "2"]`)
+	require.NoError(t, err)
+	assert.Equal(t, &Path{Attribute: "members", Filter: &Comparison{Attribute: "value", Operator: OperatorEqual, Value: "2"}}, path)
+
+	path, err = ParsePath(`emails[type eq "work"].value`)
+	require.NoError(t, err)
+	assert.Equal(t, "emails", path.Attribute)
+	assert.Equal(t, "value", path.SubAttribute)
+
+	for _, invalid := range []string{``, `[value eq "2"]`, `members[value eq "2"`, `members eq`} {
+		_, err := ParsePath(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
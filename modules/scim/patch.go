@@ -0,0 +1,168 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Patch operations
+const (
+	PatchOpAdd     = "add"
+	PatchOpReplace = "replace"
+	PatchOpRemove  = "remove"
+)
+
+func asList(value any) []any {
+	if list, ok := value.([]any); ok {
+		return list
+	}
+	return []any{value}
+}
+
+// valueOf returns the "value" sub attribute of an element of a multi-valued attribute.
+// Values are compared by their string representation, as some clients send ids as numbers.
+func valueOf(element any) any {
+	if object, ok := element.(map[string]any); ok {
+		_, value, _ := lookup(object, "value")
+		return value
+	}
+	return element
+}
+
+func removeValues(list, values []any) []any {
+	result := make([]any, 0, len(list))
+	for _, element := range list {
+		removed := false
+		for _, value := range values {
+			if fmt.Sprint(valueOf(element)) == fmt.Sprint(valueOf(value)) {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			result = append(result, element)
+		}
+	}
+	return result
+}
+
+func setValue(resource map[string]any, attribute string, value any, add bool) {
+	key, existing, _ := lookup(resource, attribute)
+	switch existingValue := existing.(type) {
+	case []any:
+		if add {
+			resource[key] = append(existingValue, asList(value)...)
+			return
+		}
+		resource[key] = asList(value)
+	case map[string]any:
+		if object, ok := value.(map[string]any); ok {
+			for k, v := range object {
+				setValue(existingValue, k, v, add)
+			}
+			return
+		}
+		resource[key] = value
+	default:
+		resource[key] = value
+	}
+}
+
+// ApplyPatch applies a PATCH operation to the JSON representation of a resource, see RFC 7644 section 3.5.2
+func ApplyPatch(resource map[string]any, operation PatchOperation) error {
+	op := strings.ToLower(operation.Op)
+	if op != PatchOpAdd && op != PatchOpReplace && op != PatchOpRemove {
+		return NewError(http.StatusBadRequest, ErrorTypeInvalidSyntax, "unknown operation %q", operation.Op)
+	}
+
+	if operation.Path == "" {
+		if op == PatchOpRemove {
+			return NewError(http.StatusBadRequest, ErrorTypeNoTarget, "remove requires a path")
+		}
+		object, ok := operation.Value.(map[string]any)
+		if !ok {
+			return NewError(http.StatusBadRequest, ErrorTypeInvalidValue, "value must be an object if no path is given")
+		}
+		for key, value := range object {
+			if err := ApplyPatch(resource, PatchOperation{Op: op, Path: key, Value: value}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path, err := ParsePath(operation.Path)
+	if err != nil {
+		return err
+	}
+	key, existing, exists := lookup(resource, path.Attribute)
+
+	if path.Filter == nil {
+		switch {
+		case path.SubAttribute == "" && op == PatchOpRemove:
+			if list, ok := existing.([]any); ok && operation.Value != nil {
+				resource[key] = removeValues(list, asList(operation.Value))
+			} else {
+				delete(resource, key)
+			}
+		case path.SubAttribute == "":
+			setValue(resource, path.Attribute, operation.Value, op == PatchOpAdd)
+		default:
+			object, ok := existing.(map[string]any)
+			if !exists || existing == nil {
+				if op == PatchOpRemove {
+					return nil
+				}
+				object, ok = map[string]any{}, true
+				resource[key] = object
+			}
+			if !ok {
+				return NewError(http.StatusBadRequest, ErrorTypeInvalidPath, "%s is not a complex attribute", path.Attribute)
+			}
+			if op == PatchOpRemove {
+				subKey, _, _ := lookup(object, path.SubAttribute)
+				delete(object, subKey)
+			} else {
+				setValue(object, path.SubAttribute, operation.Value, op == PatchOpAdd)
+			}
+		}
+		return nil
+	}
+
+	list, _ := existing.([]any)
+	matched := 0
+	result := make([]any, 0, len(list))
+	for _, element := range list {
+		object, ok := element.(map[string]any)
+		if !ok || !path.Filter.Match(object) {
+			result = append(result, element)
+			continue
+		}
+		matched++
+		switch {
+		case op == PatchOpRemove && path.SubAttribute == "":
+			continue
+		case op == PatchOpRemove:
+			subKey, _, _ := lookup(object, path.SubAttribute)
+			delete(object, subKey)
+		case path.SubAttribute == "":
+			if value, ok := operation.Value.(map[string]any); ok {
+				for k, v := range value {
+					setValue(object, k, v, false)
+				}
+			}
+		default:
+			setValue(object, path.SubAttribute, operation.Value, false)
+		}
+		result = append(result, element)
+	}
+	if matched == 0 && op != PatchOpAdd {
+		return NewError(http.StatusBadRequest, ErrorTypeNoTarget, "no values of %s match the filter", path.Attribute)
+	}
+	resource[key] = result
+	return nil
+}
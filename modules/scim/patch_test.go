@@ -0,0 +1,79 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch(t *testing.T) {
+	newResource := func() map[string]any {
+		return map[string]any{
+			"displayName": "Barbara Jensen",
+			"active":      true,
+			"name":        map[string]any{"givenName": "Barbara"},
+			"emails": []any{
+				map[string]any{"value": "bjensen@example.com", "type": "work"},
+			},
+			"members": []any{
+				map[string]any{"value": "1"},
+				map[string]any{"value": "2"},
+			},
+		}
+	}
+
+	t.Run("ReplaceWithoutPath", func(t *testing.T) {
+		resource := newResource()
+		require.NoError(t, ApplyPatch(resource, PatchOperation{Op: "Replace", Value: map[string]any{"active": false, "name.familyName": "Jensen"}}))
+		assert.Equal(t, false, resource["active"])
+		assert.Equal(t, map[string]any{"givenName": "Barbara", "familyName": "Jensen"}, resource["name"])
+	})
+
+	t.Run("ReplaceSubAttribute", func(t *testing.T) {
+		resource := newResource()
+		require.NoError(t, ApplyPatch(resource, PatchOperation{Op: PatchOpReplace, Path: "name.givenName", Value: "Babs"}))
+		assert.Equal(t, map[string]any{"givenName": "Babs"}, resource["name"])
+	})
+
+	t.Run("ReplaceFiltered", func(t *testing.T) {
+		resource := newResource()
+		require.NoError(t, ApplyPatch(resource, PatchOperation{Op: PatchOpReplace, Path: `emails[type eq "work"].value`, Value: "babs@example.com"}))
+		assert.Equal(t, []any{map[string]any{"value": "babs@example.com", "type": "work"}}, resource["emails"])
+
+		err := ApplyPatch(resource, PatchOperation{Op: PatchOpReplace, Path: `emails[type eq "home"].value`, Value: "babs@example.com"})
+		require.Error(t, err)
+		assert.Equal(t, ErrorTypeNoTarget, err.(*Error).ScimType)
+	})
+
+	t.Run("AddMembers", func(t *testing.T) {
+		resource := newResource()
+		require.NoError(t, ApplyPatch(resource, PatchOperation{Op: PatchOpAdd, Path: "members", Value: []any{map[string]any{"value": "3"}}}))
+		assert.Len(t, resource["members"], 3)
+	})
+
+	t.Run("RemoveMembers", func(t *testing.T) {
+		resource := newResource()
+		require.NoError(t, ApplyPatch(resource, PatchOperation{Op: PatchOpRemove, Path: `members[value eq "1"]`}))
+		assert.Equal(t, []any{map[string]any{"value": "2"}}, resource["members"])
+
+		resource = newResource()
+		require.NoError(t, ApplyPatch(resource, PatchOperation{Op: PatchOpRemove, Path: "members", Value: []any{map[string]any{"value": 2}}}))
+		assert.Equal(t, []any{map[string]any{"value": "1"}}, resource["members"])
+
+		resource = newResource()
+		require.NoError(t, ApplyPatch(resource, PatchOperation{Op: PatchOpRemove, Path: "members"}))
+		assert.NotContains(t, resource, "members")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		resource := newResource()
+		require.Error(t, ApplyPatch(resource, PatchOperation{Op: "move", Path: "active"}))
+		require.Error(t, ApplyPatch(resource, PatchOperation{Op: PatchOpRemove}))
+		require.Error(t, ApplyPatch(resource, PatchOperation{Op: PatchOpReplace, Value: "x"}))
+		require.Error(t, ApplyPatch(resource, PatchOperation{Op: PatchOpReplace, Path: "displayName.formatted", Value: "x"}))
+	})
+}
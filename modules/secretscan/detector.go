@@ -0,0 +1,136 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+// Package secretscan finds secrets like cloud credentials, tokens and private keys in file contents.
+package secretscan
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Detector finds one kind of secret.
+// If its pattern has a capture group, the group is the secret, otherwise the whole match is.
+type Detector struct {
+	ID          string
+	Description string
+	Pattern     *regexp.Regexp
+	// MinEntropy is the minimal Shannon entropy in bits per character of the secret,
+	// it filters out placeholders and other values which are not random enough to be secrets
+	MinEntropy float64
+}
+
+// NewDetector compiles the pattern of a detector
+func NewDetector(id, description, pattern string, minEntropy float64) (*Detector, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &Detector{
+		ID:          id,
+		Description: description,
+		Pattern:     re,
+		MinEntropy:  minEntropy,
+	}, nil
+}
+
+// AccessTokenDetector finds Forgejo access tokens, their checksum is verified when they are looked up
+var AccessTokenDetector = &Detector{
+	ID:          "forgejo_access_token",
+	Description: "Forgejo access token",
+	Pattern:     regexp.MustCompile(`\b(gtp_[0-9a-f]{48})\b`),
+}
+
+// DefaultDetectors are the detectors of the secrets which are pushed the most
+var DefaultDetectors = []*Detector{
+	{
+		ID:          "aws_access_key_id",
+		Description: "AWS access key ID",
+		Pattern:     regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`),
+		MinEntropy:  3,
+	},
+	{
+		ID:          "aws_secret_access_key",
+		Description: "AWS secret access key",
+		Pattern:     regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key\W{0,4}([A-Za-z0-9/+]{40})\b`),
+		MinEntropy:  4,
+	},
+	AccessTokenDetector,
+	{
+		ID:          "forgejo_token",
+		Description: "Forgejo OAuth2 client secret or code",
+		Pattern:     regexp.MustCompile(`\b(gt[oad]_[a-z2-7]{52})\b`),
+	},
+	{
+		ID:          "private_key",
+		Description: "Private key",
+		Pattern:     regexp.MustCompile(`-----BEGIN (?:RSA |DSA |EC |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`),
+	},
+	{
+		ID:          "generic_secret",
+		Description: "Generic secret assignment",
+		Pattern:     regexp.MustCompile(`(?i)(?:secret|password|passwd|api_?key|access_?token|auth_?token)["']?\s*[:=]\s*["']([A-Za-z0-9/+_\-.=]{20,})["']`),
+		MinEntropy:  4.5,
+	},
+}
+
+// Match is a secret found in a content
+type Match struct {
+	Detector *Detector
+	// Line is the 1-based number of the line the secret starts on
+	Line   int
+	Secret string
+}
+
+// Scan returns the secrets found by the detectors in the content
+func Scan(content []byte, detectors []*Detector) []*Match {
+	var matches []*Match
+	for _, d := range detectors {
+		for _, loc := range d.Pattern.FindAllSubmatchIndex(content, -1) {
+			start, end := loc[0], loc[1]
+			if len(loc) >= 4 && loc[2] >= 0 {
+				start, end = loc[2], loc[3]
+			}
+			secret := string(content[start:end])
+			if d.MinEntropy > 0 && Entropy(secret) < d.MinEntropy {
+				continue
+			}
+			matches = append(matches, &Match{
+				Detector: d,
+				Line:     bytes.Count(content[:start], []byte{'\n'}) + 1,
+				Secret:   secret,
+			})
+		}
+	}
+	return matches
+}
+
+// Entropy returns the Shannon entropy of the string in bits per character
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// Redact hides the secret but its first and last characters so that it can be recognized without being leaked
+func Redact(secret string) string {
+	visible := len(secret) / 8
+	if visible > 4 {
+		visible = 4
+	}
+	return secret[:visible] + strings.Repeat("*", len(secret)-2*visible) + secret[len(secret)-visible:]
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"net/url"
+
+	"forgejo.org/modules/log"
+)
+
+// AuditSink is a destination the audit events are streamed to as they are recorded
+type AuditSink struct {
+	Name string
+	// Mode is file, syslog or http
+	Mode string
+
+	// File is the option of the file the events are written to as JSON lines
+	File log.WriterFileOption
+
+	// Conn is the option of the connection to the syslog server
+	Conn log.WriterConnOption
+	// Facility is the syslog facility of the messages, 13 is "log audit"
+	Facility int
+
+	// URL is the endpoint the events are posted to, the body is signed with the secret
+	URL    string
+	Secret string
+}
+
+// Audit settings
+var Audit = struct {
+	Enabled       bool        `ini:"ENABLED"`
+	RetentionDays int64       `ini:"RETENTION_DAYS"`
+	Sinks         []AuditSink `ini:"-"`
+}{
+	Enabled:       false,
+	RetentionDays: 365,
+}
+
+func loadAuditFrom(rootCfg ConfigProvider) {
+	mustMapSetting(rootCfg, "audit", &Audit)
+
+	Audit.Sinks = nil
+	for _, name := range rootCfg.Section("audit").Key("SINKS").Strings(",") {
+		Audit.Sinks = append(Audit.Sinks, loadAuditSink(rootCfg, name))
+	}
+}
+
+func loadAuditSink(rootCfg ConfigProvider, name string) AuditSink {
+	sec := rootCfg.Section("audit.sink." + name)
+	sink := AuditSink{
+		Name: name,
+		Mode: sec.Key("MODE").String(),
+	}
+	switch sink.Mode {
+	case "file":
+		// the options are the same as the ones of the file log writer
+		sink.File.FileName = LogPrepareFilenameForWriter(sec.Key("FILE_NAME").String(), "audit.log")
+		sink.File.LogRotate = sec.Key("LOG_ROTATE").MustBool(true)
+		sink.File.MaxSize = 1 << uint(sec.Key("MAX_SIZE_SHIFT").MustInt(28))
+		sink.File.DailyRotate = sec.Key("DAILY_ROTATE").MustBool(true)
+		sink.File.MaxDays = sec.Key("MAX_DAYS").MustInt(7)
+		sink.File.Compress = sec.Key("COMPRESS").MustBool(true)
+		sink.File.CompressionLevel = sec.Key("COMPRESSION_LEVEL").MustInt(-1)
+	case "syslog":
+		sink.Conn.Protocol = sec.Key("PROTOCOL").In("udp", []string{"tcp", "udp"})
+		sink.Conn.Addr = sec.Key("ADDR").MustString("localhost:514")
+		sink.Facility = sec.Key("FACILITY").MustInt(13)
+		if sink.Facility < 0 || sink.Facility > 23 {
+			log.Fatal("Invalid syslog FACILITY %d of audit sink %q, it must be between 0 and 23", sink.Facility, name)
+		}
+	case "http":
+		sink.URL = sec.Key("URL").String()
+		if u, err := url.Parse(sink.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			log.Fatal("Invalid URL %q of audit sink %q", sink.URL, name)
+		}
+		sink.Secret = loadSecret(sec, "SECRET_URI", "SECRET")
+	default:
+		log.Fatal("Invalid MODE %q of audit sink %q, it must be one of: file, syslog, http", sink.Mode, name)
+	}
+	return sink
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"path/filepath"
+	"testing"
+
+	"forgejo.org/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAuditSinks(t *testing.T) {
+	defer test.MockVariableValue(&Log.RootPath, t.TempDir())()
+	defer test.MockProtect(&Audit)()
+
+	cfg, err := NewConfigProviderFromData(`
+[audit]
+ENABLED = true
+SINKS = archive, siem, endpoint
+
+[audit.sink.archive]
+MODE = file
+MAX_DAYS = 30
+
+[audit.sink.siem]
+MODE = syslog
+PROTOCOL = tcp
+ADDR = siem.example.com:6514
+
+[audit.sink.endpoint]
+MODE = http
+URL = https://siem.example.com/audit
+SECRET = s3cr3t
+`)
+	require.NoError(t, err)
+	loadAuditFrom(cfg)
+
+	require.Len(t, Audit.Sinks, 3)
+
+	archive := Audit.Sinks[0]
+	assert.Equal(t, "archive", archive.Name)
+	assert.Equal(t, "file", archive.Mode)
+	assert.Equal(t, filepath.Join(Log.RootPath, "audit.log"), archive.File.FileName)
+	assert.Equal(t, 30, archive.File.MaxDays)
+	assert.True(t, archive.File.LogRotate)
+
+	siem := Audit.Sinks[1]
+	assert.Equal(t, "syslog", siem.Mode)
+	assert.Equal(t, "tcp", siem.Conn.Protocol)
+	assert.Equal(t, "siem.example.com:6514", siem.Conn.Addr)
+	assert.Equal(t, 13, siem.Facility)
+
+	endpoint := Audit.Sinks[2]
+	assert.Equal(t, "http", endpoint.Mode)
+	assert.Equal(t, "https://siem.example.com/audit", endpoint.URL)
+	assert.Equal(t, "s3cr3t", endpoint.Secret)
+}
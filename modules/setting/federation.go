@@ -20,6 +20,8 @@ var (
 		GetHeaders          []string
 		PostHeaders         []string
 		SignatureEnforced   bool
+		HostPolicy          string
+		QuarantineNewHosts  bool
 	}{
 		Enabled:             false,
 		ShareUserStatistics: true,
@@ -29,9 +31,19 @@ var (
 		GetHeaders:          []string{"(request-target)", "Date", "Host"},
 		PostHeaders:         []string{"(request-target)", "Date", "Host", "Digest"},
 		SignatureEnforced:   true,
+		HostPolicy:          FederationHostPolicyBlocklist,
+		QuarantineNewHosts:  false,
 	}
 )
 
+// Federation host policies
+const (
+	// FederationHostPolicyBlocklist federates with all hosts which are not denied
+	FederationHostPolicyBlocklist = "blocklist"
+	// FederationHostPolicyAllowlist only federates with allowed hosts
+	FederationHostPolicyAllowlist = "allowlist"
+)
+
 // HttpsigAlgs is a constant slice of httpsig algorithm objects
 var HttpsigAlgs []httpsig.Algorithm
 
@@ -43,6 +55,11 @@ func loadFederationFrom(rootCfg ConfigProvider) {
 		return
 	}
 
+	if Federation.HostPolicy != FederationHostPolicyBlocklist && Federation.HostPolicy != FederationHostPolicyAllowlist {
+		log.Fatal("unsupported federation host policy: %s", Federation.HostPolicy)
+		return
+	}
+
 	// Get MaxSize in bytes instead of MiB
 	Federation.MaxSize = 1 << 20 * Federation.MaxSize
 
@@ -33,9 +33,11 @@ var (
 		LimitSizeContainer    int64
 		LimitSizeCran         int64
 		LimitSizeDebian       int64
+		LimitSizeGalaxy       int64
 		LimitSizeGeneric      int64
 		LimitSizeGo           int64
 		LimitSizeHelm         int64
+		LimitSizeHex          int64
 		LimitSizeMaven        int64
 		LimitSizeNpm          int64
 		LimitSizeNuGet        int64
@@ -94,9 +96,11 @@ func loadPackagesFrom(rootCfg ConfigProvider) (err error) {
 	Packages.LimitSizeContainer = mustBytes(sec, "LIMIT_SIZE_CONTAINER")
 	Packages.LimitSizeCran = mustBytes(sec, "LIMIT_SIZE_CRAN")
 	Packages.LimitSizeDebian = mustBytes(sec, "LIMIT_SIZE_DEBIAN")
+	Packages.LimitSizeGalaxy = mustBytes(sec, "LIMIT_SIZE_GALAXY")
 	Packages.LimitSizeGeneric = mustBytes(sec, "LIMIT_SIZE_GENERIC")
 	Packages.LimitSizeGo = mustBytes(sec, "LIMIT_SIZE_GO")
 	Packages.LimitSizeHelm = mustBytes(sec, "LIMIT_SIZE_HELM")
+	Packages.LimitSizeHex = mustBytes(sec, "LIMIT_SIZE_HEX")
 	Packages.LimitSizeMaven = mustBytes(sec, "LIMIT_SIZE_MAVEN")
 	Packages.LimitSizeNpm = mustBytes(sec, "LIMIT_SIZE_NPM")
 	Packages.LimitSizeNuGet = mustBytes(sec, "LIMIT_SIZE_NUGET")
@@ -0,0 +1,19 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+// SCIM settings
+var SCIM = struct {
+	Enabled           bool `ini:"ENABLED"`
+	MaxBulkOperations int  `ini:"MAX_BULK_OPERATIONS"`
+	MaxResults        int  `ini:"MAX_RESULTS"`
+}{
+	Enabled:           false,
+	MaxBulkOperations: 100,
+	MaxResults:        200,
+}
+
+func loadSCIMFrom(rootCfg ConfigProvider) {
+	mustMapSetting(rootCfg, "scim", &SCIM)
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"forgejo.org/modules/log"
+)
+
+// SecretScanningDetector is a detector configured in addition to the default ones
+type SecretScanningDetector struct {
+	ID          string
+	Description string
+	Pattern     string
+	MinEntropy  float64
+}
+
+// SecretScanning settings
+var SecretScanning = struct {
+	Enabled           bool                     `ini:"ENABLED"`
+	PushProtection    bool                     `ini:"PUSH_PROTECTION"`
+	MaxFileSize       int64                    `ini:"MAX_FILE_SIZE"`
+	DisabledDetectors []string                 `ini:"DISABLED_DETECTORS"`
+	Detectors         []SecretScanningDetector `ini:"-"`
+	// RevokeLeakedTokens revokes the access tokens found in public content, even if secret scanning is disabled
+	RevokeLeakedTokens bool `ini:"REVOKE_LEAKED_TOKENS"`
+}{
+	Enabled:            false,
+	PushProtection:     true,
+	MaxFileSize:        1 << 20,
+	RevokeLeakedTokens: true,
+}
+
+func loadSecretScanningFrom(rootCfg ConfigProvider) {
+	mustMapSetting(rootCfg, "secret_scanning", &SecretScanning)
+
+	SecretScanning.Detectors = nil
+	for _, sec := range rootCfg.Section("secret_scanning").ChildSections() {
+		id, ok := strings.CutPrefix(sec.Name(), "secret_scanning.detector.")
+		if !ok {
+			continue
+		}
+		d := SecretScanningDetector{
+			ID:          id,
+			Description: sec.Key("DESCRIPTION").MustString(id),
+			Pattern:     sec.Key("PATTERN").String(),
+		}
+		if v := sec.Key("MIN_ENTROPY").String(); v != "" {
+			var err error
+			if d.MinEntropy, err = strconv.ParseFloat(v, 64); err != nil {
+				log.Fatal("Invalid MIN_ENTROPY of secret scanning detector %q: %v", id, err)
+			}
+		}
+		if _, err := regexp.Compile(d.Pattern); err != nil || d.Pattern == "" {
+			log.Fatal("Invalid PATTERN of secret scanning detector %q: %v", id, err)
+		}
+		SecretScanning.Detectors = append(SecretScanning.Detectors, d)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"testing"
+
+	"forgejo.org/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSecretScanning(t *testing.T) {
+	defer test.MockProtect(&SecretScanning)()
+
+	cfg, err := NewConfigProviderFromData(`
+[secret_scanning]
+ENABLED = true
+DISABLED_DETECTORS = generic_secret
+
+[secret_scanning.detector.slack_token]
+DESCRIPTION = Slack token
+PATTERN = \b(xox[abpr]-[0-9A-Za-z-]{10,})\b
+MIN_ENTROPY = 3.5
+
+[secret_scanning.detector.internal]
+PATTERN = internal-[0-9a-f]{32}
+`)
+	require.NoError(t, err)
+	loadSecretScanningFrom(cfg)
+
+	assert.True(t, SecretScanning.Enabled)
+	assert.True(t, SecretScanning.PushProtection)
+	assert.True(t, SecretScanning.RevokeLeakedTokens)
+	assert.EqualValues(t, 1<<20, SecretScanning.MaxFileSize)
+	assert.Equal(t, []string{"generic_secret"}, SecretScanning.DisabledDetectors)
+	assert.ElementsMatch(t, []SecretScanningDetector{
+		{ID: "slack_token", Description: "Slack token", Pattern: `\b(xox[abpr]-[0-9A-Za-z-]{10,})\b`, MinEntropy: 3.5},
+		{ID: "internal", Description: "internal", Pattern: `internal-[0-9a-f]{32}`},
+	}, SecretScanning.Detectors)
+}
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"forgejo.org/modules/auth/password/hash"
 	"forgejo.org/modules/generate"
@@ -34,6 +35,9 @@ var (
 	PasswordComplexity                 []string
 	PasswordHashAlgo                   string
 	PasswordCheckPwn                   bool
+	RequirePasskeyForAdmins            bool
+	RequireTwoFactor                   bool
+	TwoFactorGracePeriod               time.Duration
 	SuccessfulTokensCacheSize          int
 	CSRFCookieName                     = "_csrf"
 	CSRFCookieHTTPOnly                 = true
@@ -139,6 +143,9 @@ func loadSecurityFrom(rootCfg ConfigProvider) {
 
 	CSRFCookieHTTPOnly = sec.Key("CSRF_COOKIE_HTTP_ONLY").MustBool(true)
 	PasswordCheckPwn = sec.Key("PASSWORD_CHECK_PWN").MustBool(false)
+	RequirePasskeyForAdmins = sec.Key("REQUIRE_PASSKEY_FOR_ADMINS").MustBool(false)
+	RequireTwoFactor = sec.Key("REQUIRE_TWO_FACTOR").MustBool(false)
+	TwoFactorGracePeriod = sec.Key("TWO_FACTOR_GRACE_PERIOD").MustDuration(72 * time.Hour)
 	SuccessfulTokensCacheSize = sec.Key("SUCCESSFUL_TOKENS_CACHE_SIZE").MustInt(20)
 
 	InternalToken = loadSecret(sec, "INTERNAL_TOKEN_URI", "INTERNAL_TOKEN")
@@ -142,6 +142,8 @@ func loadCommonSettingsFrom(cfg ConfigProvider) error {
 	}
 	loadUIFrom(cfg)
 	loadAdminFrom(cfg)
+	loadAuditFrom(cfg)
+	loadSecretScanningFrom(cfg)
 	loadAPIFrom(cfg)
 	loadBadgesFrom(cfg)
 	loadMetricsFrom(cfg)
@@ -222,6 +224,7 @@ func LoadSettings() {
 	loadMimeTypeMapFrom(CfgProvider)
 	loadF3From(CfgProvider)
 	loadModerationFrom(CfgProvider)
+	loadSCIMFrom(CfgProvider)
 }
 
 // LoadSettingsForInstall initializes the settings for install
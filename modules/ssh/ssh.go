@@ -11,6 +11,7 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
+	"fmt"
 	"io"
 	"net"
 	"os"
@@ -22,6 +23,7 @@ import (
 	"syscall"
 
 	asymkey_model "forgejo.org/models/asymkey"
+	user_model "forgejo.org/models/user"
 	"forgejo.org/modules/graceful"
 	"forgejo.org/modules/log"
 	"forgejo.org/modules/process"
@@ -57,13 +59,23 @@ func getExitStatusFromError(err error) int {
 }
 
 func sessionHandler(session ssh.Session) {
-	keyID := session.ConnPermissions().Extensions["forgejo-key-id"]
+	extensions := session.ConnPermissions().Extensions
+	identity := "key-" + extensions["forgejo-key-id"]
+	// the certificate is identified in the audit log by its key ID and serial
+	var certEnv []string
+	if caID, ok := extensions["forgejo-certificate-authority-id"]; ok {
+		identity = "cert-" + caID + "-" + extensions["forgejo-user-id"]
+		certEnv = []string{
+			"FORGEJO_SSH_CERTIFICATE_KEY_ID=" + extensions["forgejo-certificate-key-id"],
+			"FORGEJO_SSH_CERTIFICATE_SERIAL=" + extensions["forgejo-certificate-serial"],
+		}
+	}
 
 	command := session.RawCommand()
 
 	log.Trace("SSH: Payload: %v", command)
 
-	args := []string{"--config=" + setting.CustomConf, "serv", "key-" + keyID}
+	args := []string{"--config=" + setting.CustomConf, "serv", identity}
 	log.Trace("SSH: Arguments: %v", args)
 
 	ctx, cancel := context.WithCancel(session.Context())
@@ -83,7 +95,9 @@ func sessionHandler(session ssh.Session) {
 		"SSH_ORIGINAL_COMMAND="+command,
 		"SKIP_MINWINSVC=1",
 		"GIT_PROTOCOL="+gitProtocol,
+		"SSH_CONNECTION="+sshConnection(session.RemoteAddr(), session.LocalAddr()),
 	)
+	cmd.Env = append(cmd.Env, certEnv...)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -175,70 +189,105 @@ func publicKeyHandler(ctx ssh.Context, key ssh.PublicKey) bool {
 			log.Debug("Handle Certificate: %s Fingerprint: %s is a certificate", ctx.RemoteAddr(), gossh.FingerprintSHA256(key))
 		}
 
-		if len(setting.SSH.TrustedUserCAKeys) == 0 {
-			log.Warn("Certificate Rejected: No trusted certificate authorities for this server")
+		if cert.CertType != gossh.UserCert {
+			log.Warn("Certificate Rejected: Not a user certificate")
 			log.Warn("Failed authentication attempt from %s", ctx.RemoteAddr())
 			return false
 		}
 
-		if cert.CertType != gossh.UserCert {
-			log.Warn("Certificate Rejected: Not a user certificate")
+		if len(cert.ValidPrincipals) == 0 {
+			log.Warn("Certificate Rejected: No principals")
 			log.Warn("Failed authentication attempt from %s", ctx.RemoteAddr())
 			return false
 		}
 
+		// certificate authorities are trusted in the configuration or by the administrators,
+		// only the latter may map certificates to users
+		ca, err := asymkey_model.GetSSHCertificateAuthorityByFingerprint(ctx, gossh.FingerprintSHA256(cert.SignatureKey))
+		if err != nil && !asymkey_model.IsErrSSHCertificateAuthorityNotExist(err) {
+			log.Error("GetSSHCertificateAuthorityByFingerprint: %v", err)
+			return false
+		}
+		c := &gossh.CertChecker{
+			IsUserAuthority: func(auth gossh.PublicKey) bool {
+				if ca != nil {
+					return true
+				}
+				marshaled := auth.Marshal()
+				for _, k := range setting.SSH.TrustedUserCAKeysParsed {
+					if bytes.Equal(marshaled, k.Marshal()) {
+						return true
+					}
+				}
+
+				return false
+			},
+			// source-address is enforced below, any other critical option is rejected
+			SupportedCriticalOptions: []string{sourceAddressCriticalOption},
+		}
+
+		// check the CA of the cert
+		if !c.IsUserAuthority(cert.SignatureKey) {
+			log.Warn("Certificate Rejected: Untrusted Authority Signature Fingerprint %s", gossh.FingerprintSHA256(cert.SignatureKey))
+			log.Warn("Failed authentication attempt from %s", ctx.RemoteAddr())
+			return false
+		}
+
+		// validate the signature, validity period and critical options of the cert,
+		// the principals are matched below
+		if err := c.CheckCert(cert.ValidPrincipals[0], cert); err != nil {
+			// User is presenting an invalid certificate - STOP any further processing
+			log.Error("Invalid Certificate KeyID %s with Signature Fingerprint %s presented from %s: %v", cert.KeyId, gossh.FingerprintSHA256(cert.SignatureKey), ctx.RemoteAddr(), err)
+			log.Warn("Failed authentication attempt from %s", ctx.RemoteAddr())
+			return false
+		}
+		if sourceAddress, ok := cert.CriticalOptions[sourceAddressCriticalOption]; ok {
+			if err := checkSourceAddress(ctx.RemoteAddr(), sourceAddress); err != nil {
+				log.Warn("Certificate Rejected: KeyID %s %v", cert.KeyId, err)
+				log.Warn("Failed authentication attempt from %s", ctx.RemoteAddr())
+				return false
+			}
+		}
+		if ca != nil {
+			if err := ca.CheckValidityPeriod(cert); err != nil {
+				log.Warn("Certificate Rejected: KeyID %s %v", cert.KeyId, err)
+				log.Warn("Failed authentication attempt from %s", ctx.RemoteAddr())
+				return false
+			}
+		}
+
 		// look for the exact principal
-	principalLoop:
 		for _, principal := range cert.ValidPrincipals {
 			pkey, err := asymkey_model.SearchPublicKeyByContentExact(ctx, principal)
 			if err != nil {
 				if asymkey_model.IsErrKeyNotExist(err) {
 					log.Debug("Principal Rejected: %s Unknown Principal: %s", ctx.RemoteAddr(), principal)
-					continue principalLoop
+					continue
 				}
 				log.Error("SearchPublicKeyByContentExact: %v", err)
 				return false
 			}
 
-			c := &gossh.CertChecker{
-				IsUserAuthority: func(auth gossh.PublicKey) bool {
-					marshaled := auth.Marshal()
-					for _, k := range setting.SSH.TrustedUserCAKeysParsed {
-						if bytes.Equal(marshaled, k.Marshal()) {
-							return true
-						}
-					}
+			log.Info("SSH certificate KeyID %s serial %d signed by %s authenticated principal key %d of user %d for principal %s from %s",
+				cert.KeyId, cert.Serial, gossh.FingerprintSHA256(cert.SignatureKey), pkey.ID, pkey.OwnerID, principal, ctx.RemoteAddr())
+			setKeyIdentity(ctx, pkey.ID)
 
-					return false
-				},
-			}
-
-			// check the CA of the cert
-			if !c.IsUserAuthority(cert.SignatureKey) {
-				if log.IsDebug() {
-					log.Debug("Principal Rejected: %s Untrusted Authority Signature Fingerprint %s for Principal: %s", ctx.RemoteAddr(), gossh.FingerprintSHA256(cert.SignatureKey), principal)
-				}
-				continue principalLoop
-			}
-
-			// validate the cert for this principal
-			if err := c.CheckCert(principal, cert); err != nil {
-				// User is presenting an invalid certificate - STOP any further processing
-				log.Error("Invalid Certificate KeyID %s with Signature Fingerprint %s presented for Principal: %s from %s", cert.KeyId, gossh.FingerprintSHA256(cert.SignatureKey), principal, ctx.RemoteAddr())
-				log.Warn("Failed authentication attempt from %s", ctx.RemoteAddr())
+			return true
+		}
 
+		// otherwise map the certificate to a user if the authority allows it
+		if ca != nil && ca.Mapping != asymkey_model.SSHCertificateAuthorityMappingNone {
+			u, principal, err := ca.MapUser(ctx, cert)
+			if err == nil {
+				log.Info("SSH certificate KeyID %s serial %d signed by %s (%s) authenticated user %s mapped by %s %s from %s",
+					cert.KeyId, cert.Serial, ca.Name, ca.Fingerprint, u.Name, ca.Mapping, principal, ctx.RemoteAddr())
+				setCertificateIdentity(ctx, ca.ID, u.ID, cert)
+
+				return true
+			} else if !user_model.IsErrUserNotExist(err) {
+				log.Error("MapUser: %v", err)
 				return false
 			}
-
-			if log.IsDebug() { // <- FingerprintSHA256 is kinda expensive so only calculate it if necessary
-				log.Debug("Successfully authenticated: %s Certificate Fingerprint: %s Principal: %s", ctx.RemoteAddr(), gossh.FingerprintSHA256(key), principal)
-			}
-			if ctx.Permissions().Extensions == nil {
-				ctx.Permissions().Extensions = map[string]string{}
-			}
-			ctx.Permissions().Extensions["forgejo-key-id"] = strconv.FormatInt(pkey.ID, 10)
-
-			return true
 		}
 
 		log.Warn("From %s Fingerprint: %s is a certificate, but no valid principals found", ctx.RemoteAddr(), gossh.FingerprintSHA256(key))
@@ -264,14 +313,63 @@ func publicKeyHandler(ctx ssh.Context, key ssh.PublicKey) bool {
 	if log.IsDebug() { // <- FingerprintSHA256 is kinda expensive so only calculate it if necessary
 		log.Debug("Successfully authenticated: %s Public Key Fingerprint: %s", ctx.RemoteAddr(), gossh.FingerprintSHA256(key))
 	}
-	if ctx.Permissions().Extensions == nil {
-		ctx.Permissions().Extensions = map[string]string{}
-	}
-	ctx.Permissions().Extensions["forgejo-key-id"] = strconv.FormatInt(pkey.ID, 10)
+	setKeyIdentity(ctx, pkey.ID)
 
 	return true
 }
 
+// setKeyIdentity makes the session run as the public key, replacing the identity of any key checked before
+func setKeyIdentity(ctx ssh.Context, keyID int64) {
+	ctx.Permissions().Extensions = map[string]string{
+		"forgejo-key-id": strconv.FormatInt(keyID, 10),
+	}
+}
+
+// setCertificateIdentity makes the session run as the user a certificate was mapped to by the certificate authority,
+// replacing the identity of any key checked before
+func setCertificateIdentity(ctx ssh.Context, caID, userID int64, cert *gossh.Certificate) {
+	ctx.Permissions().Extensions = map[string]string{
+		"forgejo-certificate-authority-id": strconv.FormatInt(caID, 10),
+		"forgejo-user-id":                  strconv.FormatInt(userID, 10),
+		"forgejo-certificate-key-id":       cert.KeyId,
+		"forgejo-certificate-serial":       strconv.FormatUint(cert.Serial, 10),
+	}
+}
+
+const sourceAddressCriticalOption = "source-address"
+
+// sshConnection returns the value OpenSSH sets SSH_CONNECTION to for a session between the addresses
+func sshConnection(remoteAddr, localAddr net.Addr) string {
+	remoteHost, remotePort, _ := net.SplitHostPort(remoteAddr.String())
+	localHost, localPort, _ := net.SplitHostPort(localAddr.String())
+	return strings.Join([]string{remoteHost, remotePort, localHost, localPort}, " ")
+}
+
+// checkSourceAddress checks the remote address against the comma separated addresses and networks of the
+// source-address critical option of a certificate
+func checkSourceAddress(addr net.Addr, sourceAddress string) error {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return fmt.Errorf("remote address %s is not a TCP address", addr)
+	}
+	for _, allowed := range strings.Split(sourceAddress, ",") {
+		if ip := net.ParseIP(allowed); ip != nil {
+			if ip.Equal(tcpAddr.IP) {
+				return nil
+			}
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(allowed)
+		if err != nil {
+			return fmt.Errorf("invalid source-address %q: %w", allowed, err)
+		}
+		if ipNet.Contains(tcpAddr.IP) {
+			return nil
+		}
+	}
+	return fmt.Errorf("remote address %s is not allowed by source-address %s", tcpAddr.IP, sourceAddress)
+}
+
 // sshConnectionFailed logs a failed connection
 // -  this mainly exists to give a nice function name in logging
 func sshConnectionFailed(conn net.Conn, err error) {
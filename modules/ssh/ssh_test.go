@@ -0,0 +1,36 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package ssh
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSourceAddress(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.ParseIP("192.0.2.10"), Port: 2222}
+
+	require.NoError(t, checkSourceAddress(addr, "192.0.2.10"))
+	require.NoError(t, checkSourceAddress(addr, "198.51.100.1,192.0.2.0/24"))
+	require.NoError(t, checkSourceAddress(&net.TCPAddr{IP: net.ParseIP("2001:db8::1")}, "2001:db8::/32"))
+
+	require.Error(t, checkSourceAddress(addr, "192.0.2.11"))
+	require.Error(t, checkSourceAddress(addr, "198.51.100.0/24"))
+	assert.ErrorContains(t, checkSourceAddress(addr, "invalid"), "invalid source-address")
+	require.Error(t, checkSourceAddress(&net.UnixAddr{Name: "/tmp/socket"}, "192.0.2.10"))
+}
+
+func TestSSHConnection(t *testing.T) {
+	assert.Equal(t, "192.0.2.10 51234 198.51.100.1 22", sshConnection(
+		&net.TCPAddr{IP: net.ParseIP("192.0.2.10"), Port: 51234},
+		&net.TCPAddr{IP: net.ParseIP("198.51.100.1"), Port: 22},
+	))
+	assert.Equal(t, "2001:db8::1 51234 2001:db8::2 2222", sshConnection(
+		&net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 51234},
+		&net.TCPAddr{IP: net.ParseIP("2001:db8::2"), Port: 2222},
+	))
+}
@@ -0,0 +1,27 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package structs
+
+import "time"
+
+// AuditEvent represents a security relevant action recorded in the audit log
+type AuditEvent struct {
+	ID     int64  `json:"id"`
+	Action string `json:"action"`
+	// ActorID is 0 if the actor could not be authenticated
+	ActorID    int64  `json:"actor_id"`
+	ActorName  string `json:"actor_name"`
+	IPAddress  string `json:"ip_address"`
+	OwnerID    int64  `json:"owner_id"`
+	RepoID     int64  `json:"repo_id"`
+	TargetType string `json:"target_type"`
+	TargetID   int64  `json:"target_id"`
+	TargetName string `json:"target_name"`
+	// Before is the JSON encoded value before the action
+	Before string `json:"before"`
+	// After is the JSON encoded value after the action
+	After string `json:"after"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
@@ -17,6 +17,8 @@ type Package struct {
 	Name       string      `json:"name"`
 	Version    string      `json:"version"`
 	HTMLURL    string      `json:"html_url"`
+	// Immutable versions can't be overwritten or deleted
+	IsImmutable bool `json:"is_immutable"`
 	// swagger:strfmt date-time
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -31,3 +33,150 @@ type PackageFile struct {
 	HashSHA256 string `json:"sha256"`
 	HashSHA512 string `json:"sha512"`
 }
+
+// PackageChannel represents a named pointer like "staging" or "prod" to a package version
+type PackageChannel struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	PromotedBy *User  `json:"promoted_by"`
+	// swagger:strfmt date-time
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetPackageChannelOption options for pointing a channel to a package version
+type SetPackageChannelOption struct {
+	// required: true
+	Version string `json:"version" binding:"Required"`
+}
+
+// PromotePackageOption options for promoting a package version
+type PromotePackageOption struct {
+	// Owner which receives a copy of the version, defaults to the owner of the package.
+	// Versions of alpine, alt, arch, cargo, debian and rpm packages can't be copied to another owner.
+	TargetOwner string `json:"target_owner"`
+	// Channel of the target owner which is pointed to the promoted version
+	Channel string `json:"channel"`
+	// Make the promoted version immutable
+	Lock bool `json:"lock"`
+}
+
+// PackagePromotion represents a recorded promotion of a package version
+type PackagePromotion struct {
+	ID          int64  `json:"id"`
+	SourceOwner *User  `json:"source_owner"`
+	Channel     string `json:"channel"`
+	Promoter    *User  `json:"promoter"`
+	// swagger:strfmt date-time
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PackageSecurityReport represents the scan results of a package version
+type PackageSecurityReport struct {
+	// Licenses declared by the package version
+	Licenses        []string                `json:"licenses"`
+	Vulnerabilities []*PackageVulnerability `json:"vulnerabilities"`
+}
+
+// PackageVulnerability represents a dependency which is affected by a known vulnerability
+type PackageVulnerability struct {
+	AdvisoryID            string `json:"advisory_id"`
+	DependencyName        string `json:"dependency_name"`
+	DependencyRequirement string `json:"dependency_requirement"`
+	// enum: UNKNOWN,LOW,MODERATE,HIGH,CRITICAL
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	URL      string `json:"url"`
+}
+
+// PackageProvenance represents a SLSA provenance statement in the in-toto format
+// of a package version which was published by an Actions run
+type PackageProvenance struct {
+	Type          string                      `json:"_type"`
+	Subject       []*PackageProvenanceSubject `json:"subject"`
+	PredicateType string                      `json:"predicateType"`
+	Predicate     *PackageProvenancePredicate `json:"predicate"`
+}
+
+// PackageProvenanceSubject represents a package file covered by a provenance statement
+type PackageProvenanceSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// PackageProvenancePredicate represents the SLSA build provenance predicate
+type PackageProvenancePredicate struct {
+	BuildDefinition *PackageProvenanceBuildDefinition `json:"buildDefinition"`
+	RunDetails      *PackageProvenanceRunDetails      `json:"runDetails"`
+}
+
+// PackageProvenanceBuildDefinition describes the inputs of a build
+type PackageProvenanceBuildDefinition struct {
+	BuildType            string                                 `json:"buildType"`
+	ExternalParameters   map[string]any                         `json:"externalParameters"`
+	InternalParameters   map[string]any                         `json:"internalParameters,omitempty"`
+	ResolvedDependencies []*PackageProvenanceResourceDescriptor `json:"resolvedDependencies"`
+}
+
+// PackageProvenanceResourceDescriptor describes an artifact used by a build
+type PackageProvenanceResourceDescriptor struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+// PackageProvenanceRunDetails describes the build invocation
+type PackageProvenanceRunDetails struct {
+	Builder  *PackageProvenanceBuilder  `json:"builder"`
+	Metadata *PackageProvenanceMetadata `json:"metadata"`
+}
+
+// PackageProvenanceBuilder identifies the build platform
+type PackageProvenanceBuilder struct {
+	ID string `json:"id"`
+}
+
+// PackageProvenanceMetadata contains information about the build invocation
+type PackageProvenanceMetadata struct {
+	InvocationID string `json:"invocationId"`
+	// swagger:strfmt date-time
+	StartedOn *time.Time `json:"startedOn,omitempty"`
+	// swagger:strfmt date-time
+	FinishedOn *time.Time `json:"finishedOn,omitempty"`
+}
+
+// PackageVirtualRegistry represents a registry which resolves packages across several owners.
+// Requests for packages none of them contain are proxied to the upstream registry, which is not a
+// pull-through cache: its packages are fetched again on every request and never stored.
+type PackageVirtualRegistry struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Owners whose packages are looked up, in resolution order
+	Members []string `json:"members"`
+	// Registry which is queried if no member contains the package
+	UpstreamURL string `json:"upstream_url"`
+	// URL to configure in the package manager client
+	RegistryURL string `json:"registry_url"`
+	// swagger:strfmt date-time
+	CreatedAt time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreatePackageVirtualRegistryOption options for creating a virtual package registry
+type CreatePackageVirtualRegistryOption struct {
+	// required: true
+	Name string `json:"name" binding:"Required;AlphaDashDot;MaxSize(255)"`
+	// required: true
+	// enum: maven,npm,pypi
+	Type string `json:"type" binding:"Required"`
+	// Owners whose packages are looked up, in resolution order
+	Members     []string `json:"members"`
+	UpstreamURL string   `json:"upstream_url" binding:"MaxSize(2048)"`
+}
+
+// EditPackageVirtualRegistryOption options for editing a virtual package registry
+type EditPackageVirtualRegistryOption struct {
+	// Owners whose packages are looked up, in resolution order
+	Members     []string `json:"members"`
+	UpstreamURL *string  `json:"upstream_url" binding:"MaxSize(2048)"`
+}
@@ -118,3 +118,34 @@ type ChangedFile struct {
 	ContentsURL      string `json:"contents_url,omitempty"`
 	RawURL           string `json:"raw_url,omitempty"`
 }
+
+// CreateFederatedPullRequestOption options to offer a branch as pull request to a repository of another instance
+type CreateFederatedPullRequestOption struct {
+	// branch of this repository which is offered
+	Head string `json:"head" binding:"Required"`
+	// ActivityPub id of the remote repository
+	Target string `json:"target" binding:"Required;ValidUrl"`
+	// branch of the remote repository the changes should be merged into
+	Base  string `json:"base" binding:"Required"`
+	Title string `json:"title" binding:"Required"`
+	Body  string `json:"body"`
+}
+
+// FederatedPullRequest represents a branch offered as pull request to a repository of another instance
+type FederatedPullRequest struct {
+	ID     int64  `json:"id"`
+	Head   string `json:"head"`
+	Target string `json:"target"`
+	Base   string `json:"base"`
+	Title  string `json:"title"`
+	// ActivityPub id of the offer
+	OfferID string `json:"offer_id"`
+	// ActivityPub id of the remote pull request, set once it was accepted
+	TicketID string `json:"ticket_id"`
+	// enum: pending,accepted,merged,closed
+	Status string `json:"status"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
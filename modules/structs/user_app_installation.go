@@ -0,0 +1,43 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package structs
+
+import (
+	"time"
+)
+
+// InstallableApplication represents an OAuth2 application which users and organizations can install
+type InstallableApplication struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	ClientID string `json:"client_id"`
+	// the bot acting for the application in its installations
+	Bot *User `json:"bot"`
+	// the repository permissions requested from the owners installing the application
+	Permissions []string `json:"permissions"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// ApplicationInstallation represents the installation of an application on a user or organization
+type ApplicationInstallation struct {
+	ID    int64 `json:"id"`
+	Owner *User `json:"owner"`
+	// the repositories of the owner the application is limited to, empty for all of them
+	RepositoryIDs []int64  `json:"repository_ids"`
+	Permissions   []string `json:"permissions"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// ApplicationInstallationToken represents a short-lived token to act for an application in one of its installations
+type ApplicationInstallationToken struct {
+	Token         string   `json:"token"`
+	RepositoryIDs []int64  `json:"repository_ids"`
+	Permissions   []string `json:"permissions"`
+	// swagger:strfmt date-time
+	ExpiresAt time.Time `json:"expires_at"`
+}
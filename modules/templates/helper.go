@@ -113,6 +113,9 @@ func NewFuncMap() template.FuncMap {
 		"RepoFlagsEnabled": func() bool {
 			return setting.Repository.EnableFlags
 		},
+		"SecretScanningEnabled": func() bool {
+			return setting.SecretScanning.Enabled
+		},
 		"AssetVersion": func() string {
 			return setting.AssetVersion
 		},
@@ -4,10 +4,17 @@
 package test
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
 	"strings"
 	"testing"
 )
@@ -18,12 +25,14 @@ type FederationServerMockPerson struct {
 	PubKey string
 }
 type FederationServerMockRepository struct {
-	ID int64
+	ID       int64
+	CloneURI string // absolute or relative to the mock server
 }
 type FederationServerMock struct {
 	Persons      []FederationServerMockPerson
 	Repositories []FederationServerMockRepository
 	LastPost     string
+	GitUpstream  string // requests to /git/ are passed on to this server if set
 }
 
 func NewFederationServerMockPerson(id int64, name string) FederationServerMockPerson {
@@ -44,6 +53,19 @@ func NewFederationServerMockRepository(id int64) FederationServerMockRepository
 	}
 }
 
+func (r FederationServerMockRepository) marshal(host string) string {
+	cloneURI := r.CloneURI
+	if strings.HasPrefix(cloneURI, "/") {
+		cloneURI = "http://" + host + cloneURI
+	}
+	return fmt.Sprintf(`{"@context":["https://www.w3.org/ns/activitystreams","https://forgefed.org/ns"],`+
+		`"id":"http://%[1]v/api/v1/activitypub/repository-id/%[2]v",`+
+		`"type":"Repository",`+
+		`"inbox":"http://%[1]v/api/v1/activitypub/repository-id/%[2]v/inbox",`+
+		`"name":"repo%[2]v",`+
+		`"cloneUri":"%[3]v"}`, host, r.ID, cloneURI)
+}
+
 func (p FederationServerMockPerson) marshal(host string) string {
 	return fmt.Sprintf(`{"@context":["https://www.w3.org/ns/activitystreams","https://w3id.org/security/v1"],`+
 		`"id":"http://%[1]v/api/activitypub/user-id/%[2]v",`+
@@ -71,6 +93,36 @@ func NewFederationServerMock() *FederationServerMock {
 	}
 }
 
+// GeneratePersonKey replaces the public key of a person by a generated one and returns the PEM encoded key pair,
+// so that requests can be signed on behalf of the person. It has to be called before DistantServer.
+func (mock *FederationServerMock) GeneratePersonKey(t *testing.T, id int64) (privPem, pubPem string) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privPem = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}))
+	pubPem = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}))
+	quotedPubPem, err := json.Marshal(pubPem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range mock.Persons {
+		if mock.Persons[i].ID == id {
+			mock.Persons[i].PubKey = string(quotedPubPem)
+		}
+	}
+	return privPem, pubPem
+}
+
+// PersonKeyID returns the id of the public key of a person of the mock server
+func PersonKeyID(serverURL string, id int64) string {
+	return fmt.Sprintf("%s/api/activitypub/user-id/%d#main-key", serverURL, id)
+}
+
 func (mock *FederationServerMock) DistantServer(t *testing.T) *httptest.Server {
 	federatedRoutes := http.NewServeMux()
 	federatedRoutes.HandleFunc("/.well-known/nodeinfo",
@@ -93,8 +145,29 @@ func (mock *FederationServerMock) DistantServer(t *testing.T) *httptest.Server {
 				// curl -H "Accept: application/json" https://federated-repo.prod.meissa.de/api/v1/activitypub/user-id/2
 				fmt.Fprint(res, person.marshal(req.Host))
 			})
+		// the key id refers to the person without the api version
+		federatedRoutes.HandleFunc(fmt.Sprintf("/api/activitypub/user-id/%v", person.ID),
+			func(res http.ResponseWriter, req *http.Request) {
+				fmt.Fprint(res, person.marshal(req.Host))
+			})
+		federatedRoutes.HandleFunc(fmt.Sprintf("/api/activitypub/user-id/%v/inbox", person.ID),
+			func(res http.ResponseWriter, req *http.Request) {
+				if req.Method != "POST" {
+					t.Errorf("POST expected at: %q", req.URL.EscapedPath())
+				}
+				buf := new(strings.Builder)
+				_, err := io.Copy(buf, req.Body)
+				if err != nil {
+					t.Errorf("Error reading body: %q", err)
+				}
+				mock.LastPost = buf.String()
+			})
 	}
 	for _, repository := range mock.Repositories {
+		federatedRoutes.HandleFunc(fmt.Sprintf("/api/v1/activitypub/repository-id/%v", repository.ID),
+			func(res http.ResponseWriter, req *http.Request) {
+				fmt.Fprint(res, repository.marshal(req.Host))
+			})
 		federatedRoutes.HandleFunc(fmt.Sprintf("/api/v1/activitypub/repository-id/%v/inbox", repository.ID),
 			func(res http.ResponseWriter, req *http.Request) {
 				if req.Method != "POST" {
@@ -108,6 +181,13 @@ func (mock *FederationServerMock) DistantServer(t *testing.T) *httptest.Server {
 				mock.LastPost = buf.String()
 			})
 	}
+	if mock.GitUpstream != "" {
+		upstream, err := url.Parse(mock.GitUpstream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		federatedRoutes.Handle("/git/", http.StripPrefix("/git", httputil.NewSingleHostReverseProxy(upstream)))
+	}
 	federatedRoutes.HandleFunc("/",
 		func(res http.ResponseWriter, req *http.Request) {
 			t.Errorf("Unhandled request: %q", req.URL.EscapedPath())
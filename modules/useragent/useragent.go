@@ -0,0 +1,60 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+// Package useragent describes the device of a client from its User-Agent header
+package useragent
+
+import "strings"
+
+type match struct {
+	token string
+	name  string
+}
+
+// the first match wins, browsers which include the tokens of others come first
+var browsers = []match{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Vivaldi/", "Vivaldi"},
+	{"Firefox/", "Firefox"},
+	{"Chromium/", "Chromium"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"git/", "Git"},
+	{"curl/", "curl"},
+}
+
+var systems = []match{
+	{"Android", "Android"},
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+	{"BSD", "BSD"},
+}
+
+func find(userAgent string, matches []match) string {
+	for _, m := range matches {
+		if strings.Contains(userAgent, m.token) {
+			return m.name
+		}
+	}
+	return ""
+}
+
+// Device returns a short human readable description of the browser and the operating system of the
+// client, like "Firefox on Linux", or an empty string if neither is known
+func Device(userAgent string) string {
+	browser := find(userAgent, browsers)
+	system := find(userAgent, systems)
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	default:
+		return system
+	}
+}
@@ -62,7 +62,7 @@ func createOrAddToExisting(ctx *context.Context, pck *alpine_module.Package, bra
 	)
 	if err != nil {
 		switch err {
-		case packages_model.ErrDuplicatePackageVersion, packages_model.ErrDuplicatePackageFile:
+		case packages_model.ErrDuplicatePackageVersion, packages_model.ErrDuplicatePackageFile, packages_model.ErrPackageVersionImmutable:
 			apiError(ctx, http.StatusConflict, err)
 		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
 			apiError(ctx, http.StatusForbidden, err)
@@ -272,6 +272,8 @@ func DeletePackageFile(ctx *context.Context) {
 	if err := packages_service.RemovePackageFileAndVersionIfUnreferenced(ctx, ctx.Doer, pfs[0]); err != nil {
 		if errors.Is(err, util.ErrNotExist) {
 			apiError(ctx, http.StatusNotFound, err)
+		} else if errors.Is(err, packages_model.ErrPackageVersionImmutable) {
+			apiError(ctx, http.StatusConflict, err)
 		} else {
 			apiError(ctx, http.StatusInternalServerError, err)
 		}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	auth_model "forgejo.org/models/auth"
+	packages_model "forgejo.org/models/packages"
 	"forgejo.org/models/perm"
 	quota_model "forgejo.org/models/quota"
 	"forgejo.org/modules/log"
@@ -25,9 +26,11 @@ import (
 	"forgejo.org/routers/api/packages/container"
 	"forgejo.org/routers/api/packages/cran"
 	"forgejo.org/routers/api/packages/debian"
+	"forgejo.org/routers/api/packages/galaxy"
 	"forgejo.org/routers/api/packages/generic"
 	"forgejo.org/routers/api/packages/goproxy"
 	"forgejo.org/routers/api/packages/helm"
+	"forgejo.org/routers/api/packages/hex"
 	"forgejo.org/routers/api/packages/maven"
 	"forgejo.org/routers/api/packages/npm"
 	"forgejo.org/routers/api/packages/nuget"
@@ -37,8 +40,10 @@ import (
 	"forgejo.org/routers/api/packages/rubygems"
 	"forgejo.org/routers/api/packages/swift"
 	"forgejo.org/routers/api/packages/vagrant"
+	"forgejo.org/routers/api/packages/virtual"
 	"forgejo.org/services/auth"
 	"forgejo.org/services/context"
+	packages_service "forgejo.org/services/packages"
 )
 
 func reqPackageAccess(accessMode perm.AccessMode) func(ctx *context.Context) {
@@ -89,7 +94,31 @@ func reqPackageAccess(accessMode perm.AccessMode) func(ctx *context.Context) {
 			ctx.Error(http.StatusUnauthorized, "reqPackageAccess", "user should have specific permission or be a site admin")
 			return
 		}
+
+		// only the routes which upload or change packages need the provenance of the versions they create
+		if accessMode >= perm.AccessModeWrite {
+			assignProvenance(ctx)
+		}
+	}
+}
+
+// assignProvenance records the Actions run as provenance of versions uploaded with a task token
+func assignProvenance(ctx *context.Context) {
+	if ctx.Data["IsActionsToken"] != true {
+		return
 	}
+	taskID, ok := ctx.Data["ActionsTaskID"].(int64)
+	if !ok {
+		return
+	}
+
+	provenance, err := packages_service.GetProvenanceByTaskID(ctx, taskID)
+	if err != nil {
+		log.Error("GetProvenanceByTaskID: %v", err)
+		ctx.Error(http.StatusInternalServerError, "GetProvenanceByTaskID")
+		return
+	}
+	ctx.AppendContextValue(packages_service.ProvenanceContextKey, provenance)
 }
 
 func enforcePackagesQuota() func(ctx *context.Context) {
@@ -138,6 +167,8 @@ func CommonRoutes() *web.Route {
 		&nuget.Auth{},
 		&conan.Auth{},
 		&chef.Auth{},
+		&hex.Auth{},
+		&galaxy.Auth{},
 	})
 
 	r.Group("/{username}", func() {
@@ -428,6 +459,22 @@ func CommonRoutes() *web.Route {
 				ctx.Status(http.StatusNotFound)
 			})
 		}, reqPackageAccess(perm.AccessModeRead))
+		r.Group("/galaxy", func() {
+			r.Get("", galaxy.APIRoot)
+			r.Group("/api", func() {
+				r.Get("", galaxy.APIRoot)
+				r.Group("/v3", func() {
+					r.Post("/artifacts/collections", reqPackageAccess(perm.AccessModeWrite), enforcePackagesQuota(), galaxy.UploadPackageFile)
+					r.Get("/imports/collections/{id}", galaxy.ImportTask)
+					r.Group("/collections/{namespace}/{name}", func() {
+						r.Get("", galaxy.CollectionMetadata)
+						r.Get("/versions", galaxy.EnumerateCollectionVersions)
+						r.Get("/versions/{version}", galaxy.CollectionVersionMetadata)
+					})
+					r.Get("/download/{filename}", galaxy.DownloadPackageFile)
+				})
+			})
+		}, reqPackageAccess(perm.AccessModeRead))
 		r.Group("/generic", func() {
 			r.Group("/{packagename}/{packageversion}", func() {
 				r.Delete("", reqPackageAccess(perm.AccessModeWrite), generic.DeletePackage)
@@ -445,6 +492,25 @@ func CommonRoutes() *web.Route {
 			r.Get("/{filename}", helm.DownloadPackageFile)
 			r.Post("/api/charts", reqPackageAccess(perm.AccessModeWrite), enforcePackagesQuota(), helm.UploadPackage)
 		}, reqPackageAccess(perm.AccessModeRead))
+		r.Group("/hex", func() {
+			r.Get("/names", hex.GetNames)
+			r.Get("/versions", hex.GetVersions)
+			r.Get("/packages/{name}", hex.GetPackage)
+			r.Get("/tarballs/{filename}", hex.DownloadTarball)
+			r.Get("/public_key", hex.GetPublicKey)
+			r.Group("/api", func() {
+				r.Post("/publish", reqPackageAccess(perm.AccessModeWrite), enforcePackagesQuota(), hex.UploadPackage)
+				r.Post("/repos/{repo}/publish", reqPackageAccess(perm.AccessModeWrite), enforcePackagesQuota(), hex.UploadPackage)
+				r.Group("/packages/{name}", func() {
+					r.Get("", hex.PackageMetadata)
+					r.Group("/releases/{version}", func() {
+						r.Delete("", hex.DeleteRelease)
+						r.Post("/retire", hex.RetireRelease)
+						r.Delete("/retire", hex.UnretireRelease)
+					}, reqPackageAccess(perm.AccessModeWrite))
+				})
+			})
+		}, reqPackageAccess(perm.AccessModeRead))
 		r.Group("/maven", func() {
 			r.Put("/*", reqPackageAccess(perm.AccessModeWrite), enforcePackagesQuota(), maven.UploadPackageFile)
 			r.Get("/*", maven.DownloadPackageFile)
@@ -760,6 +826,28 @@ func CommonRoutes() *web.Route {
 				})
 			})
 		}, reqPackageAccess(perm.AccessModeRead))
+		r.Group("/virtual/{registry}", func() {
+			r.Group("/maven", func() {
+				r.Get("/*", virtual.Serve(maven.PackageNameFromPath, maven.DownloadPackageFile))
+				r.Head("/*", virtual.Serve(maven.PackageNameFromPath, maven.ProvidePackageFileHeader))
+			}, virtual.RegistryAssignment(packages_model.TypeMaven))
+			r.Group("/npm", func() {
+				r.Group("/@{scope}/{id}", func() {
+					r.Get("", virtual.ServeByParam(npm.PackageNameFromParams, npm.PackageMetadata))
+					r.Get("/-/{version}/{filename}", virtual.ServeByParam(npm.PackageNameFromParams, npm.DownloadPackageFile))
+					r.Get("/-/{filename}", virtual.ServeByParam(npm.PackageNameFromParams, npm.DownloadPackageFileByName))
+				})
+				r.Group("/{id}", func() {
+					r.Get("", virtual.ServeByParam(npm.PackageNameFromParams, npm.PackageMetadata))
+					r.Get("/-/{version}/{filename}", virtual.ServeByParam(npm.PackageNameFromParams, npm.DownloadPackageFile))
+					r.Get("/-/{filename}", virtual.ServeByParam(npm.PackageNameFromParams, npm.DownloadPackageFileByName))
+				})
+			}, virtual.RegistryAssignment(packages_model.TypeNpm))
+			r.Group("/pypi", func() {
+				r.Get("/files/{id}/{version}/{filename}", virtual.ServeByParam(pypi.PackageNameFromParams, pypi.DownloadPackageFile))
+				r.Get("/simple/{id}", virtual.ServeByParam(pypi.PackageNameFromParams, pypi.PackageMetadata))
+			}, virtual.RegistryAssignment(packages_model.TypePyPI))
+		}, reqPackageAccess(perm.AccessModeRead))
 	}, context.UserAssignmentWeb(), context.PackageAssignment())
 
 	return r
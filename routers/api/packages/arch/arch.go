@@ -146,7 +146,7 @@ func PushPackage(ctx *context.Context) {
 	)
 	if err != nil {
 		switch {
-		case errors.Is(err, packages_model.ErrDuplicatePackageVersion), errors.Is(err, packages_model.ErrDuplicatePackageFile):
+		case errors.Is(err, packages_model.ErrDuplicatePackageVersion), errors.Is(err, packages_model.ErrDuplicatePackageFile), errors.Is(err, packages_model.ErrPackageVersionImmutable):
 			apiError(ctx, http.StatusConflict, err)
 		case errors.Is(err, packages_service.ErrQuotaTotalCount), errors.Is(err, packages_service.ErrQuotaTypeSize), errors.Is(err, packages_service.ErrQuotaTotalSize):
 			apiError(ctx, http.StatusForbidden, err)
@@ -278,7 +278,11 @@ func RemovePackage(ctx *context.Context) {
 			deleted = true
 			err := packages_service.RemovePackageFileAndVersionIfUnreferenced(ctx, ctx.ContextUser, file)
 			if err != nil {
-				apiError(ctx, http.StatusInternalServerError, err)
+				if errors.Is(err, packages_model.ErrPackageVersionImmutable) {
+					apiError(ctx, http.StatusConflict, err)
+				} else {
+					apiError(ctx, http.StatusInternalServerError, err)
+				}
 				return
 			}
 		}
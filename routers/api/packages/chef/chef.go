@@ -370,6 +370,8 @@ func DeletePackageVersion(ctx *context.Context) {
 	if err != nil {
 		if err == packages_model.ErrPackageNotExist {
 			apiError(ctx, http.StatusNotFound, err)
+		} else if err == packages_model.ErrPackageVersionImmutable {
+			apiError(ctx, http.StatusConflict, err)
 		} else {
 			apiError(ctx, http.StatusInternalServerError, err)
 		}
@@ -394,7 +396,11 @@ func DeletePackage(ctx *context.Context) {
 
 	for _, pv := range pvs {
 		if err := packages_service.RemovePackageVersion(ctx, ctx.Doer, pv); err != nil {
-			apiError(ctx, http.StatusInternalServerError, err)
+			if err == packages_model.ErrPackageVersionImmutable {
+				apiError(ctx, http.StatusConflict, err)
+			} else {
+				apiError(ctx, http.StatusInternalServerError, err)
+			}
 			return
 		}
 	}
@@ -120,8 +120,9 @@ func Authenticate(ctx *context.Context) {
 
 	// If there's an API scope, ensure it propagates.
 	scope, _ := ctx.Data.GetData()["ApiTokenScope"].(auth_model.AccessTokenScope)
+	actionsTaskID, _ := ctx.Data["ActionsTaskID"].(int64)
 
-	token, err := packages_service.CreateAuthorizationToken(ctx.Doer, scope)
+	token, err := packages_service.CreateAuthorizationToken(ctx.Doer, scope, actionsTaskID)
 	if err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
@@ -418,7 +419,7 @@ func uploadFile(ctx *context.Context, fileFilter container.Set[string], fileKey
 	)
 	if err != nil {
 		switch err {
-		case packages_model.ErrDuplicatePackageFile:
+		case packages_model.ErrDuplicatePackageFile, packages_model.ErrPackageVersionImmutable:
 			apiError(ctx, http.StatusConflict, err)
 		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
 			apiError(ctx, http.StatusForbidden, err)
@@ -23,7 +23,7 @@ func (a *Auth) Name() string {
 // Verify extracts the user from the Bearer token
 // If it's an anonymous session a ghost user is returned
 func (a *Auth) Verify(req *http.Request, w http.ResponseWriter, store auth.DataStore, sess auth.SessionStore) (*user_model.User, error) {
-	uid, scope, err := packages.ParseAuthorizationToken(req)
+	uid, scope, actionsTaskID, err := packages.ParseAuthorizationToken(req)
 	if err != nil {
 		log.Trace("ParseAuthorizationToken: %v", err)
 		return nil, err
@@ -38,6 +38,11 @@ func (a *Auth) Verify(req *http.Request, w http.ResponseWriter, store auth.DataS
 		store.GetData()["IsApiToken"] = true
 		store.GetData()["ApiTokenScope"] = scope
 	}
+	// Propagate the Actions task of the token the authorization token was created with.
+	if actionsTaskID != 0 {
+		store.GetData()["IsActionsToken"] = true
+		store.GetData()["ActionsTaskID"] = actionsTaskID
+	}
 
 	u, err := user_model.GetPossibleUserByID(req.Context(), uid)
 	if err != nil {
@@ -167,8 +167,9 @@ func Authenticate(ctx *context.Context) {
 
 	// If there's an API scope, ensure it propagates.
 	scope, _ := ctx.Data["ApiTokenScope"].(auth_model.AccessTokenScope)
+	actionsTaskID, _ := ctx.Data["ActionsTaskID"].(int64)
 
-	token, err := packages_service.CreateAuthorizationToken(u, scope)
+	token, err := packages_service.CreateAuthorizationToken(u, scope, actionsTaskID)
 	if err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
@@ -584,6 +585,8 @@ func UploadManifest(ctx *context.Context) {
 			apiErrorDefined(ctx, namedError)
 		} else if errors.Is(err, container_model.ErrContainerBlobNotExist) {
 			apiErrorDefined(ctx, errBlobUnknown)
+		} else if errors.Is(err, packages_model.ErrPackageVersionImmutable) {
+			apiErrorDefined(ctx, errDenied.WithMessage(err.Error()))
 		} else {
 			switch err {
 			case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
@@ -686,6 +689,13 @@ func DeleteManifest(ctx *context.Context) {
 		return
 	}
 
+	for _, pv := range pvs {
+		if err := packages_service.CheckVersionMutable(ctx.Doer, pv); err != nil {
+			apiErrorDefined(ctx, errDenied.WithMessage(err.Error()))
+			return
+		}
+	}
+
 	for _, pv := range pvs {
 		if err := packages_service.RemovePackageVersion(ctx, ctx.Doer, pv); err != nil {
 			apiError(ctx, http.StatusInternalServerError, err)
@@ -350,9 +350,13 @@ func createPackageAndVersion(ctx context.Context, mci *manifestCreationInfo, met
 		LowerVersion: strings.ToLower(mci.Reference),
 		MetadataJSON: string(metadataJSON),
 	}
+	packages_service.ApplyProvenance(ctx, _pv)
 	var pv *packages_model.PackageVersion
 	if pv, err = packages_model.GetOrInsertVersion(ctx, _pv); err != nil {
 		if err == packages_model.ErrDuplicatePackageVersion {
+			if pv.IsImmutable {
+				return nil, packages_model.ErrPackageVersionImmutable
+			}
 			if err := packages_service.DeletePackageVersionAndReferences(ctx, pv); err != nil {
 				return nil, err
 			}
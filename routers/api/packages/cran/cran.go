@@ -208,7 +208,7 @@ func uploadPackageFile(ctx *context.Context, compositeKey string, properties map
 	)
 	if err != nil {
 		switch err {
-		case packages_model.ErrDuplicatePackageFile:
+		case packages_model.ErrDuplicatePackageFile, packages_model.ErrPackageVersionImmutable:
 			apiError(ctx, http.StatusConflict, err)
 		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
 			apiError(ctx, http.StatusForbidden, err)
@@ -0,0 +1,458 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package galaxy
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	packages_model "forgejo.org/models/packages"
+	"forgejo.org/modules/json"
+	"forgejo.org/modules/log"
+	packages_module "forgejo.org/modules/packages"
+	galaxy_module "forgejo.org/modules/packages/galaxy"
+	"forgejo.org/modules/util"
+	"forgejo.org/routers/api/packages/helper"
+	"forgejo.org/services/context"
+	packages_service "forgejo.org/services/packages"
+)
+
+const maxPageSize = 100
+
+func jsonResponse(ctx *context.Context, status int, obj any) {
+	resp := ctx.Resp
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(status)
+	if err := json.NewEncoder(resp).Encode(obj); err != nil {
+		log.Error("JSON encode: %v", err)
+	}
+}
+
+// https://github.com/ansible/ansible/blob/devel/lib/ansible/galaxy/api.py (GalaxyError)
+func apiError(ctx *context.Context, status int, obj any) {
+	type Error struct {
+		Status string `json:"status"`
+		Code   string `json:"code"`
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+	}
+	type ErrorWrapper struct {
+		Errors []Error `json:"errors"`
+	}
+
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		jsonResponse(ctx, status, ErrorWrapper{
+			Errors: []Error{
+				{
+					Status: strconv.Itoa(status),
+					Code:   strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_")),
+					Title:  http.StatusText(status),
+					Detail: message,
+				},
+			},
+		})
+	})
+}
+
+// apiURL gets the absolute url of the v3 api
+func apiURL(ctx *context.Context) string {
+	return helper.RegistryURL(ctx, packages_model.TypeGalaxy) + "/api/v3"
+}
+
+// apiPath gets the url path of the v3 api. ansible-galaxy expects pagination links without scheme and host.
+func apiPath(ctx *context.Context) string {
+	u, err := url.Parse(apiURL(ctx))
+	if err != nil {
+		return ""
+	}
+	return u.Path
+}
+
+func collectionPath(ctx *context.Context, pd *packages_model.PackageDescriptor) string {
+	metadata := pd.Metadata.(*galaxy_module.Metadata)
+	return fmt.Sprintf("%s/collections/%s/%s", apiPath(ctx), url.PathEscape(metadata.Namespace), url.PathEscape(metadata.Name))
+}
+
+func versionPath(ctx *context.Context, pd *packages_model.PackageDescriptor) string {
+	return fmt.Sprintf("%s/versions/%s/", collectionPath(ctx, pd), url.PathEscape(pd.Version.Version))
+}
+
+// https://github.com/ansible/ansible/blob/devel/lib/ansible/galaxy/api.py (available_api_versions)
+func APIRoot(ctx *context.Context) {
+	jsonResponse(ctx, http.StatusOK, map[string]any{
+		"description": "Forgejo Ansible Galaxy registry",
+		"available_versions": map[string]string{
+			"v3": "v3/",
+		},
+	})
+}
+
+func getPackageDescriptors(ctx *context.Context) ([]*packages_model.PackageDescriptor, bool) {
+	packageName := ctx.Params("namespace") + "." + ctx.Params("name")
+
+	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeGalaxy, packageName)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	if len(pvs) == 0 {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+		return nil, false
+	}
+
+	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	// highest version first
+	sort.Slice(pds, func(i, j int) bool {
+		return pds[j].SemVer.LessThan(pds[i].SemVer)
+	})
+
+	return pds, true
+}
+
+// https://galaxy.ansible.com/api/v3/swagger-ui/ (collections_read)
+func CollectionMetadata(ctx *context.Context) {
+	pds, ok := getPackageDescriptors(ctx)
+	if !ok {
+		return
+	}
+
+	latest := pds[0]
+	metadata := latest.Metadata.(*galaxy_module.Metadata)
+
+	created := pds[0].Version.CreatedUnix
+	for _, pd := range pds {
+		created = min(created, pd.Version.CreatedUnix)
+	}
+
+	jsonResponse(ctx, http.StatusOK, map[string]any{
+		"href":         collectionPath(ctx, latest) + "/",
+		"namespace":    metadata.Namespace,
+		"name":         metadata.Name,
+		"deprecated":   false,
+		"versions_url": collectionPath(ctx, latest) + "/versions/",
+		"highest_version": map[string]string{
+			"href":    versionPath(ctx, latest),
+			"version": latest.Version.Version,
+		},
+		"created_at": created.AsTime().Format(time.RFC3339),
+		"updated_at": latest.Version.CreatedUnix.AsTime().Format(time.RFC3339),
+	})
+}
+
+// https://galaxy.ansible.com/api/v3/swagger-ui/ (collections_versions_list)
+func EnumerateCollectionVersions(ctx *context.Context) {
+	pds, ok := getPackageDescriptors(ctx)
+	if !ok {
+		return
+	}
+
+	limit := ctx.FormInt("limit")
+	if limit <= 0 || limit > maxPageSize {
+		limit = maxPageSize
+	}
+	offset := max(ctx.FormInt("offset"), 0)
+
+	pageLink := func(offset int) string {
+		return fmt.Sprintf("%s/versions/?limit=%d&offset=%d", collectionPath(ctx, pds[0]), limit, offset)
+	}
+
+	var previous, next any
+	if offset > 0 {
+		previous = pageLink(max(offset-limit, 0))
+	}
+	if offset+limit < len(pds) {
+		next = pageLink(offset + limit)
+	}
+
+	data := make([]map[string]any, 0, limit)
+	for _, pd := range pds[min(offset, len(pds)):min(offset+limit, len(pds))] {
+		data = append(data, map[string]any{
+			"version":          pd.Version.Version,
+			"href":             versionPath(ctx, pd),
+			"created_at":       pd.Version.CreatedUnix.AsTime().Format(time.RFC3339),
+			"updated_at":       pd.Version.CreatedUnix.AsTime().Format(time.RFC3339),
+			"requires_ansible": nil,
+		})
+	}
+
+	jsonResponse(ctx, http.StatusOK, map[string]any{
+		"meta": map[string]int{
+			"count": len(pds),
+		},
+		"links": map[string]any{
+			"first":    pageLink(0),
+			"previous": previous,
+			"next":     next,
+			"last":     pageLink(max((len(pds)-1)/limit*limit, 0)),
+		},
+		"data": data,
+	})
+}
+
+// https://galaxy.ansible.com/api/v3/swagger-ui/ (collections_versions_read)
+func CollectionVersionMetadata(ctx *context.Context) {
+	pds, ok := getPackageDescriptors(ctx)
+	if !ok {
+		return
+	}
+
+	var pd *packages_model.PackageDescriptor
+	for _, candidate := range pds {
+		if candidate.Version.LowerVersion == strings.ToLower(ctx.Params("version")) {
+			pd = candidate
+			break
+		}
+	}
+	if pd == nil {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+		return
+	}
+
+	metadata := pd.Metadata.(*galaxy_module.Metadata)
+	pf := pd.Files[0]
+
+	dependencies := metadata.Dependencies
+	if dependencies == nil {
+		dependencies = map[string]string{}
+	}
+
+	jsonResponse(ctx, http.StatusOK, map[string]any{
+		"href":    versionPath(ctx, pd),
+		"version": pd.Version.Version,
+		"namespace": map[string]string{
+			"name": metadata.Namespace,
+		},
+		"collection": map[string]string{
+			"name": metadata.Name,
+			"href": collectionPath(ctx, pd) + "/",
+		},
+		"artifact": map[string]any{
+			"filename": pf.File.Name,
+			"sha256":   pf.Blob.HashSHA256,
+			"size":     pf.Blob.Size,
+		},
+		"download_url": fmt.Sprintf("%s/download/%s", apiURL(ctx), url.PathEscape(pf.File.Name)),
+		"metadata": map[string]any{
+			"authors":       metadata.Authors,
+			"description":   metadata.Description,
+			"license":       metadata.License,
+			"tags":          metadata.Tags,
+			"dependencies":  dependencies,
+			"repository":    metadata.RepositoryURL,
+			"documentation": metadata.DocumentationURL,
+			"homepage":      metadata.HomepageURL,
+			"issues":        metadata.IssuesURL,
+		},
+		"signatures": []any{},
+		"created_at": pd.Version.CreatedUnix.AsTime().Format(time.RFC3339),
+		"updated_at": pd.Version.CreatedUnix.AsTime().Format(time.RFC3339),
+	})
+}
+
+// DownloadPackageFile serves the collection tarball
+func DownloadPackageFile(ctx *context.Context) {
+	filename := ctx.Params("filename")
+
+	// namespace-name-version.tar.gz, namespace and name can't contain a dash
+	parts := strings.SplitN(strings.TrimSuffix(filename, ".tar.gz"), "-", 3)
+	if len(parts) != 3 || !strings.HasSuffix(filename, ".tar.gz") {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageFileNotExist)
+		return
+	}
+
+	s, u, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
+		ctx,
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: packages_model.TypeGalaxy,
+			Name:        parts[0] + "." + parts[1],
+			Version:     parts[2],
+		},
+		&packages_service.PackageFileInfo{
+			Filename: strings.ToLower(filename),
+		},
+	)
+	if err != nil {
+		if errors.Is(err, packages_model.ErrPackageNotExist) || errors.Is(err, packages_model.ErrPackageFileNotExist) {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	helper.ServePackageFile(ctx, s, u, pf)
+}
+
+// UploadPackageFile publishes a collection. The import is done synchronously, the returned task is already completed.
+// https://galaxy.ansible.com/api/v3/swagger-ui/ (artifacts_collections_create)
+func UploadPackageFile(ctx *context.Context) {
+	// ansible-galaxy sends the file part with a "file" content disposition
+	// which is ignored by the form parser of the standard library.
+	mr, err := ctx.Req.MultipartReader()
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var buf *packages_module.HashedBuffer
+	defer func() {
+		if buf != nil {
+			buf.Close()
+		}
+	}()
+	var expectedSHA256 string
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			apiError(ctx, http.StatusBadRequest, err)
+			return
+		}
+
+		_, params, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+		switch params["name"] {
+		case "sha256":
+			data, err := io.ReadAll(io.LimitReader(part, 128))
+			if err != nil {
+				apiError(ctx, http.StatusBadRequest, err)
+				return
+			}
+			expectedSHA256 = strings.TrimSpace(string(data))
+		case "file":
+			if buf != nil {
+				apiError(ctx, http.StatusBadRequest, "multiple files")
+				return
+			}
+			buf, err = packages_module.CreateHashedBufferFromReader(part)
+			if err != nil {
+				apiError(ctx, http.StatusInternalServerError, err)
+				return
+			}
+		}
+	}
+
+	if buf == nil {
+		apiError(ctx, http.StatusBadRequest, "file is missing")
+		return
+	}
+
+	_, _, hashSHA256, _, _ := buf.Sums()
+	if expectedSHA256 != "" && !strings.EqualFold(expectedSHA256, hex.EncodeToString(hashSHA256)) {
+		apiError(ctx, http.StatusBadRequest, "hash mismatch")
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	gp, err := galaxy_module.ParsePackage(buf)
+	if err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			apiError(ctx, http.StatusBadRequest, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	pv, _, err := packages_service.CreatePackageAndAddFile(
+		ctx,
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeGalaxy,
+				Name:        gp.FullName(),
+				Version:     gp.Version,
+			},
+			SemverCompatible: true,
+			Creator:          ctx.Doer,
+			Metadata:         gp.Metadata,
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename: strings.ToLower(fmt.Sprintf("%s-%s-%s.tar.gz", gp.Namespace, gp.Name, gp.Version)),
+			},
+			Creator: ctx.Doer,
+			Data:    buf,
+			IsLead:  true,
+		},
+	)
+	if err != nil {
+		switch err {
+		case packages_model.ErrDuplicatePackageVersion, packages_model.ErrPackageVersionImmutable:
+			apiError(ctx, http.StatusConflict, err)
+		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusForbidden, err)
+		default:
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	jsonResponse(ctx, http.StatusAccepted, map[string]string{
+		"task": fmt.Sprintf("%s/imports/collections/%d/", apiPath(ctx), pv.ID),
+	})
+}
+
+// ImportTask reports the state of an upload. Uploads are processed synchronously, so every existing task is completed.
+// https://galaxy.ansible.com/api/v3/swagger-ui/ (imports_collections_read)
+func ImportTask(ctx *context.Context) {
+	pv, err := packages_model.GetVersionByID(ctx, ctx.ParamsInt64("id"))
+	if err != nil {
+		if errors.Is(err, packages_model.ErrPackageNotExist) {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	p, err := packages_model.GetPackageByID(ctx, pv.PackageID)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if p.OwnerID != ctx.Package.Owner.ID || p.Type != packages_model.TypeGalaxy {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+		return
+	}
+
+	created := pv.CreatedUnix.AsTime().Format(time.RFC3339)
+
+	jsonResponse(ctx, http.StatusOK, map[string]any{
+		"id":          strconv.FormatInt(pv.ID, 10),
+		"state":       "completed",
+		"created_at":  created,
+		"updated_at":  created,
+		"started_at":  created,
+		"finished_at": created,
+		"error":       nil,
+		"messages":    []any{},
+	})
+}
@@ -129,7 +129,7 @@ func UploadPackage(ctx *context.Context) {
 	)
 	if err != nil {
 		switch err {
-		case packages_model.ErrDuplicatePackageFile:
+		case packages_model.ErrDuplicatePackageFile, packages_model.ErrPackageVersionImmutable:
 			apiError(ctx, http.StatusConflict, err)
 		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
 			apiError(ctx, http.StatusForbidden, err)
@@ -159,6 +159,10 @@ func DeletePackage(ctx *context.Context) {
 			apiError(ctx, http.StatusNotFound, err)
 			return
 		}
+		if errors.Is(err, packages_model.ErrPackageVersionImmutable) {
+			apiError(ctx, http.StatusConflict, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -190,6 +194,11 @@ func DeletePackageFile(ctx *context.Context) {
 		return
 	}
 
+	if err := packages_service.CheckVersionMutable(ctx.Doer, pv); err != nil {
+		apiError(ctx, http.StatusConflict, err)
+		return
+	}
+
 	pfs, err := packages_model.GetFilesByVersionID(ctx, pv.ID)
 	if err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
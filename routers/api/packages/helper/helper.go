@@ -61,3 +61,12 @@ func ServePackageFile(ctx *context.Context, s io.ReadSeekCloser, u *url.URL, pf
 
 	ctx.ServeContent(s, opts)
 }
+
+// RegistryURL returns the base URL of the registry the request was made against.
+// Requests which are served through a virtual registry get the URL of the virtual registry.
+func RegistryURL(ctx *context.Context, packageType packages_model.Type) string {
+	if u, ok := ctx.Data["VirtualRegistryURL"].(string); ok {
+		return u
+	}
+	return setting.AppURL + "api/packages/" + ctx.Package.Owner.Name + "/" + string(packageType)
+}
@@ -0,0 +1,402 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package hex
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	packages_model "forgejo.org/models/packages"
+	"forgejo.org/modules/json"
+	"forgejo.org/modules/log"
+	packages_module "forgejo.org/modules/packages"
+	hex_module "forgejo.org/modules/packages/hex"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/util"
+	"forgejo.org/routers/api/packages/helper"
+	"forgejo.org/services/context"
+	packages_service "forgejo.org/services/packages"
+	hex_service "forgejo.org/services/packages/hex"
+)
+
+// response writes the object in the Erlang External Term Format if the client accepts it or as JSON otherwise
+func response(ctx *context.Context, status int, obj map[string]any) {
+	if strings.Contains(ctx.Req.Header.Get("Accept"), hex_module.ContentTypeErlang) {
+		data, err := hex_module.EncodeExternalTerm(obj)
+		if err != nil {
+			log.Error("EncodeExternalTerm: %v", err)
+			ctx.Status(http.StatusInternalServerError)
+			return
+		}
+		ctx.Resp.Header().Set("Content-Type", hex_module.ContentTypeErlang)
+		ctx.Resp.WriteHeader(status)
+		_, _ = ctx.Resp.Write(data)
+		return
+	}
+
+	ctx.Resp.Header().Set("Content-Type", "application/json")
+	ctx.Resp.WriteHeader(status)
+	if err := json.NewEncoder(ctx.Resp).Encode(obj); err != nil {
+		log.Error("JSON encode: %v", err)
+	}
+}
+
+func apiError(ctx *context.Context, status int, obj any) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		response(ctx, status, map[string]any{
+			"status":  status,
+			"message": message,
+		})
+	})
+}
+
+// readRequest reads a request body in the Erlang External Term Format or JSON
+func readRequest(ctx *context.Context) (map[string]any, error) {
+	data, err := io.ReadAll(io.LimitReader(ctx.Req.Body, 64*1024))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return map[string]any{}, nil
+	}
+
+	if strings.HasPrefix(ctx.Req.Header.Get("Content-Type"), hex_module.ContentTypeErlang) {
+		v, err := hex_module.DecodeExternalTerm(data)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, hex_module.ErrInvalidExternalTerm
+		}
+		return m, nil
+	}
+
+	m := map[string]any{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, util.NewInvalidArgumentErrorf("invalid request body")
+	}
+	return m, nil
+}
+
+func baseURL(ctx *context.Context) string {
+	return setting.AppURL + "api/packages/" + url.PathEscape(ctx.Package.Owner.Name) + "/hex"
+}
+
+// serveResource serves a signed and gzipped registry resource
+func serveResource(ctx *context.Context, data []byte, err error) {
+	if err != nil {
+		if errors.Is(err, packages_model.ErrPackageNotExist) {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.ServeContent(bytes.NewReader(data), &context.ServeHeaderOptions{
+		ContentType: "application/octet-stream",
+	})
+}
+
+// https://github.com/hexpm/specifications/blob/main/registry-v2.md#names
+func GetNames(ctx *context.Context) {
+	data, err := hex_service.BuildNames(ctx, ctx.Package.Owner)
+	serveResource(ctx, data, err)
+}
+
+// https://github.com/hexpm/specifications/blob/main/registry-v2.md#versions
+func GetVersions(ctx *context.Context) {
+	data, err := hex_service.BuildVersions(ctx, ctx.Package.Owner)
+	serveResource(ctx, data, err)
+}
+
+// https://github.com/hexpm/specifications/blob/main/registry-v2.md#package
+func GetPackage(ctx *context.Context) {
+	data, err := hex_service.BuildPackage(ctx, ctx.Package.Owner, ctx.Params("name"))
+	serveResource(ctx, data, err)
+}
+
+// GetPublicKey serves the key which is used to verify the registry resources
+func GetPublicKey(ctx *context.Context) {
+	_, pub, err := hex_service.GetOrCreateKeyPair(ctx, ctx.Package.Owner.ID)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.ServeContent(strings.NewReader(pub), &context.ServeHeaderOptions{
+		ContentType: "application/x-pem-file",
+		Filename:    ctx.Package.Owner.LowerName + ".pem",
+	})
+}
+
+// https://github.com/hexpm/specifications/blob/main/endpoints.md#repository
+func DownloadTarball(ctx *context.Context) {
+	filename := ctx.Params("filename")
+
+	name, version, ok := strings.Cut(strings.TrimSuffix(filename, ".tar"), "-")
+	if !ok || !strings.HasSuffix(filename, ".tar") {
+		apiError(ctx, http.StatusNotFound, nil)
+		return
+	}
+
+	s, u, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
+		ctx,
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: packages_model.TypeHex,
+			Name:        name,
+			Version:     version,
+		},
+		&packages_service.PackageFileInfo{
+			Filename: strings.ToLower(filename),
+		},
+	)
+	if err != nil {
+		if errors.Is(err, packages_model.ErrPackageNotExist) || errors.Is(err, packages_model.ErrPackageFileNotExist) {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	helper.ServePackageFile(ctx, s, u, pf)
+}
+
+func releaseToResponse(ctx *context.Context, pd *packages_model.PackageDescriptor) map[string]any {
+	return map[string]any{
+		"version":     pd.Version.Version,
+		"checksum":    pd.Files[0].Blob.HashSHA256,
+		"has_docs":    false,
+		"retirement":  nil,
+		"inserted_at": pd.Version.CreatedUnix.AsTime().Format("2006-01-02T15:04:05Z"),
+		"url":         fmt.Sprintf("%s/api/packages/%s/releases/%s", baseURL(ctx), url.PathEscape(pd.Package.Name), url.PathEscape(pd.Version.Version)),
+		"package_url": fmt.Sprintf("%s/api/packages/%s", baseURL(ctx), url.PathEscape(pd.Package.Name)),
+		"html_url":    pd.VersionHTMLURL(),
+	}
+}
+
+// https://github.com/hexpm/specifications/blob/main/apiary.apib (Publish a Release)
+func UploadPackage(ctx *context.Context) {
+	defer ctx.Req.Body.Close()
+
+	buf, err := packages_module.CreateHashedBufferFromReader(ctx.Req.Body)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	hp, err := hex_module.ParsePackage(buf)
+	if err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			apiError(ctx, http.StatusUnprocessableEntity, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if ctx.FormBool("replace") {
+		pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeHex, hp.Name, hp.Version)
+		if err == nil {
+			err = packages_service.RemovePackageVersion(ctx, ctx.Doer, pv)
+		}
+		if err != nil && !errors.Is(err, packages_model.ErrPackageNotExist) {
+			if errors.Is(err, packages_model.ErrPackageVersionImmutable) {
+				apiError(ctx, http.StatusConflict, err)
+				return
+			}
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	pv, _, err := packages_service.CreatePackageAndAddFile(
+		ctx,
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeHex,
+				Name:        hp.Name,
+				Version:     hp.Version,
+			},
+			SemverCompatible: true,
+			Creator:          ctx.Doer,
+			Metadata:         hp.Metadata,
+			VersionProperties: map[string]string{
+				hex_module.PropertyInnerChecksum: hex.EncodeToString(hp.InnerChecksum),
+			},
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename: strings.ToLower(fmt.Sprintf("%s-%s.tar", hp.Name, hp.Version)),
+			},
+			Creator: ctx.Doer,
+			Data:    buf,
+			IsLead:  true,
+		},
+	)
+	if err != nil {
+		switch err {
+		case packages_model.ErrDuplicatePackageVersion, packages_model.ErrPackageVersionImmutable:
+			apiError(ctx, http.StatusConflict, err)
+		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusForbidden, err)
+		default:
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	pd, err := packages_model.GetPackageDescriptor(ctx, pv)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	response(ctx, http.StatusCreated, releaseToResponse(ctx, pd))
+}
+
+// https://github.com/hexpm/specifications/blob/main/apiary.apib (Fetch a Package)
+func PackageMetadata(ctx *context.Context) {
+	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeHex, ctx.Params("name"))
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if len(pvs) == 0 {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+		return
+	}
+
+	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	releases := make([]any, 0, len(pds))
+	for _, pd := range pds {
+		releases = append(releases, releaseToResponse(ctx, pd))
+	}
+
+	latest := pds[0]
+	for _, pd := range pds[1:] {
+		if latest.SemVer.LessThan(pd.SemVer) {
+			latest = pd
+		}
+	}
+	metadata := latest.Metadata.(*hex_module.Metadata)
+
+	links := map[string]any{}
+	for name, link := range metadata.Links {
+		links[name] = link
+	}
+
+	response(ctx, http.StatusOK, map[string]any{
+		"name":       latest.Package.Name,
+		"repository": hex_service.RepositoryName(ctx.Package.Owner),
+		"url":        fmt.Sprintf("%s/api/packages/%s", baseURL(ctx), url.PathEscape(latest.Package.Name)),
+		"html_url":   latest.PackageHTMLURL(),
+		"releases":   releases,
+		"meta": map[string]any{
+			"description": metadata.Description,
+			"licenses":    metadata.Licenses,
+			"links":       links,
+		},
+	})
+}
+
+func getPackageVersion(ctx *context.Context) (*packages_model.PackageVersion, bool) {
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeHex, ctx.Params("name"), ctx.Params("version"))
+	if err != nil {
+		if errors.Is(err, packages_model.ErrPackageNotExist) {
+			apiError(ctx, http.StatusNotFound, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return nil, false
+	}
+	return pv, true
+}
+
+// https://github.com/hexpm/specifications/blob/main/apiary.apib (Retire a Release)
+func RetireRelease(ctx *context.Context) {
+	pv, ok := getPackageVersion(ctx)
+	if !ok {
+		return
+	}
+
+	body, err := readRequest(ctx)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	reasonName, _ := body["reason"].(string)
+	reason, ok := hex_module.RetirementReasons[reasonName]
+	if !ok {
+		apiError(ctx, http.StatusUnprocessableEntity, util.NewInvalidArgumentErrorf("invalid retirement reason"))
+		return
+	}
+	message, _ := body["message"].(string)
+
+	if err := hex_service.RetireRelease(ctx, pv, &hex_module.RetirementStatus{
+		Reason:  reason,
+		Message: message,
+	}); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// https://github.com/hexpm/specifications/blob/main/apiary.apib (Unretire a Release)
+func UnretireRelease(ctx *context.Context) {
+	pv, ok := getPackageVersion(ctx)
+	if !ok {
+		return
+	}
+
+	if err := hex_service.UnretireRelease(ctx, pv); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// https://github.com/hexpm/specifications/blob/main/apiary.apib (Delete a Release)
+func DeleteRelease(ctx *context.Context) {
+	pv, ok := getPackageVersion(ctx)
+	if !ok {
+		return
+	}
+
+	if err := packages_service.RemovePackageVersion(ctx, ctx.Doer, pv); err != nil {
+		if errors.Is(err, packages_model.ErrPackageVersionImmutable) {
+			apiError(ctx, http.StatusConflict, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -68,6 +68,15 @@ func buildPackageID(groupID, artifactID string) string {
 	return fmt.Sprintf("%s:%s", groupID, artifactID)
 }
 
+// PackageNameFromPath gets the package name from the request path
+func PackageNameFromPath(ctx *context.Context) (string, error) {
+	params, err := extractPathParameters(ctx)
+	if err != nil {
+		return "", err
+	}
+	return buildPackageID(params.GroupID, params.ArtifactID), nil
+}
+
 // DownloadPackageFile serves the content of a package
 func DownloadPackageFile(ctx *context.Context) {
 	handlePackageFile(ctx, true)
@@ -377,7 +386,7 @@ func UploadPackageFile(ctx *context.Context) {
 	)
 	if err != nil {
 		switch err {
-		case packages_model.ErrDuplicatePackageFile:
+		case packages_model.ErrDuplicatePackageFile, packages_model.ErrPackageVersionImmutable:
 			apiError(ctx, http.StatusConflict, err)
 		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
 			apiError(ctx, http.StatusForbidden, err)
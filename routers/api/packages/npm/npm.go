@@ -20,7 +20,6 @@ import (
 	"forgejo.org/modules/optional"
 	packages_module "forgejo.org/modules/packages"
 	npm_module "forgejo.org/modules/packages/npm"
-	"forgejo.org/modules/setting"
 	"forgejo.org/modules/util"
 	"forgejo.org/routers/api/packages/helper"
 	"forgejo.org/services/context"
@@ -40,9 +39,9 @@ func apiError(ctx *context.Context, status int, obj any) {
 	})
 }
 
-// packageNameFromParams gets the package name from the url parameters
+// PackageNameFromParams gets the package name from the url parameters
 // Variations: /name/, /@scope/name/, /@scope%2Fname/
-func packageNameFromParams(ctx *context.Context) string {
+func PackageNameFromParams(ctx *context.Context) string {
 	scope := ctx.Params("scope")
 	id := ctx.Params("id")
 	if scope != "" {
@@ -53,7 +52,7 @@ func packageNameFromParams(ctx *context.Context) string {
 
 // PackageMetadata returns the metadata for a single package
 func PackageMetadata(ctx *context.Context) {
-	packageName := packageNameFromParams(ctx)
+	packageName := PackageNameFromParams(ctx)
 
 	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeNpm, packageName)
 	if err != nil {
@@ -72,7 +71,7 @@ func PackageMetadata(ctx *context.Context) {
 	}
 
 	resp := createPackageMetadataResponse(
-		setting.AppURL+"api/packages/"+ctx.Package.Owner.Name+"/npm",
+		helper.RegistryURL(ctx, packages_model.TypeNpm),
 		pds,
 	)
 
@@ -81,7 +80,7 @@ func PackageMetadata(ctx *context.Context) {
 
 // DownloadPackageFile serves the content of a package
 func DownloadPackageFile(ctx *context.Context) {
-	packageName := packageNameFromParams(ctx)
+	packageName := PackageNameFromParams(ctx)
 	packageVersion := ctx.Params("version")
 	filename := ctx.Params("filename")
 
@@ -118,7 +117,7 @@ func DownloadPackageFileByName(ctx *context.Context) {
 		Type:    packages_model.TypeNpm,
 		Name: packages_model.SearchValue{
 			ExactMatch: true,
-			Value:      packageNameFromParams(ctx),
+			Value:      PackageNameFromParams(ctx),
 		},
 		HasFileWithName: filename,
 		IsInternal:      optional.Some(false),
@@ -253,7 +252,7 @@ func DeletePreview(ctx *context.Context) {
 
 // DeletePackageVersion deletes the package version
 func DeletePackageVersion(ctx *context.Context) {
-	packageName := packageNameFromParams(ctx)
+	packageName := PackageNameFromParams(ctx)
 	packageVersion := ctx.Params("version")
 
 	err := packages_service.RemovePackageVersionByNameAndVersion(
@@ -271,6 +270,10 @@ func DeletePackageVersion(ctx *context.Context) {
 			apiError(ctx, http.StatusNotFound, err)
 			return
 		}
+		if err == packages_model.ErrPackageVersionImmutable {
+			apiError(ctx, http.StatusConflict, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -280,7 +283,7 @@ func DeletePackageVersion(ctx *context.Context) {
 
 // DeletePackage deletes the package and all versions
 func DeletePackage(ctx *context.Context) {
-	packageName := packageNameFromParams(ctx)
+	packageName := PackageNameFromParams(ctx)
 
 	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeNpm, packageName)
 	if err != nil {
@@ -295,7 +298,11 @@ func DeletePackage(ctx *context.Context) {
 
 	for _, pv := range pvs {
 		if err := packages_service.RemovePackageVersion(ctx, ctx.Doer, pv); err != nil {
-			apiError(ctx, http.StatusInternalServerError, err)
+			if err == packages_model.ErrPackageVersionImmutable {
+				apiError(ctx, http.StatusConflict, err)
+			} else {
+				apiError(ctx, http.StatusInternalServerError, err)
+			}
 			return
 		}
 	}
@@ -305,7 +312,7 @@ func DeletePackage(ctx *context.Context) {
 
 // ListPackageTags returns all tags for a package
 func ListPackageTags(ctx *context.Context) {
-	packageName := packageNameFromParams(ctx)
+	packageName := PackageNameFromParams(ctx)
 
 	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeNpm, packageName)
 	if err != nil {
@@ -330,7 +337,7 @@ func ListPackageTags(ctx *context.Context) {
 
 // AddPackageTag adds a tag to the package
 func AddPackageTag(ctx *context.Context) {
-	packageName := packageNameFromParams(ctx)
+	packageName := PackageNameFromParams(ctx)
 
 	body, err := io.ReadAll(ctx.Req.Body)
 	if err != nil {
@@ -361,7 +368,7 @@ func AddPackageTag(ctx *context.Context) {
 
 // DeletePackageTag deletes a package tag
 func DeletePackageTag(ctx *context.Context) {
-	packageName := packageNameFromParams(ctx)
+	packageName := PackageNameFromParams(ctx)
 
 	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeNpm, packageName)
 	if err != nil {
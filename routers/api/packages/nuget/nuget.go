@@ -263,6 +263,10 @@ func RegistrationLeafV2(ctx *context.Context) {
 			apiError(ctx, http.StatusNotFound, err)
 			return
 		}
+		if err == packages_model.ErrPackageVersionImmutable {
+			apiError(ctx, http.StatusConflict, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -558,7 +562,7 @@ func UploadSymbolPackage(ctx *context.Context) {
 		switch err {
 		case packages_model.ErrPackageNotExist:
 			apiError(ctx, http.StatusNotFound, err)
-		case packages_model.ErrDuplicatePackageFile:
+		case packages_model.ErrDuplicatePackageFile, packages_model.ErrPackageVersionImmutable:
 			apiError(ctx, http.StatusConflict, err)
 		case packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
 			apiError(ctx, http.StatusForbidden, err)
@@ -587,7 +591,7 @@ func UploadSymbolPackage(ctx *context.Context) {
 		)
 		if err != nil {
 			switch err {
-			case packages_model.ErrDuplicatePackageFile:
+			case packages_model.ErrDuplicatePackageFile, packages_model.ErrPackageVersionImmutable:
 				apiError(ctx, http.StatusConflict, err)
 			case packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
 				apiError(ctx, http.StatusForbidden, err)
@@ -15,7 +15,6 @@ import (
 	packages_model "forgejo.org/models/packages"
 	packages_module "forgejo.org/modules/packages"
 	pypi_module "forgejo.org/modules/packages/pypi"
-	"forgejo.org/modules/setting"
 	"forgejo.org/modules/validation"
 	"forgejo.org/routers/api/packages/helper"
 	"forgejo.org/services/context"
@@ -44,9 +43,14 @@ func apiError(ctx *context.Context, status int, obj any) {
 	})
 }
 
+// PackageNameFromParams gets the normalized package name from the url parameters
+func PackageNameFromParams(ctx *context.Context) string {
+	return normalizer.Replace(ctx.Params("id"))
+}
+
 // PackageMetadata returns the metadata for a single package
 func PackageMetadata(ctx *context.Context) {
-	packageName := normalizer.Replace(ctx.Params("id"))
+	packageName := PackageNameFromParams(ctx)
 
 	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypePyPI, packageName)
 	if err != nil {
@@ -69,7 +73,7 @@ func PackageMetadata(ctx *context.Context) {
 		return strings.Compare(pds[i].Version.Version, pds[j].Version.Version) < 0
 	})
 
-	ctx.Data["RegistryURL"] = setting.AppURL + "api/packages/" + ctx.Package.Owner.Name + "/pypi"
+	ctx.Data["RegistryURL"] = helper.RegistryURL(ctx, packages_model.TypePyPI)
 	ctx.Data["PackageDescriptor"] = pds[0]
 	ctx.Data["PackageDescriptors"] = pds
 	ctx.HTML(http.StatusOK, "api/packages/pypi/simple")
@@ -77,7 +81,7 @@ func PackageMetadata(ctx *context.Context) {
 
 // DownloadPackageFile serves the content of a package
 func DownloadPackageFile(ctx *context.Context) {
-	packageName := normalizer.Replace(ctx.Params("id"))
+	packageName := PackageNameFromParams(ctx)
 	packageVersion := ctx.Params("version")
 	filename := ctx.Params("filename")
 
@@ -185,6 +189,7 @@ func UploadPackageFile(ctx *context.Context) {
 				ProjectURL:      homepageURL,
 				License:         ctx.Req.FormValue("license"),
 				RequiresPython:  ctx.Req.FormValue("requires_python"),
+				RequiresDist:    ctx.Req.Form["requires_dist"],
 			},
 		},
 		&packages_service.PackageFileCreationInfo{
@@ -198,7 +203,7 @@ func UploadPackageFile(ctx *context.Context) {
 	)
 	if err != nil {
 		switch err {
-		case packages_model.ErrDuplicatePackageFile:
+		case packages_model.ErrDuplicatePackageFile, packages_model.ErrPackageVersionImmutable:
 			apiError(ctx, http.StatusConflict, err)
 		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
 			apiError(ctx, http.StatusForbidden, err)
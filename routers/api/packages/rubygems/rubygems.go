@@ -377,6 +377,10 @@ func DeletePackage(ctx *context.Context) {
 			apiError(ctx, http.StatusNotFound, err)
 			return
 		}
+		if err == packages_model.ErrPackageVersionImmutable {
+			apiError(ctx, http.StatusConflict, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 	}
 }
@@ -0,0 +1,167 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package virtual
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	auth_model "forgejo.org/models/auth"
+	packages_model "forgejo.org/models/packages"
+	"forgejo.org/models/perm"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/util"
+	"forgejo.org/routers/api/packages/helper"
+	"forgejo.org/services/context"
+	virtual_service "forgejo.org/services/packages/virtual"
+)
+
+// headers of an upstream response which are passed to the client
+var upstreamHeaders = []string{
+	"Cache-Control",
+	"Content-Encoding",
+	"Content-Length",
+	"Content-Range",
+	"Content-Type",
+	"ETag",
+	"Last-Modified",
+}
+
+func apiError(ctx *context.Context, status int, obj any) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.PlainText(status, message)
+	})
+}
+
+func virtualRegistry(ctx *context.Context) *packages_model.PackageVirtualRegistry {
+	return ctx.Data["VirtualRegistry"].(*packages_model.PackageVirtualRegistry)
+}
+
+// RegistryAssignment returns a middleware which loads the virtual registry addressed by the request
+func RegistryAssignment(packageType packages_model.Type) func(ctx *context.Context) {
+	return func(ctx *context.Context) {
+		vr, err := packages_model.GetVirtualRegistryByName(ctx, ctx.Package.Owner.ID, packageType, ctx.Params("registry"))
+		if err != nil {
+			if errors.Is(err, util.ErrNotExist) {
+				apiError(ctx, http.StatusNotFound, err)
+			} else {
+				apiError(ctx, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		ctx.Data["VirtualRegistry"] = vr
+		ctx.Data["VirtualRegistryURL"] = fmt.Sprintf("%sapi/packages/%s/virtual/%s/%s", setting.AppURL, ctx.Package.Owner.Name, vr.Name, vr.Type)
+	}
+}
+
+// Serve returns a handler which resolves the package across the members of the virtual registry.
+// The request is served by the first member which the doer can read and which contains the package.
+// If no member contains the package the request is passed through to the upstream registry.
+func Serve(packageName func(*context.Context) (string, error), handler func(*context.Context)) func(*context.Context) {
+	return func(ctx *context.Context) {
+		vr := virtualRegistry(ctx)
+
+		name, err := packageName(ctx)
+		if err != nil {
+			apiError(ctx, http.StatusBadRequest, err)
+			return
+		}
+
+		members, err := virtual_service.GetMembers(ctx, vr)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		publicOnly := isTokenPublicOnly(ctx)
+
+		for _, member := range members {
+			if publicOnly && !member.Visibility.IsPublic() {
+				continue
+			}
+
+			accessMode, err := context.DeterminePackageAccessMode(ctx.Base, member, ctx.Doer)
+			if err != nil {
+				apiError(ctx, http.StatusInternalServerError, err)
+				return
+			}
+			if accessMode < perm.AccessModeRead {
+				continue
+			}
+
+			if _, err := packages_model.GetPackageByName(ctx, member.ID, vr.Type, name); err != nil {
+				if err == packages_model.ErrPackageNotExist {
+					continue
+				}
+				apiError(ctx, http.StatusInternalServerError, err)
+				return
+			}
+
+			ctx.Package = &context.Package{
+				Owner:      member,
+				AccessMode: accessMode,
+			}
+			handler(ctx)
+			return
+		}
+
+		serveUpstream(ctx, vr)
+	}
+}
+
+// ServeByParam is a shortcut for Serve if the package name does not need to be validated
+func ServeByParam(packageName func(*context.Context) string, handler func(*context.Context)) func(*context.Context) {
+	return Serve(func(ctx *context.Context) (string, error) {
+		return packageName(ctx), nil
+	}, handler)
+}
+
+func isTokenPublicOnly(ctx *context.Context) bool {
+	scope, ok := ctx.Data["ApiTokenScope"].(auth_model.AccessTokenScope)
+	if !ok {
+		return false
+	}
+	publicOnly, err := scope.PublicOnly()
+	return err != nil || publicOnly
+}
+
+func serveUpstream(ctx *context.Context, vr *packages_model.PackageVirtualRegistry) {
+	if vr.UpstreamURL == "" {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+		return
+	}
+
+	_, path, ok := strings.Cut(ctx.Req.URL.Path, "/virtual/"+ctx.Params("registry")+"/"+string(vr.Type))
+	if !ok {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+		return
+	}
+
+	resp, err := virtual_service.FetchUpstream(ctx, vr, ctx.Req.Method, path, ctx.Req.URL.RawQuery, ctx.Req.Header)
+	if err != nil {
+		log.Warn("Failed to fetch %s from upstream of virtual registry %d: %v", path, vr.ID, err)
+		apiError(ctx, http.StatusBadGateway, "upstream registry is not reachable")
+		return
+	}
+	defer resp.Body.Close()
+
+	for _, h := range upstreamHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			ctx.Resp.Header().Set(h, v)
+		}
+	}
+	ctx.Resp.WriteHeader(resp.StatusCode)
+
+	if ctx.Req.Method == http.MethodHead {
+		return
+	}
+	if _, err := io.Copy(ctx.Resp, resp.Body); err != nil {
+		log.Debug("Failed to pass through upstream response: %v", err)
+	}
+}
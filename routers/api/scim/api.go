@@ -0,0 +1,185 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+// Package scim implements the SCIM 2.0 provisioning endpoint mounted on /scim/v2, see RFC 7644.
+package scim
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/modules/json"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/scim"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/web"
+	"forgejo.org/routers/common"
+	"forgejo.org/services/auth"
+	"forgejo.org/services/context"
+	scim_service "forgejo.org/services/scim"
+)
+
+// maxBodySize limits the size of request bodies, bulk requests included
+const maxBodySize = 1 << 20
+
+func Routes() *web.Route {
+	m := web.NewRoute()
+
+	m.Use(context.APIContexter())
+	m.Use(reqAdminToken())
+
+	m.Get("/ServiceProviderConfig", ServiceProviderConfig)
+	m.Get("/ResourceTypes", ResourceTypes)
+	m.Group("/Users", func() {
+		m.Get("", ListUsers)
+		m.Post("", CreateUser)
+		m.Get("/{id}", GetUser)
+		m.Put("/{id}", ReplaceUser)
+		m.Patch("/{id}", PatchUser)
+		m.Delete("/{id}", DeleteUser)
+	})
+	m.Group("/Groups", func() {
+		m.Get("", ListGroups)
+		m.Post("", CreateGroup)
+		m.Get("/{id}", GetGroup)
+		m.Put("/{id}", ReplaceGroup)
+		m.Patch("/{id}", PatchGroup)
+		m.Delete("/{id}", DeleteGroup)
+	})
+	m.Post("/Bulk", Bulk)
+
+	return m
+}
+
+// reqAdminToken only allows requests authenticated with an access token of an administrator having the write:admin scope
+func reqAdminToken() func(ctx *context.APIContext) {
+	authMethod := &auth.OAuth2{}
+	return func(ctx *context.APIContext) {
+		ar, err := common.AuthShared(ctx.Base, nil, authMethod)
+		if err != nil || ar.Doer == nil {
+			ctx.Resp.Header().Set("WWW-Authenticate", `Bearer realm="Forgejo SCIM"`)
+			writeError(ctx, scim.NewError(http.StatusUnauthorized, "", "an access token is required"))
+			return
+		}
+		ctx.Doer = ar.Doer
+		ctx.IsSigned = true
+
+		if !ctx.Doer.IsAdmin || !ctx.Doer.IsActive || ctx.Doer.ProhibitLogin {
+			writeError(ctx, scim.NewError(http.StatusForbidden, "", "the token does not belong to an administrator"))
+			return
+		}
+		scope, ok := ctx.Data["ApiTokenScope"].(auth_model.AccessTokenScope)
+		if !ok {
+			writeError(ctx, scim.NewError(http.StatusForbidden, "", "an access token is required"))
+			return
+		}
+		if has, err := scope.HasScope(auth_model.AccessTokenScopeWriteAdmin); err != nil || !has {
+			writeError(ctx, scim.NewError(http.StatusForbidden, "", "the token requires the %s scope", auth_model.AccessTokenScopeWriteAdmin))
+			return
+		}
+	}
+}
+
+func writeJSON(ctx *context.APIContext, status int, v any) {
+	ctx.Resp.Header().Set("Content-Type", scim.ContentType)
+	ctx.Resp.WriteHeader(status)
+	if err := json.NewEncoder(ctx.Resp).Encode(v); err != nil {
+		log.Error("Render SCIM response failed: %v", err)
+	}
+}
+
+// writeError responds with a SCIM error, errors which were not caused by the request are logged
+func writeError(ctx *context.APIContext, err error) {
+	var scimErr *scim.Error
+	if !errors.As(err, &scimErr) {
+		log.Error("SCIM request %s %s failed: %v", ctx.Req.Method, ctx.Req.URL.Path, err)
+		scimErr = scim.NewError(http.StatusInternalServerError, "", "internal error")
+	}
+	writeJSON(ctx, scimErr.HTTPStatus(), scimErr)
+}
+
+// decodeBody decodes the JSON body of the request into v
+func decodeBody(ctx *context.APIContext, v any) bool {
+	body, err := io.ReadAll(io.LimitReader(ctx.Req.Body, maxBodySize+1))
+	if err != nil {
+		writeError(ctx, err)
+		return false
+	}
+	if len(body) > maxBodySize {
+		writeError(ctx, scim.NewError(http.StatusRequestEntityTooLarge, scim.ErrorTypeTooMany, "the request body is too large"))
+		return false
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		writeError(ctx, scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidSyntax, "%v", err))
+		return false
+	}
+	return true
+}
+
+// listCount returns the requested number of resources of a list, -1 if the client did not ask for a limit
+func listCount(ctx *context.APIContext) int {
+	if !ctx.Req.URL.Query().Has("count") {
+		return -1
+	}
+	return ctx.FormInt("count")
+}
+
+// ServiceProviderConfig describes the supported features
+func ServiceProviderConfig(ctx *context.APIContext) {
+	writeJSON(ctx, http.StatusOK, &scim.ServiceProviderConfig{
+		Schemas:        []string{scim.SchemaServiceProviderConfig},
+		Patch:          scim.Supported{Supported: true},
+		Bulk:           scim.BulkSupport{Supported: true, MaxOperations: setting.SCIM.MaxBulkOperations, MaxPayloadSize: maxBodySize},
+		Filter:         scim.FilterSupport{Supported: true, MaxResults: setting.SCIM.MaxResults},
+		ChangePassword: scim.Supported{Supported: true},
+		Sort:           scim.Supported{Supported: false},
+		ETag:           scim.Supported{Supported: false},
+		AuthenticationSchemes: []scim.AuthenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "OAuth Bearer Token",
+			Description: "Access token of an administrator with the write:admin scope",
+			Primary:     true,
+		}},
+	})
+}
+
+// ResourceTypes lists the supported resource types
+func ResourceTypes(ctx *context.APIContext) {
+	resourceTypes := []any{
+		&scim.ResourceType{
+			Schemas:     []string{scim.SchemaResourceType},
+			ID:          scim.ResourceTypeUser,
+			Name:        scim.ResourceTypeUser,
+			Endpoint:    "/Users",
+			Description: "User accounts",
+			Schema:      scim.SchemaUser,
+			Meta:        &scim.Meta{ResourceType: "ResourceType", Location: setting.AppURL + "scim/v2/ResourceTypes/User"},
+		},
+		&scim.ResourceType{
+			Schemas:     []string{scim.SchemaResourceType},
+			ID:          scim.ResourceTypeGroup,
+			Name:        scim.ResourceTypeGroup,
+			Endpoint:    "/Groups",
+			Description: "Teams of organizations, named organization/team",
+			Schema:      scim.SchemaGroup,
+			Meta:        &scim.Meta{ResourceType: "ResourceType", Location: setting.AppURL + "scim/v2/ResourceTypes/Group"},
+		},
+	}
+	writeJSON(ctx, http.StatusOK, scim.NewListResponse(int64(len(resourceTypes)), 1, resourceTypes))
+}
+
+// Bulk runs several operations in one request
+func Bulk(ctx *context.APIContext) {
+	req := &scim.BulkRequest{}
+	if !decodeBody(ctx, req) {
+		return
+	}
+	resp, err := scim_service.ProcessBulk(ctx, req)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeJSON(ctx, http.StatusOK, resp)
+}
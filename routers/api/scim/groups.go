@@ -0,0 +1,84 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package scim
+
+import (
+	"net/http"
+
+	"forgejo.org/modules/scim"
+	"forgejo.org/services/context"
+	scim_service "forgejo.org/services/scim"
+)
+
+// ListGroups lists the teams matching the filter
+func ListGroups(ctx *context.APIContext) {
+	resp, err := scim_service.ListGroups(ctx, ctx.FormString("filter"), ctx.FormInt("startIndex"), listCount(ctx))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeJSON(ctx, http.StatusOK, resp)
+}
+
+// GetGroup returns a team
+func GetGroup(ctx *context.APIContext) {
+	g, err := scim_service.GetGroup(ctx, ctx.Params("id"))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeJSON(ctx, http.StatusOK, g)
+}
+
+// CreateGroup creates a team
+func CreateGroup(ctx *context.APIContext) {
+	in := &scim.Group{}
+	if !decodeBody(ctx, in) {
+		return
+	}
+	g, err := scim_service.CreateGroup(ctx, in)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.Resp.Header().Set("Location", g.Meta.Location)
+	writeJSON(ctx, http.StatusCreated, g)
+}
+
+// ReplaceGroup replaces the name and members of a team
+func ReplaceGroup(ctx *context.APIContext) {
+	in := &scim.Group{}
+	if !decodeBody(ctx, in) {
+		return
+	}
+	g, err := scim_service.ReplaceGroup(ctx, ctx.Params("id"), in)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeJSON(ctx, http.StatusOK, g)
+}
+
+// PatchGroup modifies the name and members of a team
+func PatchGroup(ctx *context.APIContext) {
+	patch := &scim.PatchRequest{}
+	if !decodeBody(ctx, patch) {
+		return
+	}
+	g, err := scim_service.PatchGroup(ctx, ctx.Params("id"), patch)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeJSON(ctx, http.StatusOK, g)
+}
+
+// DeleteGroup deletes a team
+func DeleteGroup(ctx *context.APIContext) {
+	if err := scim_service.DeleteGroup(ctx, ctx.Params("id")); err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
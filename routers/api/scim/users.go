@@ -0,0 +1,84 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package scim
+
+import (
+	"net/http"
+
+	"forgejo.org/modules/scim"
+	"forgejo.org/services/context"
+	scim_service "forgejo.org/services/scim"
+)
+
+// ListUsers lists the users matching the filter
+func ListUsers(ctx *context.APIContext) {
+	resp, err := scim_service.ListUsers(ctx, ctx.FormString("filter"), ctx.FormInt("startIndex"), listCount(ctx))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeJSON(ctx, http.StatusOK, resp)
+}
+
+// GetUser returns a user
+func GetUser(ctx *context.APIContext) {
+	u, err := scim_service.GetUser(ctx, ctx.Params("id"))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeJSON(ctx, http.StatusOK, u)
+}
+
+// CreateUser provisions a user
+func CreateUser(ctx *context.APIContext) {
+	in := &scim.User{}
+	if !decodeBody(ctx, in) {
+		return
+	}
+	u, err := scim_service.CreateUser(ctx, in)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.Resp.Header().Set("Location", u.Meta.Location)
+	writeJSON(ctx, http.StatusCreated, u)
+}
+
+// ReplaceUser replaces the attributes of a user
+func ReplaceUser(ctx *context.APIContext) {
+	in := &scim.User{}
+	if !decodeBody(ctx, in) {
+		return
+	}
+	u, err := scim_service.ReplaceUser(ctx, ctx.Params("id"), in)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeJSON(ctx, http.StatusOK, u)
+}
+
+// PatchUser modifies the attributes of a user
+func PatchUser(ctx *context.APIContext) {
+	patch := &scim.PatchRequest{}
+	if !decodeBody(ctx, patch) {
+		return
+	}
+	u, err := scim_service.PatchUser(ctx, ctx.Params("id"), patch)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeJSON(ctx, http.StatusOK, u)
+}
+
+// DeleteUser deactivates a user
+func DeleteUser(ctx *context.APIContext) {
+	if err := scim_service.DeleteUser(ctx, ctx.Params("id")); err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
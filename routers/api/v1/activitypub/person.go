@@ -5,13 +5,16 @@ package activitypub
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
 	"forgejo.org/modules/activitypub"
 	"forgejo.org/modules/log"
 	"forgejo.org/modules/setting"
+	"forgejo.org/routers/api/v1/utils"
 	"forgejo.org/services/context"
+	"forgejo.org/services/federation"
 
 	ap "github.com/go-ap/activitypub"
 	"github.com/go-ap/jsonld"
@@ -101,6 +104,58 @@ func PersonInbox(ctx *context.APIContext) {
 	// responses:
 	//   "204":
 	//     "$ref": "#/responses/empty"
+	//   "406":
+	//     "$ref": "#/responses/error"
 
+	body, err := io.ReadAll(io.LimitReader(ctx.Req.Body, setting.Federation.MaxSize))
+	if err != nil {
+		ctx.ServerError("ReadAll", err)
+		return
+	}
+
+	httpStatus, title, err := federation.ProcessPersonInbox(ctx, ctx.ContextUser, verifiedSigningHost(ctx), body)
+	if err != nil {
+		ctx.Error(httpStatus, title, err)
+		return
+	}
 	ctx.Status(http.StatusNoContent)
 }
+
+// PersonOutbox function returns the public activities of a user
+func PersonOutbox(ctx *context.APIContext) {
+	// swagger:operation GET /activitypub/user-id/{user-id}/outbox activitypub activitypubPersonOutbox
+	// ---
+	// summary: Returns the public activities of a user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: user-id
+	//   in: path
+	//   description: user ID of the user
+	//   type: integer
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActivityPub"
+
+	link := ctx.ContextUser.APActorID() + "/outbox"
+
+	activities, count, err := federation.GetUserActivities(ctx, ctx.ContextUser, utils.GetListOptions(ctx))
+	if err != nil {
+		ctx.ServerError("GetUserActivities", err)
+		return
+	}
+
+	outbox := ap.OrderedCollectionNew(ap.IRI(link))
+	outbox.AttributedTo = ap.IRI(ctx.ContextUser.APActorID())
+	outbox.TotalItems = uint(count)
+	for _, activity := range activities {
+		outbox.OrderedItems = append(outbox.OrderedItems, activity)
+	}
+
+	response(ctx, outbox)
+}
@@ -5,13 +5,14 @@ package activitypub
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
+	issues_model "forgejo.org/models/issues"
 	"forgejo.org/modules/forgefed"
 	"forgejo.org/modules/log"
 	"forgejo.org/modules/setting"
-	"forgejo.org/modules/web"
 	"forgejo.org/services/context"
 	"forgejo.org/services/federation"
 
@@ -44,6 +45,9 @@ func Repository(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "Set Name", err)
 		return
 	}
+	if !ctx.Repo.Repository.IsPrivate {
+		repo.CloneURI = ap.IRI(ctx.Repo.Repository.CloneLink().HTTPS)
+	}
 	response(ctx, repo)
 }
 
@@ -70,11 +74,127 @@ func RepositoryInbox(ctx *context.APIContext) {
 
 	repository := ctx.Repo.Repository
 	log.Info("RepositoryInbox: repo: %v", repository)
-	form := web.GetForm(ctx)
-	// TODO: Decide between like/undo{like} activity
-	httpStatus, title, err := federation.ProcessLikeActivity(ctx, form, repository.ID)
+
+	body, err := io.ReadAll(io.LimitReader(ctx.Req.Body, setting.Federation.MaxSize))
+	if err != nil {
+		ctx.ServerError("ReadAll", err)
+		return
+	}
+
+	httpStatus, title, err := federation.ProcessRepositoryInbox(ctx, repository, verifiedSigningHost(ctx), body)
 	if err != nil {
 		ctx.Error(httpStatus, title, err)
+		return
 	}
 	ctx.Status(http.StatusNoContent)
 }
+
+// RepositoryIssue function returns the Ticket of an issue
+func RepositoryIssue(ctx *context.APIContext) {
+	// swagger:operation GET /activitypub/repository-id/{repository-id}/issues/{index} activitypub activitypubRepositoryIssue
+	// ---
+	// summary: Returns the Ticket of an issue
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: repository-id
+	//   in: path
+	//   description: repository ID of the repo
+	//   type: integer
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActivityPub"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue := getFederatedIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ticket, err := federation.NewIssueTicket(ctx, ctx.Repo.Repository, issue)
+	if err != nil {
+		ctx.ServerError("NewIssueTicket", err)
+		return
+	}
+	response(ctx, ticket)
+}
+
+// RepositoryIssueComment function returns the Note of an issue comment
+func RepositoryIssueComment(ctx *context.APIContext) {
+	// swagger:operation GET /activitypub/repository-id/{repository-id}/issues/{index}/comments/{comment-id} activitypub activitypubRepositoryIssueComment
+	// ---
+	// summary: Returns the Note of an issue comment
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: repository-id
+	//   in: path
+	//   description: repository ID of the repo
+	//   type: integer
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   required: true
+	// - name: comment-id
+	//   in: path
+	//   description: id of the comment
+	//   type: integer
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActivityPub"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue := getFederatedIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	comment, err := issues_model.GetCommentByID(ctx, ctx.ParamsInt64(":comment-id"))
+	if err != nil {
+		if issues_model.IsErrCommentNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.ServerError("GetCommentByID", err)
+		}
+		return
+	}
+	if comment.IssueID != issue.ID || comment.Type != issues_model.CommentTypeComment {
+		ctx.NotFound()
+		return
+	}
+
+	note, err := federation.NewCommentNote(ctx, ctx.Repo.Repository, issue, comment)
+	if err != nil {
+		ctx.ServerError("NewCommentNote", err)
+		return
+	}
+	response(ctx, note)
+}
+
+func getFederatedIssue(ctx *context.APIContext) *issues_model.Issue {
+	issue, err := issues_model.GetIssueByIndex(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if issues_model.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.ServerError("GetIssueByIndex", err)
+		}
+		return nil
+	}
+	if !federation.IsIssueFederated(ctx, ctx.Repo.Repository, issue) {
+		ctx.NotFound()
+		return nil
+	}
+	return issue
+}
@@ -10,8 +10,10 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"forgejo.org/models/db"
 	"forgejo.org/models/forgefed"
@@ -130,10 +132,6 @@ func getPublicKeyFromResponse(b []byte, keyID *url.URL) (person *ap.Person, pubK
 }
 
 func verifyHTTPSignatures(ctx *gitea_context.APIContext) (authenticated bool, err error) {
-	if !setting.Federation.SignatureEnforced {
-		return true, nil
-	}
-
 	r := ctx.Req
 
 	// 1. Figure out what key we need to verify
@@ -216,14 +214,89 @@ func verifyHTTPSignatures(ctx *gitea_context.APIContext) (authenticated bool, er
 	return authenticated, err
 }
 
+// signingHost returns the host of the key a request was signed with or an empty string for unsigned requests
+func signingHost(ctx *gitea_context.APIContext) string {
+	v, err := httpsig.NewVerifier(ctx.Req)
+	if err != nil {
+		return ""
+	}
+	keyID, err := url.Parse(v.KeyId())
+	if err != nil {
+		return ""
+	}
+	return keyID.Hostname()
+}
+
+// verifiedSigningHost returns the host of the key a request was verified with by ReqHTTPSignature
+// or an empty string for unsigned requests, which are only accepted if signatures are not enforced
+func verifiedSigningHost(ctx *gitea_context.APIContext) string {
+	host, _ := ctx.Data["HTTPSignatureHost"].(string)
+	return host
+}
+
+// holdActivity stores the activity posted to the inbox of a user or a repository by a quarantined host for review.
+// Other requests are passed on.
+func holdActivity(ctx *gitea_context.APIContext, host string) {
+	var targetType forgefed.QuarantinedActivityTargetType
+	var targetID int64
+	switch {
+	case ctx.Repo != nil && ctx.Repo.Repository != nil:
+		targetType, targetID = forgefed.QuarantinedActivityTargetRepository, ctx.Repo.Repository.ID
+	case ctx.ContextUser != nil:
+		targetType, targetID = forgefed.QuarantinedActivityTargetUser, ctx.ContextUser.ID
+	default:
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(ctx.Req.Body, setting.Federation.MaxSize))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Error reading request body", err)
+		return
+	}
+	if err := federation.HoldActivity(ctx, host, targetType, targetID, body); err != nil {
+		ctx.Error(http.StatusInternalServerError, "HoldActivity", err)
+		return
+	}
+	log.Info("Held activity of quarantined host %s for review", host)
+	ctx.Status(http.StatusAccepted)
+}
+
 // ReqHTTPSignature function
 func ReqHTTPSignature() func(ctx *gitea_context.APIContext) {
 	return func(ctx *gitea_context.APIContext) {
-		if authenticated, err := verifyHTTPSignatures(ctx); err != nil {
-			log.Warn("verifyHttpSignatures failed: %v", err)
-			ctx.Error(http.StatusBadRequest, "reqSignature", "request signature verification failed")
-		} else if !authenticated {
-			ctx.Error(http.StatusForbidden, "reqSignature", "request signature verification failed")
+		// the host policy is checked before the key of a blocked host would be fetched
+		host := signingHost(ctx)
+		policy := federation.HostPolicyAllow
+		if host != "" {
+			var err error
+			if policy, err = federation.GetHostPolicy(ctx, host); err != nil {
+				ctx.Error(http.StatusInternalServerError, "GetHostPolicy", err)
+				return
+			}
+		} else if setting.Federation.HostPolicy == setting.FederationHostPolicyAllowlist {
+			policy = federation.HostPolicyDeny
+		}
+		if policy == federation.HostPolicyDeny {
+			ctx.Error(http.StatusForbidden, "reqSignature", "federation with this host is blocked")
+			return
+		}
+
+		// a signature is verified even if signatures are not enforced,
+		// so that the services can check the actors of the activity against its host
+		if host != "" || setting.Federation.SignatureEnforced {
+			if authenticated, err := verifyHTTPSignatures(ctx); err != nil {
+				log.Warn("verifyHttpSignatures failed: %v", err)
+				ctx.Error(http.StatusBadRequest, "reqSignature", "request signature verification failed")
+				return
+			} else if !authenticated {
+				ctx.Error(http.StatusForbidden, "reqSignature", "request signature verification failed")
+				return
+			}
+			ctx.Data["HTTPSignatureHost"] = strings.ToLower(host)
+		}
+
+		if policy == federation.HostPolicyQuarantine && ctx.Req.Method == http.MethodPost {
+			holdActivity(ctx, host)
 		}
 	}
 }
@@ -0,0 +1,54 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package admin
+
+import (
+	"forgejo.org/routers/api/v1/shared"
+	"forgejo.org/services/context"
+)
+
+// ListAuditEvents lists the audit events of the instance
+func ListAuditEvents(ctx *context.APIContext) {
+	// swagger:operation GET /admin/audit admin adminListAuditEvents
+	// ---
+	// summary: List the audit events of the instance
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: action
+	//   in: query
+	//   description: only list events of this action
+	//   type: string
+	// - name: actor
+	//   in: query
+	//   description: only list events of the actor with this name
+	//   type: string
+	// - name: since
+	//   in: query
+	//   description: only list events recorded after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: only list events recorded before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AuditEventList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	shared.ListAuditEvents(ctx, 0)
+}
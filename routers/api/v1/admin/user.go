@@ -12,6 +12,7 @@ import (
 
 	"forgejo.org/models"
 	asymkey_model "forgejo.org/models/asymkey"
+	audit_model "forgejo.org/models/audit"
 	"forgejo.org/models/auth"
 	"forgejo.org/models/db"
 	user_model "forgejo.org/models/user"
@@ -26,6 +27,8 @@ import (
 	"forgejo.org/routers/api/v1/user"
 	"forgejo.org/routers/api/v1/utils"
 	asymkey_service "forgejo.org/services/asymkey"
+	audit_service "forgejo.org/services/audit"
+	auth_service "forgejo.org/services/auth"
 	"forgejo.org/services/context"
 	"forgejo.org/services/convert"
 	"forgejo.org/services/mailer"
@@ -154,6 +157,7 @@ func CreateUser(ctx *context.APIContext) {
 	}
 
 	log.Trace("Account created by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionAdminUserCreate, audit_service.UserTarget(u), nil, audit_service.UserAccountValue(u))
 
 	// Send email notification.
 	if form.SendNotify {
@@ -192,6 +196,7 @@ func EditUser(ctx *context.APIContext) {
 	//     "$ref": "#/responses/validationError"
 
 	form := web.GetForm(ctx).(*api.EditUserOption)
+	before := audit_service.UserAccountValue(ctx.ContextUser)
 
 	// If either LoginSource or LoginName is given, the other must be present too.
 	if form.SourceID != nil || form.LoginName != nil {
@@ -266,6 +271,7 @@ func EditUser(ctx *context.APIContext) {
 	}
 
 	log.Trace("Account profile updated by admin (%s): %s", ctx.Doer.Name, ctx.ContextUser.Name)
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionAdminUserUpdate, audit_service.UserTarget(ctx.ContextUser), before, audit_service.UserAccountValue(ctx.ContextUser))
 
 	ctx.JSON(http.StatusOK, convert.ToUser(ctx, ctx.ContextUser, ctx.Doer))
 }
@@ -320,6 +326,38 @@ func DeleteUser(ctx *context.APIContext) {
 		return
 	}
 	log.Trace("Account deleted by admin(%s): %s", ctx.Doer.Name, ctx.ContextUser.Name)
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionAdminUserDelete, audit_service.UserTarget(ctx.ContextUser), audit_service.UserAccountValue(ctx.ContextUser), nil)
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// SignOutUser api for signing a user out of all sessions
+func SignOutUser(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/users/{username}/sessions admin adminSignOutUser
+	// ---
+	// summary: Sign a user out of all web sessions and remembered devices
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of the user
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if err := auth_service.SignOutEverywhere(ctx, ctx.ContextUser.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SignOutEverywhere", err)
+		return
+	}
+	log.Trace("Account signed out everywhere by admin(%s): %s", ctx.Doer.Name, ctx.ContextUser.Name)
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionAdminUserSignOut, audit_service.UserTarget(ctx.ContextUser), nil, nil)
 
 	ctx.Status(http.StatusNoContent)
 }
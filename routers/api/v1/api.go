@@ -69,7 +69,6 @@ import (
 	repo_model "forgejo.org/models/repo"
 	"forgejo.org/models/unit"
 	user_model "forgejo.org/models/user"
-	"forgejo.org/modules/forgefed"
 	"forgejo.org/modules/log"
 	"forgejo.org/modules/setting"
 	api "forgejo.org/modules/structs"
@@ -77,6 +76,7 @@ import (
 	"forgejo.org/routers/api/shared"
 	"forgejo.org/routers/api/v1/activitypub"
 	"forgejo.org/routers/api/v1/admin"
+	"forgejo.org/routers/api/v1/apps"
 	"forgejo.org/routers/api/v1/misc"
 	"forgejo.org/routers/api/v1/notify"
 	"forgejo.org/routers/api/v1/org"
@@ -157,6 +157,10 @@ func repoAssignment() func(ctx *context.APIContext) {
 		ctx.Repo.Owner = owner
 		ctx.ContextUser = owner
 
+		if !ctx.CheckIPAllowlist(owner) || !ctx.CheckTwoFactorPolicy(owner) {
+			return
+		}
+
 		// Get repository.
 		repo, err := repo_model.GetRepositoryByName(ctx, owner.ID, repoName)
 		if err != nil {
@@ -211,6 +215,7 @@ func repoAssignment() func(ctx *context.APIContext) {
 				ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
 				return
 			}
+			context.LimitPermissionToAccessToken(ctx.Data, repo, &ctx.Repo.Permission)
 		}
 
 		if !ctx.Repo.HasAccess() {
@@ -352,6 +357,12 @@ func tokenRequiresScopes(requiredScopeCategories ...auth_model.AccessTokenScopeC
 			return
 		}
 
+		// fine-grained tokens are limited to repositories, they can not be used outside of them
+		if context.FineGrainedAccessToken(ctx.Data) != nil && (ctx.Params(":username") == "" || ctx.Params(":reponame") == "") {
+			ctx.Error(http.StatusForbidden, "tokenRequiresScope", "fine-grained tokens can only be used for the repositories they are limited to")
+			return
+		}
+
 		ctx.Data["requiredScopeCategories"] = requiredScopeCategories
 
 		// check if scope only applies to public resources
@@ -459,6 +470,36 @@ func reqRepoWriter(unitTypes ...unit.Type) func(ctx *context.APIContext) {
 	}
 }
 
+// reqCommitStatusWriter user should have a permission to write to the code of a repo, or be a site admin.
+// Fine-grained tokens need the permission to write commit statuses instead.
+func reqCommitStatusWriter() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		token := context.FineGrainedAccessToken(ctx.Data)
+		if token == nil {
+			reqRepoWriter(unit.TypeCode)(ctx)
+			return
+		}
+		if token.RepoPermissions.Level(auth_model.AccessTokenRepoPermissionStatuses) < auth_model.Write {
+			ctx.Error(http.StatusForbidden, "reqCommitStatusWriter", "token should have a permission to write commit statuses")
+			return
+		}
+		// the tokens of installations have the permissions of the installation
+		if token.InstallationID > 0 {
+			return
+		}
+		// the permission of the repository is limited by the token, check the user can write without it
+		permission, err := access_model.GetUserRepoPermission(ctx, ctx.Repo.Repository, ctx.Doer)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+			return
+		}
+		if !permission.CanWrite(unit.TypeCode) {
+			ctx.Error(http.StatusForbidden, "reqCommitStatusWriter", "user should have a permission to write to a repo")
+			return
+		}
+	}
+}
+
 // reqRepoBranchWriter user should have a permission to write to a branch, or be a site admin
 func reqRepoBranchWriter(ctx *context.APIContext) {
 	options, ok := web.GetForm(ctx).(api.FileOptionInterface)
@@ -640,6 +681,9 @@ func orgAssignment(args ...bool) func(ctx *context.APIContext) {
 				return
 			}
 			ctx.ContextUser = ctx.Org.Organization.AsUser()
+			if !ctx.CheckIPAllowlist(ctx.ContextUser) {
+				return
+			}
 		}
 
 		if assignTeam {
@@ -652,6 +696,16 @@ func orgAssignment(args ...bool) func(ctx *context.APIContext) {
 				}
 				return
 			}
+			if !assignOrg {
+				org, err := user_model.GetUserByID(ctx, ctx.Org.Team.OrgID)
+				if err != nil {
+					ctx.Error(http.StatusInternalServerError, "GetUserByID", err)
+					return
+				}
+				if !ctx.CheckIPAllowlist(org) {
+					return
+				}
+			}
 		}
 	}
 }
@@ -834,6 +888,7 @@ func Routes() *web.Route {
 				m.Group("/user-id/{user-id}", func() {
 					m.Get("", activitypub.ReqHTTPSignature(), activitypub.Person)
 					m.Post("/inbox", activitypub.ReqHTTPSignature(), activitypub.PersonInbox)
+					m.Get("/outbox", activitypub.ReqHTTPSignature(), activitypub.PersonOutbox)
 				}, context.UserIDAssignmentAPI(), checkTokenPublicOnly())
 				m.Group("/actor", func() {
 					m.Get("", activitypub.Actor)
@@ -841,10 +896,11 @@ func Routes() *web.Route {
 				})
 				m.Group("/repository-id/{repository-id}", func() {
 					m.Get("", activitypub.ReqHTTPSignature(), activitypub.Repository)
-					m.Post("/inbox",
-						bind(forgefed.ForgeLike{}),
-						activitypub.ReqHTTPSignature(),
-						activitypub.RepositoryInbox)
+					m.Post("/inbox", activitypub.ReqHTTPSignature(), activitypub.RepositoryInbox)
+					m.Group("/issues/{index}", func() {
+						m.Get("", activitypub.RepositoryIssue)
+						m.Get("/comments/{comment-id}", activitypub.RepositoryIssueComment)
+					}, activitypub.ReqHTTPSignature())
 				}, context.RepositoryIDAssignmentAPI())
 			}, tokenRequiresScopes(auth_model.AccessTokenScopeCategoryActivityPub))
 		}
@@ -925,6 +981,15 @@ func Routes() *web.Route {
 			}, context.UserAssignmentAPI(), checkTokenPublicOnly())
 		}, tokenRequiresScopes(auth_model.AccessTokenScopeCategoryUser), reqToken())
 
+		// Applications authenticated with a JWT they signed
+		m.Group("/app", func() {
+			m.Get("", apps.GetApplication)
+			m.Group("/installations", func() {
+				m.Get("", apps.ListInstallations)
+				m.Post("/{id}/access_tokens", apps.CreateInstallationToken)
+			})
+		}, apps.ReqApplicationToken())
+
 		// Users (requires user scope)
 		m.Group("/user", func() {
 			m.Get("", user.GetAuthenticatedUser)
@@ -1245,6 +1310,11 @@ func Routes() *web.Route {
 						Delete(mustNotBeArchived, repo.DeletePushMirrorByRemoteName).
 						Get(repo.GetPushMirrorByName)
 				}, reqAdmin(), reqToken())
+				if setting.Federation.Enabled {
+					m.Combo("/federated_pulls", reqToken()).
+						Get(repo.ListFederatedPullRequests).
+						Post(reqRepoWriter(unit.TypeCode), mustNotBeArchived, bind(api.CreateFederatedPullRequestOption{}), repo.CreateFederatedPullRequest)
+				}
 
 				m.Get("/editorconfig/{filename}", context.ReferencesGitRepo(), context.RepoRefForAPI, reqRepoReader(unit.TypeCode), repo.GetEditorconfig)
 				m.Group("/pulls", func() {
@@ -1292,7 +1362,7 @@ func Routes() *web.Route {
 				}, mustAllowPulls, reqRepoReader(unit.TypeCode), context.ReferencesGitRepo())
 				m.Group("/statuses", func() {
 					m.Combo("/{sha}").Get(repo.GetCommitStatuses).
-						Post(reqToken(), reqRepoWriter(unit.TypeCode), bind(api.CreateStatusOption{}), repo.NewCommitStatus)
+						Post(reqToken(), reqCommitStatusWriter(), bind(api.CreateStatusOption{}), repo.NewCommitStatus)
 				}, reqRepoReader(unit.TypeCode))
 				m.Group("/commits", func() {
 					m.Get("", context.ReferencesGitRepo(), repo.GetAllCommits)
@@ -1489,12 +1559,35 @@ func Routes() *web.Route {
 					m.Get("", packages.GetPackage)
 					m.Delete("", reqToken(), reqPackageAccess(perm.AccessModeWrite), packages.DeletePackage)
 					m.Get("/files", packages.ListPackageFiles)
+					m.Get("/security", packages.GetPackageSecurityReport)
+					m.Get("/provenance", packages.GetPackageProvenance)
+					m.Post("/-/promote", reqToken(), bind(api.PromotePackageOption{}), packages.PromotePackage)
+					m.Post("/-/lock", reqToken(), reqPackageAccess(perm.AccessModeWrite), packages.LockPackage)
+					m.Get("/-/promotions", packages.ListPackagePromotions)
+				})
+
+				m.Group("/-/channels", func() {
+					m.Get("", packages.ListPackageChannels)
+					m.Combo("/{channel}").
+						Get(packages.GetPackageChannel).
+						Put(reqToken(), reqPackageAccess(perm.AccessModeWrite), bind(api.SetPackageChannelOption{}), packages.SetPackageChannel).
+						Delete(reqToken(), reqPackageAccess(perm.AccessModeWrite), packages.DeletePackageChannel)
 				})
 
 				m.Post("/-/link/{repo_name}", reqToken(), reqPackageAccess(perm.AccessModeWrite), packages.LinkPackage)
 				m.Post("/-/unlink", reqToken(), reqPackageAccess(perm.AccessModeWrite), packages.UnlinkPackage)
 			})
 
+			m.Group("/-/virtual", func() {
+				m.Combo("").
+					Get(packages.ListVirtualRegistries).
+					Post(bind(api.CreatePackageVirtualRegistryOption{}), packages.CreateVirtualRegistry)
+				m.Combo("/{type}/{name}").
+					Get(packages.GetVirtualRegistry).
+					Patch(bind(api.EditPackageVirtualRegistryOption{}), packages.EditVirtualRegistry).
+					Delete(packages.DeleteVirtualRegistry)
+			}, reqToken(), reqPackageAccess(perm.AccessModeAdmin))
+
 			m.Get("/", packages.ListPackages)
 		}, tokenRequiresScopes(auth_model.AccessTokenScopeCategoryPackage), context.UserAssignmentAPI(), context.PackageAssignmentAPI(), reqPackageAccess(perm.AccessModeRead), checkTokenPublicOnly())
 
@@ -1553,6 +1646,9 @@ func Routes() *web.Route {
 				m.Delete("", org.DeleteAvatar)
 			}, reqToken(), reqOrgOwnership())
 			m.Get("/activities/feeds", org.ListOrgActivityFeeds)
+			if setting.Audit.Enabled {
+				m.Get("/audit", reqToken(), reqOrgOwnership(), org.ListAuditEvents)
+			}
 
 			if setting.Quota.Enabled {
 				m.Group("/quota", func() {
@@ -1598,6 +1694,9 @@ func Routes() *web.Route {
 				m.Get("", admin.ListCronTasks)
 				m.Post("/{task}", admin.PostCronTask)
 			})
+			if setting.Audit.Enabled {
+				m.Get("/audit", admin.ListAuditEvents)
+			}
 			m.Get("/orgs", admin.GetAllOrgs)
 			m.Group("/users", func() {
 				m.Get("", admin.SearchUsers)
@@ -1609,6 +1708,7 @@ func Routes() *web.Route {
 						m.Post("", bind(api.CreateKeyOption{}), admin.CreatePublicKey)
 						m.Delete("/{id}", admin.DeleteUserPublicKey)
 					})
+					m.Delete("/sessions", admin.SignOutUser)
 					m.Get("/orgs", org.ListUserOrgs)
 					m.Post("/orgs", bind(api.CreateOrgOption{}), admin.CreateOrg)
 					m.Post("/repos", bind(api.CreateRepoOption{}), admin.CreateRepo)
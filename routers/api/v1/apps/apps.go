@@ -0,0 +1,157 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package apps
+
+import (
+	"net/http"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	user_model "forgejo.org/models/user"
+	api "forgejo.org/modules/structs"
+	"forgejo.org/routers/api/v1/utils"
+	"forgejo.org/services/context"
+	"forgejo.org/services/convert"
+)
+
+// ReqApplicationToken requires the request to be authenticated with a JWT signed by an application
+func ReqApplicationToken() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		if _, ok := ctx.Data["Application"].(*auth_model.OAuth2Application); !ok || ctx.Data["IsApplicationToken"] != true {
+			ctx.Error(http.StatusUnauthorized, "reqApplicationToken", "a JWT signed by the application is required")
+			return
+		}
+	}
+}
+
+func application(ctx *context.APIContext) *auth_model.OAuth2Application {
+	return ctx.Data["Application"].(*auth_model.OAuth2Application)
+}
+
+// GetApplication returns the application the request is authenticated for
+func GetApplication(ctx *context.APIContext) {
+	// swagger:operation GET /app application appGetApplication
+	// ---
+	// summary: Get the application authenticated with a JWT it signed
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/InstallableApplication"
+	//   "401":
+	//     "$ref": "#/responses/unauthorized"
+
+	app, err := convert.ToInstallableApplication(ctx, application(ctx))
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, app)
+}
+
+// ListInstallations lists the installations of the application
+func ListInstallations(ctx *context.APIContext) {
+	// swagger:operation GET /app/installations application appListInstallations
+	// ---
+	// summary: List the installations of the application authenticated with a JWT it signed
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ApplicationInstallationList"
+	//   "401":
+	//     "$ref": "#/responses/unauthorized"
+
+	insts, count, err := db.FindAndCount[auth_model.OAuth2ApplicationInstallation](ctx, auth_model.FindOAuth2ApplicationInstallationsOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		AppID:       application(ctx).ID,
+	})
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	ownerIDs := make([]int64, 0, len(insts))
+	for _, inst := range insts {
+		ownerIDs = append(ownerIDs, inst.OwnerID)
+	}
+	ownerList, err := user_model.GetUsersByIDs(ctx, ownerIDs)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	owners := make(map[int64]*user_model.User, len(ownerList))
+	for _, owner := range ownerList {
+		owners[owner.ID] = owner
+	}
+
+	apiInsts := make([]*api.ApplicationInstallation, 0, len(insts))
+	for _, inst := range insts {
+		if owner, ok := owners[inst.OwnerID]; ok {
+			apiInsts = append(apiInsts, convert.ToApplicationInstallation(ctx, inst, owner))
+		}
+	}
+
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiInsts)
+}
+
+// CreateInstallationToken mints a token to act for the application in one of its installations
+func CreateInstallationToken(ctx *context.APIContext) {
+	// swagger:operation POST /app/installations/{id}/access_tokens application appCreateInstallationToken
+	// ---
+	// summary: Create a short-lived token to act for the application authenticated with a JWT it signed in one of its installations
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the installation
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/ApplicationInstallationToken"
+	//   "401":
+	//     "$ref": "#/responses/unauthorized"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	inst, err := auth_model.GetOAuth2ApplicationInstallationByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if auth_model.IsErrOAuth2ApplicationInstallationNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.InternalServerError(err)
+		}
+		return
+	}
+	if inst.AppID != application(ctx).ID {
+		ctx.NotFound()
+		return
+	}
+	inst.App = application(ctx)
+
+	token, err := auth_model.CreateInstallationToken(ctx, inst)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, &api.ApplicationInstallationToken{
+		Token:         token.Token,
+		RepositoryIDs: inst.RepoIDs,
+		Permissions:   inst.Permissions.StringSlice(),
+		ExpiresAt:     token.ExpiresUnix.AsTime(),
+	})
+}
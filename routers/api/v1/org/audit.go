@@ -0,0 +1,61 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package org
+
+import (
+	"forgejo.org/routers/api/v1/shared"
+	"forgejo.org/services/context"
+)
+
+// ListAuditEvents lists the audit events of the organization and its repositories
+func ListAuditEvents(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/audit organization orgListAuditEvents
+	// ---
+	// summary: List the audit events of an organization and its repositories
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: action
+	//   in: query
+	//   description: only list events of this action
+	//   type: string
+	// - name: actor
+	//   in: query
+	//   description: only list events of the actor with this name
+	//   type: string
+	// - name: since
+	//   in: query
+	//   description: only list events recorded after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: only list events recorded before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AuditEventList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	shared.ListAuditEvents(ctx, ctx.Org.Organization.ID)
+}
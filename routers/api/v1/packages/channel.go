@@ -0,0 +1,514 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package packages
+
+import (
+	"errors"
+	"net/http"
+
+	packages_model "forgejo.org/models/packages"
+	"forgejo.org/models/perm"
+	user_model "forgejo.org/models/user"
+	api "forgejo.org/modules/structs"
+	"forgejo.org/modules/util"
+	"forgejo.org/modules/web"
+	"forgejo.org/services/context"
+	"forgejo.org/services/convert"
+	packages_service "forgejo.org/services/packages"
+)
+
+func getUserOrGhost(ctx *context.APIContext, id int64) (*user_model.User, error) {
+	u, err := user_model.GetPossibleUserByID(ctx, id)
+	if err != nil {
+		if user_model.IsErrUserNotExist(err) {
+			return user_model.NewGhostUser(), nil
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+func getPackageFromParams(ctx *context.APIContext) *packages_model.Package {
+	p, err := packages_model.GetPackageByName(ctx, ctx.ContextUser.ID, packages_model.Type(ctx.PathParamRaw("type")), ctx.PathParamRaw("name"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.Error(http.StatusNotFound, "GetPackageByName", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPackageByName", err)
+		}
+		return nil
+	}
+	return p
+}
+
+func toAPIPackageChannel(ctx *context.APIContext, pc *packages_model.PackageChannel) (*api.PackageChannel, error) {
+	pv, err := packages_model.GetVersionByID(ctx, pc.VersionID)
+	if err != nil {
+		return nil, err
+	}
+	promoter, err := getUserOrGhost(ctx, pc.PromotedByID)
+	if err != nil {
+		return nil, err
+	}
+	return convert.ToPackageChannel(ctx, pc, pv, promoter, ctx.Doer), nil
+}
+
+// ListPackageChannels lists the channels of a package
+func ListPackageChannels(ctx *context.APIContext) {
+	// swagger:operation GET /packages/{owner}/{type}/{name}/-/channels package listPackageChannels
+	// ---
+	// summary: Lists the channels of a package
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the package
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the package
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the package
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackageChannelList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getPackageFromParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	pcs, err := packages_model.GetChannelsByPackageID(ctx, p.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetChannelsByPackageID", err)
+		return
+	}
+
+	apiChannels := make([]*api.PackageChannel, 0, len(pcs))
+	for _, pc := range pcs {
+		apiChannel, err := toAPIPackageChannel(ctx, pc)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "toAPIPackageChannel", err)
+			return
+		}
+		apiChannels = append(apiChannels, apiChannel)
+	}
+
+	ctx.JSON(http.StatusOK, apiChannels)
+}
+
+// GetPackageChannel gets a channel of a package
+func GetPackageChannel(ctx *context.APIContext) {
+	// swagger:operation GET /packages/{owner}/{type}/{name}/-/channels/{channel} package getPackageChannel
+	// ---
+	// summary: Gets a channel of a package
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the package
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the package
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the package
+	//   type: string
+	//   required: true
+	// - name: channel
+	//   in: path
+	//   description: name of the channel
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackageChannel"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getPackageFromParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	pc, err := packages_model.GetChannel(ctx, p.ID, ctx.PathParamRaw("channel"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetChannel", err)
+		}
+		return
+	}
+
+	apiChannel, err := toAPIPackageChannel(ctx, pc)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "toAPIPackageChannel", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiChannel)
+}
+
+// SetPackageChannel points a channel of a package to a version
+func SetPackageChannel(ctx *context.APIContext) {
+	// swagger:operation PUT /packages/{owner}/{type}/{name}/-/channels/{channel} package setPackageChannel
+	// ---
+	// summary: Points a channel of a package to a version
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the package
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the package
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the package
+	//   type: string
+	//   required: true
+	// - name: channel
+	//   in: path
+	//   description: name of the channel
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/SetPackageChannelOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackageChannel"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.SetPackageChannelOption)
+
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.ContextUser.ID, packages_model.Type(ctx.PathParamRaw("type")), ctx.PathParamRaw("name"), form.Version)
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.Error(http.StatusNotFound, "GetVersionByNameAndVersion", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetVersionByNameAndVersion", err)
+		}
+		return
+	}
+
+	pd, err := packages_model.GetPackageDescriptor(ctx, pv)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPackageDescriptor", err)
+		return
+	}
+
+	if err := packages_service.SetPackageChannel(ctx, ctx.Doer, pd, ctx.PathParamRaw("channel")); err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Error(http.StatusUnprocessableEntity, "SetPackageChannel", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "SetPackageChannel", err)
+		}
+		return
+	}
+
+	pc, err := packages_model.GetChannel(ctx, pd.Package.ID, ctx.PathParamRaw("channel"))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetChannel", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToPackageChannel(ctx, pc, pv, ctx.Doer, ctx.Doer))
+}
+
+// DeletePackageChannel deletes a channel of a package
+func DeletePackageChannel(ctx *context.APIContext) {
+	// swagger:operation DELETE /packages/{owner}/{type}/{name}/-/channels/{channel} package deletePackageChannel
+	// ---
+	// summary: Deletes a channel of a package
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the package
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the package
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the package
+	//   type: string
+	//   required: true
+	// - name: channel
+	//   in: path
+	//   description: name of the channel
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getPackageFromParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := packages_model.DeleteChannel(ctx, p.ID, ctx.PathParamRaw("channel")); err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DeleteChannel", err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// PromotePackage promotes a package version to a channel and/or another owner
+func PromotePackage(ctx *context.APIContext) {
+	// swagger:operation POST /packages/{owner}/{type}/{name}/{version}/-/promote package promotePackage
+	// ---
+	// summary: Promotes a package version to a channel and/or another owner without re-uploading it
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the package
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the package
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the package
+	//   type: string
+	//   required: true
+	// - name: version
+	//   in: path
+	//   description: version of the package
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/PromotePackageOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Package"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/conflict"
+	//   "413":
+	//     "$ref": "#/responses/quotaExceeded"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.PromotePackageOption)
+
+	target := ctx.Package.Owner
+	if form.TargetOwner != "" {
+		var err error
+		target, err = user_model.GetUserByName(ctx, form.TargetOwner)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				ctx.Error(http.StatusNotFound, "GetUserByName", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			}
+			return
+		}
+	}
+
+	accessMode, err := context.DeterminePackageAccessMode(ctx.Base, target, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeterminePackageAccessMode", err)
+		return
+	}
+	if accessMode < perm.AccessModeWrite && !ctx.IsUserSiteAdmin() {
+		ctx.Error(http.StatusForbidden, "PromotePackage", "user should have write permission on the packages of the target owner")
+		return
+	}
+
+	pv, err := packages_service.PromotePackageVersion(ctx, ctx.Package.Descriptor, &packages_service.PromotionOptions{
+		Doer:        ctx.Doer,
+		TargetOwner: target,
+		Channel:     form.Channel,
+		Lock:        form.Lock,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, util.ErrInvalidArgument):
+			ctx.Error(http.StatusUnprocessableEntity, "PromotePackageVersion", err)
+		case errors.Is(err, packages_model.ErrDuplicatePackageVersion):
+			ctx.Error(http.StatusConflict, "PromotePackageVersion", err)
+		case errors.Is(err, packages_service.ErrQuotaTotalCount), errors.Is(err, packages_service.ErrQuotaTypeSize), errors.Is(err, packages_service.ErrQuotaTotalSize):
+			ctx.Error(http.StatusRequestEntityTooLarge, "PromotePackageVersion", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "PromotePackageVersion", err)
+		}
+		return
+	}
+
+	pd, err := packages_model.GetPackageDescriptor(ctx, pv)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPackageDescriptor", err)
+		return
+	}
+
+	apiPackage, err := convert.ToPackage(ctx, pd, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Error converting package for api", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiPackage)
+}
+
+// LockPackage makes a package version immutable
+func LockPackage(ctx *context.APIContext) {
+	// swagger:operation POST /packages/{owner}/{type}/{name}/{version}/-/lock package lockPackage
+	// ---
+	// summary: Makes a package version immutable so that it can't be overwritten or deleted
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the package
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the package
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the package
+	//   type: string
+	//   required: true
+	// - name: version
+	//   in: path
+	//   description: version of the package
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Package"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if err := packages_service.LockPackageVersion(ctx, ctx.Package.Descriptor.Version); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LockPackageVersion", err)
+		return
+	}
+
+	apiPackage, err := convert.ToPackage(ctx, ctx.Package.Descriptor, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Error converting package for api", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiPackage)
+}
+
+// ListPackagePromotions lists the recorded promotions of a package version
+func ListPackagePromotions(ctx *context.APIContext) {
+	// swagger:operation GET /packages/{owner}/{type}/{name}/{version}/-/promotions package listPackagePromotions
+	// ---
+	// summary: Lists the recorded promotions of a package version
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the package
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the package
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the package
+	//   type: string
+	//   required: true
+	// - name: version
+	//   in: path
+	//   description: version of the package
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackagePromotionList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pps, err := packages_model.GetPromotionsByVersionID(ctx, ctx.Package.Descriptor.Version.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPromotionsByVersionID", err)
+		return
+	}
+
+	apiPromotions := make([]*api.PackagePromotion, 0, len(pps))
+	for _, pp := range pps {
+		sourceOwner, err := getUserOrGhost(ctx, pp.SourceOwnerID)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "getUserOrGhost", err)
+			return
+		}
+		promoter, err := getUserOrGhost(ctx, pp.DoerID)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "getUserOrGhost", err)
+			return
+		}
+		apiPromotions = append(apiPromotions, convert.ToPackagePromotion(ctx, pp, sourceOwner, promoter, ctx.Doer))
+	}
+
+	ctx.JSON(http.StatusOK, apiPromotions)
+}
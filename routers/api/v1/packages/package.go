@@ -8,6 +8,7 @@ import (
 	"net/http"
 
 	"forgejo.org/models/packages"
+	access_model "forgejo.org/models/perm/access"
 	repo_model "forgejo.org/models/repo"
 	"forgejo.org/modules/optional"
 	api "forgejo.org/modules/structs"
@@ -16,6 +17,7 @@ import (
 	"forgejo.org/services/context"
 	"forgejo.org/services/convert"
 	packages_service "forgejo.org/services/packages"
+	packages_scan "forgejo.org/services/packages/scan"
 )
 
 // ListPackages gets all packages of an owner
@@ -43,7 +45,7 @@ func ListPackages(ctx *context.APIContext) {
 	//   in: query
 	//   description: package type filter
 	//   type: string
-	//   enum: [alpine, cargo, chef, composer, conan, conda, container, cran, debian, generic, go, helm, maven, npm, nuget, pub, pypi, rpm, rubygems, swift, vagrant]
+	//   enum: [alpine, cargo, chef, composer, conan, conda, container, cran, debian, galaxy, generic, go, helm, hex, maven, npm, nuget, pub, pypi, rpm, rubygems, swift, vagrant]
 	// - name: q
 	//   in: query
 	//   description: name filter
@@ -166,9 +168,15 @@ func DeletePackage(ctx *context.APIContext) {
 	//     "$ref": "#/responses/empty"
 	//   "404":
 	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/conflict"
 
 	err := packages_service.RemovePackageVersion(ctx, ctx.Doer, ctx.Package.Descriptor.Version)
 	if err != nil {
+		if errors.Is(err, packages.ErrPackageVersionImmutable) {
+			ctx.Error(http.StatusConflict, "RemovePackageVersion", err)
+			return
+		}
 		ctx.Error(http.StatusInternalServerError, "RemovePackageVersion", err)
 		return
 	}
@@ -217,6 +225,113 @@ func ListPackageFiles(ctx *context.APIContext) {
 	ctx.JSON(http.StatusOK, apiPackageFiles)
 }
 
+// GetPackageSecurityReport gets the detected licenses and vulnerable dependencies of a package
+func GetPackageSecurityReport(ctx *context.APIContext) {
+	// swagger:operation GET /packages/{owner}/{type}/{name}/{version}/security package getPackageSecurityReport
+	// ---
+	// summary: Gets the detected licenses and vulnerable dependencies of a package
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the package
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the package
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the package
+	//   type: string
+	//   required: true
+	// - name: version
+	//   in: path
+	//   description: version of the package
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackageSecurityReport"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pd := ctx.Package.Descriptor
+
+	vulnerabilities, err := packages.GetVulnerabilitiesByVersionID(ctx, pd.Version.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetVulnerabilitiesByVersionID", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToPackageSecurityReport(packages_scan.Licenses(pd), vulnerabilities))
+}
+
+// GetPackageProvenance gets the build provenance of a package published by an Actions run
+func GetPackageProvenance(ctx *context.APIContext) {
+	// swagger:operation GET /packages/{owner}/{type}/{name}/{version}/provenance package getPackageProvenance
+	// ---
+	// summary: Gets the SLSA build provenance of a package published by an Actions run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the package
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the package
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the package
+	//   type: string
+	//   required: true
+	// - name: version
+	//   in: path
+	//   description: version of the package
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackageProvenance"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pd := ctx.Package.Descriptor
+
+	provenance, err := packages_service.GetVersionProvenance(ctx, pd.Version)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetVersionProvenance", err)
+		return
+	}
+	if provenance == nil {
+		ctx.NotFound()
+		return
+	}
+
+	// the provenance discloses the repository, so it's only visible to users with access to it
+	if provenance.Repository != nil {
+		permission, err := access_model.GetUserRepoPermission(ctx, provenance.Repository, ctx.Doer)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+			return
+		}
+		if !permission.HasAccess() {
+			ctx.NotFound()
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToPackageProvenance(pd, provenance))
+}
+
 // LinkPackage sets a repository link for a package
 func LinkPackage(ctx *context.APIContext) {
 	// swagger:operation POST /packages/{owner}/{type}/{name}/-/link/{repo_name} package linkPackage
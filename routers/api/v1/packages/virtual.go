@@ -0,0 +1,310 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package packages
+
+import (
+	"errors"
+	"net/http"
+
+	packages_model "forgejo.org/models/packages"
+	user_model "forgejo.org/models/user"
+	api "forgejo.org/modules/structs"
+	"forgejo.org/modules/util"
+	"forgejo.org/modules/web"
+	"forgejo.org/services/context"
+	"forgejo.org/services/convert"
+	virtual_service "forgejo.org/services/packages/virtual"
+)
+
+// ListVirtualRegistries lists the virtual registries of an owner
+func ListVirtualRegistries(ctx *context.APIContext) {
+	// swagger:operation GET /packages/{owner}/-/virtual package listPackageVirtualRegistries
+	// ---
+	// summary: List the virtual package registries of an owner
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the virtual registries
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackageVirtualRegistryList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	vrs, err := packages_model.GetVirtualRegistriesByOwner(ctx, ctx.ContextUser.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetVirtualRegistriesByOwner", err)
+		return
+	}
+
+	apiRegistries := make([]*api.PackageVirtualRegistry, 0, len(vrs))
+	for _, vr := range vrs {
+		members, err := virtual_service.GetMembers(ctx, vr)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetMembers", err)
+			return
+		}
+		apiRegistries = append(apiRegistries, convert.ToPackageVirtualRegistry(ctx.ContextUser, vr, members))
+	}
+
+	ctx.JSON(http.StatusOK, apiRegistries)
+}
+
+// CreateVirtualRegistry creates a virtual registry
+func CreateVirtualRegistry(ctx *context.APIContext) {
+	// swagger:operation POST /packages/{owner}/-/virtual package createPackageVirtualRegistry
+	// ---
+	// summary: Create a virtual package registry
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the virtual registry
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreatePackageVirtualRegistryOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/PackageVirtualRegistry"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/conflict"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreatePackageVirtualRegistryOption)
+
+	members, ok := lookupMembers(ctx, form.Members)
+	if !ok {
+		return
+	}
+
+	vr, err := virtual_service.CreateRegistry(ctx, ctx.ContextUser, &virtual_service.RegistryOptions{
+		Name:        form.Name,
+		Type:        packages_model.Type(form.Type),
+		Members:     members,
+		UpstreamURL: form.UpstreamURL,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, util.ErrInvalidArgument):
+			ctx.Error(http.StatusUnprocessableEntity, "CreateRegistry", err)
+		case errors.Is(err, util.ErrAlreadyExist):
+			ctx.Error(http.StatusConflict, "CreateRegistry", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "CreateRegistry", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, convert.ToPackageVirtualRegistry(ctx.ContextUser, vr, members))
+}
+
+// GetVirtualRegistry gets a virtual registry
+func GetVirtualRegistry(ctx *context.APIContext) {
+	// swagger:operation GET /packages/{owner}/-/virtual/{type}/{name} package getPackageVirtualRegistry
+	// ---
+	// summary: Get a virtual package registry
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the virtual registry
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: package type of the virtual registry
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the virtual registry
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackageVirtualRegistry"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	vr := getVirtualRegistry(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	members, err := virtual_service.GetMembers(ctx, vr)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetMembers", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToPackageVirtualRegistry(ctx.ContextUser, vr, members))
+}
+
+// EditVirtualRegistry changes the members or the upstream of a virtual registry
+func EditVirtualRegistry(ctx *context.APIContext) {
+	// swagger:operation PATCH /packages/{owner}/-/virtual/{type}/{name} package editPackageVirtualRegistry
+	// ---
+	// summary: Edit a virtual package registry
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the virtual registry
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: package type of the virtual registry
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the virtual registry
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditPackageVirtualRegistryOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackageVirtualRegistry"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditPackageVirtualRegistryOption)
+
+	vr := getVirtualRegistry(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	opts := &virtual_service.RegistryOptions{
+		UpstreamURL: vr.UpstreamURL,
+	}
+	if form.UpstreamURL != nil {
+		opts.UpstreamURL = *form.UpstreamURL
+	}
+
+	var err error
+	if form.Members != nil {
+		var ok bool
+		if opts.Members, ok = lookupMembers(ctx, form.Members); !ok {
+			return
+		}
+	} else if opts.Members, err = virtual_service.GetMembers(ctx, vr); err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetMembers", err)
+		return
+	}
+
+	if err := virtual_service.UpdateRegistry(ctx, vr, opts); err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Error(http.StatusUnprocessableEntity, "UpdateRegistry", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UpdateRegistry", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToPackageVirtualRegistry(ctx.ContextUser, vr, opts.Members))
+}
+
+// DeleteVirtualRegistry deletes a virtual registry
+func DeleteVirtualRegistry(ctx *context.APIContext) {
+	// swagger:operation DELETE /packages/{owner}/-/virtual/{type}/{name} package deletePackageVirtualRegistry
+	// ---
+	// summary: Delete a virtual package registry
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the virtual registry
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: package type of the virtual registry
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the virtual registry
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	vr := getVirtualRegistry(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := packages_model.DeleteVirtualRegistryByID(ctx, vr.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteVirtualRegistryByID", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func getVirtualRegistry(ctx *context.APIContext) *packages_model.PackageVirtualRegistry {
+	vr, err := packages_model.GetVirtualRegistryByName(ctx, ctx.ContextUser.ID, packages_model.Type(ctx.Params("type")), ctx.Params("name"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetVirtualRegistryByName", err)
+		}
+		return nil
+	}
+	return vr
+}
+
+func lookupMembers(ctx *context.APIContext, names []string) ([]*user_model.User, bool) {
+	members := make([]*user_model.User, 0, len(names))
+	for _, name := range names {
+		u, err := user_model.GetUserByName(ctx, name)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "GetUserByName", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			}
+			return nil, false
+		}
+		members = append(members, u)
+	}
+	return members, true
+}
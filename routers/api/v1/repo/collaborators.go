@@ -8,6 +8,7 @@ import (
 	"errors"
 	"net/http"
 
+	audit_model "forgejo.org/models/audit"
 	"forgejo.org/models/db"
 	"forgejo.org/models/perm"
 	access_model "forgejo.org/models/perm/access"
@@ -17,6 +18,7 @@ import (
 	api "forgejo.org/modules/structs"
 	"forgejo.org/modules/web"
 	"forgejo.org/routers/api/v1/utils"
+	audit_service "forgejo.org/services/audit"
 	"forgejo.org/services/context"
 	"forgejo.org/services/convert"
 	repo_service "forgejo.org/services/repository"
@@ -193,12 +195,16 @@ func AddCollaborator(ctx *context.APIContext) {
 		return
 	}
 
+	mode := perm.AccessModeWrite
 	if form.Permission != nil {
-		if err := repo_model.ChangeCollaborationAccessMode(ctx, ctx.Repo.Repository, collaborator.ID, perm.ParseAccessMode(*form.Permission)); err != nil {
+		mode = perm.ParseAccessMode(*form.Permission)
+		if err := repo_model.ChangeCollaborationAccessMode(ctx, ctx.Repo.Repository, collaborator.ID, mode); err != nil {
 			ctx.Error(http.StatusInternalServerError, "ChangeCollaborationAccessMode", err)
 			return
 		}
 	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionRepoCollaboratorAdd, audit_service.RepoTarget(ctx.Repo.Repository),
+		nil, map[string]any{"collaborator": collaborator.Name, "access_mode": mode.String()})
 
 	ctx.Status(http.StatusNoContent)
 }
@@ -248,6 +254,8 @@ func DeleteCollaborator(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "DeleteCollaboration", err)
 		return
 	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionRepoCollaboratorRemove, audit_service.RepoTarget(ctx.Repo.Repository),
+		map[string]any{"collaborator": collaborator.Name}, nil)
 	ctx.Status(http.StatusNoContent)
 }
 
@@ -0,0 +1,116 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"errors"
+	"net/http"
+
+	issues_model "forgejo.org/models/issues"
+	api "forgejo.org/modules/structs"
+	"forgejo.org/modules/web"
+	"forgejo.org/routers/api/v1/utils"
+	"forgejo.org/services/context"
+	"forgejo.org/services/convert"
+	"forgejo.org/services/federation"
+)
+
+// ListFederatedPullRequests lists the pull requests offered from a repository to repositories of other instances
+func ListFederatedPullRequests(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/federated_pulls repository repoListFederatedPullRequests
+	// ---
+	// summary: List the pull requests offered to repositories of other instances
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/FederatedPullRequestList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	listOptions := utils.GetListOptions(ctx)
+	offers, count, err := issues_model.FindPullRequestOffers(ctx, ctx.Repo.Repository.ID, listOptions)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindPullRequestOffers", err)
+		return
+	}
+
+	apiOffers := make([]*api.FederatedPullRequest, 0, len(offers))
+	for _, offer := range offers {
+		apiOffers = append(apiOffers, convert.ToFederatedPullRequest(offer))
+	}
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiOffers)
+}
+
+// CreateFederatedPullRequest offers a branch as pull request to a repository of another instance
+func CreateFederatedPullRequest(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/federated_pulls repository repoCreateFederatedPullRequest
+	// ---
+	// summary: Offer a branch as pull request to a repository of another instance
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateFederatedPullRequestOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/FederatedPullRequest"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateFederatedPullRequestOption)
+	offer, err := federation.OfferPullRequest(ctx, ctx.Doer, ctx.Repo.Repository, federation.OfferPullRequestOptions{
+		HeadBranch:   form.Head,
+		TargetRepo:   form.Target,
+		TargetBranch: form.Base,
+		Title:        form.Title,
+		Content:      form.Body,
+	})
+	if err != nil {
+		if errors.Is(err, federation.ErrInvalidPullRequestOffer) {
+			ctx.Error(http.StatusUnprocessableEntity, "OfferPullRequest", err)
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "OfferPullRequest", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToFederatedPullRequest(offer))
+}
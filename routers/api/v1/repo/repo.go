@@ -32,6 +32,7 @@ import (
 	"forgejo.org/modules/web"
 	"forgejo.org/routers/api/v1/utils"
 	actions_service "forgejo.org/services/actions"
+	audit_service "forgejo.org/services/audit"
 	"forgejo.org/services/context"
 	"forgejo.org/services/convert"
 	"forgejo.org/services/issue"
@@ -773,6 +774,9 @@ func updateBasicProperties(ctx *context.APIContext, opts api.EditRepoOption) err
 		ctx.Error(http.StatusInternalServerError, "UpdateRepository", err)
 		return err
 	}
+	if visibilityChanged {
+		audit_service.RecordRepoVisibility(ctx, ctx.Doer, repo)
+	}
 
 	log.Trace("Repository basic settings updated: %s/%s", owner.Name, repo.Name)
 	return nil
@@ -0,0 +1,44 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package shared
+
+import (
+	"net/http"
+
+	audit_model "forgejo.org/models/audit"
+	"forgejo.org/models/db"
+	api "forgejo.org/modules/structs"
+	"forgejo.org/modules/timeutil"
+	"forgejo.org/routers/api/v1/utils"
+	"forgejo.org/services/context"
+	"forgejo.org/services/convert"
+)
+
+// ListAuditEvents lists the audit events of the owner, or of the whole instance if ownerID is 0
+func ListAuditEvents(ctx *context.APIContext, ownerID int64) {
+	before, since, err := context.GetQueryBeforeSince(ctx.Base)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetQueryBeforeSince", err)
+		return
+	}
+	events, total, err := db.FindAndCount[audit_model.Event](ctx, audit_model.FindEventsOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		Action:      audit_model.Action(ctx.FormTrim("action")),
+		ActorName:   ctx.FormTrim("actor"),
+		OwnerID:     ownerID,
+		Since:       timeutil.TimeStamp(since),
+		Before:      timeutil.TimeStamp(before),
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindAuditEvents", err)
+		return
+	}
+
+	apiEvents := make([]*api.AuditEvent, len(events))
+	for i, e := range events {
+		apiEvents[i] = convert.ToAuditEvent(e)
+	}
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, apiEvents)
+}
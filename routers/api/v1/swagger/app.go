@@ -20,3 +20,24 @@ type swaggerResponseAccessToken struct {
 	// in:body
 	Body api.AccessToken `json:"body"`
 }
+
+// InstallableApplication
+// swagger:response InstallableApplication
+type swaggerResponseInstallableApplication struct {
+	// in:body
+	Body api.InstallableApplication `json:"body"`
+}
+
+// ApplicationInstallationList
+// swagger:response ApplicationInstallationList
+type swaggerResponseApplicationInstallationList struct {
+	// in:body
+	Body []api.ApplicationInstallation `json:"body"`
+}
+
+// ApplicationInstallationToken
+// swagger:response ApplicationInstallationToken
+type swaggerResponseApplicationInstallationToken struct {
+	// in:body
+	Body api.ApplicationInstallationToken `json:"body"`
+}
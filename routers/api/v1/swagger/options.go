@@ -204,6 +204,9 @@ type swaggerParameterBodies struct {
 	// in:body
 	CreatePushMirrorOption api.CreatePushMirrorOption
 
+	// in:body
+	CreateFederatedPullRequestOption api.CreateFederatedPullRequestOption
+
 	// in:body
 	UpdateUserAvatarOptions api.UpdateUserAvatarOption
 
@@ -239,4 +242,16 @@ type swaggerParameterBodies struct {
 
 	// in:body
 	NoteOptions api.NoteOptions
+
+	// in:body
+	CreatePackageVirtualRegistryOption api.CreatePackageVirtualRegistryOption
+
+	// in:body
+	EditPackageVirtualRegistryOption api.EditPackageVirtualRegistryOption
+
+	// in:body
+	SetPackageChannelOption api.SetPackageChannelOption
+
+	// in:body
+	PromotePackageOption api.PromotePackageOption
 }
@@ -27,3 +27,52 @@ type swaggerResponsePackageFileList struct {
 	// in:body
 	Body []api.PackageFile `json:"body"`
 }
+
+// PackageChannel
+// swagger:response PackageChannel
+type swaggerResponsePackageChannel struct {
+	// in:body
+	Body api.PackageChannel `json:"body"`
+}
+
+// PackageChannelList
+// swagger:response PackageChannelList
+type swaggerResponsePackageChannelList struct {
+	// in:body
+	Body []api.PackageChannel `json:"body"`
+}
+
+// PackagePromotionList
+// swagger:response PackagePromotionList
+type swaggerResponsePackagePromotionList struct {
+	// in:body
+	Body []api.PackagePromotion `json:"body"`
+}
+
+// PackageProvenance
+// swagger:response PackageProvenance
+type swaggerResponsePackageProvenance struct {
+	// in:body
+	Body api.PackageProvenance `json:"body"`
+}
+
+// PackageSecurityReport
+// swagger:response PackageSecurityReport
+type swaggerResponsePackageSecurityReport struct {
+	// in:body
+	Body api.PackageSecurityReport `json:"body"`
+}
+
+// PackageVirtualRegistry
+// swagger:response PackageVirtualRegistry
+type swaggerResponsePackageVirtualRegistry struct {
+	// in:body
+	Body api.PackageVirtualRegistry `json:"body"`
+}
+
+// PackageVirtualRegistryList
+// swagger:response PackageVirtualRegistryList
+type swaggerResponsePackageVirtualRegistryList struct {
+	// in:body
+	Body []api.PackageVirtualRegistry `json:"body"`
+}
@@ -401,6 +401,20 @@ type swaggerPushMirrorList struct {
 	Body []api.PushMirror `json:"body"`
 }
 
+// FederatedPullRequest
+// swagger:response FederatedPullRequest
+type swaggerFederatedPullRequest struct {
+	// in:body
+	Body api.FederatedPullRequest `json:"body"`
+}
+
+// FederatedPullRequestList
+// swagger:response FederatedPullRequestList
+type swaggerFederatedPullRequestList struct {
+	// in:body
+	Body []api.FederatedPullRequest `json:"body"`
+}
+
 // RepoCollaboratorPermission
 // swagger:response RepoCollaboratorPermission
 type swaggerRepoCollaboratorPermission struct {
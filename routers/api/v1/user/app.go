@@ -11,11 +11,13 @@ import (
 	"strconv"
 	"strings"
 
+	audit_model "forgejo.org/models/audit"
 	auth_model "forgejo.org/models/auth"
 	"forgejo.org/models/db"
 	api "forgejo.org/modules/structs"
 	"forgejo.org/modules/web"
 	"forgejo.org/routers/api/v1/utils"
+	audit_service "forgejo.org/services/audit"
 	"forgejo.org/services/context"
 	"forgejo.org/services/convert"
 )
@@ -132,6 +134,7 @@ func CreateAccessToken(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "NewAccessToken", err)
 		return
 	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionUserAccessTokenCreate, audit_service.AccessTokenTarget(t), nil, map[string]any{"scope": t.Scope})
 	ctx.JSON(http.StatusCreated, &api.AccessToken{
 		Name:           t.Name,
 		Token:          t.Token,
@@ -206,6 +209,7 @@ func DeleteAccessToken(ctx *context.APIContext) {
 		}
 		return
 	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionUserAccessTokenDelete, audit_service.AccessTokenTarget(&auth_model.AccessToken{ID: tokenID, UID: ctx.ContextUser.ID}), nil, nil)
 
 	ctx.Status(http.StatusNoContent)
 }
@@ -14,6 +14,7 @@ import (
 	"forgejo.org/modules/setting"
 	"forgejo.org/modules/web/middleware"
 	"forgejo.org/modules/web/routing"
+	"forgejo.org/services/audit"
 	"forgejo.org/services/context"
 
 	"code.forgejo.org/go-chi/session"
@@ -64,6 +65,13 @@ func ProtocolMiddlewares() (handlers []any) {
 		handlers = append(handlers, proxy.ForwardedHeaders(opt))
 	}
 
+	// remember the address of the client, it is only known after the forwarded headers were applied
+	handlers = append(handlers, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(resp, req.WithContext(audit.WithRemoteAddress(req.Context(), req.RemoteAddr)))
+		})
+	})
+
 	if setting.IsRouteLogEnabled() {
 		handlers = append(handlers, routing.NewLoggerHandler())
 	}
@@ -29,15 +29,18 @@ import (
 	actions_router "forgejo.org/routers/api/actions"
 	forgejo "forgejo.org/routers/api/forgejo/v1"
 	packages_router "forgejo.org/routers/api/packages"
+	scim_router "forgejo.org/routers/api/scim"
 	apiv1 "forgejo.org/routers/api/v1"
 	"forgejo.org/routers/common"
 	"forgejo.org/routers/private"
 	web_routers "forgejo.org/routers/web"
 	actions_service "forgejo.org/services/actions"
+	audit_service "forgejo.org/services/audit"
 	"forgejo.org/services/auth"
 	"forgejo.org/services/auth/source/oauth2"
 	"forgejo.org/services/automerge"
 	"forgejo.org/services/cron"
+	federation_service "forgejo.org/services/federation"
 	feed_service "forgejo.org/services/feed"
 	indexer_service "forgejo.org/services/indexer"
 	"forgejo.org/services/mailer"
@@ -45,10 +48,12 @@ import (
 	markup_service "forgejo.org/services/markup"
 	repo_migrations "forgejo.org/services/migrations"
 	mirror_service "forgejo.org/services/mirror"
+	packages_scan "forgejo.org/services/packages/scan"
 	pull_service "forgejo.org/services/pull"
 	release_service "forgejo.org/services/release"
 	repo_service "forgejo.org/services/repository"
 	"forgejo.org/services/repository/archiver"
+	secretscan_service "forgejo.org/services/secretscan"
 	"forgejo.org/services/task"
 	"forgejo.org/services/uinotification"
 	"forgejo.org/services/webhook"
@@ -155,6 +160,11 @@ func InitWebInstalled(ctx context.Context) {
 	mustInit(automerge.Init)
 	mustInit(task.Init)
 	mustInit(repo_migrations.Init)
+	mustInit(packages_scan.Init)
+	federation_service.SetCloneURLCheck(repo_migrations.IsMigrateURLAllowed)
+	mustInit(federation_service.Init)
+	mustInit(audit_service.Init)
+	mustInit(secretscan_service.Init)
 	eventsource.GetManager().Init()
 	mustInitCtx(ctx, mailer_incoming.Init)
 
@@ -204,5 +214,10 @@ func NormalRoutes() *web.Route {
 		r.Mount(prefix, actions_router.ArtifactsV4Routes(prefix))
 	}
 
+	if setting.SCIM.Enabled {
+		// This implements user and team provisioning by identity providers
+		r.Mount("/scim/v2", scim_router.Routes())
+	}
+
 	return r
 }
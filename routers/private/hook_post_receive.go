@@ -44,6 +44,8 @@ func HookPostReceive(ctx *gitea_context.PrivateContext) {
 	ownerName := ctx.Params(":owner")
 	repoName := ctx.Params(":repo")
 
+	revokePushedAccessTokens(ctx, opts, ownerName, repoName)
+
 	// defer getting the repository at this point - as we should only retrieve it if we're going to call update
 	var (
 		repo    *repo_model.Repository
@@ -219,6 +219,10 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 		}
 	}
 
+	if !ourCtx.assertNoSecrets() {
+		return
+	}
+
 	ctx.PlainText(http.StatusOK, "ok")
 }
 
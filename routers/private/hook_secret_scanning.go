@@ -0,0 +1,120 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package private
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	repo_model "forgejo.org/models/repo"
+	"forgejo.org/modules/git/pushoptions"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/private"
+	"forgejo.org/modules/secretscan"
+	"forgejo.org/modules/setting"
+	gitea_context "forgejo.org/services/context"
+	secretscan_service "forgejo.org/services/secretscan"
+)
+
+// assertNoSecrets rejects the push if the pushed commits add secrets to the repository,
+// unless a repository administrator asked to bypass the push protection.
+// The access tokens pushed to public repositories are recorded and revoked by the post-receive hook,
+// a push which is rejected later on does not publish them.
+func (ctx *preReceiveContext) assertNoSecrets() bool {
+	// the commits of merged pull requests have been scanned when they were pushed, wikis are not scanned
+	if ctx.opts.PullRequestID != 0 || ctx.opts.IsWiki {
+		return true
+	}
+
+	repo := ctx.Repo.Repository
+	pushProtection := setting.SecretScanning.Enabled && setting.SecretScanning.PushProtection
+	revokeTokens := setting.SecretScanning.RevokeLeakedTokens && secretscan_service.IsPublic(ctx, repo)
+	if !pushProtection && !revokeTokens {
+		return true
+	}
+	var detectors []*secretscan.Detector
+	if pushProtection {
+		detectors = secretscan_service.Detectors()
+	}
+	if revokeTokens && !slices.Contains(detectors, secretscan.AccessTokenDetector) {
+		detectors = append(detectors, secretscan.AccessTokenDetector)
+	}
+
+	emptyCommitID := ctx.Repo.GetObjectFormat().EmptyObjectID().String()
+	newCommitIDs := make([]string, 0, len(ctx.opts.NewCommitIDs))
+	for _, id := range ctx.opts.NewCommitIDs {
+		if id != emptyCommitID {
+			newCommitIDs = append(newCommitIDs, id)
+		}
+	}
+	if len(newCommitIDs) == 0 {
+		return true
+	}
+
+	findings, err := secretscan_service.ScanPush(ctx, repo, newCommitIDs, ctx.env, detectors)
+	if err != nil {
+		log.Error("Unable to scan the commits pushed to %-v for secrets: %v", repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: fmt.Sprintf("Unable to scan the commits pushed to %s for secrets: %v", repo.FullName(), err),
+		})
+		return false
+	}
+	if pushProtection && len(findings) > 0 && !ctx.bypassSecretScanning(findings) {
+		return false
+	}
+	if revokeTokens {
+		if err := secretscan_service.RecordPushedAccessTokens(ctx, repo, newCommitIDs, findings); err != nil {
+			log.Error("Unable to record the access tokens pushed to %-v: %v", repo, err)
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: fmt.Sprintf("Unable to record the access tokens pushed to %s: %v", repo.FullName(), err),
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// revokePushedAccessTokens revokes the access tokens the pre-receive hook found in the accepted push
+func revokePushedAccessTokens(ctx *gitea_context.PrivateContext, opts *private.HookOptions, ownerName, repoName string) {
+	if !setting.SecretScanning.RevokeLeakedTokens || opts.PullRequestID != 0 || opts.IsWiki {
+		return
+	}
+	repo, err := repo_model.GetRepositoryByOwnerAndName(ctx, ownerName, repoName)
+	if err != nil {
+		log.Error("Unable to revoke the access tokens pushed to %s/%s: %v", ownerName, repoName, err)
+		return
+	}
+	secretscan_service.RevokePushedAccessTokens(ctx, repo, opts.NewCommitIDs)
+}
+
+// bypassSecretScanning records the secrets found in the push if the pusher is allowed to bypass the push protection,
+// otherwise it rejects the push
+func (ctx *preReceiveContext) bypassSecretScanning(findings []*secretscan_service.Finding) bool {
+	repo := ctx.Repo.Repository
+	if !ctx.opts.GetGitPushOptions().GetBool(pushoptions.SecretScanningBypass, false) {
+		ctx.JSON(http.StatusForbidden, private.Response{
+			UserMsg: secretscan_service.RejectionMessage(findings),
+		})
+		return false
+	}
+
+	if !ctx.loadPusherAndPermission() {
+		return false
+	}
+	if !ctx.userPerm.IsAdmin() {
+		ctx.JSON(http.StatusForbidden, private.Response{
+			UserMsg: "Only repository administrators are allowed to bypass the secret scanning push protection.",
+		})
+		return false
+	}
+	if err := secretscan_service.RecordAlerts(ctx, repo, findings, ctx.user.ID); err != nil {
+		log.Error("Unable to record the secrets pushed to %-v: %v", repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: fmt.Sprintf("Unable to record the secrets pushed to %s: %v", repo.FullName(), err),
+		})
+		return false
+	}
+	return true
+}
@@ -4,11 +4,13 @@
 package private
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
 	asymkey_model "forgejo.org/models/asymkey"
+	audit_model "forgejo.org/models/audit"
 	"forgejo.org/models/perm"
 	access_model "forgejo.org/models/perm/access"
 	repo_model "forgejo.org/models/repo"
@@ -18,24 +20,66 @@ import (
 	"forgejo.org/modules/log"
 	"forgejo.org/modules/private"
 	"forgejo.org/modules/setting"
+	"forgejo.org/modules/util"
+	audit_service "forgejo.org/services/audit"
 	"forgejo.org/services/context"
 	repo_service "forgejo.org/services/repository"
 	wiki_service "forgejo.org/services/wiki"
 )
 
+// getServKey returns the public key the ssh session authenticated with. Certificates which a trusted certificate
+// authority mapped to a user are represented by a principal key of the user named after the certificate authority.
+func getServKey(ctx *context.PrivateContext, keyID int64) (*asymkey_model.PublicKey, error) {
+	caID := ctx.FormInt64("certificate_authority")
+	if caID <= 0 {
+		return asymkey_model.GetPublicKeyByID(ctx, keyID)
+	}
+
+	// the certificate authority may have been removed since the session was authenticated
+	ca, err := asymkey_model.GetSSHCertificateAuthorityByID(ctx, caID)
+	if err != nil {
+		return nil, err
+	}
+	if ca.Mapping == asymkey_model.SSHCertificateAuthorityMappingNone {
+		return nil, asymkey_model.ErrSSHCertificateAuthorityNotExist{ID: caID}
+	}
+	return &asymkey_model.PublicKey{
+		OwnerID:     ctx.FormInt64("user"),
+		Name:        ca.Name,
+		Fingerprint: ca.Fingerprint,
+		Mode:        perm.AccessModeWrite,
+		Type:        asymkey_model.KeyTypePrincipal,
+	}, nil
+}
+
+// recordCertificateSignIn records which certificate authenticated the user in the audit log
+func recordCertificateSignIn(ctx *context.PrivateContext, key *asymkey_model.PublicKey, user *user_model.User) {
+	if ctx.FormInt64("certificate_authority") <= 0 || user == nil {
+		return
+	}
+	audit_service.Record(audit_service.WithRemoteAddress(ctx, ctx.FormString("remote_addr")), user,
+		audit_model.ActionUserSignIn, audit_service.UserTarget(user), nil, map[string]string{
+			"ssh_certificate_authority":             key.Name,
+			"ssh_certificate_authority_fingerprint": key.Fingerprint,
+			"ssh_certificate_key_id":                ctx.FormString("certificate_key_id"),
+			"ssh_certificate_serial":                ctx.FormString("certificate_serial"),
+		})
+}
+
 // ServNoCommand returns information about the provided keyid
 func ServNoCommand(ctx *context.PrivateContext) {
 	keyID := ctx.ParamsInt64(":keyid")
-	if keyID <= 0 {
+	if keyID <= 0 && ctx.FormInt64("certificate_authority") <= 0 {
 		ctx.JSON(http.StatusBadRequest, private.Response{
 			UserMsg: fmt.Sprintf("Bad key id: %d", keyID),
 		})
+		return
 	}
 	results := private.KeyAndOwner{}
 
-	key, err := asymkey_model.GetPublicKeyByID(ctx, keyID)
+	key, err := getServKey(ctx, keyID)
 	if err != nil {
-		if asymkey_model.IsErrKeyNotExist(err) {
+		if errors.Is(err, util.ErrNotExist) {
 			ctx.JSON(http.StatusUnauthorized, private.Response{
 				UserMsg: fmt.Sprintf("Cannot find key: %d", keyID),
 			})
@@ -71,6 +115,7 @@ func ServNoCommand(ctx *context.PrivateContext) {
 			return
 		}
 		results.Owner = user
+		recordCertificateSignIn(ctx, key, user)
 	}
 	ctx.JSON(http.StatusOK, &results)
 }
@@ -184,9 +229,9 @@ func ServCommand(ctx *context.PrivateContext) {
 	}
 
 	// Get the Public Key represented by the keyID
-	key, err := asymkey_model.GetPublicKeyByID(ctx, keyID)
+	key, err := getServKey(ctx, keyID)
 	if err != nil {
-		if asymkey_model.IsErrKeyNotExist(err) {
+		if errors.Is(err, util.ErrNotExist) {
 			ctx.JSON(http.StatusNotFound, private.Response{
 				UserMsg: fmt.Sprintf("Cannot find key: %d", keyID),
 			})
@@ -273,6 +318,35 @@ func ServCommand(ctx *context.PrivateContext) {
 		}
 	}
 
+	// The address of the SSH client is passed by serv, the user is nil if the key is a deploy key
+	blocked, err := context.IsBlockedByIPAllowlist(ctx, owner, user, ctx.FormString("remote_addr"))
+	if err != nil {
+		log.Error("Unable to check the IP allowlist of %s: %v", results.OwnerName, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: fmt.Sprintf("Unable to check the IP allowlist of %s: %v", results.OwnerName, err),
+		})
+		return
+	} else if blocked {
+		ctx.JSON(http.StatusForbidden, private.Response{
+			UserMsg: context.IPAllowlistBlockedMessage,
+		})
+		return
+	}
+	blocked, err = context.IsBlockedByTwoFactorPolicy(ctx, owner, user)
+	if err != nil {
+		log.Error("Unable to check the two-factor authentication policy of %s: %v", results.OwnerName, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: fmt.Sprintf("Unable to check the two-factor authentication policy of %s: %v", results.OwnerName, err),
+		})
+		return
+	} else if blocked {
+		ctx.JSON(http.StatusForbidden, private.Response{
+			UserMsg: context.TwoFactorBlockedMessage,
+		})
+		return
+	}
+	recordCertificateSignIn(ctx, key, user)
+
 	// Don't allow pushing if the repo is archived
 	if repoExist && mode > perm.AccessModeRead && repo.IsArchived {
 		ctx.JSON(http.StatusUnauthorized, private.Response{
@@ -81,6 +81,12 @@ func ApplicationsRegenerateSecret(ctx *context.Context) {
 	oa.RegenerateSecret(ctx)
 }
 
+// ApplicationsInstallable handles the post request for editing how the oauth2 application is installed
+func ApplicationsInstallable(ctx *context.Context) {
+	oa := newOAuth2CommonHandlers()
+	oa.EditInstallable(ctx)
+}
+
 // DeleteApplication deletes the given oauth2 application
 func DeleteApplication(ctx *context.Context) {
 	oa := newOAuth2CommonHandlers()
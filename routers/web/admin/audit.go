@@ -0,0 +1,19 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package admin
+
+import (
+	"forgejo.org/modules/base"
+	"forgejo.org/routers/web/shared"
+	"forgejo.org/services/context"
+)
+
+const tplAudit base.TplName = "admin/audit"
+
+// Audit shows the audit events of the instance
+func Audit(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.audit")
+	ctx.Data["PageIsAdminAudit"] = true
+	shared.AuditEvents(ctx, 0, tplAudit)
+}
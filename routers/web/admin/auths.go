@@ -15,12 +15,14 @@ import (
 	"forgejo.org/modules/auth/pam"
 	"forgejo.org/modules/base"
 	"forgejo.org/modules/log"
+	saml_module "forgejo.org/modules/saml"
 	"forgejo.org/modules/setting"
 	"forgejo.org/modules/web"
 	auth_service "forgejo.org/services/auth"
 	"forgejo.org/services/auth/source/ldap"
 	"forgejo.org/services/auth/source/oauth2"
 	pam_service "forgejo.org/services/auth/source/pam"
+	"forgejo.org/services/auth/source/saml"
 	"forgejo.org/services/auth/source/smtp"
 	"forgejo.org/services/context"
 	"forgejo.org/services/forms"
@@ -61,6 +63,7 @@ var (
 			{auth.DLDAP.String(), auth.DLDAP},
 			{auth.SMTP.String(), auth.SMTP},
 			{auth.OAuth2.String(), auth.OAuth2},
+			{auth.SAML.String(), auth.SAML},
 		}
 		if pam.Supported {
 			items = append(items, dropdownItem{auth.Names[auth.PAM], auth.PAM})
@@ -193,6 +196,44 @@ func parseOAuth2Config(form forms.AuthenticationForm) *oauth2.Source {
 	}
 }
 
+// parseSAMLConfig imports the identity provider metadata from its URL, if set, and validates it
+func parseSAMLConfig(ctx *context.Context, form forms.AuthenticationForm, tpl base.TplName) *saml.Source {
+	metadata := form.SAMLIdPMetadata
+	if form.SAMLIdPMetadataURL != "" {
+		metadataURL, err := url.Parse(form.SAMLIdPMetadataURL)
+		if err != nil || (metadataURL.Scheme != "http" && metadataURL.Scheme != "https") {
+			ctx.Data["Err_SAMLIdPMetadataURL"] = true
+			ctx.RenderWithErr(ctx.Tr("admin.auths.saml_invalid_metadata_url"), tpl, form)
+			return nil
+		}
+		if metadata, err = saml.FetchIdentityProviderMetadata(ctx, form.SAMLIdPMetadataURL); err != nil {
+			ctx.Data["Err_SAMLIdPMetadataURL"] = true
+			ctx.RenderWithErr(ctx.Tr("admin.auths.saml_unable_to_fetch_metadata", err.Error()), tpl, form)
+			return nil
+		}
+	} else if _, err := saml_module.ParseIdentityProviderMetadata([]byte(metadata)); err != nil {
+		ctx.Data["Err_SAMLIdPMetadata"] = true
+		ctx.RenderWithErr(ctx.Tr("admin.auths.saml_invalid_metadata", err.Error()), tpl, form)
+		return nil
+	}
+
+	return &saml.Source{
+		IdentityProviderMetadata:    metadata,
+		IdentityProviderMetadataURL: form.SAMLIdPMetadataURL,
+		NameIDFormat:                form.SAMLNameIDFormat,
+		AttributeUsername:           form.SAMLAttributeUsername,
+		AttributeFullName:           form.SAMLAttributeFullName,
+		AttributeEmail:              form.SAMLAttributeEmail,
+		GroupAttribute:              form.SAMLGroupAttribute,
+		AdminGroup:                  form.SAMLAdminGroup,
+		RestrictedGroup:             form.SAMLRestrictedGroup,
+		GroupTeamMap:                form.SAMLGroupTeamMap,
+		GroupTeamMapRemoval:         form.SAMLGroupTeamMapRemoval,
+		AllowIdPInitiated:           form.SAMLAllowIdPInitiated,
+		SkipLocalTwoFA:              form.SkipLocalTwoFA,
+	}
+}
+
 // NewAuthSourcePost response for adding an auth source
 func NewAuthSourcePost(ctx *context.Context) {
 	form := *web.GetForm(ctx).(*forms.AuthenticationForm)
@@ -233,6 +274,11 @@ func NewAuthSourcePost(ctx *context.Context) {
 				return
 			}
 		}
+	case auth.SAML:
+		config = parseSAMLConfig(ctx, form, tplAuthNew)
+		if ctx.Written() {
+			return
+		}
 	default:
 		ctx.Error(http.StatusBadRequest)
 		return
@@ -287,6 +333,7 @@ func EditAuthSource(ctx *context.Context) {
 	}
 	ctx.Data["Source"] = source
 	ctx.Data["HasTLS"] = source.HasTLS()
+	ctx.Data["SAMLServiceProviderURL"] = saml.ServiceProviderURL(source.Name)
 
 	if source.IsOAuth2() {
 		type Named interface {
@@ -321,6 +368,7 @@ func EditAuthSourcePost(ctx *context.Context) {
 	}
 	ctx.Data["Source"] = source
 	ctx.Data["HasTLS"] = source.HasTLS()
+	ctx.Data["SAMLServiceProviderURL"] = saml.ServiceProviderURL(source.Name)
 
 	if ctx.HasError() {
 		ctx.HTML(http.StatusOK, tplAuthEdit)
@@ -349,6 +397,11 @@ func EditAuthSourcePost(ctx *context.Context) {
 				return
 			}
 		}
+	case auth.SAML:
+		config = parseSAMLConfig(ctx, form, tplAuthEdit)
+		if ctx.Written() {
+			return
+		}
 	default:
 		ctx.Error(http.StatusBadRequest)
 		return
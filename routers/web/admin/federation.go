@@ -0,0 +1,191 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"forgejo.org/models/db"
+	"forgejo.org/models/forgefed"
+	"forgejo.org/modules/base"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/util"
+	"forgejo.org/modules/web"
+	"forgejo.org/services/context"
+	"forgejo.org/services/federation"
+	"forgejo.org/services/forms"
+	moderation_service "forgejo.org/services/moderation"
+)
+
+const (
+	tplFederation base.TplName = "admin/federation"
+)
+
+func redirectToFederation(ctx *context.Context) {
+	ctx.Redirect(setting.AppSubURL + "/admin/federation")
+}
+
+// Federation shows the federation allow and deny lists and the activities held for review
+func Federation(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.federation")
+	ctx.Data["PageIsAdminFederation"] = true
+	ctx.Data["HostPolicy"] = setting.Federation.HostPolicy
+	ctx.Data["QuarantineNewHosts"] = setting.Federation.QuarantineNewHosts
+
+	rules, err := forgefed.FindFederationHostRules(ctx, 0)
+	if err != nil {
+		ctx.ServerError("FindFederationHostRules", err)
+		return
+	}
+	ctx.Data["Rules"] = rules
+
+	hosts, err := forgefed.FindQuarantinedFederationHosts(ctx)
+	if err != nil {
+		ctx.ServerError("FindQuarantinedFederationHosts", err)
+		return
+	}
+	ctx.Data["QuarantinedHosts"] = hosts
+
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	activities, count, err := forgefed.FindQuarantinedActivities(ctx, "", db.ListOptions{
+		Page:     page,
+		PageSize: setting.UI.Admin.NoticePagingNum,
+	})
+	if err != nil {
+		ctx.ServerError("FindQuarantinedActivities", err)
+		return
+	}
+	ctx.Data["Activities"] = activities
+	ctx.Data["Page"] = context.NewPagination(int(count), setting.UI.Admin.NoticePagingNum, page, 5)
+
+	ctx.HTML(http.StatusOK, tplFederation)
+}
+
+// FederationRulePost adds a host to the allow or deny list
+func FederationRulePost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.AdminFederationHostRuleForm)
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		redirectToFederation(ctx)
+		return
+	}
+
+	ruleType := forgefed.FederationHostRuleDeny
+	if form.Type == "allow" {
+		ruleType = forgefed.FederationHostRuleAllow
+	}
+	err := forgefed.CreateFederationHostRule(ctx, &forgefed.FederationHostRule{
+		Pattern: form.Pattern,
+		Type:    ruleType,
+		Comment: form.Comment,
+	})
+	if err != nil {
+		if errors.Is(err, util.ErrAlreadyExist) {
+			ctx.Flash.Error(ctx.Tr("admin.federation.rule_exists", form.Pattern))
+			redirectToFederation(ctx)
+			return
+		}
+		ctx.ServerError("CreateFederationHostRule", err)
+		return
+	}
+
+	log.Trace("Federation host rule %q added by admin (%s)", form.Pattern, ctx.Doer.Name)
+	ctx.Flash.Success(ctx.Tr("admin.federation.rule_added", form.Pattern))
+	redirectToFederation(ctx)
+}
+
+// DeleteFederationRule removes a host from the allow or deny list
+func DeleteFederationRule(ctx *context.Context) {
+	if err := forgefed.DeleteFederationHostRule(ctx, ctx.ParamsInt64(":id")); err != nil {
+		ctx.ServerError("DeleteFederationHostRule", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("admin.federation.rule_deleted"))
+	redirectToFederation(ctx)
+}
+
+// ImportFederationBlocklist adds the hosts of an uploaded blocklist file to the deny list
+func ImportFederationBlocklist(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.AdminFederationBlocklistForm)
+	if form.Blocklist == nil || form.Blocklist.Filename == "" {
+		ctx.Flash.Error(ctx.Tr("admin.federation.import_no_file"))
+		redirectToFederation(ctx)
+		return
+	}
+
+	f, err := form.Blocklist.Open()
+	if err != nil {
+		ctx.ServerError("Open", err)
+		return
+	}
+	defer f.Close()
+
+	added, err := federation.ImportHostBlocklist(ctx, f, form.Comment)
+	if err != nil {
+		ctx.ServerError("ImportHostBlocklist", err)
+		return
+	}
+
+	log.Trace("Federation blocklist %q imported by admin (%s): %d hosts", form.Blocklist.Filename, ctx.Doer.Name, added)
+	ctx.Flash.Success(ctx.Tr("admin.federation.import_success", added))
+	redirectToFederation(ctx)
+}
+
+// ReleaseFederationHost ends the quarantine of a host and processes its held activities
+func ReleaseFederationHost(ctx *context.Context) {
+	host := ctx.FormString("host")
+	if err := moderation_service.ReleaseFederationHost(ctx, host); err != nil {
+		ctx.ServerError("ReleaseFederationHost", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("admin.federation.host_released", host))
+	redirectToFederation(ctx)
+}
+
+// BlockFederationHost adds a quarantined host to the deny list and drops its held activities
+func BlockFederationHost(ctx *context.Context) {
+	host := ctx.FormString("host")
+	if err := moderation_service.BlockFederationHost(ctx, host, ctx.Locale.TrString("admin.federation.blocked_from_quarantine")); err != nil {
+		ctx.ServerError("BlockFederationHost", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("admin.federation.host_blocked", host))
+	redirectToFederation(ctx)
+}
+
+// ApproveQuarantinedActivity processes a held activity
+func ApproveQuarantinedActivity(ctx *context.Context) {
+	err := moderation_service.ApproveQuarantinedActivity(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound("GetQuarantinedActivity", err)
+			return
+		}
+		ctx.Flash.Error(ctx.Tr("admin.federation.activity_failed", err.Error()))
+		redirectToFederation(ctx)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("admin.federation.activity_approved"))
+	redirectToFederation(ctx)
+}
+
+// RejectQuarantinedActivity drops a held activity
+func RejectQuarantinedActivity(ctx *context.Context) {
+	err := moderation_service.RejectQuarantinedActivity(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound("GetQuarantinedActivity", err)
+			return
+		}
+		ctx.ServerError("RejectQuarantinedActivity", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("admin.federation.activity_rejected"))
+	redirectToFederation(ctx)
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"forgejo.org/models"
+	audit_model "forgejo.org/models/audit"
 	"forgejo.org/models/auth"
 	"forgejo.org/models/db"
 	org_model "forgejo.org/models/organization"
@@ -26,6 +27,8 @@ import (
 	"forgejo.org/modules/web"
 	"forgejo.org/routers/web/explore"
 	user_setting "forgejo.org/routers/web/user/setting"
+	audit_service "forgejo.org/services/audit"
+	auth_service "forgejo.org/services/auth"
 	"forgejo.org/services/context"
 	"forgejo.org/services/forms"
 	"forgejo.org/services/mailer"
@@ -206,6 +209,7 @@ func NewUserPost(ctx *context.Context) {
 	}
 
 	log.Trace("Account created by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionAdminUserCreate, audit_service.UserTarget(u), nil, audit_service.UserAccountValue(u))
 
 	// Send email notification.
 	if form.SendNotify {
@@ -333,6 +337,7 @@ func EditUserPost(ctx *context.Context) {
 	if ctx.Written() {
 		return
 	}
+	before := audit_service.UserAccountValue(u)
 
 	form := web.GetForm(ctx).(*forms.AdminEditUserForm)
 	if ctx.HasError() {
@@ -473,6 +478,9 @@ func EditUserPost(ctx *context.Context) {
 			}
 		}
 	}
+	after := audit_service.UserAccountValue(u)
+	after["reset_2fa"] = form.Reset2FA
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionAdminUserUpdate, audit_service.UserTarget(u), before, after)
 
 	ctx.Flash.Success(ctx.Tr("admin.users.update_profile_success"))
 	ctx.Redirect(setting.AppSubURL + "/admin/users/" + url.PathEscape(ctx.Params(":userid")))
@@ -513,11 +521,30 @@ func DeleteUser(ctx *context.Context) {
 		return
 	}
 	log.Trace("Account deleted by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionAdminUserDelete, audit_service.UserTarget(u), audit_service.UserAccountValue(u), nil)
 
 	ctx.Flash.Success(ctx.Tr("admin.users.deletion_success"))
 	ctx.Redirect(setting.AppSubURL + "/admin/users")
 }
 
+// SignOutUser signs the user out of all sessions and remembered devices
+func SignOutUser(ctx *context.Context) {
+	u := prepareUserInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := auth_service.SignOutEverywhere(ctx, u.ID); err != nil {
+		ctx.ServerError("SignOutEverywhere", err)
+		return
+	}
+	log.Trace("Account signed out everywhere by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionAdminUserSignOut, audit_service.UserTarget(u), nil, nil)
+
+	ctx.Flash.Success(ctx.Tr("admin.users.sign_out_success"))
+	ctx.JSONRedirect(setting.AppSubURL + "/admin/users/" + strconv.FormatInt(u.ID, 10))
+}
+
 // AvatarPost response for change user's avatar request
 func AvatarPost(ctx *context.Context) {
 	u := prepareUserInfo(ctx)
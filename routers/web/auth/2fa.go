@@ -6,12 +6,15 @@ package auth
 import (
 	"errors"
 	"net/http"
+	"strings"
+	"time"
 
 	"forgejo.org/models/auth"
 	user_model "forgejo.org/models/user"
 	"forgejo.org/modules/base"
 	"forgejo.org/modules/setting"
 	"forgejo.org/modules/web"
+	auth_service "forgejo.org/services/auth"
 	"forgejo.org/services/context"
 	"forgejo.org/services/externalaccount"
 	"forgejo.org/services/forms"
@@ -157,3 +160,45 @@ func TwoFactorScratchPost(ctx *context.Context) {
 
 	ctx.RenderWithErr(ctx.Tr("auth.twofa_scratch_token_incorrect"), tplTwofaScratch, forms.TwoFactorScratchAuthForm{})
 }
+
+// isSecuritySetupPath returns whether the users who are sent to set up their security settings can still
+// request the path. The router receives the paths without the sub-URL, they must not be prefixed with it.
+func isSecuritySetupPath(path string) bool {
+	return strings.HasPrefix(path, "/user/settings/security") || path == "/user/logout" || path == "/user/events"
+}
+
+// RequireTwoFactor sends the signed in users who are required to enable two-factor authentication to set it up,
+// once per session during their grace period and on every request once it ended
+func RequireTwoFactor(ctx *context.Context) {
+	policy, deadline, err := auth_service.CheckTwoFactorPolicy(ctx, ctx.Doer)
+	if err != nil {
+		ctx.ServerError("CheckTwoFactorPolicy", err)
+		return
+	}
+	if policy != auth_service.TwoFactorGracePeriod && policy != auth_service.TwoFactorGraceExpired {
+		return
+	}
+	if isSecuritySetupPath(ctx.Req.URL.Path) {
+		return
+	}
+
+	if policy == auth_service.TwoFactorGracePeriod {
+		if ctx.Req.Method != http.MethodGet || ctx.Session.Get("twoFactorPolicyNotified") == true {
+			return
+		}
+		if err := ctx.Session.Set("twoFactorPolicyNotified", true); err != nil {
+			ctx.ServerError("Session.Set", err)
+			return
+		}
+		ctx.Flash.Warning(ctx.Tr("auth.two_factor_grace_period", deadline.Format(time.RFC1123Z)))
+		ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+		return
+	}
+
+	if strings.HasPrefix(ctx.Req.UserAgent(), "git") {
+		ctx.Error(http.StatusUnauthorized, ctx.Locale.TrString("auth.two_factor_required"))
+		return
+	}
+	ctx.Flash.Warning(ctx.Tr("auth.two_factor_required"))
+	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+}
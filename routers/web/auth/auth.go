@@ -27,6 +27,7 @@ import (
 	"forgejo.org/modules/validation"
 	"forgejo.org/modules/web"
 	"forgejo.org/modules/web/middleware"
+	audit_service "forgejo.org/services/audit"
 	auth_service "forgejo.org/services/auth"
 	"forgejo.org/services/auth/source/oauth2"
 	"forgejo.org/services/context"
@@ -72,6 +73,11 @@ func autoSignIn(ctx *context.Context) (bool, error) {
 
 	isSucceed = true
 
+	lookupKey, _, _ := strings.Cut(authCookie, ":")
+	if err := ctx.UpdateLTAClient(lookupKey); err != nil {
+		return false, fmt.Errorf("UpdateLTAClient: %w", err)
+	}
+
 	if err := updateSession(ctx, nil, map[string]any{
 		// Set session IDs
 		"uid": u.ID,
@@ -83,6 +89,10 @@ func autoSignIn(ctx *context.Context) (bool, error) {
 		return false, err
 	}
 
+	if err := auth_service.SignedIn(ctx, u, audit_service.SignInMethodRememberMe); err != nil {
+		return false, err
+	}
+
 	ctx.Csrf.DeleteCookie(ctx)
 	return true, nil
 }
@@ -159,6 +169,15 @@ func SignIn(ctx *context.Context) {
 		ctx.ServerError("UserSignIn", err)
 		return
 	}
+	samlSources, err := db.Find[auth.Source](ctx, auth.FindSourcesOptions{
+		IsActive:  optional.Some(true),
+		LoginType: auth.SAML,
+	})
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	ctx.Data["SAMLSources"] = samlSources
 	ctx.Data["OAuth2Providers"] = oauth2Providers
 	ctx.Data["Title"] = ctx.Tr("sign_in")
 	ctx.Data["SignInLink"] = setting.AppSubURL + "/user/login"
@@ -184,6 +203,15 @@ func SignInPost(ctx *context.Context) {
 		ctx.ServerError("UserSignIn", err)
 		return
 	}
+	samlSources, err := db.Find[auth.Source](ctx, auth.FindSourcesOptions{
+		IsActive:  optional.Some(true),
+		LoginType: auth.SAML,
+	})
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	ctx.Data["SAMLSources"] = samlSources
 	ctx.Data["OAuth2Providers"] = oauth2Providers
 	ctx.Data["Title"] = ctx.Tr("sign_in")
 	ctx.Data["SignInLink"] = setting.AppSubURL + "/user/login"
@@ -214,7 +242,7 @@ func SignInPost(ctx *context.Context) {
 		}
 	}
 
-	u, source, err := auth_service.UserSignIn(ctx, form.UserName, form.Password)
+	u, source, err := auth_service.PasswordSignIn(ctx, form.UserName, form.Password)
 	if err != nil {
 		if errors.Is(err, util.ErrNotExist) || errors.Is(err, util.ErrInvalidArgument) {
 			ctx.RenderWithErr(ctx.Tr("form.username_password_incorrect"), tplSignIn, &form)
@@ -232,11 +260,25 @@ func SignInPost(ctx *context.Context) {
 		return
 	}
 
-	// Now handle 2FA:
+	handleSignInWithTwoFactor(ctx, source, u, form.Remember, audit_service.SignInMethodPassword)
+}
+
+// setSignInMethod remembers how the user authenticated until the sign in completes, e.g. after the second factor
+func setSignInMethod(ctx *context.Context, method string) error {
+	return ctx.Session.Set("signInMethod", method)
+}
+
+// handleSignInWithTwoFactor signs in the user authenticated by the source with the method,
+// or redirects to the second factor if the user has enrolled one and the source does not skip it.
+func handleSignInWithTwoFactor(ctx *context.Context, source *auth.Source, u *user_model.User, remember bool, method string) {
+	if err := setSignInMethod(ctx, method); err != nil {
+		ctx.ServerError("UserSignIn: Unable to update session", err)
+		return
+	}
 
 	// First of all if the source can skip local two fa we're done
 	if skipper, ok := source.Cfg.(auth_service.LocalTwoFASkipper); ok && skipper.IsSkipLocalTwoFA() {
-		handleSignIn(ctx, u, form.Remember)
+		handleSignIn(ctx, u, remember)
 		return
 	}
 
@@ -257,14 +299,14 @@ func SignInPost(ctx *context.Context) {
 
 	if !hasTOTPtwofa && !hasWebAuthnTwofa {
 		// No two factor auth configured we can sign in the user
-		handleSignIn(ctx, u, form.Remember)
+		handleSignIn(ctx, u, remember)
 		return
 	}
 
 	updates := map[string]any{
 		// User will need to use 2FA TOTP or WebAuthn, save data
 		"twofaUid":      u.ID,
-		"twofaRemember": form.Remember,
+		"twofaRemember": remember,
 	}
 	if hasTOTPtwofa {
 		// User will need to use WebAuthn, save data
@@ -285,6 +327,15 @@ func SignInPost(ctx *context.Context) {
 	ctx.Redirect(setting.AppSubURL + "/user/two_factor")
 }
 
+// handleSignInWithMethod handles the final part of the sign-in process of the user who authenticated with the method.
+func handleSignInWithMethod(ctx *context.Context, u *user_model.User, remember bool, method string) {
+	if err := setSignInMethod(ctx, method); err != nil {
+		ctx.ServerError("UserSignIn: Unable to update session", err)
+		return
+	}
+	handleSignIn(ctx, u, remember)
+}
+
 // This handles the final part of the sign-in process of the user.
 func handleSignIn(ctx *context.Context, u *user_model.User, remember bool) {
 	redirect := handleSignInFull(ctx, u, remember, true)
@@ -295,6 +346,28 @@ func handleSignIn(ctx *context.Context, u *user_model.User, remember bool) {
 }
 
 func handleSignInFull(ctx *context.Context, u *user_model.User, remember, obeyRedirect bool) string {
+	return signInFull(ctx, u, remember, obeyRedirect, false)
+}
+
+// handlePasskeySignIn handles the final part of the sign-in process of a user who authenticated with a passkey.
+func handlePasskeySignIn(ctx *context.Context, u *user_model.User, remember bool) string {
+	return signInFull(ctx, u, remember, false, true)
+}
+
+func signInFull(ctx *context.Context, u *user_model.User, remember, obeyRedirect, passkey bool) string {
+	// Users who must sign in with a passkey can only sign in otherwise to register one
+	if !passkey && auth_service.PasskeyRequired(u) {
+		hasPasskey, err := auth.HasPasskeysByUID(ctx, u.ID)
+		if err != nil {
+			ctx.ServerError("HasPasskeysByUID", err)
+			return setting.AppSubURL + "/"
+		}
+		if hasPasskey {
+			ctx.Flash.Error(ctx.Tr("auth.passkey_required"))
+			return setting.AppSubURL + "/user/login"
+		}
+	}
+
 	if remember {
 		if err := ctx.SetLTACookie(u); err != nil {
 			ctx.ServerError("GenerateAuthToken", err)
@@ -302,6 +375,13 @@ func handleSignInFull(ctx *context.Context, u *user_model.User, remember, obeyRe
 		}
 	}
 
+	method := audit_service.SignInMethodPasskey
+	if !passkey {
+		if method, _ = ctx.Session.Get("signInMethod").(string); method == "" {
+			method = audit_service.SignInMethodPassword
+		}
+	}
+
 	if err := updateSession(ctx, []string{
 		// Delete the openid, 2fa and linkaccount data
 		"openid_verified_uri",
@@ -311,8 +391,10 @@ func handleSignInFull(ctx *context.Context, u *user_model.User, remember, obeyRe
 		"twofaUid",
 		"twofaRemember",
 		"linkAccount",
+		"signInMethod",
 	}, map[string]any{
-		"uid": u.ID,
+		"uid":           u.ID,
+		"passkeySignIn": passkey,
 	}); err != nil {
 		ctx.ServerError("RegenerateSession", err)
 		return setting.AppSubURL + "/"
@@ -340,8 +422,8 @@ func handleSignInFull(ctx *context.Context, u *user_model.User, remember, obeyRe
 	ctx.Csrf.DeleteCookie(ctx)
 
 	// Register last login
-	if err := user_service.UpdateUser(ctx, u, &user_service.UpdateOptions{SetLastLogin: true}); err != nil {
-		ctx.ServerError("UpdateUser", err)
+	if err := auth_service.SignedIn(ctx, u, method); err != nil {
+		ctx.ServerError("SignedIn", err)
 		return setting.AppSubURL + "/"
 	}
 
@@ -371,6 +453,11 @@ func getUserName(gothUser *goth.User) (string, error) {
 
 // HandleSignOut resets the session and sets the cookies
 func HandleSignOut(ctx *context.Context) {
+	if id := auth_service.SessionRecordID(ctx.Session); id != 0 && ctx.Doer != nil {
+		if err := auth.DeleteUserSession(ctx, ctx.Doer.ID, id); err != nil {
+			log.Error("DeleteUserSession: %v", err)
+		}
+	}
 	_ = ctx.Session.Flush()
 	_ = ctx.Session.Destroy(ctx.Resp, ctx.Req)
 	ctx.DeleteSiteCookie(setting.CookieRememberName)
@@ -401,6 +488,15 @@ func SignUp(ctx *context.Context) {
 		ctx.ServerError("UserSignUp", err)
 		return
 	}
+	samlSources, err := db.Find[auth.Source](ctx, auth.FindSourcesOptions{
+		IsActive:  optional.Some(true),
+		LoginType: auth.SAML,
+	})
+	if err != nil {
+		ctx.ServerError("UserSignUp", err)
+		return
+	}
+	ctx.Data["SAMLSources"] = samlSources
 
 	ctx.Data["OAuth2Providers"] = oauth2Providers
 	context.SetCaptchaData(ctx)
@@ -430,6 +526,15 @@ func SignUpPost(ctx *context.Context) {
 		ctx.ServerError("UserSignUp", err)
 		return
 	}
+	samlSources, err := db.Find[auth.Source](ctx, auth.FindSourcesOptions{
+		IsActive:  optional.Some(true),
+		LoginType: auth.SAML,
+	})
+	if err != nil {
+		ctx.ServerError("UserSignUp", err)
+		return
+	}
+	ctx.Data["SAMLSources"] = samlSources
 
 	ctx.Data["OAuth2Providers"] = oauth2Providers
 	context.SetCaptchaData(ctx)
@@ -495,7 +600,7 @@ func SignUpPost(ctx *context.Context) {
 	}
 
 	ctx.Flash.Success(ctx.Tr("auth.sign_up_successful"))
-	handleSignIn(ctx, u, false)
+	handleSignInWithMethod(ctx, u, false, audit_service.SignInMethodPassword)
 }
 
 // createAndHandleCreatedUser calls createUserInContext and
@@ -16,6 +16,7 @@ import (
 	"forgejo.org/modules/setting"
 	"forgejo.org/modules/util"
 	"forgejo.org/modules/web"
+	audit_service "forgejo.org/services/audit"
 	auth_service "forgejo.org/services/auth"
 	"forgejo.org/services/auth/source/oauth2"
 	"forgejo.org/services/context"
@@ -140,7 +141,7 @@ func LinkAccountPostSignIn(ctx *context.Context) {
 		return
 	}
 
-	u, _, err := auth_service.UserSignIn(ctx, signInForm.UserName, signInForm.Password)
+	u, _, err := auth_service.PasswordSignIn(ctx, signInForm.UserName, signInForm.Password)
 	if err != nil {
 		handleSignInError(ctx, signInForm.UserName, &signInForm, tplLinkAccount, "UserLinkAccount", err)
 		return
@@ -150,6 +151,11 @@ func LinkAccountPostSignIn(ctx *context.Context) {
 }
 
 func linkAccount(ctx *context.Context, u *user_model.User, gothUser goth.User, remember bool) {
+	if err := setSignInMethod(ctx, audit_service.SignInMethodOAuth2); err != nil {
+		ctx.ServerError("UserLinkAccount: Unable to update session", err)
+		return
+	}
+
 	updateAvatarIfNeed(ctx, gothUser.AvatarURL, u)
 
 	// If this user is enrolled in 2FA, we can't sign the user in just yet.
@@ -295,5 +301,5 @@ func LinkAccountPostRegister(ctx *context.Context) {
 		return
 	}
 
-	handleSignIn(ctx, u, false)
+	handleSignInWithMethod(ctx, u, false, audit_service.SignInMethodOAuth2)
 }
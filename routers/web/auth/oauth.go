@@ -16,6 +16,7 @@ import (
 	"net/url"
 	"sort"
 	"strings"
+	"time"
 
 	asymkey_model "forgejo.org/models/asymkey"
 	"forgejo.org/models/auth"
@@ -32,6 +33,7 @@ import (
 	"forgejo.org/modules/util"
 	"forgejo.org/modules/web"
 	"forgejo.org/modules/web/middleware"
+	audit_service "forgejo.org/services/audit"
 	auth_service "forgejo.org/services/auth"
 	source_service "forgejo.org/services/auth/source"
 	"forgejo.org/services/auth/source/oauth2"
@@ -52,8 +54,9 @@ import (
 )
 
 const (
-	tplGrantAccess base.TplName = "user/auth/grant"
-	tplGrantError  base.TplName = "user/auth/grant_error"
+	tplGrantAccess        base.TplName = "user/auth/grant"
+	tplGrantError         base.TplName = "user/auth/grant_error"
+	tplDeviceVerification base.TplName = "user/auth/device"
 )
 
 // TODO move error and responses to SDK or models
@@ -109,8 +112,19 @@ const (
 	AccessTokenErrorCodeUnsupportedGrantType = "unsupported_grant_type"
 	// AccessTokenErrorCodeInvalidScope represents an error code specified in RFC 6749
 	AccessTokenErrorCodeInvalidScope = "invalid_scope"
+	// AccessTokenErrorCodeAuthorizationPending represents an error code specified in RFC 8628
+	AccessTokenErrorCodeAuthorizationPending = "authorization_pending"
+	// AccessTokenErrorCodeSlowDown represents an error code specified in RFC 8628
+	AccessTokenErrorCodeSlowDown = "slow_down"
+	// AccessTokenErrorCodeAccessDenied represents an error code specified in RFC 8628
+	AccessTokenErrorCodeAccessDenied = "access_denied"
+	// AccessTokenErrorCodeExpiredToken represents an error code specified in RFC 8628
+	AccessTokenErrorCodeExpiredToken = "expired_token"
 )
 
+// GrantTypeDeviceCode is the grant type devices poll the token endpoint with, RFC 8628 Section 3.4
+const GrantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+
 // AccessTokenError represents an error response specified in RFC 6749
 // https://datatracker.ietf.org/doc/html/rfc6749#section-5.2
 type AccessTokenError struct {
@@ -276,6 +290,17 @@ func newAccessTokenResponse(ctx go_context.Context, grant *auth.OAuth2Grant, ser
 	}, nil
 }
 
+// DeviceAuthorizationResponse represents a successful device authorization response
+// https://datatracker.ietf.org/doc/html/rfc8628#section-3.2
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
 type userInfoResponse struct {
 	Sub      string   `json:"sub"`
 	Name     string   `json:"name"`
@@ -666,6 +691,164 @@ func GrantApplicationOAuth(ctx *context.Context) {
 	ctx.Redirect(redirect.String(), http.StatusSeeOther)
 }
 
+// DeviceAuthorizationOAuth issues the codes of a device which can not open a browser
+// https://datatracker.ietf.org/doc/html/rfc8628#section-3.1
+func DeviceAuthorizationOAuth(ctx *context.Context) {
+	form := *web.GetForm(ctx).(*forms.DeviceAuthorizationForm)
+	if form.ClientSecret == "" {
+		authHeader := ctx.Req.Header.Get("Authorization")
+		if authType, authData, ok := strings.Cut(authHeader, " "); ok && strings.EqualFold(authType, "Basic") {
+			clientID, clientSecret, err := base.BasicAuthDecode(authData)
+			if err != nil || (form.ClientID != "" && form.ClientID != clientID) {
+				handleAccessTokenError(ctx, AccessTokenError{
+					ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+					ErrorDescription: "cannot parse basic auth header",
+				})
+				return
+			}
+			form.ClientID = clientID
+			form.ClientSecret = clientSecret
+		}
+	}
+
+	app, err := auth.GetOAuth2ApplicationByClientID(ctx, form.ClientID)
+	if err != nil {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidClient,
+			ErrorDescription: fmt.Sprintf("cannot load client with client id: %q", form.ClientID),
+		})
+		return
+	}
+	if app.ConfidentialClient && !app.ValidateClientSecret([]byte(form.ClientSecret)) {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidClient,
+			ErrorDescription: "invalid client secret",
+		})
+		return
+	}
+
+	device, err := auth.CreateOAuth2DeviceAuthorization(ctx, app, form.Scope,
+		time.Duration(setting.OAuth2.DeviceCodeExpirationTime)*time.Second, setting.OAuth2.DeviceCodePollingInterval)
+	if err != nil {
+		log.Error("CreateOAuth2DeviceAuthorization: %v", err)
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "cannot proceed your request",
+		})
+		return
+	}
+
+	verificationURI := setting.AppURL + "login/oauth/device"
+	ctx.JSON(http.StatusOK, &DeviceAuthorizationResponse{
+		DeviceCode:              device.DeviceCode,
+		UserCode:                device.FormattedUserCode(),
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURI + "?user_code=" + url.QueryEscape(device.FormattedUserCode()),
+		ExpiresIn:               setting.OAuth2.DeviceCodeExpirationTime,
+		Interval:                device.PollInterval,
+	})
+}
+
+// loadPendingDeviceAuthorization loads the device authorization of the user code,
+// it returns nil and sets an error flash if the user can not approve it
+func loadPendingDeviceAuthorization(ctx *context.Context, userCode string, current bool) (*auth.OAuth2DeviceAuthorization, *auth.OAuth2Application) {
+	device, err := auth.GetOAuth2DeviceAuthorizationByUserCode(ctx, userCode)
+	if err != nil && !auth.IsErrOAuth2DeviceAuthorizationNotExist(err) {
+		ctx.ServerError("GetOAuth2DeviceAuthorizationByUserCode", err)
+		return nil, nil
+	}
+	if device == nil || device.IsExpired() || !device.IsPending() {
+		ctx.Flash.Error(ctx.Tr("auth.device_code_invalid"), current)
+		return nil, nil
+	}
+	app, err := auth.GetOAuth2ApplicationByID(ctx, device.ApplicationID)
+	if err != nil {
+		ctx.ServerError("GetOAuth2ApplicationByID", err)
+		return nil, nil
+	}
+	return device, app
+}
+
+// DeviceVerificationOAuth shows the page where users enter the code displayed by a device to review its authorization
+func DeviceVerificationOAuth(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("auth.device_title")
+
+	if userCode := ctx.FormTrim("user_code"); userCode != "" {
+		ctx.Data["UserCode"] = userCode
+		device, app := loadPendingDeviceAuthorization(ctx, userCode, true)
+		if ctx.Written() {
+			return
+		}
+		if device != nil {
+			var creatorLinkHTML template.HTML
+			if app.UID != 0 {
+				creator, err := user_model.GetUserByID(ctx, app.UID)
+				if err != nil {
+					ctx.ServerError("GetUserByID", err)
+					return
+				}
+				creatorLinkHTML = template.HTML(fmt.Sprintf(`<a href="%s">@%s</a>`, html.EscapeString(creator.HomeLink()), html.EscapeString(creator.Name)))
+			} else {
+				creatorLinkHTML = template.HTML(fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(setting.AppSubURL+"/"), html.EscapeString(setting.AppName)))
+			}
+			ctx.Data["DeviceAuthorization"] = device
+			ctx.Data["Application"] = app
+			ctx.Data["ApplicationCreatorLinkHTML"] = creatorLinkHTML
+		}
+	}
+
+	ctx.HTML(http.StatusOK, tplDeviceVerification)
+}
+
+// DeviceVerificationPostOAuth approves or denies the authorization of a device
+func DeviceVerificationPostOAuth(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.DeviceVerificationForm)
+	link := setting.AppSubURL + "/login/oauth/device"
+
+	device, app := loadPendingDeviceAuthorization(ctx, form.UserCode, false)
+	if ctx.Written() {
+		return
+	}
+	if device == nil {
+		ctx.Redirect(link)
+		return
+	}
+
+	if !form.Granted {
+		if err := device.Deny(ctx); err != nil {
+			ctx.ServerError("Deny", err)
+			return
+		}
+		ctx.Flash.Info(ctx.Tr("auth.device_denied", app.Name))
+		ctx.Redirect(link)
+		return
+	}
+
+	grant, err := app.GetGrantByUserID(ctx, ctx.Doer.ID)
+	if err != nil {
+		ctx.ServerError("GetGrantByUserID", err)
+		return
+	}
+	if grant == nil {
+		grant, err = app.CreateGrant(ctx, ctx.Doer.ID, device.Scope)
+		if err != nil {
+			ctx.ServerError("CreateGrant", err)
+			return
+		}
+	} else if grant.Scope != device.Scope {
+		ctx.Flash.Error(ctx.Tr("auth.device_grant_scope_mismatch", app.Name))
+		ctx.Redirect(link)
+		return
+	}
+	if err := device.Approve(ctx, grant); err != nil {
+		ctx.ServerError("Approve", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("auth.device_authorized", app.Name))
+	ctx.Redirect(link)
+}
+
 // OIDCWellKnown generates JSON so OIDC clients know Gitea's capabilities
 func OIDCWellKnown(ctx *context.Context) {
 	ctx.Data["SigningKey"] = oauth2.DefaultSigningKey
@@ -751,10 +934,12 @@ func AccessTokenOAuth(ctx *context.Context) {
 		handleRefreshToken(ctx, form, serverKey, clientKey)
 	case "authorization_code":
 		handleAuthorizationCode(ctx, form, serverKey, clientKey)
+	case GrantTypeDeviceCode:
+		handleDeviceCode(ctx, form, serverKey, clientKey)
 	default:
 		handleAccessTokenError(ctx, AccessTokenError{
 			ErrorCode:        AccessTokenErrorCodeUnsupportedGrantType,
-			ErrorDescription: "Only refresh_token or authorization_code grant type is supported",
+			ErrorDescription: "Only refresh_token, authorization_code or device_code grant type is supported",
 		})
 	}
 }
@@ -886,6 +1071,103 @@ func handleAuthorizationCode(ctx *context.Context, form forms.AccessTokenForm, s
 	ctx.JSON(http.StatusOK, resp)
 }
 
+func handleDeviceCode(ctx *context.Context, form forms.AccessTokenForm, serverKey, clientKey oauth2.JWTSigningKey) {
+	app, err := auth.GetOAuth2ApplicationByClientID(ctx, form.ClientID)
+	if err != nil {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidClient,
+			ErrorDescription: fmt.Sprintf("cannot load client with client id: %q", form.ClientID),
+		})
+		return
+	}
+	if app.ConfidentialClient && !app.ValidateClientSecret([]byte(form.ClientSecret)) {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidClient,
+			ErrorDescription: "invalid client secret",
+		})
+		return
+	}
+	device, err := auth.GetOAuth2DeviceAuthorizationByDeviceCode(ctx, form.DeviceCode)
+	if err != nil || device.ApplicationID != app.ID {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidGrant,
+			ErrorDescription: "invalid device code",
+		})
+		return
+	}
+
+	// "The device authorization request ... MUST NOT be used after it expired or was denied"
+	// https://datatracker.ietf.org/doc/html/rfc8628#section-3.5
+	if device.IsExpired() || device.Denied {
+		if _, err := device.Invalidate(ctx); err != nil {
+			log.Error("Unable to invalidate the device authorization: %v", err)
+		}
+		if device.Denied {
+			handleAccessTokenError(ctx, AccessTokenError{
+				ErrorCode:        AccessTokenErrorCodeAccessDenied,
+				ErrorDescription: "the authorization request was denied",
+			})
+			return
+		}
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeExpiredToken,
+			ErrorDescription: "the device code has expired",
+		})
+		return
+	}
+
+	if device.IsPending() {
+		slowDown, err := device.Poll(ctx)
+		if err != nil {
+			log.Error("Unable to record the poll of the device: %v", err)
+		}
+		if slowDown {
+			handleAccessTokenError(ctx, AccessTokenError{
+				ErrorCode:        AccessTokenErrorCodeSlowDown,
+				ErrorDescription: fmt.Sprintf("the device must wait %d seconds between polls", device.PollInterval),
+			})
+			return
+		}
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeAuthorizationPending,
+			ErrorDescription: "the authorization request is still pending",
+		})
+		return
+	}
+
+	grant, err := auth.GetOAuth2GrantByID(ctx, device.GrantID)
+	if err != nil || grant == nil {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidGrant,
+			ErrorDescription: "grant does not exist",
+		})
+		return
+	}
+	// remove the device authorization from database to deny duplicate usage
+	deleted, err := device.Invalidate(ctx)
+	if err != nil {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "cannot proceed your request",
+		})
+		return
+	}
+	if deleted == 0 {
+		// a concurrent request exchanged the device code first
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidGrant,
+			ErrorDescription: "device code was already used",
+		})
+		return
+	}
+	resp, tokenErr := newAccessTokenResponse(ctx, grant, serverKey, clientKey)
+	if tokenErr != nil {
+		handleAccessTokenError(ctx, *tokenErr)
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
 func handleAccessTokenError(ctx *context.Context, acErr AccessTokenError) {
 	ctx.JSON(http.StatusBadRequest, acErr)
 }
@@ -935,7 +1217,7 @@ func SignInOAuth(ctx *context.Context) {
 	}
 
 	// try to do a direct callback flow, so we don't authenticate the user again but use the valid accesstoken to get the user
-	user, gothUser, err := oAuth2UserLoginCallback(ctx, authSource, ctx.Req, ctx.Resp)
+	user, gothUser, err := oAuth2UserLoginCallback(ctx, authSource, ctx.Req, ctx.Resp, false)
 	if err == nil && user != nil {
 		// we got the user without going through the whole OAuth2 authentication flow again
 		handleOAuth2SignIn(ctx, authSource, user, gothUser)
@@ -991,7 +1273,7 @@ func SignInOAuthCallback(ctx *context.Context) {
 		return
 	}
 
-	u, gothUser, err := oAuth2UserLoginCallback(ctx, authSource, ctx.Req, ctx.Resp)
+	u, gothUser, err := oAuth2UserLoginCallback(ctx, authSource, ctx.Req, ctx.Resp, true)
 	if err != nil {
 		if user_model.IsErrUserProhibitLogin(err) {
 			uplerr := err.(user_model.ErrUserProhibitLogin)
@@ -1101,23 +1383,6 @@ func SignInOAuthCallback(ctx *context.Context) {
 	handleOAuth2SignIn(ctx, authSource, u, gothUser)
 }
 
-func claimValueToStringSet(claimValue any) container.Set[string] {
-	var groups []string
-
-	switch rawGroup := claimValue.(type) {
-	case []string:
-		groups = rawGroup
-	case []any:
-		for _, group := range rawGroup {
-			groups = append(groups, fmt.Sprintf("%s", group))
-		}
-	default:
-		str := fmt.Sprintf("%s", rawGroup)
-		groups = strings.Split(str, ",")
-	}
-	return container.SetOf(groups...)
-}
-
 func syncGroupsToTeams(ctx *context.Context, source *oauth2.Source, gothUser *goth.User, u *user_model.User) error {
 	if source.GroupTeamMap != "" || source.GroupTeamMapRemoval {
 		groupTeamMapping, err := auth_module.UnmarshalGroupTeamMapping(source.GroupTeamMap)
@@ -1141,7 +1406,7 @@ func getClaimedGroups(source *oauth2.Source, gothUser *goth.User) container.Set[
 		return nil
 	}
 
-	return claimValueToStringSet(groupClaims)
+	return oauth2.ClaimValueToStringSet(groupClaims)
 }
 
 func getUserAdminAndRestrictedFromGroupClaims(source *oauth2.Source, gothUser *goth.User) (isAdmin, isRestricted optional.Option[bool]) {
@@ -1273,12 +1538,16 @@ func handleOAuth2SignIn(ctx *context.Context, source *auth.Source, u *user_model
 		// Clear whatever CSRF cookie has right now, force to generate a new one
 		ctx.Csrf.DeleteCookie(ctx)
 
-		opts := &user_service.UpdateOptions{
-			SetLastLogin: true,
-		}
+		opts := &user_service.UpdateOptions{}
 		opts.IsAdmin, opts.IsRestricted = getUserAdminAndRestrictedFromGroupClaims(oauth2Source, &gothUser)
-		if err := user_service.UpdateUser(ctx, u, opts); err != nil {
-			ctx.ServerError("UpdateUser", err)
+		if opts.IsAdmin.Has() || opts.IsRestricted.Has() {
+			if err := user_service.UpdateUser(ctx, u, opts); err != nil {
+				ctx.ServerError("UpdateUser", err)
+				return
+			}
+		}
+		if err := auth_service.SignedIn(ctx, u, audit_service.SignInMethodOAuth2); err != nil {
+			ctx.ServerError("SignedIn", err)
 			return
 		}
 
@@ -1331,6 +1600,7 @@ func handleOAuth2SignIn(ctx *context.Context, source *auth.Source, u *user_model
 		// User needs to use 2FA, save data and redirect to 2FA page.
 		"twofaUid":      u.ID,
 		"twofaRemember": false,
+		"signInMethod":  audit_service.SignInMethodOAuth2,
 	}); err != nil {
 		ctx.ServerError("updateSession", err)
 		return
@@ -1381,8 +1651,10 @@ func encodeCodeChallenge(codeVerifier string) (string, error) {
 
 // OAuth2UserLoginCallback attempts to handle the callback from the OAuth2 provider and if successful
 // login the user
-func oAuth2UserLoginCallback(ctx *context.Context, authSource *auth.Source, request *http.Request, response http.ResponseWriter) (*user_model.User, goth.User, error) {
-	gothUser, err := oAuth2FetchUser(ctx, authSource, request, response)
+// oAuth2UserLoginCallback returns the user logged in by the provider, the failed logins are recorded
+// if the request is the callback of the provider and not an attempt to reuse a previous login.
+func oAuth2UserLoginCallback(ctx *context.Context, authSource *auth.Source, request *http.Request, response http.ResponseWriter, isCallback bool) (*user_model.User, goth.User, error) {
+	gothUser, err := oAuth2FetchUser(ctx, authSource, request, response, isCallback)
 	if err != nil {
 		return nil, goth.User{}, err
 	}
@@ -1395,7 +1667,7 @@ func oAuth2UserLoginCallback(ctx *context.Context, authSource *auth.Source, requ
 	return u, gothUser, err
 }
 
-func oAuth2FetchUser(ctx *context.Context, authSource *auth.Source, request *http.Request, response http.ResponseWriter) (goth.User, error) {
+func oAuth2FetchUser(ctx *context.Context, authSource *auth.Source, request *http.Request, response http.ResponseWriter, isCallback bool) (goth.User, error) {
 	oauth2Source := authSource.Cfg.(*oauth2.Source)
 
 	// Make sure that the response is not an error response.
@@ -1419,7 +1691,11 @@ func oAuth2FetchUser(ctx *context.Context, authSource *auth.Source, request *htt
 	// Proceed to authenticate through goth.
 	codeVerifier, _ := ctx.Session.Get("CodeVerifier").(string)
 	_ = ctx.Session.Delete("CodeVerifier")
-	gothUser, err := oauth2Source.Callback(request, response, codeVerifier)
+	callback := oauth2Source.Callback
+	if isCallback {
+		callback = oauth2Source.AuthenticateCallback
+	}
+	gothUser, err := callback(request, response, codeVerifier)
 	if err != nil {
 		if err.Error() == "securecookie: the value is too long" || strings.Contains(err.Error(), "Data too long") {
 			log.Error("OAuth2 Provider %s returned too long a token. Current max: %d. Either increase the [OAuth2] MAX_TOKEN_LENGTH or reduce the information returned from the OAuth2 provider", authSource.Name, setting.OAuth2.MaxTokenLength)
@@ -1428,21 +1704,6 @@ func oAuth2FetchUser(ctx *context.Context, authSource *auth.Source, request *htt
 		return goth.User{}, err
 	}
 
-	if oauth2Source.RequiredClaimName != "" {
-		claimInterface, has := gothUser.RawData[oauth2Source.RequiredClaimName]
-		if !has {
-			return goth.User{}, user_model.ErrUserProhibitLogin{Name: gothUser.UserID}
-		}
-
-		if oauth2Source.RequiredClaimValue != "" {
-			groups := claimValueToStringSet(claimInterface)
-
-			if !groups.Contains(oauth2Source.RequiredClaimValue) {
-				return goth.User{}, user_model.ErrUserProhibitLogin{Name: gothUser.UserID}
-			}
-		}
-	}
-
 	return gothUser, nil
 }
 
@@ -16,6 +16,7 @@ import (
 	"forgejo.org/modules/setting"
 	"forgejo.org/modules/util"
 	"forgejo.org/modules/web"
+	audit_service "forgejo.org/services/audit"
 	"forgejo.org/services/auth"
 	"forgejo.org/services/context"
 	"forgejo.org/services/forms"
@@ -155,7 +156,7 @@ func signInOpenIDVerify(ctx *context.Context) {
 		log.Trace("User exists, logging in")
 		remember, _ := ctx.Session.Get("openid_signin_remember").(bool)
 		log.Trace("Session stored openid-remember: %t", remember)
-		handleSignIn(ctx, u, remember)
+		handleSignInWithMethod(ctx, u, remember, audit_service.SignInMethodOpenID)
 		return
 	}
 
@@ -264,7 +265,7 @@ func ConnectOpenIDPost(ctx *context.Context) {
 	ctx.Data["EnableOpenIDSignUp"] = setting.Service.EnableOpenIDSignUp
 	ctx.Data["OpenID"] = oid
 
-	u, _, err := auth.UserSignIn(ctx, form.UserName, form.Password)
+	u, _, err := auth.PasswordSignIn(ctx, form.UserName, form.Password)
 	if err != nil {
 		handleSignInError(ctx, form.UserName, &form, tplConnectOID, "ConnectOpenIDPost", err)
 		return
@@ -285,7 +286,7 @@ func ConnectOpenIDPost(ctx *context.Context) {
 
 	remember, _ := ctx.Session.Get("openid_signin_remember").(bool)
 	log.Trace("Session stored openid-remember: %t", remember)
-	handleSignIn(ctx, u, remember)
+	handleSignInWithMethod(ctx, u, remember, audit_service.SignInMethodOpenID)
 }
 
 // RegisterOpenID shows a form to create a new user authenticated via an OpenID URI
@@ -388,5 +389,5 @@ func RegisterOpenIDPost(ctx *context.Context) {
 
 	remember, _ := ctx.Session.Get("openid_signin_remember").(bool)
 	log.Trace("Session stored openid-remember: %t", remember)
-	handleSignIn(ctx, u, remember)
+	handleSignInWithMethod(ctx, u, remember, audit_service.SignInMethodOpenID)
 }
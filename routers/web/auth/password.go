@@ -18,6 +18,7 @@ import (
 	"forgejo.org/modules/timeutil"
 	"forgejo.org/modules/web"
 	"forgejo.org/modules/web/middleware"
+	auth_service "forgejo.org/services/auth"
 	"forgejo.org/services/context"
 	"forgejo.org/services/forms"
 	"forgejo.org/services/mailer"
@@ -316,6 +317,12 @@ func MustChangePasswordPost(ctx *context.Context) {
 		return
 	}
 
+	// Changing the password signed out all sessions, keep the current one.
+	if err := auth_service.RecordSession(ctx.Req, ctx.Session, ctx.Doer.ID); err != nil {
+		ctx.ServerError("RecordSession", err)
+		return
+	}
+
 	ctx.Flash.Success(ctx.Tr("settings.change_password_success"))
 
 	log.Trace("User updated password: %s", ctx.Doer.Name)
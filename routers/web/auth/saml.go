@@ -0,0 +1,124 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/httplib"
+	"forgejo.org/modules/log"
+	saml_module "forgejo.org/modules/saml"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/validation"
+	"forgejo.org/modules/web/middleware"
+	audit_service "forgejo.org/services/audit"
+	"forgejo.org/services/auth/source/saml"
+	"forgejo.org/services/context"
+)
+
+func getSAMLSource(ctx *context.Context) (*auth.Source, *saml.Source) {
+	authSource, err := auth.GetActiveSourceByName(ctx, ctx.Params(":provider"), auth.SAML)
+	if err != nil {
+		if auth.IsErrSourceNotExist(err) {
+			ctx.NotFound("GetActiveSourceByName", err)
+		} else {
+			ctx.ServerError("GetActiveSourceByName", err)
+		}
+		return nil, nil
+	}
+	return authSource, authSource.Cfg.(*saml.Source)
+}
+
+// SignInSAML redirects the user to the identity provider of the SAML source
+func SignInSAML(ctx *context.Context) {
+	_, source := getSAMLSource(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	// the redirect is passed through the identity provider as RelayState
+	redirectTo := ctx.FormString("redirect_to")
+	if redirectTo == "" {
+		redirectTo = ctx.GetSiteCookie("redirect_to")
+	}
+	redirect, err := source.NewAuthnRequestURL(redirectTo)
+	if err != nil {
+		ctx.ServerError("NewAuthnRequestURL", err)
+		return
+	}
+	ctx.Redirect(redirect)
+}
+
+// SAMLAssertionConsumer signs in the user with the response posted by the identity provider of the SAML source
+func SAMLAssertionConsumer(ctx *context.Context) {
+	authSource, source := getSAMLSource(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	u, err := source.AuthenticateResponse(ctx, ctx.Req.PostFormValue("SAMLResponse"))
+	if err != nil {
+		var missingAttribute saml.ErrMissingAttribute
+		switch {
+		case user_model.IsErrUserProhibitLogin(err):
+			log.Info("Failed authentication attempt from %s: %v", ctx.RemoteAddr(), err)
+			ctx.Data["Title"] = ctx.Tr("auth.prohibit_login")
+			ctx.HTML(http.StatusOK, "user/auth/prohibit_login")
+			return
+		case errors.Is(err, saml_module.ErrInvalidResponse),
+			errors.Is(err, saml.ErrUnsolicitedResponse),
+			errors.Is(err, saml.ErrReplayedAssertion):
+			log.Warn("Failed SAML authentication from %s with source %s: %v", ctx.RemoteAddr(), authSource.Name, err)
+			ctx.Flash.Error(ctx.Tr("auth.saml.signin.error"))
+		case errors.As(err, &missingAttribute):
+			log.Warn("Failed SAML authentication from %s with source %s: %v", ctx.RemoteAddr(), authSource.Name, err)
+			ctx.Flash.Error(ctx.Tr("auth.saml.signin.missing_attribute", missingAttribute.Name))
+		case user_model.IsErrUserAlreadyExist(err), user_model.IsErrEmailAlreadyUsed(err):
+			ctx.Flash.Error(ctx.Tr("auth.saml.signin.user_exists"))
+		case db.IsErrNameReserved(err), db.IsErrNamePatternNotAllowed(err), db.IsErrNameCharsNotAllowed(err),
+			validation.IsErrEmailInvalid(err):
+			ctx.Flash.Error(ctx.Tr("auth.saml.signin.invalid_user", err.Error()))
+		default:
+			ctx.ServerError("AuthenticateResponse", err)
+			return
+		}
+		ctx.Redirect(setting.AppSubURL + "/user/login")
+		return
+	}
+
+	// the redirect_to cookie is not sent with the cross-site POST of the identity provider, restore it from the RelayState
+	if relayState := ctx.Req.PostFormValue("RelayState"); relayState != "" && !httplib.IsRiskyRedirectURL(relayState) {
+		middleware.SetRedirectToCookie(ctx.Resp, relayState)
+		ctx.Req.AddCookie(&http.Cookie{Name: "redirect_to", Value: url.QueryEscape(relayState)})
+	}
+
+	handleSignInWithTwoFactor(ctx, authSource, u, false, audit_service.SignInMethodSAML)
+}
+
+// SAMLMetadata returns the service provider metadata to register this instance at the identity provider
+func SAMLMetadata(ctx *context.Context) {
+	_, source := getSAMLSource(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	sp, err := source.ServiceProvider()
+	if err != nil {
+		ctx.ServerError("ServiceProvider", err)
+		return
+	}
+	metadata, err := sp.Metadata()
+	if err != nil {
+		ctx.ServerError("Metadata", err)
+		return
+	}
+	ctx.Resp.Header().Set("Content-Type", "application/samlmetadata+xml")
+	ctx.Resp.WriteHeader(http.StatusOK)
+	_, _ = ctx.Resp.Write(metadata)
+}
@@ -13,6 +13,9 @@ import (
 	"forgejo.org/modules/base"
 	"forgejo.org/modules/log"
 	"forgejo.org/modules/setting"
+	"forgejo.org/modules/util"
+	auth_service "forgejo.org/services/auth"
+	"forgejo.org/services/auth/source/oauth2"
 	"forgejo.org/services/context"
 	"forgejo.org/services/externalaccount"
 
@@ -175,3 +178,81 @@ func WebAuthnLoginAssertionPost(ctx *context.Context) {
 
 	ctx.JSONRedirect(redirect)
 }
+
+// PasskeyLoginAssertion submits a WebAuthn challenge any passkey can answer to sign in without a password
+func PasskeyLoginAssertion(ctx *context.Context) {
+	assertion, sessionData, err := auth_service.BeginPasskeySignIn()
+	if err != nil {
+		ctx.ServerError("BeginPasskeySignIn", err)
+		return
+	}
+
+	if err := ctx.Session.Set("passkeyAssertion", sessionData); err != nil {
+		ctx.ServerError("Session.Set", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, assertion)
+}
+
+// PasskeyLoginAssertionPost validates the signature of the passkey and logs its user in
+func PasskeyLoginAssertionPost(ctx *context.Context) {
+	sessionData, ok := ctx.Session.Get("passkeyAssertion").(*webauthn.SessionData)
+	if !ok || sessionData == nil {
+		ctx.ServerError("UserSignIn", errors.New("not in passkey session"))
+		return
+	}
+	defer func() {
+		_ = ctx.Session.Delete("passkeyAssertion")
+	}()
+
+	parsedResponse, err := protocol.ParseCredentialRequestResponse(ctx.Req)
+	if err != nil {
+		log.Info("Failed passkey authentication attempt from %s: %v", ctx.RemoteAddr(), err)
+		ctx.Status(http.StatusForbidden)
+		return
+	}
+
+	user, err := auth_service.PasskeySignIn(ctx, sessionData, parsedResponse)
+	if err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) || user_model.IsErrUserProhibitLogin(err) || errors.Is(err, oauth2.ErrAuthSourceNotActivated) {
+			log.Info("Failed passkey authentication attempt from %s: %v", ctx.RemoteAddr(), err)
+			ctx.Status(http.StatusForbidden)
+			return
+		}
+		ctx.ServerError("PasskeySignIn", err)
+		return
+	}
+
+	redirect := handlePasskeySignIn(ctx, user, ctx.FormBool("remember"))
+	if ctx.Written() {
+		return
+	}
+	if redirect == "" {
+		redirect = setting.AppSubURL + "/"
+	}
+	ctx.JSONRedirect(redirect)
+}
+
+// RequirePasskey makes the signed in users who must sign in with a passkey register one,
+// and signs them out if they have one but did not sign in with it
+func RequirePasskey(ctx *context.Context) {
+	if !auth_service.PasskeyRequired(ctx.Doer) || ctx.Session.Get("passkeySignIn") == true {
+		return
+	}
+
+	hasPasskey, err := auth.HasPasskeysByUID(ctx, ctx.Doer.ID)
+	if err != nil {
+		ctx.ServerError("HasPasskeysByUID", err)
+		return
+	}
+	if hasPasskey {
+		HandleSignOut(ctx)
+		ctx.Flash.Error(ctx.Tr("auth.passkey_required"))
+		ctx.Redirect(setting.AppSubURL + "/user/login")
+		return
+	}
+	if !isSecuritySetupPath(ctx.Req.URL.Path) {
+		ctx.Flash.Warning(ctx.Tr("auth.passkey_register_required"))
+		ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+	}
+}
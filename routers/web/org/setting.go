@@ -11,6 +11,7 @@ import (
 
 	"forgejo.org/models"
 	"forgejo.org/models/db"
+	"forgejo.org/models/organization"
 	repo_model "forgejo.org/models/repo"
 	user_model "forgejo.org/models/user"
 	"forgejo.org/models/webhook"
@@ -51,6 +52,10 @@ func Settings(ctx *context.Context) {
 	ctx.Data["ContextUser"] = ctx.ContextUser
 	ctx.Data["CooldownPeriod"] = setting.Service.UsernameCooldownPeriod
 
+	if !loadTwoFactorRequired(ctx) {
+		return
+	}
+
 	err := shared_user.LoadHeaderCount(ctx)
 	if err != nil {
 		ctx.ServerError("LoadHeaderCount", err)
@@ -60,6 +65,18 @@ func Settings(ctx *context.Context) {
 	ctx.HTML(http.StatusOK, tplSettingsOptions)
 }
 
+// loadTwoFactorRequired loads whether the organization requires its members to enable two-factor authentication
+func loadTwoFactorRequired(ctx *context.Context) bool {
+	required, err := organization.IsTwoFactorRequired(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("IsTwoFactorRequired", err)
+		return false
+	}
+	ctx.Data["TwoFactorRequired"] = required
+	ctx.Data["TwoFactorRequiredByInstance"] = setting.RequireTwoFactor
+	return true
+}
+
 // SettingsPost response for settings change submitted
 func SettingsPost(ctx *context.Context) {
 	form := web.GetForm(ctx).(*forms.UpdateOrgSettingForm)
@@ -68,6 +85,9 @@ func SettingsPost(ctx *context.Context) {
 	ctx.Data["PageIsSettingsOptions"] = true
 	ctx.Data["CurrentVisibility"] = ctx.Org.Organization.Visibility
 	ctx.Data["CooldownPeriod"] = setting.Service.UsernameCooldownPeriod
+	if !loadTwoFactorRequired(ctx) {
+		return
+	}
 
 	if ctx.HasError() {
 		ctx.HTML(http.StatusOK, tplSettingsOptions)
@@ -0,0 +1,19 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"forgejo.org/modules/base"
+	"forgejo.org/routers/web/shared"
+	"forgejo.org/services/context"
+)
+
+const tplSettingsAudit base.TplName = "org/settings/audit"
+
+// Audit shows the audit events of the organization and its repositories
+func Audit(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings.audit")
+	ctx.Data["PageIsSettingsAudit"] = true
+	shared.AuditEvents(ctx, ctx.Org.Organization.ID, tplSettingsAudit)
+}
@@ -0,0 +1,207 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+
+	audit_model "forgejo.org/models/audit"
+	"forgejo.org/models/organization"
+	"forgejo.org/modules/base"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/util"
+	"forgejo.org/modules/web"
+	audit_service "forgejo.org/services/audit"
+	"forgejo.org/services/context"
+	"forgejo.org/services/forms"
+)
+
+const tplSettingsIPAllowlist base.TplName = "org/settings/ip_allowlist"
+
+// IPAllowlist shows the IP allowlist of the organization
+func IPAllowlist(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings.ip_allowlist")
+	ctx.Data["PageIsSettingsIPAllowlist"] = true
+
+	mode, err := organization.GetIPAllowlistMode(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetIPAllowlistMode", err)
+		return
+	}
+	entries, err := organization.FindIPAllowlistEntries(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("FindIPAllowlistEntries", err)
+		return
+	}
+	ctx.Data["Mode"] = mode
+	ctx.Data["Modes"] = []organization.IPAllowlistMode{organization.IPAllowlistDisabled, organization.IPAllowlistDryRun, organization.IPAllowlistEnforced}
+	ctx.Data["Entries"] = entries
+	ctx.Data["RemoteAddr"] = remoteHost(ctx)
+
+	ctx.HTML(http.StatusOK, tplSettingsIPAllowlist)
+}
+
+// IPAllowlistModePost changes how the IP allowlist of the organization is applied
+func IPAllowlistModePost(ctx *context.Context) {
+	org := ctx.Org.Organization
+	mode := organization.IPAllowlistMode(ctx.FormString("mode"))
+	if !mode.IsValid() {
+		ctx.Error(http.StatusBadRequest)
+		return
+	}
+
+	// prevent the organization owners from locking themselves out
+	if mode == organization.IPAllowlistEnforced {
+		entries, err := organization.FindIPAllowlistEntries(ctx, org.ID)
+		if err != nil {
+			ctx.ServerError("FindIPAllowlistEntries", err)
+			return
+		}
+		if !allowsSelf(ctx, entries) {
+			return
+		}
+	}
+
+	before := ipAllowlistValue(ctx, org.ID)
+	if err := organization.SetIPAllowlistMode(ctx, org.ID, mode); err != nil {
+		ctx.ServerError("SetIPAllowlistMode", err)
+		return
+	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionOrgIPAllowlistUpdate, audit_service.UserTarget(org.AsUser()), before, ipAllowlistValue(ctx, org.ID))
+
+	log.Trace("IP allowlist mode of %s changed to %q by %s", org.Name, mode, ctx.Doer.Name)
+	ctx.Flash.Success(ctx.Tr("org.settings.ip_allowlist.mode_updated"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/ip_allowlist")
+}
+
+// IPAllowlistEntryPost adds a range to the IP allowlist of the organization
+func IPAllowlistEntryPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.OrgIPAllowlistEntryForm)
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(ctx.Org.OrgLink + "/settings/ip_allowlist")
+		return
+	}
+
+	org := ctx.Org.Organization
+	cidr, err := organization.NormalizeIPAllowlistCIDR(form.CIDR)
+	if err != nil {
+		ctx.Flash.Error(ctx.Tr("org.settings.ip_allowlist.entry_invalid", form.CIDR))
+		ctx.Redirect(ctx.Org.OrgLink + "/settings/ip_allowlist")
+		return
+	}
+	entry := &organization.IPAllowlistEntry{
+		OrgID:   org.ID,
+		CIDR:    cidr,
+		Comment: form.Comment,
+	}
+
+	// the first range of an enforced allowlist blocks everyone else
+	mode, err := organization.GetIPAllowlistMode(ctx, org.ID)
+	if err != nil {
+		ctx.ServerError("GetIPAllowlistMode", err)
+		return
+	}
+	entries, err := organization.FindIPAllowlistEntries(ctx, org.ID)
+	if err != nil {
+		ctx.ServerError("FindIPAllowlistEntries", err)
+		return
+	}
+	if mode == organization.IPAllowlistEnforced && !allowsSelf(ctx, append(entries, entry)) {
+		return
+	}
+
+	before := ipAllowlistValue(ctx, org.ID)
+	if err := organization.CreateIPAllowlistEntry(ctx, entry); err != nil {
+		if errors.Is(err, util.ErrAlreadyExist) {
+			ctx.Flash.Error(ctx.Tr("org.settings.ip_allowlist.entry_exists", entry.CIDR))
+			ctx.Redirect(ctx.Org.OrgLink + "/settings/ip_allowlist")
+			return
+		}
+		ctx.ServerError("CreateIPAllowlistEntry", err)
+		return
+	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionOrgIPAllowlistUpdate, audit_service.UserTarget(org.AsUser()), before, ipAllowlistValue(ctx, org.ID))
+
+	ctx.Flash.Success(ctx.Tr("org.settings.ip_allowlist.entry_added", entry.CIDR))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/ip_allowlist")
+}
+
+// IPAllowlistEntryDelete removes a range from the IP allowlist of the organization
+func IPAllowlistEntryDelete(ctx *context.Context) {
+	org := ctx.Org.Organization
+	id := ctx.ParamsInt64(":id")
+
+	// prevent the organization owners from locking themselves out
+	mode, err := organization.GetIPAllowlistMode(ctx, org.ID)
+	if err != nil {
+		ctx.ServerError("GetIPAllowlistMode", err)
+		return
+	}
+	if mode == organization.IPAllowlistEnforced {
+		entries, err := organization.FindIPAllowlistEntries(ctx, org.ID)
+		if err != nil {
+			ctx.ServerError("FindIPAllowlistEntries", err)
+			return
+		}
+		entries = slices.DeleteFunc(entries, func(entry *organization.IPAllowlistEntry) bool {
+			return entry.ID == id
+		})
+		if !allowsSelf(ctx, entries) {
+			return
+		}
+	}
+
+	before := ipAllowlistValue(ctx, org.ID)
+	if err := organization.DeleteIPAllowlistEntry(ctx, org.ID, id); err != nil {
+		ctx.ServerError("DeleteIPAllowlistEntry", err)
+		return
+	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionOrgIPAllowlistUpdate, audit_service.UserTarget(org.AsUser()), before, ipAllowlistValue(ctx, org.ID))
+
+	ctx.Flash.Success(ctx.Tr("org.settings.ip_allowlist.entry_deleted"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/ip_allowlist")
+}
+
+// allowsSelf returns true if the entries allow the client, otherwise the owner is told that the change
+// would lock them out
+func allowsSelf(ctx *context.Context, entries []*organization.IPAllowlistEntry) bool {
+	addr, _ := netip.ParseAddr(remoteHost(ctx))
+	if organization.IPAllowlistContains(entries, addr) {
+		return true
+	}
+	ctx.Flash.Error(ctx.Tr("org.settings.ip_allowlist.self_blocked", remoteHost(ctx)))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/ip_allowlist")
+	return false
+}
+
+// remoteHost returns the address of the client without the port
+func remoteHost(ctx *context.Context) string {
+	host, _, err := net.SplitHostPort(ctx.RemoteAddr())
+	if err != nil {
+		return ctx.RemoteAddr()
+	}
+	return host
+}
+
+// ipAllowlistValue returns the IP allowlist of the organization as it is recorded in the audit events
+func ipAllowlistValue(ctx *context.Context, orgID int64) map[string]any {
+	mode, err := organization.GetIPAllowlistMode(ctx, orgID)
+	if err != nil {
+		log.Error("GetIPAllowlistMode: %v", err)
+	}
+	entries, err := organization.FindIPAllowlistEntries(ctx, orgID)
+	if err != nil {
+		log.Error("FindIPAllowlistEntries: %v", err)
+	}
+	ranges := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ranges = append(ranges, entry.CIDR)
+	}
+	return map[string]any{"mode": string(mode), "ranges": ranges}
+}
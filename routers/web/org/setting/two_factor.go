@@ -0,0 +1,49 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	audit_model "forgejo.org/models/audit"
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/organization"
+	"forgejo.org/modules/log"
+	audit_service "forgejo.org/services/audit"
+	"forgejo.org/services/context"
+)
+
+// TwoFactorPost changes whether the organization requires its members to enable two-factor authentication
+func TwoFactorPost(ctx *context.Context) {
+	org := ctx.Org.Organization
+	required := ctx.FormBool("two_factor_required")
+
+	// prevent the organization owners from locking themselves out
+	if required {
+		hasTwoFactor, err := auth_model.HasTwoFactorByUID(ctx, ctx.Doer.ID)
+		if err != nil {
+			ctx.ServerError("HasTwoFactorByUID", err)
+			return
+		}
+		if !hasTwoFactor {
+			ctx.Flash.Error(ctx.Tr("org.settings.two_factor.self_not_enrolled"))
+			ctx.Redirect(ctx.Org.OrgLink + "/settings")
+			return
+		}
+	}
+
+	wasRequired, err := organization.IsTwoFactorRequired(ctx, org.ID)
+	if err != nil {
+		ctx.ServerError("IsTwoFactorRequired", err)
+		return
+	}
+	if err := organization.SetTwoFactorRequired(ctx, org.ID, required); err != nil {
+		ctx.ServerError("SetTwoFactorRequired", err)
+		return
+	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionOrgTwoFactorRequirement, audit_service.UserTarget(org.AsUser()),
+		map[string]bool{"required": wasRequired}, map[string]bool{"required": required})
+
+	log.Trace("Two-factor authentication requirement of %s changed to %t by %s", org.Name, required, ctx.Doer.Name)
+	ctx.Flash.Success(ctx.Tr("org.settings.two_factor.updated"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings")
+}
@@ -45,6 +45,11 @@ func Applications(ctx *context.Context) {
 	}
 	ctx.Data["Applications"] = apps
 
+	user_setting.LoadApplicationInstallations(ctx, ctx.Org.Organization.ID)
+	if ctx.Written() {
+		return
+	}
+
 	err = shared_user.LoadHeaderCount(ctx)
 	if err != nil {
 		ctx.ServerError("LoadHeaderCount", err)
@@ -93,10 +98,27 @@ func OAuthApplicationsRegenerateSecret(ctx *context.Context) {
 	oa.RegenerateSecret(ctx)
 }
 
+// OAuthApplicationsInstallable handles the post request for editing how the oauth2 application is installed
+func OAuthApplicationsInstallable(ctx *context.Context) {
+	oa := newOAuth2CommonHandlers(ctx.Org)
+	oa.EditInstallable(ctx)
+}
+
 // DeleteOAuth2Application deletes the given oauth2 application
 func DeleteOAuth2Application(ctx *context.Context) {
 	oa := newOAuth2CommonHandlers(ctx.Org)
 	oa.DeleteApp(ctx)
 }
 
+// DeleteOAuth2ApplicationInstallation uninstalls an application from the organization
+func DeleteOAuth2ApplicationInstallation(ctx *context.Context) {
+	if err := auth.DeleteOAuth2ApplicationInstallation(ctx, ctx.ParamsInt64("id"), ctx.Org.Organization.ID); err != nil {
+		ctx.ServerError("DeleteOAuth2ApplicationInstallation", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("settings.oauth2_application_uninstall_success"))
+	ctx.JSONRedirect(fmt.Sprintf("%s/org/%s/settings/applications", setting.AppSubURL, ctx.Org.Organization.Name))
+}
+
 // TODO: revokes the grant with the given id
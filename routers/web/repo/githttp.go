@@ -151,7 +151,7 @@ func httpBase(ctx *context.Context) *serviceHandler {
 			return nil
 		}
 
-		context.CheckRepoScopedToken(ctx, repo, auth_model.GetScopeLevelFromAccessMode(accessMode))
+		context.CheckRepoScopedTokenForUnit(ctx, repo, unitType, auth_model.GetScopeLevelFromAccessMode(accessMode))
 		if ctx.Written() {
 			return nil
 		}
@@ -242,6 +242,24 @@ func httpBase(ctx *context.Context) *serviceHandler {
 		}
 	}
 
+	// the allowlist is checked once the client is authenticated, Actions runners are exempt
+	blocked, err := context.IsBlockedByIPAllowlist(ctx, owner, ctx.Doer, ctx.RemoteAddr())
+	if err != nil {
+		ctx.ServerError("IsBlockedByIPAllowlist", err)
+		return nil
+	} else if blocked {
+		ctx.PlainText(http.StatusForbidden, context.IPAllowlistBlockedMessage)
+		return nil
+	}
+	blocked, err = context.IsBlockedByTwoFactorPolicy(ctx, owner, ctx.Doer)
+	if err != nil {
+		ctx.ServerError("IsBlockedByTwoFactorPolicy", err)
+		return nil
+	} else if blocked {
+		ctx.PlainText(http.StatusForbidden, context.TwoFactorBlockedMessage)
+		return nil
+	}
+
 	if !repoExist {
 		if !receivePack {
 			ctx.PlainText(http.StatusNotFound, "Repository not found")
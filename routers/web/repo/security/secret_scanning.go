@@ -0,0 +1,110 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package security
+
+import (
+	"net/http"
+
+	"forgejo.org/models/db"
+	secretscan_model "forgejo.org/models/secretscan"
+	"forgejo.org/modules/base"
+	"forgejo.org/modules/optional"
+	"forgejo.org/modules/setting"
+	"forgejo.org/services/context"
+	secretscan_service "forgejo.org/services/secretscan"
+)
+
+const (
+	tplSecretScanning base.TplName = "repo/security/secret_scanning"
+)
+
+// SecretScanning lists the secrets found in the repository
+func SecretScanning(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.security.secret_scanning")
+	ctx.Data["PageIsSecurity"] = true
+
+	state := ctx.FormString("state")
+	isOpen := optional.Some(true)
+	if state == "closed" {
+		isOpen = optional.Some(false)
+	} else {
+		state = "open"
+	}
+	ctx.Data["State"] = state
+
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	alerts, count, err := db.FindAndCount[secretscan_model.Alert](ctx, secretscan_model.FindAlertsOptions{
+		ListOptions: db.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.IssuePagingNum,
+		},
+		RepoID: ctx.Repo.Repository.ID,
+		IsOpen: isOpen,
+	})
+	if err != nil {
+		ctx.ServerError("FindAlerts", err)
+		return
+	}
+	if err := secretscan_model.AlertList(alerts).LoadUsers(ctx); err != nil {
+		ctx.ServerError("LoadUsers", err)
+		return
+	}
+	ctx.Data["Alerts"] = alerts
+
+	detectors := make(map[string]string)
+	for _, d := range secretscan_service.Detectors() {
+		detectors[d.ID] = d.Description
+	}
+	ctx.Data["Detectors"] = detectors
+
+	pager := context.NewPagination(int(count), setting.UI.IssuePagingNum, page, 5)
+	pager.AddParamString("state", state)
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplSecretScanning)
+}
+
+// SecretScanningScanPost scans the history of the repository for secrets in the background
+func SecretScanningScanPost(ctx *context.Context) {
+	if err := secretscan_service.QueueHistoryScan(ctx.Repo.Repository); err != nil {
+		ctx.ServerError("QueueHistoryScan", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.security.secret_scanning.scan_queued"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/security")
+}
+
+// SecretScanningAlertPost closes or reopens an alert
+func SecretScanningAlertPost(ctx *context.Context) {
+	alert, err := secretscan_model.GetAlertByID(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if secretscan_model.IsErrAlertNotExist(err) {
+			ctx.NotFound("GetAlertByID", err)
+		} else {
+			ctx.ServerError("GetAlertByID", err)
+		}
+		return
+	}
+
+	state := secretscan_model.AlertState(ctx.FormString("state"))
+	if !state.IsValid() {
+		ctx.Error(http.StatusBadRequest, "invalid state")
+		return
+	}
+	if err := alert.SetState(ctx, state, ctx.Doer.ID); err != nil {
+		ctx.ServerError("SetState", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.security.secret_scanning.alert_updated"))
+	if state == secretscan_model.AlertStateOpen {
+		ctx.Redirect(ctx.Repo.RepoLink + "/security?state=closed")
+	} else {
+		ctx.Redirect(ctx.Repo.RepoLink + "/security")
+	}
+}
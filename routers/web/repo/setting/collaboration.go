@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strings"
 
+	audit_model "forgejo.org/models/audit"
 	"forgejo.org/models/db"
 	"forgejo.org/models/organization"
 	"forgejo.org/models/perm"
@@ -17,6 +18,7 @@ import (
 	"forgejo.org/modules/log"
 	repo_module "forgejo.org/modules/repository"
 	"forgejo.org/modules/setting"
+	audit_service "forgejo.org/services/audit"
 	"forgejo.org/services/context"
 	"forgejo.org/services/mailer"
 	org_service "forgejo.org/services/org"
@@ -116,6 +118,8 @@ func CollaborationPost(ctx *context.Context) {
 		ctx.Redirect(ctx.Repo.RepoLink + "/settings/collaboration")
 		return
 	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionRepoCollaboratorAdd, audit_service.RepoTarget(ctx.Repo.Repository),
+		nil, map[string]any{"collaborator": u.Name, "access_mode": perm.AccessModeWrite.String()})
 
 	if setting.Service.EnableNotifyMail {
 		mailer.SendCollaboratorMail(u, ctx.Doer, ctx.Repo.Repository)
@@ -127,20 +131,28 @@ func CollaborationPost(ctx *context.Context) {
 
 // ChangeCollaborationAccessMode response for changing access of a collaboration
 func ChangeCollaborationAccessMode(ctx *context.Context) {
+	uid := ctx.FormInt64("uid")
+	mode := perm.AccessMode(ctx.FormInt("mode"))
 	if err := repo_model.ChangeCollaborationAccessMode(
 		ctx,
 		ctx.Repo.Repository,
-		ctx.FormInt64("uid"),
-		perm.AccessMode(ctx.FormInt("mode"))); err != nil {
+		uid,
+		mode); err != nil {
 		log.Error("ChangeCollaborationAccessMode: %v", err)
+		return
 	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionRepoCollaboratorAccessMode, audit_service.RepoTarget(ctx.Repo.Repository),
+		nil, map[string]any{"collaborator_id": uid, "access_mode": mode.String()})
 }
 
 // DeleteCollaboration delete a collaboration for a repository
 func DeleteCollaboration(ctx *context.Context) {
-	if err := repo_service.DeleteCollaboration(ctx, ctx.Repo.Repository, ctx.FormInt64("id")); err != nil {
+	uid := ctx.FormInt64("id")
+	if err := repo_service.DeleteCollaboration(ctx, ctx.Repo.Repository, uid); err != nil {
 		ctx.Flash.Error("DeleteCollaboration: " + err.Error())
 	} else {
+		audit_service.Record(ctx, ctx.Doer, audit_model.ActionRepoCollaboratorRemove, audit_service.RepoTarget(ctx.Repo.Repository),
+			map[string]any{"collaborator_id": uid}, nil)
 		ctx.Flash.Success(ctx.Tr("repo.settings.remove_collaborator_success"))
 	}
 
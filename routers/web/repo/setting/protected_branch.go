@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	audit_model "forgejo.org/models/audit"
 	git_model "forgejo.org/models/git"
 	"forgejo.org/models/organization"
 	"forgejo.org/models/perm"
@@ -18,6 +19,7 @@ import (
 	"forgejo.org/modules/base"
 	"forgejo.org/modules/web"
 	"forgejo.org/routers/web/repo"
+	audit_service "forgejo.org/services/audit"
 	"forgejo.org/services/context"
 	"forgejo.org/services/forms"
 	pull_service "forgejo.org/services/pull"
@@ -152,6 +154,12 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 			RuleName: f.RuleName,
 		}
 	}
+	// remember the rule as it was for the audit log, new rules have no previous state
+	var before *git_model.ProtectedBranch
+	if protectBranch.ID > 0 {
+		rule := *protectBranch
+		before = &rule
+	}
 
 	var whitelistUsers, whitelistTeams, mergeWhitelistUsers, mergeWhitelistTeams, approvalsWhitelistUsers, approvalsWhitelistTeams []int64
 	protectBranch.RuleName = f.RuleName
@@ -251,6 +259,7 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 		ctx.ServerError("UpdateProtectBranch", err)
 		return
 	}
+	audit_service.RecordProtectedBranch(ctx, ctx.Doer, ctx.Repo.Repository, audit_model.ActionRepoBranchProtectionUpdate, before, protectBranch)
 
 	// FIXME: since we only need to recheck files protected rules, we could improve this
 	matchedBranches, err := git_model.FindAllMatchedBranches(ctx, ctx.Repo.Repository.ID, protectBranch.RuleName)
@@ -296,6 +305,7 @@ func DeleteProtectedBranchRulePost(ctx *context.Context) {
 		ctx.JSONRedirect(fmt.Sprintf("%s/settings/branches", ctx.Repo.RepoLink))
 		return
 	}
+	audit_service.RecordProtectedBranch(ctx, ctx.Doer, ctx.Repo.Repository, audit_model.ActionRepoBranchProtectionDelete, rule, nil)
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.remove_protected_branch_success", rule.RuleName))
 	ctx.JSONRedirect(fmt.Sprintf("%s/settings/branches", ctx.Repo.RepoLink))
@@ -34,6 +34,7 @@ import (
 	"forgejo.org/modules/web"
 	actions_service "forgejo.org/services/actions"
 	asymkey_service "forgejo.org/services/asymkey"
+	audit_service "forgejo.org/services/audit"
 	"forgejo.org/services/context"
 	"forgejo.org/services/federation"
 	"forgejo.org/services/forms"
@@ -387,6 +388,9 @@ func SettingsPost(ctx *context.Context) {
 			ctx.ServerError("UpdateRepository", err)
 			return
 		}
+		if visibilityChanged {
+			audit_service.RecordRepoVisibility(ctx, ctx.Doer, repo)
+		}
 		log.Trace("Repository basic settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
@@ -0,0 +1,68 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package shared
+
+import (
+	"net/http"
+	"time"
+
+	audit_model "forgejo.org/models/audit"
+	"forgejo.org/models/db"
+	"forgejo.org/modules/base"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/timeutil"
+	"forgejo.org/services/context"
+)
+
+// AuditEvents renders the audit events of the owner, or of the whole instance if ownerID is 0.
+// The events can be filtered by action, actor and a range of days.
+func AuditEvents(ctx *context.Context, ownerID int64, tpl base.TplName) {
+	if !setting.Audit.Enabled {
+		ctx.NotFound("MustEnableAudit", nil)
+		return
+	}
+
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	opts := audit_model.FindEventsOptions{
+		ListOptions: db.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.Admin.NoticePagingNum,
+		},
+		Action:    audit_model.Action(ctx.FormTrim("action")),
+		ActorName: ctx.FormTrim("actor"),
+		OwnerID:   ownerID,
+	}
+	if since, err := time.ParseInLocation(time.DateOnly, ctx.FormTrim("since"), setting.DefaultUILocation); err == nil {
+		opts.Since = timeutil.TimeStamp(since.Unix())
+	}
+	// the until day is included
+	if until, err := time.ParseInLocation(time.DateOnly, ctx.FormTrim("until"), setting.DefaultUILocation); err == nil {
+		opts.Before = timeutil.TimeStamp(until.AddDate(0, 0, 1).Unix())
+	}
+
+	events, total, err := db.FindAndCount[audit_model.Event](ctx, opts)
+	if err != nil {
+		ctx.ServerError("FindAuditEvents", err)
+		return
+	}
+	ctx.Data["Events"] = events
+	ctx.Data["Total"] = total
+	ctx.Data["Actions"] = audit_model.Actions
+	ctx.Data["Action"] = string(opts.Action)
+	ctx.Data["Actor"] = opts.ActorName
+	ctx.Data["Since"] = ctx.FormTrim("since")
+	ctx.Data["Until"] = ctx.FormTrim("until")
+
+	pager := context.NewPagination(int(total), opts.PageSize, page, 5)
+	pager.AddParam(ctx, "action", "Action")
+	pager.AddParam(ctx, "actor", "Actor")
+	pager.AddParam(ctx, "since", "Since")
+	pager.AddParam(ctx, "until", "Until")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tpl)
+}
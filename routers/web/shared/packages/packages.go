@@ -22,6 +22,7 @@ import (
 	"forgejo.org/services/forms"
 	cargo_service "forgejo.org/services/packages/cargo"
 	container_service "forgejo.org/services/packages/container"
+	hex_service "forgejo.org/services/packages/hex"
 )
 
 func SetPackagesContext(ctx *context.Context, owner *user_model.User) {
@@ -38,6 +39,12 @@ func SetPackagesContext(ctx *context.Context, owner *user_model.User) {
 		ctx.ServerError("IsRepositoryModelExist", err)
 		return
 	}
+
+	ctx.Data["HexPublicKey"], err = hex_service.GetPublicKey(ctx, owner.ID)
+	if err != nil {
+		ctx.ServerError("GetPublicKey", err)
+		return
+	}
 }
 
 func SetRuleAddContext(ctx *context.Context) {
@@ -258,3 +265,12 @@ func RebuildCargoIndex(ctx *context.Context, owner *user_model.User) {
 		ctx.Flash.Success(ctx.Tr("packages.owner.settings.cargo.rebuild.success"))
 	}
 }
+
+func RegenerateHexKeyPair(ctx *context.Context, owner *user_model.User) {
+	if err := hex_service.RegenerateKeyPair(ctx, owner.ID); err != nil {
+		log.Error("RegenerateKeyPair failed: %v", err)
+		ctx.Flash.Error(ctx.Tr("packages.owner.settings.hex.keypair.error", err))
+	} else {
+		ctx.Flash.Success(ctx.Tr("packages.owner.settings.hex.keypair.success"))
+	}
+}
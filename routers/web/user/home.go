@@ -134,6 +134,18 @@ func Dashboard(ctx *context.Context) {
 
 	ctx.Data["Feeds"] = feeds
 
+	if setting.Federation.Enabled && ctxUser.ID == ctx.Doer.ID && page == 1 && ctx.FormString("date") == "" {
+		federatedActivities, err := activities_model.GetFederatedUserActivitiesForFollower(ctx, ctx.Doer.ID, db.ListOptions{
+			Page:     1,
+			PageSize: setting.UI.FeedPagingNum,
+		})
+		if err != nil {
+			ctx.ServerError("GetFederatedUserActivitiesForFollower", err)
+			return
+		}
+		ctx.Data["FederatedActivities"] = federatedActivities
+	}
+
 	pager := context.NewPagination(int(count), setting.UI.FeedPagingNum, page, 5)
 	pager.AddParam(ctx, "date", "Date")
 	ctx.Data["Page"] = pager
@@ -4,6 +4,7 @@
 package user
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"slices"
@@ -30,6 +31,7 @@ import (
 	"forgejo.org/services/context"
 	"forgejo.org/services/forms"
 	packages_service "forgejo.org/services/packages"
+	packages_scan "forgejo.org/services/packages/scan"
 )
 
 const (
@@ -294,6 +296,37 @@ func ViewPackageVersion(ctx *context.Context) {
 	}
 	ctx.Data["HasRepositoryAccess"] = hasRepositoryAccess
 
+	vulnerabilities, err := packages_model.GetVulnerabilitiesByVersionID(ctx, pd.Version.ID)
+	if err != nil {
+		ctx.ServerError("GetVulnerabilitiesByVersionID", err)
+		return
+	}
+	ctx.Data["Vulnerabilities"] = vulnerabilities
+
+	channels, err := packages_model.GetChannelsByVersionID(ctx, pd.Version.ID)
+	if err != nil {
+		ctx.ServerError("GetChannelsByVersionID", err)
+		return
+	}
+	ctx.Data["Channels"] = channels
+	ctx.Data["Licenses"] = packages_scan.Licenses(pd)
+
+	provenance, err := packages_service.GetVersionProvenance(ctx, pd.Version)
+	if err != nil {
+		ctx.ServerError("GetVersionProvenance", err)
+		return
+	}
+	if provenance != nil && provenance.Repository != nil {
+		permission, err := access_model.GetUserRepoPermission(ctx, provenance.Repository, ctx.Doer)
+		if err != nil {
+			ctx.ServerError("GetUserRepoPermission", err)
+			return
+		}
+		if permission.HasAccess() {
+			ctx.Data["Provenance"] = provenance
+		}
+	}
+
 	err = shared_user.LoadHeaderCount(ctx)
 	if err != nil {
 		ctx.ServerError("LoadHeaderCount", err)
@@ -469,11 +502,25 @@ func PackageSettingsPost(ctx *context.Context) {
 			ctx.Flash.Error(ctx.Tr("packages.settings.link.error"))
 		}
 
+		ctx.Redirect(ctx.Link)
+		return
+	case "lock":
+		if err := packages_service.LockPackageVersion(ctx, pd.Version); err != nil {
+			log.Error("Error locking package version: %v", err)
+			ctx.Flash.Error(ctx.Tr("packages.settings.lock.error"))
+		} else {
+			ctx.Flash.Success(ctx.Tr("packages.settings.lock.success"))
+		}
+
 		ctx.Redirect(ctx.Link)
 		return
 	case "delete":
 		err := packages_service.RemovePackageVersion(ctx, ctx.Doer, ctx.Package.Descriptor.Version)
-		if err != nil {
+		if errors.Is(err, packages_model.ErrPackageVersionImmutable) {
+			ctx.Flash.Error(ctx.Tr("packages.settings.delete.immutable"))
+			ctx.Redirect(ctx.Link)
+			return
+		} else if err != nil {
 			log.Error("Error deleting package: %v", err)
 			ctx.Flash.Error(ctx.Tr("packages.settings.delete.error"))
 		} else {
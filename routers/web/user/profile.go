@@ -28,6 +28,7 @@ import (
 	"forgejo.org/routers/web/org"
 	shared_user "forgejo.org/routers/web/shared/user"
 	"forgejo.org/services/context"
+	"forgejo.org/services/federation"
 	user_service "forgejo.org/services/user"
 )
 
@@ -363,9 +364,17 @@ func Action(ctx *context.Context) {
 
 	switch action {
 	case "follow":
-		err = user_model.FollowUser(ctx, ctx.Doer.ID, ctx.ContextUser.ID)
+		if ctx.ContextUser.IsRemote() {
+			err = federation.FollowRemoteUser(ctx, ctx.Doer, ctx.ContextUser)
+		} else {
+			err = user_model.FollowUser(ctx, ctx.Doer.ID, ctx.ContextUser.ID)
+		}
 	case "unfollow":
-		err = user_model.UnfollowUser(ctx, ctx.Doer.ID, ctx.ContextUser.ID)
+		if ctx.ContextUser.IsRemote() {
+			err = federation.UnfollowRemoteUser(ctx, ctx.Doer, ctx.ContextUser)
+		} else {
+			err = user_model.UnfollowUser(ctx, ctx.Doer.ID, ctx.ContextUser.ID)
+		}
 	case "block":
 		err = user_service.BlockUser(ctx, ctx.Doer.ID, ctx.ContextUser.ID)
 	case "unblock":
@@ -81,6 +81,12 @@ func AccountPost(ctx *context.Context) {
 				return
 			}
 		} else {
+			// Changing the password signed out all sessions, keep the current one.
+			if err := auth.RecordSession(ctx.Req, ctx.Session, ctx.Doer.ID); err != nil {
+				ctx.ServerError("RecordSession", err)
+				return
+			}
+
 			// Re-generate LTA cookie.
 			if len(ctx.GetSiteCookie(setting.CookieRememberName)) != 0 {
 				if err := ctx.SetLTACookie(ctx.Doer); err != nil {
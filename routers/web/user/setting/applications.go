@@ -5,14 +5,26 @@
 package setting
 
 import (
+	"html/template"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
 
+	audit_model "forgejo.org/models/audit"
 	auth_model "forgejo.org/models/auth"
 	"forgejo.org/models/db"
+	"forgejo.org/models/organization"
+	access_model "forgejo.org/models/perm/access"
+	repo_model "forgejo.org/models/repo"
+	user_model "forgejo.org/models/user"
 	"forgejo.org/modules/base"
 	"forgejo.org/modules/log"
 	"forgejo.org/modules/setting"
+	"forgejo.org/modules/timeutil"
 	"forgejo.org/modules/web"
+	audit_service "forgejo.org/services/audit"
 	"forgejo.org/services/context"
 	"forgejo.org/services/forms"
 )
@@ -73,6 +85,116 @@ func ApplicationsPost(ctx *context.Context) {
 		ctx.ServerError("NewAccessToken", err)
 		return
 	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionUserAccessTokenCreate, audit_service.AccessTokenTarget(t), nil, map[string]any{"scope": t.Scope})
+
+	ctx.Flash.Success(ctx.Tr("settings.generate_token_success"))
+	ctx.Flash.Info(t.Token)
+
+	ctx.Redirect(setting.AppSubURL + "/user/settings/applications")
+}
+
+// ApplicationsFineGrainedPost response for add user's fine-grained access token
+func ApplicationsFineGrainedPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.NewFineGrainedAccessTokenForm)
+	ctx.Data["Title"] = ctx.Tr("settings")
+	ctx.Data["PageIsSettingsApplications"] = true
+
+	if ctx.HasError() {
+		loadApplicationsData(ctx)
+
+		ctx.HTML(http.StatusOK, tplSettingsApplications)
+		return
+	}
+
+	fail := func(msg template.HTML) {
+		ctx.Flash.Error(msg)
+		ctx.Redirect(setting.AppSubURL + "/user/settings/applications")
+	}
+
+	permissions, err := auth_model.ParseAccessTokenRepoPermissions(strings.Join(form.Permission, ","))
+	if err != nil || permissions == "" {
+		fail(ctx.Tr("settings.at_least_one_permission"))
+		return
+	}
+	expires, err := time.ParseInLocation(time.DateOnly, form.Expires, setting.DefaultUILocation)
+	if err != nil || !expires.After(time.Now()) {
+		fail(ctx.Tr("settings.fine_grained_token.invalid_expiry"))
+		return
+	}
+
+	t := &auth_model.AccessToken{
+		UID:             ctx.Doer.ID,
+		Name:            form.Name,
+		FineGrained:     true,
+		RepoPermissions: permissions,
+		// the token can be used until the end of the day it expires
+		ExpiresUnix: timeutil.TimeStamp(expires.AddDate(0, 0, 1).Unix()),
+	}
+
+	if form.Resource == "owner" {
+		owner, err := user_model.GetUserByName(ctx, form.Owner)
+		if err != nil && !user_model.IsErrUserNotExist(err) {
+			ctx.ServerError("GetUserByName", err)
+			return
+		}
+		isMember := false
+		if owner != nil && owner.IsOrganization() {
+			if isMember, err = organization.IsOrganizationMember(ctx, owner.ID, ctx.Doer.ID); err != nil {
+				ctx.ServerError("IsOrganizationMember", err)
+				return
+			}
+		}
+		if owner == nil || (owner.ID != ctx.Doer.ID && !isMember) {
+			fail(ctx.Tr("settings.fine_grained_token.owner_not_found", form.Owner))
+			return
+		}
+		t.ResourceOwnerID = owner.ID
+	} else {
+		for _, fullName := range strings.FieldsFunc(form.Repositories, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+			ownerName, repoName, _ := strings.Cut(fullName, "/")
+			repo, err := repo_model.GetRepositoryByOwnerAndName(ctx, ownerName, repoName)
+			if err != nil && !repo_model.IsErrRepoNotExist(err) {
+				ctx.ServerError("GetRepositoryByOwnerAndName", err)
+				return
+			}
+			hasAccess := false
+			if repo != nil {
+				permission, err := access_model.GetUserRepoPermission(ctx, repo, ctx.Doer)
+				if err != nil {
+					ctx.ServerError("GetUserRepoPermission", err)
+					return
+				}
+				hasAccess = permission.HasAccess()
+			}
+			if !hasAccess {
+				fail(ctx.Tr("settings.fine_grained_token.repository_not_found", fullName))
+				return
+			}
+			if !slices.Contains(t.RepoIDs, repo.ID) {
+				t.RepoIDs = append(t.RepoIDs, repo.ID)
+			}
+		}
+		if len(t.RepoIDs) == 0 {
+			fail(ctx.Tr("settings.fine_grained_token.no_repositories"))
+			return
+		}
+	}
+
+	exist, err := auth_model.AccessTokenByNameExists(ctx, t)
+	if err != nil {
+		ctx.ServerError("AccessTokenByNameExists", err)
+		return
+	}
+	if exist {
+		fail(ctx.Tr("settings.generate_token_name_duplicate", t.Name))
+		return
+	}
+
+	if err := auth_model.NewAccessToken(ctx, t); err != nil {
+		ctx.ServerError("NewAccessToken", err)
+		return
+	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionUserAccessTokenCreate, audit_service.AccessTokenTarget(t), nil, map[string]any{"scope": t.Scope})
 
 	ctx.Flash.Success(ctx.Tr("settings.generate_token_success"))
 	ctx.Flash.Info(t.Token)
@@ -82,9 +204,11 @@ func ApplicationsPost(ctx *context.Context) {
 
 // DeleteApplication response for delete user access token
 func DeleteApplication(ctx *context.Context) {
-	if err := auth_model.DeleteAccessTokenByID(ctx, ctx.FormInt64("id"), ctx.Doer.ID); err != nil {
+	id := ctx.FormInt64("id")
+	if err := auth_model.DeleteAccessTokenByID(ctx, id, ctx.Doer.ID); err != nil {
 		ctx.Flash.Error("DeleteAccessTokenByID: " + err.Error())
 	} else {
+		audit_service.Record(ctx, ctx.Doer, audit_model.ActionUserAccessTokenDelete, audit_service.AccessTokenTarget(&auth_model.AccessToken{ID: id, UID: ctx.Doer.ID}), nil, nil)
 		ctx.Flash.Success(ctx.Tr("settings.delete_token_success"))
 	}
 
@@ -116,6 +240,44 @@ func loadApplicationsData(ctx *context.Context) {
 		return
 	}
 	ctx.Data["Tokens"] = tokens
+
+	// the repositories and owners fine-grained tokens are limited to
+	var repoIDs, ownerIDs []int64
+	for _, t := range tokens {
+		repoIDs = append(repoIDs, t.RepoIDs...)
+		if t.ResourceOwnerID > 0 {
+			ownerIDs = append(ownerIDs, t.ResourceOwnerID)
+		}
+	}
+	ctx.Data["TokenRepos"], err = repo_model.GetRepositoriesMapByIDs(ctx, repoIDs)
+	if err != nil {
+		ctx.ServerError("GetRepositoriesMapByIDs", err)
+		return
+	}
+	owners, err := user_model.GetUsersByIDs(ctx, ownerIDs)
+	if err != nil {
+		ctx.ServerError("GetUsersByIDs", err)
+		return
+	}
+	tokenOwners := make(map[int64]*user_model.User, len(owners))
+	for _, owner := range owners {
+		tokenOwners[owner.ID] = owner
+	}
+	ctx.Data["TokenOwners"] = tokenOwners
+
+	orgs, err := db.Find[organization.Organization](ctx, organization.FindOrgOptions{
+		UserID:         ctx.Doer.ID,
+		IncludeLimited: true,
+		IncludePrivate: true,
+	})
+	if err != nil {
+		ctx.ServerError("FindOrgs", err)
+		return
+	}
+	ctx.Data["FineGrainedTokenOrgs"] = orgs
+	ctx.Data["FineGrainedTokenPermissions"] = auth_model.AllAccessTokenRepoPermissions
+	ctx.Data["FineGrainedTokenMinExpiry"] = time.Now().In(setting.DefaultUILocation).Format(time.DateOnly)
+	ctx.Data["FineGrainedTokenDefaultExpiry"] = time.Now().In(setting.DefaultUILocation).AddDate(0, 0, 30).Format(time.DateOnly)
 	ctx.Data["EnableOAuth2"] = setting.OAuth2.Enabled
 	ctx.Data["IsAdmin"] = ctx.Doer.IsAdmin
 	if setting.OAuth2.Enabled {
@@ -132,5 +294,6 @@ func loadApplicationsData(ctx *context.Context) {
 			return
 		}
 		ctx.Data["EnableAdditionalGrantScopes"] = setting.OAuth2.EnableAdditionalGrantScopes
+		LoadApplicationInstallations(ctx, ctx.Doer.ID)
 	}
 }
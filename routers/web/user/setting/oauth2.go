@@ -49,6 +49,12 @@ func OAuthApplicationsRegenerateSecret(ctx *context.Context) {
 	oa.RegenerateSecret(ctx)
 }
 
+// OAuthApplicationsInstallable handles the post request for editing how the oauth2 application is installed
+func OAuthApplicationsInstallable(ctx *context.Context) {
+	oa := newOAuth2CommonHandlers(ctx.Doer.ID)
+	oa.EditInstallable(ctx)
+}
+
 // OAuth2ApplicationShow displays the given application
 func OAuth2ApplicationShow(ctx *context.Context) {
 	oa := newOAuth2CommonHandlers(ctx.Doer.ID)
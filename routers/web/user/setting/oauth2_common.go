@@ -4,14 +4,21 @@
 package setting
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"forgejo.org/models/auth"
+	user_model "forgejo.org/models/user"
 	"forgejo.org/modules/base"
+	"forgejo.org/modules/setting"
 	"forgejo.org/modules/util"
+	"forgejo.org/modules/validation"
 	"forgejo.org/modules/web"
 	shared_user "forgejo.org/routers/web/shared/user"
+	"forgejo.org/services/apps"
 	"forgejo.org/services/context"
 	"forgejo.org/services/forms"
 )
@@ -27,6 +34,21 @@ func (oa *OAuth2CommonHandlers) renderEditPage(ctx *context.Context) {
 	app := ctx.Data["App"].(*auth.OAuth2Application)
 	ctx.Data["FormActionPath"] = fmt.Sprintf("%s/%d", oa.BasePathEditPrefix, app.ID)
 
+	ctx.Data["AppRepoPermissions"] = auth.AllAccessTokenRepoPermissions
+	hook, err := apps.GetWebhook(ctx, app)
+	if err != nil {
+		ctx.ServerError("GetWebhook", err)
+		return
+	}
+	ctx.Data["AppWebhook"] = hook
+	if app.BotUserID > 0 {
+		if ctx.Data["AppBot"], err = user_model.GetUserByID(ctx, app.BotUserID); err != nil {
+			ctx.ServerError("GetUserByID", err)
+			return
+		}
+		ctx.Data["AppInstallLink"] = setting.AppURL + "user/settings/applications/install/" + url.PathEscape(app.ClientID)
+	}
+
 	if ctx.ContextUser != nil && ctx.ContextUser.IsOrganization() {
 		if err := shared_user.LoadHeaderCount(ctx); err != nil {
 			ctx.ServerError("LoadHeaderCount", err)
@@ -128,6 +150,60 @@ func (oa *OAuth2CommonHandlers) EditSave(ctx *context.Context) {
 	ctx.Redirect(oa.BasePathList)
 }
 
+// EditInstallable saves how the oauth2 application is installed
+func (oa *OAuth2CommonHandlers) EditInstallable(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.EditOAuth2ApplicationInstallableForm)
+
+	app, err := auth.GetOAuth2ApplicationByID(ctx, ctx.ParamsInt64("id"))
+	if err != nil {
+		if auth.IsErrOAuthApplicationNotFound(err) {
+			ctx.NotFound("Application not found", err)
+			return
+		}
+		ctx.ServerError("GetOAuth2ApplicationByID", err)
+		return
+	}
+	if app.UID != oa.OwnerID {
+		ctx.NotFound("Application not found", nil)
+		return
+	}
+	editLink := fmt.Sprintf("%s/%d", oa.BasePathEditPrefix, app.ID)
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(editLink)
+		return
+	}
+	permissions, err := auth.ParseAccessTokenRepoPermissions(strings.Join(form.Permission, ","))
+	if err != nil {
+		ctx.Flash.Error(ctx.Tr("settings.at_least_one_permission"))
+		ctx.Redirect(editLink)
+		return
+	}
+	if form.WebhookURL != "" && !validation.IsValidURL(form.WebhookURL) {
+		ctx.Flash.Error(ctx.Tr("form.url_error", form.WebhookURL))
+		ctx.Redirect(editLink)
+		return
+	}
+
+	if err := apps.UpdateSettings(ctx, app, apps.Settings{
+		Permissions:   permissions,
+		PublicKey:     form.PublicKey,
+		WebhookURL:    form.WebhookURL,
+		WebhookSecret: form.WebhookSecret,
+	}); err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Flash.Error(ctx.Tr("settings.oauth2_application_invalid_public_key", err.Error()))
+			ctx.Redirect(editLink)
+			return
+		}
+		ctx.ServerError("UpdateSettings", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("settings.update_oauth2_application_success"))
+	ctx.Redirect(editLink)
+}
+
 // RegenerateSecret regenerates the secret
 func (oa *OAuth2CommonHandlers) RegenerateSecret(ctx *context.Context) {
 	app, err := auth.GetOAuth2ApplicationByID(ctx, ctx.ParamsInt64("id"))
@@ -0,0 +1,186 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"net/http"
+	"strings"
+	"unicode"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	"forgejo.org/models/organization"
+	repo_model "forgejo.org/models/repo"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/base"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/web"
+	"forgejo.org/services/apps"
+	"forgejo.org/services/context"
+	"forgejo.org/services/forms"
+)
+
+const tplSettingsApplicationInstall base.TplName = "user/settings/applications_install"
+
+// LoadApplicationInstallations loads the applications installed on the owner and the repositories they are limited to
+func LoadApplicationInstallations(ctx *context.Context, ownerID int64) {
+	insts, err := db.Find[auth_model.OAuth2ApplicationInstallation](ctx, auth_model.FindOAuth2ApplicationInstallationsOptions{
+		OwnerID: ownerID,
+	})
+	if err != nil {
+		ctx.ServerError("FindOAuth2ApplicationInstallations", err)
+		return
+	}
+	var repoIDs []int64
+	for _, inst := range insts {
+		if err := inst.LoadApp(ctx); err != nil {
+			ctx.ServerError("LoadApp", err)
+			return
+		}
+		repoIDs = append(repoIDs, inst.RepoIDs...)
+	}
+	ctx.Data["Installations"] = insts
+	ctx.Data["InstallationRepos"], err = repo_model.GetRepositoriesMapByIDs(ctx, repoIDs)
+	if err != nil {
+		ctx.ServerError("GetRepositoriesMapByIDs", err)
+		return
+	}
+}
+
+// installableApplication returns the application to install, it renders a not found page if it can not be installed
+func installableApplication(ctx *context.Context) *auth_model.OAuth2Application {
+	app, err := auth_model.GetOAuth2ApplicationByClientID(ctx, ctx.Params("clientid"))
+	if err != nil {
+		if auth_model.IsErrOauthClientIDInvalid(err) {
+			ctx.NotFound("GetOAuth2ApplicationByClientID", err)
+		} else {
+			ctx.ServerError("GetOAuth2ApplicationByClientID", err)
+		}
+		return nil
+	}
+	if !app.IsInstallable() {
+		ctx.NotFound("IsInstallable", nil)
+		return nil
+	}
+	return app
+}
+
+// installationOwners returns the signed user and the organizations they own, applications can be installed on them
+func installationOwners(ctx *context.Context) ([]*user_model.User, error) {
+	orgs, err := db.Find[organization.Organization](ctx, organization.FindOrgOptions{
+		UserID:         ctx.Doer.ID,
+		IncludeLimited: true,
+		IncludePrivate: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	owners := []*user_model.User{ctx.Doer}
+	for _, org := range orgs {
+		isOwner, err := org.IsOwnedBy(ctx, ctx.Doer.ID)
+		if err != nil {
+			return nil, err
+		}
+		if isOwner {
+			owners = append(owners, org.AsUser())
+		}
+	}
+	return owners, nil
+}
+
+// InstallOAuth2Application displays the page to install an application on the signed user or an organization they own
+func InstallOAuth2Application(ctx *context.Context) {
+	app := installableApplication(ctx)
+	if ctx.Written() {
+		return
+	}
+	owners, err := installationOwners(ctx)
+	if err != nil {
+		ctx.ServerError("installationOwners", err)
+		return
+	}
+
+	ctx.Data["Title"] = ctx.Tr("settings.oauth2_application_install", app.Name)
+	ctx.Data["PageIsSettingsApplications"] = true
+	ctx.Data["App"] = app
+	ctx.Data["InstallationOwners"] = owners
+	ctx.HTML(http.StatusOK, tplSettingsApplicationInstall)
+}
+
+// InstallOAuth2ApplicationPost installs an application on the signed user or an organization they own
+func InstallOAuth2ApplicationPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.InstallOAuth2ApplicationForm)
+	app := installableApplication(ctx)
+	if ctx.Written() {
+		return
+	}
+	link := setting.AppSubURL + "/user/settings/applications/install/" + app.ClientID
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(link)
+		return
+	}
+
+	owners, err := installationOwners(ctx)
+	if err != nil {
+		ctx.ServerError("installationOwners", err)
+		return
+	}
+	var owner *user_model.User
+	for _, o := range owners {
+		if o.LowerName == strings.ToLower(form.Owner) {
+			owner = o
+		}
+	}
+	if owner == nil {
+		ctx.Flash.Error(ctx.Tr("settings.fine_grained_token.owner_not_found", form.Owner))
+		ctx.Redirect(link)
+		return
+	}
+
+	var repos []*repo_model.Repository
+	if form.Resource == "repositories" {
+		for _, name := range strings.FieldsFunc(form.Repositories, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+			repo, err := repo_model.GetRepositoryByName(ctx, owner.ID, name)
+			if err != nil {
+				if repo_model.IsErrRepoNotExist(err) {
+					ctx.Flash.Error(ctx.Tr("settings.fine_grained_token.repository_not_found", owner.Name+"/"+name))
+					ctx.Redirect(link)
+					return
+				}
+				ctx.ServerError("GetRepositoryByName", err)
+				return
+			}
+			repos = append(repos, repo)
+		}
+		if len(repos) == 0 {
+			ctx.Flash.Error(ctx.Tr("settings.fine_grained_token.no_repositories"))
+			ctx.Redirect(link)
+			return
+		}
+	}
+
+	if _, err := apps.Install(ctx, app, ctx.Doer, owner, repos); err != nil {
+		ctx.ServerError("Install", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("settings.oauth2_application_install_success", app.Name, owner.Name))
+	if owner.IsOrganization() {
+		ctx.Redirect(owner.OrganisationLink() + "/settings/applications")
+		return
+	}
+	ctx.Redirect(setting.AppSubURL + "/user/settings/applications")
+}
+
+// DeleteOAuth2ApplicationInstallation uninstalls an application from the signed user
+func DeleteOAuth2ApplicationInstallation(ctx *context.Context) {
+	if err := auth_model.DeleteOAuth2ApplicationInstallation(ctx, ctx.ParamsInt64("id"), ctx.Doer.ID); err != nil {
+		ctx.ServerError("DeleteOAuth2ApplicationInstallation", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("settings.oauth2_application_uninstall_success"))
+	ctx.JSONRedirect(setting.AppSubURL + "/user/settings/applications")
+}
@@ -12,10 +12,13 @@ import (
 	"net/http"
 	"strings"
 
+	audit_model "forgejo.org/models/audit"
 	"forgejo.org/models/auth"
 	"forgejo.org/modules/log"
 	"forgejo.org/modules/setting"
 	"forgejo.org/modules/web"
+	audit_service "forgejo.org/services/audit"
+	auth_service "forgejo.org/services/auth"
 	"forgejo.org/services/context"
 	"forgejo.org/services/forms"
 	"forgejo.org/services/mailer"
@@ -67,6 +70,18 @@ func DisableTwoFactor(ctx *context.Context) {
 		return
 	}
 
+	hasWebAuthn, err := auth.HasWebAuthnRegistrationsByUID(ctx, ctx.Doer.ID)
+	if err != nil {
+		ctx.ServerError("HasWebAuthnRegistrationsByUID", err)
+		return
+	}
+	if !keepsRequiredTwoFactor(ctx, hasWebAuthn) {
+		if !ctx.Written() {
+			ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+		}
+		return
+	}
+
 	if err = auth.DeleteTwoFactorByID(ctx, t.ID, ctx.Doer.ID); err != nil {
 		if auth.IsErrTwoFactorNotEnrolled(err) {
 			// There is a potential DB race here - we must have been disabled by another request in the intervening period
@@ -77,6 +92,7 @@ func DisableTwoFactor(ctx *context.Context) {
 		}
 		return
 	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionUserTwoFactorDisable, audit_service.UserTarget(ctx.Doer), nil, nil)
 
 	if err := mailer.SendDisabledTOTP(ctx, ctx.Doer); err != nil {
 		ctx.ServerError("SendDisabledTOTP", err)
@@ -87,6 +103,24 @@ func DisableTwoFactor(ctx *context.Context) {
 	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
 }
 
+// keepsRequiredTwoFactor returns false and tells the user if removing one of their second factors would leave them
+// without two-factor authentication while they are required to enable it
+func keepsRequiredTwoFactor(ctx *context.Context, hasOtherFactor bool) bool {
+	if hasOtherFactor {
+		return true
+	}
+	required, err := auth_service.TwoFactorRequired(ctx, ctx.Doer)
+	if err != nil {
+		ctx.ServerError("TwoFactorRequired", err)
+		return false
+	}
+	if required {
+		ctx.Flash.Error(ctx.Tr("settings.twofa_required"))
+		return false
+	}
+	return true
+}
+
 func twofaGenerateSecretAndQr(ctx *context.Context) bool {
 	var otpKey *otp.Key
 	var err error
@@ -244,6 +278,7 @@ func EnrollTwoFactorPost(ctx *context.Context) {
 		ctx.ServerError("SettingsTwoFactor: Failed to save two factor", err)
 		return
 	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionUserTwoFactorEnable, audit_service.UserTarget(ctx.Doer), nil, nil)
 
 	ctx.Flash.Success(ctx.Tr("settings.twofa_enrolled", token))
 	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
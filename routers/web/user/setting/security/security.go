@@ -14,6 +14,7 @@ import (
 	"forgejo.org/modules/base"
 	"forgejo.org/modules/optional"
 	"forgejo.org/modules/setting"
+	auth_service "forgejo.org/services/auth"
 	"forgejo.org/services/auth/source/oauth2"
 	"forgejo.org/services/context"
 )
@@ -68,6 +69,7 @@ func loadSecurityData(ctx *context.Context) {
 		return
 	}
 	ctx.Data["WebAuthnCredentials"] = credentials
+	ctx.Data["PasskeyRequired"] = auth_service.PasskeyRequired(ctx.Doer)
 
 	tokens, err := db.Find[auth_model.AccessToken](ctx, auth_model.ListAccessTokensOptions{UserID: ctx.Doer.ID})
 	if err != nil {
@@ -145,4 +147,6 @@ func loadSecurityData(ctx *context.Context) {
 		return
 	}
 	ctx.Data["OpenIDs"] = openid
+
+	loadSessionsData(ctx)
 }
@@ -0,0 +1,79 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package security
+
+import (
+	audit_model "forgejo.org/models/audit"
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/setting"
+	audit_service "forgejo.org/services/audit"
+	auth_service "forgejo.org/services/auth"
+	"forgejo.org/services/context"
+)
+
+// loadSessionsData loads the sessions and the remembered devices of the user
+func loadSessionsData(ctx *context.Context) {
+	sessions, err := auth_model.FindUserSessions(ctx, ctx.Doer.ID, setting.SessionConfig.Maxlifetime)
+	if err != nil {
+		ctx.ServerError("FindUserSessions", err)
+		return
+	}
+	ctx.Data["Sessions"] = sessions
+	ctx.Data["CurrentSessionID"] = auth_service.SessionRecordID(ctx.Session)
+
+	rememberedDevices, err := auth_model.FindLongTermAuthTokens(ctx, ctx.Doer.ID)
+	if err != nil {
+		ctx.ServerError("FindLongTermAuthTokens", err)
+		return
+	}
+	ctx.Data["RememberedDevices"] = rememberedDevices
+}
+
+// RevokeSession signs out a session of the user
+func RevokeSession(ctx *context.Context) {
+	id := ctx.FormInt64("id")
+	if id == auth_service.SessionRecordID(ctx.Session) {
+		ctx.Flash.Error(ctx.Tr("settings.sessions.revoke_current"))
+		ctx.JSONRedirect(setting.AppSubURL + "/user/settings/security")
+		return
+	}
+
+	if err := auth_model.DeleteUserSession(ctx, ctx.Doer.ID, id); err != nil {
+		ctx.ServerError("DeleteUserSession", err)
+		return
+	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionUserSessionRevoke, audit_service.UserTarget(ctx.Doer), map[string]int64{"session": id}, nil)
+
+	log.Trace("Session %d of %s revoked", id, ctx.Doer.Name)
+	ctx.Flash.Success(ctx.Tr("settings.sessions.revoke_success"))
+	ctx.JSONRedirect(setting.AppSubURL + "/user/settings/security")
+}
+
+// RevokeOtherSessions signs out all sessions of the user except the current one
+func RevokeOtherSessions(ctx *context.Context) {
+	if err := auth_model.DeleteUserSessionsByUser(ctx, ctx.Doer.ID, auth_service.SessionRecordID(ctx.Session)); err != nil {
+		ctx.ServerError("DeleteUserSessionsByUser", err)
+		return
+	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionUserSessionRevoke, audit_service.UserTarget(ctx.Doer), map[string]string{"session": "others"}, nil)
+
+	log.Trace("Other sessions of %s revoked", ctx.Doer.Name)
+	ctx.Flash.Success(ctx.Tr("settings.sessions.revoke_others_success"))
+	ctx.JSONRedirect(setting.AppSubURL + "/user/settings/security")
+}
+
+// RevokeRememberedDevice forgets a device on which the user chose to stay signed in
+func RevokeRememberedDevice(ctx *context.Context) {
+	id := ctx.FormInt64("id")
+	if err := auth_model.DeleteLongTermAuthToken(ctx, ctx.Doer.ID, id); err != nil {
+		ctx.ServerError("DeleteLongTermAuthToken", err)
+		return
+	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionUserSessionRevoke, audit_service.UserTarget(ctx.Doer), map[string]int64{"remembered_device": id}, nil)
+
+	log.Trace("Remembered device %d of %s revoked", id, ctx.Doer.Name)
+	ctx.Flash.Success(ctx.Tr("settings.sessions.revoke_success"))
+	ctx.JSONRedirect(setting.AppSubURL + "/user/settings/security")
+}
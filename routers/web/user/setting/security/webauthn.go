@@ -9,11 +9,13 @@ import (
 	"strconv"
 	"time"
 
+	audit_model "forgejo.org/models/audit"
 	"forgejo.org/models/auth"
 	wa "forgejo.org/modules/auth/webauthn"
 	"forgejo.org/modules/log"
 	"forgejo.org/modules/setting"
 	"forgejo.org/modules/web"
+	audit_service "forgejo.org/services/audit"
 	"forgejo.org/services/context"
 	"forgejo.org/services/forms"
 	"forgejo.org/services/mailer"
@@ -45,8 +47,21 @@ func WebAuthnRegister(ctx *context.Context) {
 		ctx.ServerError("Unable to set session key for webauthnName", err)
 		return
 	}
+	if err := ctx.Session.Set("webauthnPasskey", form.Passkey); err != nil {
+		ctx.ServerError("Unable to set session key for webauthnPasskey", err)
+		return
+	}
 
-	credentialOptions, sessionData, err := wa.WebAuthn.BeginRegistration((*wa.User)(ctx.Doer))
+	var opts []webauthn.RegistrationOption
+	if form.Passkey {
+		// passkeys are resident on the authenticator and verify the user, they replace the password
+		opts = append(opts, webauthn.WithAuthenticatorSelection(protocol.AuthenticatorSelection{
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			RequireResidentKey: protocol.ResidentKeyRequired(),
+			UserVerification:   protocol.VerificationRequired,
+		}))
+	}
+	credentialOptions, sessionData, err := wa.WebAuthn.BeginRegistration((*wa.User)(ctx.Doer), opts...)
 	if err != nil {
 		ctx.ServerError("Unable to BeginRegistration", err)
 		return
@@ -99,17 +114,33 @@ func WebauthnRegisterPost(ctx *context.Context) {
 		return
 	}
 
-	// Create the credential
-	_, err = auth.CreateCredential(ctx, ctx.Doer.ID, name, cred)
+	// Create the credential, the user verification was required to register a passkey
+	if passkey, _ := ctx.Session.Get("webauthnPasskey").(bool); passkey && cred.Flags.UserVerified {
+		dbCred, err = auth.CreatePasskey(ctx, ctx.Doer.ID, name, cred)
+	} else {
+		dbCred, err = auth.CreateCredential(ctx, ctx.Doer.ID, name, cred)
+	}
 	if err != nil {
 		ctx.ServerError("CreateCredential", err)
 		return
 	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionUserWebAuthnAdd, webAuthnTarget(ctx, dbCred), nil, map[string]bool{"passkey": dbCred.Discoverable})
 	_ = ctx.Session.Delete("webauthnName")
+	_ = ctx.Session.Delete("webauthnPasskey")
 
 	ctx.JSON(http.StatusCreated, cred)
 }
 
+// webAuthnTarget returns the audit target of changes to a security key of the signed user
+func webAuthnTarget(ctx *context.Context, cred *auth.WebAuthnCredential) audit_service.Target {
+	return audit_service.Target{
+		Type:    audit_model.TargetTypeWebAuthnCredential,
+		ID:      cred.ID,
+		Name:    cred.Name,
+		OwnerID: ctx.Doer.ID,
+	}
+}
+
 // WebauthnDelete deletes an security key by id
 func WebauthnDelete(ctx *context.Context) {
 	form := web.GetForm(ctx).(*forms.WebauthnDeleteForm)
@@ -123,10 +154,31 @@ func WebauthnDelete(ctx *context.Context) {
 		return
 	}
 
+	hasOtherFactor, err := auth.HasTOTPByUID(ctx, ctx.Doer.ID)
+	if err != nil {
+		ctx.ServerError("HasTOTPByUID", err)
+		return
+	}
+	if !hasOtherFactor {
+		credentials, err := auth.GetWebAuthnCredentialsByUID(ctx, ctx.Doer.ID)
+		if err != nil {
+			ctx.ServerError("GetWebAuthnCredentialsByUID", err)
+			return
+		}
+		hasOtherFactor = len(credentials) > 1
+	}
+	if !keepsRequiredTwoFactor(ctx, hasOtherFactor) {
+		if !ctx.Written() {
+			ctx.JSONRedirect(setting.AppSubURL + "/user/settings/security")
+		}
+		return
+	}
+
 	if _, err := auth.DeleteCredential(ctx, form.ID, ctx.Doer.ID); err != nil {
 		ctx.ServerError("GetWebAuthnCredentialByID", err)
 		return
 	}
+	audit_service.Record(ctx, ctx.Doer, audit_model.ActionUserWebAuthnRemove, webAuthnTarget(ctx, cred), nil, nil)
 
 	if err := mailer.SendRemovedSecurityKey(ctx, ctx.Doer, cred.Name); err != nil {
 		ctx.ServerError("SendRemovedSecurityKey", err)
@@ -39,6 +39,7 @@ import (
 	"forgejo.org/routers/web/repo/actions"
 	"forgejo.org/routers/web/repo/badges"
 	repo_flags "forgejo.org/routers/web/repo/flags"
+	repo_security "forgejo.org/routers/web/repo/security"
 	repo_setting "forgejo.org/routers/web/repo/setting"
 	"forgejo.org/routers/web/shared/project"
 	"forgejo.org/routers/web/user"
@@ -169,6 +170,16 @@ func verifyAuthWithOptions(options *common.VerifyOptions) func(ctx *context.Cont
 				ctx.Redirect(setting.AppSubURL + "/")
 				return
 			}
+
+			auth.RequirePasskey(ctx)
+			if ctx.Written() {
+				return
+			}
+
+			auth.RequireTwoFactor(ctx)
+			if ctx.Written() {
+				return
+			}
 		}
 
 		// Redirect to dashboard (or alternate location) if user tries to visit any non-login page.
@@ -519,6 +530,10 @@ func registerRoutes(m *web.Route) {
 			m.Get("/assertion", auth.WebAuthnLoginAssertion)
 			m.Post("/assertion", auth.WebAuthnLoginAssertionPost)
 		})
+		m.Group("/passkey", func() {
+			m.Get("/assertion", auth.PasskeyLoginAssertion)
+			m.Post("/assertion", auth.PasskeyLoginAssertionPost)
+		})
 	}, reqSignOut)
 
 	m.Any("/user/events", routing.MarkLongPolling, events.Events)
@@ -530,7 +545,11 @@ func registerRoutes(m *web.Route) {
 			// TODO manage redirection
 			m.Post("/authorize", web.Bind(forms.AuthorizationForm{}), auth.AuthorizeOAuth)
 		}, ignSignInAndCsrf, reqSignIn)
+		m.Combo("/device", reqSignIn).
+			Get(auth.DeviceVerificationOAuth).
+			Post(web.Bind(forms.DeviceVerificationForm{}), auth.DeviceVerificationPostOAuth)
 
+		m.Methods("POST, OPTIONS", "/device_authorization", optionsCorsHandler(), web.Bind(forms.DeviceAuthorizationForm{}), ignSignInAndCsrf, auth.DeviceAuthorizationOAuth)
 		m.Methods("GET, POST, OPTIONS", "/userinfo", optionsCorsHandler(), ignSignInAndCsrf, auth.InfoOAuth)
 		m.Methods("POST, OPTIONS", "/access_token", optionsCorsHandler(), web.Bind(forms.AccessTokenForm{}), ignSignInAndCsrf, auth.AccessTokenOAuth)
 		m.Methods("GET, OPTIONS", "/keys", optionsCorsHandler(), ignSignInAndCsrf, auth.OIDCKeys)
@@ -576,6 +595,11 @@ func registerRoutes(m *web.Route) {
 				m.Post("/toggle_visibility", security.ToggleOpenIDVisibility)
 			}, openIDSignInEnabled)
 			m.Post("/account_link", linkAccountEnabled, security.DeleteAccountLink)
+			m.Group("/sessions", func() {
+				m.Post("/revoke", security.RevokeSession)
+				m.Post("/revoke_others", security.RevokeOtherSessions)
+				m.Post("/remembered/revoke", security.RevokeRememberedDevice)
+			})
 		})
 
 		m.Group("/applications", func() {
@@ -584,14 +608,23 @@ func registerRoutes(m *web.Route) {
 				m.Get("/{id}", user_setting.OAuth2ApplicationShow)
 				m.Post("/{id}", web.Bind(forms.EditOAuth2ApplicationForm{}), user_setting.OAuthApplicationsEdit)
 				m.Post("/{id}/regenerate_secret", user_setting.OAuthApplicationsRegenerateSecret)
+				m.Post("/{id}/installable", web.Bind(forms.EditOAuth2ApplicationInstallableForm{}), user_setting.OAuthApplicationsInstallable)
 				m.Post("", web.Bind(forms.EditOAuth2ApplicationForm{}), user_setting.OAuthApplicationsPost)
 				m.Post("/{id}/delete", user_setting.DeleteOAuth2Application)
 				m.Post("/{id}/revoke/{grantId}", user_setting.RevokeOAuth2Grant)
 			}, oauth2Enabled)
 
+			// installations of oauth2 applications
+			m.Group("", func() {
+				m.Combo("/install/{clientid}").Get(user_setting.InstallOAuth2Application).
+					Post(web.Bind(forms.InstallOAuth2ApplicationForm{}), user_setting.InstallOAuth2ApplicationPost)
+				m.Post("/installations/{id}/delete", user_setting.DeleteOAuth2ApplicationInstallation)
+			}, oauth2Enabled)
+
 			// access token applications
 			m.Combo("").Get(user_setting.Applications).
 				Post(web.Bind(forms.NewAccessTokenForm{}), user_setting.ApplicationsPost)
+			m.Post("/fine-grained", web.Bind(forms.NewFineGrainedAccessTokenForm{}), user_setting.ApplicationsFineGrainedPost)
 			m.Post("/delete", user_setting.DeleteApplication)
 			m.Post("/regenerate", user_setting.RegenerateApplication)
 		})
@@ -616,6 +649,7 @@ func registerRoutes(m *web.Route) {
 				m.Post("/initialize", user_setting.InitializeCargoIndex)
 				m.Post("/rebuild", user_setting.RebuildCargoIndex)
 			})
+			m.Post("/hex/regenerate_keypair", user_setting.RegenerateHexKeyPair)
 			m.Post("/chef/regenerate_keypair", user_setting.RegenerateChefKeyPair)
 		}, packagesEnabled)
 
@@ -666,6 +700,11 @@ func registerRoutes(m *web.Route) {
 			m.Get("/{provider}", auth.SignInOAuth)
 			m.Get("/{provider}/callback", auth.SignInOAuthCallback)
 		})
+		m.Group("/saml/{provider}", func() {
+			m.Get("", auth.SignInSAML)
+			m.Post("/acs", auth.SAMLAssertionConsumer)
+			m.Get("/metadata", auth.SAMLMetadata)
+		})
 	})
 	// ***** END: User *****
 
@@ -711,6 +750,7 @@ func registerRoutes(m *web.Route) {
 			m.Get("/{userid}", admin.ViewUser)
 			m.Combo("/{userid}/edit").Get(admin.EditUser).Post(web.Bind(forms.AdminEditUserForm{}), admin.EditUserPost)
 			m.Post("/{userid}/delete", admin.DeleteUser)
+			m.Post("/{userid}/sign_out", admin.SignOutUser)
 			m.Post("/{userid}/avatar", web.Bind(forms.AvatarForm{}), admin.AvatarPost)
 			m.Post("/{userid}/avatar/delete", admin.DeleteAvatar)
 		})
@@ -760,18 +800,32 @@ func registerRoutes(m *web.Route) {
 			m.Post("/{authid}/delete", admin.DeleteAuthSource)
 		})
 
+		m.Group("/federation", func() {
+			m.Get("", admin.Federation)
+			m.Post("/rules", web.Bind(forms.AdminFederationHostRuleForm{}), admin.FederationRulePost)
+			m.Post("/rules/import", web.Bind(forms.AdminFederationBlocklistForm{}), admin.ImportFederationBlocklist)
+			m.Post("/rules/{id}/delete", admin.DeleteFederationRule)
+			m.Post("/hosts/release", admin.ReleaseFederationHost)
+			m.Post("/hosts/block", admin.BlockFederationHost)
+			m.Post("/activities/{id}/approve", admin.ApproveQuarantinedActivity)
+			m.Post("/activities/{id}/reject", admin.RejectQuarantinedActivity)
+		}, federationEnabled)
+
 		m.Group("/notices", func() {
 			m.Get("", admin.Notices)
 			m.Post("/delete", admin.DeleteNotices)
 			m.Post("/empty", admin.EmptyNotices)
 		})
 
+		m.Get("/audit", admin.Audit)
+
 		m.Group("/applications", func() {
 			m.Get("", admin.Applications)
 			m.Post("/oauth2", web.Bind(forms.EditOAuth2ApplicationForm{}), admin.ApplicationsPost)
 			m.Group("/oauth2/{id}", func() {
 				m.Combo("").Get(admin.EditApplication).Post(web.Bind(forms.EditOAuth2ApplicationForm{}), admin.EditApplicationPost)
 				m.Post("/regenerate_secret", admin.ApplicationsRegenerateSecret)
+				m.Post("/installable", web.Bind(forms.EditOAuth2ApplicationInstallableForm{}), admin.ApplicationsInstallable)
 				m.Post("/delete", admin.DeleteApplication)
 			})
 		}, oauth2Enabled)
@@ -781,7 +835,7 @@ func registerRoutes(m *web.Route) {
 			addSettingsRunnersRoutes()
 			addSettingsVariablesRoutes()
 		})
-	}, adminReq, ctxDataSet("EnableOAuth2", setting.OAuth2.Enabled, "EnablePackages", setting.Packages.Enabled))
+	}, adminReq, ctxDataSet("EnableOAuth2", setting.OAuth2.Enabled, "EnablePackages", setting.Packages.Enabled, "EnableFederation", setting.Federation.Enabled, "EnableAudit", setting.Audit.Enabled))
 	// ***** END: Admin *****
 
 	m.Group("", func() {
@@ -895,8 +949,10 @@ func registerRoutes(m *web.Route) {
 					m.Group("/oauth2/{id}", func() {
 						m.Combo("").Get(org.OAuth2ApplicationShow).Post(web.Bind(forms.EditOAuth2ApplicationForm{}), org.OAuth2ApplicationEdit)
 						m.Post("/regenerate_secret", org.OAuthApplicationsRegenerateSecret)
+						m.Post("/installable", web.Bind(forms.EditOAuth2ApplicationInstallableForm{}), org.OAuthApplicationsInstallable)
 						m.Post("/delete", org.DeleteOAuth2Application)
 					})
+					m.Post("/installations/{id}/delete", org.DeleteOAuth2ApplicationInstallation)
 				}, oauth2Enabled)
 
 				m.Group("/hooks", func() {
@@ -934,6 +990,15 @@ func registerRoutes(m *web.Route) {
 					m.Post("/unblock", org_setting.BlockedUsersUnblock)
 				})
 				m.Get("/storage_overview", org_setting.StorageOverview)
+				m.Get("/audit", org_setting.Audit)
+
+				m.Post("/two_factor", org_setting.TwoFactorPost)
+				m.Group("/ip_allowlist", func() {
+					m.Get("", org_setting.IPAllowlist)
+					m.Post("/mode", org_setting.IPAllowlistModePost)
+					m.Post("/entries", web.Bind(forms.OrgIPAllowlistEntryForm{}), org_setting.IPAllowlistEntryPost)
+					m.Post("/entries/{id}/delete", org_setting.IPAllowlistEntryDelete)
+				})
 
 				m.Group("/packages", func() {
 					m.Get("", org.Packages)
@@ -952,8 +1017,9 @@ func registerRoutes(m *web.Route) {
 						m.Post("/initialize", org.InitializeCargoIndex)
 						m.Post("/rebuild", org.RebuildCargoIndex)
 					})
+					m.Post("/hex/regenerate_keypair", org.RegenerateHexKeyPair)
 				}, packagesEnabled)
-			}, ctxDataSet("EnableOAuth2", setting.OAuth2.Enabled, "EnablePackages", setting.Packages.Enabled, "EnableQuota", setting.Quota.Enabled, "PageIsOrgSettings", true))
+			}, ctxDataSet("EnableOAuth2", setting.OAuth2.Enabled, "EnablePackages", setting.Packages.Enabled, "EnableQuota", setting.Quota.Enabled, "EnableAudit", setting.Audit.Enabled, "PageIsOrgSettings", true))
 		}, context.OrgAssignment(true, true))
 	}, reqSignIn)
 	// ***** END: Organization *****
@@ -1645,6 +1711,14 @@ func registerRoutes(m *web.Route) {
 		})
 	})
 
+	if setting.SecretScanning.Enabled {
+		m.Group("/{username}/{reponame}/security", func() {
+			m.Get("", repo_security.SecretScanning)
+			m.Post("/scan", repo_security.SecretScanningScanPost)
+			m.Post("/alerts/{id}", repo_security.SecretScanningAlertPost)
+		}, reqSignIn, context.RepoAssignment, context.UnitTypes(), reqRepoAdmin, context.RepoRef())
+	}
+
 	if setting.Repository.EnableFlags {
 		m.Group("/{username}/{reponame}/flags", func() {
 			m.Get("", repo_flags.Manage)
@@ -0,0 +1,170 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package apps
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	repo_model "forgejo.org/models/repo"
+	user_model "forgejo.org/models/user"
+	webhook_model "forgejo.org/models/webhook"
+	"forgejo.org/modules/optional"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/util"
+	webhook_module "forgejo.org/modules/webhook"
+)
+
+// Settings are the settings which make an OAuth2 application installable
+type Settings struct {
+	Permissions   auth_model.AccessTokenRepoPermissions
+	PublicKey     string
+	WebhookURL    string
+	WebhookSecret string
+}
+
+// ParsePublicKey parses the PEM encoded public key applications sign their JWTs with
+func ParsePublicKey(content string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(content))
+	if block == nil {
+		return nil, util.NewInvalidArgumentErrorf("the public key is not PEM encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, util.NewInvalidArgumentErrorf("invalid public key: %v", err)
+	}
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	}
+	return nil, util.NewInvalidArgumentErrorf("unsupported public key type %T", key)
+}
+
+// GetWebhook returns the webhook receiving the events of the installations of the application, nil if there is none
+func GetWebhook(ctx context.Context, app *auth_model.OAuth2Application) (*webhook_model.Webhook, error) {
+	if app.BotUserID <= 0 {
+		return nil, nil
+	}
+	hooks, err := db.Find[webhook_model.Webhook](ctx, webhook_model.ListWebhookOptions{OwnerID: app.BotUserID})
+	if err != nil || len(hooks) == 0 {
+		return nil, err
+	}
+	return hooks[0], nil
+}
+
+// UpdateSettings updates the settings of the application installations use,
+// the bot acting for the application is created the first time the application requests permissions
+func UpdateSettings(ctx context.Context, app *auth_model.OAuth2Application, settings Settings) error {
+	if settings.PublicKey = strings.TrimSpace(settings.PublicKey); settings.PublicKey != "" {
+		if _, err := ParsePublicKey(settings.PublicKey); err != nil {
+			return err
+		}
+	}
+
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if app.BotUserID <= 0 && settings.Permissions != "" {
+			bot, err := createBotUser(ctx, app)
+			if err != nil {
+				return err
+			}
+			app.BotUserID = bot.ID
+		}
+		app.AppPermissions = settings.Permissions
+		app.AppPublicKey = settings.PublicKey
+		if _, err := db.GetEngine(ctx).ID(app.ID).Cols("bot_user_id", "app_permissions", "app_public_key").Update(app); err != nil {
+			return err
+		}
+
+		hook, err := GetWebhook(ctx, app)
+		if err != nil {
+			return err
+		}
+		switch {
+		case settings.WebhookURL == "" && hook != nil:
+			return webhook_model.DeleteWebhookByOwnerID(ctx, app.BotUserID, hook.ID)
+		case settings.WebhookURL == "" || app.BotUserID <= 0:
+			return nil
+		case hook == nil:
+			// the hook of the bot is never triggered by repositories of the bot,
+			// the events of the installations are delivered to it
+			hook = &webhook_model.Webhook{
+				OwnerID:     app.BotUserID,
+				HTTPMethod:  "POST",
+				ContentType: webhook_model.ContentTypeJSON,
+				HookEvent:   &webhook_module.HookEvent{SendEverything: true},
+				IsActive:    true,
+				Type:        webhook_module.FORGEJO,
+			}
+		}
+		hook.URL = settings.WebhookURL
+		hook.Secret = settings.WebhookSecret
+		if err := hook.UpdateEvent(); err != nil {
+			return err
+		}
+		if hook.ID == 0 {
+			return webhook_model.CreateWebhook(ctx, hook)
+		}
+		return webhook_model.UpdateWebhook(ctx, hook)
+	})
+}
+
+// createBotUser creates the bot acting for the application, it is named after the application
+func createBotUser(ctx context.Context, app *auth_model.OAuth2Application) (*user_model.User, error) {
+	name, err := user_model.NormalizeUserName(app.Name)
+	name = strings.ToLower(strings.Trim(name, "-._")) + "-bot"
+	if err != nil || user_model.IsUsableUsername(name) != nil {
+		name = "app-bot"
+	}
+	if exist, err := user_model.IsUserExist(ctx, 0, name); err != nil {
+		return nil, err
+	} else if exist {
+		name = fmt.Sprintf("%s-%d", name, app.ID)
+	}
+
+	bot := &user_model.User{
+		Name:        name,
+		FullName:    app.Name,
+		Email:       fmt.Sprintf("%s@%s", name, setting.Service.NoReplyAddress),
+		Type:        user_model.UserTypeBot,
+		Description: fmt.Sprintf("Acts for the application %s", app.Name),
+	}
+	if err := user_model.AdminCreateUser(ctx, bot, &user_model.CreateUserOverwriteOptions{
+		IsActive:     optional.Some(true),
+		IsRestricted: optional.Some(false),
+	}); err != nil {
+		return nil, err
+	}
+	return bot, nil
+}
+
+// Install installs the application on the owner with the permissions it currently requests,
+// repos limits the installation to some repositories of the owner
+func Install(ctx context.Context, app *auth_model.OAuth2Application, doer, owner *user_model.User, repos []*repo_model.Repository) (*auth_model.OAuth2ApplicationInstallation, error) {
+	if !app.IsInstallable() {
+		return nil, util.NewInvalidArgumentErrorf("the application %s can not be installed", app.Name)
+	}
+	inst := &auth_model.OAuth2ApplicationInstallation{
+		AppID:       app.ID,
+		OwnerID:     owner.ID,
+		Permissions: app.AppPermissions,
+		InstallerID: doer.ID,
+		App:         app,
+	}
+	for _, repo := range repos {
+		if repo.OwnerID != owner.ID {
+			return nil, util.NewInvalidArgumentErrorf("the repository %s does not belong to %s", repo.FullName(), owner.Name)
+		}
+		inst.RepoIDs = append(inst.RepoIDs, repo.ID)
+	}
+	return inst, auth_model.CreateOAuth2ApplicationInstallation(ctx, inst)
+}
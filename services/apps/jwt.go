@@ -0,0 +1,81 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package apps
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"time"
+
+	auth_model "forgejo.org/models/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// MaxJWTLifetime is the longest lifetime accepted for the JWTs applications sign
+const MaxJWTLifetime = 10 * time.Minute
+
+// jwtLeeway tolerates the clocks of applications being slightly off
+const jwtLeeway = time.Minute
+
+// VerifyJWT returns the application which signed the JWT, its issuer must be the client id of the application
+func VerifyJWT(ctx context.Context, token string) (*auth_model.OAuth2Application, error) {
+	var app *auth_model.OAuth2Application
+	claims := &jwt.RegisteredClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		issuer, err := t.Claims.GetIssuer()
+		if err != nil || issuer == "" {
+			return nil, errors.New("missing issuer")
+		}
+		app, err = auth_model.GetOAuth2ApplicationByClientID(ctx, issuer)
+		if err != nil {
+			return nil, err
+		}
+		if app.AppPublicKey == "" {
+			return nil, fmt.Errorf("application %s has no public key", app.ClientID)
+		}
+		key, err := ParsePublicKey(app.AppPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		switch key.(type) {
+		case *rsa.PublicKey:
+			_, ok := t.Method.(*jwt.SigningMethodRSA)
+			if !ok {
+				_, ok = t.Method.(*jwt.SigningMethodRSAPSS)
+			}
+			if !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+			}
+		case *ecdsa.PublicKey:
+			if _, ok := t.Method.(*jwt.SigningMethodECDSA); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+			}
+		case ed25519.PublicKey:
+			if _, ok := t.Method.(*jwt.SigningMethodEd25519); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+			}
+		}
+		return key, nil
+	}, jwt.WithExpirationRequired(), jwt.WithIssuedAt(), jwt.WithLeeway(jwtLeeway))
+	if err != nil {
+		return nil, err
+	}
+	if !parsed.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	// the JWTs can not be revoked, they must be short-lived
+	if claims.IssuedAt == nil || claims.ExpiresAt.Sub(claims.IssuedAt.Time) > MaxJWTLifetime {
+		return nil, fmt.Errorf("the token must be issued and expire within %s", MaxJWTLifetime)
+	}
+	if !app.IsInstallable() {
+		return nil, fmt.Errorf("application %s can not be installed", app.ClientID)
+	}
+	return app, nil
+}
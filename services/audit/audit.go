@@ -0,0 +1,211 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package audit
+
+import (
+	"context"
+	"net"
+	"time"
+
+	audit_model "forgejo.org/models/audit"
+	auth_model "forgejo.org/models/auth"
+	git_model "forgejo.org/models/git"
+	org_model "forgejo.org/models/organization"
+	repo_model "forgejo.org/models/repo"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/json"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/setting"
+)
+
+type remoteAddressKeyType struct{}
+
+var remoteAddressKey remoteAddressKeyType
+
+// WithRemoteAddress returns a context the events recorded with remember the address of the client of
+func WithRemoteAddress(ctx context.Context, addr string) context.Context {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return context.WithValue(ctx, remoteAddressKey, addr)
+}
+
+// Target is the object an audit event changed
+type Target struct {
+	Type    audit_model.TargetType
+	ID      int64
+	Name    string
+	OwnerID int64
+	RepoID  int64
+}
+
+// UserTarget returns the target of changes made to the user or organization itself
+func UserTarget(u *user_model.User) Target {
+	return Target{
+		Type:    audit_model.TargetTypeUser,
+		ID:      u.ID,
+		Name:    u.Name,
+		OwnerID: u.ID,
+	}
+}
+
+// TeamTarget returns the target of changes made to the team of an organization
+func TeamTarget(t *org_model.Team) Target {
+	return Target{
+		Type:    audit_model.TargetTypeTeam,
+		ID:      t.ID,
+		Name:    t.Name,
+		OwnerID: t.OrgID,
+	}
+}
+
+// RepoTarget returns the target of changes made to the repository
+func RepoTarget(repo *repo_model.Repository) Target {
+	return Target{
+		Type:    audit_model.TargetTypeRepository,
+		ID:      repo.ID,
+		Name:    repo.FullName(),
+		OwnerID: repo.OwnerID,
+		RepoID:  repo.ID,
+	}
+}
+
+// UserAccountValue returns the attributes of the account of the user which matter for its security
+func UserAccountValue(u *user_model.User) map[string]any {
+	return map[string]any{
+		"name":                 u.Name,
+		"email":                u.Email,
+		"login_source":         u.LoginSource,
+		"is_active":            u.IsActive,
+		"is_admin":             u.IsAdmin,
+		"is_restricted":        u.IsRestricted,
+		"prohibit_login":       u.ProhibitLogin,
+		"must_change_password": u.MustChangePassword,
+		"visibility":           u.Visibility.String(),
+	}
+}
+
+// RecordRepoVisibility records that the doer made the repository private or public
+func RecordRepoVisibility(ctx context.Context, doer *user_model.User, repo *repo_model.Repository) {
+	Record(ctx, doer, audit_model.ActionRepoVisibility, RepoTarget(repo),
+		map[string]bool{"private": !repo.IsPrivate}, map[string]bool{"private": repo.IsPrivate})
+}
+
+// AccessTokenTarget returns the target of changes made to the access token
+func AccessTokenTarget(t *auth_model.AccessToken) Target {
+	return Target{
+		Type:    audit_model.TargetTypeAccessToken,
+		ID:      t.ID,
+		Name:    t.Name,
+		OwnerID: t.UID,
+	}
+}
+
+// RecordProtectedBranch records a change of a branch protection rule of the repository,
+// before is nil if the rule was created and after is nil if it was deleted
+func RecordProtectedBranch(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, action audit_model.Action, before, after *git_model.ProtectedBranch) {
+	rule := after
+	if rule == nil {
+		rule = before
+	}
+	target := Target{
+		Type:    audit_model.TargetTypeProtectedBranch,
+		ID:      rule.ID,
+		Name:    rule.RuleName,
+		OwnerID: repo.OwnerID,
+		RepoID:  repo.ID,
+	}
+	Record(ctx, doer, action, target, protectedBranchValue(before), protectedBranchValue(after))
+}
+
+func protectedBranchValue(rule *git_model.ProtectedBranch) any {
+	if rule == nil {
+		return nil
+	}
+	v := *rule
+	v.Repo = nil
+	return &v
+}
+
+// Record records a change the doer made to the target if audit events are enabled.
+// Before and after are the changed values, they are encoded as JSON unless they are nil.
+// Failing to record the event is only logged, it does not prevent the change.
+func Record(ctx context.Context, doer *user_model.User, action audit_model.Action, target Target, before, after any) {
+	if !setting.Audit.Enabled {
+		return
+	}
+
+	e := &audit_model.Event{
+		Action:     action,
+		OwnerID:    target.OwnerID,
+		RepoID:     target.RepoID,
+		TargetType: target.Type,
+		TargetID:   target.ID,
+		TargetName: target.Name,
+		Before:     encodeValue(before),
+		After:      encodeValue(after),
+	}
+	if doer != nil {
+		e.ActorID = doer.ID
+		e.ActorName = doer.Name
+	}
+	insert(ctx, e)
+}
+
+// Methods users sign in with, they are recorded with the sign in events
+const (
+	SignInMethodPassword   = "password"
+	SignInMethodPasskey    = "passkey"
+	SignInMethodOAuth2     = "oauth2"
+	SignInMethodSAML       = "saml"
+	SignInMethodOpenID     = "openid"
+	SignInMethodRememberMe = "remember_me"
+)
+
+// RecordSignIn records that the user signed in with the method
+func RecordSignIn(ctx context.Context, u *user_model.User, method string) {
+	Record(ctx, u, audit_model.ActionUserSignIn, UserTarget(u), nil, map[string]string{"method": method})
+}
+
+// RecordSignInFailed records a failed sign in with the method, nobody is signed in yet so the actor is the name which was tried.
+// The name is empty if it is unknown, e.g. if the identity provider did not authenticate the user.
+func RecordSignInFailed(ctx context.Context, name, method string) {
+	if !setting.Audit.Enabled {
+		return
+	}
+
+	insert(ctx, &audit_model.Event{
+		Action:     audit_model.ActionUserSignInFailed,
+		ActorName:  name,
+		TargetType: audit_model.TargetTypeUser,
+		TargetName: name,
+		After:      encodeValue(map[string]string{"method": method}),
+	})
+}
+
+func insert(ctx context.Context, e *audit_model.Event) {
+	e.IPAddress, _ = ctx.Value(remoteAddressKey).(string)
+	if err := audit_model.InsertEvent(ctx, e); err != nil {
+		log.Error("Unable to record audit event %s of %q: %v", e.Action, e.ActorName, err)
+		return
+	}
+	stream(e)
+}
+
+func encodeValue(v any) string {
+	if v == nil {
+		return ""
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		log.Error("Unable to encode the value of an audit event: %v", err)
+		return ""
+	}
+	return string(b)
+}
+
+// Cleanup deletes the audit events which are older than the retention period
+func Cleanup(ctx context.Context) error {
+	return audit_model.DeleteEventsOlderThan(ctx, time.Duration(setting.Audit.RetentionDays)*24*time.Hour)
+}
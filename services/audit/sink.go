@@ -0,0 +1,229 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	audit_model "forgejo.org/models/audit"
+	"forgejo.org/modules/graceful"
+	"forgejo.org/modules/json"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/proxy"
+	"forgejo.org/modules/queue"
+	"forgejo.org/modules/setting"
+	api "forgejo.org/modules/structs"
+	"forgejo.org/modules/util/rotatingfilewriter"
+	"forgejo.org/services/convert"
+)
+
+// sink streams the audit events to a destination outside of the database
+type sink interface {
+	Send(ctx context.Context, e *api.AuditEvent) error
+}
+
+// sinkItem is an audit event which has to be sent to a sink
+type sinkItem struct {
+	Sink  string
+	Event *api.AuditEvent
+}
+
+var (
+	sinks     map[string]sink
+	sinkQueue *queue.WorkerPoolQueue[*sinkItem]
+)
+
+// Init starts the queue which streams the audit events to the configured sinks.
+// Events are queued so that a sink which is down does not slow down the requests recording them.
+func Init() error {
+	if !setting.Audit.Enabled || len(setting.Audit.Sinks) == 0 {
+		return nil
+	}
+
+	sinks = make(map[string]sink, len(setting.Audit.Sinks))
+	for _, cfg := range setting.Audit.Sinks {
+		s, err := newSink(cfg)
+		if err != nil {
+			return fmt.Errorf("audit sink %q: %w", cfg.Name, err)
+		}
+		sinks[cfg.Name] = s
+	}
+
+	sinkQueue = queue.CreateSimpleQueue(graceful.GetManager().ShutdownContext(), "audit_sink", sinkHandler)
+	if sinkQueue == nil {
+		return errors.New("unable to create audit_sink queue")
+	}
+	go graceful.GetManager().RunWithCancel(sinkQueue)
+	return nil
+}
+
+func newSink(cfg setting.AuditSink) (sink, error) {
+	switch cfg.Mode {
+	case "file":
+		w, err := rotatingfilewriter.Open(cfg.File.FileName, &rotatingfilewriter.Options{
+			Rotate:           cfg.File.LogRotate,
+			MaximumSize:      cfg.File.MaxSize,
+			RotateDaily:      cfg.File.DailyRotate,
+			KeepDays:         cfg.File.MaxDays,
+			Compress:         cfg.File.Compress,
+			CompressionLevel: cfg.File.CompressionLevel,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &writerSink{w: w, format: formatJSONLine}, nil
+	case "syslog":
+		hostname, err := os.Hostname()
+		if err != nil || hostname == "" {
+			hostname = "-"
+		}
+		return &writerSink{
+			w: log.NewConnWriter(cfg.Conn),
+			format: func(e *api.AuditEvent) ([]byte, error) {
+				return formatSyslog(e, cfg.Facility, hostname, cfg.Conn.Protocol == "tcp")
+			},
+			reopen: func() io.WriteCloser {
+				return log.NewConnWriter(cfg.Conn)
+			},
+		}, nil
+	case "http":
+		return &httpSink{
+			url:    cfg.URL,
+			secret: []byte(cfg.Secret),
+			client: &http.Client{
+				Timeout:   time.Duration(setting.Webhook.DeliverTimeout) * time.Second,
+				Transport: &http.Transport{Proxy: proxy.Proxy()},
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown mode %q", cfg.Mode)
+}
+
+func sinkHandler(items ...*sinkItem) (unhandled []*sinkItem) {
+	ctx := graceful.GetManager().ShutdownContext()
+	for _, item := range items {
+		s, ok := sinks[item.Sink]
+		if !ok {
+			// the sink has been removed from the configuration since the event was queued
+			continue
+		}
+		if err := s.Send(ctx, item.Event); err != nil {
+			log.Warn("Unable to send audit event %d to sink %q, it will be retried: %v", item.Event.ID, item.Sink, err)
+			unhandled = append(unhandled, item)
+		}
+	}
+	return unhandled
+}
+
+// stream queues the event for every sink
+func stream(e *audit_model.Event) {
+	if sinkQueue == nil {
+		return
+	}
+	apiEvent := convert.ToAuditEvent(e)
+	for name := range sinks {
+		if err := sinkQueue.Push(&sinkItem{Sink: name, Event: apiEvent}); err != nil {
+			log.Error("Unable to queue audit event %d for sink %q: %v", e.ID, name, err)
+		}
+	}
+}
+
+// writerSink writes every event as one message with an output writer of the logger.
+// If reopen is set the writer is replaced after a write failed, e.g. because the connection to syslog is broken,
+// so that the event is sent with a new connection when it is retried.
+type writerSink struct {
+	mu     sync.Mutex
+	w      io.WriteCloser
+	format func(e *api.AuditEvent) ([]byte, error)
+	reopen func() io.WriteCloser
+}
+
+func (s *writerSink) Send(_ context.Context, e *api.AuditEvent) error {
+	msg, err := s.format(e)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err = s.w.Write(msg); err != nil && s.reopen != nil {
+		_ = s.w.Close()
+		s.w = s.reopen()
+	}
+	return err
+}
+
+func formatJSONLine(e *api.AuditEvent) ([]byte, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// syslogSeverityNotice is the severity of the audit messages, they are normal but significant conditions
+const syslogSeverityNotice = 5
+
+// formatSyslog formats the event as a RFC 5424 message with the JSON encoded event as its content.
+// Messages sent over TCP are framed by octet counting as described in RFC 6587.
+func formatSyslog(e *api.AuditEvent, facility int, hostname string, octetCounting bool) ([]byte, error) {
+	content, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	msg := fmt.Sprintf("<%d>1 %s %s forgejo %d %s - %s",
+		facility*8+syslogSeverityNotice,
+		e.Created.UTC().Format(time.RFC3339),
+		hostname,
+		os.Getpid(),
+		e.Action,
+		content,
+	)
+	if octetCounting {
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+	return []byte(msg), nil
+}
+
+// httpSink posts every event to an endpoint, the body is signed like the body of webhooks
+type httpSink struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+func (s *httpSink) Send(ctx context.Context, e *api.AuditEvent) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	sig := hmac.New(sha256.New, s.secret)
+	_, _ = sig.Write(body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forgejo-Signature", hex.EncodeToString(sig.Sum(nil)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package audit
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"forgejo.org/modules/json"
+	"forgejo.org/modules/setting"
+	api "forgejo.org/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEvent = &api.AuditEvent{
+	ID:         1,
+	Action:     "user_sign_in",
+	ActorID:    2,
+	ActorName:  "user2",
+	IPAddress:  "192.0.2.1",
+	TargetType: "user",
+	TargetID:   2,
+	TargetName: "user2",
+	Created:    time.Date(2025, time.March, 1, 12, 30, 0, 0, time.UTC),
+}
+
+func TestFormatSyslog(t *testing.T) {
+	content, err := json.Marshal(testEvent)
+	require.NoError(t, err)
+	expected := fmt.Sprintf("<109>1 2025-03-01T12:30:00Z host forgejo %d user_sign_in - %s", os.Getpid(), content)
+
+	msg, err := formatSyslog(testEvent, 13, "host", false)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(msg))
+
+	// over tcp the message is prefixed with its length
+	msg, err = formatSyslog(testEvent, 13, "host", true)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%d %s", len(expected), expected), string(msg))
+}
+
+func TestFileSink(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "audit.log")
+	cfg := setting.AuditSink{Name: "archive", Mode: "file"}
+	cfg.File.FileName = fileName
+	s, err := newSink(cfg)
+	require.NoError(t, err)
+	require.NoError(t, s.Send(t.Context(), testEvent))
+	require.NoError(t, s.Send(t.Context(), testEvent))
+	require.NoError(t, s.(*writerSink).w.Close())
+
+	content, err := json.Marshal(testEvent)
+	require.NoError(t, err)
+	written, err := os.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, string(content)+"\n"+string(content)+"\n", string(written))
+}
+
+// brokenConn fails every write like a connection closed by the peer
+type brokenConn struct {
+	closed bool
+}
+
+func (c *brokenConn) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func (c *brokenConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+type bufferConn struct {
+	bytes.Buffer
+}
+
+func (c *bufferConn) Close() error {
+	return nil
+}
+
+func TestWriterSinkReopen(t *testing.T) {
+	broken := &brokenConn{}
+	reopened := &bufferConn{}
+	s := &writerSink{
+		w:      broken,
+		format: formatJSONLine,
+		reopen: func() io.WriteCloser {
+			return reopened
+		},
+	}
+
+	// the failed event is retried with a new connection
+	require.Error(t, s.Send(t.Context(), testEvent))
+	assert.True(t, broken.closed)
+	require.NoError(t, s.Send(t.Context(), testEvent))
+
+	content, err := json.Marshal(testEvent)
+	require.NoError(t, err)
+	assert.Equal(t, string(content)+"\n", reopened.String())
+}
+
+func TestHTTPSink(t *testing.T) {
+	status := http.StatusNoContent
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		sig := hmac.New(sha256.New, []byte("s3cr3t"))
+		_, _ = sig.Write(body)
+		assert.Equal(t, hex.EncodeToString(sig.Sum(nil)), r.Header.Get("X-Forgejo-Signature"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		received = body
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	s, err := newSink(setting.AuditSink{Name: "endpoint", Mode: "http", URL: server.URL, Secret: "s3cr3t"})
+	require.NoError(t, err)
+	require.NoError(t, s.Send(t.Context(), testEvent))
+
+	var event api.AuditEvent
+	require.NoError(t, json.Unmarshal(received, &event))
+	assert.Equal(t, *testEvent, event)
+
+	// the event is retried if the endpoint fails
+	status = http.StatusServiceUnavailable
+	assert.Error(t, s.Send(t.Context(), testEvent))
+}
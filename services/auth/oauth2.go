@@ -19,6 +19,7 @@ import (
 	"forgejo.org/modules/timeutil"
 	"forgejo.org/modules/web/middleware"
 	"forgejo.org/services/actions"
+	"forgejo.org/services/apps"
 	"forgejo.org/services/auth/source/oauth2"
 )
 
@@ -156,8 +157,21 @@ func (o *OAuth2) userIDFromToken(ctx context.Context, tokenSHA string, store Dat
 			} else {
 				store.GetData()["ApiTokenScope"] = auth_model.AccessTokenScopeAll // fallback to all
 			}
+			return uid
 		}
-		return uid
+
+		// Finally, check if this is a JWT signed by an application, it acts as the bot of the application
+		// without any scope and can only be used for the endpoints of the application
+		if setting.OAuth2.Enabled {
+			if app, err := apps.VerifyJWT(ctx, tokenSHA); err == nil {
+				store.GetData()["IsApiToken"] = true
+				store.GetData()["ApiTokenScope"] = auth_model.AccessTokenScope("")
+				store.GetData()["IsApplicationToken"] = true
+				store.GetData()["Application"] = app
+				return app.BotUserID
+			}
+		}
+		return 0
 	}
 	t, err := auth_model.GetAccessTokenBySHA(ctx, tokenSHA)
 	if err != nil {
@@ -183,6 +197,7 @@ func (o *OAuth2) userIDFromToken(ctx context.Context, tokenSHA string, store Dat
 	}
 	store.GetData()["IsApiToken"] = true
 	store.GetData()["ApiTokenScope"] = t.Scope
+	store.GetData()["ApiToken"] = t
 	return t.UID
 }
 
@@ -0,0 +1,86 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"forgejo.org/models/auth"
+	user_model "forgejo.org/models/user"
+	wa "forgejo.org/modules/auth/webauthn"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/util"
+	"forgejo.org/services/auth/source/oauth2"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// BeginPasskeySignIn starts a passwordless sign in, any passkey which verifies the user can answer it
+func BeginPasskeySignIn() (*protocol.CredentialAssertion, *webauthn.SessionData, error) {
+	return wa.WebAuthn.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+}
+
+// PasskeySignIn validates the assertion of a passkey and returns the user it belongs to.
+// It returns an invalid argument error if the assertion does not authenticate anyone.
+func PasskeySignIn(ctx context.Context, sessionData *webauthn.SessionData, response *protocol.ParsedCredentialAssertionData) (*user_model.User, error) {
+	var (
+		user   *user_model.User
+		dbCred *auth.WebAuthnCredential
+	)
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		uid, err := wa.UserIDFromWebAuthnID(userHandle)
+		if err != nil {
+			return nil, err
+		}
+		dbCred, err = auth.GetWebAuthnCredentialByCredID(ctx, uid, rawID)
+		if err != nil {
+			return nil, err
+		}
+		// security keys registered as a second factor only complete a password
+		if !dbCred.Discoverable {
+			return nil, errors.New("the credential is not a passkey")
+		}
+		user, err = user_model.GetUserByID(ctx, uid)
+		if err != nil {
+			return nil, err
+		}
+		return (*wa.User)(user), nil
+	}
+
+	cred, err := wa.WebAuthn.ValidateDiscoverableLogin(handler, *sessionData, response)
+	if err != nil {
+		return nil, util.NewInvalidArgumentErrorf("invalid passkey assertion: %v", err)
+	}
+
+	// Ensure that the credential wasn't cloned by checking if CloneWarning is set.
+	if cred.Authenticator.CloneWarning {
+		return nil, util.NewInvalidArgumentErrorf("cloned credential of %s", user.Name)
+	}
+	dbCred.SignCount = cred.Authenticator.SignCount
+	if err := dbCred.UpdateSignCount(ctx); err != nil {
+		return nil, err
+	}
+
+	if user.ProhibitLogin {
+		return nil, user_model.ErrUserProhibitLogin{UID: user.ID, Name: user.Name}
+	}
+	// the passkey replaces the password of the user, not the source they sign in with
+	if user.LoginSource > 0 {
+		source, err := auth.GetSourceByID(ctx, user.LoginSource)
+		if err != nil {
+			return nil, err
+		}
+		if !source.IsActive {
+			return nil, oauth2.ErrAuthSourceNotActivated
+		}
+	}
+	return user, nil
+}
+
+// PasskeyRequired returns whether the user must sign in with a passkey
+func PasskeyRequired(u *user_model.User) bool {
+	return setting.RequirePasskeyForAdmins && u.IsAdmin
+}
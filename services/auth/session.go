@@ -4,10 +4,16 @@
 package auth
 
 import (
+	"context"
+	"net"
 	"net/http"
 
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
 	user_model "forgejo.org/models/user"
 	"forgejo.org/modules/log"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/timeutil"
 )
 
 // Ensure the struct implements the interface.
@@ -55,6 +61,91 @@ func (s *Session) Verify(req *http.Request, w http.ResponseWriter, store DataSto
 		return nil, nil
 	}
 
+	revoked, err := checkSessionRecord(req, sess, user.ID)
+	if err != nil {
+		log.Error("checkSessionRecord: %v", err)
+		return nil, err
+	}
+	if revoked {
+		log.Trace("Session Authorization: Session of user %-v was revoked", user)
+		return nil, nil
+	}
+
 	log.Trace("Session Authorization: Logged in user %-v", user)
 	return user, nil
 }
+
+// sessionRecordKey is the key of the ID of the record of the session in the session data
+const sessionRecordKey = "sessionRecordID"
+
+// sessionAccessInterval is how often the last access to a session is recorded, in seconds
+const sessionAccessInterval = 5 * 60
+
+// checkSessionRecord returns true if the session of the user was revoked.
+// Otherwise it records the client which uses the session, the record is created if the session has none yet.
+func checkSessionRecord(req *http.Request, sess SessionStore, uid int64) (bool, error) {
+	id, ok := sess.Get(sessionRecordKey).(int64)
+	if !ok {
+		return false, RecordSession(req, sess, uid)
+	}
+
+	record, err := auth_model.GetUserSessionByID(req.Context(), id)
+	if err != nil {
+		if auth_model.IsErrUserSessionNotExist(err) {
+			return true, sess.Delete(sessionRecordKey)
+		}
+		return false, err
+	}
+	// the session was used by another user before
+	if record.UID != uid {
+		return false, RecordSession(req, sess, uid)
+	}
+
+	ip := remoteIP(req)
+	if record.IP != ip || record.UserAgent != req.UserAgent() || timeutil.TimeStampNow()-record.LastAccessUnix > sessionAccessInterval {
+		record.IP = ip
+		record.UserAgent = req.UserAgent()
+		return false, auth_model.UpdateUserSessionAccess(req.Context(), record)
+	}
+	return false, nil
+}
+
+// RecordSession creates a new record of the session of the user, for example after all the other records
+// of the user were deleted because the password was changed
+func RecordSession(req *http.Request, sess SessionStore, uid int64) error {
+	record := &auth_model.UserSession{
+		UID:       uid,
+		IP:        remoteIP(req),
+		UserAgent: req.UserAgent(),
+	}
+	if err := auth_model.CreateUserSession(req.Context(), record, setting.SessionConfig.Maxlifetime); err != nil {
+		return err
+	}
+	return sess.Set(sessionRecordKey, record.ID)
+}
+
+// SessionRecordID returns the ID of the record of the session, 0 if it has none
+func SessionRecordID(sess SessionStore) int64 {
+	id, _ := sess.Get(sessionRecordKey).(int64)
+	return id
+}
+
+// remoteIP returns the address of the client without the port
+func remoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// SignOutEverywhere revokes all sessions and long term authorization tokens of the user, the next request
+// of each of its signed in clients is unauthenticated
+func SignOutEverywhere(ctx context.Context, uid int64) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if err := auth_model.DeleteUserSessionsByUser(ctx, uid, 0); err != nil {
+			return err
+		}
+		return auth_model.DeleteAuthTokenByUser(ctx, uid)
+	})
+}
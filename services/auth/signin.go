@@ -5,6 +5,7 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"forgejo.org/models/auth"
@@ -12,14 +13,38 @@ import (
 	user_model "forgejo.org/models/user"
 	"forgejo.org/modules/log"
 	"forgejo.org/modules/optional"
+	"forgejo.org/modules/util"
+	audit_service "forgejo.org/services/audit"
 	"forgejo.org/services/auth/source/oauth2"
 	"forgejo.org/services/auth/source/smtp"
+	user_service "forgejo.org/services/user"
 
 	_ "forgejo.org/services/auth/source/db"   // register the sources (and below)
 	_ "forgejo.org/services/auth/source/ldap" // register the ldap source
 	_ "forgejo.org/services/auth/source/pam"  // register the pam source
+	_ "forgejo.org/services/auth/source/saml" // register the saml source
 )
 
+// SignedIn registers the sign in of the user with the method: it updates the last login,
+// records it in the audit log and starts the two-factor grace period if the instance requires it.
+func SignedIn(ctx context.Context, u *user_model.User, method string) error {
+	if err := user_service.UpdateUser(ctx, u, &user_service.UpdateOptions{SetLastLogin: true}); err != nil {
+		return err
+	}
+	audit_service.RecordSignIn(ctx, u, method)
+	return StartInstanceTwoFactorGracePeriod(ctx, u)
+}
+
+// PasswordSignIn validates the user name and password submitted to sign in and records the failed attempts.
+func PasswordSignIn(ctx context.Context, username, password string) (*user_model.User, *auth.Source, error) {
+	u, source, err := UserSignIn(ctx, username, password)
+	if err != nil && (errors.Is(err, util.ErrNotExist) || errors.Is(err, util.ErrInvalidArgument) ||
+		user_model.IsErrEmailAlreadyUsed(err) || user_model.IsErrUserProhibitLogin(err)) {
+		audit_service.RecordSignInFailed(ctx, username, audit_service.SignInMethodPassword)
+	}
+	return u, source, err
+}
+
 // UserSignIn validates user name and password.
 func UserSignIn(ctx context.Context, username, password string) (*user_model.User, *auth.Source, error) {
 	var user *user_model.User
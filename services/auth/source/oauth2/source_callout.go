@@ -4,8 +4,14 @@
 package oauth2
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
+
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/container"
+	audit_service "forgejo.org/services/audit"
 
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/gothic"
@@ -40,8 +46,18 @@ func (source *Source) Callout(request *http.Request, response http.ResponseWrite
 	return err
 }
 
+// AuthenticateCallback handles the OAuth callback of the provider like Callback and records the failed logins in the audit log
+func (source *Source) AuthenticateCallback(request *http.Request, response http.ResponseWriter, codeVerifier string) (goth.User, error) {
+	user, err := source.Callback(request, response, codeVerifier)
+	if err != nil {
+		audit_service.RecordSignInFailed(request.Context(), user.UserID, audit_service.SignInMethodOAuth2)
+	}
+	return user, err
+}
+
 // Callback handles OAuth callback, resolve to a goth user and send back to original url
-// this will trigger a new authentication request, but because we save it in the session we can use that
+// this will trigger a new authentication request, but because we save it in the session we can use that.
+// Users without the required claim are prohibited to log in.
 func (source *Source) Callback(request *http.Request, response http.ResponseWriter, codeVerifier string) (goth.User, error) {
 	// not sure if goth is thread safe (?) when using multiple providers
 	request.Header.Set(ProviderHeaderKey, source.authSource.Name)
@@ -64,5 +80,38 @@ func (source *Source) Callback(request *http.Request, response http.ResponseWrit
 		return user, err
 	}
 
+	if source.RequiredClaimName != "" {
+		claimInterface, has := user.RawData[source.RequiredClaimName]
+		if !has {
+			return user, user_model.ErrUserProhibitLogin{Name: user.UserID}
+		}
+
+		if source.RequiredClaimValue != "" {
+			groups := ClaimValueToStringSet(claimInterface)
+
+			if !groups.Contains(source.RequiredClaimValue) {
+				return user, user_model.ErrUserProhibitLogin{Name: user.UserID}
+			}
+		}
+	}
+
 	return user, nil
 }
+
+// ClaimValueToStringSet returns the values of a claim which is either a list or a comma separated string
+func ClaimValueToStringSet(claimValue any) container.Set[string] {
+	var groups []string
+
+	switch rawGroup := claimValue.(type) {
+	case []string:
+		groups = rawGroup
+	case []any:
+		for _, group := range rawGroup {
+			groups = append(groups, fmt.Sprintf("%s", group))
+		}
+	default:
+		str := fmt.Sprintf("%s", rawGroup)
+		groups = strings.Split(str, ",")
+	}
+	return container.SetOf(groups...)
+}
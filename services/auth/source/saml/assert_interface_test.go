@@ -0,0 +1,22 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package saml_test
+
+import (
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/services/auth"
+	"forgejo.org/services/auth/source/saml"
+)
+
+// This test file exists to assert that our Source exposes the interfaces that we expect
+// It tightly binds the interfaces and implementation without breaking go import cycles
+
+type sourceInterface interface {
+	auth_model.Config
+	auth_model.SourceSettable
+	auth.PasswordAuthenticator
+	auth.LocalTwoFASkipper
+}
+
+var _ (sourceInterface) = &saml.Source{}
@@ -0,0 +1,112 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package saml
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"forgejo.org/models/auth"
+	"forgejo.org/modules/json"
+	"forgejo.org/modules/proxy"
+	saml_module "forgejo.org/modules/saml"
+	"forgejo.org/modules/setting"
+)
+
+// Source holds configuration for the SAML login source.
+type Source struct {
+	// IdentityProviderMetadata is the XML metadata of the identity provider
+	IdentityProviderMetadata string
+	// IdentityProviderMetadataURL is where the metadata was imported from, if any
+	IdentityProviderMetadataURL string
+	NameIDFormat                string
+
+	AttributeUsername string
+	AttributeFullName string
+	AttributeEmail    string
+
+	GroupAttribute      string
+	AdminGroup          string
+	RestrictedGroup     string
+	GroupTeamMap        string
+	GroupTeamMapRemoval bool
+
+	// AllowIdPInitiated accepts responses which were not requested by this instance
+	AllowIdPInitiated bool
+	SkipLocalTwoFA    bool `json:",omitempty"`
+
+	// reference to the authSource
+	authSource *auth.Source
+}
+
+// FromDB fills up a SAML Source from serialized format.
+func (source *Source) FromDB(bs []byte) error {
+	return json.UnmarshalHandleDoubleEncode(bs, &source)
+}
+
+// ToDB exports a SAML Source to a serialized format.
+func (source *Source) ToDB() ([]byte, error) {
+	return json.Marshal(source)
+}
+
+// SetAuthSource sets the related AuthSource
+func (source *Source) SetAuthSource(authSource *auth.Source) {
+	source.authSource = authSource
+}
+
+// ServiceProvider returns this instance as the service provider of the identity provider of the source
+func (source *Source) ServiceProvider() (*saml_module.ServiceProvider, error) {
+	idp, err := saml_module.ParseIdentityProviderMetadata([]byte(source.IdentityProviderMetadata))
+	if err != nil {
+		return nil, err
+	}
+	base := ServiceProviderURL(source.authSource.Name)
+	return &saml_module.ServiceProvider{
+		EntityID:                    base + "/metadata",
+		AssertionConsumerServiceURL: base + "/acs",
+		NameIDFormat:                source.NameIDFormat,
+		IdentityProvider:            idp,
+	}, nil
+}
+
+// ServiceProviderURL returns the base URL of the service provider endpoints of the named source
+func ServiceProviderURL(name string) string {
+	return setting.AppURL + "user/saml/" + url.PathEscape(name)
+}
+
+// FetchIdentityProviderMetadata downloads and validates the metadata of an identity provider
+func FetchIdentityProviderMetadata(ctx context.Context, metadataURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL, nil)
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &http.Transport{Proxy: proxy.Proxy()},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s fetching %s", resp.Status, metadataURL)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if _, err := saml_module.ParseIdentityProviderMetadata(data); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func init() {
+	auth.RegisterTypeConfig(auth.SAML, &Source{})
+}
@@ -0,0 +1,217 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package saml
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	user_model "forgejo.org/models/user"
+	auth_module "forgejo.org/modules/auth"
+	"forgejo.org/modules/cache"
+	"forgejo.org/modules/container"
+	"forgejo.org/modules/optional"
+	saml_module "forgejo.org/modules/saml"
+	audit_service "forgejo.org/services/audit"
+	source_service "forgejo.org/services/auth/source"
+	"forgejo.org/services/auth/source/db"
+	user_service "forgejo.org/services/user"
+)
+
+// requestTimeout is how long a user may take to log in at the identity provider
+const requestTimeout = 10 * time.Minute
+
+var (
+	// ErrUnsolicitedResponse is returned for responses to unknown requests if IdP-initiated login is not allowed
+	ErrUnsolicitedResponse = errors.New("the SAML response does not answer a pending request")
+	// ErrReplayedAssertion is returned if an assertion has already been used to log in
+	ErrReplayedAssertion = errors.New("the SAML assertion has already been used")
+)
+
+// ErrMissingAttribute is returned if the identity provider did not send a required attribute
+type ErrMissingAttribute struct {
+	Name string
+}
+
+func (err ErrMissingAttribute) Error() string {
+	return fmt.Sprintf("the SAML assertion has no attribute %q", err.Name)
+}
+
+// Authenticate falls back to the db authenticator
+func (source *Source) Authenticate(ctx context.Context, user *user_model.User, login, password string) (*user_model.User, error) {
+	return db.Authenticate(ctx, user, login, password)
+}
+
+// IsSkipLocalTwoFA returns if this source should skip local 2fa
+func (source *Source) IsSkipLocalTwoFA() bool {
+	return source.SkipLocalTwoFA
+}
+
+func requestCacheKey(requestID string) string {
+	return "saml_request_" + requestID
+}
+
+func assertionCacheKey(sourceID int64, assertionID string) string {
+	return fmt.Sprintf("saml_assertion_%d_%s", sourceID, assertionID)
+}
+
+// NewAuthnRequestURL starts a login and returns the URL of the identity provider to redirect the user to.
+// The request is remembered on the server because the response is posted cross-site without the session cookie.
+func (source *Source) NewAuthnRequestURL(relayState string) (string, error) {
+	sp, err := source.ServiceProvider()
+	if err != nil {
+		return "", err
+	}
+	requestID := saml_module.NewRequestID()
+	redirect, err := sp.AuthnRequestURL(requestID, relayState, time.Now())
+	if err != nil {
+		return "", err
+	}
+	if err := cache.GetCache().Put(requestCacheKey(requestID), strconv.FormatInt(source.authSource.ID, 10), int64(requestTimeout.Seconds())); err != nil {
+		return "", err
+	}
+	return redirect, nil
+}
+
+// AuthenticateResponse verifies a response posted by the identity provider and returns the user it logs in,
+// the user is created on first login and its admin and restricted flags and teams are synchronized.
+// The failed logins are recorded in the audit log with the NameID of the assertion if it is valid.
+func (source *Source) AuthenticateResponse(ctx context.Context, encoded string) (*user_model.User, error) {
+	sp, err := source.ServiceProvider()
+	if err != nil {
+		return nil, err
+	}
+	assertion, err := sp.ParseResponse(encoded, time.Now())
+	if err != nil {
+		audit_service.RecordSignInFailed(ctx, "", audit_service.SignInMethodSAML)
+		return nil, err
+	}
+	user, err := source.authenticateAssertion(ctx, assertion)
+	if err != nil {
+		audit_service.RecordSignInFailed(ctx, assertion.NameID().Value, audit_service.SignInMethodSAML)
+		return nil, err
+	}
+	return user, nil
+}
+
+func (source *Source) authenticateAssertion(ctx context.Context, assertion *saml_module.Assertion) (*user_model.User, error) {
+
+	c := cache.GetCache()
+	if assertion.InResponseTo != "" {
+		key := requestCacheKey(assertion.InResponseTo)
+		if sourceID, ok := c.Get(key).(string); !ok || sourceID != strconv.FormatInt(source.authSource.ID, 10) {
+			return nil, ErrUnsolicitedResponse
+		}
+		if err := c.Delete(key); err != nil {
+			return nil, err
+		}
+	} else if !source.AllowIdPInitiated {
+		return nil, ErrUnsolicitedResponse
+	}
+
+	key := assertionCacheKey(source.authSource.ID, assertion.ID)
+	if c.IsExist(key) {
+		return nil, ErrReplayedAssertion
+	}
+	if err := c.Put(key, "1", int64(time.Until(assertion.NotOnOrAfter).Seconds())+int64(saml_module.DefaultClockSkew.Seconds())); err != nil {
+		return nil, err
+	}
+
+	attributes := assertion.Attributes()
+	firstAttribute := func(name string) string {
+		if values := attributes[name]; len(values) > 0 {
+			return strings.TrimSpace(values[0])
+		}
+		return ""
+	}
+	groups := container.Set[string]{}
+	if source.GroupAttribute != "" {
+		groups.AddMultiple(attributes[source.GroupAttribute]...)
+	}
+	var isAdmin, isRestricted optional.Option[bool]
+	if source.AdminGroup != "" {
+		isAdmin = optional.Some(groups.Contains(source.AdminGroup))
+	}
+	if source.RestrictedGroup != "" {
+		isRestricted = optional.Some(groups.Contains(source.RestrictedGroup))
+	}
+
+	nameID := assertion.NameID().Value
+	user := &user_model.User{
+		LoginType:   source.authSource.Type,
+		LoginSource: source.authSource.ID,
+		LoginName:   nameID,
+	}
+	has, err := user_model.GetUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if has {
+		if user.ProhibitLogin {
+			return nil, user_model.ErrUserProhibitLogin{UID: user.ID, Name: user.Name}
+		}
+		opts := &user_service.UpdateOptions{IsAdmin: isAdmin, IsRestricted: isRestricted}
+		if opts.IsAdmin.Has() || opts.IsRestricted.Has() {
+			if err := user_service.UpdateUser(ctx, user, opts); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		username := nameID
+		if source.AttributeUsername != "" {
+			if username = firstAttribute(source.AttributeUsername); username == "" {
+				return nil, ErrMissingAttribute{source.AttributeUsername}
+			}
+		}
+		// without an email attribute the NameID must be an email address
+		email, emailAttribute := nameID, "NameID"
+		if source.AttributeEmail != "" {
+			email, emailAttribute = firstAttribute(source.AttributeEmail), source.AttributeEmail
+		}
+		if !strings.Contains(email, "@") {
+			return nil, ErrMissingAttribute{emailAttribute}
+		}
+		fullName := ""
+		if source.AttributeFullName != "" {
+			fullName = firstAttribute(source.AttributeFullName)
+		}
+
+		user = &user_model.User{
+			LowerName:   strings.ToLower(username),
+			Name:        username,
+			FullName:    fullName,
+			Email:       email,
+			LoginType:   source.authSource.Type,
+			LoginSource: source.authSource.ID,
+			LoginName:   nameID,
+			IsAdmin:     isAdmin.Value(),
+		}
+		overwriteDefault := &user_model.CreateUserOverwriteOptions{
+			IsActive: optional.Some(true),
+		}
+		if isRestricted.Has() {
+			overwriteDefault.IsRestricted = optional.Some(isRestricted.Value() && !isAdmin.Value())
+		}
+		if err := user_model.CreateUser(ctx, user, overwriteDefault); err != nil {
+			return nil, err
+		}
+	}
+
+	if source.GroupTeamMap != "" || source.GroupTeamMapRemoval {
+		groupTeamMapping, err := auth_module.UnmarshalGroupTeamMapping(source.GroupTeamMap)
+		if err != nil {
+			return nil, err
+		}
+		if err := source_service.SyncGroupsToTeams(ctx, user, groups, groupTeamMapping, source.GroupTeamMapRemoval); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
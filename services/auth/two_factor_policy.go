@@ -0,0 +1,79 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth
+
+import (
+	"context"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/organization"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/timeutil"
+)
+
+// TwoFactorPolicy is how the requirement to enable two-factor authentication applies to a user
+type TwoFactorPolicy int
+
+const (
+	TwoFactorNotRequired  TwoFactorPolicy = iota // the user is not required to enable two-factor authentication
+	TwoFactorCompliant                           // the user is required to and did enable two-factor authentication
+	TwoFactorGracePeriod                         // the user must enable two-factor authentication before the deadline
+	TwoFactorGraceExpired                        // the user did not enable two-factor authentication in time
+)
+
+// TwoFactorRequired returns whether the instance or one of the organizations of the user requires
+// them to enable two-factor authentication. Bots, organizations and other non-individual users are exempt.
+func TwoFactorRequired(ctx context.Context, u *user_model.User) (bool, error) {
+	if u.IsBot() || !u.IsIndividual() {
+		return false, nil
+	}
+	if setting.RequireTwoFactor {
+		return true, nil
+	}
+	return organization.HasOrgRequiringTwoFactor(ctx, u.ID)
+}
+
+// CheckTwoFactorPolicy returns how the requirement to enable two-factor authentication applies to the user,
+// and when their grace period ends if they did not enable it. The grace period starts if it did not yet.
+func CheckTwoFactorPolicy(ctx context.Context, u *user_model.User) (TwoFactorPolicy, timeutil.TimeStamp, error) {
+	required, err := TwoFactorRequired(ctx, u)
+	if err != nil || !required {
+		return TwoFactorNotRequired, 0, err
+	}
+	hasTwoFactor, err := auth_model.HasTwoFactorByUID(ctx, u.ID)
+	if err != nil {
+		return TwoFactorNotRequired, 0, err
+	}
+	if hasTwoFactor {
+		return TwoFactorCompliant, 0, nil
+	}
+
+	deadline, err := user_model.GetTwoFactorGraceDeadline(ctx, u.ID)
+	if err != nil {
+		return TwoFactorNotRequired, 0, err
+	}
+	if timeutil.TimeStampNow() < deadline {
+		return TwoFactorGracePeriod, deadline, nil
+	}
+	return TwoFactorGraceExpired, deadline, nil
+}
+
+// StartInstanceTwoFactorGracePeriod starts the grace period of a user signing in who is required to enable
+// two-factor authentication by the instance, unless it already started or they did enable it
+func StartInstanceTwoFactorGracePeriod(ctx context.Context, u *user_model.User) error {
+	if !setting.RequireTwoFactor || u.IsBot() || !u.IsIndividual() {
+		return nil
+	}
+	started, err := user_model.HasTwoFactorGracePeriod(ctx, u.ID)
+	if err != nil || started {
+		return err
+	}
+	hasTwoFactor, err := auth_model.HasTwoFactorByUID(ctx, u.ID)
+	if err != nil || hasTwoFactor {
+		return err
+	}
+	_, err = user_model.StartTwoFactorGracePeriod(ctx, u.ID)
+	return err
+}
@@ -0,0 +1,65 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth
+
+import (
+	"testing"
+
+	"forgejo.org/models/db"
+	"forgejo.org/models/unittest"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTwoFactorPolicy(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	defer test.MockVariableValue(&setting.RequireTwoFactor, true)()
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	gracePeriodStarted := func() bool {
+		return unittest.BeanExists(t, &user_model.Setting{UserID: user.ID, SettingKey: user_model.SettingsKeyTwoFactorGraceDeadline})
+	}
+
+	t.Run("Bots are exempt", func(t *testing.T) {
+		bot := &user_model.User{ID: user.ID, Type: user_model.UserTypeBot}
+		required, err := TwoFactorRequired(db.DefaultContext, bot)
+		require.NoError(t, err)
+		assert.False(t, required)
+
+		require.NoError(t, StartInstanceTwoFactorGracePeriod(db.DefaultContext, bot))
+		assert.False(t, gracePeriodStarted())
+	})
+
+	t.Run("Signing in starts the grace period", func(t *testing.T) {
+		require.NoError(t, StartInstanceTwoFactorGracePeriod(db.DefaultContext, user))
+		assert.True(t, gracePeriodStarted())
+		require.NoError(t, user_model.DeleteUserSetting(db.DefaultContext, user.ID, user_model.SettingsKeyTwoFactorGraceDeadline))
+	})
+
+	// the grace period of a user who never signs in, e.g. who only uses access tokens, starts once the policy is checked
+	policy, deadline, err := CheckTwoFactorPolicy(db.DefaultContext, user)
+	require.NoError(t, err)
+	assert.Equal(t, TwoFactorGracePeriod, policy)
+	assert.True(t, gracePeriodStarted())
+
+	// and it does not move on the next checks
+	_, nextDeadline, err := CheckTwoFactorPolicy(db.DefaultContext, user)
+	require.NoError(t, err)
+	assert.Equal(t, deadline, nextDeadline)
+
+	require.NoError(t, user_model.SetUserSetting(db.DefaultContext, user.ID, user_model.SettingsKeyTwoFactorGraceDeadline, "1"))
+	policy, _, err = CheckTwoFactorPolicy(db.DefaultContext, user)
+	require.NoError(t, err)
+	assert.Equal(t, TwoFactorGraceExpired, policy)
+
+	// the grace period does not restart once it started
+	require.NoError(t, StartInstanceTwoFactorGracePeriod(db.DefaultContext, user))
+	policy, _, err = CheckTwoFactorPolicy(db.DefaultContext, user)
+	require.NoError(t, err)
+	assert.Equal(t, TwoFactorGraceExpired, policy)
+}
@@ -456,7 +456,8 @@ func (ctx *APIContext) NotFoundOrServerError(logMsg string, errCheck func(error)
 
 // IsUserSiteAdmin returns true if current user is a site admin
 func (ctx *APIContext) IsUserSiteAdmin() bool {
-	return ctx.IsSigned && ctx.Doer.IsAdmin
+	// fine-grained tokens never act as site administrator
+	return ctx.IsSigned && ctx.Doer.IsAdmin && FineGrainedAccessToken(ctx.Data) == nil
 }
 
 // IsUserRepoAdmin returns true if current user is admin in current repo
@@ -4,6 +4,7 @@
 package context
 
 import (
+	"net"
 	"net/http"
 	"strings"
 
@@ -52,5 +53,14 @@ func (ctx *Context) SetLTACookie(u *user_model.User) error {
 		return err
 	}
 	ctx.SetSiteCookie(setting.CookieRememberName, lookup+":"+validator, days)
-	return nil
+	return ctx.UpdateLTAClient(lookup)
+}
+
+// UpdateLTAClient remembers the client which uses the LTA token
+func (ctx *Context) UpdateLTAClient(lookupKey string) error {
+	ip, _, err := net.SplitHostPort(ctx.RemoteAddr())
+	if err != nil {
+		ip = ctx.RemoteAddr()
+	}
+	return auth_model.UpdateAuthTokenClient(ctx, lookupKey, ip, ctx.Req.UserAgent())
 }
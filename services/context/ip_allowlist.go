@@ -0,0 +1,84 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package context
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+
+	"forgejo.org/models/organization"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/log"
+)
+
+// IPAllowlistBlockedMessage is the message shown to the clients blocked by the IP allowlist of an organization
+const IPAllowlistBlockedMessage = "Your IP address is not allowed to access this organization."
+
+// IsBlockedByIPAllowlist returns true if the IP allowlist of the owner does not allow the client at remoteAddr
+// to access its content. Actions runners are exempt, and in dry run mode the would-be blocks are only logged.
+func IsBlockedByIPAllowlist(ctx context.Context, owner, doer *user_model.User, remoteAddr string) (bool, error) {
+	if owner == nil || !owner.IsOrganization() {
+		return false, nil
+	}
+	if doer != nil && doer.ID == user_model.ActionsUserID {
+		return false, nil
+	}
+
+	mode, err := organization.GetIPAllowlistMode(ctx, owner.ID)
+	if err != nil || mode == organization.IPAllowlistDisabled {
+		return false, err
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	// an address which cannot be parsed is in none of the ranges
+	addr, _ := netip.ParseAddr(host)
+	allowed, err := organization.IsIPAllowlisted(ctx, owner.ID, addr)
+	if err != nil || allowed {
+		return false, err
+	}
+
+	doerName := "anonymous"
+	if doer != nil {
+		doerName = doer.Name
+	}
+	if mode == organization.IPAllowlistDryRun {
+		log.Warn("IP allowlist of %s would block %s from %s (dry run)", owner.Name, doerName, host)
+		return false, nil
+	}
+	log.Warn("IP allowlist of %s blocked %s from %s", owner.Name, doerName, host)
+	return true, nil
+}
+
+// checkIPAllowlist responds with an error and returns false if the IP allowlist of the owner blocks the request
+func (ctx *Context) checkIPAllowlist(owner *user_model.User) bool {
+	blocked, err := IsBlockedByIPAllowlist(ctx, owner, ctx.Doer, ctx.RemoteAddr())
+	if err != nil {
+		ctx.ServerError("IsBlockedByIPAllowlist", err)
+		return false
+	}
+	if blocked {
+		ctx.PlainText(http.StatusForbidden, IPAllowlistBlockedMessage)
+		return false
+	}
+	return true
+}
+
+// CheckIPAllowlist responds with an error and returns false if the IP allowlist of the owner blocks the request
+func (ctx *APIContext) CheckIPAllowlist(owner *user_model.User) bool {
+	blocked, err := IsBlockedByIPAllowlist(ctx, owner, ctx.Doer, ctx.RemoteAddr())
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsBlockedByIPAllowlist", err)
+		return false
+	}
+	if blocked {
+		ctx.Error(http.StatusForbidden, "IPAllowlist", IPAllowlistBlockedMessage)
+		return false
+	}
+	return true
+}
@@ -113,6 +113,10 @@ func HandleOrgAssignment(ctx *Context, args ...bool) {
 		return
 	}
 
+	if !ctx.checkIPAllowlist(org.AsUser()) {
+		return
+	}
+
 	if org.Visibility == structs.VisibleTypePrivate {
 		requireMember = true
 	} else if ctx.IsSigned && ctx.Doer.IsRestricted {
@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"net/http"
 
+	actions_model "forgejo.org/models/actions"
 	"forgejo.org/models/organization"
 	packages_model "forgejo.org/models/packages"
 	"forgejo.org/models/perm"
+	repo_model "forgejo.org/models/repo"
 	"forgejo.org/models/unit"
 	user_model "forgejo.org/models/user"
 	"forgejo.org/modules/setting"
@@ -92,6 +94,11 @@ func packageAssignment(ctx *packageAssignmentCtx, errCb func(int, string, any))
 	return pkg
 }
 
+// DeterminePackageAccessMode returns the access mode of the doer to the packages of the owner
+func DeterminePackageAccessMode(ctx *Base, owner, doer *user_model.User) (perm.AccessMode, error) {
+	return determineAccessMode(ctx, &Package{Owner: owner}, doer)
+}
+
 func determineAccessMode(ctx *Base, pkg *Package, doer *user_model.User) (perm.AccessMode, error) {
 	if setting.Service.RequireSignInView && (doer == nil || doer.IsGhost()) {
 		return perm.AccessModeNone, nil
@@ -101,7 +108,10 @@ func determineAccessMode(ctx *Base, pkg *Package, doer *user_model.User) (perm.A
 		return perm.AccessModeNone, nil
 	}
 
-	// TODO: ActionUser permission check
+	if doer.IsActions() {
+		return determineActionsAccessMode(ctx, pkg)
+	}
+
 	accessMode := perm.AccessModeNone
 	if pkg.Owner.IsOrganization() {
 		org := organization.OrgFromUser(pkg.Owner)
@@ -148,6 +158,37 @@ func determineAccessMode(ctx *Base, pkg *Package, doer *user_model.User) (perm.A
 	return accessMode, nil
 }
 
+// determineActionsAccessMode returns the access mode of an Actions task token.
+// Tasks may publish packages to the owner of their repository, tasks of fork pull requests are limited to read access.
+func determineActionsAccessMode(ctx *Base, pkg *Package) (perm.AccessMode, error) {
+	taskID, ok := ctx.Data["ActionsTaskID"].(int64)
+	if !ok {
+		return perm.AccessModeNone, nil
+	}
+
+	task, err := actions_model.GetTaskByID(ctx, taskID)
+	if err != nil {
+		return perm.AccessModeNone, err
+	}
+	repo, err := repo_model.GetRepositoryByID(ctx, task.RepoID)
+	if err != nil {
+		return perm.AccessModeNone, err
+	}
+
+	if repo.OwnerID == pkg.Owner.ID {
+		if task.IsForkPullRequest {
+			return perm.AccessModeRead, nil
+		}
+		return perm.AccessModeWrite, nil
+	}
+
+	if pkg.Owner.Visibility == structs.VisibleTypePublic {
+		return perm.AccessModeRead, nil
+	}
+
+	return perm.AccessModeNone, nil
+}
+
 // PackageContexter initializes a package context for a request.
 func PackageContexter() func(next http.Handler) http.Handler {
 	renderer := templates.HTMLRenderer()
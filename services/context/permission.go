@@ -7,9 +7,11 @@ import (
 	"net/http"
 
 	auth_model "forgejo.org/models/auth"
+	access_model "forgejo.org/models/perm/access"
 	repo_model "forgejo.org/models/repo"
 	"forgejo.org/models/unit"
 	"forgejo.org/modules/log"
+	"forgejo.org/modules/web/middleware"
 )
 
 // RequireRepoAdmin returns a middleware for requiring repository admin permission
@@ -113,10 +115,24 @@ func RequireRepoReaderOr(unitTypes ...unit.Type) func(ctx *Context) {
 
 // CheckRepoScopedToken check whether personal access token has repo scope
 func CheckRepoScopedToken(ctx *Context, repo *repo_model.Repository, level auth_model.AccessTokenScopeLevel) {
+	CheckRepoScopedTokenForUnit(ctx, repo, unit.TypeCode, level)
+}
+
+// CheckRepoScopedTokenForUnit check whether personal access token has repo scope,
+// fine-grained access tokens must also allow the access to the unit of the repository
+func CheckRepoScopedTokenForUnit(ctx *Context, repo *repo_model.Repository, unitType unit.Type, level auth_model.AccessTokenScopeLevel) {
 	if !ctx.IsBasicAuth || ctx.Data["IsApiToken"] != true {
 		return
 	}
 
+	if token := FineGrainedAccessToken(ctx.Data); token != nil {
+		if repo == nil || !token.CanAccessRepo(repo.ID, repo.OwnerID) ||
+			auth_model.GetScopeLevelFromAccessMode(token.RepoPermissions.UnitAccessMode(unitType)) < level {
+			ctx.Error(http.StatusForbidden)
+			return
+		}
+	}
+
 	scope, ok := ctx.Data["ApiTokenScope"].(auth_model.AccessTokenScope)
 	if ok { // it's a personal access token but not oauth2 token
 		var scopeMatched bool
@@ -147,3 +163,26 @@ func CheckRepoScopedToken(ctx *Context, repo *repo_model.Repository, level auth_
 		}
 	}
 }
+
+// FineGrainedAccessToken returns the fine-grained access token the request is authenticated with, if any
+func FineGrainedAccessToken(data middleware.ContextData) *auth_model.AccessToken {
+	token, ok := data["ApiToken"].(*auth_model.AccessToken)
+	if !ok || !token.FineGrained {
+		return nil
+	}
+	return token
+}
+
+// LimitPermissionToAccessToken limits the permission in the repository to what the fine-grained access token
+// the request is authenticated with allows, the permission is removed if the token is limited to other repositories
+func LimitPermissionToAccessToken(data middleware.ContextData, repo *repo_model.Repository, permission *access_model.Permission) {
+	token := FineGrainedAccessToken(data)
+	if token == nil {
+		return
+	}
+	if !token.CanAccessRepo(repo.ID, repo.OwnerID) {
+		*permission = access_model.Permission{}
+		return
+	}
+	permission.LimitUnitsMode(token.RepoPermissions.UnitAccessMode)
+}
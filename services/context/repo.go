@@ -378,6 +378,7 @@ func repoAssignment(ctx *Context, repo *repo_model.Repository) {
 		ctx.ServerError("GetUserRepoPermission", err)
 		return
 	}
+	LimitPermissionToAccessToken(ctx.Data, repo, &ctx.Repo.Permission)
 
 	// Check access.
 	if !ctx.Repo.HasAccess() {
@@ -495,6 +496,10 @@ func RepoAssignment(ctx *Context) context.CancelFunc {
 	ctx.Data["ContextUser"] = ctx.ContextUser
 	ctx.Data["Username"] = ctx.Repo.Owner.Name
 
+	if !ctx.checkIPAllowlist(owner) || !ctx.checkTwoFactorPolicy(owner) {
+		return nil
+	}
+
 	// redirect link to wiki
 	if strings.HasSuffix(repoName, ".wiki") {
 		// ctx.Req.URL.Path does not have the preceding appSubURL - any redirect must have this added
@@ -0,0 +1,77 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package context
+
+import (
+	"context"
+	"net/http"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/organization"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/timeutil"
+)
+
+// TwoFactorBlockedMessage is the message shown to the members blocked because they did not enable two-factor authentication
+const TwoFactorBlockedMessage = "This organization requires its members to enable two-factor authentication."
+
+// IsBlockedByTwoFactorPolicy returns true if the owner is an organization which requires its members to enable
+// two-factor authentication, and the doer is a member who did not enable it before the end of their grace period.
+// Bots are exempt.
+func IsBlockedByTwoFactorPolicy(ctx context.Context, owner, doer *user_model.User) (bool, error) {
+	if owner == nil || !owner.IsOrganization() || doer == nil || doer.IsBot() || !doer.IsIndividual() {
+		return false, nil
+	}
+
+	required, err := organization.IsTwoFactorRequired(ctx, owner.ID)
+	if err != nil || !required {
+		return false, err
+	}
+	isMember, err := organization.IsOrganizationMember(ctx, owner.ID, doer.ID)
+	if err != nil || !isMember {
+		return false, err
+	}
+	hasTwoFactor, err := auth_model.HasTwoFactorByUID(ctx, doer.ID)
+	if err != nil || hasTwoFactor {
+		return false, err
+	}
+	deadline, err := user_model.GetTwoFactorGraceDeadline(ctx, doer.ID)
+	if err != nil || timeutil.TimeStampNow() < deadline {
+		return false, err
+	}
+
+	log.Warn("Two-factor authentication policy of %s blocked %s", owner.Name, doer.Name)
+	return true, nil
+}
+
+// checkTwoFactorPolicy responds with an error and returns false if the two-factor authentication policy of the owner
+// blocks the request
+func (ctx *Context) checkTwoFactorPolicy(owner *user_model.User) bool {
+	blocked, err := IsBlockedByTwoFactorPolicy(ctx, owner, ctx.Doer)
+	if err != nil {
+		ctx.ServerError("IsBlockedByTwoFactorPolicy", err)
+		return false
+	}
+	if blocked {
+		ctx.PlainText(http.StatusForbidden, TwoFactorBlockedMessage)
+		return false
+	}
+	return true
+}
+
+// CheckTwoFactorPolicy responds with an error and returns false if the two-factor authentication policy of the owner
+// blocks the request
+func (ctx *APIContext) CheckTwoFactorPolicy(owner *user_model.User) bool {
+	blocked, err := IsBlockedByTwoFactorPolicy(ctx, owner, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsBlockedByTwoFactorPolicy", err)
+		return false
+	}
+	if blocked {
+		ctx.Error(http.StatusForbidden, "TwoFactorPolicy", TwoFactorBlockedMessage)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,28 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package convert
+
+import (
+	audit_model "forgejo.org/models/audit"
+	api "forgejo.org/modules/structs"
+)
+
+// ToAuditEvent converts an audit event to its API format
+func ToAuditEvent(e *audit_model.Event) *api.AuditEvent {
+	return &api.AuditEvent{
+		ID:         e.ID,
+		Action:     string(e.Action),
+		ActorID:    e.ActorID,
+		ActorName:  e.ActorName,
+		IPAddress:  e.IPAddress,
+		OwnerID:    e.OwnerID,
+		RepoID:     e.RepoID,
+		TargetType: string(e.TargetType),
+		TargetID:   e.TargetID,
+		TargetName: e.TargetName,
+		Before:     e.Before,
+		After:      e.After,
+		Created:    e.CreatedUnix.AsTime(),
+	}
+}
@@ -484,6 +484,34 @@ func ToOAuth2Application(app *auth.OAuth2Application) *api.OAuth2Application {
 	}
 }
 
+// ToInstallableApplication convert an installable auth.OAuth2Application to api.InstallableApplication
+func ToInstallableApplication(ctx context.Context, app *auth.OAuth2Application) (*api.InstallableApplication, error) {
+	bot, err := user_model.GetUserByID(ctx, app.BotUserID)
+	if err != nil {
+		return nil, err
+	}
+	return &api.InstallableApplication{
+		ID:          app.ID,
+		Name:        app.Name,
+		ClientID:    app.ClientID,
+		Bot:         ToUser(ctx, bot, nil),
+		Permissions: app.AppPermissions.StringSlice(),
+		Created:     app.CreatedUnix.AsTime(),
+	}, nil
+}
+
+// ToApplicationInstallation convert an auth.OAuth2ApplicationInstallation to api.ApplicationInstallation
+func ToApplicationInstallation(ctx context.Context, inst *auth.OAuth2ApplicationInstallation, owner *user_model.User) *api.ApplicationInstallation {
+	return &api.ApplicationInstallation{
+		ID:            inst.ID,
+		Owner:         ToUser(ctx, owner, nil),
+		RepositoryIDs: inst.RepoIDs,
+		Permissions:   inst.Permissions.StringSlice(),
+		Created:       inst.CreatedUnix.AsTime(),
+		Updated:       inst.UpdatedUnix.AsTime(),
+	}
+}
+
 // ToLFSLock convert a LFSLock to api.LFSLock
 func ToLFSLock(ctx context.Context, l *git_model.LFSLock) *api.LFSLock {
 	u, err := user_model.GetUserByID(ctx, l.OwnerID)
@@ -5,11 +5,14 @@ package convert
 
 import (
 	"context"
+	"fmt"
 
 	"forgejo.org/models/packages"
 	access_model "forgejo.org/models/perm/access"
 	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/setting"
 	api "forgejo.org/modules/structs"
+	packages_service "forgejo.org/services/packages"
 )
 
 // ToPackage convert a packages.PackageDescriptor to api.Package
@@ -27,15 +30,16 @@ func ToPackage(ctx context.Context, pd *packages.PackageDescriptor, doer *user_m
 	}
 
 	return &api.Package{
-		ID:         pd.Version.ID,
-		Owner:      ToUser(ctx, pd.Owner, doer),
-		Repository: repo,
-		Creator:    ToUser(ctx, pd.Creator, doer),
-		Type:       string(pd.Package.Type),
-		Name:       pd.Package.Name,
-		Version:    pd.Version.Version,
-		CreatedAt:  pd.Version.CreatedUnix.AsTime(),
-		HTMLURL:    pd.VersionHTMLURL(),
+		ID:          pd.Version.ID,
+		Owner:       ToUser(ctx, pd.Owner, doer),
+		Repository:  repo,
+		Creator:     ToUser(ctx, pd.Creator, doer),
+		Type:        string(pd.Package.Type),
+		Name:        pd.Package.Name,
+		Version:     pd.Version.Version,
+		CreatedAt:   pd.Version.CreatedUnix.AsTime(),
+		HTMLURL:     pd.VersionHTMLURL(),
+		IsImmutable: pd.Version.IsImmutable,
 	}, nil
 }
 
@@ -51,3 +55,129 @@ func ToPackageFile(pfd *packages.PackageFileDescriptor) *api.PackageFile {
 		HashSHA512: pfd.Blob.HashSHA512,
 	}
 }
+
+// ToPackageChannel converts a packages.PackageChannel to api.PackageChannel
+func ToPackageChannel(ctx context.Context, pc *packages.PackageChannel, pv *packages.PackageVersion, promoter, doer *user_model.User) *api.PackageChannel {
+	return &api.PackageChannel{
+		Name:       pc.Name,
+		Version:    pv.Version,
+		PromotedBy: ToUser(ctx, promoter, doer),
+		UpdatedAt:  pc.UpdatedUnix.AsTime(),
+	}
+}
+
+// ToPackagePromotion converts a packages.PackagePromotion to api.PackagePromotion
+func ToPackagePromotion(ctx context.Context, pp *packages.PackagePromotion, sourceOwner, promoter, doer *user_model.User) *api.PackagePromotion {
+	return &api.PackagePromotion{
+		ID:          pp.ID,
+		SourceOwner: ToUser(ctx, sourceOwner, doer),
+		Channel:     pp.Channel,
+		Promoter:    ToUser(ctx, promoter, doer),
+		CreatedAt:   pp.CreatedUnix.AsTime(),
+	}
+}
+
+// ToPackageSecurityReport converts the scan results of a package version to api.PackageSecurityReport
+func ToPackageSecurityReport(licenses []string, pvs []*packages.PackageVulnerability) *api.PackageSecurityReport {
+	vulnerabilities := make([]*api.PackageVulnerability, 0, len(pvs))
+	for _, pv := range pvs {
+		vulnerabilities = append(vulnerabilities, &api.PackageVulnerability{
+			AdvisoryID:            pv.AdvisoryID,
+			DependencyName:        pv.DependencyName,
+			DependencyRequirement: pv.DependencyRequirement,
+			Severity:              pv.Severity,
+			Summary:               pv.Summary,
+			URL:                   pv.URL,
+		})
+	}
+	return &api.PackageSecurityReport{
+		Licenses:        licenses,
+		Vulnerabilities: vulnerabilities,
+	}
+}
+
+// ToPackageProvenance converts the provenance of a package version to a SLSA provenance statement
+func ToPackageProvenance(pd *packages.PackageDescriptor, p *packages_service.Provenance) *api.PackageProvenance {
+	subjects := make([]*api.PackageProvenanceSubject, 0, len(pd.Files))
+	for _, pfd := range pd.Files {
+		subjects = append(subjects, &api.PackageProvenanceSubject{
+			Name: pfd.File.Name,
+			Digest: map[string]string{
+				"sha256": pfd.Blob.HashSHA256,
+			},
+		})
+	}
+
+	var repoURL string
+	if p.Repository != nil {
+		repoURL = p.Repository.HTMLURL()
+	}
+
+	metadata := &api.PackageProvenanceMetadata{}
+	if p.Run != nil {
+		metadata.InvocationID = p.Run.HTMLURL()
+		if p.Run.Started != 0 {
+			started := p.Run.Started.AsTime()
+			metadata.StartedOn = &started
+		}
+		if p.Run.Stopped != 0 {
+			stopped := p.Run.Stopped.AsTime()
+			metadata.FinishedOn = &stopped
+		}
+	}
+
+	return &api.PackageProvenance{
+		Type:          "https://in-toto.io/Statement/v1",
+		Subject:       subjects,
+		PredicateType: "https://slsa.dev/provenance/v1",
+		Predicate: &api.PackageProvenancePredicate{
+			BuildDefinition: &api.PackageProvenanceBuildDefinition{
+				BuildType: "https://forgejo.org/actions/workflow/v1",
+				ExternalParameters: map[string]any{
+					"workflow": map[string]string{
+						"ref":        p.Ref,
+						"repository": repoURL,
+						"path":       p.WorkflowID,
+					},
+				},
+				InternalParameters: map[string]any{
+					"run_id":  p.RunID,
+					"task_id": p.TaskID,
+				},
+				ResolvedDependencies: []*api.PackageProvenanceResourceDescriptor{
+					{
+						URI: fmt.Sprintf("git+%s@%s", repoURL, p.Ref),
+						Digest: map[string]string{
+							"gitCommit": p.CommitSHA,
+						},
+					},
+				},
+			},
+			RunDetails: &api.PackageProvenanceRunDetails{
+				Builder: &api.PackageProvenanceBuilder{
+					ID: setting.AppURL + "actions",
+				},
+				Metadata: metadata,
+			},
+		},
+	}
+}
+
+// ToPackageVirtualRegistry converts a packages.PackageVirtualRegistry to api.PackageVirtualRegistry
+func ToPackageVirtualRegistry(owner *user_model.User, vr *packages.PackageVirtualRegistry, members []*user_model.User) *api.PackageVirtualRegistry {
+	names := make([]string, 0, len(members))
+	for _, m := range members {
+		names = append(names, m.Name)
+	}
+
+	return &api.PackageVirtualRegistry{
+		ID:          vr.ID,
+		Name:        vr.Name,
+		Type:        string(vr.Type),
+		Members:     names,
+		UpstreamURL: vr.UpstreamURL,
+		RegistryURL: fmt.Sprintf("%sapi/packages/%s/virtual/%s/%s", setting.AppURL, owner.Name, vr.Name, vr.Type),
+		CreatedAt:   vr.CreatedUnix.AsTime(),
+		UpdatedAt:   vr.UpdatedUnix.AsTime(),
+	}
+}
@@ -267,3 +267,19 @@ func ToAPIPullRequest(ctx context.Context, pr *issues_model.PullRequest, doer *u
 
 	return apiPullRequest
 }
+
+// ToFederatedPullRequest converts a PullRequestOffer to API format
+func ToFederatedPullRequest(offer *issues_model.PullRequestOffer) *api.FederatedPullRequest {
+	return &api.FederatedPullRequest{
+		ID:       offer.ID,
+		Head:     offer.HeadBranch,
+		Target:   offer.TargetRepo,
+		Base:     offer.TargetBranch,
+		Title:    offer.Title,
+		OfferID:  offer.OfferID,
+		TicketID: offer.TicketID,
+		Status:   offer.Status.String(),
+		Created:  offer.CreatedUnix.AsTime(),
+		Updated:  offer.UpdatedUnix.AsTime(),
+	}
+}
@@ -8,11 +8,13 @@ import (
 	"time"
 
 	"forgejo.org/models"
+	auth_model "forgejo.org/models/auth"
 	git_model "forgejo.org/models/git"
 	user_model "forgejo.org/models/user"
 	"forgejo.org/models/webhook"
 	"forgejo.org/modules/git"
 	"forgejo.org/modules/setting"
+	"forgejo.org/services/audit"
 	"forgejo.org/services/auth"
 	"forgejo.org/services/migrations"
 	mirror_service "forgejo.org/services/mirror"
@@ -156,6 +158,26 @@ func registerCleanupPackages() {
 	})
 }
 
+func registerCleanupOAuth2DeviceAuthorizations() {
+	RegisterTaskFatal("cleanup_oauth2_device_authorizations", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@every 1h",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return auth_model.DeleteExpiredOAuth2DeviceAuthorizations(ctx)
+	})
+}
+
+func registerCleanupAuditEvents() {
+	RegisterTaskFatal("cleanup_audit_events", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@midnight",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return audit.Cleanup(ctx)
+	})
+}
+
 func initBasicTasks() {
 	if setting.Mirror.Enabled {
 		registerUpdateMirrorTask()
@@ -172,4 +194,10 @@ func initBasicTasks() {
 	if setting.Packages.Enabled {
 		registerCleanupPackages()
 	}
+	if setting.OAuth2.Enabled {
+		registerCleanupOAuth2DeviceAuthorizations()
+	}
+	if setting.Audit.Enabled {
+		registerCleanupAuditEvents()
+	}
 }
@@ -0,0 +1,104 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package federation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/activitypub"
+	"forgejo.org/modules/graceful"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/queue"
+	notify_service "forgejo.org/services/notify"
+)
+
+// deliveryItem is an activity which is sent to the inbox of a federated actor
+type deliveryItem struct {
+	DoerID   int64
+	InboxURL string
+	Payload  []byte
+}
+
+var deliveryQueue *queue.WorkerPoolQueue[*deliveryItem]
+
+// Init starts the queue which delivers activities to other instances
+func Init() error {
+	deliveryQueue = queue.CreateSimpleQueue(graceful.GetManager().ShutdownContext(), "activitypub_delivery", deliveryHandler)
+	if deliveryQueue == nil {
+		return errors.New("unable to create activitypub_delivery queue")
+	}
+	go graceful.GetManager().RunWithCancel(deliveryQueue)
+
+	notify_service.RegisterNotifier(&userActivityNotifier{})
+	return nil
+}
+
+func deliveryHandler(items ...*deliveryItem) []*deliveryItem {
+	ctx := graceful.GetManager().ShutdownContext()
+	for _, item := range items {
+		if err := deliver(ctx, item); err != nil {
+			log.Error("Unable to deliver activity of user %d to %s: %v", item.DoerID, item.InboxURL, err)
+		}
+	}
+	return nil
+}
+
+func deliver(ctx context.Context, item *deliveryItem) error {
+	if denied, err := IsURIDenied(ctx, item.InboxURL); err != nil {
+		return err
+	} else if denied {
+		return ErrFederationHostBlocked
+	}
+
+	var doer *user_model.User
+	if item.DoerID == user_model.APServerActorUserID {
+		doer = user_model.NewAPServerActor()
+	} else {
+		var err error
+		doer, err = user_model.GetUserByID(ctx, item.DoerID)
+		if err != nil {
+			return err
+		}
+	}
+
+	clientFactory, err := activitypub.GetClientFactory(ctx)
+	if err != nil {
+		return err
+	}
+	apClient, err := clientFactory.WithKeys(ctx, doer, doer.APActorKeyID())
+	if err != nil {
+		return err
+	}
+
+	resp, err := apClient.Post(item.Payload, item.InboxURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("inbox returned status %s", resp.Status)
+	}
+	return nil
+}
+
+type activityMarshaler interface {
+	MarshalJSON() ([]byte, error)
+}
+
+// enqueueActivity queues an activity of doer for the delivery to an inbox
+func enqueueActivity(doer *user_model.User, inboxURL string, activity activityMarshaler) error {
+	payload, err := activity.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return deliveryQueue.Push(&deliveryItem{
+		DoerID:   doer.ID,
+		InboxURL: inboxURL,
+		Payload:  payload,
+	})
+}
@@ -134,6 +134,7 @@ func CreateFederationHostFromAP(ctx context.Context, actorID fm.ActorID) (*forge
 	if err != nil {
 		return nil, err
 	}
+	result.Quarantined = setting.Federation.QuarantineNewHosts
 
 	err = forgefed.CreateFederationHost(ctx, &result)
 	if err != nil {
@@ -149,6 +150,11 @@ func GetFederationHostForURI(ctx context.Context, actorURI string) (*forgefed.Fe
 	if err != nil {
 		return nil, err
 	}
+	if denied, err := IsHostDenied(ctx, rawActorID.Host); err != nil {
+		return nil, err
+	} else if denied {
+		return nil, fmt.Errorf("%w: %s", ErrFederationHostBlocked, rawActorID.Host)
+	}
 	federationHost, err := forgefed.FindFederationHostByFqdnAndPort(ctx, rawActorID.Host, rawActorID.HostPort)
 	if err != nil {
 		return nil, err
@@ -229,6 +235,9 @@ func CreateUserFromAP(ctx context.Context, personID fm.PersonID, federationHostI
 		FederationHostID:      federationHostID,
 		NormalizedOriginalURL: personID.AsURI(),
 	}
+	if person.Inbox != nil {
+		federatedUser.InboxURL = person.Inbox.GetID().String()
+	}
 
 	err = user.CreateFederatedUser(ctx, &newUser, &federatedUser)
 	if err != nil {
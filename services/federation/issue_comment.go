@@ -0,0 +1,227 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package federation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	issues_model "forgejo.org/models/issues"
+	repo_model "forgejo.org/models/repo"
+	"forgejo.org/models/unit"
+	user_model "forgejo.org/models/user"
+	fm "forgejo.org/modules/forgefed"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/markup"
+	"forgejo.org/modules/markup/markdown"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/validation"
+	issue_service "forgejo.org/services/issue"
+
+	ap "github.com/go-ap/activitypub"
+)
+
+// IssueAPID returns the id of the Ticket of an issue
+func IssueAPID(repo *repo_model.Repository, issue *issues_model.Issue) string {
+	return fmt.Sprintf("%s/issues/%d", repo.APActorID(), issue.Index)
+}
+
+// CommentAPID returns the id of the Note of a comment
+func CommentAPID(repo *repo_model.Repository, issue *issues_model.Issue, comment *issues_model.Comment) string {
+	return fmt.Sprintf("%s/comments/%d", IssueAPID(repo, issue), comment.ID)
+}
+
+// IsIssueFederated returns true if the issues of the repository are visible to other instances
+func IsIssueFederated(ctx context.Context, repo *repo_model.Repository, issue *issues_model.Issue) bool {
+	if repo.IsPrivate || issue.IsPull || !repo.UnitEnabled(ctx, unit.TypeIssues) {
+		return false
+	}
+	if err := repo.LoadOwner(ctx); err != nil {
+		return false
+	}
+	return repo.Owner.Visibility.IsPublic()
+}
+
+// NewIssueTicket converts an issue into a ForgeFed Ticket
+func NewIssueTicket(ctx context.Context, repo *repo_model.Repository, issue *issues_model.Issue) (*fm.Ticket, error) {
+	if err := issue.LoadPoster(ctx); err != nil {
+		return nil, err
+	}
+	content, err := renderContent(ctx, repo, issue.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket := fm.TicketNew(ap.IRI(IssueAPID(repo, issue)))
+	ticket.AttributedTo = ap.IRI(issue.Poster.APActorID())
+	ticket.Context = ap.IRI(repo.APActorID())
+	ticket.Name = ap.DefaultNaturalLanguageValue(issue.Title)
+	ticket.MediaType = "text/html"
+	ticket.Content = ap.DefaultNaturalLanguageValue(content)
+	ticket.URL = ap.IRI(issue.HTMLURL())
+	ticket.Published = issue.CreatedUnix.AsTime()
+	ticket.Updated = issue.UpdatedUnix.AsTime()
+	ticket.Replies = ap.IRI(IssueAPID(repo, issue) + "/comments")
+	ticket.IsResolved = issue.IsClosed
+	return ticket, nil
+}
+
+// NewCommentNote converts a comment into a Note replying to the Ticket of the issue
+func NewCommentNote(ctx context.Context, repo *repo_model.Repository, issue *issues_model.Issue, comment *issues_model.Comment) (ap.Object, error) {
+	if err := comment.LoadPoster(ctx); err != nil {
+		return ap.Object{}, err
+	}
+	content, err := renderContent(ctx, repo, comment.Content)
+	if err != nil {
+		return ap.Object{}, err
+	}
+
+	return fm.NewForgeNote(
+		CommentAPID(repo, issue, comment),
+		comment.Poster.APActorID(),
+		IssueAPID(repo, issue),
+		comment.HTMLURL(ctx),
+		content,
+		comment.CreatedUnix.AsTime(),
+	), nil
+}
+
+func renderContent(ctx context.Context, repo *repo_model.Repository, content string) (string, error) {
+	rendered, err := markdown.RenderString(&markup.RenderContext{
+		Ctx: ctx,
+		Links: markup.Links{
+			AbsolutePrefix: true,
+			Base:           repo.HTMLURL(),
+		},
+		Metas: repo.ComposeMetas(ctx),
+	}, content)
+	return string(rendered), err
+}
+
+// issueIndexFromTicketID returns the index of the issue a Ticket or a Note of a comment refers to
+func issueIndexFromTicketID(repo *repo_model.Repository, id string) (int64, error) {
+	prefix := repo.APActorID() + "/issues/"
+	if !strings.HasPrefix(id, prefix) {
+		return 0, fmt.Errorf("%q is not an issue of repository %d", id, repo.ID)
+	}
+	index, _, _ := strings.Cut(strings.TrimPrefix(id, prefix), "/")
+	return strconv.ParseInt(index, 10, 64)
+}
+
+// ProcessRepositoryInbox handles an activity which was sent to the inbox of a repository.
+// Supported are Like, Create{Note} replying to an issue, Offer{Ticket} proposing a pull request
+// and Push announcing new commits of a mirrored repository.
+// signingHost is the host of the key the request was signed with.
+func ProcessRepositoryInbox(ctx context.Context, repo *repo_model.Repository, signingHost string, body []byte) (int, string, error) {
+	activity := ap.Activity{}
+	if err := activity.UnmarshalJSON(body); err != nil {
+		return http.StatusNotAcceptable, "Invalid activity", err
+	}
+
+	switch activity.Type {
+	case ap.LikeType:
+		like := fm.ForgeLike{Activity: activity}
+		return ProcessLikeActivity(ctx, &like, repo.ID)
+	case ap.CreateType:
+		return ProcessCreateNoteActivity(ctx, repo, signingHost, fm.ForgeCreateNote{Activity: activity})
+	case ap.OfferType:
+		return ProcessOfferTicket(ctx, repo, signingHost, body)
+	case fm.PushType:
+		return ProcessPushActivity(ctx, repo, signingHost, body)
+	}
+	return http.StatusNotAcceptable, "Unsupported activity", fmt.Errorf("activity type %q is not supported", activity.Type)
+}
+
+// ProcessCreateNoteActivity adds the Note of a user of another instance as a comment to an issue
+func ProcessCreateNoteActivity(ctx context.Context, repo *repo_model.Repository, signingHost string, create fm.ForgeCreateNote) (int, string, error) {
+	if valid, err := validation.IsValid(create); !valid {
+		return http.StatusNotAcceptable, "Invalid activity", err
+	}
+	if err := checkActorHost(signingHost, create.Actor.GetID().String()); err != nil {
+		return http.StatusForbidden, "Actor does not match signer", err
+	}
+	note := create.Note()
+
+	index, err := issueIndexFromTicketID(repo, note.InReplyTo.GetID().String())
+	if err != nil {
+		return http.StatusNotAcceptable, "Invalid inReplyTo", err
+	}
+	issue, err := issues_model.GetIssueByIndex(ctx, repo.ID, index)
+	if err != nil {
+		if issues_model.IsErrIssueNotExist(err) {
+			return http.StatusNotFound, "Issue not found", err
+		}
+		return http.StatusInternalServerError, "GetIssueByIndex", err
+	}
+	if !IsIssueFederated(ctx, repo, issue) {
+		return http.StatusNotFound, "Issue not found", errors.New("issue is not federated")
+	}
+	if repo.IsArchived || issue.IsLocked {
+		return http.StatusForbidden, "Commenting not allowed", errors.New("issue is locked or repository is archived")
+	}
+
+	// activities may be delivered more than once
+	fc, err := issues_model.GetFederatedCommentByNoteID(ctx, note.ID.String())
+	if err != nil {
+		return http.StatusInternalServerError, "GetFederatedCommentByNoteID", err
+	}
+	if fc != nil {
+		return 0, "", nil
+	}
+
+	remoteUser, _, err := GetOrCreateFederatedUser(ctx, create.Actor.GetID().String())
+	if err != nil {
+		return http.StatusInternalServerError, "Error getting federated user", err
+	}
+
+	comment, err := issue_service.CreateIssueComment(ctx, remoteUser, repo, issue, note.Content.String(), nil)
+	if err != nil {
+		if errors.Is(err, user_model.ErrBlockedByUser) {
+			return http.StatusForbidden, "Commenting not allowed", err
+		}
+		return http.StatusInternalServerError, "CreateIssueComment", err
+	}
+	if err := issues_model.CreateFederatedComment(ctx, comment, note.ID.String()); err != nil {
+		return http.StatusInternalServerError, "CreateFederatedComment", err
+	}
+	return 0, "", nil
+}
+
+// sendCommentToFederatedCommenters sends a comment to the users of other instances who took part in the discussion.
+// Only comments of local users are sent, the instances of remote users distribute their comments themselves.
+func sendCommentToFederatedCommenters(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, issue *issues_model.Issue, comment *issues_model.Comment) {
+	if !setting.Federation.Enabled || doer.IsRemote() || !IsIssueFederated(ctx, repo, issue) {
+		return
+	}
+
+	commenters, err := issues_model.GetFederatedCommenters(ctx, issue.ID)
+	if err != nil {
+		log.Error("GetFederatedCommenters: %v", err)
+		return
+	}
+	if len(commenters) == 0 {
+		return
+	}
+
+	note, err := NewCommentNote(ctx, repo, issue, comment)
+	if err != nil {
+		log.Error("NewCommentNote: %v", err)
+		return
+	}
+	create, err := fm.NewForgeCreateNote(doer.APActorID(), note)
+	if err != nil {
+		log.Error("NewForgeCreateNote: %v", err)
+		return
+	}
+
+	for _, commenter := range commenters {
+		if err := enqueueActivity(doer, commenter.GetInboxURL(), create); err != nil {
+			log.Error("Unable to enqueue comment %d for %s: %v", comment.ID, commenter.GetInboxURL(), err)
+		}
+	}
+}
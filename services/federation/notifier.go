@@ -0,0 +1,165 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package federation
+
+import (
+	"context"
+
+	activities_model "forgejo.org/models/activities"
+	issues_model "forgejo.org/models/issues"
+	repo_model "forgejo.org/models/repo"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/git"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/repository"
+	"forgejo.org/modules/setting"
+	notify_service "forgejo.org/services/notify"
+)
+
+// userActivityNotifier sends the public actions of users to their federated followers.
+// It relies on the actions being created by the feed notifier, which is registered earlier.
+type userActivityNotifier struct {
+	notify_service.NullNotifier
+}
+
+var _ notify_service.Notifier = &userActivityNotifier{}
+
+func (n *userActivityNotifier) CreateRepository(ctx context.Context, doer, u *user_model.User, repo *repo_model.Repository) {
+	sendUserActivity(ctx, doer, repo, activities_model.ActionCreateRepo)
+}
+
+func (n *userActivityNotifier) NewIssue(ctx context.Context, issue *issues_model.Issue, mentions []*user_model.User) {
+	if err := issue.LoadRepo(ctx); err != nil {
+		log.Error("issue.LoadRepo: %v", err)
+		return
+	}
+	if err := issue.LoadPoster(ctx); err != nil {
+		log.Error("issue.LoadPoster: %v", err)
+		return
+	}
+	sendUserActivity(ctx, issue.Poster, issue.Repo, activities_model.ActionCreateIssue)
+}
+
+func (n *userActivityNotifier) NewPullRequest(ctx context.Context, pr *issues_model.PullRequest, mentions []*user_model.User) {
+	if err := pr.LoadIssue(ctx); err != nil {
+		log.Error("pr.LoadIssue: %v", err)
+		return
+	}
+	if err := pr.Issue.LoadRepo(ctx); err != nil {
+		log.Error("issue.LoadRepo: %v", err)
+		return
+	}
+	if err := pr.Issue.LoadPoster(ctx); err != nil {
+		log.Error("issue.LoadPoster: %v", err)
+		return
+	}
+	sendUserActivity(ctx, pr.Issue.Poster, pr.Issue.Repo, activities_model.ActionCreatePullRequest)
+}
+
+func (n *userActivityNotifier) MergePullRequest(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest) {
+	if err := pr.LoadIssue(ctx); err != nil {
+		log.Error("pr.LoadIssue: %v", err)
+		return
+	}
+	if err := pr.Issue.LoadRepo(ctx); err != nil {
+		log.Error("issue.LoadRepo: %v", err)
+		return
+	}
+	sendUserActivity(ctx, doer, pr.Issue.Repo, activities_model.ActionMergePullRequest)
+	sendPullRequestStatus(ctx, pr, true)
+}
+
+func (n *userActivityNotifier) AutoMergePullRequest(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest) {
+	sendPullRequestStatus(ctx, pr, true)
+}
+
+func (n *userActivityNotifier) IssueChangeStatus(ctx context.Context, doer *user_model.User, commitID string, issue *issues_model.Issue, actionComment *issues_model.Comment, closeOrReopen bool) {
+	if !issue.IsPull || !closeOrReopen {
+		return
+	}
+	if err := issue.LoadPullRequest(ctx); err != nil {
+		log.Error("issue.LoadPullRequest: %v", err)
+		return
+	}
+	if !issue.PullRequest.HasMerged {
+		sendPullRequestStatus(ctx, issue.PullRequest, false)
+	}
+}
+
+func (n *userActivityNotifier) CreateIssueComment(ctx context.Context, doer *user_model.User, repo *repo_model.Repository,
+	issue *issues_model.Issue, comment *issues_model.Comment, mentions []*user_model.User,
+) {
+	opType := activities_model.ActionCommentIssue
+	if issue.IsPull {
+		opType = activities_model.ActionCommentPull
+	}
+	sendUserActivity(ctx, doer, repo, opType)
+	sendCommentToFederatedCommenters(ctx, doer, repo, issue, comment)
+}
+
+func (n *userActivityNotifier) NewRelease(ctx context.Context, rel *repo_model.Release) {
+	if rel.IsDraft {
+		return
+	}
+	if err := rel.LoadAttributes(ctx); err != nil {
+		log.Error("LoadAttributes: %v", err)
+		return
+	}
+	sendUserActivity(ctx, rel.Publisher, rel.Repo, activities_model.ActionPublishRelease)
+}
+
+func (n *userActivityNotifier) PushCommits(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
+	opType := activities_model.ActionCommitRepo
+	if opts.RefFullName.IsTag() {
+		opType = activities_model.ActionPushTag
+	}
+	sendUserActivity(ctx, pusher, repo, opType)
+	sendPushToFollowingRepos(ctx, pusher, repo, opts, commits)
+}
+
+func (n *userActivityNotifier) CreateRef(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, refFullName git.RefName, refID string) {
+	if refFullName.IsTag() {
+		sendUserActivity(ctx, doer, repo, activities_model.ActionPushTag)
+	}
+}
+
+// sendUserActivity sends the latest action of doer to the federated followers of doer
+func sendUserActivity(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, opType activities_model.ActionType) {
+	if !setting.Federation.Enabled || doer == nil || repo.IsPrivate || doer.KeepActivityPrivate || !doer.Visibility.IsPublic() {
+		return
+	}
+
+	followers, err := user_model.GetFederatedFollowers(ctx, doer.ID)
+	if err != nil {
+		log.Error("GetFederatedFollowers: %v", err)
+		return
+	}
+	if len(followers) == 0 {
+		return
+	}
+
+	action, err := activities_model.GetLatestPublicAction(ctx, doer.ID, repo.ID, opType)
+	if err != nil {
+		log.Error("GetLatestPublicAction: %v", err)
+		return
+	}
+	if action == nil {
+		return
+	}
+
+	activity, err := NewUserActivity(ctx, action)
+	if err != nil {
+		log.Error("NewUserActivity: %v", err)
+		return
+	}
+	if activity == nil {
+		return
+	}
+
+	for _, follower := range followers {
+		if err := enqueueActivity(doer, follower.GetInboxURL(), activity); err != nil {
+			log.Error("Unable to enqueue activity of user %d for %s: %v", doer.ID, follower.GetInboxURL(), err)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package federation
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+
+	"forgejo.org/models/forgefed"
+	"forgejo.org/modules/hostmatcher"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/util"
+)
+
+// ErrFederationHostBlocked is returned if federation with a host is not allowed by the host policy
+var ErrFederationHostBlocked = errors.New("federation with the host is blocked")
+
+// ErrActorNotSigner is returned if the actor of an activity is not on the host which signed the request
+var ErrActorNotSigner = errors.New("the actor is not on the host which signed the request")
+
+// HostPolicy is the treatment of activities of a host
+type HostPolicy int
+
+const (
+	HostPolicyAllow      HostPolicy = iota // activities are processed
+	HostPolicyQuarantine                   // activities are held for review by an admin
+	HostPolicyDeny                         // activities are rejected
+)
+
+// matchHostOrParent returns true if the host or one of its parent domains is in the list
+func matchHostOrParent(list *hostmatcher.HostMatchList, host string) bool {
+	if list.MatchHostName(host) {
+		return true
+	}
+	if net.ParseIP(host) != nil {
+		return false
+	}
+	for {
+		_, parent, found := strings.Cut(host, ".")
+		if !found || parent == "" {
+			return false
+		}
+		if list.MatchHostName(parent) {
+			return true
+		}
+		host = parent
+	}
+}
+
+// IsHostDenied returns true if the host policy does not allow federation with host
+func IsHostDenied(ctx context.Context, host string) (bool, error) {
+	host = strings.ToLower(host)
+	denyList, err := forgefed.GetFederationHostMatchList(ctx, forgefed.FederationHostRuleDeny)
+	if err != nil {
+		return false, err
+	}
+	if matchHostOrParent(denyList, host) {
+		return true, nil
+	}
+	if setting.Federation.HostPolicy != setting.FederationHostPolicyAllowlist {
+		return false, nil
+	}
+	allowList, err := forgefed.GetFederationHostMatchList(ctx, forgefed.FederationHostRuleAllow)
+	if err != nil {
+		return false, err
+	}
+	return !matchHostOrParent(allowList, host), nil
+}
+
+// IsURIDenied returns true if the host policy does not allow federation with the host of uri
+func IsURIDenied(ctx context.Context, uri string) (bool, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false, err
+	}
+	return IsHostDenied(ctx, u.Hostname())
+}
+
+// GetHostPolicy returns how activities signed by a host are treated
+func GetHostPolicy(ctx context.Context, host string) (HostPolicy, error) {
+	denied, err := IsHostDenied(ctx, host)
+	if err != nil {
+		return HostPolicyDeny, err
+	}
+	if denied {
+		return HostPolicyDeny, nil
+	}
+
+	federationHost, err := forgefed.FindFederationHostByFqdn(ctx, host)
+	if err != nil {
+		return HostPolicyDeny, err
+	}
+	if federationHost == nil {
+		if setting.Federation.QuarantineNewHosts {
+			return HostPolicyQuarantine, nil
+		}
+		return HostPolicyAllow, nil
+	}
+	if federationHost.Quarantined {
+		return HostPolicyQuarantine, nil
+	}
+	return HostPolicyAllow, nil
+}
+
+// checkActorHost makes sure that the actor of an activity is on the host which signed the request,
+// so that an instance can not act on behalf of the users of another one.
+// The signing host is only empty for unsigned requests, which are accepted if signatures are not enforced.
+func checkActorHost(signingHost, actorID string) error {
+	if signingHost == "" {
+		return nil
+	}
+	actorURL, err := url.Parse(actorID)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(actorURL.Hostname(), signingHost) {
+		return fmt.Errorf("%w: %s is not on %s", ErrActorNotSigner, actorID, signingHost)
+	}
+	return nil
+}
+
+// HoldActivity stores an activity of a quarantined host for review by an admin
+func HoldActivity(ctx context.Context, host string, targetType forgefed.QuarantinedActivityTargetType, targetID int64, payload []byte) error {
+	return forgefed.CreateQuarantinedActivity(ctx, &forgefed.QuarantinedActivity{
+		Host:       strings.ToLower(host),
+		TargetType: targetType,
+		TargetID:   targetID,
+		Payload:    string(payload),
+	})
+}
+
+// ParseHostBlocklist reads the hosts of a blocklist file.
+// Supported are plain lists with one host per line and CSV exports whose first column is the domain,
+// like the domain blocklists of Mastodon. Empty lines, comments, headers and entries with severity noop are skipped.
+func ParseHostBlocklist(r io.Reader) ([]string, error) {
+	hosts := make([]string, 0, 10)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		host := forgefed.NormalizeFederationHostPattern(fields[0])
+		if host == "" || host == "domain" {
+			continue
+		}
+		if len(fields) > 1 && strings.EqualFold(strings.TrimSpace(fields[1]), "noop") {
+			continue
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, scanner.Err()
+}
+
+// ImportHostBlocklist adds the hosts of a blocklist file as deny rules and returns the number of added rules
+func ImportHostBlocklist(ctx context.Context, r io.Reader, comment string) (int, error) {
+	hosts, err := ParseHostBlocklist(r)
+	if err != nil {
+		return 0, err
+	}
+	added := 0
+	for _, host := range hosts {
+		err := forgefed.CreateFederationHostRule(ctx, &forgefed.FederationHostRule{
+			Pattern: host,
+			Type:    forgefed.FederationHostRuleDeny,
+			Comment: comment,
+		})
+		if err != nil {
+			if errors.Is(err, util.ErrAlreadyExist) {
+				continue
+			}
+			return added, err
+		}
+		added++
+	}
+	return added, nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package federation
+
+import (
+	"strings"
+	"testing"
+
+	"forgejo.org/modules/hostmatcher"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchHostOrParent(t *testing.T) {
+	list := hostmatcher.ParseHostMatchList("test", "example.com,*.example.org,10.0.0.0/8")
+
+	assert.True(t, matchHostOrParent(list, "example.com"))
+	assert.True(t, matchHostOrParent(list, "social.example.com"))
+	assert.True(t, matchHostOrParent(list, "a.b.example.org"))
+	assert.True(t, matchHostOrParent(list, "10.1.2.3"))
+	assert.False(t, matchHostOrParent(list, "example.net"))
+	assert.False(t, matchHostOrParent(list, "notexample.com"))
+	assert.False(t, matchHostOrParent(list, "11.1.2.3"))
+}
+
+func TestCheckActorHost(t *testing.T) {
+	require.NoError(t, checkActorHost("", "https://example.com/api/v1/activitypub/user-id/1"))
+	require.NoError(t, checkActorHost("example.com", "https://example.com/api/v1/activitypub/user-id/1"))
+	require.NoError(t, checkActorHost("Example.com", "https://example.com:3000/api/v1/activitypub/user-id/1"))
+	require.ErrorIs(t, checkActorHost("example.com", "https://example.org/api/v1/activitypub/user-id/1"), ErrActorNotSigner)
+	require.ErrorIs(t, checkActorHost("example.com", "https://social.example.com/users/1"), ErrActorNotSigner)
+}
+
+func TestParseHostBlocklist(t *testing.T) {
+	t.Run("Plain", func(t *testing.T) {
+		hosts, err := ParseHostBlocklist(strings.NewReader("# comment\nspam.example.com\n\n  Evil.Example.org  \n"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"spam.example.com", "evil.example.org"}, hosts)
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		csv := "#domain,#severity,#reject_media\nspam.example.com,suspend,false\nnoisy.example.com,noop,false\nevil.example.org,silence,true\n"
+		hosts, err := ParseHostBlocklist(strings.NewReader(csv))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"spam.example.com", "evil.example.org"}, hosts)
+	})
+}
@@ -0,0 +1,427 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package federation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	git_model "forgejo.org/models/git"
+	issues_model "forgejo.org/models/issues"
+	repo_model "forgejo.org/models/repo"
+	"forgejo.org/models/unit"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/activitypub"
+	fm "forgejo.org/modules/forgefed"
+	"forgejo.org/modules/git"
+	"forgejo.org/modules/gitrepo"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/validation"
+	pull_service "forgejo.org/services/pull"
+
+	ap "github.com/go-ap/activitypub"
+	"github.com/google/uuid"
+)
+
+// federatedRefPrefix is the prefix of the hidden refs remote branches are fetched into
+const federatedRefPrefix = "refs/federated/"
+
+// ErrInvalidPullRequestOffer is returned if a pull request can not be offered to a remote repository
+var ErrInvalidPullRequestOffer = errors.New("invalid pull request offer")
+
+// cloneURLCheck checks the clone URI of the origin of an offered pull request like the address of a migration.
+// The migrations service depends on this package, so it is set with SetCloneURLCheck.
+var cloneURLCheck func(cloneURL string, doer *user_model.User) error
+
+// SetCloneURLCheck sets the check which has to allow the clone URI of an origin repository before a branch is fetched
+func SetCloneURLCheck(check func(cloneURL string, doer *user_model.User) error) {
+	cloneURLCheck = check
+}
+
+// IsPullRequestFederated returns true if pull requests of the repository can be offered by other instances
+func IsPullRequestFederated(ctx context.Context, repo *repo_model.Repository) bool {
+	if repo.IsPrivate || repo.IsArchived || repo.IsMirror || !repo.UnitEnabled(ctx, unit.TypePullRequests) {
+		return false
+	}
+	if err := repo.LoadOwner(ctx); err != nil {
+		return false
+	}
+	return repo.Owner.Visibility.IsPublic()
+}
+
+// fetchRepositoryActor returns the Repository actor of another instance
+func fetchRepositoryActor(ctx context.Context, repoURI string) (*fm.Repository, error) {
+	serverActor := user_model.NewAPServerActor()
+	clientFactory, err := activitypub.GetClientFactory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	apClient, err := clientFactory.WithKeys(ctx, serverActor, serverActor.APActorKeyID())
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := apClient.GetBody(repoURI)
+	if err != nil {
+		return nil, err
+	}
+	repo := fm.RepositoryNew("")
+	if err := repo.UnmarshalJSON(body); err != nil {
+		return nil, err
+	}
+	if repo.Type != fm.RepositoryType || repo.ID.String() != repoURI {
+		return nil, fmt.Errorf("%s is not a repository", repoURI)
+	}
+	return repo, nil
+}
+
+// repositoryInboxURL returns the inbox of a Repository actor
+func repositoryInboxURL(repo *fm.Repository) string {
+	if repo.Inbox != nil {
+		return repo.Inbox.GetID().String()
+	}
+	return repo.ID.String() + "/inbox"
+}
+
+func isSameHost(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(ua.Host, ub.Host)
+}
+
+// fetchFederatedBranch fetches a branch of a remote repository into a hidden ref of repo.
+// The returned function removes the hidden ref again.
+func fetchFederatedBranch(ctx context.Context, repo *repo_model.Repository, cloneURI, ref string) (string, func(), error) {
+	hiddenRef := federatedRefPrefix + uuid.New().String()
+	remove := func() {
+		if _, _, err := git.NewCommand(ctx, "update-ref", "-d").AddDynamicArguments(hiddenRef).RunStdString(&git.RunOpts{Dir: repo.RepoPath()}); err != nil {
+			log.Error("Unable to remove %s from %s: %v", hiddenRef, repo.FullName(), err)
+		}
+	}
+
+	_, _, err := git.NewCommand(ctx, "fetch", "--no-tags", "--no-recurse-submodules").
+		AddDynamicArguments(cloneURI, "+"+ref+":"+hiddenRef).
+		RunStdString(&git.RunOpts{
+			Dir:     repo.RepoPath(),
+			Timeout: time.Duration(setting.Git.Timeout.Pull) * time.Second,
+		})
+	if err != nil {
+		return "", nil, fmt.Errorf("fetch %s from %s: %w", ref, cloneURI, err)
+	}
+
+	gitRepo, openErr := gitrepo.OpenRepository(ctx, repo)
+	if openErr != nil {
+		remove()
+		return "", nil, openErr
+	}
+	defer gitRepo.Close()
+
+	commitID, refErr := gitRepo.GetRefCommitID(hiddenRef)
+	if refErr != nil {
+		remove()
+		return "", nil, refErr
+	}
+	return commitID, remove, nil
+}
+
+// ProcessOfferTicket creates a pull request from an Offer{Ticket} of a user of another instance.
+// The offered branch is fetched from the origin repository and the result is reported back with Accept{Offer}.
+// signingHost is the host of the key the request was signed with.
+func ProcessOfferTicket(ctx context.Context, repo *repo_model.Repository, signingHost string, body []byte) (int, string, error) {
+	offer := fm.ForgeOfferTicket{}
+	if err := offer.UnmarshalJSON(body); err != nil {
+		return http.StatusNotAcceptable, "Invalid activity", err
+	}
+	if valid, err := validation.IsValid(offer); !valid {
+		return http.StatusNotAcceptable, "Invalid activity", err
+	}
+	ticket := offer.Ticket
+	actorURI := offer.Actor.GetID().String()
+	originURI := ticket.Origin.Context.String()
+
+	if ticket.Target.Context.String() != repo.APActorID() {
+		return http.StatusNotAcceptable, "Invalid target", fmt.Errorf("offer is not for repository %d", repo.ID)
+	}
+	if err := checkActorHost(signingHost, actorURI); err != nil {
+		return http.StatusForbidden, "Actor does not match signer", err
+	}
+	if !isSameHost(actorURI, originURI) {
+		return http.StatusNotAcceptable, "Invalid origin", errors.New("origin repository and actor are on different instances")
+	}
+	if !IsPullRequestFederated(ctx, repo) {
+		return http.StatusForbidden, "Pull requests not allowed", errors.New("repository does not accept federated pull requests")
+	}
+
+	baseBranch := git.RefName(ticket.Target.Ref).BranchName()
+	if _, err := git_model.GetBranch(ctx, repo.ID, baseBranch); err != nil {
+		if git_model.IsErrBranchNotExist(err) {
+			return http.StatusNotFound, "Target branch not found", err
+		}
+		return http.StatusInternalServerError, "GetBranch", err
+	}
+
+	// activities may be delivered more than once
+	fpr, err := issues_model.GetFederatedPullRequestByOfferID(ctx, offer.ID.String())
+	if err != nil {
+		return http.StatusInternalServerError, "GetFederatedPullRequestByOfferID", err
+	}
+	if fpr != nil {
+		return 0, "", nil
+	}
+
+	remoteUser, federatedUser, err := GetOrCreateFederatedUser(ctx, actorURI)
+	if err != nil {
+		return http.StatusInternalServerError, "Error getting federated user", err
+	}
+
+	headBranch := strings.ToLower(remoteUser.Name) + "/" + git.RefName(ticket.Origin.Ref).BranchName()
+	if _, err := issues_model.GetUnmergedPullRequest(ctx, repo.ID, repo.ID, headBranch, baseBranch, issues_model.PullRequestFlowAGit); err == nil {
+		return http.StatusConflict, "Pull request already exists", fmt.Errorf("pull request for %s already exists", headBranch)
+	} else if !issues_model.IsErrPullRequestNotExist(err) {
+		return http.StatusInternalServerError, "GetUnmergedPullRequest", err
+	}
+
+	originRepo, err := fetchRepositoryActor(ctx, originURI)
+	if err != nil {
+		return http.StatusNotAcceptable, "Invalid origin", err
+	}
+	cloneURI := originRepo.CloneURI.String()
+	if !validation.IsValidURL(cloneURI) {
+		return http.StatusNotAcceptable, "Invalid origin", fmt.Errorf("origin repository has no valid clone URI: %q", cloneURI)
+	}
+	// the branch is fetched from the instance of the origin repository only, like a migration
+	if !isSameHost(cloneURI, originURI) {
+		return http.StatusNotAcceptable, "Invalid origin", fmt.Errorf("clone URI %q is not on the instance of the origin repository", cloneURI)
+	}
+	if cloneURLCheck == nil {
+		return http.StatusInternalServerError, "Clone URI check", errors.New("the clone URI check is not set")
+	}
+	if err := cloneURLCheck(cloneURI, remoteUser); err != nil {
+		return http.StatusForbidden, "Clone URI not allowed", err
+	}
+
+	commitID, removeRef, err := fetchFederatedBranch(ctx, repo, cloneURI, ticket.Origin.Ref)
+	if err != nil {
+		return http.StatusNotAcceptable, "Unable to fetch origin branch", err
+	}
+	defer removeRef()
+
+	issue := &issues_model.Issue{
+		RepoID:   repo.ID,
+		Repo:     repo,
+		Title:    ticket.Name.String(),
+		PosterID: remoteUser.ID,
+		Poster:   remoteUser,
+		IsPull:   true,
+		Content:  ticket.Content.String(),
+	}
+	pr := &issues_model.PullRequest{
+		HeadRepoID:   repo.ID,
+		BaseRepoID:   repo.ID,
+		HeadBranch:   headBranch,
+		HeadCommitID: commitID,
+		BaseBranch:   baseBranch,
+		HeadRepo:     repo,
+		BaseRepo:     repo,
+		Type:         issues_model.PullRequestGitea,
+		Flow:         issues_model.PullRequestFlowAGit,
+	}
+	if err := pull_service.NewPullRequest(ctx, repo, issue, nil, nil, pr, nil); err != nil {
+		if errors.Is(err, user_model.ErrBlockedByUser) {
+			return http.StatusForbidden, "Pull requests not allowed", err
+		}
+		return http.StatusInternalServerError, "NewPullRequest", err
+	}
+
+	if err := issues_model.CreateFederatedPullRequest(ctx, &issues_model.FederatedPullRequest{
+		PullID:     pr.ID,
+		IssueID:    issue.ID,
+		OfferID:    offer.ID.String(),
+		OriginRepo: originURI,
+		OriginRef:  ticket.Origin.Ref,
+	}); err != nil {
+		return http.StatusInternalServerError, "CreateFederatedPullRequest", err
+	}
+
+	accept, err := fm.NewForgeAcceptOffer(repo.APActorID(), offer.ID.String(), IssueAPID(repo, issue))
+	if err != nil {
+		return http.StatusInternalServerError, "Error creating accept", err
+	}
+	if err := enqueueActivity(user_model.NewAPServerActor(), federatedUser.GetInboxURL(), accept); err != nil {
+		return http.StatusInternalServerError, "Error sending accept", err
+	}
+	return 0, "", nil
+}
+
+// sendPullRequestStatus reports the merge or the closing of a pull request to the instance it was offered from
+func sendPullRequestStatus(ctx context.Context, pr *issues_model.PullRequest, merged bool) {
+	if !setting.Federation.Enabled {
+		return
+	}
+
+	fpr, err := issues_model.GetFederatedPullRequestByPullID(ctx, pr.ID)
+	if err != nil {
+		log.Error("GetFederatedPullRequestByPullID: %v", err)
+		return
+	}
+	if fpr == nil {
+		return
+	}
+
+	if err := pr.LoadIssue(ctx); err != nil {
+		log.Error("pr.LoadIssue: %v", err)
+		return
+	}
+	if err := pr.Issue.LoadRepo(ctx); err != nil {
+		log.Error("issue.LoadRepo: %v", err)
+		return
+	}
+	federatedUser, err := user_model.GetFederatedUserByUserID(ctx, pr.Issue.PosterID)
+	if err != nil {
+		log.Error("GetFederatedUserByUserID: %v", err)
+		return
+	}
+
+	repo := pr.Issue.Repo
+	var activity activityMarshaler
+	if merged {
+		activity, err = fm.NewForgeResolve(repo.APActorID(), IssueAPID(repo, pr.Issue))
+	} else {
+		activity, err = fm.NewForgeReject(repo.APActorID(), fpr.OfferID)
+	}
+	if err != nil {
+		log.Error("Unable to create status of pull request %d: %v", pr.ID, err)
+		return
+	}
+	if err := enqueueActivity(user_model.NewAPServerActor(), federatedUser.GetInboxURL(), activity); err != nil {
+		log.Error("Unable to enqueue status of pull request %d: %v", pr.ID, err)
+	}
+}
+
+// OfferPullRequestOptions are the options to offer a branch as pull request to a repository of another instance
+type OfferPullRequestOptions struct {
+	HeadBranch   string
+	TargetRepo   string // id of the remote repository actor
+	TargetBranch string
+	Title        string
+	Content      string
+}
+
+// OfferPullRequest sends an Offer{Ticket} for a branch of repo to a repository of another instance
+func OfferPullRequest(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, opts OfferPullRequestOptions) (*issues_model.PullRequestOffer, error) {
+	if !setting.Federation.Enabled {
+		return nil, fmt.Errorf("%w: federation is disabled", ErrInvalidPullRequestOffer)
+	}
+	if repo.IsPrivate {
+		return nil, fmt.Errorf("%w: private repositories can not be fetched by other instances", ErrInvalidPullRequestOffer)
+	}
+	if !validation.IsValidURL(opts.TargetRepo) || isSameHost(opts.TargetRepo, setting.AppURL) {
+		return nil, fmt.Errorf("%w: %q is not a repository of another instance", ErrInvalidPullRequestOffer, opts.TargetRepo)
+	}
+	if _, err := git_model.GetBranch(ctx, repo.ID, opts.HeadBranch); err != nil {
+		if git_model.IsErrBranchNotExist(err) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPullRequestOffer, err)
+		}
+		return nil, err
+	}
+
+	targetRepo, err := fetchRepositoryActor(ctx, opts.TargetRepo)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPullRequestOffer, err)
+	}
+
+	ticket := fm.TicketNew("")
+	ticket.AttributedTo = ap.IRI(doer.APActorID())
+	ticket.Name = ap.DefaultNaturalLanguageValue(opts.Title)
+	ticket.Content = ap.DefaultNaturalLanguageValue(opts.Content)
+	ticket.Origin = &fm.Branch{Context: ap.IRI(repo.APActorID()), Ref: git.BranchPrefix + opts.HeadBranch}
+	ticket.Target = &fm.Branch{Context: ap.IRI(opts.TargetRepo), Ref: git.BranchPrefix + opts.TargetBranch}
+
+	activity, err := fm.NewForgeOfferTicket(doer.APActorID(), ticket)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPullRequestOffer, err)
+	}
+
+	offer := &issues_model.PullRequestOffer{
+		RepoID:       repo.ID,
+		DoerID:       doer.ID,
+		HeadBranch:   opts.HeadBranch,
+		TargetRepo:   opts.TargetRepo,
+		TargetBranch: opts.TargetBranch,
+		Title:        opts.Title,
+		OfferID:      activity.ID.String(),
+		Status:       issues_model.PullRequestOfferStatusPending,
+	}
+	if err := issues_model.CreatePullRequestOffer(ctx, offer); err != nil {
+		return nil, err
+	}
+	if err := enqueueActivity(doer, repositoryInboxURL(targetRepo), activity); err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
+
+// processPullRequestOfferStatus handles Accept{Offer}, Reject{Offer} and Resolve{Ticket} of a repository a local user offered a pull request to
+func processPullRequestOfferStatus(ctx context.Context, localUser *user_model.User, activity ap.Activity, offer *issues_model.PullRequestOffer) (int, string, error) {
+	if offer.DoerID != localUser.ID {
+		return http.StatusNotAcceptable, "Invalid object", errors.New("offer was not made by the owner of the inbox")
+	}
+	if activity.Actor.GetID().String() != offer.TargetRepo {
+		return http.StatusNotAcceptable, "Invalid actor", errors.New("actor is not the repository the offer was made to")
+	}
+
+	switch activity.Type {
+	case ap.AcceptType:
+		accept := fm.ForgeAccept{Activity: activity}
+		if valid, err := validation.IsValid(accept); !valid {
+			return http.StatusNotAcceptable, "Invalid accept", err
+		}
+		if activity.Result == nil {
+			return http.StatusNotAcceptable, "Invalid accept", errors.New("result is missing")
+		}
+		offer.TicketID = activity.Result.GetID().String()
+		offer.Status = issues_model.PullRequestOfferStatusAccepted
+	case ap.RejectType:
+		reject := fm.ForgeReject{Activity: activity}
+		if valid, err := validation.IsValid(reject); !valid {
+			return http.StatusNotAcceptable, "Invalid reject", err
+		}
+		offer.Status = issues_model.PullRequestOfferStatusClosed
+	case fm.ResolveType:
+		resolve := fm.ForgeResolve{Activity: activity}
+		if valid, err := validation.IsValid(resolve); !valid {
+			return http.StatusNotAcceptable, "Invalid resolve", err
+		}
+		offer.Status = issues_model.PullRequestOfferStatusMerged
+	}
+
+	if err := issues_model.UpdatePullRequestOffer(ctx, offer); err != nil {
+		return http.StatusInternalServerError, "UpdatePullRequestOffer", err
+	}
+	return 0, "", nil
+}
+
+// findPullRequestOffer returns the offer an Accept, Reject or Resolve refers to or nil if it does not refer to one
+func findPullRequestOffer(ctx context.Context, activity ap.Activity) (*issues_model.PullRequestOffer, error) {
+	if activity.Object == nil {
+		return nil, nil
+	}
+	id := activity.Object.GetID().String()
+	if activity.Type == fm.ResolveType {
+		return issues_model.GetPullRequestOfferByTicketID(ctx, id)
+	}
+	return issues_model.GetPullRequestOfferByOfferID(ctx, id)
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package federation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	repo_model "forgejo.org/models/repo"
+	user_model "forgejo.org/models/user"
+	fm "forgejo.org/modules/forgefed"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/repository"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/validation"
+	mirror_service "forgejo.org/services/mirror"
+)
+
+// sendPushToFollowingRepos announces a push to the repositories of other instances following the repository,
+// so that their mirrors can be synchronized right away
+func sendPushToFollowingRepos(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
+	if !setting.Federation.Enabled || repo.IsPrivate {
+		return
+	}
+
+	followingRepos, err := repo_model.FindFollowingReposByRepoID(ctx, repo.ID)
+	if err != nil {
+		log.Error("FindFollowingReposByRepoID: %v", err)
+		return
+	}
+	if len(followingRepos) == 0 {
+		return
+	}
+
+	// the key of a user who is not public can not be fetched by other instances
+	if pusher == nil || pusher.KeepActivityPrivate || !pusher.Visibility.IsPublic() {
+		pusher = user_model.NewAPServerActor()
+	}
+
+	commitIDs := make([]string, 0, 10)
+	if commits != nil {
+		for i := len(commits.Commits) - 1; i >= 0; i-- {
+			commitIDs = append(commitIDs, repo.HTMLURL()+"/commit/"+url.PathEscape(commits.Commits[i].Sha1))
+		}
+	}
+	push, err := fm.NewForgePush(pusher.APActorID(), repo.APActorID(), opts.RefFullName.String(), opts.OldCommitID, opts.NewCommitID, commitIDs)
+	if err != nil {
+		log.Error("NewForgePush: %v", err)
+		return
+	}
+
+	for _, followingRepo := range followingRepos {
+		if err := enqueueActivity(pusher, followingRepo.URI+"/inbox", push); err != nil {
+			log.Error("Unable to queue push of repository %d for %s: %v", repo.ID, followingRepo.URI, err)
+		}
+	}
+}
+
+// ProcessPushActivity synchronizes a mirror when a repository it follows announces a push
+func ProcessPushActivity(ctx context.Context, repo *repo_model.Repository, signingHost string, body []byte) (int, string, error) {
+	push := fm.ForgePush{}
+	if err := push.UnmarshalJSON(body); err != nil {
+		return http.StatusNotAcceptable, "Invalid activity", err
+	}
+	if valid, err := validation.IsValid(push); !valid {
+		return http.StatusNotAcceptable, "Invalid activity", err
+	}
+	if err := checkActorHost(signingHost, push.Actor.GetID().String()); err != nil {
+		return http.StatusForbidden, "Actor does not match signer", err
+	}
+
+	pushedRepo := push.Context.GetID().String()
+	actorURI, err := url.Parse(push.Actor.GetID().String())
+	if err != nil {
+		return http.StatusNotAcceptable, "Invalid actor", err
+	}
+	pushedRepoURI, err := url.Parse(pushedRepo)
+	if err != nil {
+		return http.StatusNotAcceptable, "Invalid context", err
+	}
+	if !strings.EqualFold(actorURI.Host, pushedRepoURI.Host) {
+		return http.StatusNotAcceptable, "Invalid context", errors.New("the repository is not on the instance of the actor")
+	}
+
+	if !repo.IsMirror {
+		return http.StatusNotAcceptable, "Repository is not a mirror", errors.New("repository is not a mirror")
+	}
+
+	followingRepos, err := repo_model.FindFollowingReposByRepoID(ctx, repo.ID)
+	if err != nil {
+		return http.StatusInternalServerError, "FindFollowingReposByRepoID", err
+	}
+	for _, followingRepo := range followingRepos {
+		if strings.TrimSuffix(followingRepo.URI, "/") == pushedRepo {
+			log.Trace("Push to %s of %s announced, syncing mirror %d", push.Branch.Ref, pushedRepo, repo.ID)
+			mirror_service.AddPullMirrorToQueue(repo.ID)
+			return 0, "", nil
+		}
+	}
+	return http.StatusNotAcceptable, "Repository is not followed", errors.New("the pushed repository is not followed by the mirror")
+}
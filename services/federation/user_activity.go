@@ -0,0 +1,96 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package federation
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+
+	activities_model "forgejo.org/models/activities"
+	"forgejo.org/models/db"
+	user_model "forgejo.org/models/user"
+	fm "forgejo.org/modules/forgefed"
+	"forgejo.org/modules/translation"
+)
+
+// userActivityContent renders an action the way it is shown in the dashboard feed, with absolute links.
+// The returned content is empty for actions which are not federated.
+func userActivityContent(ctx context.Context, action *activities_model.Action) string {
+	locale := translation.NewLocale("en-US")
+	repoLink := action.GetRepoAbsoluteLink(ctx)
+	repoPath := action.ShortRepoPath(ctx)
+	issueLink := func(kind string) (string, string) {
+		index := action.GetIssueInfos()[0]
+		return fmt.Sprintf("%s/%s/%s", repoLink, kind, index), index
+	}
+
+	var content template.HTML
+	switch action.OpType {
+	case activities_model.ActionCreateRepo:
+		content = locale.Tr("action.create_repo", repoLink, repoPath)
+	case activities_model.ActionCommitRepo:
+		content = locale.Tr("action.commit_repo", repoLink, fmt.Sprintf("%s/src/branch/%s", repoLink, action.GetBranch()), action.GetBranch(), repoPath)
+	case activities_model.ActionPushTag:
+		content = locale.Tr("action.push_tag", repoLink, fmt.Sprintf("%s/src/tag/%s", repoLink, action.GetTag()), action.GetTag(), repoPath)
+	case activities_model.ActionCreateIssue:
+		link, index := issueLink("issues")
+		content = locale.Tr("action.create_issue", link, index, repoPath)
+	case activities_model.ActionCreatePullRequest:
+		link, index := issueLink("pulls")
+		content = locale.Tr("action.create_pull_request", link, index, repoPath)
+	case activities_model.ActionCommentIssue:
+		link, index := issueLink("issues")
+		content = locale.Tr("action.comment_issue", link, index, repoPath)
+	case activities_model.ActionCommentPull:
+		link, index := issueLink("pulls")
+		content = locale.Tr("action.comment_pull", link, index, repoPath)
+	case activities_model.ActionMergePullRequest:
+		link, index := issueLink("pulls")
+		content = locale.Tr("action.merge_pull_request", link, index, repoPath)
+	case activities_model.ActionPublishRelease:
+		content = locale.Tr("action.publish_release", repoLink, fmt.Sprintf("%s/releases/tag/%s", repoLink, action.GetTag()), repoPath, action.Content)
+	}
+	return string(content)
+}
+
+// NewUserActivity converts a public action into a Create{Note} activity of its performer
+func NewUserActivity(ctx context.Context, action *activities_model.Action) (*fm.ForgeUserActivity, error) {
+	content := userActivityContent(ctx, action)
+	if content == "" {
+		return nil, nil
+	}
+	action.LoadActUser(ctx)
+	activity, err := fm.NewForgeUserActivity(action.ActUser, action.ID, content)
+	if err != nil {
+		return nil, err
+	}
+	activity.Published = action.GetCreate()
+	return &activity, nil
+}
+
+// GetUserActivities returns the public activities of a user for the outbox
+func GetUserActivities(ctx context.Context, user *user_model.User, opts db.ListOptions) ([]*fm.ForgeUserActivity, int64, error) {
+	actions, count, err := activities_model.GetFeeds(ctx, activities_model.GetFeedsOptions{
+		ListOptions:     opts,
+		RequestedUser:   user,
+		OnlyPerformedBy: true,
+		IncludePrivate:  false,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	activities := make([]*fm.ForgeUserActivity, 0, len(actions))
+	for _, action := range actions {
+		activity, err := NewUserActivity(ctx, action)
+		if err != nil {
+			return nil, 0, err
+		}
+		if activity != nil {
+			activities = append(activities, activity)
+		}
+	}
+	return activities, count, nil
+}
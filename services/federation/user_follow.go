@@ -0,0 +1,244 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package federation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	activities_model "forgejo.org/models/activities"
+	"forgejo.org/models/user"
+	fm "forgejo.org/modules/forgefed"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/validation"
+
+	ap "github.com/go-ap/activitypub"
+)
+
+// ErrNotFederatedUser is returned if a remote operation is requested for a user which does not belong to another instance
+var ErrNotFederatedUser = errors.New("user is not a federated user")
+
+// GetOrCreateFederatedUser returns the local representation of a remote actor, creating it if necessary
+func GetOrCreateFederatedUser(ctx context.Context, actorURI string) (*user.User, *user.FederatedUser, error) {
+	federationHost, err := GetFederationHostForURI(ctx, actorURI)
+	if err != nil {
+		return nil, nil, err
+	}
+	actorID, err := fm.NewPersonID(actorURI, string(federationHost.NodeInfo.SoftwareName))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u, federatedUser, err := user.FindFederatedUser(ctx, actorID.ID, federationHost.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if u != nil {
+		return u, federatedUser, nil
+	}
+	return CreateUserFromAP(ctx, actorID, federationHost.ID)
+}
+
+// ProcessPersonInbox handles an activity which was sent to the inbox of a local user.
+// Supported are Follow, Accept{Follow}, Undo{Follow}, Create{Note} and the answers to offered pull requests:
+// Accept{Offer}, Reject{Offer} and Resolve{Ticket}.
+// signingHost is the host of the key the request was signed with.
+func ProcessPersonInbox(ctx context.Context, localUser *user.User, signingHost string, body []byte) (int, string, error) {
+	activity := ap.Activity{}
+	if err := activity.UnmarshalJSON(body); err != nil {
+		return http.StatusBadRequest, "Invalid activity", err
+	}
+	if activity.Actor == nil {
+		return http.StatusNotAcceptable, "Invalid activity", errors.New("actor is missing")
+	}
+	if err := checkActorHost(signingHost, activity.Actor.GetID().String()); err != nil {
+		return http.StatusForbidden, "Actor does not match signer", err
+	}
+
+	switch activity.Type {
+	case ap.AcceptType, ap.RejectType, fm.ResolveType:
+		offer, err := findPullRequestOffer(ctx, activity)
+		if err != nil {
+			return http.StatusInternalServerError, "Error getting pull request offer", err
+		}
+		if offer != nil {
+			return processPullRequestOfferStatus(ctx, localUser, activity, offer)
+		}
+		if activity.Type != ap.AcceptType {
+			return http.StatusNotAcceptable, "Invalid object", errors.New("no matching pull request offer")
+		}
+		return processAccept(ctx, localUser, activity)
+	case ap.FollowType:
+		return processFollow(ctx, localUser, activity)
+	case ap.UndoType:
+		return processUndoFollow(ctx, localUser, activity)
+	case ap.CreateType:
+		return processUserActivity(ctx, localUser, activity)
+	}
+	return http.StatusNotAcceptable, "Unsupported activity", fmt.Errorf("activity type %q is not supported", activity.Type)
+}
+
+func processFollow(ctx context.Context, localUser *user.User, activity ap.Activity) (int, string, error) {
+	follow, err := fm.NewForgeFollowFromAp(activity)
+	if err != nil {
+		return http.StatusNotAcceptable, "Invalid follow", err
+	}
+	if follow.Object.GetID().String() != localUser.APActorID() {
+		return http.StatusNotAcceptable, "Invalid object", errors.New("follow is not for the owner of the inbox")
+	}
+	if !localUser.Visibility.IsPublic() {
+		return http.StatusForbidden, "Follow not allowed", errors.New("only public users can be followed")
+	}
+
+	remoteUser, federatedUser, err := GetOrCreateFederatedUser(ctx, follow.Actor.GetID().String())
+	if err != nil {
+		return http.StatusInternalServerError, "Error getting federated user", err
+	}
+	if err := user.FollowUser(ctx, remoteUser.ID, localUser.ID); err != nil {
+		if errors.Is(err, user.ErrBlockedByUser) {
+			return http.StatusForbidden, "Follow not allowed", err
+		}
+		return http.StatusInternalServerError, "Error following", err
+	}
+
+	accept, err := fm.NewForgeAccept(localUser.APActorID(), follow.Activity)
+	if err != nil {
+		return http.StatusInternalServerError, "Error creating accept", err
+	}
+	if err := enqueueActivity(localUser, federatedUser.GetInboxURL(), accept); err != nil {
+		return http.StatusInternalServerError, "Error sending accept", err
+	}
+	return 0, "", nil
+}
+
+func processAccept(ctx context.Context, localUser *user.User, activity ap.Activity) (int, string, error) {
+	accept := fm.ForgeAccept{Activity: activity}
+	if valid, err := validation.IsValid(accept); !valid {
+		return http.StatusNotAcceptable, "Invalid accept", err
+	}
+
+	remoteUser, _, err := GetOrCreateFederatedUser(ctx, accept.Actor.GetID().String())
+	if err != nil {
+		return http.StatusInternalServerError, "Error getting federated user", err
+	}
+	fr, err := user.GetFollowRequest(ctx, localUser.ID, remoteUser.ID)
+	if err != nil {
+		return http.StatusInternalServerError, "Error getting follow request", err
+	}
+	if fr == nil || fr.ActivityID != accept.Object.GetID().String() {
+		return http.StatusNotAcceptable, "Invalid object", errors.New("no matching follow request")
+	}
+
+	if err := user.AcceptFollowRequest(ctx, fr); err != nil {
+		return http.StatusInternalServerError, "Error accepting follow request", err
+	}
+	return 0, "", nil
+}
+
+func processUndoFollow(ctx context.Context, localUser *user.User, activity ap.Activity) (int, string, error) {
+	undo := fm.ForgeUndoFollow{Activity: activity}
+	if valid, err := validation.IsValid(undo); !valid {
+		return http.StatusNotAcceptable, "Invalid undo", err
+	}
+	follow := undo.Object.(*ap.Activity)
+	if follow.Object.GetID().String() != localUser.APActorID() {
+		return http.StatusNotAcceptable, "Invalid object", errors.New("undo is not for the owner of the inbox")
+	}
+
+	remoteUser, _, err := GetOrCreateFederatedUser(ctx, undo.Actor.GetID().String())
+	if err != nil {
+		return http.StatusInternalServerError, "Error getting federated user", err
+	}
+	if err := user.UnfollowUser(ctx, remoteUser.ID, localUser.ID); err != nil {
+		return http.StatusInternalServerError, "Error unfollowing", err
+	}
+	return 0, "", nil
+}
+
+func processUserActivity(ctx context.Context, localUser *user.User, activity ap.Activity) (int, string, error) {
+	if _, ok := activity.Object.(*ap.Object); !ok {
+		return http.StatusNotAcceptable, "Invalid object", errors.New("object is not a note")
+	}
+	userActivity, err := fm.NewForgeUserActivityFromAp(activity)
+	if err != nil {
+		return http.StatusNotAcceptable, "Invalid activity", err
+	}
+
+	remoteUser, _, err := GetOrCreateFederatedUser(ctx, userActivity.Actor.GetID().String())
+	if err != nil {
+		return http.StatusInternalServerError, "Error getting federated user", err
+	}
+	// activities of users nobody follows are dropped
+	if !user.IsFollowing(ctx, localUser.ID, remoteUser.ID) {
+		log.Info("Dropped activity of %s, %s does not follow them", remoteUser.Name, localUser.Name)
+		return 0, "", nil
+	}
+
+	noteURL := userActivity.Note.URL.GetID().String()
+	if !validation.IsValidURL(noteURL) {
+		return http.StatusNotAcceptable, "Invalid note", fmt.Errorf("invalid note url %q", noteURL)
+	}
+
+	if err := activities_model.CreateFederatedUserActivity(ctx, &activities_model.FederatedUserActivity{
+		UserID:      remoteUser.ID,
+		NoteURL:     noteURL,
+		NoteContent: userActivity.Note.Content.String(),
+	}); err != nil {
+		return http.StatusInternalServerError, "Error storing activity", err
+	}
+	return 0, "", nil
+}
+
+// FollowRemoteUser sends a Follow activity to a federated user.
+// The follow is recorded once the remote instance accepted it.
+func FollowRemoteUser(ctx context.Context, doer, remoteUser *user.User) error {
+	federatedUser, err := user.GetFederatedUserByUserID(ctx, remoteUser.ID)
+	if err != nil {
+		return err
+	}
+	if federatedUser == nil {
+		return ErrNotFederatedUser
+	}
+	if user.IsBlocked(ctx, doer.ID, remoteUser.ID) || user.IsBlocked(ctx, remoteUser.ID, doer.ID) {
+		return user.ErrBlockedByUser
+	}
+	if user.IsFollowing(ctx, doer.ID, remoteUser.ID) {
+		return nil
+	}
+
+	follow, err := fm.NewForgeFollow(doer.APActorID(), federatedUser.NormalizedOriginalURL)
+	if err != nil {
+		return err
+	}
+	if err := user.CreateFollowRequest(ctx, doer.ID, remoteUser.ID, follow.ID.String()); err != nil {
+		return err
+	}
+	return enqueueActivity(doer, federatedUser.GetInboxURL(), follow)
+}
+
+// UnfollowRemoteUser removes the follow of a federated user and sends an Undo{Follow} activity
+func UnfollowRemoteUser(ctx context.Context, doer, remoteUser *user.User) error {
+	federatedUser, err := user.GetFederatedUserByUserID(ctx, remoteUser.ID)
+	if err != nil {
+		return err
+	}
+	if federatedUser == nil {
+		return ErrNotFederatedUser
+	}
+
+	if err := user.UnfollowUser(ctx, doer.ID, remoteUser.ID); err != nil {
+		return err
+	}
+	if err := user.DeleteFollowRequest(ctx, doer.ID, remoteUser.ID); err != nil {
+		return err
+	}
+
+	undo, err := fm.NewForgeUndoFollow(doer.APActorID(), federatedUser.NormalizedOriginalURL)
+	if err != nil {
+		return err
+	}
+	return enqueueActivity(doer, federatedUser.GetInboxURL(), undo)
+}
@@ -4,6 +4,7 @@
 package forms
 
 import (
+	"mime/multipart"
 	"net/http"
 
 	"forgejo.org/modules/structs"
@@ -72,3 +73,28 @@ func (f *AdminDashboardForm) Validate(req *http.Request, errs binding.Errors) bi
 	ctx := context.GetValidateContext(req)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// AdminFederationHostRuleForm form for adding a host to the federation allow or deny list
+type AdminFederationHostRuleForm struct {
+	Pattern string `binding:"Required;MaxSize(255)"`
+	Type    string `binding:"Required;In(allow,deny)"`
+	Comment string
+}
+
+// Validate validates form fields
+func (f *AdminFederationHostRuleForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// AdminFederationBlocklistForm form for importing a federation blocklist file
+type AdminFederationBlocklistForm struct {
+	Blocklist *multipart.FileHeader
+	Comment   string
+}
+
+// Validate validates form fields
+func (f *AdminFederationBlocklistForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
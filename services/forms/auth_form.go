@@ -15,7 +15,7 @@ import (
 // AuthenticationForm form for authentication
 type AuthenticationForm struct {
 	ID                            int64
-	Type                          int    `binding:"Range(2,7)"`
+	Type                          int    `binding:"Range(2,9)"`
 	Name                          string `binding:"Required;MaxSize(30)"`
 	Host                          string
 	Port                          int
@@ -79,6 +79,18 @@ type AuthenticationForm struct {
 	SkipLocalTwoFA                bool
 	GroupTeamMap                  string `binding:"ValidGroupTeamMap"`
 	GroupTeamMapRemoval           bool
+	SAMLIdPMetadata               string `form:"saml_idp_metadata"`
+	SAMLIdPMetadataURL            string `form:"saml_idp_metadata_url"`
+	SAMLNameIDFormat              string `form:"saml_name_id_format"`
+	SAMLAttributeUsername         string `form:"saml_attribute_username"`
+	SAMLAttributeFullName         string `form:"saml_attribute_full_name"`
+	SAMLAttributeEmail            string `form:"saml_attribute_email"`
+	SAMLGroupAttribute            string `form:"saml_group_attribute"`
+	SAMLAdminGroup                string `form:"saml_admin_group"`
+	SAMLRestrictedGroup           string `form:"saml_restricted_group"`
+	SAMLGroupTeamMap              string `form:"saml_group_team_map" binding:"ValidGroupTeamMap"`
+	SAMLGroupTeamMapRemoval       bool   `form:"saml_group_team_map_removal"`
+	SAMLAllowIdPInitiated         bool   `form:"saml_allow_idp_initiated"`
 }
 
 // Validate validates fields
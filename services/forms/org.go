@@ -53,6 +53,18 @@ func (f *UpdateOrgSettingForm) Validate(req *http.Request, errs binding.Errors)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// OrgIPAllowlistEntryForm form for adding a range to the IP allowlist of an organization
+type OrgIPAllowlistEntryForm struct {
+	CIDR    string `form:"cidr" binding:"Required;MaxSize(64)"`
+	Comment string `binding:"MaxSize(255)"`
+}
+
+// Validate validates the fields
+func (f *OrgIPAllowlistEntryForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // ___________
 // \__    ___/___ _____    _____
 //   |    |_/ __ \\__  \  /     \
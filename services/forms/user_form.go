@@ -187,6 +187,9 @@ type AccessTokenForm struct {
 
 	// PKCE support
 	CodeVerifier string `json:"code_verifier"`
+
+	// Device authorization grant support
+	DeviceCode string `json:"device_code"`
 }
 
 // Validate validates the fields
@@ -195,6 +198,31 @@ func (f *AccessTokenForm) Validate(req *http.Request, errs binding.Errors) bindi
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// DeviceAuthorizationForm for requesting the authorization of a device, RFC 8628
+type DeviceAuthorizationForm struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	Scope        string `json:"scope"`
+}
+
+// Validate validates the fields
+func (f *DeviceAuthorizationForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// DeviceVerificationForm for approving or denying the authorization of a device
+type DeviceVerificationForm struct {
+	UserCode string `binding:"Required"`
+	Granted  bool
+}
+
+// Validate validates the fields
+func (f *DeviceVerificationForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // IntrospectTokenForm for introspecting tokens
 type IntrospectTokenForm struct {
 	Token string `json:"token"`
@@ -386,6 +414,22 @@ func (f *NewAccessTokenForm) GetScope() (auth_model.AccessTokenScope, error) {
 	return s, err
 }
 
+// NewFineGrainedAccessTokenForm form for creating fine-grained access tokens
+type NewFineGrainedAccessTokenForm struct {
+	Name         string `binding:"Required;MaxSize(255)" locale:"settings.token_name"`
+	Resource     string `binding:"Required;In(owner,repositories)"`
+	Owner        string
+	Repositories string
+	Permission   []string
+	Expires      string `binding:"Required" locale:"settings.fine_grained_token.expires"`
+}
+
+// Validate validates the fields
+func (f *NewFineGrainedAccessTokenForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // EditOAuth2ApplicationForm form for editing oauth2 applications
 type EditOAuth2ApplicationForm struct {
 	Name               string `binding:"Required;MaxSize(255)" form:"application_name"`
@@ -399,6 +443,33 @@ func (f *EditOAuth2ApplicationForm) Validate(req *http.Request, errs binding.Err
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// EditOAuth2ApplicationInstallableForm form for editing how oauth2 applications are installed
+type EditOAuth2ApplicationInstallableForm struct {
+	Permission    []string
+	PublicKey     string `binding:"MaxSize(4096)" form:"public_key"`
+	WebhookURL    string `binding:"MaxSize(2048)" form:"webhook_url"`
+	WebhookSecret string `binding:"MaxSize(255)" form:"webhook_secret"`
+}
+
+// Validate validates the fields
+func (f *EditOAuth2ApplicationInstallableForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// InstallOAuth2ApplicationForm form for installing oauth2 applications
+type InstallOAuth2ApplicationForm struct {
+	Owner        string `binding:"Required"`
+	Resource     string `binding:"Required;In(owner,repositories)"`
+	Repositories string
+}
+
+// Validate validates the fields
+func (f *InstallOAuth2ApplicationForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // TwoFactorAuthForm for logging in with 2FA token.
 type TwoFactorAuthForm struct {
 	Passcode string `binding:"Required"`
@@ -424,6 +495,8 @@ func (f *TwoFactorScratchAuthForm) Validate(req *http.Request, errs binding.Erro
 // WebauthnRegistrationForm for reserving an WebAuthn name
 type WebauthnRegistrationForm struct {
 	Name string `binding:"Required"`
+	// Passkey registers a discoverable credential to sign in without a password
+	Passkey bool
 }
 
 // Validate validates the fields
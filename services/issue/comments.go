@@ -69,6 +69,8 @@ func CreateIssueComment(ctx context.Context, doer *user_model.User, repo *repo_m
 		return nil, err
 	}
 
+	revokeCommentAccessTokens(ctx, comment)
+
 	notify_service.CreateIssueComment(ctx, doer, repo, issue, comment, mentions)
 
 	return comment, nil
@@ -111,6 +113,7 @@ func UpdateComment(ctx context.Context, c *issues_model.Comment, contentVersion
 	}
 
 	if !isPartOfPendingReview {
+		revokeCommentAccessTokens(ctx, c)
 		notify_service.UpdateComment(ctx, doer, c, oldContent)
 	}
 
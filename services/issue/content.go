@@ -19,6 +19,8 @@ func ChangeContent(ctx context.Context, issue *issues_model.Issue, doer *user_mo
 		return err
 	}
 
+	RevokeIssueAccessTokens(ctx, issue)
+
 	notify_service.IssueChangeContent(ctx, doer, issue, oldContent)
 
 	return nil
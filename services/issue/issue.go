@@ -47,6 +47,8 @@ func NewIssue(ctx context.Context, repo *repo_model.Repository, issue *issues_mo
 		return err
 	}
 
+	RevokeIssueAccessTokens(ctx, issue)
+
 	notify_service.NewIssue(ctx, issue, mentions)
 	if len(issue.Labels) > 0 {
 		notify_service.IssueChangeLabels(ctx, issue.Poster, issue, issue.Labels, nil)
@@ -302,6 +304,8 @@ func deleteIssue(ctx context.Context, issue *issues_model.Issue) error {
 	if err := db.DeleteBeans(ctx,
 		&issues_model.ContentHistory{IssueID: issue.ID},
 		&issues_model.Comment{IssueID: issue.ID},
+		&issues_model.FederatedComment{IssueID: issue.ID},
+		&issues_model.FederatedPullRequest{IssueID: issue.ID},
 		&issues_model.IssueLabel{IssueID: issue.ID},
 		&issues_model.IssueDependency{IssueID: issue.ID},
 		&issues_model.IssueAssignees{IssueID: issue.ID},
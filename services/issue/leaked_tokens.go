@@ -0,0 +1,34 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package issue
+
+import (
+	"context"
+
+	issues_model "forgejo.org/models/issues"
+	"forgejo.org/modules/log"
+	secretscan_service "forgejo.org/services/secretscan"
+)
+
+// RevokeIssueAccessTokens revokes the access tokens found in the content of the issue if it is public
+func RevokeIssueAccessTokens(ctx context.Context, issue *issues_model.Issue) {
+	if err := issue.LoadRepo(ctx); err != nil {
+		log.Error("LoadRepo[%d]: %v", issue.RepoID, err)
+		return
+	}
+	secretscan_service.RevokeAccessTokensInContent(ctx, issue.Repo, issue.Content, issue.HTMLURL())
+}
+
+// revokeCommentAccessTokens revokes the access tokens found in the content of the comment if it is public
+func revokeCommentAccessTokens(ctx context.Context, c *issues_model.Comment) {
+	if err := c.LoadIssue(ctx); err != nil {
+		log.Error("LoadIssue[%d]: %v", c.IssueID, err)
+		return
+	}
+	if err := c.Issue.LoadRepo(ctx); err != nil {
+		log.Error("LoadRepo[%d]: %v", c.Issue.RepoID, err)
+		return
+	}
+	secretscan_service.RevokeAccessTokensInContent(ctx, c.Issue.Repo, c.Content, c.HTMLURL(ctx))
+}
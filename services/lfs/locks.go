@@ -72,6 +72,11 @@ func GetListLockHandler(ctx *context.Context) {
 		})
 		return
 	}
+
+	if !checkOwnerPolicies(ctx, repository) {
+		return
+	}
+
 	ctx.Resp.Header().Set("Content-Type", lfs_module.MediaType)
 
 	cursor := ctx.FormInt("cursor")
@@ -165,6 +170,10 @@ func PostLockHandler(ctx *context.Context) {
 		return
 	}
 
+	if !checkOwnerPolicies(ctx, repository) {
+		return
+	}
+
 	ctx.Resp.Header().Set("Content-Type", lfs_module.MediaType)
 
 	var req api.LFSLockRequest
@@ -237,6 +246,10 @@ func VerifyLockHandler(ctx *context.Context) {
 		return
 	}
 
+	if !checkOwnerPolicies(ctx, repository) {
+		return
+	}
+
 	ctx.Resp.Header().Set("Content-Type", lfs_module.MediaType)
 
 	cursor := ctx.FormInt("cursor")
@@ -308,6 +321,10 @@ func UnLockHandler(ctx *context.Context) {
 		return
 	}
 
+	if !checkOwnerPolicies(ctx, repository) {
+		return
+	}
+
 	ctx.Resp.Header().Set("Content-Type", lfs_module.MediaType)
 
 	var req api.LFSLockDeleteRequest
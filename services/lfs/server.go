@@ -456,6 +456,10 @@ func getAuthenticatedRepository(ctx *context.Context, rc *requestContext, requir
 		return nil
 	}
 
+	if !checkOwnerPolicies(ctx, repository) {
+		return nil
+	}
+
 	if requireWrite {
 		context.CheckRepoScopedToken(ctx, repository, auth_model.Write)
 	} else {
@@ -633,6 +637,35 @@ func parseToken(ctx stdCtx.Context, authorization string, target *repo_model.Rep
 	return nil, errors.New("token not found")
 }
 
+// checkOwnerPolicies writes an error and returns false if the policies of the owner of the repository block the
+// authenticated request, this also covers the tokens handed out to clients over SSH
+func checkOwnerPolicies(ctx *context.Context, repository *repo_model.Repository) bool {
+	if err := repository.LoadOwner(ctx); err != nil {
+		log.Error("Unable to load owner of %-v: %v", repository, err)
+		writeStatus(ctx, http.StatusInternalServerError)
+		return false
+	}
+	blocked, err := context.IsBlockedByIPAllowlist(ctx, repository.Owner, ctx.Doer, ctx.RemoteAddr())
+	if err != nil {
+		log.Error("Unable to check IP allowlist of %s: %v", repository.Owner.Name, err)
+		writeStatus(ctx, http.StatusInternalServerError)
+		return false
+	} else if blocked {
+		writeStatusMessage(ctx, http.StatusForbidden, context.IPAllowlistBlockedMessage)
+		return false
+	}
+	blocked, err = context.IsBlockedByTwoFactorPolicy(ctx, repository.Owner, ctx.Doer)
+	if err != nil {
+		log.Error("Unable to check two-factor authentication policy of %s: %v", repository.Owner.Name, err)
+		writeStatus(ctx, http.StatusInternalServerError)
+		return false
+	} else if blocked {
+		writeStatusMessage(ctx, http.StatusForbidden, context.TwoFactorBlockedMessage)
+		return false
+	}
+	return true
+}
+
 func requireAuth(ctx *context.Context) {
 	ctx.Resp.Header().Set("WWW-Authenticate", "Basic realm=gitea-lfs")
 	writeStatus(ctx, http.StatusUnauthorized)
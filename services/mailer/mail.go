@@ -45,6 +45,7 @@ const (
 	mailAuth2faDisabled        base.TplName = "auth/2fa_disabled"
 	mailAuthRemovedSecurityKey base.TplName = "auth/removed_security_key"
 	mailAuthTOTPEnrolled       base.TplName = "auth/totp_enrolled"
+	mailAuthAccessTokenRevoked base.TplName = "auth/access_token_revoked"
 
 	mailNotifyCollaborator base.TplName = "notify/collaborator"
 
@@ -744,3 +745,33 @@ func SendTOTPEnrolled(ctx context.Context, u *user_model.User) error {
 	SendAsync(msg)
 	return nil
 }
+
+// SendAccessTokenRevoked informs the user that their access token has been revoked because it was found at the location.
+func SendAccessTokenRevoked(u *user_model.User, tokenName, location string) error {
+	if setting.MailService == nil {
+		return nil
+	}
+	locale := translation.NewLocale(u.Language)
+
+	data := map[string]any{
+		"locale":           locale,
+		"TokenName":        tokenName,
+		"Location":         location,
+		"ApplicationsLink": setting.AppURL + "user/settings/applications",
+		"DisplayName":      u.DisplayName(),
+		"Username":         u.Name,
+		"Language":         locale.Language(),
+	}
+
+	var content bytes.Buffer
+
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailAuthAccessTokenRevoked), data); err != nil {
+		return err
+	}
+
+	msg := NewMessage(u.EmailTo(), locale.TrString("mail.access_token_revoked.subject"), content.String())
+	msg.Info = fmt.Sprintf("UID: %d, access token revoked notification", u.ID)
+
+	SendAsync(msg)
+	return nil
+}
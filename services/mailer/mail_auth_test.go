@@ -60,3 +60,23 @@ func TestPrimaryMailChange(t *testing.T) {
 
 	require.NoError(t, user_service.MakeEmailAddressPrimary(db.DefaultContext, user, firstEmail, false))
 }
+
+func TestAccessTokenRevokedMail(t *testing.T) {
+	defer require.NoError(t, unittest.PrepareTestDatabase())
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	location := setting.AppURL + "user2/repo1/issues/1#issuecomment-2"
+	called := false
+	defer mailer.MockMailSettings(func(msgs ...*mailer.Message) {
+		assert.Len(t, msgs, 1)
+		assert.Equal(t, user.EmailTo(), msgs[0].To)
+		assert.EqualValues(t, translation.NewLocale("en-US").Tr("mail.access_token_revoked.subject"), msgs[0].Subject)
+		assert.Contains(t, msgs[0].Body, "Token A")
+		assert.Contains(t, msgs[0].Body, location)
+		mailer.AssertTranslatedLocale(t, msgs[0].Body, "mail.access_token_revoked")
+		called = true
+	})()
+
+	require.NoError(t, mailer.SendAccessTokenRevoked(user, "Token A", location))
+	assert.True(t, called)
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"forgejo.org/models/db"
+	"forgejo.org/models/forgefed"
+	repo_model "forgejo.org/models/repo"
+	"forgejo.org/models/user"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/util"
+	"forgejo.org/services/federation"
+)
+
+// processQuarantinedActivity handles a held activity as if it was just delivered to the inbox
+func processQuarantinedActivity(ctx context.Context, activity *forgefed.QuarantinedActivity) error {
+	var err error
+	switch activity.TargetType {
+	case forgefed.QuarantinedActivityTargetUser:
+		var target *user.User
+		if target, err = user.GetUserByID(ctx, activity.TargetID); err != nil {
+			return err
+		}
+		_, _, err = federation.ProcessPersonInbox(ctx, target, activity.Host, []byte(activity.Payload))
+	case forgefed.QuarantinedActivityTargetRepository:
+		var target *repo_model.Repository
+		if target, err = repo_model.GetRepositoryByID(ctx, activity.TargetID); err != nil {
+			return err
+		}
+		_, _, err = federation.ProcessRepositoryInbox(ctx, target, activity.Host, []byte(activity.Payload))
+	default:
+		err = fmt.Errorf("unknown target type %d", activity.TargetType)
+	}
+	return err
+}
+
+// ApproveQuarantinedActivity processes a held activity and removes it from the moderation queue.
+// The activity is removed even if processing fails, as it would fail again.
+func ApproveQuarantinedActivity(ctx context.Context, id int64) error {
+	activity, err := forgefed.GetQuarantinedActivity(ctx, id)
+	if err != nil {
+		return err
+	}
+	processErr := processQuarantinedActivity(ctx, activity)
+	if err := forgefed.DeleteQuarantinedActivity(ctx, id); err != nil {
+		return err
+	}
+	return processErr
+}
+
+// RejectQuarantinedActivity drops a held activity
+func RejectQuarantinedActivity(ctx context.Context, id int64) error {
+	if _, err := forgefed.GetQuarantinedActivity(ctx, id); err != nil {
+		return err
+	}
+	return forgefed.DeleteQuarantinedActivity(ctx, id)
+}
+
+// ReleaseFederationHost ends the quarantine of a host and processes its held activities
+func ReleaseFederationHost(ctx context.Context, host string) error {
+	federationHost, err := forgefed.FindFederationHostByFqdn(ctx, host)
+	if err != nil {
+		return err
+	}
+	if federationHost != nil {
+		federationHost.Quarantined = false
+		if err := forgefed.UpdateFederationHost(ctx, federationHost); err != nil {
+			return err
+		}
+	}
+
+	activities, _, err := forgefed.FindQuarantinedActivities(ctx, host, db.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, activity := range activities {
+		if err := ApproveQuarantinedActivity(ctx, activity.ID); err != nil {
+			log.Warn("Unable to process held activity %d of %s: %v", activity.ID, host, err)
+		}
+	}
+	return nil
+}
+
+// BlockFederationHost adds a host to the deny list and drops its held activities
+func BlockFederationHost(ctx context.Context, host, comment string) error {
+	err := forgefed.CreateFederationHostRule(ctx, &forgefed.FederationHostRule{
+		Pattern: host,
+		Type:    forgefed.FederationHostRuleDeny,
+		Comment: comment,
+	})
+	if err != nil && !errors.Is(err, util.ErrAlreadyExist) {
+		return err
+	}
+	return forgefed.DeleteQuarantinedActivitiesByHost(ctx, host)
+}
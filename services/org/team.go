@@ -0,0 +1,110 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package org
+
+import (
+	"context"
+
+	"forgejo.org/models"
+	audit_model "forgejo.org/models/audit"
+	org_model "forgejo.org/models/organization"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/setting"
+	audit_service "forgejo.org/services/audit"
+)
+
+// The doer of the changes below is nil if they are made by the system, e.g. to synchronize the groups of an identity provider.
+
+// NewTeam creates the team and records it in the audit log
+func NewTeam(ctx context.Context, doer *user_model.User, t *org_model.Team) error {
+	if err := models.NewTeam(ctx, t); err != nil {
+		return err
+	}
+	audit_service.Record(ctx, doer, audit_model.ActionOrgTeamCreate, audit_service.TeamTarget(t), nil, teamValue(ctx, t.ID))
+	return nil
+}
+
+// UpdateTeam updates the team and records how its name, permissions and units changed in the audit log
+func UpdateTeam(ctx context.Context, doer *user_model.User, t *org_model.Team, authChanged, includeAllChanged bool) error {
+	before := teamValue(ctx, t.ID)
+	if err := models.UpdateTeam(ctx, t, authChanged, includeAllChanged); err != nil {
+		return err
+	}
+	audit_service.Record(ctx, doer, audit_model.ActionOrgTeamUpdate, audit_service.TeamTarget(t), before, teamValue(ctx, t.ID))
+	return nil
+}
+
+// DeleteTeam deletes the team and records it in the audit log
+func DeleteTeam(ctx context.Context, doer *user_model.User, t *org_model.Team) error {
+	before := teamValue(ctx, t.ID)
+	if err := models.DeleteTeam(ctx, t); err != nil {
+		return err
+	}
+	audit_service.Record(ctx, doer, audit_model.ActionOrgTeamDelete, audit_service.TeamTarget(t), before, nil)
+	return nil
+}
+
+// AddTeamMember adds the user to the team and records it in the audit log
+func AddTeamMember(ctx context.Context, doer *user_model.User, t *org_model.Team, userID int64) error {
+	if err := models.AddTeamMember(ctx, t, userID); err != nil {
+		return err
+	}
+	audit_service.Record(ctx, doer, audit_model.ActionOrgTeamMemberAdd, audit_service.TeamTarget(t), nil, memberValue(ctx, userID))
+	return nil
+}
+
+// RemoveTeamMember removes the user from the team and records it in the audit log
+func RemoveTeamMember(ctx context.Context, doer *user_model.User, t *org_model.Team, userID int64) error {
+	if err := models.RemoveTeamMember(ctx, t, userID); err != nil {
+		return err
+	}
+	audit_service.Record(ctx, doer, audit_model.ActionOrgTeamMemberRemove, audit_service.TeamTarget(t), memberValue(ctx, userID), nil)
+	return nil
+}
+
+// RemoveOrgUser removes the user from the organization and all its teams and records it in the audit log
+func RemoveOrgUser(ctx context.Context, doer *user_model.User, org *org_model.Organization, userID int64) error {
+	if err := models.RemoveOrgUser(ctx, org.ID, userID); err != nil {
+		return err
+	}
+	audit_service.Record(ctx, doer, audit_model.ActionOrgMemberRemove, audit_service.UserTarget(org.AsUser()), memberValue(ctx, userID), nil)
+	return nil
+}
+
+// teamValue returns the name and permissions of the team as stored, the units are keyed by their name.
+// It is only loaded if audit events are enabled.
+func teamValue(ctx context.Context, teamID int64) map[string]any {
+	if !setting.Audit.Enabled {
+		return nil
+	}
+	t, err := org_model.GetTeamByID(ctx, teamID)
+	if err != nil {
+		return nil
+	}
+	units := make(map[string]string)
+	if err := t.LoadUnits(ctx); err == nil {
+		for _, u := range t.Units {
+			units[u.Unit().NameKey] = u.AccessMode.String()
+		}
+	}
+	return map[string]any{
+		"name":                      t.Name,
+		"access_mode":               t.AccessMode.String(),
+		"includes_all_repositories": t.IncludesAllRepositories,
+		"can_create_org_repo":       t.CanCreateOrgRepo,
+		"units":                     units,
+	}
+}
+
+// memberValue returns the name of the member a change was made for
+func memberValue(ctx context.Context, userID int64) map[string]string {
+	if !setting.Audit.Enabled {
+		return nil
+	}
+	name := ""
+	if u, err := user_model.GetUserByID(ctx, userID); err == nil {
+		name = u.Name
+	}
+	return map[string]string{"member": name}
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package org
+
+import (
+	"testing"
+
+	audit_model "forgejo.org/models/audit"
+	"forgejo.org/models/db"
+	"forgejo.org/models/organization"
+	"forgejo.org/models/perm"
+	"forgejo.org/models/unit"
+	"forgejo.org/models/unittest"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamAuditEvents(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	defer test.MockVariableValue(&setting.Audit.Enabled, true)()
+
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	org := unittest.AssertExistsAndLoadBean(t, &organization.Organization{ID: 3})
+	event := func(t *testing.T, action audit_model.Action) *audit_model.Event {
+		t.Helper()
+		events, err := db.Find[audit_model.Event](db.DefaultContext, audit_model.FindEventsOptions{Action: action, OwnerID: org.ID})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.EqualValues(t, doer.ID, events[0].ActorID)
+		return events[0]
+	}
+
+	team := &organization.Team{
+		OrgID:      org.ID,
+		Name:       "audited",
+		AccessMode: perm.AccessModeRead,
+		Units:      []*organization.TeamUnit{{OrgID: org.ID, Type: unit.TypeCode, AccessMode: perm.AccessModeRead}},
+	}
+	require.NoError(t, NewTeam(db.DefaultContext, doer, team))
+	e := event(t, audit_model.ActionOrgTeamCreate)
+	assert.Equal(t, audit_model.TargetTypeTeam, e.TargetType)
+	assert.Equal(t, team.ID, e.TargetID)
+	assert.JSONEq(t, `{"name":"audited","access_mode":"read","includes_all_repositories":false,"can_create_org_repo":false,"units":{"repo.code":"read"}}`, e.After)
+
+	team.AccessMode = perm.AccessModeWrite
+	team.Units = []*organization.TeamUnit{{OrgID: org.ID, Type: unit.TypeCode, AccessMode: perm.AccessModeWrite}}
+	require.NoError(t, UpdateTeam(db.DefaultContext, doer, team, true, false))
+	e = event(t, audit_model.ActionOrgTeamUpdate)
+	assert.JSONEq(t, `{"name":"audited","access_mode":"read","includes_all_repositories":false,"can_create_org_repo":false,"units":{"repo.code":"read"}}`, e.Before)
+	assert.JSONEq(t, `{"name":"audited","access_mode":"write","includes_all_repositories":false,"can_create_org_repo":false,"units":{"repo.code":"write"}}`, e.After)
+
+	require.NoError(t, AddTeamMember(db.DefaultContext, doer, team, 5))
+	assert.JSONEq(t, `{"member":"user5"}`, event(t, audit_model.ActionOrgTeamMemberAdd).After)
+
+	require.NoError(t, RemoveTeamMember(db.DefaultContext, doer, team, 5))
+	assert.JSONEq(t, `{"member":"user5"}`, event(t, audit_model.ActionOrgTeamMemberRemove).Before)
+
+	require.NoError(t, DeleteTeam(db.DefaultContext, doer, team))
+	assert.NotEmpty(t, event(t, audit_model.ActionOrgTeamDelete).Before)
+
+	// user4 is a member of team1 of org3
+	require.NoError(t, RemoveOrgUser(db.DefaultContext, doer, org, 4))
+	e = event(t, audit_model.ActionOrgMemberRemove)
+	assert.Equal(t, audit_model.TargetTypeUser, e.TargetType)
+	assert.JSONEq(t, `{"member":"user4"}`, e.Before)
+}
@@ -22,9 +22,11 @@ type packageClaims struct {
 	jwt.RegisteredClaims
 	UserID int64
 	Scope  auth_model.AccessTokenScope
+	// ActionsTaskID is the task whose token the authorization token was created with
+	ActionsTaskID int64 `json:",omitempty"`
 }
 
-func CreateAuthorizationToken(u *user_model.User, scope auth_model.AccessTokenScope) (string, error) {
+func CreateAuthorizationToken(u *user_model.User, scope auth_model.AccessTokenScope, actionsTaskID int64) (string, error) {
 	now := time.Now()
 
 	claims := packageClaims{
@@ -32,8 +34,9 @@ func CreateAuthorizationToken(u *user_model.User, scope auth_model.AccessTokenSc
 			ExpiresAt: jwt.NewNumericDate(now.Add(24 * time.Hour)),
 			NotBefore: jwt.NewNumericDate(now),
 		},
-		UserID: u.ID,
-		Scope:  scope,
+		UserID:        u.ID,
+		Scope:         scope,
+		ActionsTaskID: actionsTaskID,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
@@ -45,16 +48,16 @@ func CreateAuthorizationToken(u *user_model.User, scope auth_model.AccessTokenSc
 	return tokenString, nil
 }
 
-func ParseAuthorizationToken(req *http.Request) (int64, auth_model.AccessTokenScope, error) {
+func ParseAuthorizationToken(req *http.Request) (int64, auth_model.AccessTokenScope, int64, error) {
 	h := req.Header.Get("Authorization")
 	if h == "" {
-		return 0, "", nil
+		return 0, "", 0, nil
 	}
 
 	parts := strings.SplitN(h, " ", 2)
 	if len(parts) != 2 {
 		log.Error("split token failed: %s", h)
-		return 0, "", errors.New("split token failed")
+		return 0, "", 0, errors.New("split token failed")
 	}
 
 	token, err := jwt.ParseWithClaims(parts[1], &packageClaims{}, func(t *jwt.Token) (any, error) {
@@ -64,13 +67,13 @@ func ParseAuthorizationToken(req *http.Request) (int64, auth_model.AccessTokenSc
 		return setting.GetGeneralTokenSigningSecret(), nil
 	})
 	if err != nil {
-		return 0, "", err
+		return 0, "", 0, err
 	}
 
 	c, ok := token.Claims.(*packageClaims)
 	if !token.Valid || !ok {
-		return 0, "", errors.New("invalid token claim")
+		return 0, "", 0, errors.New("invalid token claim")
 	}
 
-	return c.UserID, c.Scope, nil
+	return c.UserID, c.Scope, c.ActionsTaskID, nil
 }
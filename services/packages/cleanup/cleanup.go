@@ -71,6 +71,10 @@ func ExecuteCleanupRules(outerCtx context.Context) error {
 			}
 			versionDeleted := false
 			for _, pv := range pvs {
+				if pv.IsImmutable {
+					log.Debug("Rule[%d]: keep '%s/%s' (immutable)", pcr.ID, p.Name, pv.Version)
+					continue
+				}
 				if pcr.Type == packages_model.TypeContainer {
 					if skip, err := container_service.ShouldBeSkipped(ctx, pcr, p, pv); err != nil {
 						return fmt.Errorf("CleanupRule [%d]: container.ShouldBeSkipped failed: %w", pcr.ID, err)
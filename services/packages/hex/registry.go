@@ -0,0 +1,275 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package hex
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+
+	"forgejo.org/models/db"
+	packages_model "forgejo.org/models/packages"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/json"
+	hex_module "forgejo.org/modules/packages/hex"
+	"forgejo.org/modules/sync"
+	"forgejo.org/modules/util"
+)
+
+// RepositoryName gets the name of the Hex repository of the owner.
+// Clients verify that the resources were created for the repository they added, so the name must be stable.
+func RepositoryName(owner *user_model.User) string {
+	return owner.LowerName
+}
+
+var keyPairLocker = sync.NewExclusivePool()
+
+func keyPairLockKey(ownerID int64) string {
+	return fmt.Sprintf("hex_keypair_%d", ownerID)
+}
+
+func getKeyPair(ctx context.Context, ownerID int64) (string, string, error) {
+	priv, err := user_model.GetSetting(ctx, ownerID, hex_module.SettingKeyPrivate)
+	if err != nil && !errors.Is(err, util.ErrNotExist) {
+		return "", "", err
+	}
+
+	pub, err := user_model.GetSetting(ctx, ownerID, hex_module.SettingKeyPublic)
+	if err != nil && !errors.Is(err, util.ErrNotExist) {
+		return "", "", err
+	}
+
+	return priv, pub, nil
+}
+
+func createKeyPair(ctx context.Context, ownerID int64) (string, string, error) {
+	priv, pub, err := util.GenerateKeyPair(4096)
+	if err != nil {
+		return "", "", err
+	}
+
+	return priv, pub, db.WithTx(ctx, func(ctx context.Context) error {
+		if err := user_model.SetUserSetting(ctx, ownerID, hex_module.SettingKeyPrivate, priv); err != nil {
+			return err
+		}
+		return user_model.SetUserSetting(ctx, ownerID, hex_module.SettingKeyPublic, pub)
+	})
+}
+
+// GetOrCreateKeyPair gets or creates the RSA keys used to sign the registry resources
+func GetOrCreateKeyPair(ctx context.Context, ownerID int64) (string, string, error) {
+	priv, pub, err := getKeyPair(ctx, ownerID)
+	if err != nil || (priv != "" && pub != "") {
+		return priv, pub, err
+	}
+
+	key := keyPairLockKey(ownerID)
+	keyPairLocker.CheckIn(key)
+	defer keyPairLocker.CheckOut(key)
+
+	// another request may have created the keys while waiting for the lock
+	priv, pub, err = getKeyPair(ctx, ownerID)
+	if err != nil || (priv != "" && pub != "") {
+		return priv, pub, err
+	}
+
+	return createKeyPair(ctx, ownerID)
+}
+
+// GetPublicKey gets the public key of the owner, or an empty string if no resource was signed yet
+func GetPublicKey(ctx context.Context, ownerID int64) (string, error) {
+	_, pub, err := getKeyPair(ctx, ownerID)
+	return pub, err
+}
+
+// RegenerateKeyPair replaces the keys of the owner. Clients have to fetch the new public key afterwards.
+func RegenerateKeyPair(ctx context.Context, ownerID int64) error {
+	key := keyPairLockKey(ownerID)
+	keyPairLocker.CheckIn(key)
+	defer keyPairLocker.CheckOut(key)
+
+	_, _, err := createKeyPair(ctx, ownerID)
+	return err
+}
+
+// sign creates the gzipped Signed message of the payload
+func sign(ctx context.Context, ownerID int64, payload []byte) ([]byte, error) {
+	priv, _, err := GetOrCreateKeyPair(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode([]byte(priv))
+	if block == nil {
+		return nil, errors.New("failed to decode private key pem")
+	}
+
+	privKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha512.Sum512(payload)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privKey, crypto.SHA512, h[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return hex_module.EncodeSigned(payload, signature)
+}
+
+// loadPackageDescriptors gets the descriptors of all versions of the owner grouped by package and sorted by version
+func loadPackageDescriptors(ctx context.Context, owner *user_model.User) ([][]*packages_model.PackageDescriptor, error) {
+	pvs, err := packages_model.GetVersionsByPackageType(ctx, owner.ID, packages_model.TypeHex)
+	if err != nil {
+		return nil, err
+	}
+
+	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupPackageDescriptors(pds), nil
+}
+
+func groupPackageDescriptors(pds []*packages_model.PackageDescriptor) [][]*packages_model.PackageDescriptor {
+	sort.Slice(pds, func(i, j int) bool {
+		if pds[i].Package.LowerName != pds[j].Package.LowerName {
+			return pds[i].Package.LowerName < pds[j].Package.LowerName
+		}
+		return pds[i].SemVer.LessThan(pds[j].SemVer)
+	})
+
+	groups := make([][]*packages_model.PackageDescriptor, 0, len(pds))
+	for _, pd := range pds {
+		if n := len(groups); n > 0 && groups[n-1][0].Package.ID == pd.Package.ID {
+			groups[n-1] = append(groups[n-1], pd)
+		} else {
+			groups = append(groups, []*packages_model.PackageDescriptor{pd})
+		}
+	}
+	return groups
+}
+
+// BuildNames builds the signed names resource of the owner
+func BuildNames(ctx context.Context, owner *user_model.User) ([]byte, error) {
+	groups, err := loadPackageDescriptors(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make([]*hex_module.NamesPackage, 0, len(groups))
+	for _, pds := range groups {
+		var updated int64
+		for _, pd := range pds {
+			updated = max(updated, int64(pd.Version.CreatedUnix))
+		}
+		packages = append(packages, &hex_module.NamesPackage{
+			Name:           pds[0].Package.Name,
+			UpdatedSeconds: updated,
+		})
+	}
+
+	return sign(ctx, owner.ID, hex_module.EncodeNames(RepositoryName(owner), packages))
+}
+
+// BuildVersions builds the signed versions resource of the owner
+func BuildVersions(ctx context.Context, owner *user_model.User) ([]byte, error) {
+	groups, err := loadPackageDescriptors(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make([]*hex_module.VersionsPackage, 0, len(groups))
+	for _, pds := range groups {
+		p := &hex_module.VersionsPackage{
+			Name:     pds[0].Package.Name,
+			Versions: make([]string, 0, len(pds)),
+		}
+		for i, pd := range pds {
+			p.Versions = append(p.Versions, pd.Version.Version)
+			if pd.VersionProperties.GetByName(hex_module.PropertyRetired) != "" {
+				p.Retired = append(p.Retired, int32(i))
+			}
+		}
+		packages = append(packages, p)
+	}
+
+	return sign(ctx, owner.ID, hex_module.EncodeVersions(RepositoryName(owner), packages))
+}
+
+// BuildPackage builds the signed package resource of a package
+func BuildPackage(ctx context.Context, owner *user_model.User, name string) ([]byte, error) {
+	pvs, err := packages_model.GetVersionsByPackageName(ctx, owner.ID, packages_model.TypeHex, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(pvs) == 0 {
+		return nil, packages_model.ErrPackageNotExist
+	}
+
+	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+	if err != nil {
+		return nil, err
+	}
+	pds = groupPackageDescriptors(pds)[0]
+
+	releases := make([]*hex_module.Release, 0, len(pds))
+	for _, pd := range pds {
+		innerChecksum, err := hex.DecodeString(pd.VersionProperties.GetByName(hex_module.PropertyInnerChecksum))
+		if err != nil {
+			return nil, err
+		}
+		outerChecksum, err := hex.DecodeString(pd.Files[0].Blob.HashSHA256)
+		if err != nil {
+			return nil, err
+		}
+
+		r := &hex_module.Release{
+			Version:       pd.Version.Version,
+			InnerChecksum: innerChecksum,
+			OuterChecksum: outerChecksum,
+			Dependencies:  pd.Metadata.(*hex_module.Metadata).Requirements,
+		}
+		if retired := pd.VersionProperties.GetByName(hex_module.PropertyRetired); retired != "" {
+			r.Retired = &hex_module.RetirementStatus{}
+			if err := json.Unmarshal([]byte(retired), r.Retired); err != nil {
+				return nil, err
+			}
+		}
+		releases = append(releases, r)
+	}
+
+	return sign(ctx, owner.ID, hex_module.EncodePackage(RepositoryName(owner), pds[0].Package.Name, releases))
+}
+
+// RetireRelease marks the version as retired. Retired versions are still available but clients warn about them.
+func RetireRelease(ctx context.Context, pv *packages_model.PackageVersion, status *hex_module.RetirementStatus) error {
+	value, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if err := packages_model.DeletePropertyByName(ctx, packages_model.PropertyTypeVersion, pv.ID, hex_module.PropertyRetired); err != nil {
+			return err
+		}
+		_, err := packages_model.InsertProperty(ctx, packages_model.PropertyTypeVersion, pv.ID, hex_module.PropertyRetired, string(value))
+		return err
+	})
+}
+
+// UnretireRelease removes the retirement of the version
+func UnretireRelease(ctx context.Context, pv *packages_model.PackageVersion) error {
+	return packages_model.DeletePropertyByName(ctx, packages_model.PropertyTypeVersion, pv.ID, hex_module.PropertyRetired)
+}
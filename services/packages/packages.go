@@ -162,6 +162,7 @@ func createPackageAndVersion(ctx context.Context, pvci *PackageCreationInfo, all
 		LowerVersion: strings.ToLower(pvci.Version),
 		MetadataJSON: string(metadataJSON),
 	}
+	ApplyProvenance(ctx, pv)
 	if pv, err = packages_model.GetOrInsertVersion(ctx, pv); err != nil {
 		if err == packages_model.ErrDuplicatePackageVersion {
 			versionCreated = false
@@ -174,6 +175,9 @@ func createPackageAndVersion(ctx context.Context, pvci *PackageCreationInfo, all
 			// no need to log an error
 			return nil, false, err
 		}
+		if pv.IsImmutable {
+			return nil, false, packages_model.ErrPackageVersionImmutable
+		}
 	}
 
 	if versionCreated {
@@ -199,6 +203,9 @@ func AddFileToExistingPackage(ctx context.Context, pvi *PackageInfo, pfci *Packa
 		if err != nil {
 			return nil, nil, false, err
 		}
+		if pv.IsImmutable {
+			return nil, nil, false, packages_model.ErrPackageVersionImmutable
+		}
 
 		return addFileToPackageVersion(ctx, pv, pvi, pfci)
 	})
@@ -378,12 +385,16 @@ func CheckSizeQuotaExceeded(ctx context.Context, doer, owner *user_model.User, p
 		typeSpecificSize = setting.Packages.LimitSizeCran
 	case packages_model.TypeDebian:
 		typeSpecificSize = setting.Packages.LimitSizeDebian
+	case packages_model.TypeGalaxy:
+		typeSpecificSize = setting.Packages.LimitSizeGalaxy
 	case packages_model.TypeGeneric:
 		typeSpecificSize = setting.Packages.LimitSizeGeneric
 	case packages_model.TypeGo:
 		typeSpecificSize = setting.Packages.LimitSizeGo
 	case packages_model.TypeHelm:
 		typeSpecificSize = setting.Packages.LimitSizeHelm
+	case packages_model.TypeHex:
+		typeSpecificSize = setting.Packages.LimitSizeHex
 	case packages_model.TypeMaven:
 		typeSpecificSize = setting.Packages.LimitSizeMaven
 	case packages_model.TypeNpm:
@@ -475,8 +486,21 @@ func RemovePackageVersionByNameAndVersion(ctx context.Context, doer *user_model.
 	return RemovePackageVersion(ctx, doer, pv)
 }
 
+// CheckVersionMutable checks if the doer may change or delete the version.
+// Immutable versions can only be deleted by site administrators.
+func CheckVersionMutable(doer *user_model.User, pv *packages_model.PackageVersion) error {
+	if pv.IsImmutable && (doer == nil || !doer.IsAdmin) {
+		return packages_model.ErrPackageVersionImmutable
+	}
+	return nil
+}
+
 // RemovePackageVersion deletes the package version and all associated files
 func RemovePackageVersion(ctx context.Context, doer *user_model.User, pv *packages_model.PackageVersion) error {
+	if err := CheckVersionMutable(doer, pv); err != nil {
+		return err
+	}
+
 	dbCtx, committer, err := db.TxContext(ctx)
 	if err != nil {
 		return err
@@ -508,6 +532,14 @@ func RemovePackageFileAndVersionIfUnreferenced(ctx context.Context, doer *user_m
 	var pd *packages_model.PackageDescriptor
 
 	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		pv, err := packages_model.GetVersionByID(ctx, pf.VersionID)
+		if err != nil {
+			return err
+		}
+		if err := CheckVersionMutable(doer, pv); err != nil {
+			return err
+		}
+
 		if err := DeletePackageFile(ctx, pf); err != nil {
 			return err
 		}
@@ -517,11 +549,6 @@ func RemovePackageFileAndVersionIfUnreferenced(ctx context.Context, doer *user_m
 			return err
 		}
 		if !has {
-			pv, err := packages_model.GetVersionByID(ctx, pf.VersionID)
-			if err != nil {
-				return err
-			}
-
 			pd, err = packages_model.GetPackageDescriptor(ctx, pv)
 			if err != nil {
 				return err
@@ -550,6 +577,18 @@ func DeletePackageVersionAndReferences(ctx context.Context, pv *packages_model.P
 		return err
 	}
 
+	if err := packages_model.DeleteVulnerabilitiesByVersionID(ctx, pv.ID); err != nil {
+		return err
+	}
+
+	if err := packages_model.DeleteChannelsByVersionID(ctx, pv.ID); err != nil {
+		return err
+	}
+
+	if err := packages_model.DeletePromotionsByVersionID(ctx, pv.ID); err != nil {
+		return err
+	}
+
 	pfs, err := packages_model.GetFilesByVersionID(ctx, pv.ID)
 	if err != nil {
 		return err
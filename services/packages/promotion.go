@@ -0,0 +1,318 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package packages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"forgejo.org/models/db"
+	packages_model "forgejo.org/models/packages"
+	container_model "forgejo.org/models/packages/container"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/log"
+	container_module "forgejo.org/modules/packages/container"
+	"forgejo.org/modules/util"
+	notify_service "forgejo.org/services/notify"
+)
+
+var (
+	ErrInvalidChannelName    = util.NewInvalidArgumentErrorf("channel name is invalid")
+	ErrPromotionNotSupported = util.NewInvalidArgumentErrorf("package type does not support promotion to another owner")
+)
+
+var channelNamePattern = regexp.MustCompile(`\A[a-z0-9][a-z0-9._-]{0,63}\z`)
+
+// IsValidChannelName checks if the name can be used as a channel name
+func IsValidChannelName(name string) bool {
+	return channelNamePattern.MatchString(name)
+}
+
+// CanPromoteToOtherOwner checks if versions of the package type can be copied to another owner.
+// Types with an owner wide repository index are excluded because a copied version would not be
+// reachable without rebuilding the index of the target owner.
+func CanPromoteToOtherOwner(packageType packages_model.Type) bool {
+	switch packageType {
+	case packages_model.TypeAlpine, packages_model.TypeAlt, packages_model.TypeArch, packages_model.TypeCargo,
+		packages_model.TypeDebian, packages_model.TypeRpm:
+		return false
+	}
+	return true
+}
+
+// PromotionOptions describes a promotion of a package version
+type PromotionOptions struct {
+	Doer *user_model.User
+	// Owner which receives the version, may be the owner of the source version
+	TargetOwner *user_model.User
+	// Channel which is pointed to the promoted version, optional
+	Channel string
+	// Lock the promoted version so that it can't be overwritten or deleted
+	Lock bool
+}
+
+// PromotePackageVersion promotes a version to a channel and/or another owner.
+// Versions are copied between owners without re-uploading the files, the copy references the same blobs.
+func PromotePackageVersion(ctx context.Context, pd *packages_model.PackageDescriptor, opts *PromotionOptions) (*packages_model.PackageVersion, error) {
+	if opts.Channel != "" && !IsValidChannelName(opts.Channel) {
+		return nil, ErrInvalidChannelName
+	}
+
+	sameOwner := opts.TargetOwner.ID == pd.Owner.ID
+	if !sameOwner && !CanPromoteToOtherOwner(pd.Package.Type) {
+		return nil, fmt.Errorf("%w: %s versions can only be promoted to channels of their owner", ErrPromotionNotSupported, pd.Package.Type.Name())
+	}
+
+	var pv *packages_model.PackageVersion
+	created := false
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if sameOwner {
+			pv = pd.Version
+		} else {
+			if pd.Package.Type == packages_model.TypeContainer {
+				if err := copyContainerIndexManifests(ctx, pd, opts.TargetOwner, opts.Doer); err != nil {
+					return err
+				}
+			}
+			pv, created, err = copyPackageVersion(ctx, pd, opts.TargetOwner, opts.Doer)
+			if err != nil {
+				return err
+			}
+		}
+
+		if opts.Channel != "" {
+			if err := packages_model.SetChannel(ctx, &packages_model.PackageChannel{
+				PackageID:    pv.PackageID,
+				Name:         opts.Channel,
+				VersionID:    pv.ID,
+				PromotedByID: opts.Doer.ID,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if opts.Lock && !pv.IsImmutable {
+			if err := packages_model.SetVersionImmutable(ctx, pv.ID); err != nil {
+				return err
+			}
+			pv.IsImmutable = true
+		}
+
+		return packages_model.InsertPromotion(ctx, &packages_model.PackagePromotion{
+			VersionID:       pv.ID,
+			SourceVersionID: pd.Version.ID,
+			SourceOwnerID:   pd.Owner.ID,
+			Channel:         opts.Channel,
+			DoerID:          opts.Doer.ID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if created {
+		pdCopy, err := packages_model.GetPackageDescriptor(ctx, pv)
+		if err != nil {
+			return nil, err
+		}
+		notify_service.PackageCreate(ctx, opts.Doer, pdCopy)
+	}
+
+	return pv, nil
+}
+
+// copyPackageVersion copies the version with its properties and files to the target owner.
+// If the target owner has the version already, it is reused if it contains the same files.
+func copyPackageVersion(ctx context.Context, pd *packages_model.PackageDescriptor, target, doer *user_model.User) (*packages_model.PackageVersion, bool, error) {
+	p := &packages_model.Package{
+		OwnerID:          target.ID,
+		Type:             pd.Package.Type,
+		Name:             pd.Package.Name,
+		LowerName:        pd.Package.LowerName,
+		SemverCompatible: pd.Package.SemverCompatible,
+	}
+	packageCreated := true
+	p, err := packages_model.TryInsertPackage(ctx, p)
+	if err != nil {
+		if !errors.Is(err, packages_model.ErrDuplicatePackage) {
+			return nil, false, err
+		}
+		packageCreated = false
+	}
+
+	if packageCreated {
+		for _, pp := range pd.PackageProperties {
+			value := pp.Value
+			// the repository of a container image is named after its owner
+			if pp.Name == container_module.PropertyRepository && p.Type == packages_model.TypeContainer {
+				value = target.LowerName + "/" + p.LowerName
+			}
+			if _, err := packages_model.InsertProperty(ctx, packages_model.PropertyTypePackage, p.ID, pp.Name, value); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
+	pv, err := packages_model.GetOrInsertVersion(ctx, &packages_model.PackageVersion{
+		PackageID:    p.ID,
+		CreatorID:    pd.Version.CreatorID,
+		Version:      pd.Version.Version,
+		LowerVersion: pd.Version.LowerVersion,
+		MetadataJSON: pd.Version.MetadataJSON,
+		// the promoted version is the same build
+		ProvenanceRepoID:     pd.Version.ProvenanceRepoID,
+		ProvenanceRunID:      pd.Version.ProvenanceRunID,
+		ProvenanceTaskID:     pd.Version.ProvenanceTaskID,
+		ProvenanceCommitSHA:  pd.Version.ProvenanceCommitSHA,
+		ProvenanceRef:        pd.Version.ProvenanceRef,
+		ProvenanceWorkflowID: pd.Version.ProvenanceWorkflowID,
+	})
+	if err != nil {
+		if !errors.Is(err, packages_model.ErrDuplicatePackageVersion) {
+			return nil, false, err
+		}
+
+		same, err := hasSameFiles(ctx, pd, pv)
+		if err != nil {
+			return nil, false, err
+		}
+		if !same {
+			return nil, false, packages_model.ErrDuplicatePackageVersion
+		}
+		return pv, false, nil
+	}
+
+	if err := CheckCountQuotaExceeded(ctx, doer, target); err != nil {
+		return nil, false, err
+	}
+	if err := CheckSizeQuotaExceeded(ctx, doer, target, pd.Package.Type, pd.CalculateBlobSize()); err != nil {
+		return nil, false, err
+	}
+
+	for _, pp := range pd.VersionProperties {
+		if _, err := packages_model.InsertProperty(ctx, packages_model.PropertyTypeVersion, pv.ID, pp.Name, pp.Value); err != nil {
+			return nil, false, err
+		}
+	}
+
+	for _, pfd := range pd.Files {
+		pf, err := packages_model.TryInsertFile(ctx, &packages_model.PackageFile{
+			VersionID:    pv.ID,
+			BlobID:       pfd.Blob.ID,
+			Name:         pfd.File.Name,
+			LowerName:    pfd.File.LowerName,
+			CompositeKey: pfd.File.CompositeKey,
+			IsLead:       pfd.File.IsLead,
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, pp := range pfd.Properties {
+			if _, err := packages_model.InsertProperty(ctx, packages_model.PropertyTypeFile, pf.ID, pp.Name, pp.Value); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
+	log.Trace("Copied package version %d to %d of owner %d", pd.Version.ID, pv.ID, target.ID)
+
+	return pv, true, nil
+}
+
+// copyContainerIndexManifests copies the manifests an image index references, they must exist in
+// the image of the target owner before the index can be pulled from it
+func copyContainerIndexManifests(ctx context.Context, pd *packages_model.PackageDescriptor, target, doer *user_model.User) error {
+	metadata, ok := pd.Metadata.(*container_module.Metadata)
+	if !ok {
+		return nil
+	}
+	for _, manifest := range metadata.Manifests {
+		pfd, err := container_model.GetContainerBlob(ctx, &container_model.BlobSearchOptions{
+			OwnerID:    pd.Owner.ID,
+			Image:      pd.Package.LowerName,
+			Digest:     manifest.Digest,
+			IsManifest: true,
+		})
+		if err != nil {
+			return err
+		}
+		pv, err := packages_model.GetVersionByID(ctx, pfd.File.VersionID)
+		if err != nil {
+			return err
+		}
+		manifestPd, err := packages_model.GetPackageDescriptor(ctx, pv)
+		if err != nil {
+			return err
+		}
+		if _, _, err := copyPackageVersion(ctx, manifestPd, target, doer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasSameFiles(ctx context.Context, pd *packages_model.PackageDescriptor, pv *packages_model.PackageVersion) (bool, error) {
+	pfs, err := packages_model.GetFilesByVersionID(ctx, pv.ID)
+	if err != nil {
+		return false, err
+	}
+	if len(pfs) != len(pd.Files) {
+		return false, nil
+	}
+
+	type fileKey struct {
+		name, compositeKey string
+	}
+	blobs := make(map[fileKey]int64, len(pfs))
+	for _, pf := range pfs {
+		blobs[fileKey{pf.LowerName, pf.CompositeKey}] = pf.BlobID
+	}
+	for _, pfd := range pd.Files {
+		if blobID, ok := blobs[fileKey{pfd.File.LowerName, pfd.File.CompositeKey}]; !ok || blobID != pfd.Blob.ID {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// SetPackageChannel points the channel of the package to the version
+func SetPackageChannel(ctx context.Context, doer *user_model.User, pd *packages_model.PackageDescriptor, channel string) error {
+	if !IsValidChannelName(channel) {
+		return ErrInvalidChannelName
+	}
+
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if err := packages_model.SetChannel(ctx, &packages_model.PackageChannel{
+			PackageID:    pd.Package.ID,
+			Name:         channel,
+			VersionID:    pd.Version.ID,
+			PromotedByID: doer.ID,
+		}); err != nil {
+			return err
+		}
+
+		return packages_model.InsertPromotion(ctx, &packages_model.PackagePromotion{
+			VersionID:       pd.Version.ID,
+			SourceVersionID: pd.Version.ID,
+			SourceOwnerID:   pd.Owner.ID,
+			Channel:         channel,
+			DoerID:          doer.ID,
+		})
+	})
+}
+
+// LockPackageVersion makes the version immutable
+func LockPackageVersion(ctx context.Context, pv *packages_model.PackageVersion) error {
+	if pv.IsImmutable {
+		return nil
+	}
+	if err := packages_model.SetVersionImmutable(ctx, pv.ID); err != nil {
+		return err
+	}
+	pv.IsImmutable = true
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package packages
+
+import (
+	"context"
+	"errors"
+
+	actions_model "forgejo.org/models/actions"
+	packages_model "forgejo.org/models/packages"
+	repo_model "forgejo.org/models/repo"
+	"forgejo.org/modules/util"
+)
+
+type contextKey struct {
+	name string
+}
+
+// ProvenanceContextKey is the context key of the provenance which is recorded for created package versions
+var ProvenanceContextKey = &contextKey{"provenance"}
+
+// Provenance describes the Actions run which published a package version
+type Provenance struct {
+	RepoID     int64
+	RunID      int64
+	TaskID     int64
+	CommitSHA  string
+	Ref        string
+	WorkflowID string
+
+	// Repository and Run are nil if they got deleted in the meantime
+	Repository *repo_model.Repository
+	Run        *actions_model.ActionRun
+}
+
+func (p *Provenance) apply(pv *packages_model.PackageVersion) {
+	pv.ProvenanceRepoID = p.RepoID
+	pv.ProvenanceRunID = p.RunID
+	pv.ProvenanceTaskID = p.TaskID
+	pv.ProvenanceCommitSHA = p.CommitSHA
+	pv.ProvenanceRef = p.Ref
+	pv.ProvenanceWorkflowID = p.WorkflowID
+}
+
+// ApplyProvenance records the provenance of the context, if any, for the package version which is created
+func ApplyProvenance(ctx context.Context, pv *packages_model.PackageVersion) {
+	if provenance, ok := ctx.Value(ProvenanceContextKey).(*Provenance); ok {
+		provenance.apply(pv)
+	}
+}
+
+// GetProvenanceByTaskID gets the provenance of packages uploaded with the token of an Actions task
+func GetProvenanceByTaskID(ctx context.Context, taskID int64) (*Provenance, error) {
+	task, err := actions_model.GetTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := task.LoadJob(ctx); err != nil {
+		return nil, err
+	}
+	if err := task.Job.LoadRun(ctx); err != nil {
+		return nil, err
+	}
+	run := task.Job.Run
+	if err := run.LoadRepo(ctx); err != nil {
+		return nil, err
+	}
+
+	return &Provenance{
+		RepoID:     run.RepoID,
+		RunID:      run.ID,
+		TaskID:     task.ID,
+		CommitSHA:  task.CommitSHA,
+		Ref:        run.Ref,
+		WorkflowID: run.WorkflowID,
+		Repository: run.Repo,
+		Run:        run,
+	}, nil
+}
+
+// GetVersionProvenance gets the provenance of a package version or nil if it was not published by an Actions run
+func GetVersionProvenance(ctx context.Context, pv *packages_model.PackageVersion) (*Provenance, error) {
+	if !pv.HasProvenance() {
+		return nil, nil
+	}
+
+	p := &Provenance{
+		RepoID:     pv.ProvenanceRepoID,
+		RunID:      pv.ProvenanceRunID,
+		TaskID:     pv.ProvenanceTaskID,
+		CommitSHA:  pv.ProvenanceCommitSHA,
+		Ref:        pv.ProvenanceRef,
+		WorkflowID: pv.ProvenanceWorkflowID,
+	}
+
+	repo, err := repo_model.GetRepositoryByID(ctx, p.RepoID)
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			return p, nil
+		}
+		return nil, err
+	}
+	p.Repository = repo
+
+	run, err := actions_model.GetRunByID(ctx, p.RunID)
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			return p, nil
+		}
+		return nil, err
+	}
+	run.Repo = repo
+	p.Run = run
+
+	return p, nil
+}
@@ -0,0 +1,216 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package scan
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	packages_model "forgejo.org/models/packages"
+	"forgejo.org/modules/osv"
+	"forgejo.org/modules/packages/cargo"
+	"forgejo.org/modules/packages/composer"
+	"forgejo.org/modules/packages/maven"
+	"forgejo.org/modules/packages/npm"
+	"forgejo.org/modules/packages/pypi"
+	repo_module "forgejo.org/modules/repository"
+)
+
+// Dependency is a dependency declared in the manifest of a package version
+type Dependency struct {
+	Ecosystem   string
+	Name        string
+	Requirement string
+}
+
+// ExtractDependencies returns the runtime dependencies of the package version.
+// Development dependencies are ignored because they are not shipped to consumers.
+func ExtractDependencies(pd *packages_model.PackageDescriptor) []*Dependency {
+	deps := make([]*Dependency, 0, 10)
+	addMap := func(ecosystem string, m map[string]string) {
+		for name, req := range m {
+			deps = append(deps, &Dependency{Ecosystem: ecosystem, Name: name, Requirement: req})
+		}
+	}
+
+	switch m := pd.Metadata.(type) {
+	case *npm.Metadata:
+		addMap(osv.EcosystemNpm, m.Dependencies)
+		addMap(osv.EcosystemNpm, m.PeerDependencies)
+		addMap(osv.EcosystemNpm, m.OptionalDependencies)
+	case *cargo.Metadata:
+		for _, d := range m.Dependencies {
+			if d.Kind == "dev" {
+				continue
+			}
+			name := d.Name
+			if d.Package != nil && *d.Package != "" {
+				// the dependency is renamed in the manifest
+				name = *d.Package
+			}
+			deps = append(deps, &Dependency{Ecosystem: osv.EcosystemCargo, Name: name, Requirement: d.Req})
+		}
+	case *composer.Metadata:
+		for name, req := range m.Require {
+			if isComposerPlatformPackage(name) {
+				continue
+			}
+			deps = append(deps, &Dependency{Ecosystem: osv.EcosystemPackagist, Name: name, Requirement: req})
+		}
+	case *maven.Metadata:
+		for _, d := range m.Dependencies {
+			if d.GroupID == "" || d.ArtifactID == "" {
+				continue
+			}
+			deps = append(deps, &Dependency{Ecosystem: osv.EcosystemMaven, Name: d.GroupID + ":" + d.ArtifactID, Requirement: d.Version})
+		}
+	case *pypi.Metadata:
+		for _, rd := range m.RequiresDist {
+			if d := parseRequiresDist(rd); d != nil {
+				deps = append(deps, d)
+			}
+		}
+	}
+
+	sort.Slice(deps, func(i, j int) bool {
+		return deps[i].Name < deps[j].Name
+	})
+	return deps
+}
+
+// isComposerPlatformPackage checks if the requirement targets the platform instead of a package
+// https://getcomposer.org/doc/01-basic-usage.md#platform-packages
+func isComposerPlatformPackage(name string) bool {
+	return !strings.Contains(name, "/")
+}
+
+// https://packaging.python.org/en/latest/specifications/dependency-specifiers/
+var requiresDistPattern = regexp.MustCompile(`\A\s*([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?)\s*(?:\[[^\]]*\])?\s*\(?([^;)]*)\)?\s*(?:;(.*))?\z`)
+
+func parseRequiresDist(rd string) *Dependency {
+	m := requiresDistPattern.FindStringSubmatch(rd)
+	if m == nil {
+		return nil
+	}
+	if strings.Contains(m[3], "extra") {
+		// optional dependency which is only installed on request
+		return nil
+	}
+	return &Dependency{
+		Ecosystem:   osv.EcosystemPyPI,
+		Name:        m[1],
+		Requirement: strings.TrimSpace(m[2]),
+	}
+}
+
+var versionPattern = regexp.MustCompile(`[0-9]+(?:\.[0-9]+)*(?:[-.+]?[0-9A-Za-z]+)*`)
+
+// MinimumVersion returns the lowest version allowed by a requirement like `^1.2.3`, `>=2.0,<3`
+// or `[1.0,2.0)`. The lowest version is the one a consumer may resolve to in the worst case.
+// An empty string is returned if the requirement does not contain a version.
+func MinimumVersion(requirement string) string {
+	requirement = strings.TrimSpace(requirement)
+	if requirement == "" || strings.HasPrefix(requirement, "${") {
+		return ""
+	}
+	return versionPattern.FindString(requirement)
+}
+
+var licenseAliases = map[string]string{
+	"mit license":                                 "MIT",
+	"the mit license":                             "MIT",
+	"the mit license (mit)":                       "MIT",
+	"apache 2":                                    "Apache-2.0",
+	"apache 2.0":                                  "Apache-2.0",
+	"apache-2":                                    "Apache-2.0",
+	"apache license 2.0":                          "Apache-2.0",
+	"apache license, version 2.0":                 "Apache-2.0",
+	"apache software license":                     "Apache-2.0",
+	"the apache license, version 2.0":             "Apache-2.0",
+	"the apache software license, version 2.0":    "Apache-2.0",
+	"asl 2.0":                                     "Apache-2.0",
+	"isc license":                                 "ISC",
+	"mozilla public license 2.0":                  "MPL-2.0",
+	"mozilla public license, version 2.0":         "MPL-2.0",
+	"eclipse public license 2.0":                  "EPL-2.0",
+	"eclipse public license - v 2.0":              "EPL-2.0",
+	"gnu general public license v3 (gplv3)":       "GPL-3.0",
+	"gnu lesser general public license v3":        "LGPL-3.0",
+	"gnu affero general public license v3":        "AGPL-3.0",
+	"bsd 3-clause license":                        "BSD-3-Clause",
+	"bsd 2-clause license":                        "BSD-2-Clause",
+	"the unlicense":                               "Unlicense",
+	"public domain":                               "Unlicense",
+	"unlicense":                                   "Unlicense",
+	"cc0 1.0 universal":                           "CC0-1.0",
+	"common development and distribution license": "CDDL-1.0",
+}
+
+var licenseSplitter = regexp.MustCompile(`\s+(?:OR|AND|WITH)\s+|[()/]`)
+
+// DetectLicenses returns the licenses declared by the package version.
+// Well known license names and SPDX expressions are normalized to SPDX identifiers.
+func DetectLicenses(pd *packages_model.PackageDescriptor) []string {
+	var declared []string
+	switch m := pd.Metadata.(type) {
+	case *npm.Metadata:
+		declared = []string{m.License}
+	case *cargo.Metadata:
+		declared = []string{m.License}
+	case *composer.Metadata:
+		declared = m.License
+	case *maven.Metadata:
+		declared = m.Licenses
+	case *pypi.Metadata:
+		declared = []string{m.License}
+	}
+
+	licenses := make([]string, 0, len(declared))
+	seen := make(map[string]bool)
+	add := func(l string) {
+		if l != "" && !seen[l] {
+			seen[l] = true
+			licenses = append(licenses, l)
+		}
+	}
+
+	for _, d := range declared {
+		d = strings.TrimSpace(d)
+		if l, ok := normalizeLicense(d); ok {
+			add(l)
+			continue
+		}
+		parts := licenseSplitter.Split(d, -1)
+		if len(parts) == 1 {
+			// keep unknown free text licenses as declared
+			add(d)
+			continue
+		}
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if l, ok := normalizeLicense(part); ok {
+				add(l)
+			} else {
+				add(part)
+			}
+		}
+	}
+	return licenses
+}
+
+func normalizeLicense(name string) (string, bool) {
+	if name == "" {
+		return "", false
+	}
+	if l, ok := licenseAliases[strings.ToLower(name)]; ok {
+		return l, true
+	}
+	for _, l := range repo_module.Licenses {
+		if strings.EqualFold(l, name) {
+			return l, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package scan
+
+import (
+	"testing"
+
+	packages_model "forgejo.org/models/packages"
+	"forgejo.org/modules/osv"
+	"forgejo.org/modules/packages/composer"
+	"forgejo.org/modules/packages/npm"
+	"forgejo.org/modules/packages/pypi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMinimumVersion(t *testing.T) {
+	cases := map[string]string{
+		"":              "",
+		"*":             "",
+		"${version}":    "",
+		"1.2.3":         "1.2.3",
+		"^1.2.3":        "1.2.3",
+		"~> 2.0":        "2.0",
+		">=2.0,<3":      "2.0",
+		"[1.0,2.0)":     "1.0",
+		"1.0.0-beta.1":  "1.0.0-beta.1",
+		">= 4.1.0 < 5":  "4.1.0",
+		"v3.1 || v4.0":  "3.1",
+		"latest":        "",
+		"== 2.31.0":     "2.31.0",
+		"workspace:^1":  "1",
+		"npm:foo@1.2.0": "1.2.0",
+	}
+	for req, expected := range cases {
+		assert.Equal(t, expected, MinimumVersion(req), "requirement %q", req)
+	}
+}
+
+func TestParseRequiresDist(t *testing.T) {
+	d := parseRequiresDist("requests (>=2.0)")
+	assert.Equal(t, &Dependency{Ecosystem: osv.EcosystemPyPI, Name: "requests", Requirement: ">=2.0"}, d)
+
+	d = parseRequiresDist(`urllib3[socks]>=1.26; python_version >= "3.8"`)
+	assert.Equal(t, &Dependency{Ecosystem: osv.EcosystemPyPI, Name: "urllib3", Requirement: ">=1.26"}, d)
+
+	d = parseRequiresDist("six")
+	assert.Equal(t, &Dependency{Ecosystem: osv.EcosystemPyPI, Name: "six", Requirement: ""}, d)
+
+	assert.Nil(t, parseRequiresDist(`pytest>=7; extra == "test"`))
+	assert.Nil(t, parseRequiresDist("!invalid"))
+}
+
+func TestExtractDependencies(t *testing.T) {
+	deps := ExtractDependencies(&packages_model.PackageDescriptor{
+		Metadata: &npm.Metadata{
+			Dependencies:         map[string]string{"lodash": "^4.17.0"},
+			OptionalDependencies: map[string]string{"fsevents": "2.3.2"},
+			DevelopmentDependencies: map[string]string{
+				"jest": "29.0.0",
+			},
+		},
+	})
+	assert.Equal(t, []*Dependency{
+		{Ecosystem: osv.EcosystemNpm, Name: "fsevents", Requirement: "2.3.2"},
+		{Ecosystem: osv.EcosystemNpm, Name: "lodash", Requirement: "^4.17.0"},
+	}, deps)
+
+	deps = ExtractDependencies(&packages_model.PackageDescriptor{
+		Metadata: &composer.Metadata{
+			Require: map[string]string{"php": ">=8.1", "ext-json": "*", "guzzlehttp/guzzle": "^7.0"},
+		},
+	})
+	assert.Equal(t, []*Dependency{
+		{Ecosystem: osv.EcosystemPackagist, Name: "guzzlehttp/guzzle", Requirement: "^7.0"},
+	}, deps)
+}
+
+func TestDetectLicenses(t *testing.T) {
+	licenses := DetectLicenses(&packages_model.PackageDescriptor{
+		Metadata: &pypi.Metadata{License: "MIT License"},
+	})
+	assert.Equal(t, []string{"MIT"}, licenses)
+
+	licenses = DetectLicenses(&packages_model.PackageDescriptor{
+		Metadata: &composer.Metadata{License: []string{"(MIT OR Apache-2.0)", "MIT"}},
+	})
+	assert.Equal(t, []string{"MIT", "Apache-2.0"}, licenses)
+
+	licenses = DetectLicenses(&packages_model.PackageDescriptor{
+		Metadata: &npm.Metadata{License: "SEE LICENSE IN LICENSE.txt"},
+	})
+	assert.Equal(t, []string{"SEE LICENSE IN LICENSE.txt"}, licenses)
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package scan
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"path"
+	"strings"
+
+	packages_model "forgejo.org/models/packages"
+	"forgejo.org/modules/json"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/osv"
+	"forgejo.org/modules/timeutil"
+)
+
+var supportedEcosystems = map[string]bool{
+	osv.EcosystemCargo:     true,
+	osv.EcosystemMaven:     true,
+	osv.EcosystemNpm:       true,
+	osv.EcosystemPackagist: true,
+	osv.EcosystemPyPI:      true,
+}
+
+// ImportResult contains statistics about an advisory import
+type ImportResult struct {
+	Records   int
+	Withdrawn int
+	Skipped   int
+}
+
+// ImportArchive imports all OSV records of a zip archive like the ones published at
+// https://osv-vulnerabilities.storage.googleapis.com/<ecosystem>/all.zip
+func ImportArchive(ctx context.Context, r io.ReaderAt, size int64) (*ImportResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !strings.EqualFold(path.Ext(f.Name), ".json") {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		default:
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return result, err
+		}
+		err = importRecord(ctx, rc, result)
+		rc.Close()
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// ImportRecord imports a single OSV record
+func ImportRecord(ctx context.Context, r io.Reader) (*ImportResult, error) {
+	result := &ImportResult{}
+	return result, importRecord(ctx, r, result)
+}
+
+func importRecord(ctx context.Context, r io.Reader, result *ImportResult) error {
+	rec, err := osv.ParseRecord(r)
+	if err != nil {
+		log.Warn("Skipping invalid OSV record: %v", err)
+		result.Skipped++
+		return nil
+	}
+
+	if rec.Withdrawn != nil {
+		result.Withdrawn++
+		return packages_model.DeleteAdvisoriesByAdvisoryID(ctx, rec.ID)
+	}
+
+	imported := false
+	for _, a := range rec.Affected {
+		if !supportedEcosystems[a.Package.Ecosystem] {
+			continue
+		}
+
+		affected, err := json.Marshal(a)
+		if err != nil {
+			return err
+		}
+
+		if err := packages_model.UpsertAdvisory(ctx, &packages_model.PackageAdvisory{
+			AdvisoryID:   rec.ID,
+			Ecosystem:    a.Package.Ecosystem,
+			PackageName:  osv.NormalizePackageName(a.Package.Ecosystem, a.Package.Name),
+			Aliases:      rec.Aliases,
+			Summary:      rec.Summary,
+			Severity:     rec.Severity(a),
+			URL:          rec.AdvisoryURL(),
+			AffectedJSON: string(affected),
+			ModifiedUnix: timeutil.TimeStamp(rec.Modified.Unix()),
+		}); err != nil {
+			return err
+		}
+		imported = true
+	}
+
+	if imported {
+		result.Records++
+	} else {
+		result.Skipped++
+	}
+	return nil
+}
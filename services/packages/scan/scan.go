@@ -0,0 +1,182 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package scan
+
+import (
+	"context"
+	"errors"
+
+	"forgejo.org/models/db"
+	packages_model "forgejo.org/models/packages"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/graceful"
+	"forgejo.org/modules/json"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/osv"
+	"forgejo.org/modules/queue"
+	notify_service "forgejo.org/services/notify"
+)
+
+// LicenseProperty is the name of the version property which stores a detected license
+const LicenseProperty = "scan.license"
+
+// ScannableTypes are the package types whose metadata contains dependencies or licenses
+var ScannableTypes = []packages_model.Type{
+	packages_model.TypeCargo,
+	packages_model.TypeComposer,
+	packages_model.TypeMaven,
+	packages_model.TypeNpm,
+	packages_model.TypePyPI,
+}
+
+var scanQueue *queue.WorkerPoolQueue[int64]
+
+// Init starts the queue which scans new package versions
+func Init() error {
+	scanQueue = queue.CreateUniqueQueue(graceful.GetManager().ShutdownContext(), "package_scan", handler)
+	if scanQueue == nil {
+		return errors.New("unable to create package_scan queue")
+	}
+	go graceful.GetManager().RunWithCancel(scanQueue)
+
+	notify_service.RegisterNotifier(&scanNotifier{})
+	return nil
+}
+
+func handler(items ...int64) []int64 {
+	ctx := graceful.GetManager().ShutdownContext()
+	for _, versionID := range items {
+		pv, err := packages_model.GetVersionByID(ctx, versionID)
+		if err != nil {
+			if !errors.Is(err, packages_model.ErrPackageNotExist) {
+				log.Error("GetVersionByID[%d]: %v", versionID, err)
+			}
+			continue
+		}
+		if err := ScanPackageVersion(ctx, pv); err != nil {
+			log.Error("ScanPackageVersion[%d]: %v", versionID, err)
+		}
+	}
+	return nil
+}
+
+type scanNotifier struct {
+	notify_service.NullNotifier
+}
+
+func (n *scanNotifier) PackageCreate(ctx context.Context, doer *user_model.User, pd *packages_model.PackageDescriptor) {
+	if !isScannable(pd.Package.Type) {
+		return
+	}
+	if err := scanQueue.Push(pd.Version.ID); err != nil {
+		log.Error("Unable to push package version %d to the package_scan queue: %v", pd.Version.ID, err)
+	}
+}
+
+func isScannable(t packages_model.Type) bool {
+	for _, st := range ScannableTypes {
+		if st == t {
+			return true
+		}
+	}
+	return false
+}
+
+// ScanPackageVersion matches the dependencies of the package version against the imported
+// advisories and records the findings together with the detected licenses.
+func ScanPackageVersion(ctx context.Context, pv *packages_model.PackageVersion) error {
+	pd, err := packages_model.GetPackageDescriptor(ctx, pv)
+	if err != nil {
+		return err
+	}
+
+	vulnerabilities, err := findVulnerabilities(ctx, ExtractDependencies(pd))
+	if err != nil {
+		return err
+	}
+	licenses := DetectLicenses(pd)
+
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if err := packages_model.ReplaceVulnerabilities(ctx, pv.ID, vulnerabilities); err != nil {
+			return err
+		}
+		if err := packages_model.DeletePropertyByName(ctx, packages_model.PropertyTypeVersion, pv.ID, LicenseProperty); err != nil {
+			return err
+		}
+		for _, l := range licenses {
+			if _, err := packages_model.InsertProperty(ctx, packages_model.PropertyTypeVersion, pv.ID, LicenseProperty, l); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func findVulnerabilities(ctx context.Context, deps []*Dependency) ([]*packages_model.PackageVulnerability, error) {
+	vulnerabilities := make([]*packages_model.PackageVulnerability, 0, 2)
+	for _, dep := range deps {
+		v := MinimumVersion(dep.Requirement)
+		if v == "" {
+			continue
+		}
+
+		advisories, err := packages_model.GetAdvisoriesByPackageName(ctx, dep.Ecosystem, osv.NormalizePackageName(dep.Ecosystem, dep.Name))
+		if err != nil {
+			return nil, err
+		}
+		for _, pa := range advisories {
+			affected := &osv.Affected{}
+			if err := json.Unmarshal([]byte(pa.AffectedJSON), affected); err != nil {
+				log.Warn("Invalid affected ranges of advisory %s: %v", pa.AdvisoryID, err)
+				continue
+			}
+			if !affected.IsVersionAffected(v) {
+				continue
+			}
+			vulnerabilities = append(vulnerabilities, &packages_model.PackageVulnerability{
+				AdvisoryID:            pa.AdvisoryID,
+				DependencyName:        dep.Name,
+				DependencyRequirement: dep.Requirement,
+				Severity:              pa.Severity,
+				Summary:               pa.Summary,
+				URL:                   pa.URL,
+			})
+		}
+	}
+	return vulnerabilities, nil
+}
+
+// ScanAll scans all versions of the scannable package types, e.g. after new advisories were imported
+func ScanAll(ctx context.Context) error {
+	for _, t := range ScannableTypes {
+		pvs, _, err := packages_model.SearchVersions(ctx, &packages_model.PackageSearchOptions{
+			Type: t,
+		})
+		if err != nil {
+			return err
+		}
+		for _, pv := range pvs {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+			if err := ScanPackageVersion(ctx, pv); err != nil {
+				log.Error("ScanPackageVersion[%d]: %v", pv.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Licenses returns the licenses detected for the package version
+func Licenses(pd *packages_model.PackageDescriptor) []string {
+	licenses := make([]string, 0, 1)
+	for _, pp := range pd.VersionProperties {
+		if pp.Name == LicenseProperty {
+			licenses = append(licenses, pp.Value)
+		}
+	}
+	return licenses
+}
@@ -0,0 +1,163 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package virtual
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	packages_model "forgejo.org/models/packages"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/hostmatcher"
+	"forgejo.org/modules/proxy"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/util"
+	"forgejo.org/modules/validation"
+)
+
+var (
+	ErrInvalidRegistryName = util.NewInvalidArgumentErrorf("virtual registry name is invalid")
+	ErrUnsupportedType     = util.NewInvalidArgumentErrorf("package type is not supported by virtual registries")
+	ErrInvalidUpstreamURL  = util.NewInvalidArgumentErrorf("upstream url is invalid")
+	ErrNoMembers           = util.NewInvalidArgumentErrorf("virtual registry needs at least one member or an upstream url")
+	ErrNoUpstream          = errors.New("virtual registry has no upstream")
+)
+
+// RegistryOptions describes the settings of a virtual registry
+type RegistryOptions struct {
+	Name        string
+	Type        packages_model.Type
+	Members     []*user_model.User
+	UpstreamURL string
+}
+
+func (opts *RegistryOptions) validate() error {
+	if !packages_model.IsVirtualRegistryType(opts.Type) {
+		return ErrUnsupportedType
+	}
+	if !validation.IsValidUsername(opts.Name) {
+		return ErrInvalidRegistryName
+	}
+	opts.UpstreamURL = strings.TrimSuffix(strings.TrimSpace(opts.UpstreamURL), "/")
+	if opts.UpstreamURL != "" && !validation.IsValidExternalURL(opts.UpstreamURL) {
+		return ErrInvalidUpstreamURL
+	}
+	if len(opts.Members) == 0 && opts.UpstreamURL == "" {
+		return ErrNoMembers
+	}
+	return nil
+}
+
+func memberIDs(members []*user_model.User) []int64 {
+	ids := make([]int64, 0, len(members))
+	for _, m := range members {
+		if !slices.Contains(ids, m.ID) {
+			ids = append(ids, m.ID)
+		}
+	}
+	return ids
+}
+
+// CreateRegistry creates a new virtual registry for the owner
+func CreateRegistry(ctx context.Context, owner *user_model.User, opts *RegistryOptions) (*packages_model.PackageVirtualRegistry, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	return packages_model.InsertVirtualRegistry(ctx, &packages_model.PackageVirtualRegistry{
+		OwnerID:     owner.ID,
+		Type:        opts.Type,
+		Name:        opts.Name,
+		MemberIDs:   memberIDs(opts.Members),
+		UpstreamURL: opts.UpstreamURL,
+	})
+}
+
+// UpdateRegistry replaces the members and the upstream of a virtual registry
+func UpdateRegistry(ctx context.Context, vr *packages_model.PackageVirtualRegistry, opts *RegistryOptions) error {
+	opts.Name = vr.Name
+	opts.Type = vr.Type
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	vr.MemberIDs = memberIDs(opts.Members)
+	vr.UpstreamURL = opts.UpstreamURL
+	return packages_model.UpdateVirtualRegistry(ctx, vr)
+}
+
+// GetMembers returns the owners aggregated by the virtual registry in resolution order.
+// Members which do not exist anymore are skipped.
+func GetMembers(ctx context.Context, vr *packages_model.PackageVirtualRegistry) ([]*user_model.User, error) {
+	users, err := user_model.GetUsersByIDs(ctx, vr.MemberIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*user_model.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+
+	members := make([]*user_model.User, 0, len(vr.MemberIDs))
+	for _, id := range vr.MemberIDs {
+		if u, ok := byID[id]; ok {
+			members = append(members, u)
+		}
+	}
+	return members, nil
+}
+
+var (
+	upstreamClient     *http.Client
+	upstreamClientOnce sync.Once
+)
+
+func getUpstreamClient() *http.Client {
+	upstreamClientOnce.Do(func() {
+		allowList := hostmatcher.ParseHostMatchList("packages.VIRTUAL_REGISTRY", hostmatcher.MatchBuiltinExternal)
+		upstreamClient = &http.Client{
+			Timeout: 5 * time.Minute,
+			Transport: &http.Transport{
+				Proxy:       proxy.Proxy(),
+				DialContext: hostmatcher.NewDialContext("package upstream", allowList, nil, setting.Proxy.ProxyURLFixed),
+			},
+		}
+	})
+	return upstreamClient
+}
+
+// FetchUpstream requests the path from the upstream registry of the virtual registry.
+// The caller is responsible for closing the response body.
+func FetchUpstream(ctx context.Context, vr *packages_model.PackageVirtualRegistry, method, path, rawQuery string, header http.Header) (*http.Response, error) {
+	if vr.UpstreamURL == "" {
+		return nil, ErrNoUpstream
+	}
+
+	u, err := url.Parse(vr.UpstreamURL + "/" + strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = rawQuery
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range []string{"Accept", "Accept-Encoding", "If-None-Match", "If-Modified-Since", "Range"} {
+		if v := header.Get(h); v != "" {
+			req.Header.Set(h, v)
+		}
+	}
+	req.Header.Set("User-Agent", fmt.Sprintf("Forgejo/%s", setting.AppVer))
+
+	return getUpstreamClient().Do(req)
+}
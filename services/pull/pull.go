@@ -149,6 +149,7 @@ func NewPullRequest(ctx context.Context, repo *repo_model.Repository, issue *iss
 	if err != nil {
 		return err
 	}
+	issue_service.RevokeIssueAccessTokens(ctx, issue)
 	notify_service.NewPullRequest(ctx, pr, mentions)
 	if len(issue.Labels) > 0 {
 		notify_service.IssueChangeLabels(ctx, issue.Poster, issue, issue.Labels, nil)
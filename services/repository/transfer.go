@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"forgejo.org/models"
+	audit_model "forgejo.org/models/audit"
 	"forgejo.org/models/db"
 	issues_model "forgejo.org/models/issues"
 	"forgejo.org/models/organization"
@@ -21,6 +22,7 @@ import (
 	repo_module "forgejo.org/modules/repository"
 	"forgejo.org/modules/sync"
 	"forgejo.org/modules/util"
+	audit_service "forgejo.org/services/audit"
 	notify_service "forgejo.org/services/notify"
 )
 
@@ -59,6 +61,11 @@ func TransferOwnership(ctx context.Context, doer, newOwner *user_model.User, rep
 		}
 	}
 
+	// the repository left the old owner, its organization owners must be able to see where it went
+	target := audit_service.RepoTarget(newRepo)
+	target.OwnerID = oldOwner.ID
+	audit_service.Record(ctx, doer, audit_model.ActionRepoTransfer, target, map[string]string{"owner": oldOwner.Name}, map[string]string{"owner": newOwner.Name})
+
 	notify_service.TransferRepository(ctx, doer, repo, oldOwner.Name)
 
 	return nil
@@ -0,0 +1,160 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package scim
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"forgejo.org/modules/json"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/scim"
+	"forgejo.org/modules/setting"
+)
+
+// normalizeWindow limits the 1-based start index and the number of resources of a list request
+func normalizeWindow(startIndex, count int) (int, int) {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 || count > setting.SCIM.MaxResults {
+		count = setting.SCIM.MaxResults
+	}
+	return startIndex, count
+}
+
+// resolveBulkIDs replaces references to resources created earlier in a bulk request by their ids
+func resolveBulkIDs(s string, ids map[string]string) string {
+	for bulkID, id := range ids {
+		s = strings.ReplaceAll(s, "bulkId:"+bulkID, id)
+	}
+	return s
+}
+
+func decodeBulkData(data []byte, v any) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidSyntax, "%v", err)
+	}
+	return nil
+}
+
+// processBulkOperation runs an operation of a bulk request and returns the HTTP status and the affected resource
+func processBulkOperation(ctx context.Context, method, path string, data []byte) (int, string, error) {
+	resourceType, id, _ := strings.Cut(strings.Trim(path, "/"), "/")
+	if strings.Contains(id, "bulkId:") {
+		return 0, "", scim.NewError(http.StatusConflict, scim.ErrorTypeInvalidValue, "unresolved reference in %s", path)
+	}
+
+	var err error
+	switch {
+	case resourceType == "Users" && method == http.MethodPost && id == "":
+		in := &scim.User{}
+		if err = decodeBulkData(data, in); err == nil {
+			var u *scim.User
+			if u, err = CreateUser(ctx, in); err == nil {
+				return http.StatusCreated, u.ID, nil
+			}
+		}
+	case resourceType == "Groups" && method == http.MethodPost && id == "":
+		in := &scim.Group{}
+		if err = decodeBulkData(data, in); err == nil {
+			var g *scim.Group
+			if g, err = CreateGroup(ctx, in); err == nil {
+				return http.StatusCreated, g.ID, nil
+			}
+		}
+	case id == "":
+		err = scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidPath, "invalid path %s", path)
+	case resourceType == "Users" && method == http.MethodPut:
+		in := &scim.User{}
+		if err = decodeBulkData(data, in); err == nil {
+			_, err = ReplaceUser(ctx, id, in)
+		}
+	case resourceType == "Users" && method == http.MethodPatch:
+		patch := &scim.PatchRequest{}
+		if err = decodeBulkData(data, patch); err == nil {
+			_, err = PatchUser(ctx, id, patch)
+		}
+	case resourceType == "Users" && method == http.MethodDelete:
+		if err = DeleteUser(ctx, id); err == nil {
+			return http.StatusNoContent, id, nil
+		}
+	case resourceType == "Groups" && method == http.MethodPut:
+		in := &scim.Group{}
+		if err = decodeBulkData(data, in); err == nil {
+			_, err = ReplaceGroup(ctx, id, in)
+		}
+	case resourceType == "Groups" && method == http.MethodPatch:
+		patch := &scim.PatchRequest{}
+		if err = decodeBulkData(data, patch); err == nil {
+			_, err = PatchGroup(ctx, id, patch)
+		}
+	case resourceType == "Groups" && method == http.MethodDelete:
+		if err = DeleteGroup(ctx, id); err == nil {
+			return http.StatusNoContent, id, nil
+		}
+	default:
+		err = scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidPath, "unsupported operation %s %s", method, path)
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	return http.StatusOK, id, nil
+}
+
+// ProcessBulk runs the operations of a bulk request in order.
+// Processing stops once the number of failed operations reaches failOnErrors.
+func ProcessBulk(ctx context.Context, req *scim.BulkRequest) (*scim.BulkResponse, error) {
+	if len(req.Operations) > setting.SCIM.MaxBulkOperations {
+		return nil, scim.NewError(http.StatusRequestEntityTooLarge, scim.ErrorTypeTooMany, "the number of operations exceeds the maximum of %d", setting.SCIM.MaxBulkOperations)
+	}
+
+	response := &scim.BulkResponse{
+		Schemas:    []string{scim.SchemaBulkResponse},
+		Operations: make([]scim.BulkOperation, 0, len(req.Operations)),
+	}
+	ids := make(map[string]string, len(req.Operations))
+	errorCount := 0
+	for _, operation := range req.Operations {
+		method := strings.ToUpper(operation.Method)
+		path := resolveBulkIDs(operation.Path, ids)
+		var data []byte
+		if operation.Data != nil {
+			var err error
+			if data, err = json.Marshal(operation.Data); err != nil {
+				return nil, err
+			}
+			data = []byte(resolveBulkIDs(string(data), ids))
+		}
+
+		result := scim.BulkOperation{Method: operation.Method, BulkID: operation.BulkID}
+		status, id, err := processBulkOperation(ctx, method, path, data)
+		if err != nil {
+			var scimErr *scim.Error
+			if !errors.As(err, &scimErr) {
+				log.Error("SCIM bulk operation %s %s failed: %v", method, path, err)
+				scimErr = scim.NewError(http.StatusInternalServerError, "", "internal error")
+			}
+			result.Status = scimErr.Status
+			result.Response = scimErr
+			errorCount++
+		} else {
+			resourceType, _, _ := strings.Cut(strings.Trim(path, "/"), "/")
+			result.Location = setting.AppURL + "scim/v2/" + resourceType + "/" + id
+			result.Status = strconv.Itoa(status)
+			if operation.BulkID != "" {
+				ids[operation.BulkID] = id
+			}
+		}
+		response.Operations = append(response.Operations, result)
+
+		if req.FailOnErrors > 0 && errorCount >= req.FailOnErrors {
+			break
+		}
+	}
+	return response, nil
+}
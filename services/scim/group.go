@@ -0,0 +1,402 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package scim
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	"forgejo.org/models/organization"
+	"forgejo.org/models/perm"
+	"forgejo.org/models/unit"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/scim"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/util"
+	org_service "forgejo.org/services/org"
+
+	"xorm.io/builder"
+)
+
+func groupLocation(id int64) string {
+	return setting.AppURL + "scim/v2/Groups/" + strconv.FormatInt(id, 10)
+}
+
+// groupError converts the errors of team operations caused by the request
+func groupError(err error) error {
+	switch {
+	case organization.IsErrTeamAlreadyExist(err):
+		return scim.NewError(http.StatusConflict, scim.ErrorTypeUniqueness, "%v", err)
+	case organization.IsErrLastOrgOwner(err), db.IsErrNameReserved(err), db.IsErrNamePatternNotAllowed(err),
+		errors.Is(err, util.ErrInvalidArgument):
+		return scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, "%v", err)
+	}
+	return err
+}
+
+// groupDisplayName returns the display name of a team, which is qualified by the name of its organization
+func groupDisplayName(ctx context.Context, team *organization.Team) (string, error) {
+	org, err := user_model.GetUserByID(ctx, team.OrgID)
+	if err != nil {
+		return "", err
+	}
+	return org.Name + "/" + team.Name, nil
+}
+
+// splitGroupDisplayName returns the organization and team of a display name like "org/team"
+func splitGroupDisplayName(displayName string) (string, string, error) {
+	orgName, teamName, ok := strings.Cut(displayName, "/")
+	if !ok || orgName == "" || teamName == "" {
+		return "", "", scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, "displayName %q is not of the form organization/team", displayName)
+	}
+	return orgName, teamName, nil
+}
+
+// getTeam returns the team with the SCIM id
+func getTeam(ctx context.Context, id string) (*organization.Team, error) {
+	teamID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, scim.NewNotFoundError(scim.ResourceTypeGroup, id)
+	}
+	team, err := organization.GetTeamByID(ctx, teamID)
+	if err != nil {
+		if organization.IsErrTeamNotExist(err) {
+			return nil, scim.NewNotFoundError(scim.ResourceTypeGroup, id)
+		}
+		return nil, err
+	}
+	return team, nil
+}
+
+// toGroup converts a team to a SCIM Group, the members are only listed if withMembers is set
+func toGroup(ctx context.Context, team *organization.Team, resource *auth_model.SCIMResource, withMembers bool) (*scim.Group, error) {
+	displayName, err := groupDisplayName(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+	result := &scim.Group{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          strconv.FormatInt(team.ID, 10),
+		DisplayName: displayName,
+		Meta: &scim.Meta{
+			ResourceType: scim.ResourceTypeGroup,
+			Location:     groupLocation(team.ID),
+		},
+	}
+	if resource != nil {
+		result.ExternalID = resource.ExternalID
+	}
+
+	if withMembers {
+		if err := team.LoadMembers(ctx); err != nil {
+			return nil, err
+		}
+		for _, member := range team.Members {
+			result.Members = append(result.Members, scim.MultiValue{
+				Value:   strconv.FormatInt(member.ID, 10),
+				Display: member.Name,
+				Ref:     userLocation(member.ID),
+			})
+		}
+	}
+	return result, nil
+}
+
+// GetGroup returns the SCIM Group with the id
+func GetGroup(ctx context.Context, id string) (*scim.Group, error) {
+	team, err := getTeam(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := auth_model.GetSCIMResource(ctx, auth_model.SCIMResourceTypeGroup, team.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toGroup(ctx, team, resource, true)
+}
+
+// findGroupCandidates returns the ids of the only teams which can match a filter,
+// or nil if all teams have to be matched against it
+func findGroupCandidates(ctx context.Context, filter scim.Filter) ([]int64, error) {
+	comparison, ok := filter.(*scim.Comparison)
+	if !ok || comparison.Operator != scim.OperatorEqual || comparison.SubAttribute != "" {
+		return nil, nil
+	}
+	value, ok := comparison.Value.(string)
+	if !ok {
+		return nil, nil
+	}
+	switch strings.ToLower(comparison.Attribute) {
+	case "id":
+		id, _ := strconv.ParseInt(value, 10, 64)
+		return []int64{id}, nil
+	case "displayname":
+		orgName, teamName, err := splitGroupDisplayName(value)
+		if err != nil {
+			return []int64{}, nil
+		}
+		org, err := organization.GetOrgByName(ctx, orgName)
+		if err != nil {
+			if organization.IsErrOrgNotExist(err) {
+				return []int64{}, nil
+			}
+			return nil, err
+		}
+		team, err := org.GetTeam(ctx, teamName)
+		if err != nil {
+			if organization.IsErrTeamNotExist(err) {
+				return []int64{}, nil
+			}
+			return nil, err
+		}
+		return []int64{team.ID}, nil
+	case "externalid":
+		return auth_model.FindSCIMResourceIDsByExternalID(ctx, auth_model.SCIMResourceTypeGroup, value)
+	}
+	return nil, nil
+}
+
+// ListGroups returns count teams matching the filter, starting at the 1-based startIndex
+func ListGroups(ctx context.Context, filter string, startIndex, count int) (*scim.ListResponse, error) {
+	startIndex, count = normalizeWindow(startIndex, count)
+
+	cond := builder.NewCond()
+	var f scim.Filter
+	if filter != "" {
+		var err error
+		if f, err = scim.ParseFilter(filter); err != nil {
+			return nil, err
+		}
+		ids, err := findGroupCandidates(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+		if ids != nil {
+			cond = cond.And(builder.In("id", ids))
+		}
+	}
+
+	page := make([]*organization.Team, 0, count)
+	var total int64
+	err := db.Iterate(ctx, cond, func(ctx context.Context, team *organization.Team) error {
+		if f != nil {
+			resource, err := auth_model.GetSCIMResource(ctx, auth_model.SCIMResourceTypeGroup, team.ID)
+			if err != nil {
+				return err
+			}
+			group, err := toGroup(ctx, team, resource, false)
+			if err != nil {
+				return err
+			}
+			m, err := scim.ToMap(group)
+			if err != nil {
+				return err
+			}
+			if !f.Match(m) {
+				return nil
+			}
+		}
+		total++
+		if total >= int64(startIndex) && len(page) < count {
+			page = append(page, team)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(page))
+	for _, team := range page {
+		ids = append(ids, team.ID)
+	}
+	resources, err := auth_model.FindSCIMResourcesByIDs(ctx, auth_model.SCIMResourceTypeGroup, ids)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]any, 0, len(page))
+	for _, team := range page {
+		group, err := toGroup(ctx, team, resources[team.ID], true)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, group)
+	}
+	return scim.NewListResponse(total, startIndex, result), nil
+}
+
+// syncMembers makes the members of the group the members of the team
+func syncMembers(ctx context.Context, team *organization.Team, members []scim.MultiValue) error {
+	wanted := make(map[int64]bool, len(members))
+	for _, member := range members {
+		u, err := getUser(ctx, member.Value)
+		if err != nil {
+			return scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, "member %s is not a user", member.Value)
+		}
+		wanted[u.ID] = true
+	}
+
+	if err := team.LoadMembers(ctx); err != nil {
+		return err
+	}
+	current := make(map[int64]bool, len(team.Members))
+	for _, member := range team.Members {
+		current[member.ID] = true
+	}
+
+	for userID := range wanted {
+		if current[userID] {
+			continue
+		}
+		if err := org_service.AddTeamMember(ctx, nil, team, userID); err != nil {
+			return groupError(err)
+		}
+	}
+	for userID := range current {
+		if wanted[userID] {
+			continue
+		}
+		if err := org_service.RemoveTeamMember(ctx, nil, team, userID); err != nil {
+			return groupError(err)
+		}
+	}
+	return nil
+}
+
+// CreateGroup creates a team with read access in the organization named by the display name of the group
+func CreateGroup(ctx context.Context, in *scim.Group) (*scim.Group, error) {
+	orgName, teamName, err := splitGroupDisplayName(in.DisplayName)
+	if err != nil {
+		return nil, err
+	}
+	org, err := organization.GetOrgByName(ctx, orgName)
+	if err != nil {
+		if organization.IsErrOrgNotExist(err) {
+			return nil, scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, "organization %s does not exist", orgName)
+		}
+		return nil, err
+	}
+
+	team := &organization.Team{
+		OrgID:      org.ID,
+		Name:       teamName,
+		AccessMode: perm.AccessModeRead,
+	}
+	for _, tp := range unit.AllRepoUnitTypes {
+		team.Units = append(team.Units, &organization.TeamUnit{
+			OrgID:      org.ID,
+			Type:       tp,
+			AccessMode: perm.AccessModeRead,
+		})
+	}
+	if err := org_service.NewTeam(ctx, nil, team); err != nil {
+		return nil, groupError(err)
+	}
+	if err := auth_model.SetSCIMResource(ctx, auth_model.SCIMResourceTypeGroup, team.ID, in.ExternalID); err != nil {
+		return nil, err
+	}
+	if err := syncMembers(ctx, team, in.Members); err != nil {
+		return nil, err
+	}
+
+	log.Trace("Team %s provisioned with SCIM", in.DisplayName)
+	return GetGroup(ctx, strconv.FormatInt(team.ID, 10))
+}
+
+// updateGroup applies the attributes of a SCIM Group to a team
+func updateGroup(ctx context.Context, team *organization.Team, in *scim.Group) error {
+	orgName, teamName, err := splitGroupDisplayName(in.DisplayName)
+	if err != nil {
+		return err
+	}
+	displayName, err := groupDisplayName(ctx, team)
+	if err != nil {
+		return err
+	}
+	if currentOrgName, _, _ := strings.Cut(displayName, "/"); !strings.EqualFold(currentOrgName, orgName) {
+		return scim.NewError(http.StatusBadRequest, scim.ErrorTypeMutability, "a group can not be moved to another organization")
+	}
+	if teamName != team.Name {
+		if team.IsOwnerTeam() {
+			return scim.NewError(http.StatusBadRequest, scim.ErrorTypeMutability, "the owners team can not be renamed")
+		}
+		team.Name = teamName
+		if err := org_service.UpdateTeam(ctx, nil, team, false, false); err != nil {
+			return groupError(err)
+		}
+	}
+
+	if err := auth_model.SetSCIMResource(ctx, auth_model.SCIMResourceTypeGroup, team.ID, in.ExternalID); err != nil {
+		return err
+	}
+	return syncMembers(ctx, team, in.Members)
+}
+
+// ReplaceGroup replaces the name and members of a team
+func ReplaceGroup(ctx context.Context, id string, in *scim.Group) (*scim.Group, error) {
+	team, err := getTeam(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := updateGroup(ctx, team, in); err != nil {
+		return nil, err
+	}
+	return GetGroup(ctx, id)
+}
+
+// PatchGroup modifies the name and members of a team
+func PatchGroup(ctx context.Context, id string, patch *scim.PatchRequest) (*scim.Group, error) {
+	team, err := getTeam(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := auth_model.GetSCIMResource(ctx, auth_model.SCIMResourceTypeGroup, team.ID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := toGroup(ctx, team, resource, true)
+	if err != nil {
+		return nil, err
+	}
+	m, err := scim.ToMap(current)
+	if err != nil {
+		return nil, err
+	}
+	for _, operation := range patch.Operations {
+		if err := scim.ApplyPatch(m, operation); err != nil {
+			return nil, err
+		}
+	}
+
+	updated := &scim.Group{}
+	if err := scim.FromMap(m, updated); err != nil {
+		return nil, err
+	}
+	if err := updateGroup(ctx, team, updated); err != nil {
+		return nil, err
+	}
+	return GetGroup(ctx, id)
+}
+
+// DeleteGroup deletes a team
+func DeleteGroup(ctx context.Context, id string) error {
+	team, err := getTeam(ctx, id)
+	if err != nil {
+		return err
+	}
+	if team.IsOwnerTeam() {
+		return scim.NewError(http.StatusBadRequest, scim.ErrorTypeMutability, "the owners team can not be deleted")
+	}
+	if err := org_service.DeleteTeam(ctx, nil, team); err != nil {
+		return err
+	}
+	log.Trace("Team %d deleted with SCIM", team.ID)
+	return nil
+}
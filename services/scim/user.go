@@ -0,0 +1,349 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package scim
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	"forgejo.org/models/organization"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/optional"
+	"forgejo.org/modules/scim"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/util"
+	"forgejo.org/modules/validation"
+	user_service "forgejo.org/services/user"
+
+	"xorm.io/builder"
+)
+
+func userLocation(id int64) string {
+	return setting.AppURL + "scim/v2/Users/" + strconv.FormatInt(id, 10)
+}
+
+// userError converts the errors of user operations caused by the request
+func userError(err error) error {
+	switch {
+	case user_model.IsErrUserAlreadyExist(err), user_model.IsErrEmailAlreadyUsed(err):
+		return scim.NewError(http.StatusConflict, scim.ErrorTypeUniqueness, "%v", err)
+	case db.IsErrNameReserved(err), db.IsErrNameCharsNotAllowed(err), db.IsErrNamePatternNotAllowed(err),
+		validation.IsErrEmailInvalid(err), user_model.IsErrUserIsNotLocal(err), errors.Is(err, util.ErrInvalidArgument):
+		return scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, "%v", err)
+	}
+	return err
+}
+
+// getUser returns the individual user with the SCIM id
+func getUser(ctx context.Context, id string) (*user_model.User, error) {
+	userID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, scim.NewNotFoundError(scim.ResourceTypeUser, id)
+	}
+	u, err := user_model.GetUserByID(ctx, userID)
+	if err != nil {
+		if user_model.IsErrUserNotExist(err) {
+			return nil, scim.NewNotFoundError(scim.ResourceTypeUser, id)
+		}
+		return nil, err
+	}
+	if u.Type != user_model.UserTypeIndividual {
+		return nil, scim.NewNotFoundError(scim.ResourceTypeUser, id)
+	}
+	return u, nil
+}
+
+// toUser converts a user to a SCIM User, the groups are only listed if withGroups is set
+func toUser(ctx context.Context, u *user_model.User, resource *auth_model.SCIMResource, withGroups bool) (*scim.User, error) {
+	created, modified := u.CreatedUnix.AsTime(), u.UpdatedUnix.AsTime()
+	active := !u.ProhibitLogin
+	result := &scim.User{
+		Schemas:     []string{scim.SchemaUser},
+		ID:          strconv.FormatInt(u.ID, 10),
+		UserName:    u.Name,
+		DisplayName: u.FullName,
+		Active:      &active,
+		Meta: &scim.Meta{
+			ResourceType: scim.ResourceTypeUser,
+			Created:      &created,
+			LastModified: &modified,
+			Location:     userLocation(u.ID),
+		},
+	}
+	if u.FullName != "" {
+		result.Name = &scim.Name{Formatted: u.FullName}
+	}
+	if u.Email != "" {
+		result.Emails = []scim.MultiValue{{Value: u.Email, Type: "work", Primary: true}}
+	}
+	if resource != nil {
+		result.ExternalID = resource.ExternalID
+	}
+
+	if withGroups {
+		teams, _, err := organization.SearchTeam(ctx, &organization.SearchTeamOptions{
+			ListOptions: db.ListOptionsAll,
+			UserID:      u.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, team := range teams {
+			displayName, err := groupDisplayName(ctx, team)
+			if err != nil {
+				return nil, err
+			}
+			result.Groups = append(result.Groups, scim.MultiValue{
+				Value:   strconv.FormatInt(team.ID, 10),
+				Display: displayName,
+				Ref:     groupLocation(team.ID),
+			})
+		}
+	}
+	return result, nil
+}
+
+// GetUser returns the SCIM User with the id
+func GetUser(ctx context.Context, id string) (*scim.User, error) {
+	u, err := getUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := auth_model.GetSCIMResource(ctx, auth_model.SCIMResourceTypeUser, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toUser(ctx, u, resource, true)
+}
+
+// findUserCandidates returns the ids of the only users which can match a filter,
+// or nil if all users have to be matched against it
+func findUserCandidates(ctx context.Context, filter scim.Filter) ([]int64, error) {
+	comparison, ok := filter.(*scim.Comparison)
+	if !ok || comparison.Operator != scim.OperatorEqual || comparison.SubAttribute != "" {
+		return nil, nil
+	}
+	value, ok := comparison.Value.(string)
+	if !ok {
+		return nil, nil
+	}
+	switch strings.ToLower(comparison.Attribute) {
+	case "id":
+		id, _ := strconv.ParseInt(value, 10, 64)
+		return []int64{id}, nil
+	case "username":
+		u, err := user_model.GetUserByName(ctx, value)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				return []int64{}, nil
+			}
+			return nil, err
+		}
+		return []int64{u.ID}, nil
+	case "externalid":
+		return auth_model.FindSCIMResourceIDsByExternalID(ctx, auth_model.SCIMResourceTypeUser, value)
+	}
+	return nil, nil
+}
+
+// ListUsers returns count users matching the filter, starting at the 1-based startIndex
+func ListUsers(ctx context.Context, filter string, startIndex, count int) (*scim.ListResponse, error) {
+	startIndex, count = normalizeWindow(startIndex, count)
+
+	cond := builder.NewCond().And(builder.Eq{"type": user_model.UserTypeIndividual})
+	var f scim.Filter
+	if filter != "" {
+		var err error
+		if f, err = scim.ParseFilter(filter); err != nil {
+			return nil, err
+		}
+		ids, err := findUserCandidates(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+		if ids != nil {
+			cond = cond.And(builder.In("id", ids))
+		}
+	}
+
+	page := make([]*user_model.User, 0, count)
+	var total int64
+	err := db.Iterate(ctx, cond, func(ctx context.Context, u *user_model.User) error {
+		if f != nil {
+			resource, err := auth_model.GetSCIMResource(ctx, auth_model.SCIMResourceTypeUser, u.ID)
+			if err != nil {
+				return err
+			}
+			scimUser, err := toUser(ctx, u, resource, false)
+			if err != nil {
+				return err
+			}
+			m, err := scim.ToMap(scimUser)
+			if err != nil {
+				return err
+			}
+			if !f.Match(m) {
+				return nil
+			}
+		}
+		total++
+		if total >= int64(startIndex) && len(page) < count {
+			page = append(page, u)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(page))
+	for _, u := range page {
+		ids = append(ids, u.ID)
+	}
+	resources, err := auth_model.FindSCIMResourcesByIDs(ctx, auth_model.SCIMResourceTypeUser, ids)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]any, 0, len(page))
+	for _, u := range page {
+		scimUser, err := toUser(ctx, u, resources[u.ID], true)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, scimUser)
+	}
+	return scim.NewListResponse(total, startIndex, result), nil
+}
+
+// CreateUser provisions a new user
+func CreateUser(ctx context.Context, in *scim.User) (*scim.User, error) {
+	if in.UserName == "" {
+		return nil, scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, "userName is required")
+	}
+	email := in.PrimaryEmail()
+	if email == "" {
+		return nil, scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, "an email address is required")
+	}
+
+	u := &user_model.User{
+		Name:          in.UserName,
+		FullName:      in.FullName(),
+		Email:         email,
+		Passwd:        in.Password,
+		ProhibitLogin: in.Active != nil && !*in.Active,
+	}
+	if err := user_model.AdminCreateUser(ctx, u, &user_model.CreateUserOverwriteOptions{IsActive: optional.Some(true)}); err != nil {
+		return nil, userError(err)
+	}
+	if err := auth_model.SetSCIMResource(ctx, auth_model.SCIMResourceTypeUser, u.ID, in.ExternalID); err != nil {
+		return nil, err
+	}
+
+	log.Trace("User %s provisioned with SCIM", u.Name)
+	return GetUser(ctx, strconv.FormatInt(u.ID, 10))
+}
+
+// updateUser applies the attributes of a SCIM User to a user
+func updateUser(ctx context.Context, u *user_model.User, in *scim.User) error {
+	if in.UserName == "" {
+		return scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, "userName is required")
+	}
+	if in.UserName != u.Name {
+		if err := user_service.AdminRenameUser(ctx, u, in.UserName); err != nil {
+			return userError(err)
+		}
+	}
+
+	if err := user_service.UpdateUser(ctx, u, &user_service.UpdateOptions{FullName: optional.Some(in.FullName())}); err != nil {
+		return userError(err)
+	}
+
+	if email := in.PrimaryEmail(); email != "" {
+		if err := user_service.AdminAddOrSetPrimaryEmailAddress(ctx, u, email); err != nil {
+			return userError(err)
+		}
+	}
+
+	authOpts := &user_service.UpdateAuthOptions{}
+	if in.Active != nil {
+		authOpts.ProhibitLogin = optional.Some(!*in.Active)
+	}
+	if in.Password != "" {
+		authOpts.Password = optional.Some(in.Password)
+	}
+	if err := user_service.UpdateAuth(ctx, u, authOpts); err != nil {
+		return scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, "%v", err)
+	}
+
+	return auth_model.SetSCIMResource(ctx, auth_model.SCIMResourceTypeUser, u.ID, in.ExternalID)
+}
+
+// ReplaceUser replaces the attributes of a user
+func ReplaceUser(ctx context.Context, id string, in *scim.User) (*scim.User, error) {
+	u, err := getUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := updateUser(ctx, u, in); err != nil {
+		return nil, err
+	}
+	return GetUser(ctx, id)
+}
+
+// PatchUser modifies the attributes of a user
+func PatchUser(ctx context.Context, id string, patch *scim.PatchRequest) (*scim.User, error) {
+	u, err := getUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := auth_model.GetSCIMResource(ctx, auth_model.SCIMResourceTypeUser, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := toUser(ctx, u, resource, false)
+	if err != nil {
+		return nil, err
+	}
+	m, err := scim.ToMap(current)
+	if err != nil {
+		return nil, err
+	}
+	for _, operation := range patch.Operations {
+		if err := scim.ApplyPatch(m, operation); err != nil {
+			return nil, err
+		}
+	}
+	// some identity providers send booleans as strings
+	if active, ok := m["active"].(string); ok {
+		m["active"] = strings.EqualFold(active, "true")
+	}
+
+	updated := &scim.User{}
+	if err := scim.FromMap(m, updated); err != nil {
+		return nil, err
+	}
+	if err := updateUser(ctx, u, updated); err != nil {
+		return nil, err
+	}
+	return GetUser(ctx, id)
+}
+
+// DeleteUser deactivates a user, the user and its content are kept
+func DeleteUser(ctx context.Context, id string) error {
+	u, err := getUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := user_service.UpdateAuth(ctx, u, &user_service.UpdateAuthOptions{ProhibitLogin: optional.Some(true)}); err != nil {
+		return err
+	}
+	log.Trace("User %s deactivated with SCIM", u.Name)
+	return nil
+}
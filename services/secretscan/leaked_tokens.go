@@ -0,0 +1,147 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package secretscan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	audit_model "forgejo.org/models/audit"
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	repo_model "forgejo.org/models/repo"
+	secretscan_model "forgejo.org/models/secretscan"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/container"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/secretscan"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/timeutil"
+	"forgejo.org/modules/util"
+	audit_service "forgejo.org/services/audit"
+	"forgejo.org/services/mailer"
+)
+
+// IsPublic returns whether the content of the repository can be read by anyone, tokens leaked in it must be revoked
+func IsPublic(ctx context.Context, repo *repo_model.Repository) bool {
+	if repo.IsPrivate {
+		return false
+	}
+	if err := repo.LoadOwner(ctx); err != nil {
+		log.Error("LoadOwner of %-v: %v", repo, err)
+		return false
+	}
+	return repo.Owner.Visibility.IsPublic()
+}
+
+// RevokeAccessTokensInContent revokes the access tokens found in the content of an issue or a comment
+// of the repository if it is public. The link to the content is sent to the owners of the tokens.
+func RevokeAccessTokensInContent(ctx context.Context, repo *repo_model.Repository, content, link string) {
+	if !setting.SecretScanning.RevokeLeakedTokens || content == "" || !IsPublic(ctx, repo) {
+		return
+	}
+	for _, m := range secretscan.Scan([]byte(content), []*secretscan.Detector{secretscan.AccessTokenDetector}) {
+		revokeAccessToken(ctx, m.Secret, link)
+	}
+}
+
+// pendingRevocationsTimeout is how long the access tokens found in a push are kept until the push is accepted,
+// pushes which are rejected by another hook are never accepted
+const pendingRevocationsTimeout = 7 * 24 * time.Hour
+
+// RecordPushedAccessTokens stores the access tokens found in the files pushed to the repository if it is public,
+// they are revoked by RevokePushedAccessTokens once the push is accepted.
+// The post-receive hook is only told about the updated refs, so the tokens are stored for each of their new commits.
+func RecordPushedAccessTokens(ctx context.Context, repo *repo_model.Repository, newCommitIDs []string, findings []*Finding) error {
+	if !setting.SecretScanning.RevokeLeakedTokens || !IsPublic(ctx, repo) {
+		return nil
+	}
+	if err := secretscan_model.DeletePendingRevocationsBefore(ctx, timeutil.TimeStamp(time.Now().Add(-pendingRevocationsTimeout).Unix())); err != nil {
+		return err
+	}
+
+	revocations := make([]*secretscan_model.PendingRevocation, 0, len(findings))
+	found := make(container.Set[string])
+	for _, f := range findings {
+		if f.Detector.ID != secretscan.AccessTokenDetector.ID || !found.Add(f.Secret) || !auth_model.IsAccessTokenFormat(f.Secret) {
+			continue
+		}
+		t, err := auth_model.GetAccessTokenBySHA(ctx, f.Secret)
+		if err != nil {
+			if auth_model.IsErrAccessTokenNotExist(err) {
+				continue
+			}
+			return err
+		}
+		link := fmt.Sprintf("%s/src/commit/%s/%s#L%d", repo.HTMLURL(), f.CommitID, util.PathEscapeSegments(f.Path), f.Line)
+		for _, commitID := range newCommitIDs {
+			revocations = append(revocations, &secretscan_model.PendingRevocation{
+				RepoID:   repo.ID,
+				CommitID: commitID,
+				TokenID:  t.ID,
+				Link:     link,
+			})
+		}
+	}
+	return secretscan_model.InsertPendingRevocations(ctx, revocations)
+}
+
+// RevokePushedAccessTokens revokes the access tokens which were stored for the new commits of an accepted push
+func RevokePushedAccessTokens(ctx context.Context, repo *repo_model.Repository, newCommitIDs []string) {
+	revocations, err := secretscan_model.TakePendingRevocations(ctx, repo.ID, newCommitIDs)
+	if err != nil {
+		log.Error("Unable to read the access tokens pushed to %-v: %v", repo, err)
+		return
+	}
+	for _, r := range revocations {
+		t, exist, err := db.GetByID[auth_model.AccessToken](ctx, r.TokenID)
+		if err != nil {
+			log.Error("Unable to read the access token %d pushed to %-v: %v", r.TokenID, repo, err)
+			continue
+		}
+		// the token was found in several pushed refs and has been revoked already
+		if !exist {
+			continue
+		}
+		revokeToken(ctx, t, r.Link)
+	}
+}
+
+// revokeAccessToken deletes the access token and informs its owner. Failing to revoke it is only logged,
+// it does not prevent the content from being published.
+func revokeAccessToken(ctx context.Context, token, link string) {
+	if !auth_model.IsAccessTokenFormat(token) {
+		return
+	}
+	t, err := auth_model.GetAccessTokenBySHA(ctx, token)
+	if err != nil {
+		if !auth_model.IsErrAccessTokenNotExist(err) {
+			log.Error("GetAccessTokenBySHA: %v", err)
+		}
+		return
+	}
+	revokeToken(ctx, t, link)
+}
+
+func revokeToken(ctx context.Context, t *auth_model.AccessToken, link string) {
+	if err := auth_model.DeleteAccessTokenByID(ctx, t.ID, t.UID); err != nil {
+		// the token was found in several pushed refs and has been revoked already
+		if !auth_model.IsErrAccessTokenNotExist(err) {
+			log.Error("Unable to revoke the leaked access token %d of user %d: %v", t.ID, t.UID, err)
+		}
+		return
+	}
+	log.Info("Revoked the access token %d of user %d found in %s", t.ID, t.UID, link)
+	audit_service.Record(ctx, nil, audit_model.ActionUserAccessTokenDelete, audit_service.AccessTokenTarget(t), nil, map[string]any{"leaked_in": link})
+
+	u, err := user_model.GetUserByID(ctx, t.UID)
+	if err != nil {
+		log.Error("GetUserByID[%d]: %v", t.UID, err)
+		return
+	}
+	if err := mailer.SendAccessTokenRevoked(u, t.Name, link); err != nil {
+		log.Error("SendAccessTokenRevoked: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package secretscan
+
+import (
+	"fmt"
+	"testing"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	repo_model "forgejo.org/models/repo"
+	secretscan_model "forgejo.org/models/secretscan"
+	"forgejo.org/models/unittest"
+	"forgejo.org/modules/secretscan"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/test"
+	"forgejo.org/modules/timeutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokeAccessTokensInContent(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	newToken := func(t *testing.T, name string) *auth_model.AccessToken {
+		t.Helper()
+		token := &auth_model.AccessToken{UID: 2, Name: name}
+		require.NoError(t, auth_model.NewAccessToken(db.DefaultContext, token))
+		return token
+	}
+	content := func(token *auth_model.AccessToken) string {
+		return fmt.Sprintf("It fails with `curl -H 'Authorization: token %s' ...`", token.Token)
+	}
+
+	publicRepo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	privateRepo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 2})
+	require.True(t, privateRepo.IsPrivate)
+
+	t.Run("Public", func(t *testing.T) {
+		token := newToken(t, "leaked")
+		RevokeAccessTokensInContent(db.DefaultContext, publicRepo, content(token), publicRepo.HTMLURL()+"/issues/1")
+		unittest.AssertNotExistsBean(t, &auth_model.AccessToken{ID: token.ID})
+	})
+
+	t.Run("Private", func(t *testing.T) {
+		token := newToken(t, "private")
+		RevokeAccessTokensInContent(db.DefaultContext, privateRepo, content(token), privateRepo.HTMLURL()+"/issues/1")
+		unittest.AssertExistsAndLoadBean(t, &auth_model.AccessToken{ID: token.ID})
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		defer test.MockVariableValue(&setting.SecretScanning.RevokeLeakedTokens, false)()
+		token := newToken(t, "disabled")
+		RevokeAccessTokensInContent(db.DefaultContext, publicRepo, content(token), publicRepo.HTMLURL()+"/issues/1")
+		unittest.AssertExistsAndLoadBean(t, &auth_model.AccessToken{ID: token.ID})
+	})
+
+	t.Run("Invalid checksum", func(t *testing.T) {
+		token := newToken(t, "checksum")
+		tampered := *token
+		tampered.Token = token.Token[:len(token.Token)-8] + "00000000"
+		RevokeAccessTokensInContent(db.DefaultContext, publicRepo, content(&tampered), publicRepo.HTMLURL()+"/issues/1")
+		unittest.AssertExistsAndLoadBean(t, &auth_model.AccessToken{ID: token.ID})
+	})
+}
+
+func TestRevokePushedAccessTokens(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	token := &auth_model.AccessToken{UID: 2, Name: "pushed"}
+	require.NoError(t, auth_model.NewAccessToken(db.DefaultContext, token))
+	findings := []*Finding{{
+		Match:    &secretscan.Match{Detector: secretscan.AccessTokenDetector, Secret: token.Token, Line: 1},
+		CommitID: "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		Path:     "ci.sh",
+	}}
+
+	// the tokens are only revoked once the push is accepted, until then they are stored in the database
+	require.NoError(t, RecordPushedAccessTokens(db.DefaultContext, repo, []string{"branch-tip", "tag-tip"}, findings))
+	unittest.AssertExistsAndLoadBean(t, &auth_model.AccessToken{ID: token.ID})
+	unittest.AssertCount(t, &secretscan_model.PendingRevocation{RepoID: repo.ID, TokenID: token.ID}, 2)
+
+	RevokePushedAccessTokens(db.DefaultContext, repo, []string{"branch-tip"})
+	unittest.AssertNotExistsBean(t, &auth_model.AccessToken{ID: token.ID})
+	unittest.AssertCount(t, &secretscan_model.PendingRevocation{RepoID: repo.ID, CommitID: "branch-tip"}, 0)
+
+	// the token found for another ref of the push has been revoked already
+	RevokePushedAccessTokens(db.DefaultContext, repo, []string{"tag-tip"})
+	RevokePushedAccessTokens(db.DefaultContext, repo, []string{"unknown"})
+	unittest.AssertCount(t, &secretscan_model.PendingRevocation{RepoID: repo.ID}, 0)
+
+	// the tokens of pushes which were never accepted are eventually deleted
+	require.NoError(t, db.Insert(db.DefaultContext, &secretscan_model.PendingRevocation{RepoID: repo.ID, CommitID: "rejected", TokenID: token.ID}))
+	require.NoError(t, secretscan_model.DeletePendingRevocationsBefore(db.DefaultContext, timeutil.TimeStampNow()+1))
+	unittest.AssertCount(t, &secretscan_model.PendingRevocation{RepoID: repo.ID}, 0)
+}
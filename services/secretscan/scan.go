@@ -0,0 +1,219 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package secretscan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	repo_model "forgejo.org/models/repo"
+	secretscan_model "forgejo.org/models/secretscan"
+	"forgejo.org/modules/base"
+	"forgejo.org/modules/git"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/secretscan"
+	"forgejo.org/modules/setting"
+)
+
+// Finding is a secret found in a file of a commit
+type Finding struct {
+	*secretscan.Match
+	CommitID string
+	Path     string
+}
+
+// Fingerprint identifies the secret in the file without revealing it
+func (f *Finding) Fingerprint() string {
+	h := sha256.Sum256([]byte(f.Detector.ID + "\x00" + f.Path + "\x00" + f.Secret))
+	return hex.EncodeToString(h[:])
+}
+
+// Detectors returns the default detectors which are not disabled and the configured ones
+func Detectors() []*secretscan.Detector {
+	detectors := make([]*secretscan.Detector, 0, len(secretscan.DefaultDetectors)+len(setting.SecretScanning.Detectors))
+	for _, d := range secretscan.DefaultDetectors {
+		if !slices.Contains(setting.SecretScanning.DisabledDetectors, d.ID) {
+			detectors = append(detectors, d)
+		}
+	}
+	for _, cfg := range setting.SecretScanning.Detectors {
+		d, err := secretscan.NewDetector(cfg.ID, cfg.Description, cfg.Pattern, cfg.MinEntropy)
+		if err != nil {
+			// the patterns are validated when the settings are loaded
+			log.Error("Invalid secret scanning detector %q: %v", cfg.ID, err)
+			continue
+		}
+		detectors = append(detectors, d)
+	}
+	return detectors
+}
+
+// ScanPush scans the files added or modified by the pushed commits which are not in the repository yet with the detectors.
+// The env must give access to the quarantined objects of the push.
+func ScanPush(ctx context.Context, repo *repo_model.Repository, newCommitIDs, env []string, detectors []*secretscan.Detector) ([]*Finding, error) {
+	cmd := git.NewCommand(ctx, "log").AddDynamicArguments(newCommitIDs...).AddArguments("--not", "--all")
+	return scanCommits(ctx, cmd, repo.RepoPath(), env, detectors)
+}
+
+// ScanHistory scans the files of all the commits of the repository
+func ScanHistory(ctx context.Context, repo *repo_model.Repository) ([]*Finding, error) {
+	return scanCommits(ctx, git.NewCommand(ctx, "log", "--all"), repo.RepoPath(), nil, Detectors())
+}
+
+// blobOrigin is the file and commit which introduced a blob
+type blobOrigin struct {
+	commitID string
+	path     string
+}
+
+func scanCommits(ctx context.Context, logCmd *git.Command, repoPath string, env []string, detectors []*secretscan.Detector) ([]*Finding, error) {
+	// list the blobs of the files added or modified by each commit, from the most recent to the oldest commit
+	stdout, _, err := logCmd.AddArguments("--format=%H", "--raw", "--no-abbrev", "--no-renames", "--diff-filter=AM", "-r").
+		RunStdBytes(&git.RunOpts{Dir: repoPath, Env: env})
+	if err != nil {
+		return nil, fmt.Errorf("list changed files: %w", err)
+	}
+
+	origins := make(map[string]blobOrigin)
+	var blobIDs []string
+	var commitID string
+	for _, line := range strings.Split(string(stdout), "\n") {
+		if line == "" {
+			continue
+		}
+		if line[0] != ':' {
+			commitID = line
+			continue
+		}
+		// :<old mode> <new mode> <old blob> <new blob> <status>\t<path>
+		meta, path, ok := strings.Cut(line[1:], "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) < 4 || fields[1] == "160000" {
+			// submodules do not have blobs
+			continue
+		}
+		if strings.HasPrefix(path, `"`) {
+			if unquoted, err := strconv.Unquote(path); err == nil {
+				path = unquoted
+			}
+		}
+		blobID := fields[3]
+		if _, ok := origins[blobID]; !ok {
+			blobIDs = append(blobIDs, blobID)
+		}
+		// the last commit listed is the oldest, it introduced the blob
+		origins[blobID] = blobOrigin{commitID: commitID, path: path}
+	}
+	if len(blobIDs) == 0 {
+		return nil, nil
+	}
+
+	var findings []*Finding
+
+	stdoutReader, stdoutWriter, pipeErr := os.Pipe()
+	if pipeErr != nil {
+		return nil, pipeErr
+	}
+	defer func() {
+		_ = stdoutReader.Close()
+		_ = stdoutWriter.Close()
+	}()
+	if err := git.NewCommand(ctx, "cat-file", "--batch").Run(&git.RunOpts{
+		Dir:    repoPath,
+		Env:    env,
+		Stdin:  strings.NewReader(strings.Join(blobIDs, "\n") + "\n"),
+		Stdout: stdoutWriter,
+		PipelineFunc: func(ctx context.Context, cancel context.CancelFunc) error {
+			_ = stdoutWriter.Close()
+			defer stdoutReader.Close()
+			rd := bufio.NewReader(stdoutReader)
+			for range blobIDs {
+				sha, _, size, err := git.ReadBatchLine(rd)
+				if err != nil {
+					cancel()
+					return err
+				}
+				content, err := readBlob(rd, size)
+				if err != nil {
+					cancel()
+					return err
+				}
+				origin := origins[string(sha)]
+				for _, match := range secretscan.Scan(content, detectors) {
+					findings = append(findings, &Finding{Match: match, CommitID: origin.commitID, Path: origin.path})
+				}
+			}
+			return nil
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("read changed files: %w", err)
+	}
+	return findings, nil
+}
+
+// readBlob reads the content of a blob from the output of cat-file --batch,
+// it returns nil if the blob is too large or binary.
+func readBlob(rd *bufio.Reader, size int64) ([]byte, error) {
+	if size > setting.SecretScanning.MaxFileSize {
+		_, err := rd.Discard(int(size) + 1)
+		return nil, err
+	}
+	content := make([]byte, size+1)
+	if _, err := io.ReadFull(rd, content); err != nil {
+		return nil, err
+	}
+	content = content[:size]
+	if bytes.IndexByte(content, 0) >= 0 {
+		return nil, nil
+	}
+	return content, nil
+}
+
+// RecordAlerts stores an alert for every secret which has not been found in the repository before.
+// bypassedByID is the user who pushed the secrets while bypassing the push protection.
+func RecordAlerts(ctx context.Context, repo *repo_model.Repository, findings []*Finding, bypassedByID int64) error {
+	alerts := make([]*secretscan_model.Alert, 0, len(findings))
+	for _, f := range findings {
+		alerts = append(alerts, &secretscan_model.Alert{
+			RepoID:       repo.ID,
+			Fingerprint:  f.Fingerprint(),
+			DetectorID:   f.Detector.ID,
+			Redacted:     secretscan.Redact(f.Secret),
+			CommitID:     f.CommitID,
+			Path:         f.Path,
+			Line:         f.Line,
+			State:        secretscan_model.AlertStateOpen,
+			BypassedByID: bypassedByID,
+		})
+	}
+	return secretscan_model.InsertAlerts(ctx, alerts)
+}
+
+// maxRejectedFindings is the number of secrets listed when a push is rejected
+const maxRejectedFindings = 10
+
+// RejectionMessage explains to the pusher why their push was rejected
+func RejectionMessage(findings []*Finding) string {
+	var sb strings.Builder
+	sb.WriteString("Push rejected: secrets were found in the pushed commits\n")
+	for i, f := range findings {
+		if i == maxRejectedFindings {
+			fmt.Fprintf(&sb, "  ... and %d more\n", len(findings)-maxRejectedFindings)
+			break
+		}
+		fmt.Fprintf(&sb, "  - %s %s in %s:%d (commit %s)\n", f.Detector.Description, secretscan.Redact(f.Secret), f.Path, f.Line, base.ShortSha(f.CommitID))
+	}
+	sb.WriteString("Remove the secrets from the commits and push again.\n")
+	sb.WriteString("If the secrets are not sensitive, a repository administrator can push them with: git push -o secret_scanning.bypass")
+	return sb.String()
+}
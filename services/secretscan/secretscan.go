@@ -0,0 +1,66 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package secretscan
+
+import (
+	"errors"
+
+	repo_model "forgejo.org/models/repo"
+	"forgejo.org/modules/graceful"
+	"forgejo.org/modules/log"
+	"forgejo.org/modules/queue"
+	"forgejo.org/modules/setting"
+)
+
+var historyQueue *queue.WorkerPoolQueue[int64]
+
+// Init starts the queue which scans the history of repositories in the background
+func Init() error {
+	if !setting.SecretScanning.Enabled {
+		return nil
+	}
+
+	historyQueue = queue.CreateUniqueQueue(graceful.GetManager().ShutdownContext(), "secret_scan_history", historyHandler)
+	if historyQueue == nil {
+		return errors.New("unable to create secret_scan_history queue")
+	}
+	go graceful.GetManager().RunWithCancel(historyQueue)
+	return nil
+}
+
+func historyHandler(items ...int64) []int64 {
+	ctx := graceful.GetManager().ShutdownContext()
+	for _, repoID := range items {
+		repo, err := repo_model.GetRepositoryByID(ctx, repoID)
+		if err != nil {
+			if !repo_model.IsErrRepoNotExist(err) {
+				log.Error("GetRepositoryByID[%d]: %v", repoID, err)
+			}
+			continue
+		}
+		if repo.IsEmpty {
+			continue
+		}
+		findings, err := ScanHistory(ctx, repo)
+		if err != nil {
+			log.Error("Unable to scan the history of %-v for secrets: %v", repo, err)
+			continue
+		}
+		if err := RecordAlerts(ctx, repo, findings, 0); err != nil {
+			log.Error("Unable to record the secrets found in %-v: %v", repo, err)
+		}
+	}
+	return nil
+}
+
+// QueueHistoryScan scans all the commits of the repository in the background and records alerts for the secrets found
+func QueueHistoryScan(repo *repo_model.Repository) error {
+	if historyQueue == nil {
+		return errors.New("secret scanning is disabled")
+	}
+	if err := historyQueue.Push(repo.ID); err != nil && !errors.Is(err, queue.ErrAlreadyInQueue) {
+		return err
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ import (
 	git_model "forgejo.org/models/git"
 	issues_model "forgejo.org/models/issues"
 	"forgejo.org/models/organization"
+	packages_model "forgejo.org/models/packages"
 	access_model "forgejo.org/models/perm/access"
 	pull_model "forgejo.org/models/pull"
 	repo_model "forgejo.org/models/repo"
@@ -81,6 +82,9 @@ func deleteUser(ctx context.Context, u *user_model.User, purge bool) (err error)
 		&repo_model.Star{UID: u.ID},
 		&user_model.Follow{UserID: u.ID},
 		&user_model.Follow{FollowID: u.ID},
+		&user_model.FollowRequest{UserID: u.ID},
+		&user_model.FollowRequest{FollowID: u.ID},
+		&activities_model.FederatedUserActivity{UserID: u.ID},
 		&activities_model.Action{UserID: u.ID},
 		&issues_model.IssueUser{UID: u.ID},
 		&user_model.EmailAddress{UID: u.ID},
@@ -98,6 +102,8 @@ func deleteUser(ctx context.Context, u *user_model.User, purge bool) (err error)
 		&user_model.BlockedUser{UserID: u.ID},
 		&actions_model.ActionRunnerToken{OwnerID: u.ID},
 		&auth_model.AuthorizationToken{UID: u.ID},
+		&packages_model.PackageVirtualRegistry{OwnerID: u.ID},
+		&auth_model.SCIMResource{ResourceType: auth_model.SCIMResourceTypeUser, ResourceID: u.ID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %w", err)
 	}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"strings"
 
+	auth_model "forgejo.org/models/auth"
 	"forgejo.org/models/db"
 	repo_model "forgejo.org/models/repo"
 	user_model "forgejo.org/models/user"
@@ -240,6 +241,28 @@ func PrepareWebhooks(ctx context.Context, source EventSource, event webhook_modu
 		ws = append(ws, ownerHooks...)
 	}
 
+	// append webhooks of the applications installed for the repository or owner
+	if owner != nil {
+		var repoID int64
+		if source.Repository != nil {
+			repoID = source.Repository.ID
+		}
+		botUserIDs, err := auth_model.FindRepoInstallationBotUserIDs(ctx, repoID, owner.ID)
+		if err != nil {
+			return fmt.Errorf("FindRepoInstallationBotUserIDs: %w", err)
+		}
+		for _, botUserID := range botUserIDs {
+			appHooks, err := db.Find[webhook_model.Webhook](ctx, webhook_model.ListWebhookOptions{
+				OwnerID:  botUserID,
+				IsActive: optional.Some(true),
+			})
+			if err != nil {
+				return fmt.Errorf("ListWebhooksByOpts: %w", err)
+			}
+			ws = append(ws, appHooks...)
+		}
+	}
+
 	// Add any admin-defined system webhooks
 	systemHooks, err := webhook_model.GetSystemWebhooks(ctx, true)
 	if err != nil {
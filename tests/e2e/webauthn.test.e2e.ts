@@ -65,3 +65,48 @@ test('WebAuthn register & login flow', async ({browser, request}, workerInfo) =>
   // verify the user can login without a key
   await login_user(browser, workerInfo, username);
 });
+
+test('Passkey register & passwordless login flow', async ({browser, request}, workerInfo) => {
+  test.skip(workerInfo.project.name !== 'chromium', 'Uses Chrome protocol');
+  const {context} = await create_temp_user(browser, workerInfo, request);
+  const page = await context.newPage();
+
+  let response = await page.goto('/user/settings/security');
+  expect(response?.status()).toBe(200);
+
+  const cdpSession = await page.context().newCDPSession(page);
+  await cdpSession.send('WebAuthn.enable');
+  await cdpSession.send('WebAuthn.addVirtualAuthenticator', {
+    options: {
+      protocol: 'ctap2',
+      ctap2Version: 'ctap2_1',
+      hasResidentKey: true,
+      hasUserVerification: true,
+      transport: 'internal',
+      automaticPresenceSimulation: true,
+      isUserVerified: true,
+    },
+  });
+
+  // Register a passkey.
+  await page.locator('input#nickname').fill('Testing Passkey');
+  await page.getByLabel('Use as a passkey').check();
+  await page.getByText('Add security key').click();
+  await expect(page.getByText('Passkey', {exact: true})).toBeVisible();
+
+  // Logout.
+  await expect(async () => {
+    await page.locator('div[aria-label="Profile and settings…"]').click();
+    await page.getByText('Sign out').click();
+  }).toPass();
+  await page.waitForURL(`${workerInfo.project.use.baseURL}/`);
+
+  // Login without a username or a password.
+  response = await page.goto('/user/login');
+  expect(response?.status()).toBe(200);
+  await page.getByRole('button', {name: 'Sign in with a passkey'}).click();
+  await page.waitForURL(`${workerInfo.project.use.baseURL}/`);
+
+  response = await page.goto('/user/settings/security');
+  expect(response?.status()).toBe(200);
+});
@@ -0,0 +1,87 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"forgejo.org/models/db"
+	"forgejo.org/models/forgefed"
+	"forgejo.org/models/unittest"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/activitypub"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/test"
+	"forgejo.org/routers"
+	moderation_service "forgejo.org/services/moderation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityPubHostPolicy(t *testing.T) {
+	defer test.MockVariableValue(&setting.Federation.Enabled, true)()
+	defer test.MockVariableValue(&testWebRoutes, routers.NormalRoutes())()
+
+	mock := test.NewFederationServerMock()
+	federatedSrv := mock.DistantServer(t)
+	defer federatedSrv.Close()
+
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		defer test.MockVariableValue(&setting.AppURL, u.String())()
+		user1 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1})
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+
+		cf, err := activitypub.GetClientFactory(db.DefaultContext)
+		require.NoError(t, err)
+		c, err := cf.WithKeys(db.DefaultContext, user1, u.JoinPath("/api/v1/activitypub/user-id/1").String()+"#main-key")
+		require.NoError(t, err)
+		user2inboxurl := u.JoinPath("/api/v1/activitypub/user-id/2/inbox").String()
+		actorURL := federatedSrv.URL + "/api/v1/activitypub/user-id/15"
+		follow := fmt.Sprintf(`{"type":"Follow","id":"%[1]s/follows/1","actor":"%[1]s","object":"%[2]s"}`, actorURL, user2.APActorID())
+
+		t.Run("Denied", func(t *testing.T) {
+			rule := &forgefed.FederationHostRule{Pattern: u.Hostname(), Type: forgefed.FederationHostRuleDeny}
+			require.NoError(t, forgefed.CreateFederationHostRule(db.DefaultContext, rule))
+			defer func() {
+				require.NoError(t, forgefed.DeleteFederationHostRule(db.DefaultContext, rule.ID))
+			}()
+
+			resp, err := c.Post([]byte(follow), user2inboxurl)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		})
+
+		t.Run("Allowlist", func(t *testing.T) {
+			defer test.MockVariableValue(&setting.Federation.HostPolicy, setting.FederationHostPolicyAllowlist)()
+
+			resp, err := c.Post([]byte(follow), user2inboxurl)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+			req := NewRequest(t, "POST", user2inboxurl)
+			MakeRequest(t, req, http.StatusForbidden)
+		})
+
+		t.Run("Quarantine", func(t *testing.T) {
+			defer test.MockVariableValue(&setting.Federation.QuarantineNewHosts, true)()
+
+			resp, err := c.Post([]byte(follow), user2inboxurl)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+			held := unittest.AssertExistsAndLoadBean(t, &forgefed.QuarantinedActivity{Host: u.Hostname(), TargetID: user2.ID})
+			assert.Equal(t, forgefed.QuarantinedActivityTargetUser, held.TargetType)
+			unittest.AssertNotExistsBean(t, &user_model.FederatedUser{ExternalID: "15"})
+
+			require.NoError(t, moderation_service.ReleaseFederationHost(db.DefaultContext, u.Hostname()))
+			unittest.AssertNotExistsBean(t, &forgefed.QuarantinedActivity{ID: held.ID})
+			federatedUser := unittest.AssertExistsAndLoadBean(t, &user_model.FederatedUser{ExternalID: "15"})
+			assert.True(t, user_model.IsFollowing(db.DefaultContext, federatedUser.UserID, user2.ID))
+		})
+	})
+}
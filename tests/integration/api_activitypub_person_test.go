@@ -23,6 +23,21 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// newDistantPersonClient returns a client signing requests with the key of a person of the mock server.
+// The client factory reads keys from the settings of a user, so the key pair is stored for a local user.
+func newDistantPersonClient(t *testing.T, serverURL string, personID int64, privPem, pubPem string) activitypub.APClient {
+	t.Helper()
+	keyHolder := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 10})
+	require.NoError(t, user_model.SetUserSetting(db.DefaultContext, keyHolder.ID, user_model.UserActivityPubPrivPem, privPem))
+	require.NoError(t, user_model.SetUserSetting(db.DefaultContext, keyHolder.ID, user_model.UserActivityPubPubPem, pubPem))
+
+	cf, err := activitypub.GetClientFactory(db.DefaultContext)
+	require.NoError(t, err)
+	c, err := cf.WithKeys(db.DefaultContext, keyHolder, test.PersonKeyID(serverURL, personID))
+	require.NoError(t, err)
+	return c
+}
+
 func TestActivityPubPerson(t *testing.T) {
 	defer test.MockVariableValue(&setting.Federation.Enabled, true)()
 	defer test.MockVariableValue(&testWebRoutes, routers.NormalRoutes())()
@@ -83,9 +98,14 @@ func TestActivityPubPersonInbox(t *testing.T) {
 	defer test.MockVariableValue(&setting.Federation.Enabled, true)()
 	defer test.MockVariableValue(&testWebRoutes, routers.NormalRoutes())()
 
+	mock := test.NewFederationServerMock()
+	federatedSrv := mock.DistantServer(t)
+	defer federatedSrv.Close()
+
 	onGiteaRun(t, func(t *testing.T, u *url.URL) {
 		defer test.MockVariableValue(&setting.AppURL, u.String())()
 		user1 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1})
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
 
 		user1url := u.JoinPath("/api/v1/activitypub/user-id/1").String() + "#main-key"
 		cf, err := activitypub.GetClientFactory(db.DefaultContext)
@@ -93,14 +113,94 @@ func TestActivityPubPersonInbox(t *testing.T) {
 		c, err := cf.WithKeys(db.DefaultContext, user1, user1url)
 		require.NoError(t, err)
 		user2inboxurl := u.JoinPath("/api/v1/activitypub/user-id/2/inbox").String()
-
-		// Signed request succeeds
-		resp, err := c.Post([]byte{}, user2inboxurl)
-		require.NoError(t, err)
-		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		actorURL := federatedSrv.URL + "/api/v1/activitypub/user-id/15"
 
 		// Unsigned request fails
 		req := NewRequest(t, "POST", user2inboxurl)
 		MakeRequest(t, req, http.StatusBadRequest)
+
+		t.Run("Unsupported", func(t *testing.T) {
+			resp, err := c.Post([]byte(`{"type":"Wrong","actor":"`+actorURL+`"}`), user2inboxurl)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
+		})
+
+		t.Run("Follow", func(t *testing.T) {
+			follow := fmt.Sprintf(`{"type":"Follow","id":"%[1]s/follows/1","actor":"%[1]s","object":"%[2]s"}`, actorURL, user2.APActorID())
+			resp, err := c.Post([]byte(follow), user2inboxurl)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+			federatedUser := unittest.AssertExistsAndLoadBean(t, &user_model.FederatedUser{ExternalID: "15"})
+			assert.True(t, user_model.IsFollowing(db.DefaultContext, federatedUser.UserID, user2.ID))
+		})
+
+		t.Run("Undo", func(t *testing.T) {
+			undo := fmt.Sprintf(`{"type":"Undo","actor":"%[1]s","object":{"type":"Follow","actor":"%[1]s","object":"%[2]s"}}`, actorURL, user2.APActorID())
+			resp, err := c.Post([]byte(undo), user2inboxurl)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+			federatedUser := unittest.AssertExistsAndLoadBean(t, &user_model.FederatedUser{ExternalID: "15"})
+			assert.False(t, user_model.IsFollowing(db.DefaultContext, federatedUser.UserID, user2.ID))
+		})
+
+		t.Run("FollowOtherUser", func(t *testing.T) {
+			follow := fmt.Sprintf(`{"type":"Follow","id":"%[1]s/follows/2","actor":"%[1]s","object":"%[2]s"}`, actorURL, user1.APActorID())
+			resp, err := c.Post([]byte(follow), user2inboxurl)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
+		})
+
+		t.Run("FollowOfOtherHost", func(t *testing.T) {
+			// the signing instance can not follow on behalf of the users of another one
+			otherActorURL := "https://forge.example.com/api/v1/activitypub/user-id/15"
+			follow := fmt.Sprintf(`{"type":"Follow","id":"%[1]s/follows/3","actor":"%[1]s","object":"%[2]s"}`, otherActorURL, user2.APActorID())
+			resp, err := c.Post([]byte(follow), user2inboxurl)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+			undo := fmt.Sprintf(`{"type":"Undo","actor":"%[1]s","object":{"type":"Follow","actor":"%[1]s","object":"%[2]s"}}`, otherActorURL, user2.APActorID())
+			resp, err = c.Post([]byte(undo), user2inboxurl)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		})
+
+		t.Run("FollowOfOtherHostSignatureNotEnforced", func(t *testing.T) {
+			defer test.MockVariableValue(&setting.Federation.SignatureEnforced, false)()
+
+			// signatures are still verified and checked against the actor if they are not enforced
+			otherActorURL := "https://forge.example.com/api/v1/activitypub/user-id/15"
+			follow := fmt.Sprintf(`{"type":"Follow","id":"%[1]s/follows/4","actor":"%[1]s","object":"%[2]s"}`, otherActorURL, user2.APActorID())
+			resp, err := c.Post([]byte(follow), user2inboxurl)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		})
+	})
+}
+
+func TestActivityPubPersonOutbox(t *testing.T) {
+	defer test.MockVariableValue(&setting.Federation.Enabled, true)()
+	defer test.MockVariableValue(&testWebRoutes, routers.NormalRoutes())()
+
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		defer test.MockVariableValue(&setting.AppURL, u.String())()
+		user1 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1})
+
+		cf, err := activitypub.GetClientFactory(db.DefaultContext)
+		require.NoError(t, err)
+		c, err := cf.WithKeys(db.DefaultContext, user1, user1.APActorKeyID())
+		require.NoError(t, err)
+
+		body, err := c.GetBody(u.JoinPath("/api/v1/activitypub/user-id/2/outbox").String())
+		require.NoError(t, err)
+
+		outbox := ap.OrderedCollection{}
+		require.NoError(t, outbox.UnmarshalJSON(body))
+		assert.Equal(t, ap.OrderedCollectionType, outbox.Type)
+		assert.Regexp(t, "activitypub/user-id/2/outbox$", outbox.GetID().String())
+		for _, item := range outbox.OrderedItems {
+			assert.Equal(t, ap.CreateType, item.GetType())
+		}
 	})
 }
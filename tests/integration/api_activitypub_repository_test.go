@@ -7,18 +7,24 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"forgejo.org/models/db"
 	"forgejo.org/models/forgefed"
+	issues_model "forgejo.org/models/issues"
+	repo_model "forgejo.org/models/repo"
 	"forgejo.org/models/unittest"
 	"forgejo.org/models/user"
 	"forgejo.org/modules/activitypub"
 	forgefed_modules "forgejo.org/modules/forgefed"
+	"forgejo.org/modules/git"
 	"forgejo.org/modules/setting"
 	"forgejo.org/modules/test"
 	"forgejo.org/routers"
+	"forgejo.org/services/federation"
+	"forgejo.org/services/migrations"
 	"forgejo.org/tests"
 
 	"github.com/stretchr/testify/assert"
@@ -167,3 +173,278 @@ func TestActivityPubRepositoryInboxInvalid(t *testing.T) {
 		assert.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
 	})
 }
+
+func TestActivityPubRepositoryIssueComment(t *testing.T) {
+	defer test.MockVariableValue(&setting.Federation.Enabled, true)()
+	defer test.MockVariableValue(&testWebRoutes, routers.NormalRoutes())()
+
+	mock := test.NewFederationServerMock()
+	privPem, pubPem := mock.GeneratePersonKey(t, 15)
+	federatedSrv := mock.DistantServer(t)
+	defer federatedSrv.Close()
+
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		apServerActor := user.NewAPServerActor()
+		repositoryID := 1
+		repoURL := u.JoinPath(fmt.Sprintf("/api/v1/activitypub/repository-id/%d", repositoryID)).String()
+
+		cf, err := activitypub.GetClientFactory(db.DefaultContext)
+		require.NoError(t, err)
+
+		c, err := cf.WithKeys(db.DefaultContext, apServerActor, apServerActor.APActorKeyID())
+		require.NoError(t, err)
+		remote := newDistantPersonClient(t, federatedSrv.URL, 15, privPem, pubPem)
+
+		t.Run("Ticket", func(t *testing.T) {
+			resp, err := c.GetBody(repoURL + "/issues/1")
+			require.NoError(t, err)
+			assert.Contains(t, string(resp), `"type":"Ticket"`)
+			assert.Contains(t, string(resp), `"name":"issue1"`)
+			assert.Contains(t, string(resp), `"isResolved":false`)
+
+			_, err = c.GetBody(repoURL + "/issues/9999")
+			require.Error(t, err)
+		})
+
+		t.Run("Note", func(t *testing.T) {
+			resp, err := c.GetBody(repoURL + "/issues/1/comments/2")
+			require.NoError(t, err)
+			assert.Contains(t, string(resp), `"type":"Note"`)
+			assert.Contains(t, string(resp), repoURL+"/issues/1")
+		})
+
+		t.Run("CreateNote", func(t *testing.T) {
+			actor := federatedSrv.URL + "/api/v1/activitypub/user-id/15"
+			noteID := actor + "/notes/1"
+			activity := []byte(fmt.Sprintf(
+				`{"type":"Create","actor":"%[1]s",`+
+					`"object":{"type":"Note","id":"%[2]s","attributedTo":"%[1]s","inReplyTo":"%[3]s/issues/1","content":"comment from far away"}}`,
+				actor, noteID, repoURL))
+
+			resp, err := remote.Post(activity, repoURL+"/inbox")
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+			fc := unittest.AssertExistsAndLoadBean(t, &issues_model.FederatedComment{NoteID: noteID})
+			comment := unittest.AssertExistsAndLoadBean(t, &issues_model.Comment{ID: fc.CommentID})
+			assert.Equal(t, "comment from far away", comment.Content)
+			federatedUser := unittest.AssertExistsAndLoadBean(t, &user.FederatedUser{UserID: comment.PosterID})
+			assert.Equal(t, "15", federatedUser.ExternalID)
+
+			// a redelivery does not create another comment
+			resp, err = remote.Post(activity, repoURL+"/inbox")
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+			unittest.AssertCount(t, &issues_model.FederatedComment{NoteID: noteID}, 1)
+
+			// replies to issues of other repositories are rejected
+			activity = []byte(fmt.Sprintf(
+				`{"type":"Create","actor":"%[1]s",`+
+					`"object":{"type":"Note","id":"%[1]s/notes/2","attributedTo":"%[1]s","inReplyTo":"%[2]s/issues/1","content":"wrong repo"}}`,
+				actor, u.JoinPath("/api/v1/activitypub/repository-id/2").String()))
+			resp, err = remote.Post(activity, repoURL+"/inbox")
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
+		})
+
+		t.Run("CreateNoteOfOtherHost", func(t *testing.T) {
+			// the signing instance can not comment on behalf of the users of another one
+			actor := "https://forge.example.com/api/v1/activitypub/user-id/15"
+			noteID := actor + "/notes/1"
+			activity := []byte(fmt.Sprintf(
+				`{"type":"Create","actor":"%[1]s",`+
+					`"object":{"type":"Note","id":"%[2]s","attributedTo":"%[1]s","inReplyTo":"%[3]s/issues/1","content":"forged comment"}}`,
+				actor, noteID, repoURL))
+
+			resp, err := remote.Post(activity, repoURL+"/inbox")
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+			unittest.AssertNotExistsBean(t, &issues_model.FederatedComment{NoteID: noteID})
+		})
+	})
+}
+
+func TestActivityPubRepositoryOfferTicket(t *testing.T) {
+	defer test.MockVariableValue(&setting.Federation.Enabled, true)()
+	defer test.MockVariableValue(&testWebRoutes, routers.NormalRoutes())()
+
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		// the remote fork is served by the local instance on the same host as the mock server
+		defer test.MockVariableValue(&setting.Migrations.AllowLocalNetworks, true)()
+		require.NoError(t, migrations.Init())
+
+		mock := test.NewFederationServerMock()
+		mock.GitUpstream = u.String()
+		mock.Repositories[0].CloneURI = "/git/user2/repo1.git"
+		privPem, pubPem := mock.GeneratePersonKey(t, 15)
+		federatedSrv := mock.DistantServer(t)
+		defer federatedSrv.Close()
+
+		apServerActor := user.NewAPServerActor()
+		repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+		repoURL := u.JoinPath(fmt.Sprintf("/api/v1/activitypub/repository-id/%d", repo.ID)).String()
+
+		cf, err := activitypub.GetClientFactory(db.DefaultContext)
+		require.NoError(t, err)
+		local, err := cf.WithKeys(db.DefaultContext, apServerActor, apServerActor.APActorKeyID())
+		require.NoError(t, err)
+		c := newDistantPersonClient(t, federatedSrv.URL, 15, privPem, pubPem)
+
+		actor := federatedSrv.URL + "/api/v1/activitypub/user-id/15"
+		offerID := actor + "/offers/1"
+		offer := func(id, targetBranch string) []byte {
+			return []byte(fmt.Sprintf(
+				`{"type":"Offer","id":"%[1]s","actor":"%[2]s","target":"%[3]s",`+
+					`"object":{"type":"Ticket","attributedTo":"%[2]s","name":"federated change","content":"please merge",`+
+					`"origin":{"type":"Branch","context":"%[4]s/api/v1/activitypub/repository-id/1","ref":"refs/heads/branch2"},`+
+					`"target":{"type":"Branch","context":"%[3]s","ref":"refs/heads/%[5]s"}}}`,
+				id, actor, repoURL, federatedSrv.URL, targetBranch))
+		}
+
+		t.Run("CloneURI", func(t *testing.T) {
+			resp, err := local.GetBody(repoURL)
+			require.NoError(t, err)
+			assert.Contains(t, string(resp), `"cloneUri":"`+repo.CloneLink().HTTPS+`"`)
+		})
+
+		t.Run("Offer", func(t *testing.T) {
+			resp, err := c.Post(offer(offerID, "master"), repoURL+"/inbox")
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+			fpr := unittest.AssertExistsAndLoadBean(t, &issues_model.FederatedPullRequest{OfferID: offerID})
+			pr := unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: fpr.PullID})
+			assert.Equal(t, issues_model.PullRequestFlowAGit, pr.Flow)
+			assert.Equal(t, "master", pr.BaseBranch)
+			assert.Equal(t, "985f0301dba5e7b34be866819cd15ad3d8f508ee", pr.HeadCommitID)
+
+			issue := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{ID: pr.IssueID})
+			assert.Equal(t, "federated change", issue.Title)
+			federatedUser := unittest.AssertExistsAndLoadBean(t, &user.FederatedUser{UserID: issue.PosterID})
+			assert.Equal(t, "15", federatedUser.ExternalID)
+
+			// the hidden ref is removed once the pull request exists
+			stdout, _, err := git.NewCommand(git.DefaultContext, "for-each-ref", "refs/federated/").RunStdString(&git.RunOpts{Dir: repo.RepoPath()})
+			require.NoError(t, err)
+			assert.Empty(t, stdout)
+
+			// a redelivery does not create another pull request
+			resp, err = c.Post(offer(offerID, "master"), repoURL+"/inbox")
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+			unittest.AssertCount(t, &issues_model.FederatedPullRequest{OfferID: offerID}, 1)
+
+			// the same branch can not be offered twice
+			resp, err = c.Post(offer(actor+"/offers/2", "master"), repoURL+"/inbox")
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		})
+
+		t.Run("UnknownTargetBranch", func(t *testing.T) {
+			resp, err := c.Post(offer(actor+"/offers/3", "does-not-exist"), repoURL+"/inbox")
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+
+		t.Run("OfferOfOtherHost", func(t *testing.T) {
+			// the signing instance can not offer changes on behalf of the users of another one
+			otherActor := "https://forge.example.com/api/v1/activitypub/user-id/15"
+			activity := []byte(fmt.Sprintf(
+				`{"type":"Offer","id":"%[1]s/offers/4","actor":"%[1]s","target":"%[2]s",`+
+					`"object":{"type":"Ticket","attributedTo":"%[1]s","name":"forged change","content":"please merge",`+
+					`"origin":{"type":"Branch","context":"https://forge.example.com/api/v1/activitypub/repository-id/1","ref":"refs/heads/branch2"},`+
+					`"target":{"type":"Branch","context":"%[2]s","ref":"refs/heads/master"}}}`,
+				otherActor, repoURL))
+			resp, err := c.Post(activity, repoURL+"/inbox")
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+			unittest.AssertNotExistsBean(t, &issues_model.FederatedPullRequest{OfferID: otherActor + "/offers/4"})
+		})
+
+		t.Run("BlockedCloneURI", func(t *testing.T) {
+			defer test.MockVariableValue(&setting.Migrations.BlockedDomains, u.Hostname())()
+			require.NoError(t, migrations.Init())
+			defer func() { require.NoError(t, migrations.Init()) }()
+
+			resp, err := c.Post(offer(actor+"/offers/5", "branch2"), repoURL+"/inbox")
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+			unittest.AssertNotExistsBean(t, &issues_model.FederatedPullRequest{OfferID: actor + "/offers/5"})
+		})
+	})
+}
+
+func TestActivityPubRepositoryPush(t *testing.T) {
+	defer test.MockVariableValue(&setting.Federation.Enabled, true)()
+	defer test.MockVariableValue(&testWebRoutes, routers.NormalRoutes())()
+
+	mock := test.NewFederationServerMock()
+	privPem, pubPem := mock.GeneratePersonKey(t, 15)
+	federatedSrv := mock.DistantServer(t)
+	defer federatedSrv.Close()
+
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		defer test.MockVariableValue(&setting.AppURL, u.String())()
+		federatedRepoURL := federatedSrv.URL + "/api/v1/activitypub/repository-id/1"
+		actorURL := federatedSrv.URL + "/api/v1/activitypub/user-id/15"
+
+		t.Run("Send", func(t *testing.T) {
+			user2 := unittest.AssertExistsAndLoadBean(t, &user.User{ID: 2})
+			repo1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+			_, _, err := federation.StoreFollowingRepoList(db.DefaultContext, repo1.ID, []string{federatedRepoURL})
+			require.NoError(t, err)
+
+			_, err = createFileInBranch(user2, repo1, "federated-push.txt", repo1.DefaultBranch, "pushed")
+			require.NoError(t, err)
+
+			assert.Eventually(t, func() bool {
+				return strings.Contains(mock.LastPost, `"type":"Push"`)
+			}, 10*time.Second, 100*time.Millisecond)
+			push := forgefed_modules.ForgePush{}
+			require.NoError(t, push.UnmarshalJSON([]byte(mock.LastPost)))
+			assert.Equal(t, repo1.APActorID(), push.Context.GetID().String())
+			assert.Equal(t, git.BranchPrefix+repo1.DefaultBranch, push.Branch.Ref)
+		})
+
+		t.Run("Receive", func(t *testing.T) {
+			// the push is signed by the actor who announces it
+			c := newDistantPersonClient(t, federatedSrv.URL, 15, privPem, pubPem)
+
+			mirror := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 25, IsMirror: true})
+			_, _, err := federation.StoreFollowingRepoList(db.DefaultContext, mirror.ID, []string{federatedRepoURL})
+			require.NoError(t, err)
+
+			pushOf := func(actor, repository string) []byte {
+				push, err := forgefed_modules.NewForgePush(actor, repository, "refs/heads/main",
+					"65f1bf27bc3bf70f64657658635e66094edbcb4d", "2c54faec6c45d31c1abfaecdab471eac6633738a", nil)
+				require.NoError(t, err)
+				body, err := push.MarshalJSON()
+				require.NoError(t, err)
+				return body
+			}
+
+			mirrorInboxURL := u.JoinPath(fmt.Sprintf("/api/v1/activitypub/repository-id/%d/inbox", mirror.ID)).String()
+			resp, err := c.Post(pushOf(actorURL, federatedRepoURL), mirrorInboxURL)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+			// the pushed repository is not followed by the mirror
+			resp, err = c.Post(pushOf(actorURL, federatedSrv.URL+"/api/v1/activitypub/repository-id/2"), mirrorInboxURL)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
+
+			// the repository is not a mirror
+			resp, err = c.Post(pushOf(actorURL, federatedRepoURL), u.JoinPath("/api/v1/activitypub/repository-id/1/inbox").String())
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
+
+			// the signing instance can not announce pushes on behalf of another one
+			otherRepoURL := "https://forge.example.com/api/v1/activitypub/repository-id/1"
+			_, _, err = federation.StoreFollowingRepoList(db.DefaultContext, mirror.ID, []string{federatedRepoURL, otherRepoURL})
+			require.NoError(t, err)
+			resp, err = c.Post(pushOf("https://forge.example.com/api/v1/activitypub/user-id/15", otherRepoURL), mirrorInboxURL)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		})
+	})
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	repo_model "forgejo.org/models/repo"
+	"forgejo.org/models/unittest"
+	user_model "forgejo.org/models/user"
+	api "forgejo.org/modules/structs"
+	"forgejo.org/services/apps"
+	"forgejo.org/tests"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIOAuth2ApplicationInstallation(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+
+	app := unittest.AssertExistsAndLoadBean(t, &auth_model.OAuth2Application{ID: 2})
+	require.NoError(t, apps.UpdateSettings(db.DefaultContext, app, apps.Settings{
+		Permissions: "read:contents",
+		PublicKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+	}))
+	bot := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: app.BotUserID})
+	assert.True(t, bot.IsBot())
+
+	// repo2 is a private repository of user2
+	user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	repo2 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 2})
+	inst, err := apps.Install(db.DefaultContext, app, user2, user2, []*repo_model.Repository{repo2})
+	require.NoError(t, err)
+
+	signJWT := func(t *testing.T, issuedAt time.Time, lifetime time.Duration) string {
+		t.Helper()
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.RegisteredClaims{
+			Issuer:    app.ClientID,
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(lifetime)),
+		}).SignedString(priv)
+		require.NoError(t, err)
+		return signed
+	}
+
+	t.Run("Application", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		token := signJWT(t, time.Now(), 5*time.Minute)
+
+		resp := MakeRequest(t, NewRequest(t, "GET", "/api/v1/app").AddTokenAuth(token), http.StatusOK)
+		var apiApp api.InstallableApplication
+		DecodeJSON(t, resp, &apiApp)
+		assert.Equal(t, app.ClientID, apiApp.ClientID)
+
+		resp = MakeRequest(t, NewRequest(t, "GET", "/api/v1/app/installations").AddTokenAuth(token), http.StatusOK)
+		var insts []*api.ApplicationInstallation
+		DecodeJSON(t, resp, &insts)
+		require.Len(t, insts, 1)
+		assert.Equal(t, inst.ID, insts[0].ID)
+
+		// the JWT only authenticates the application, it does not act for the bot
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/user").AddTokenAuth(token), http.StatusForbidden)
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo2").AddTokenAuth(token), http.StatusForbidden)
+	})
+
+	t.Run("InvalidJWT", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/app").AddTokenAuth(signJWT(t, time.Now(), time.Hour)), http.StatusUnauthorized)
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/app").AddTokenAuth(signJWT(t, time.Now().Add(-time.Hour), 5*time.Minute)), http.StatusUnauthorized)
+
+		// personal access tokens can not be used either
+		token := getUserToken(t, "user2", auth_model.AccessTokenScopeAll)
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/app").AddTokenAuth(token), http.StatusUnauthorized)
+	})
+
+	t.Run("InstallationToken", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		req := NewRequest(t, "POST", fmt.Sprintf("/api/v1/app/installations/%d/access_tokens", inst.ID)).AddTokenAuth(signJWT(t, time.Now(), 5*time.Minute))
+		resp := MakeRequest(t, req, http.StatusCreated)
+		var token api.ApplicationInstallationToken
+		DecodeJSON(t, resp, &token)
+		assert.Equal(t, []int64{2}, token.RepositoryIDs)
+		assert.WithinDuration(t, time.Now().Add(auth_model.InstallationTokenLifetime), token.ExpiresAt, time.Minute)
+
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo2").AddTokenAuth(token.Token), http.StatusOK)
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo2/issues").AddTokenAuth(token.Token), http.StatusForbidden)
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo16").AddTokenAuth(token.Token), http.StatusNotFound)
+
+		// uninstalling the application revokes its tokens
+		require.NoError(t, auth_model.DeleteOAuth2ApplicationInstallation(db.DefaultContext, inst.ID, user2.ID))
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo2").AddTokenAuth(token.Token), http.StatusUnauthorized)
+		req = NewRequest(t, "POST", fmt.Sprintf("/api/v1/app/installations/%d/access_tokens", inst.ID)).AddTokenAuth(signJWT(t, time.Now(), 5*time.Minute))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+}
@@ -0,0 +1,299 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package integration
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"testing"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	"forgejo.org/models/packages"
+	"forgejo.org/models/unittest"
+	user_model "forgejo.org/models/user"
+	galaxy_module "forgejo.org/modules/packages/galaxy"
+	"forgejo.org/modules/setting"
+	"forgejo.org/tests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageGalaxy(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+
+	token := getUserToken(t, user.Name, auth_model.AccessTokenScopeWritePackage)
+
+	namespace := "forgejo"
+	collectionName := "tools"
+	packageName := namespace + "." + collectionName
+	packageVersion := "1.0.1"
+	packageDescription := "Test Description"
+	filename := fmt.Sprintf("%s-%s-%s.tar.gz", namespace, collectionName, packageVersion)
+
+	createCollection := func(version string) []byte {
+		manifest := `{"collection_info":{"namespace":"` + namespace + `","name":"` + collectionName + `","version":"` + version + `","authors":["Forgejo"],"readme":"README.md","description":"` + packageDescription + `","license":["MIT"],"dependencies":{"community.general":">=1.0.0"}},"format":1}`
+
+		files := []struct {
+			name    string
+			content string
+		}{
+			{"MANIFEST.json", manifest},
+			{"README.md", "# Tools"},
+		}
+
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(zw)
+		for _, f := range files {
+			tw.WriteHeader(&tar.Header{
+				Name: f.name,
+				Mode: 0o600,
+				Size: int64(len(f.content)),
+			})
+			tw.Write([]byte(f.content))
+		}
+		tw.Close()
+		zw.Close()
+		return buf.Bytes()
+	}
+
+	// ansible-galaxy uses the "file" content disposition for the collection part
+	createUploadBody := func(content []byte, checksum string) (io.Reader, string) {
+		var body bytes.Buffer
+		mpw := multipart.NewWriter(&body)
+
+		mpw.WriteField("sha256", checksum)
+
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`file; name="file"; filename="%s"`, filename))
+		h.Set("Content-Type", "application/octet-stream")
+		part, _ := mpw.CreatePart(h)
+		part.Write(content)
+
+		mpw.Close()
+
+		return &body, mpw.FormDataContentType()
+	}
+
+	content := createCollection(packageVersion)
+	hashSHA256 := sha256.Sum256(content)
+	checksum := hex.EncodeToString(hashSHA256[:])
+
+	root := fmt.Sprintf("/api/packages/%s/galaxy", user.Name)
+	apiRoot := root + "/api/v3"
+
+	t.Run("APIRoot", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", root+"/api/")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var result struct {
+			AvailableVersions map[string]string `json:"available_versions"`
+		}
+		DecodeJSON(t, resp, &result)
+		assert.Equal(t, "v3/", result.AvailableVersions["v3"])
+	})
+
+	var taskURL string
+
+	t.Run("Upload", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		uploadURL := apiRoot + "/artifacts/collections/"
+
+		body, contentType := createUploadBody(content, checksum)
+		req := NewRequestWithBody(t, "POST", uploadURL, body).
+			SetHeader("Content-Type", contentType)
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		body, contentType = createUploadBody(content, "0000")
+		req = NewRequestWithBody(t, "POST", uploadURL, body).
+			SetHeader("Content-Type", contentType).
+			SetHeader("Authorization", "Token "+token)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		body, contentType = createUploadBody([]byte("invalid"), "")
+		req = NewRequestWithBody(t, "POST", uploadURL, body).
+			SetHeader("Content-Type", contentType).
+			SetHeader("Authorization", "Token "+token)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		body, contentType = createUploadBody(content, checksum)
+		req = NewRequestWithBody(t, "POST", uploadURL, body).
+			SetHeader("Content-Type", contentType).
+			SetHeader("Authorization", "Token "+token)
+		resp := MakeRequest(t, req, http.StatusAccepted)
+
+		var result struct {
+			Task string `json:"task"`
+		}
+		DecodeJSON(t, resp, &result)
+		taskURL = result.Task
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeGalaxy)
+		require.NoError(t, err)
+		assert.Len(t, pvs, 1)
+
+		assert.Equal(t, fmt.Sprintf("%s/imports/collections/%d/", setting.AppSubURL+apiRoot, pvs[0].ID), taskURL)
+
+		pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+		require.NoError(t, err)
+		assert.NotNil(t, pd.SemVer)
+		assert.IsType(t, &galaxy_module.Metadata{}, pd.Metadata)
+		assert.Equal(t, packageName, pd.Package.Name)
+		assert.Equal(t, packageVersion, pd.Version.Version)
+		assert.Equal(t, packageDescription, pd.Metadata.(*galaxy_module.Metadata).Description)
+		assert.Equal(t, "# Tools", pd.Metadata.(*galaxy_module.Metadata).Readme)
+
+		require.Len(t, pd.Files, 1)
+		assert.Equal(t, filename, pd.Files[0].File.Name)
+		assert.True(t, pd.Files[0].File.IsLead)
+		assert.Equal(t, int64(len(content)), pd.Files[0].Blob.Size)
+
+		body, contentType = createUploadBody(content, checksum)
+		req = NewRequestWithBody(t, "POST", uploadURL, body).
+			SetHeader("Content-Type", contentType).
+			SetHeader("Authorization", "Token "+token)
+		MakeRequest(t, req, http.StatusConflict)
+
+		newContent := createCollection("1.1.0")
+		body, contentType = createUploadBody(newContent, "")
+		req = NewRequestWithBody(t, "POST", uploadURL, body).
+			SetHeader("Content-Type", contentType).
+			AddTokenAuth(token)
+		MakeRequest(t, req, http.StatusAccepted)
+	})
+
+	t.Run("ImportTask", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", taskURL)
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var result struct {
+			State string `json:"state"`
+		}
+		DecodeJSON(t, resp, &result)
+		assert.Equal(t, "completed", result.State)
+
+		req = NewRequest(t, "GET", apiRoot+"/imports/collections/999999/")
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("Collection", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/collections/%s/%s/", apiRoot, namespace, collectionName))
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var result struct {
+			Namespace      string `json:"namespace"`
+			Name           string `json:"name"`
+			HighestVersion struct {
+				Version string `json:"version"`
+			} `json:"highest_version"`
+		}
+		DecodeJSON(t, resp, &result)
+		assert.Equal(t, namespace, result.Namespace)
+		assert.Equal(t, collectionName, result.Name)
+		assert.Equal(t, "1.1.0", result.HighestVersion.Version)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/collections/%s/unknown/", apiRoot, namespace))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("Versions", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		type versionsResult struct {
+			Meta struct {
+				Count int `json:"count"`
+			} `json:"meta"`
+			Links struct {
+				Next *string `json:"next"`
+			} `json:"links"`
+			Data []struct {
+				Version string `json:"version"`
+				Href    string `json:"href"`
+			} `json:"data"`
+		}
+
+		versionsURL := fmt.Sprintf("%s/collections/%s/%s/versions/", apiRoot, namespace, collectionName)
+
+		req := NewRequest(t, "GET", versionsURL)
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var result versionsResult
+		DecodeJSON(t, resp, &result)
+		assert.Equal(t, 2, result.Meta.Count)
+		assert.Nil(t, result.Links.Next)
+		require.Len(t, result.Data, 2)
+		assert.Equal(t, "1.1.0", result.Data[0].Version)
+		assert.Equal(t, packageVersion, result.Data[1].Version)
+
+		req = NewRequest(t, "GET", versionsURL+"?limit=1")
+		resp = MakeRequest(t, req, http.StatusOK)
+
+		result = versionsResult{}
+		DecodeJSON(t, resp, &result)
+		assert.Equal(t, 2, result.Meta.Count)
+		require.Len(t, result.Data, 1)
+		require.NotNil(t, result.Links.Next)
+		assert.Equal(t, setting.AppSubURL+versionsURL+"?limit=1&offset=1", *result.Links.Next)
+	})
+
+	t.Run("Version", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/collections/%s/%s/versions/%s/", apiRoot, namespace, collectionName, packageVersion))
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var result struct {
+			Version  string `json:"version"`
+			Artifact struct {
+				Filename string `json:"filename"`
+				SHA256   string `json:"sha256"`
+				Size     int64  `json:"size"`
+			} `json:"artifact"`
+			DownloadURL string `json:"download_url"`
+			Metadata    struct {
+				Dependencies map[string]string `json:"dependencies"`
+			} `json:"metadata"`
+		}
+		DecodeJSON(t, resp, &result)
+		assert.Equal(t, packageVersion, result.Version)
+		assert.Equal(t, filename, result.Artifact.Filename)
+		assert.Equal(t, checksum, result.Artifact.SHA256)
+		assert.Equal(t, int64(len(content)), result.Artifact.Size)
+		assert.Equal(t, fmt.Sprintf("%sapi/packages/%s/galaxy/api/v3/download/%s", setting.AppURL, user.Name, filename), result.DownloadURL)
+		assert.Equal(t, map[string]string{"community.general": ">=1.0.0"}, result.Metadata.Dependencies)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/collections/%s/%s/versions/0.0.1/", apiRoot, namespace, collectionName))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/download/%s", apiRoot, filename))
+		resp := MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, content, resp.Body.Bytes())
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/download/%s-%s-0.0.1.tar.gz", apiRoot, namespace, collectionName))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+}
@@ -0,0 +1,328 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package integration
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	"forgejo.org/models/packages"
+	"forgejo.org/models/unittest"
+	user_model "forgejo.org/models/user"
+	hex_module "forgejo.org/modules/packages/hex"
+	hex_service "forgejo.org/services/packages/hex"
+	"forgejo.org/tests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestPackageHex(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+
+	token := getUserToken(t, user.Name, auth_model.AccessTokenScopeWritePackage)
+
+	packageName := "test_package"
+	packageVersion := "1.0.1"
+	packageDescription := "Test Description"
+
+	createPackage := func(version string) []byte {
+		metadata := `{<<"name">>,<<"` + packageName + `">>}.
+{<<"version">>,<<"` + version + `">>}.
+{<<"description">>,<<"` + packageDescription + `">>}.
+{<<"requirements">>,[{<<"decimal">>,[{<<"app">>,<<"decimal">>},{<<"optional">>,false},{<<"repository">>,<<"hexpm">>},{<<"requirement">>,<<"~> 2.0">>}]}]}.
+`
+		contents := []byte("contents")
+
+		h := sha256.New()
+		h.Write([]byte("3"))
+		h.Write([]byte(metadata))
+		h.Write(contents)
+
+		files := []struct {
+			name    string
+			content []byte
+		}{
+			{"VERSION", []byte("3")},
+			{"CHECKSUM", []byte(strings.ToUpper(hex.EncodeToString(h.Sum(nil))))},
+			{"metadata.config", []byte(metadata)},
+			{"contents.tar.gz", contents},
+		}
+
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, f := range files {
+			tw.WriteHeader(&tar.Header{
+				Name: f.name,
+				Mode: 0o600,
+				Size: int64(len(f.content)),
+			})
+			tw.Write(f.content)
+		}
+		tw.Close()
+		return buf.Bytes()
+	}
+
+	content := createPackage(packageVersion)
+
+	root := fmt.Sprintf("/api/packages/%s/hex", user.Name)
+
+	readSignedPayload := func(t *testing.T, body []byte) []byte {
+		t.Helper()
+
+		req := NewRequest(t, "GET", root+"/public_key")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		block, _ := pem.Decode(resp.Body.Bytes())
+		require.NotNil(t, block)
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		require.NoError(t, err)
+
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		data, err := io.ReadAll(zr)
+		require.NoError(t, err)
+
+		var payload, signature []byte
+		for len(data) > 0 {
+			num, typ, n := protowire.ConsumeTag(data)
+			require.Equal(t, protowire.BytesType, typ)
+			data = data[n:]
+			v, n := protowire.ConsumeBytes(data)
+			require.GreaterOrEqual(t, n, 0)
+			data = data[n:]
+			if num == 1 {
+				payload = v
+			} else {
+				signature = v
+			}
+		}
+
+		h := sha512.Sum512(payload)
+		require.NoError(t, rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), crypto.SHA512, h[:], signature))
+
+		return payload
+	}
+
+	t.Run("Upload", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		uploadURL := root + "/api/publish"
+
+		req := NewRequestWithBody(t, "POST", uploadURL, bytes.NewReader(content))
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequestWithBody(t, "POST", uploadURL, bytes.NewReader([]byte("invalid"))).
+			SetHeader("Authorization", token)
+		MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		req = NewRequestWithBody(t, "POST", uploadURL, bytes.NewReader(content)).
+			SetHeader("Authorization", token).
+			SetHeader("Accept", hex_module.ContentTypeErlang)
+		resp := MakeRequest(t, req, http.StatusCreated)
+
+		assert.Equal(t, hex_module.ContentTypeErlang, resp.Header().Get("Content-Type"))
+		v, err := hex_module.DecodeExternalTerm(resp.Body.Bytes())
+		require.NoError(t, err)
+		result := v.(map[string]any)
+		assert.Equal(t, packageVersion, result["version"])
+		assert.Contains(t, result["html_url"], "/-/packages/hex/"+packageName+"/"+packageVersion)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeHex)
+		require.NoError(t, err)
+		assert.Len(t, pvs, 1)
+
+		pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+		require.NoError(t, err)
+		assert.NotNil(t, pd.SemVer)
+		assert.IsType(t, &hex_module.Metadata{}, pd.Metadata)
+		assert.Equal(t, packageName, pd.Package.Name)
+		assert.Equal(t, packageVersion, pd.Version.Version)
+		assert.Equal(t, packageDescription, pd.Metadata.(*hex_module.Metadata).Description)
+		assert.NotEmpty(t, pd.VersionProperties.GetByName(hex_module.PropertyInnerChecksum))
+
+		require.Len(t, pd.Files, 1)
+		assert.Equal(t, packageName+"-"+packageVersion+".tar", pd.Files[0].File.Name)
+		assert.Equal(t, int64(len(content)), pd.Files[0].Blob.Size)
+
+		req = NewRequestWithBody(t, "POST", uploadURL, bytes.NewReader(content)).
+			SetHeader("Authorization", token)
+		MakeRequest(t, req, http.StatusConflict)
+
+		req = NewRequestWithBody(t, "POST", uploadURL+"?replace=true", bytes.NewReader(content)).
+			SetHeader("Authorization", token)
+		MakeRequest(t, req, http.StatusCreated)
+
+		req = NewRequestWithBody(t, "POST", uploadURL, bytes.NewReader(createPackage("1.1.0"))).
+			SetHeader("Authorization", token)
+		MakeRequest(t, req, http.StatusCreated)
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/tarballs/%s-%s.tar", root, packageName, packageVersion))
+		resp := MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, content, resp.Body.Bytes())
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/tarballs/%s-0.0.1.tar", root, packageName))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("Registry", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", root+"/names")
+		resp := MakeRequest(t, req, http.StatusOK)
+		payload := readSignedPayload(t, resp.Body.Bytes())
+		assert.Contains(t, string(payload), packageName)
+		assert.Contains(t, string(payload), user.LowerName)
+
+		req = NewRequest(t, "GET", root+"/versions")
+		resp = MakeRequest(t, req, http.StatusOK)
+		expected := hex_module.EncodeVersions(user.LowerName, []*hex_module.VersionsPackage{
+			{Name: packageName, Versions: []string{packageVersion, "1.1.0"}},
+		})
+		assert.Equal(t, expected, readSignedPayload(t, resp.Body.Bytes()))
+
+		req = NewRequest(t, "GET", root+"/packages/"+packageName)
+		resp = MakeRequest(t, req, http.StatusOK)
+		payload = readSignedPayload(t, resp.Body.Bytes())
+		assert.Contains(t, string(payload), "decimal")
+		assert.Contains(t, string(payload), "~> 2.0")
+
+		req = NewRequest(t, "GET", root+"/packages/unknown")
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("KeyPair", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		t.Run("Concurrent", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			// org3 has no keys yet, concurrent first requests must agree on them
+			var wg sync.WaitGroup
+			keys := make([]string, 5)
+			for i := range keys {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, pub, err := hex_service.GetOrCreateKeyPair(db.DefaultContext, 3)
+					assert.NoError(t, err)
+					keys[i] = pub
+				}()
+			}
+			wg.Wait()
+
+			pub, err := hex_service.GetPublicKey(db.DefaultContext, 3)
+			require.NoError(t, err)
+			for _, key := range keys {
+				assert.Equal(t, pub, key)
+			}
+		})
+
+		t.Run("Regenerate", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", root+"/public_key")
+			oldKey := MakeRequest(t, req, http.StatusOK).Body.String()
+
+			session := loginUser(t, user.Name)
+			resp := session.MakeRequest(t, NewRequest(t, "GET", "/user/settings/packages"), http.StatusOK)
+			assert.Contains(t, resp.Body.String(), "-----BEGIN PUBLIC KEY-----")
+
+			req = NewRequestWithValues(t, "POST", "/user/settings/packages/hex/regenerate_keypair", map[string]string{
+				"_csrf": GetCSRF(t, session, "/user/settings/packages"),
+			})
+			session.MakeRequest(t, req, http.StatusSeeOther)
+
+			req = NewRequest(t, "GET", root+"/public_key")
+			newKey := MakeRequest(t, req, http.StatusOK).Body.String()
+			assert.NotEqual(t, oldKey, newKey)
+
+			// the resources are signed with the new key
+			req = NewRequest(t, "GET", root+"/names")
+			resp = MakeRequest(t, req, http.StatusOK)
+			readSignedPayload(t, resp.Body.Bytes())
+		})
+	})
+
+	t.Run("Retire", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		retireURL := fmt.Sprintf("%s/api/packages/%s/releases/%s/retire", root, packageName, packageVersion)
+
+		body, err := hex_module.EncodeExternalTerm(map[string]any{"reason": "security", "message": "CVE"})
+		require.NoError(t, err)
+
+		req := NewRequestWithBody(t, "POST", retireURL, bytes.NewReader(body)).
+			SetHeader("Content-Type", hex_module.ContentTypeErlang)
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequestWithBody(t, "POST", retireURL, bytes.NewReader([]byte(`{"reason":"unknown"}`))).
+			SetHeader("Content-Type", "application/json").
+			SetHeader("Authorization", token)
+		MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		req = NewRequestWithBody(t, "POST", retireURL, bytes.NewReader(body)).
+			SetHeader("Content-Type", hex_module.ContentTypeErlang).
+			SetHeader("Authorization", token)
+		MakeRequest(t, req, http.StatusNoContent)
+
+		req = NewRequest(t, "GET", root+"/versions")
+		resp := MakeRequest(t, req, http.StatusOK)
+		expected := hex_module.EncodeVersions(user.LowerName, []*hex_module.VersionsPackage{
+			{Name: packageName, Versions: []string{packageVersion, "1.1.0"}, Retired: []int32{0}},
+		})
+		assert.Equal(t, expected, readSignedPayload(t, resp.Body.Bytes()))
+
+		req = NewRequest(t, "DELETE", retireURL).
+			SetHeader("Authorization", token)
+		MakeRequest(t, req, http.StatusNoContent)
+
+		req = NewRequest(t, "GET", root+"/versions")
+		resp = MakeRequest(t, req, http.StatusOK)
+		expected = hex_module.EncodeVersions(user.LowerName, []*hex_module.VersionsPackage{
+			{Name: packageName, Versions: []string{packageVersion, "1.1.0"}},
+		})
+		assert.Equal(t, expected, readSignedPayload(t, resp.Body.Bytes()))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		deleteURL := fmt.Sprintf("%s/api/packages/%s/releases/%s", root, packageName, packageVersion)
+
+		req := NewRequest(t, "DELETE", deleteURL)
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequest(t, "DELETE", deleteURL).
+			SetHeader("Authorization", token)
+		MakeRequest(t, req, http.StatusNoContent)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeHex)
+		require.NoError(t, err)
+		assert.Len(t, pvs, 1)
+	})
+}
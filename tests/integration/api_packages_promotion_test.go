@@ -0,0 +1,83 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package integration
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	auth_model "forgejo.org/models/auth"
+	packages_model "forgejo.org/models/packages"
+	"forgejo.org/models/unittest"
+	user_model "forgejo.org/models/user"
+	container_module "forgejo.org/modules/packages/container"
+	"forgejo.org/modules/setting"
+	api "forgejo.org/modules/structs"
+	"forgejo.org/tests"
+
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackagePromotionContainer(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	// user2 owns org3
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	org := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 3})
+	token := getUserToken(t, user.Name, auth_model.AccessTokenScopeWritePackage)
+
+	image := "promoted-image"
+	sourceURL := fmt.Sprintf("%sv2/%s/%s", setting.AppURL, user.Name, image)
+	targetURL := fmt.Sprintf("%sv2/%s/%s", setting.AppURL, org.Name, image)
+
+	configDigest := fmt.Sprintf("sha256:%x", sha256.Sum256(nil))
+	req := NewRequestWithBody(t, "POST", fmt.Sprintf("%s/blobs/uploads?digest=%s", sourceURL, configDigest), bytes.NewReader(nil)).
+		AddBasicAuth(user.Name)
+	MakeRequest(t, req, http.StatusCreated)
+
+	// an image index which references an untagged image manifest
+	manifest := fmt.Sprintf(`{"schemaVersion":2,"mediaType":"%s","config":{"mediaType":"application/vnd.oci.empty.v1+json","digest":"%s","size":0},"layers":[]}`, oci.MediaTypeImageManifest, configDigest)
+	manifestDigest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(manifest)))
+	req = NewRequestWithBody(t, "PUT", fmt.Sprintf("%s/manifests/%s", sourceURL, manifestDigest), strings.NewReader(manifest)).
+		AddBasicAuth(user.Name).
+		SetHeader("Content-Type", oci.MediaTypeImageManifest)
+	MakeRequest(t, req, http.StatusCreated)
+
+	index := fmt.Sprintf(`{"schemaVersion":2,"mediaType":"%s","manifests":[{"mediaType":"%s","digest":"%s","platform":{"os":"linux","architecture":"amd64"}}]}`, oci.MediaTypeImageIndex, oci.MediaTypeImageManifest, manifestDigest)
+	req = NewRequestWithBody(t, "PUT", fmt.Sprintf("%s/manifests/latest", sourceURL), strings.NewReader(index)).
+		AddBasicAuth(user.Name).
+		SetHeader("Content-Type", oci.MediaTypeImageIndex)
+	MakeRequest(t, req, http.StatusCreated)
+
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/packages/%s/container/%s/latest/-/promote", user.Name, image), &api.PromotePackageOption{
+		TargetOwner: org.Name,
+		Channel:     "prod",
+	}).AddTokenAuth(token)
+	resp := MakeRequest(t, req, http.StatusOK)
+	var promoted *api.Package
+	DecodeJSON(t, resp, &promoted)
+	assert.Equal(t, org.Name, promoted.Owner.UserName)
+	assert.Equal(t, "latest", promoted.Version)
+
+	// the index, the manifest it references and the blobs can be pulled from the target owner
+	for _, reference := range []string{"latest", manifestDigest} {
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/manifests/%s", targetURL, reference)).
+			AddBasicAuth(user.Name)
+		MakeRequest(t, req, http.StatusOK)
+	}
+	req = NewRequest(t, "HEAD", fmt.Sprintf("%s/blobs/%s", targetURL, configDigest)).
+		AddBasicAuth(user.Name)
+	MakeRequest(t, req, http.StatusOK)
+
+	unittest.AssertExistsAndLoadBean(t, &packages_model.PackageProperty{
+		RefType: packages_model.PropertyTypePackage,
+		Name:    container_module.PropertyRepository,
+		Value:   org.LowerName + "/" + image,
+	})
+}
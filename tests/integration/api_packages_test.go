@@ -22,6 +22,7 @@ import (
 	"forgejo.org/modules/setting"
 	api "forgejo.org/modules/structs"
 	"forgejo.org/modules/util"
+	actions_service "forgejo.org/services/actions"
 	packages_service "forgejo.org/services/packages"
 	packages_cleanup_service "forgejo.org/services/packages/cleanup"
 	"forgejo.org/tests"
@@ -420,6 +421,103 @@ func TestPackageAccess(t *testing.T) {
 	})
 }
 
+func TestPackageProvenance(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	// task 47 is running in repo4 which is owned by user5
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5})
+	other := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+
+	taskToken, err := actions_service.CreateAuthorizationToken(47, 791, 192)
+	require.NoError(t, err)
+
+	packageName := "provenance-package"
+	packageVersion := "1.0.0"
+
+	t.Run("Upload", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequestWithBody(t, "PUT", fmt.Sprintf("/api/packages/%s/generic/%s/%s/file.bin", other.Name, packageName, packageVersion), bytes.NewReader([]byte{1})).
+			AddTokenAuth(taskToken)
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequestWithBody(t, "PUT", fmt.Sprintf("/api/packages/%s/generic/%s/%s/file.bin", owner.Name, packageName, packageVersion), bytes.NewReader([]byte{1})).
+			AddTokenAuth(taskToken)
+		MakeRequest(t, req, http.StatusCreated)
+
+		pv, err := packages_model.GetVersionByNameAndVersion(db.DefaultContext, owner.ID, packages_model.TypeGeneric, packageName, packageVersion)
+		require.NoError(t, err)
+		assert.True(t, pv.HasProvenance())
+		assert.EqualValues(t, 4, pv.ProvenanceRepoID)
+		assert.EqualValues(t, 791, pv.ProvenanceRunID)
+		assert.EqualValues(t, 47, pv.ProvenanceTaskID)
+		assert.Equal(t, "c2d72f548424103f01ee1dc02889c1e2bff816b0", pv.ProvenanceCommitSHA)
+		assert.Equal(t, "refs/heads/master", pv.ProvenanceRef)
+		assert.Equal(t, "artifact.yaml", pv.ProvenanceWorkflowID)
+	})
+
+	t.Run("Container", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		// container clients exchange the task token for a token of the registry
+		registryToken, err := packages_service.CreateAuthorizationToken(user_model.NewActionsUser(), "", 47)
+		require.NoError(t, err)
+		registryToken = "Bearer " + registryToken
+
+		url := fmt.Sprintf("%sv2/%s/%s", setting.AppURL, owner.Name, packageName)
+		configDigest := fmt.Sprintf("sha256:%x", sha256.Sum256(nil))
+		req := NewRequestWithBody(t, "POST", fmt.Sprintf("%s/blobs/uploads?digest=%s", url, configDigest), bytes.NewReader(nil)).
+			AddTokenAuth(registryToken)
+		MakeRequest(t, req, http.StatusCreated)
+
+		manifest := fmt.Sprintf(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.empty.v1+json","digest":"%s","size":0},"layers":[]}`, configDigest)
+		req = NewRequestWithBody(t, "PUT", fmt.Sprintf("%s/manifests/%s", url, packageVersion), strings.NewReader(manifest)).
+			AddTokenAuth(registryToken).
+			SetHeader("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		MakeRequest(t, req, http.StatusCreated)
+
+		pv, err := packages_model.GetVersionByNameAndVersion(db.DefaultContext, owner.ID, packages_model.TypeContainer, packageName, packageVersion)
+		require.NoError(t, err)
+		assert.EqualValues(t, 791, pv.ProvenanceRunID)
+		assert.EqualValues(t, 47, pv.ProvenanceTaskID)
+	})
+
+	t.Run("API", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("/api/v1/packages/%s/generic/%s/%s/provenance", owner.Name, packageName, packageVersion))
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var statement *api.PackageProvenance
+		DecodeJSON(t, resp, &statement)
+		assert.Equal(t, "https://slsa.dev/provenance/v1", statement.PredicateType)
+		require.Len(t, statement.Subject, 1)
+		assert.Equal(t, "file.bin", statement.Subject[0].Name)
+		assert.NotEmpty(t, statement.Subject[0].Digest["sha256"])
+		require.Len(t, statement.Predicate.BuildDefinition.ResolvedDependencies, 1)
+		assert.Equal(t, "c2d72f548424103f01ee1dc02889c1e2bff816b0", statement.Predicate.BuildDefinition.ResolvedDependencies[0].Digest["gitCommit"])
+		assert.Equal(t, setting.AppURL+"user5/repo4/actions/runs/187", statement.Predicate.RunDetails.Metadata.InvocationID)
+
+		uploadURL := fmt.Sprintf("/api/packages/%s/generic/%s/%s/file.bin", owner.Name, packageName, "2.0.0")
+		req = NewRequestWithBody(t, "PUT", uploadURL, bytes.NewReader([]byte{1})).
+			AddBasicAuth(owner.Name)
+		MakeRequest(t, req, http.StatusCreated)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/packages/%s/generic/%s/%s/provenance", owner.Name, packageName, "2.0.0"))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("View", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("/%s/-/packages/generic/%s/%s", owner.Name, packageName, packageVersion))
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		htmlDoc.AssertElement(t, `a[href="/user5/repo4/actions/runs/187"]`, true)
+	})
+}
+
 func TestPackageQuota(t *testing.T) {
 	defer tests.PrepareTestEnv(t)()
 
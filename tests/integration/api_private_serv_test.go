@@ -5,22 +5,29 @@ package integration
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"net/url"
 	"testing"
 
 	asymkey_model "forgejo.org/models/asymkey"
+	audit_model "forgejo.org/models/audit"
+	"forgejo.org/models/db"
 	"forgejo.org/models/perm"
 	"forgejo.org/modules/private"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/test"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestAPIPrivateNoServ(t *testing.T) {
 	onGiteaRun(t, func(*testing.T, *url.URL) {
 		ctx, cancel := context.WithCancel(t.Context())
 		defer cancel()
-		key, user, err := private.ServNoCommand(ctx, 1)
+		key, user, err := private.ServNoCommand(ctx, 1, nil, "")
 		require.NoError(t, err)
 		assert.Equal(t, int64(2), user.ID)
 		assert.Equal(t, "user2", user.Name)
@@ -30,7 +37,7 @@ func TestAPIPrivateNoServ(t *testing.T) {
 		deployKey, err := asymkey_model.AddDeployKey(ctx, 1, "test-deploy", "sk-ecdsa-sha2-nistp256@openssh.com AAAAInNrLWVjZHNhLXNoYTItbmlzdHAyNTZAb3BlbnNzaC5jb20AAAAIbmlzdHAyNTYAAABBBGXEEzWmm1dxb+57RoK5KVCL0w2eNv9cqJX2AGGVlkFsVDhOXHzsadS3LTK4VlEbbrDMJdoti9yM8vclA8IeRacAAAAEc3NoOg== nocomment", false)
 		require.NoError(t, err)
 
-		key, user, err = private.ServNoCommand(ctx, deployKey.KeyID)
+		key, user, err = private.ServNoCommand(ctx, deployKey.KeyID, nil, "")
 		require.NoError(t, err)
 		assert.Empty(t, user)
 		assert.Equal(t, deployKey.KeyID, key.ID)
@@ -44,7 +51,7 @@ func TestAPIPrivateServ(t *testing.T) {
 		defer cancel()
 
 		// Can push to a repo we own
-		results, extra := private.ServCommand(ctx, 1, "user2", "repo1", perm.AccessModeWrite, "git-upload-pack", "")
+		results, extra := private.ServCommand(ctx, 1, nil, "127.0.0.1", "user2", "repo1", perm.AccessModeWrite, "git-upload-pack", "")
 		require.NoError(t, extra.Error)
 		assert.False(t, results.IsWiki)
 		assert.Zero(t, results.DeployKeyID)
@@ -57,17 +64,17 @@ func TestAPIPrivateServ(t *testing.T) {
 		assert.Equal(t, int64(1), results.RepoID)
 
 		// Cannot push to a private repo we're not associated with
-		results, extra = private.ServCommand(ctx, 1, "user15", "big_test_private_1", perm.AccessModeWrite, "git-upload-pack", "")
+		results, extra = private.ServCommand(ctx, 1, nil, "127.0.0.1", "user15", "big_test_private_1", perm.AccessModeWrite, "git-upload-pack", "")
 		require.Error(t, extra.Error)
 		assert.Empty(t, results)
 
 		// Cannot pull from a private repo we're not associated with
-		results, extra = private.ServCommand(ctx, 1, "user15", "big_test_private_1", perm.AccessModeRead, "git-upload-pack", "")
+		results, extra = private.ServCommand(ctx, 1, nil, "127.0.0.1", "user15", "big_test_private_1", perm.AccessModeRead, "git-upload-pack", "")
 		require.Error(t, extra.Error)
 		assert.Empty(t, results)
 
 		// Can pull from a public repo we're not associated with
-		results, extra = private.ServCommand(ctx, 1, "user15", "big_test_public_1", perm.AccessModeRead, "git-upload-pack", "")
+		results, extra = private.ServCommand(ctx, 1, nil, "127.0.0.1", "user15", "big_test_public_1", perm.AccessModeRead, "git-upload-pack", "")
 		require.NoError(t, extra.Error)
 		assert.False(t, results.IsWiki)
 		assert.Zero(t, results.DeployKeyID)
@@ -80,7 +87,7 @@ func TestAPIPrivateServ(t *testing.T) {
 		assert.Equal(t, int64(17), results.RepoID)
 
 		// Cannot push to a public repo we're not associated with
-		results, extra = private.ServCommand(ctx, 1, "user15", "big_test_public_1", perm.AccessModeWrite, "git-upload-pack", "")
+		results, extra = private.ServCommand(ctx, 1, nil, "127.0.0.1", "user15", "big_test_public_1", perm.AccessModeWrite, "git-upload-pack", "")
 		require.Error(t, extra.Error)
 		assert.Empty(t, results)
 
@@ -89,7 +96,7 @@ func TestAPIPrivateServ(t *testing.T) {
 		require.NoError(t, err)
 
 		// Can pull from repo we're a deploy key for
-		results, extra = private.ServCommand(ctx, deployKey.KeyID, "user15", "big_test_private_1", perm.AccessModeRead, "git-upload-pack", "")
+		results, extra = private.ServCommand(ctx, deployKey.KeyID, nil, "127.0.0.1", "user15", "big_test_private_1", perm.AccessModeRead, "git-upload-pack", "")
 		require.NoError(t, extra.Error)
 		assert.False(t, results.IsWiki)
 		assert.NotZero(t, results.DeployKeyID)
@@ -102,17 +109,17 @@ func TestAPIPrivateServ(t *testing.T) {
 		assert.Equal(t, int64(19), results.RepoID)
 
 		// Cannot push to a private repo with reading key
-		results, extra = private.ServCommand(ctx, deployKey.KeyID, "user15", "big_test_private_1", perm.AccessModeWrite, "git-upload-pack", "")
+		results, extra = private.ServCommand(ctx, deployKey.KeyID, nil, "127.0.0.1", "user15", "big_test_private_1", perm.AccessModeWrite, "git-upload-pack", "")
 		require.Error(t, extra.Error)
 		assert.Empty(t, results)
 
 		// Cannot pull from a private repo we're not associated with
-		results, extra = private.ServCommand(ctx, deployKey.ID, "user15", "big_test_private_2", perm.AccessModeRead, "git-upload-pack", "")
+		results, extra = private.ServCommand(ctx, deployKey.ID, nil, "127.0.0.1", "user15", "big_test_private_2", perm.AccessModeRead, "git-upload-pack", "")
 		require.Error(t, extra.Error)
 		assert.Empty(t, results)
 
 		// Cannot pull from a public repo we're not associated with
-		results, extra = private.ServCommand(ctx, deployKey.ID, "user15", "big_test_public_1", perm.AccessModeRead, "git-upload-pack", "")
+		results, extra = private.ServCommand(ctx, deployKey.ID, nil, "127.0.0.1", "user15", "big_test_public_1", perm.AccessModeRead, "git-upload-pack", "")
 		require.Error(t, extra.Error)
 		assert.Empty(t, results)
 
@@ -121,12 +128,12 @@ func TestAPIPrivateServ(t *testing.T) {
 		require.NoError(t, err)
 
 		// Cannot push to a private repo with reading key
-		results, extra = private.ServCommand(ctx, deployKey.KeyID, "user15", "big_test_private_1", perm.AccessModeWrite, "git-upload-pack", "")
+		results, extra = private.ServCommand(ctx, deployKey.KeyID, nil, "127.0.0.1", "user15", "big_test_private_1", perm.AccessModeWrite, "git-upload-pack", "")
 		require.Error(t, extra.Error)
 		assert.Empty(t, results)
 
 		// Can pull from repo we're a writing deploy key for
-		results, extra = private.ServCommand(ctx, deployKey.KeyID, "user15", "big_test_private_2", perm.AccessModeRead, "git-upload-pack", "")
+		results, extra = private.ServCommand(ctx, deployKey.KeyID, nil, "127.0.0.1", "user15", "big_test_private_2", perm.AccessModeRead, "git-upload-pack", "")
 		require.NoError(t, extra.Error)
 		assert.False(t, results.IsWiki)
 		assert.NotZero(t, results.DeployKeyID)
@@ -139,7 +146,7 @@ func TestAPIPrivateServ(t *testing.T) {
 		assert.Equal(t, int64(20), results.RepoID)
 
 		// Can push to repo we're a writing deploy key for
-		results, extra = private.ServCommand(ctx, deployKey.KeyID, "user15", "big_test_private_2", perm.AccessModeWrite, "git-upload-pack", "")
+		results, extra = private.ServCommand(ctx, deployKey.KeyID, nil, "127.0.0.1", "user15", "big_test_private_2", perm.AccessModeWrite, "git-upload-pack", "")
 		require.NoError(t, extra.Error)
 		assert.False(t, results.IsWiki)
 		assert.NotZero(t, results.DeployKeyID)
@@ -152,3 +159,41 @@ func TestAPIPrivateServ(t *testing.T) {
 		assert.Equal(t, int64(20), results.RepoID)
 	})
 }
+
+func TestAPIPrivateServCertificate(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, _ *url.URL) {
+		defer test.MockVariableValue(&setting.Audit.Enabled, true)()
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		sshPub, err := ssh.NewPublicKey(pub)
+		require.NoError(t, err)
+		ca := &asymkey_model.SSHCertificateAuthority{Name: "ca", Content: string(ssh.MarshalAuthorizedKey(sshPub)), Mapping: asymkey_model.SSHCertificateAuthorityMappingUsername}
+		require.NoError(t, asymkey_model.AddSSHCertificateAuthority(ctx, ca))
+
+		cert := &private.ServCertificate{AuthorityID: ca.ID, UserID: 2, KeyID: "user2@laptop", Serial: "42"}
+		results, extra := private.ServCommand(ctx, 0, cert, "127.0.0.1:22", "user2", "repo1", perm.AccessModeRead, "git-upload-pack", "")
+		require.NoError(t, extra.Error)
+		assert.Equal(t, "user2", results.UserName)
+		assert.Equal(t, "ca", results.KeyName)
+
+		// the certificate which authenticated the user is recorded in the audit log
+		events, err := db.Find[audit_model.Event](ctx, audit_model.FindEventsOptions{Action: audit_model.ActionUserSignIn, ActorID: 2})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, "127.0.0.1", events[0].IPAddress)
+		assert.Contains(t, events[0].After, `"ssh_certificate_authority":"ca"`)
+		assert.Contains(t, events[0].After, ca.Fingerprint)
+		assert.Contains(t, events[0].After, `"ssh_certificate_key_id":"user2@laptop"`)
+		assert.Contains(t, events[0].After, `"ssh_certificate_serial":"42"`)
+
+		_, user, err := private.ServNoCommand(ctx, 0, cert, "127.0.0.1:22")
+		require.NoError(t, err)
+		assert.Equal(t, "user2", user.Name)
+		events, err = db.Find[audit_model.Event](ctx, audit_model.FindEventsOptions{Action: audit_model.ActionUserSignIn, ActorID: 2})
+		require.NoError(t, err)
+		assert.Len(t, events, 2)
+	})
+}
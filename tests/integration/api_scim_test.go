@@ -0,0 +1,221 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/organization"
+	"forgejo.org/models/unittest"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/json"
+	"forgejo.org/modules/scim"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/test"
+	"forgejo.org/routers"
+	"forgejo.org/tests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPISCIM(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+	defer test.MockVariableValue(&setting.SCIM.Enabled, true)()
+	defer test.MockVariableValue(&testWebRoutes, routers.NormalRoutes())()
+
+	adminToken := getTokenForLoggedInUser(t, loginUser(t, "user1"), auth_model.AccessTokenScopeWriteAdmin)
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Users"), http.StatusUnauthorized)
+
+		readToken := getTokenForLoggedInUser(t, loginUser(t, "user1"), auth_model.AccessTokenScopeReadAdmin)
+		MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Users").AddTokenAuth(readToken), http.StatusForbidden)
+
+		userToken := getTokenForLoggedInUser(t, loginUser(t, "user2"), auth_model.AccessTokenScopeAll)
+		resp := MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Users").AddTokenAuth(userToken), http.StatusForbidden)
+		assert.Equal(t, scim.ContentType, resp.Header().Get("Content-Type"))
+	})
+
+	t.Run("ServiceProviderConfig", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		resp := MakeRequest(t, NewRequest(t, "GET", "/scim/v2/ServiceProviderConfig").AddTokenAuth(adminToken), http.StatusOK)
+		var config scim.ServiceProviderConfig
+		DecodeJSON(t, resp, &config)
+		assert.True(t, config.Bulk.Supported)
+		assert.Equal(t, setting.SCIM.MaxBulkOperations, config.Bulk.MaxOperations)
+	})
+
+	var userID string
+	t.Run("Users", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequestWithJSON(t, "POST", "/scim/v2/Users", &scim.User{
+			Schemas:    []string{scim.SchemaUser},
+			ExternalID: "ext-scimuser",
+			UserName:   "scimuser",
+			Name:       &scim.Name{GivenName: "Scim", FamilyName: "User"},
+			Emails:     []scim.MultiValue{{Value: "scimuser@example.com", Primary: true}},
+		}).AddTokenAuth(adminToken)
+		resp := MakeRequest(t, req, http.StatusCreated)
+		var created scim.User
+		DecodeJSON(t, resp, &created)
+		userID = created.ID
+		assert.Equal(t, created.Meta.Location, resp.Header().Get("Location"))
+		assert.Equal(t, "ext-scimuser", created.ExternalID)
+		require.NotNil(t, created.Active)
+		assert.True(t, *created.Active)
+
+		// the user name is unique
+		MakeRequest(t, req, http.StatusConflict)
+
+		resp = MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Users?filter="+url.QueryEscape(`userName eq "SCIMUSER"`)).AddTokenAuth(adminToken), http.StatusOK)
+		var list scim.ListResponse
+		DecodeJSON(t, resp, &list)
+		assert.EqualValues(t, 1, list.TotalResults)
+
+		resp = MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Users?count=2&filter="+url.QueryEscape(`emails[value co "example.com"]`)).AddTokenAuth(adminToken), http.StatusOK)
+		list = scim.ListResponse{}
+		DecodeJSON(t, resp, &list)
+		assert.Greater(t, list.TotalResults, int64(2))
+		assert.Len(t, list.Resources, 2)
+
+		MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Users?filter="+url.QueryEscape(`userName eq`)).AddTokenAuth(adminToken), http.StatusBadRequest)
+
+		req = NewRequestWithJSON(t, "PATCH", "/scim/v2/Users/"+userID, &scim.PatchRequest{
+			Schemas: []string{scim.SchemaPatchOp},
+			Operations: []scim.PatchOperation{
+				{Op: "replace", Path: "active", Value: false},
+				{Op: "replace", Path: "displayName", Value: "Patched User"},
+			},
+		}).AddTokenAuth(adminToken)
+		resp = MakeRequest(t, req, http.StatusOK)
+		var patched scim.User
+		DecodeJSON(t, resp, &patched)
+		require.NotNil(t, patched.Active)
+		assert.False(t, *patched.Active)
+
+		id, _ := strconv.ParseInt(userID, 10, 64)
+		u := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: id})
+		assert.True(t, u.ProhibitLogin)
+		assert.Equal(t, "Patched User", u.FullName)
+
+		MakeRequest(t, NewRequestWithJSON(t, "PATCH", "/scim/v2/Users/"+userID, &scim.PatchRequest{
+			Schemas:    []string{scim.SchemaPatchOp},
+			Operations: []scim.PatchOperation{{Op: "replace", Path: "active", Value: true}},
+		}).AddTokenAuth(adminToken), http.StatusOK)
+		u = unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: id})
+		assert.False(t, u.ProhibitLogin)
+
+		// deleting deactivates the user
+		MakeRequest(t, NewRequest(t, "DELETE", "/scim/v2/Users/"+userID).AddTokenAuth(adminToken), http.StatusNoContent)
+		u = unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: id})
+		assert.True(t, u.ProhibitLogin)
+
+		// organizations are not users
+		MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Users/3").AddTokenAuth(adminToken), http.StatusNotFound)
+	})
+
+	t.Run("Groups", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequestWithJSON(t, "POST", "/scim/v2/Groups", &scim.Group{
+			Schemas:     []string{scim.SchemaGroup},
+			DisplayName: "org3/scimteam",
+			Members:     []scim.MultiValue{{Value: "2"}, {Value: "4"}},
+		}).AddTokenAuth(adminToken)
+		resp := MakeRequest(t, req, http.StatusCreated)
+		var created scim.Group
+		DecodeJSON(t, resp, &created)
+		assert.Len(t, created.Members, 2)
+
+		team := unittest.AssertExistsAndLoadBean(t, &organization.Team{OrgID: 3, LowerName: "scimteam"})
+		unittest.AssertExistsAndLoadBean(t, &organization.TeamUser{TeamID: team.ID, UID: 2})
+		unittest.AssertExistsAndLoadBean(t, &organization.TeamUser{TeamID: team.ID, UID: 4})
+
+		MakeRequest(t, NewRequestWithJSON(t, "POST", "/scim/v2/Groups", &scim.Group{
+			Schemas:     []string{scim.SchemaGroup},
+			DisplayName: "no-such-org/team",
+		}).AddTokenAuth(adminToken), http.StatusBadRequest)
+
+		req = NewRequestWithJSON(t, "PATCH", "/scim/v2/Groups/"+created.ID, &scim.PatchRequest{
+			Schemas:    []string{scim.SchemaPatchOp},
+			Operations: []scim.PatchOperation{{Op: "remove", Path: `members[value eq "4"]`}},
+		}).AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusOK)
+		unittest.AssertNotExistsBean(t, &organization.TeamUser{TeamID: team.ID, UID: 4})
+		unittest.AssertExistsAndLoadBean(t, &organization.TeamUser{TeamID: team.ID, UID: 2})
+
+		resp = MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Groups?filter="+url.QueryEscape(`displayName eq "org3/scimteam"`)).AddTokenAuth(adminToken), http.StatusOK)
+		var list scim.ListResponse
+		DecodeJSON(t, resp, &list)
+		assert.EqualValues(t, 1, list.TotalResults)
+
+		// the owners team can not be deleted
+		MakeRequest(t, NewRequest(t, "DELETE", "/scim/v2/Groups/1").AddTokenAuth(adminToken), http.StatusBadRequest)
+
+		MakeRequest(t, NewRequest(t, "DELETE", "/scim/v2/Groups/"+created.ID).AddTokenAuth(adminToken), http.StatusNoContent)
+		unittest.AssertNotExistsBean(t, &organization.Team{ID: team.ID})
+		MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Groups/"+created.ID).AddTokenAuth(adminToken), http.StatusNotFound)
+	})
+
+	t.Run("Bulk", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequestWithJSON(t, "POST", "/scim/v2/Bulk", &scim.BulkRequest{
+			Schemas: []string{scim.SchemaBulkRequest},
+			Operations: []scim.BulkOperation{
+				{
+					Method: "POST",
+					BulkID: "u1",
+					Path:   "/Users",
+					Data: map[string]any{
+						"schemas":  []string{scim.SchemaUser},
+						"userName": "scimbulk",
+						"emails":   []map[string]any{{"value": "scimbulk@example.com"}},
+					},
+				},
+				{
+					Method: "POST",
+					Path:   "/Groups",
+					Data: map[string]any{
+						"schemas":     []string{scim.SchemaGroup},
+						"displayName": "org3/scimbulk",
+						"members":     []map[string]any{{"value": "bulkId:u1"}},
+					},
+				},
+				{
+					Method: "DELETE",
+					Path:   "/Users/999999",
+				},
+			},
+		}).AddTokenAuth(adminToken)
+		resp := MakeRequest(t, req, http.StatusOK)
+		var bulk scim.BulkResponse
+		DecodeJSON(t, resp, &bulk)
+		require.Len(t, bulk.Operations, 3)
+		assert.Equal(t, "201", bulk.Operations[0].Status)
+		assert.Equal(t, "201", bulk.Operations[1].Status)
+		assert.Equal(t, "404", bulk.Operations[2].Status)
+
+		u := unittest.AssertExistsAndLoadBean(t, &user_model.User{LowerName: "scimbulk"})
+		assert.Equal(t, fmt.Sprintf("%sscim/v2/Users/%d", setting.AppURL, u.ID), bulk.Operations[0].Location)
+		team := unittest.AssertExistsAndLoadBean(t, &organization.Team{OrgID: 3, LowerName: "scimbulk"})
+		unittest.AssertExistsAndLoadBean(t, &organization.TeamUser{TeamID: team.ID, UID: u.ID})
+
+		defer test.MockVariableValue(&setting.SCIM.MaxBulkOperations, 1)()
+		resp = MakeRequest(t, req, http.StatusRequestEntityTooLarge)
+		var scimErr map[string]any
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &scimErr))
+		assert.Equal(t, scim.ErrorTypeTooMany, scimErr["scimType"])
+	})
+}
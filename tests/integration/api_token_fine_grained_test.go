@@ -0,0 +1,88 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	api "forgejo.org/modules/structs"
+	"forgejo.org/modules/timeutil"
+	"forgejo.org/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createFineGrainedToken(t *testing.T, name string, repoIDs []int64, permissions auth_model.AccessTokenRepoPermissions) *auth_model.AccessToken {
+	t.Helper()
+	token := &auth_model.AccessToken{
+		UID:             2,
+		Name:            name,
+		FineGrained:     true,
+		RepoIDs:         repoIDs,
+		RepoPermissions: permissions,
+		ExpiresUnix:     timeutil.TimeStampNow().AddDuration(time.Hour),
+	}
+	require.NoError(t, auth_model.NewAccessToken(db.DefaultContext, token))
+	return token
+}
+
+func TestAPIFineGrainedToken(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	t.Run("LimitedToRepository", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		// repo2 is a private repository of user2
+		token := createFineGrainedToken(t, "contents", []int64{2}, "read:contents")
+
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo2").AddTokenAuth(token.Token), http.StatusOK)
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo2/branches").AddTokenAuth(token.Token), http.StatusOK)
+
+		// other repositories of the user are not accessible
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo16").AddTokenAuth(token.Token), http.StatusNotFound)
+
+		// nor are other permissions or routes outside of repositories
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo2/issues").AddTokenAuth(token.Token), http.StatusForbidden)
+		MakeRequest(t, NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo2", &api.EditRepoOption{}).AddTokenAuth(token.Token), http.StatusForbidden)
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/search").AddTokenAuth(token.Token), http.StatusForbidden)
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/user").AddTokenAuth(token.Token), http.StatusForbidden)
+	})
+
+	t.Run("CommitStatuses", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		token := createFineGrainedToken(t, "statuses", []int64{1}, "write:statuses")
+
+		req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/statuses/65f1bf27bc3bf70f64657658635e66094edbcb4d", &api.CreateStatusOption{
+			State:   api.CommitStatusSuccess,
+			Context: "fine-grained",
+		}).AddTokenAuth(token.Token)
+		MakeRequest(t, req, http.StatusCreated)
+
+		// the contents can be read but not written
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo1/contents/README.md").AddTokenAuth(token.Token), http.StatusOK)
+		req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/contents/new-file.txt", &api.CreateFileOptions{
+			ContentBase64: "Zm9v",
+		}).AddTokenAuth(token.Token)
+		MakeRequest(t, req, http.StatusForbidden)
+
+		// a token without the permission can not write commit statuses
+		token = createFineGrainedToken(t, "contents-write", []int64{1}, "write:contents")
+		req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/statuses/65f1bf27bc3bf70f64657658635e66094edbcb4d", &api.CreateStatusOption{
+			State: api.CommitStatusSuccess,
+		}).AddTokenAuth(token.Token)
+		MakeRequest(t, req, http.StatusForbidden)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		token := createFineGrainedToken(t, "expired", []int64{1}, "read:contents")
+		token.ExpiresUnix = timeutil.TimeStampNow().AddDuration(-time.Minute)
+		require.NoError(t, auth_model.UpdateAccessToken(db.DefaultContext, token))
+
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo1").AddTokenAuth(token.Token), http.StatusUnauthorized)
+	})
+}
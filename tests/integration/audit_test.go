@@ -0,0 +1,105 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	audit_model "forgejo.org/models/audit"
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/modules/setting"
+	api "forgejo.org/modules/structs"
+	"forgejo.org/modules/test"
+	"forgejo.org/routers"
+	"forgejo.org/tests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+	defer test.MockVariableValue(&setting.Audit.Enabled, true)()
+	defer test.MockVariableValue(&testWebRoutes, routers.NormalRoutes())()
+
+	// a failed and a successful sign in of user2
+	req := NewRequestWithValues(t, "POST", "/user/login", map[string]string{
+		"_csrf":     GetCSRF(t, emptyTestSession(t), "/user/login"),
+		"user_name": "user2",
+		"password":  "wrong password",
+	})
+	MakeRequest(t, req, http.StatusOK)
+	session := loginUser(t, "user2")
+
+	// user2 owns org3, adding a collaborator to its repository is recorded for the organization
+	token := getTokenForLoggedInUser(t, session, auth_model.AccessTokenScopeWriteRepository, auth_model.AccessTokenScopeReadOrganization)
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/repos/org3/repo3/collaborators/user5", &api.AddCollaboratorOption{}).AddTokenAuth(token)
+	MakeRequest(t, req, http.StatusNoContent)
+
+	t.Run("Admin", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		adminToken := getUserToken(t, "user1", auth_model.AccessTokenScopeReadAdmin)
+
+		listEvents := func(t *testing.T, query string) []*api.AuditEvent {
+			t.Helper()
+			resp := MakeRequest(t, NewRequest(t, "GET", "/api/v1/admin/audit?"+query).AddTokenAuth(adminToken), http.StatusOK)
+			var events []*api.AuditEvent
+			DecodeJSON(t, resp, &events)
+			return events
+		}
+
+		events := listEvents(t, "actor=user2&action=user_sign_in")
+		require.Len(t, events, 1)
+		assert.EqualValues(t, 2, events[0].ActorID)
+		assert.NotEmpty(t, events[0].IPAddress)
+		assert.JSONEq(t, `{"method":"password"}`, events[0].After)
+
+		// the actor of a failed sign in is unknown, only the name they tried is recorded
+		events = listEvents(t, "actor=user2&action=user_sign_in_failed")
+		require.Len(t, events, 1)
+		assert.Zero(t, events[0].ActorID)
+		assert.JSONEq(t, `{"method":"password"}`, events[0].After)
+
+		events = listEvents(t, "action=repo_collaborator_add")
+		require.Len(t, events, 1)
+		assert.Equal(t, "org3/repo3", events[0].TargetName)
+		assert.JSONEq(t, `{"collaborator":"user5","access_mode":"write"}`, events[0].After)
+
+		// the most recent events come first
+		events = listEvents(t, "actor=user2")
+		require.NotEmpty(t, events)
+		assert.Equal(t, string(audit_model.ActionRepoCollaboratorAdd), events[0].Action)
+
+		resp := loginUser(t, "user1").MakeRequest(t, NewRequest(t, "GET", "/admin/audit?actor=user2"), http.StatusOK)
+		NewHTMLParser(t, resp.Body).AssertElement(t, "a[href='/admin/audit']", true)
+		assert.Contains(t, resp.Body.String(), "org3/repo3")
+	})
+
+	t.Run("Organization", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		resp := MakeRequest(t, NewRequest(t, "GET", "/api/v1/orgs/org3/audit").AddTokenAuth(token), http.StatusOK)
+		var events []*api.AuditEvent
+		DecodeJSON(t, resp, &events)
+		// the sign in events of user2 are personal, they are not shown to the organization
+		require.Len(t, events, 1)
+		assert.Equal(t, string(audit_model.ActionRepoCollaboratorAdd), events[0].Action)
+
+		session.MakeRequest(t, NewRequest(t, "GET", "/org/org3/settings/audit"), http.StatusOK)
+
+		// only owners can see the audit log
+		memberToken := getUserToken(t, "user4", auth_model.AccessTokenScopeReadOrganization)
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/orgs/org3/audit").AddTokenAuth(memberToken), http.StatusForbidden)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		defer test.MockVariableValue(&setting.Audit.Enabled, false)()
+		defer test.MockVariableValue(&testWebRoutes, routers.NormalRoutes())()
+
+		adminToken := getUserToken(t, "user1", auth_model.AccessTokenScopeReadAdmin)
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/admin/audit").AddTokenAuth(adminToken), http.StatusNotFound)
+	})
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"forgejo.org/modules/setting"
+	"forgejo.org/routers/web/auth"
+	"forgejo.org/tests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthDeviceAuthorization(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	// ce5a1322-42a7-11ed-b878-0242ac120002 is a public client in models/fixtures/oauth2_application.yml
+	const clientID = "ce5a1322-42a7-11ed-b878-0242ac120002"
+
+	authorizeDevice := func(t *testing.T) *auth.DeviceAuthorizationResponse {
+		t.Helper()
+		req := NewRequestWithValues(t, "POST", "/login/oauth/device_authorization", map[string]string{
+			"client_id": clientID,
+			"scope":     "openid",
+		})
+		resp := MakeRequest(t, req, http.StatusOK)
+		device := new(auth.DeviceAuthorizationResponse)
+		DecodeJSON(t, resp, device)
+		assert.Equal(t, setting.AppURL+"login/oauth/device", device.VerificationURI)
+		assert.Equal(t, device.VerificationURI+"?user_code="+url.QueryEscape(device.UserCode), device.VerificationURIComplete)
+		assert.Equal(t, setting.OAuth2.DeviceCodePollingInterval, device.Interval)
+		return device
+	}
+
+	pollToken := func(t *testing.T, deviceCode string, expectedStatus int) *httptest.ResponseRecorder {
+		t.Helper()
+		req := NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+			"grant_type":  auth.GrantTypeDeviceCode,
+			"client_id":   clientID,
+			"device_code": deviceCode,
+		})
+		return MakeRequest(t, req, expectedStatus)
+	}
+
+	assertTokenError := func(t *testing.T, deviceCode string, errorCode auth.AccessTokenErrorCode) {
+		t.Helper()
+		resp := pollToken(t, deviceCode, http.StatusBadRequest)
+		parsedError := new(auth.AccessTokenError)
+		DecodeJSON(t, resp, parsedError)
+		assert.Equal(t, errorCode, parsedError.ErrorCode)
+	}
+
+	t.Run("Approve", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		device := authorizeDevice(t)
+
+		assertTokenError(t, device.DeviceCode, auth.AccessTokenErrorCodeAuthorizationPending)
+		// the device polls again without waiting for the interval
+		assertTokenError(t, device.DeviceCode, auth.AccessTokenErrorCodeSlowDown)
+
+		session := loginUser(t, "user4")
+		resp := session.MakeRequest(t, NewRequest(t, "GET", device.VerificationURIComplete), http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		htmlDoc.AssertElement(t, "#authorize-device", true)
+
+		req := NewRequestWithValues(t, "POST", "/login/oauth/device", map[string]string{
+			"_csrf":     htmlDoc.GetCSRF(),
+			"user_code": device.UserCode,
+			"granted":   "true",
+		})
+		session.MakeRequest(t, req, http.StatusSeeOther)
+
+		resp = pollToken(t, device.DeviceCode, http.StatusOK)
+		type response struct {
+			AccessToken  string `json:"access_token"`
+			TokenType    string `json:"token_type"`
+			RefreshToken string `json:"refresh_token"`
+		}
+		parsed := new(response)
+		DecodeJSON(t, resp, parsed)
+		assert.NotEmpty(t, parsed.AccessToken)
+		assert.NotEmpty(t, parsed.RefreshToken)
+
+		// the device code can only be exchanged once
+		assertTokenError(t, device.DeviceCode, auth.AccessTokenErrorCodeInvalidGrant)
+
+		// the code is no longer accepted on the verification page either
+		resp = session.MakeRequest(t, NewRequest(t, "GET", device.VerificationURIComplete), http.StatusOK)
+		NewHTMLParser(t, resp.Body).AssertElement(t, "#authorize-device", false)
+	})
+
+	t.Run("Deny", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		device := authorizeDevice(t)
+
+		session := loginUser(t, "user4")
+		req := NewRequestWithValues(t, "POST", "/login/oauth/device", map[string]string{
+			"_csrf":     GetCSRF(t, session, "/login/oauth/device"),
+			"user_code": device.UserCode,
+			"granted":   "false",
+		})
+		session.MakeRequest(t, req, http.StatusSeeOther)
+
+		assertTokenError(t, device.DeviceCode, auth.AccessTokenErrorCodeAccessDenied)
+		assertTokenError(t, device.DeviceCode, auth.AccessTokenErrorCodeInvalidGrant)
+	})
+
+	t.Run("InvalidClient", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		req := NewRequestWithValues(t, "POST", "/login/oauth/device_authorization", map[string]string{
+			"client_id": "da7da3ba-9a13-4167-856f-3899de0b0138",
+			"scope":     "openid",
+		})
+		resp := MakeRequest(t, req, http.StatusBadRequest)
+		parsedError := new(auth.AccessTokenError)
+		DecodeJSON(t, resp, parsedError)
+		assert.Equal(t, "invalid_client", string(parsedError.ErrorCode))
+		require.Equal(t, "invalid client secret", parsedError.ErrorDescription)
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"strings"
 	"testing"
 
+	audit_model "forgejo.org/models/audit"
 	auth_model "forgejo.org/models/auth"
 	"forgejo.org/models/db"
 	"forgejo.org/models/unittest"
@@ -24,6 +25,7 @@ import (
 	api "forgejo.org/modules/structs"
 	"forgejo.org/modules/test"
 	"forgejo.org/routers/web/auth"
+	"forgejo.org/services/auth/source/oauth2"
 	forgejo_context "forgejo.org/services/context"
 	"forgejo.org/tests"
 
@@ -537,6 +539,88 @@ func TestSignInOAuthCallbackSignIn(t *testing.T) {
 	assert.Greater(t, userAfterLogin.LastLoginUnix, userGitLab.LastLoginUnix)
 }
 
+func TestSignInOAuthCallbackAudit(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+	defer test.MockVariableValue(&setting.Audit.Enabled, true)()
+
+	gitlabName := "gitlab"
+	gitlab := addAuthSource(t, authSourcePayloadGitLabCustom(gitlabName))
+	userGitLabUserID := "5678"
+	userGitLab := &user_model.User{
+		Name:        "gitlabuser",
+		Email:       "gitlabuser@example.com",
+		Passwd:      "gitlabuserpassword",
+		Type:        user_model.UserTypeIndividual,
+		LoginType:   auth_model.OAuth2,
+		LoginSource: gitlab.ID,
+		LoginName:   userGitLabUserID,
+	}
+	defer createUser(t.Context(), t, userGitLab)()
+
+	defer mockCompleteUserAuth(func(res http.ResponseWriter, req *http.Request) (goth.User, error) {
+		return goth.User{
+			Provider: gitlabName,
+			UserID:   userGitLabUserID,
+			Email:    userGitLab.Email,
+			RawData:  map[string]any{"groups": []any{"developers"}},
+		}, nil
+	})()
+	req := NewRequest(t, "GET", fmt.Sprintf("/user/oauth2/%s/callback?code=XYZ&state=XYZ", gitlabName))
+	MakeRequest(t, req, http.StatusSeeOther)
+
+	events, err := db.Find[audit_model.Event](db.DefaultContext, audit_model.FindEventsOptions{Action: audit_model.ActionUserSignIn, ActorID: userGitLab.ID})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.JSONEq(t, `{"method":"oauth2"}`, events[0].After)
+
+	// a user without the required claim is prohibited to log in
+	gitlab.Cfg.(*oauth2.Source).RequiredClaimName = "groups"
+	gitlab.Cfg.(*oauth2.Source).RequiredClaimValue = "admins"
+	require.NoError(t, auth_model.UpdateSource(db.DefaultContext, gitlab))
+	req = NewRequest(t, "GET", fmt.Sprintf("/user/oauth2/%s/callback?code=XYZ&state=XYZ", gitlabName))
+	resp := MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "Account is suspended")
+
+	events, err = db.Find[audit_model.Event](db.DefaultContext, audit_model.FindEventsOptions{Action: audit_model.ActionUserSignInFailed, ActorName: userGitLabUserID})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.JSONEq(t, `{"method":"oauth2"}`, events[0].After)
+}
+
+func TestSignInOAuthCallbackTwoFactorGracePeriod(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+	defer test.MockVariableValue(&setting.RequireTwoFactor, true)()
+
+	gitlabName := "gitlab"
+	gitlab := addAuthSource(t, authSourcePayloadGitLabCustom(gitlabName))
+	userGitLabUserID := "5678"
+	userGitLab := &user_model.User{
+		Name:        "gitlabuser",
+		Email:       "gitlabuser@example.com",
+		Passwd:      "gitlabuserpassword",
+		Type:        user_model.UserTypeIndividual,
+		LoginType:   auth_model.OAuth2,
+		LoginSource: gitlab.ID,
+		LoginName:   userGitLabUserID,
+	}
+	defer createUser(t.Context(), t, userGitLab)()
+
+	defer mockCompleteUserAuth(func(res http.ResponseWriter, req *http.Request) (goth.User, error) {
+		return goth.User{
+			Provider: gitlabName,
+			UserID:   userGitLabUserID,
+			Email:    userGitLab.Email,
+		}, nil
+	})()
+	req := NewRequest(t, "GET", fmt.Sprintf("/user/oauth2/%s/callback?code=XYZ&state=XYZ", gitlabName))
+	MakeRequest(t, req, http.StatusSeeOther)
+
+	// signing in with OAuth2 starts the grace period like signing in with a password
+	started, err := user_model.HasTwoFactorGracePeriod(db.DefaultContext, userGitLab.ID)
+	require.NoError(t, err)
+	assert.True(t, started)
+}
+
 func TestSignInOAuthCallbackWithoutPKCEWhenUnsupported(t *testing.T) {
 	// https://codeberg.org/forgejo/forgejo/issues/4033
 	defer tests.PrepareTestEnv(t)()
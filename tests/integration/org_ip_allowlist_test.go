@@ -0,0 +1,128 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	"forgejo.org/models/organization"
+	"forgejo.org/modules/git"
+	"forgejo.org/modules/lfs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrgIPAllowlist(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		const (
+			inside  = "192.0.2.10:1234"
+			outside = "198.51.100.1:1234"
+		)
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session, auth_model.AccessTokenScopeReadRepository, auth_model.AccessTokenScopeReadOrganization)
+		link := "/org/org3/settings/ip_allowlist"
+
+		request := func(t *testing.T, req *RequestWrapper, remoteAddr string, expectedStatus int) {
+			t.Helper()
+			req.RemoteAddr = remoteAddr
+			session.MakeRequest(t, req, expectedStatus)
+		}
+		setMode := func(t *testing.T, mode organization.IPAllowlistMode, remoteAddr string) {
+			t.Helper()
+			request(t, NewRequestWithValues(t, "POST", link+"/mode", map[string]string{
+				"_csrf": GetCSRF(t, session, "/user/settings"),
+				"mode":  string(mode),
+			}), remoteAddr, http.StatusSeeOther)
+		}
+		assertMode := func(t *testing.T, expected organization.IPAllowlistMode) {
+			t.Helper()
+			mode, err := organization.GetIPAllowlistMode(db.DefaultContext, 3)
+			require.NoError(t, err)
+			assert.Equal(t, expected, mode)
+		}
+
+		// the CSRF tokens are read from a page which is not blocked by the allowlist
+		request(t, NewRequestWithValues(t, "POST", link+"/entries", map[string]string{
+			"_csrf":   GetCSRF(t, session, "/user/settings"),
+			"cidr":    "192.0.2.0/24",
+			"comment": "office",
+		}), outside, http.StatusSeeOther)
+		entries, err := organization.FindIPAllowlistEntries(db.DefaultContext, 3)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "192.0.2.0/24", entries[0].CIDR)
+
+		t.Run("Owners cannot block themselves", func(t *testing.T) {
+			setMode(t, organization.IPAllowlistEnforced, outside)
+			assertMode(t, organization.IPAllowlistDisabled)
+		})
+
+		setMode(t, organization.IPAllowlistEnforced, inside)
+		assertMode(t, organization.IPAllowlistEnforced)
+
+		t.Run("Web", func(t *testing.T) {
+			request(t, NewRequest(t, "GET", "/org3"), inside, http.StatusOK)
+			request(t, NewRequest(t, "GET", "/org3"), outside, http.StatusForbidden)
+			request(t, NewRequest(t, "GET", "/org3/repo3"), inside, http.StatusOK)
+			request(t, NewRequest(t, "GET", "/org3/repo3/issues"), outside, http.StatusForbidden)
+			// repositories of users are not affected
+			request(t, NewRequest(t, "GET", "/user2/repo1"), outside, http.StatusOK)
+		})
+
+		t.Run("API", func(t *testing.T) {
+			request(t, NewRequest(t, "GET", "/api/v1/repos/org3/repo3").AddTokenAuth(token), inside, http.StatusOK)
+			request(t, NewRequest(t, "GET", "/api/v1/repos/org3/repo3").AddTokenAuth(token), outside, http.StatusForbidden)
+			request(t, NewRequest(t, "GET", "/api/v1/orgs/org3").AddTokenAuth(token), outside, http.StatusForbidden)
+			request(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo1").AddTokenAuth(token), outside, http.StatusOK)
+		})
+
+		t.Run("LFS", func(t *testing.T) {
+			lfsRequest := func(method, path string, body any) *RequestWrapper {
+				req := NewRequestWithJSON(t, method, "/org3/repo3.git/info/lfs/"+path, body)
+				req.Header.Set("Accept", lfs.AcceptHeader)
+				req.Header.Set("Content-Type", lfs.MediaType)
+				return req
+			}
+			batch := &lfs.BatchRequest{Operation: "download", Objects: []lfs.Pointer{{Oid: "fb8f7d8435968c4f82a726a92395be4d16f2f63116caf36c8ad35c60831ab041", Size: 6}}}
+			request(t, lfsRequest("POST", "objects/batch", batch), inside, http.StatusOK)
+			request(t, lfsRequest("POST", "objects/batch", batch), outside, http.StatusForbidden)
+			request(t, lfsRequest("GET", "locks", nil), inside, http.StatusOK)
+			request(t, lfsRequest("GET", "locks", nil), outside, http.StatusForbidden)
+			request(t, lfsRequest("POST", "locks", map[string]string{"path": "README.md"}), outside, http.StatusForbidden)
+			request(t, lfsRequest("POST", "locks/verify", map[string]string{}), outside, http.StatusForbidden)
+			request(t, lfsRequest("POST", "locks/1/unlock", map[string]string{}), outside, http.StatusForbidden)
+		})
+
+		u.Path = "org3/repo3.git"
+		u.User = url.UserPassword("user2", userPassword)
+
+		t.Run("Git over HTTP", func(t *testing.T) {
+			// the test server is reached from 127.0.0.1
+			doGitCloneFail(u)(t)
+		})
+
+		t.Run("Dry run", func(t *testing.T) {
+			setMode(t, organization.IPAllowlistDryRun, inside)
+			request(t, NewRequest(t, "GET", "/org3/repo3"), outside, http.StatusOK)
+			request(t, NewRequest(t, "GET", "/api/v1/repos/org3/repo3").AddTokenAuth(token), outside, http.StatusOK)
+			require.NoError(t, git.Clone(git.DefaultContext, u.String(), t.TempDir(), git.CloneRepoOptions{}))
+		})
+
+		t.Run("Owners cannot remove their own range", func(t *testing.T) {
+			setMode(t, organization.IPAllowlistEnforced, inside)
+			request(t, NewRequestWithValues(t, "POST", fmt.Sprintf("%s/entries/%d/delete", link, entries[0].ID), map[string]string{
+				"_csrf": GetCSRF(t, session, "/user/settings"),
+			}), inside, http.StatusSeeOther)
+			entries, err := organization.FindIPAllowlistEntries(db.DefaultContext, 3)
+			require.NoError(t, err)
+			assert.Len(t, entries, 1)
+		})
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	"forgejo.org/models/organization"
+	"forgejo.org/models/perm"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/lfs"
+	"forgejo.org/modules/private"
+	"forgejo.org/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrgTwoFactorRequirement(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session, auth_model.AccessTokenScopeReadRepository)
+
+		servCommand := func(ownerName, repoName string) error {
+			_, extra := private.ServCommand(t.Context(), 1, nil, "127.0.0.1", ownerName, repoName, perm.AccessModeRead, "git-upload-pack", "")
+			return extra.Error
+		}
+
+		t.Run("Owner without two-factor authentication", func(t *testing.T) {
+			req := NewRequestWithValues(t, "POST", "/org/org3/settings/two_factor", map[string]string{
+				"_csrf":               GetCSRF(t, session, "/org/org3/settings"),
+				"two_factor_required": "on",
+			})
+			session.MakeRequest(t, req, http.StatusSeeOther)
+
+			required, err := organization.IsTwoFactorRequired(db.DefaultContext, 3)
+			require.NoError(t, err)
+			assert.False(t, required)
+		})
+
+		require.NoError(t, organization.SetTwoFactorRequired(db.DefaultContext, 3, true))
+
+		t.Run("Member who only uses an access token", func(t *testing.T) {
+			// the grace period starts on the first request if the member had none
+			require.NoError(t, user_model.DeleteUserSetting(db.DefaultContext, 2, user_model.SettingsKeyTwoFactorGraceDeadline))
+			MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/org3/repo3").AddTokenAuth(token), http.StatusOK)
+			deadline, err := user_model.GetUserSetting(db.DefaultContext, 2, user_model.SettingsKeyTwoFactorGraceDeadline)
+			require.NoError(t, err)
+			assert.NotEmpty(t, deadline)
+
+			require.NoError(t, user_model.SetUserSetting(db.DefaultContext, 2, user_model.SettingsKeyTwoFactorGraceDeadline, "1"))
+			MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/org3/repo3").AddTokenAuth(token), http.StatusForbidden)
+			require.NoError(t, user_model.DeleteUserSetting(db.DefaultContext, 2, user_model.SettingsKeyTwoFactorGraceDeadline))
+		})
+
+		t.Run("Grace period", func(t *testing.T) {
+			// the member is sent to set up two-factor authentication once
+			resp := session.MakeRequest(t, NewRequest(t, "GET", "/"), http.StatusSeeOther)
+			assert.Equal(t, "/user/settings/security", test.RedirectURL(resp))
+			session.MakeRequest(t, NewRequest(t, "GET", "/org3/repo3"), http.StatusOK)
+
+			MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/org3/repo3").AddTokenAuth(token), http.StatusOK)
+			require.NoError(t, servCommand("org3", "repo3"))
+		})
+
+		t.Run("Grace period expired", func(t *testing.T) {
+			require.NoError(t, user_model.SetUserSetting(db.DefaultContext, 2, user_model.SettingsKeyTwoFactorGraceDeadline, "1"))
+
+			resp := session.MakeRequest(t, NewRequest(t, "GET", "/user2/repo1"), http.StatusSeeOther)
+			assert.Equal(t, "/user/settings/security", test.RedirectURL(resp))
+			session.MakeRequest(t, NewRequest(t, "GET", "/user/settings/security"), http.StatusOK)
+
+			MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/org3/repo3").AddTokenAuth(token), http.StatusForbidden)
+			MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo1").AddTokenAuth(token), http.StatusOK)
+			require.Error(t, servCommand("org3", "repo3"))
+			require.NoError(t, servCommand("user2", "repo1"))
+
+			req := NewRequestWithJSON(t, "GET", "/org3/repo3.git/info/lfs/locks", nil)
+			req.Header.Set("Accept", lfs.AcceptHeader)
+			req.Header.Set("Content-Type", lfs.MediaType)
+			session.MakeRequest(t, req, http.StatusForbidden)
+		})
+
+		t.Run("Two-factor authentication enabled", func(t *testing.T) {
+			require.NoError(t, db.Insert(db.DefaultContext, &auth_model.TwoFactor{UID: 2}))
+
+			session.MakeRequest(t, NewRequest(t, "GET", "/org3/repo3"), http.StatusOK)
+			MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/org3/repo3").AddTokenAuth(token), http.StatusOK)
+			require.NoError(t, servCommand("org3", "repo3"))
+
+			// the last second factor cannot be removed
+			req := NewRequestWithValues(t, "POST", "/user/settings/security/two_factor/disable", map[string]string{
+				"_csrf": GetCSRF(t, session, "/user/settings/security"),
+			})
+			session.MakeRequest(t, req, http.StatusSeeOther)
+			has, err := auth_model.HasTOTPByUID(db.DefaultContext, 2)
+			require.NoError(t, err)
+			assert.True(t, has)
+		})
+	})
+}
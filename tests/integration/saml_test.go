@@ -0,0 +1,236 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	audit_model "forgejo.org/models/audit"
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	"forgejo.org/models/unittest"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/setting"
+	"forgejo.org/modules/test"
+	"forgejo.org/services/auth/source/saml"
+	"forgejo.org/tests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type samlTestIdentityProvider struct {
+	key         *rsa.PrivateKey
+	certificate []byte
+}
+
+func newSAMLTestIdentityProvider(t *testing.T) *samlTestIdentityProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return &samlTestIdentityProvider{key: key, certificate: certificate}
+}
+
+func (idp *samlTestIdentityProvider) metadata() string {
+	return fmt.Sprintf(`<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="https://idp.example.com/metadata">
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:X509Data><ds:X509Certificate>%s</ds:X509Certificate></ds:X509Data></ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.com/sso"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`, base64.StdEncoding.EncodeToString(idp.certificate))
+}
+
+// response returns a signed response for the user, it is written in canonical form so that its digest
+// is the digest of the document without signature
+func (idp *samlTestIdentityProvider) response(t *testing.T, spURL, inResponseTo, assertionID string) string {
+	now := time.Now().UTC()
+	inResponseToAttr := ""
+	if inResponseTo != "" {
+		inResponseToAttr = fmt.Sprintf(` InResponseTo="%s"`, inResponseTo)
+	}
+	document := fmt.Sprintf(`<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" Destination="%[1]s/acs" ID="response-1"%[2]s IssueInstant="%[3]s" Version="2.0">`+
+		`<saml:Issuer xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion">https://idp.example.com/metadata</saml:Issuer><!--signature-->`+
+		`<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"></samlp:StatusCode></samlp:Status>`+
+		`<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="%[4]s" IssueInstant="%[3]s" Version="2.0">`+
+		`<saml:Issuer>https://idp.example.com/metadata</saml:Issuer>`+
+		`<saml:Subject><saml:NameID Format="urn:oasis:names:tc:SAML:2.0:nameid-format:persistent">saml-subject-1</saml:NameID>`+
+		`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer"><saml:SubjectConfirmationData%[2]s NotOnOrAfter="%[5]s" Recipient="%[1]s/acs"></saml:SubjectConfirmationData></saml:SubjectConfirmation></saml:Subject>`+
+		`<saml:Conditions NotBefore="%[3]s" NotOnOrAfter="%[5]s"><saml:AudienceRestriction><saml:Audience>%[1]s/metadata</saml:Audience></saml:AudienceRestriction></saml:Conditions>`+
+		`<saml:AttributeStatement>`+
+		`<saml:Attribute Name="uid"><saml:AttributeValue>saml-user</saml:AttributeValue></saml:Attribute>`+
+		`<saml:Attribute Name="mail"><saml:AttributeValue>saml-user@example.com</saml:AttributeValue></saml:Attribute>`+
+		`<saml:Attribute Name="displayName"><saml:AttributeValue>SAML User</saml:AttributeValue></saml:Attribute>`+
+		`<saml:Attribute Name="groups"><saml:AttributeValue>staff</saml:AttributeValue><saml:AttributeValue>admins</saml:AttributeValue></saml:Attribute>`+
+		`</saml:AttributeStatement></saml:Assertion></samlp:Response>`,
+		spURL, inResponseToAttr, now.Add(-time.Minute).Format(time.RFC3339), assertionID, now.Add(5*time.Minute).Format(time.RFC3339))
+
+	digest := sha256.Sum256([]byte(strings.Replace(document, "<!--signature-->", "", 1)))
+	signedInfo := fmt.Sprintf(`<ds:SignedInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">`+
+		`<ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:CanonicalizationMethod>`+
+		`<ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"></ds:SignatureMethod>`+
+		`<ds:Reference URI="#response-1"><ds:Transforms>`+
+		`<ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"></ds:Transform>`+
+		`<ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:Transform>`+
+		`</ds:Transforms><ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"></ds:DigestMethod>`+
+		`<ds:DigestValue>%s</ds:DigestValue></ds:Reference></ds:SignedInfo>`, base64.StdEncoding.EncodeToString(digest[:]))
+	hashed := sha256.Sum256([]byte(signedInfo))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, hashed[:])
+	require.NoError(t, err)
+
+	signed := strings.Replace(document, "<!--signature-->", fmt.Sprintf(`<ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#">%s<ds:SignatureValue>%s</ds:SignatureValue></ds:Signature>`,
+		signedInfo, base64.StdEncoding.EncodeToString(signature)), 1)
+	return base64.StdEncoding.EncodeToString([]byte(signed))
+}
+
+// samlRequestID follows the redirect to the identity provider and returns the ID of the authentication request
+func samlRequestID(t *testing.T, location string) string {
+	u, err := url.Parse(location)
+	require.NoError(t, err)
+	assert.Equal(t, "idp.example.com", u.Host)
+	deflated, err := base64.StdEncoding.DecodeString(u.Query().Get("SAMLRequest"))
+	require.NoError(t, err)
+	request, err := io.ReadAll(flate.NewReader(bytes.NewReader(deflated)))
+	require.NoError(t, err)
+	matches := regexp.MustCompile(` ID="([^"]+)"`).FindSubmatch(request)
+	require.Len(t, matches, 2)
+	return string(matches[1])
+}
+
+func TestSAMLLogin(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+	defer test.MockVariableValue(&setting.Audit.Enabled, true)()
+
+	idp := newSAMLTestIdentityProvider(t)
+	source := addAuthSource(t, map[string]string{
+		"type":                     fmt.Sprintf("%d", auth_model.SAML),
+		"name":                     "test-saml",
+		"is_active":                "on",
+		"saml_idp_metadata":        idp.metadata(),
+		"saml_attribute_username":  "uid",
+		"saml_attribute_email":     "mail",
+		"saml_attribute_full_name": "displayName",
+		"saml_group_attribute":     "groups",
+		"saml_admin_group":         "admins",
+	})
+	spURL := setting.AppURL + "user/saml/test-saml"
+
+	t.Run("LoginPage", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		resp := MakeRequest(t, NewRequest(t, "GET", "/user/login"), http.StatusOK)
+		assert.Contains(t, resp.Body.String(), "/user/saml/test-saml")
+	})
+
+	t.Run("Metadata", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		resp := MakeRequest(t, NewRequest(t, "GET", "/user/saml/test-saml/metadata"), http.StatusOK)
+		assert.Equal(t, "application/samlmetadata+xml", resp.Header().Get("Content-Type"))
+		assert.Contains(t, resp.Body.String(), fmt.Sprintf(`entityID="%s/metadata"`, spURL))
+		assert.Contains(t, resp.Body.String(), fmt.Sprintf(`Location="%s/acs"`, spURL))
+
+		MakeRequest(t, NewRequest(t, "GET", "/user/saml/unknown/metadata"), http.StatusNotFound)
+	})
+
+	t.Run("ServiceProviderInitiated", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		session := emptyTestSession(t)
+		resp := session.MakeRequest(t, NewRequest(t, "GET", "/user/saml/test-saml?redirect_to=/user/settings"), http.StatusSeeOther)
+		location := test.RedirectURL(resp)
+		requestID := samlRequestID(t, location)
+		u, err := url.Parse(location)
+		require.NoError(t, err)
+		assert.Equal(t, "/user/settings", u.Query().Get("RelayState"))
+
+		response := idp.response(t, spURL, requestID, "assertion-1")
+		resp = session.MakeRequest(t, NewRequestWithValues(t, "POST", "/user/saml/test-saml/acs", map[string]string{
+			"SAMLResponse": response,
+			"RelayState":   "/user/settings",
+		}), http.StatusSeeOther)
+		assert.Equal(t, "/user/settings", test.RedirectURL(resp))
+		session.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+
+		user := unittest.AssertExistsAndLoadBean(t, &user_model.User{Name: "saml-user"})
+		assert.Equal(t, auth_model.SAML, user.LoginType)
+		assert.Equal(t, source.ID, user.LoginSource)
+		assert.Equal(t, "saml-subject-1", user.LoginName)
+		assert.Equal(t, "saml-user@example.com", user.Email)
+		assert.Equal(t, "SAML User", user.FullName)
+		assert.True(t, user.IsAdmin)
+
+		events, err := db.Find[audit_model.Event](db.DefaultContext, audit_model.FindEventsOptions{Action: audit_model.ActionUserSignIn, ActorID: user.ID})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.JSONEq(t, `{"method":"saml"}`, events[0].After)
+
+		t.Run("Reused", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			resp := MakeRequest(t, NewRequestWithValues(t, "POST", "/user/saml/test-saml/acs", map[string]string{
+				"SAMLResponse": response,
+			}), http.StatusSeeOther)
+			assert.Equal(t, "/user/login", test.RedirectURL(resp))
+
+			// the failed login is recorded with the NameID of the replayed assertion
+			events, err := db.Find[audit_model.Event](db.DefaultContext, audit_model.FindEventsOptions{Action: audit_model.ActionUserSignInFailed, ActorName: "saml-subject-1"})
+			require.NoError(t, err)
+			require.Len(t, events, 1)
+			assert.JSONEq(t, `{"method":"saml"}`, events[0].After)
+		})
+	})
+
+	t.Run("IdentityProviderInitiated", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequestWithValues(t, "POST", "/user/saml/test-saml/acs", map[string]string{
+			"SAMLResponse": idp.response(t, spURL, "", "assertion-2"),
+		})
+		resp := MakeRequest(t, req, http.StatusSeeOther)
+		assert.Equal(t, "/user/login", test.RedirectURL(resp))
+
+		source.Cfg.(*saml.Source).AllowIdPInitiated = true
+		require.NoError(t, auth_model.UpdateSource(db.DefaultContext, source))
+
+		response := idp.response(t, spURL, "", "assertion-3")
+		req = NewRequestWithValues(t, "POST", "/user/saml/test-saml/acs", map[string]string{
+			"SAMLResponse": response,
+		})
+		resp = MakeRequest(t, req, http.StatusSeeOther)
+		assert.Equal(t, "/", test.RedirectURL(resp))
+
+		// the same assertion must not log in twice
+		req = NewRequestWithValues(t, "POST", "/user/saml/test-saml/acs", map[string]string{
+			"SAMLResponse": response,
+		})
+		resp = MakeRequest(t, req, http.StatusSeeOther)
+		assert.Equal(t, "/user/login", test.RedirectURL(resp))
+	})
+}
@@ -0,0 +1,202 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	secretscan_model "forgejo.org/models/secretscan"
+	"forgejo.org/models/unittest"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/git"
+	repo_module "forgejo.org/modules/repository"
+	"forgejo.org/modules/setting"
+	api "forgejo.org/modules/structs"
+	"forgejo.org/modules/test"
+	"forgejo.org/routers"
+	repo_service "forgejo.org/services/repository"
+	secretscan_service "forgejo.org/services/secretscan"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretScanning(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		defer test.MockVariableValue(&setting.SecretScanning.Enabled, true)()
+		defer test.MockVariableValue(&testWebRoutes, routers.NormalRoutes())()
+
+		owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		repo, err := repo_service.CreateRepository(db.DefaultContext, owner, owner, repo_service.CreateRepoOptions{
+			Name:          "secret-scanning",
+			AutoInit:      false,
+			DefaultBranch: "master",
+		})
+		require.NoError(t, err)
+		collaborator := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5})
+		require.NoError(t, repo_module.AddCollaborator(db.DefaultContext, repo, collaborator))
+
+		gitPath := t.TempDir()
+		doGitInitTestRepository(gitPath, git.Sha1ObjectFormat)(t)
+		u.Path = repo.FullName() + ".git"
+		u.User = url.UserPassword(owner.LowerName, userPassword)
+		doGitAddRemote(gitPath, "origin", u)(t)
+		u.User = url.UserPassword(collaborator.LowerName, userPassword)
+		doGitAddRemote(gitPath, "collaborator", u)(t)
+		doGitPushTestRepository(gitPath, "origin", "master")(t)
+
+		commitFile := func(t *testing.T, name, content string) {
+			t.Helper()
+			require.NoError(t, os.WriteFile(filepath.Join(gitPath, name), []byte(content), 0o644))
+			require.NoError(t, git.AddChanges(gitPath, true))
+			signature := git.Signature{Email: "test@example.com", Name: "test"}
+			require.NoError(t, git.CommitChanges(gitPath, git.CommitChangesOptions{
+				Committer: &signature,
+				Author:    &signature,
+				Message:   "add " + name,
+			}))
+		}
+		push := func(remote string, args ...string) (string, error) {
+			_, stderr, err := git.NewCommand(git.DefaultContext, "push").AddDynamicArguments(remote, "master").AddArguments(git.ToTrustedCmdArgs(args)...).RunStdString(&git.RunOpts{Dir: gitPath})
+			return stderr, err
+		}
+
+		t.Run("Files without secrets are accepted", func(t *testing.T) {
+			commitFile(t, "config.yml", "aws_access_key_id: AKIAEXAMPLE\n")
+			_, err := push("origin")
+			require.NoError(t, err)
+		})
+
+		commitFile(t, "deploy.sh", fmt.Sprintf("#!/bin/sh\nexport AWS_ACCESS_KEY_ID=%s\n", "AKIAZ7XQ3L5M2N4P6R8T"))
+
+		t.Run("Secrets are rejected", func(t *testing.T) {
+			stderr, err := push("origin")
+			require.Error(t, err)
+			assert.Contains(t, stderr, "AWS access key ID")
+			assert.Contains(t, stderr, "deploy.sh:2")
+			assert.NotContains(t, stderr, "AKIAZ7XQ3L5M2N4P6R8T")
+			unittest.AssertCount(t, &secretscan_model.Alert{RepoID: repo.ID}, 0)
+		})
+
+		t.Run("Collaborators are not allowed to bypass the protection", func(t *testing.T) {
+			stderr, err := push("collaborator", "-o", "secret_scanning.bypass")
+			require.Error(t, err)
+			assert.Contains(t, stderr, "Only repository administrators")
+			unittest.AssertCount(t, &secretscan_model.Alert{RepoID: repo.ID}, 0)
+		})
+
+		t.Run("Administrators bypass the protection", func(t *testing.T) {
+			_, err := push("origin", "-o", "secret_scanning.bypass")
+			require.NoError(t, err)
+			alert := unittest.AssertExistsAndLoadBean(t, &secretscan_model.Alert{RepoID: repo.ID})
+			assert.Equal(t, "aws_access_key_id", alert.DetectorID)
+			assert.Equal(t, "deploy.sh", alert.Path)
+			assert.Equal(t, 2, alert.Line)
+			assert.Equal(t, owner.ID, alert.BypassedByID)
+			assert.NotContains(t, alert.Redacted, "AKIAZ7XQ3L5M2N4P6R8T")
+		})
+
+		t.Run("History", func(t *testing.T) {
+			findings, err := secretscan_service.ScanHistory(db.DefaultContext, repo)
+			require.NoError(t, err)
+			require.Len(t, findings, 1)
+			// the secret is already known
+			require.NoError(t, secretscan_service.RecordAlerts(db.DefaultContext, repo, findings, 0))
+			unittest.AssertCount(t, &secretscan_model.Alert{RepoID: repo.ID}, 1)
+		})
+
+		t.Run("Security tab", func(t *testing.T) {
+			alert := unittest.AssertExistsAndLoadBean(t, &secretscan_model.Alert{RepoID: repo.ID})
+			link := fmt.Sprintf("/%s/security", repo.FullName())
+
+			MakeRequest(t, NewRequest(t, "GET", link), http.StatusSeeOther)
+			loginUser(t, collaborator.Name).MakeRequest(t, NewRequest(t, "GET", link), http.StatusNotFound)
+
+			session := loginUser(t, owner.Name)
+			resp := session.MakeRequest(t, NewRequest(t, "GET", link), http.StatusOK)
+			htmlDoc := NewHTMLParser(t, resp.Body)
+			htmlDoc.AssertElement(t, fmt.Sprintf(`form[action="%s/alerts/%d"]`, link, alert.ID), true)
+			assert.Contains(t, resp.Body.String(), alert.Redacted)
+
+			session.MakeRequest(t, NewRequestWithValues(t, "POST", fmt.Sprintf("%s/alerts/%d", link, alert.ID), map[string]string{
+				"_csrf": GetCSRF(t, session, link),
+				"state": "false_positive",
+			}), http.StatusSeeOther)
+			alert = unittest.AssertExistsAndLoadBean(t, &secretscan_model.Alert{ID: alert.ID})
+			assert.Equal(t, secretscan_model.AlertStateFalsePositive, alert.State)
+			assert.Equal(t, owner.ID, alert.ClosedByID)
+
+			session.MakeRequest(t, NewRequestWithValues(t, "POST", fmt.Sprintf("%s/alerts/%d", link, alert.ID), map[string]string{
+				"_csrf": GetCSRF(t, session, link),
+				"state": "unknown",
+			}), http.StatusBadRequest)
+		})
+
+		require.NoError(t, repo_service.DeleteRepositoryDirectly(db.DefaultContext, owner, repo.ID))
+		unittest.AssertCount(t, &secretscan_model.Alert{RepoID: repo.ID}, 0)
+	})
+}
+
+func TestLeakedAccessTokenRevocation(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session, auth_model.AccessTokenScopeWriteIssue)
+		assertRevoked := func(t *testing.T, leaked string, revoked bool) {
+			t.Helper()
+			status := http.StatusOK
+			if revoked {
+				status = http.StatusUnauthorized
+			}
+			MakeRequest(t, NewRequest(t, "GET", "/api/v1/user").AddTokenAuth(leaked), status)
+		}
+
+		t.Run("Public comment", func(t *testing.T) {
+			leaked := getTokenForLoggedInUser(t, session, auth_model.AccessTokenScopeReadUser)
+			assertRevoked(t, leaked, false)
+
+			req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/issues/1/comments", &api.CreateIssueCommentOption{
+				Body: "The API answers 401 with " + leaked,
+			}).AddTokenAuth(token)
+			MakeRequest(t, req, http.StatusCreated)
+			assertRevoked(t, leaked, true)
+		})
+
+		t.Run("Private issue", func(t *testing.T) {
+			leaked := getTokenForLoggedInUser(t, session, auth_model.AccessTokenScopeReadUser)
+
+			req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo2/issues", &api.CreateIssueOption{
+				Title: "API answers 401",
+				Body:  "The API answers 401 with " + leaked,
+			}).AddTokenAuth(token)
+			MakeRequest(t, req, http.StatusCreated)
+			assertRevoked(t, leaked, false)
+		})
+
+		t.Run("Public push", func(t *testing.T) {
+			leaked := getTokenForLoggedInUser(t, session, auth_model.AccessTokenScopeReadUser)
+
+			gitPath := t.TempDir()
+			u.Path = "user2/repo1.git"
+			u.User = url.UserPassword("user2", userPassword)
+			doGitClone(gitPath, u)(t)
+			require.NoError(t, os.WriteFile(filepath.Join(gitPath, "ci.sh"), []byte("curl -H 'Authorization: token "+leaked+"' https://example.com\n"), 0o644))
+			require.NoError(t, git.AddChanges(gitPath, true))
+			signature := git.Signature{Email: "user2@example.com", Name: "user2"}
+			require.NoError(t, git.CommitChanges(gitPath, git.CommitChangesOptions{
+				Committer: &signature,
+				Author:    &signature,
+				Message:   "add ci script",
+			}))
+			doGitPushTestRepository(gitPath, "origin", "master")(t)
+			assertRevoked(t, leaked, true)
+		})
+	})
+}
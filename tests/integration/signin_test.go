@@ -10,6 +10,8 @@ import (
 	"strings"
 	"testing"
 
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
 	"forgejo.org/models/unittest"
 	user_model "forgejo.org/models/user"
 	"forgejo.org/modules/setting"
@@ -17,7 +19,10 @@ import (
 	"forgejo.org/modules/translation"
 	"forgejo.org/tests"
 
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func testLoginFailed(t *testing.T, username, password, message string) {
@@ -141,3 +146,48 @@ func TestDisableSignin(t *testing.T) {
 		})
 	})
 }
+
+func TestSigninWithPasskey(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	t.Run("Assertion", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		resp := MakeRequest(t, NewRequest(t, "GET", "/user/passkey/assertion"), http.StatusOK)
+		var assertion protocol.CredentialAssertion
+		DecodeJSON(t, resp, &assertion)
+		assert.NotEmpty(t, assertion.Response.Challenge)
+		// any passkey can answer it, the user is not known yet
+		assert.Empty(t, assertion.Response.AllowedCredentials)
+		assert.Equal(t, protocol.VerificationRequired, assertion.Response.UserVerification)
+	})
+
+	t.Run("RequiredForAdmins", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		defer test.MockVariableValue(&setting.RequirePasskeyForAdmins, true)()
+
+		// admins without a passkey must register one
+		session := loginUser(t, "user1")
+		resp := session.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusSeeOther)
+		assert.Equal(t, "/user/settings/security", test.RedirectURL(resp))
+		session.MakeRequest(t, NewRequest(t, "GET", "/user/settings/security"), http.StatusOK)
+		loginUser(t, "user2").MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+
+		// once they have one, they are signed out and can not sign in with their password anymore
+		_, err := auth_model.CreatePasskey(db.DefaultContext, 1, "passkey", &webauthn.Credential{ID: []byte("passkey")})
+		require.NoError(t, err)
+		resp = session.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusSeeOther)
+		assert.Equal(t, "/user/login", test.RedirectURL(resp))
+		session.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusSeeOther)
+
+		session = emptyTestSession(t)
+		req := NewRequestWithValues(t, "POST", "/user/login", map[string]string{
+			"_csrf":     GetCSRF(t, session, "/user/login"),
+			"user_name": "user1",
+			"password":  userPassword,
+		})
+		resp = session.MakeRequest(t, req, http.StatusSeeOther)
+		assert.Equal(t, "/user/login", test.RedirectURL(resp))
+		resp = session.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusSeeOther)
+		assert.Equal(t, "/user/login", test.RedirectURL(resp))
+	})
+}
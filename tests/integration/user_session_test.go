@@ -0,0 +1,103 @@
+// Copyright 2025 The Forgejo Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	auth_model "forgejo.org/models/auth"
+	"forgejo.org/models/db"
+	"forgejo.org/models/unittest"
+	user_model "forgejo.org/models/user"
+	"forgejo.org/modules/setting"
+	"forgejo.org/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+// loginUserSession signs in the user and returns the session with the ID of its record
+func loginUserSession(t *testing.T, userName string) (*TestSession, int64) {
+	t.Helper()
+
+	session := loginUser(t, userName)
+	session.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{Name: userName})
+	sessions, err := auth_model.FindUserSessions(db.DefaultContext, user.ID, setting.SessionConfig.Maxlifetime)
+	require.NoError(t, err)
+	var id int64
+	for _, s := range sessions {
+		id = max(id, s.ID)
+	}
+	return session, id
+}
+
+func TestUserSessions(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	t.Run("List and revoke", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		first, _ := loginUserSession(t, "user2")
+		second, secondID := loginUserSession(t, "user2")
+
+		resp := first.MakeRequest(t, NewRequest(t, "GET", "/user/settings/security"), http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		htmlDoc.AssertElement(t, fmt.Sprintf(`button[data-url$="/sessions/revoke"][data-id="%d"]`, secondID), true)
+
+		req := NewRequestWithValues(t, "POST", "/user/settings/security/sessions/revoke", map[string]string{
+			"_csrf": GetCSRF(t, first, "/user/settings/security"),
+			"id":    fmt.Sprint(secondID),
+		})
+		first.MakeRequest(t, req, http.StatusOK)
+		unittest.AssertNotExistsBean(t, &auth_model.UserSession{ID: secondID})
+
+		second.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusSeeOther)
+		first.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+	})
+
+	t.Run("Password change", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		first, _ := loginUserSession(t, "user4")
+		second, _ := loginUserSession(t, "user4")
+
+		req := NewRequestWithValues(t, "POST", "/user/settings/account", map[string]string{
+			"_csrf":        GetCSRF(t, second, "/user/settings/account"),
+			"old_password": userPassword,
+			"password":     "password2",
+			"retype":       "password2",
+		})
+		second.MakeRequest(t, req, http.StatusSeeOther)
+
+		first.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusSeeOther)
+		second.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		admin := loginUser(t, "user1")
+		session, _ := loginUserSession(t, "user5")
+
+		req := NewRequestWithValues(t, "POST", "/admin/users/5/sign_out", map[string]string{
+			"_csrf": GetCSRF(t, admin, "/admin/users/5/edit"),
+		})
+		admin.MakeRequest(t, req, http.StatusOK)
+		session.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusSeeOther)
+
+		session, _ = loginUserSession(t, "user5")
+		token := getTokenForLoggedInUser(t, admin, auth_model.AccessTokenScopeWriteAdmin)
+		req = NewRequest(t, "DELETE", "/api/v1/admin/users/user5/sessions").AddTokenAuth(token)
+		MakeRequest(t, req, http.StatusNoContent)
+		session.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusSeeOther)
+
+		// only admins can sign out other users
+		token = getTokenForLoggedInUser(t, loginUser(t, "user2"), auth_model.AccessTokenScopeWriteAdmin)
+		req = NewRequest(t, "DELETE", "/api/v1/admin/users/user5/sessions").AddTokenAuth(token)
+		MakeRequest(t, req, http.StatusForbidden)
+	})
+}
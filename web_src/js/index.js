@@ -60,7 +60,7 @@ import {
 } from './features/repo-settings.js';
 import {initRepoDiffView} from './features/repo-diff.js';
 import {initOrgTeamSearchRepoBox} from './features/org-team.js';
-import {initUserAuthWebAuthn, initUserAuthWebAuthnRegister} from './features/user-auth-webauthn.js';
+import {initUserAuthWebAuthn, initUserAuthWebAuthnRegister, initUserAuthPasskey} from './features/user-auth-webauthn.js';
 import {initRepoRelease, initRepoReleaseNew} from './features/repo-release.js';
 import {initRepoEditor} from './features/repo-editor.js';
 import {initCompSearchUserBox} from './features/comp/SearchUserBox.js';
@@ -184,6 +184,7 @@ onDomReady(() => {
   initUserAuthOauth2();
   initUserAuthWebAuthn();
   initUserAuthWebAuthnRegister();
+  initUserAuthPasskey();
   initUserAuth();
   initRepoDiffView();
   initPdfViewer();